@@ -0,0 +1,61 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+)
+
+// validateAliases returns an error if an alias of a flag collides with the key of a flag or with an alias of another
+// flag of the configuration, as lookups of the alias would be ambiguous
+func validateAliases(flags *Flags) error {
+	keys := make([]string, 0, len(flags.Flags))
+	for key := range flags.Flags {
+		keys = append(keys, key)
+	}
+	// sorted for deterministic errors
+	sort.Strings(keys)
+
+	declared := map[string]string{}
+	for _, key := range keys {
+		flag := flags.Flags[key]
+		value, ok := flag.Metadata[store.AliasesMetadataKey]
+		if !ok {
+			continue
+		}
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("invalid %s metadata of flag: '%s': must be a comma separated string, got %v",
+				store.AliasesMetadataKey, key, value)
+		}
+
+		for _, alias := range store.Aliases(flag) {
+			if _, ok := flags.Flags[alias]; ok {
+				return fmt.Errorf("alias '%s' of flag: '%s' collides with the key of a flag", alias, key)
+			}
+			if other, ok := declared[alias]; ok && other != key {
+				return fmt.Errorf("alias '%s' is declared by flags: '%s' and '%s'", alias, other, key)
+			}
+			declared[alias] = key
+		}
+	}
+
+	return nil
+}
+
+// lookup returns the flag of the given key, or of the flag declaring the key as alias, along with the ID of the flag
+// set answering the lookup and the key of the returned flag. Flag keys take precedence over aliases.
+func (je *Resolver) lookup(ctx context.Context, key string) (model.Flag, string, string, bool) {
+	if flag, flagSet, ok := je.store.Lookup(ctx, key); ok {
+		return flag, flagSet, key, true
+	}
+
+	target, ok := je.store.Alias(ctx, key)
+	if !ok {
+		return model.Flag{}, "", key, false
+	}
+	flag, flagSet, ok := je.store.Lookup(ctx, target)
+	return flag, flagSet, target, ok
+}
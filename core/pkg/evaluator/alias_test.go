@@ -0,0 +1,97 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type aliasRecorder struct {
+	telemetry.NoopMetricsRecorder
+	hits [][2]string
+}
+
+func (r *aliasRecorder) AliasHit(_ context.Context, alias, key string) {
+	r.hits = append(r.hits, [2]string{alias, key})
+}
+
+func TestAlias(t *testing.T) {
+	recorder := &aliasRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"new-banner": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [{"===": [{"var": "$flagd.flagKey"}, "new-banner"]}, "on", "off"]},
+				"metadata": {"aliases": "banner, old-banner"}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	for _, key := range []string{"new-banner", "banner", "old-banner"} {
+		value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", key, nil)
+		require.NoError(t, err)
+		assert.True(t, value, key)
+		assert.Equal(t, model.TargetingMatchReason, reason, key)
+	}
+	assert.Equal(t, [][2]string{{"banner", "new-banner"}, {"old-banner", "new-banner"}}, recorder.hits)
+
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "unknown", nil)
+	require.EqualError(t, err, model.FlagNotFoundErrorCode)
+}
+
+func TestValidateAliases(t *testing.T) {
+	flag := func(aliases any) model.Flag {
+		return model.Flag{Metadata: map[string]interface{}{store.AliasesMetadataKey: aliases}}
+	}
+
+	tests := map[string]struct {
+		flags map[string]model.Flag
+		err   string
+	}{
+		"valid aliases": {
+			flags: map[string]model.Flag{"a": flag("old-a"), "b": flag("old-b, older-b"), "c": {}},
+		},
+		"alias of a flag key": {
+			flags: map[string]model.Flag{"a": flag("b"), "b": {}},
+			err:   "alias 'b' of flag: 'a' collides with the key of a flag",
+		},
+		"alias of its own key": {
+			flags: map[string]model.Flag{"a": flag("a")},
+			err:   "alias 'a' of flag: 'a' collides with the key of a flag",
+		},
+		"alias declared twice": {
+			flags: map[string]model.Flag{"a": flag("old"), "b": flag("old")},
+			err:   "alias 'old' is declared by flags: 'a' and 'b'",
+		},
+		"alias repeated by a flag": {
+			flags: map[string]model.Flag{"a": flag("old,old")},
+		},
+		"invalid aliases": {
+			flags: map[string]model.Flag{"a": flag(true)},
+			err:   "invalid aliases metadata of flag: 'a': must be a comma separated string, got true",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateAliases(&Flags{Flags: tt.flags})
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+// arithmeticOperations are the JsonLogic arithmetic operators. They replace the implementations of the JsonLogic
+// library, which fail the evaluation on missing operands and produce results that can't be encoded on a division by
+// zero.
+var arithmeticOperations = map[string]func(operands []float64) (float64, bool){
+	"+": func(operands []float64) (float64, bool) {
+		sum := float64(0)
+		for _, operand := range operands {
+			sum += operand
+		}
+		return sum, true
+	},
+	"-": func(operands []float64) (float64, bool) {
+		switch len(operands) {
+		case 0:
+			return 0, false
+		case 1:
+			return -operands[0], true
+		}
+		difference := operands[0]
+		for _, operand := range operands[1:] {
+			difference -= operand
+		}
+		return difference, true
+	},
+	"*": func(operands []float64) (float64, bool) {
+		product := float64(1)
+		for _, operand := range operands {
+			product *= operand
+		}
+		return product, true
+	},
+	"/": func(operands []float64) (float64, bool) {
+		if len(operands) < 2 {
+			return 0, false
+		}
+		quotient := operands[0]
+		for _, operand := range operands[1:] {
+			if operand == 0 {
+				return 0, false
+			}
+			quotient /= operand
+		}
+		return quotient, true
+	},
+	"%": func(operands []float64) (float64, bool) {
+		if len(operands) != 2 || operands[1] == 0 {
+			return 0, false
+		}
+		return math.Mod(operands[0], operands[1]), true
+	},
+}
+
+type Arithmetic struct {
+	Logger *logger.Logger
+}
+
+func NewArithmetic(log *logger.Logger) *Arithmetic {
+	return &Arithmetic{Logger: log}
+}
+
+// ArithmeticEvaluation returns the implementation of the given arithmetic operator, which applies the operator to
+// operands that are numbers or numeric strings, e.g. properties of the evaluation context.
+// As an example, it can be used in the following way to compare against a computed threshold:
+//
+//	{
+//	  ">=": [
+//			{"var": "age"},
+//			{"-": [{"var": "threshold"}, {"var": "grace"}]}
+//	  ]
+//	}
+//
+// The operation evaluates to null instead of failing the evaluation if an operand is missing or not numeric, if a
+// division or modulo by zero occurs or if the result isn't finite.
+func (a *Arithmetic) ArithmeticEvaluation(operator string) func(values, data interface{}) interface{} {
+	operation := arithmeticOperations[operator]
+	return func(values, _ interface{}) interface{} {
+		list, ok := values.([]any)
+		if !ok {
+			list = []any{values}
+		}
+
+		operands := make([]float64, 0, len(list))
+		for _, value := range list {
+			operand, ok := arithmeticOperand(value)
+			if !ok {
+				a.Logger.Debug(fmt.Sprintf("arithmetic operation %s evaluates to null, operand is not a number: %v",
+					operator, value))
+				return nil
+			}
+			operands = append(operands, operand)
+		}
+
+		result, ok := operation(operands)
+		if !ok || math.IsInf(result, 0) || math.IsNaN(result) {
+			a.Logger.Debug(fmt.Sprintf("arithmetic operation %s evaluates to null, the result of %v is undefined",
+				operator, list))
+			return nil
+		}
+		return result
+	}
+}
+
+// arithmeticOperand coerces an operand of an arithmetic operation to a number. Numbers and strings holding a finite
+// number are coerced, other values, including booleans and null, are not.
+func arithmeticOperand(value any) (float64, bool) {
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		number = f
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, false
+		}
+		number = f
+	default:
+		return 0, false
+	}
+	if math.IsInf(number, 0) || math.IsNaN(number) {
+		return 0, false
+	}
+	return number, true
+}
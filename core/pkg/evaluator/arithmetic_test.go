@@ -0,0 +1,119 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArithmeticEvaluation(t *testing.T) {
+	tests := map[string]struct {
+		operator string
+		values   interface{}
+		expected interface{}
+	}{
+		"sum":                        {operator: "+", values: []any{1, 2, float64(3)}, expected: float64(6)},
+		"sum of int and float":       {operator: "+", values: []any{1, 2.5}, expected: 3.5},
+		"sum of json number":         {operator: "+", values: []any{json.Number("2"), 0.5}, expected: 2.5},
+		"sum of numeric string":      {operator: "+", values: []any{"3.5", 1}, expected: 4.5},
+		"cast to number":             {operator: "+", values: "3.14", expected: 3.14},
+		"empty sum":                  {operator: "+", values: []any{}, expected: float64(0)},
+		"difference":                 {operator: "-", values: []any{float64(18), 0.5}, expected: 17.5},
+		"negation":                   {operator: "-", values: []any{float64(2)}, expected: float64(-2)},
+		"product":                    {operator: "*", values: []any{2, 1.5}, expected: float64(3)},
+		"quotient":                   {operator: "/", values: []any{float64(7), 2}, expected: 3.5},
+		"modulo":                     {operator: "%", values: []any{7.5, 2}, expected: 1.5},
+		"division by zero":           {operator: "/", values: []any{float64(1), float64(0)}, expected: nil},
+		"modulo by zero":             {operator: "%", values: []any{float64(5), 0}, expected: nil},
+		"missing operand":            {operator: "-", values: []any{float64(18), nil}, expected: nil},
+		"missing single operand":     {operator: "*", values: nil, expected: nil},
+		"non-numeric string":         {operator: "*", values: []any{"x", float64(2)}, expected: nil},
+		"boolean operand":            {operator: "+", values: []any{true, float64(2)}, expected: nil},
+		"non-finite string":          {operator: "+", values: []any{"Inf", float64(2)}, expected: nil},
+		"overflow":                   {operator: "*", values: []any{1e308, float64(10)}, expected: nil},
+		"single operand of division": {operator: "/", values: []any{float64(2)}, expected: nil},
+		"modulo of three operands":   {operator: "%", values: []any{float64(5), float64(3), float64(2)}, expected: nil},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := NewArithmetic(logger.NewLogger(nil, false))
+			assert.Equal(t, tt.expected, a.ArithmeticEvaluation(tt.operator)(tt.values, nil))
+		})
+	}
+}
+
+func TestArithmeticTargeting(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"eligible": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {
+					"if": [{"strict_>=": [{"var": "age"}, {"-": [{"var": "threshold"}, {"var": "grace"}]}]}, "on", "off"]
+				}
+			},
+			"ratio": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {
+					"if": [{"==": [{"/": [{"var": "used"}, {"var": "quota"}]}, null]}, null,
+						{"if": [{">": [{"/": [{"var": "used"}, {"var": "quota"}]}, 0.5]}, "on", "off"]}]
+				}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		flag     string
+		context  map[string]any
+		expected bool
+		reason   string
+	}{
+		"mixed int and float": {
+			flag:     "eligible",
+			context:  map[string]any{"age": 17, "threshold": 18, "grace": 1.5},
+			expected: true, reason: model.TargetingMatchReason,
+		},
+		"below threshold": {
+			flag:     "eligible",
+			context:  map[string]any{"age": 16.9, "threshold": 18, "grace": 1},
+			expected: false, reason: model.TargetingMatchReason,
+		},
+		"missing operand": {
+			flag:     "eligible",
+			context:  map[string]any{"age": 17, "threshold": 18},
+			expected: false, reason: model.TargetingMatchReason,
+		},
+		"ratio": {
+			flag:     "ratio",
+			context:  map[string]any{"used": 3, "quota": 4},
+			expected: true, reason: model.TargetingMatchReason,
+		},
+		"division by zero": {
+			flag:     "ratio",
+			context:  map[string]any{"used": 3, "quota": 0},
+			expected: false, reason: model.DefaultReason,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", tt.flag, tt.context)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
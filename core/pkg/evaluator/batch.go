@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+)
+
+// batchTargetingKey is the context key of the targeting rules compiled for the evaluations of a batch
+type batchTargetingKey struct{}
+
+// batchTargeting holds the decoded targeting rules of a batch, so that the rules are decoded once per batch instead of
+// once per evaluation. Rules are keyed by their JSON rather than by flag, as the configuration may change during the
+// batch. Evaluations abandoned on timeout may still compile rules while the batch continues.
+type batchTargeting struct {
+	mu    sync.Mutex
+	rules map[string]any
+}
+
+// rule returns the decoded targeting rule, decoding it on first use
+func (b *batchTargeting) rule(targeting []byte) (any, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if rule, ok := b.rules[string(targeting)]; ok {
+		return rule, nil
+	}
+	var rule any
+	if err := json.Unmarshal(targeting, &rule); err != nil {
+		return nil, fmt.Errorf("error decoding targeting rules: %w", err)
+	}
+	b.rules[string(targeting)] = rule
+	return rule, nil
+}
+
+// ResolveBatchValues evaluates a flag against each of the given contexts, returning the results in the order of the
+// contexts. Errors of single evaluations are returned as part of their result. The targeting rules are compiled once
+// for all evaluations of the batch, which evaluates against a single snapshot of the store.
+func (je *Resolver) ResolveBatchValues(ctx context.Context, reqID string, flagKey string,
+	contexts []map[string]any,
+) []AnyValue {
+	ctx, span := je.tracer.Start(ctx, "resolveBatch")
+	defer span.End()
+
+	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating flag `%s` against %d contexts", flagKey, len(contexts)))
+	je, _ = je.snapshot()
+	ctx = context.WithValue(ctx, batchTargetingKey{}, &batchTargeting{rules: map[string]any{}})
+	values := make([]AnyValue, 0, len(contexts))
+	for _, evalCtx := range contexts {
+		value, variant, reason, meta, err := resolve[interface{}](ctx, reqID, flagKey, evalCtx, je.evaluateVariant)
+		values = append(values, NewAnyValue(value, variant, reason, flagKey, meta, err))
+	}
+	return values
+}
+
+// apply applies the targeting rules to the evaluation context, as jsonlogic.Apply does, with the rules compiled for
+// the batch
+func (b *batchTargeting) apply(targeting []byte, evalCtx []byte, result *bytes.Buffer) error {
+	rule, err := b.rule(targeting)
+	if err != nil {
+		return err
+	}
+	var data any
+	if err := json.Unmarshal(evalCtx, &data); err != nil {
+		return fmt.Errorf("error decoding the evaluation context: %w", err)
+	}
+	output, err := jsonlogic.ApplyInterface(rule, data)
+	if err != nil {
+		return fmt.Errorf("error applying targeting rules: %w", err)
+	}
+	if err := json.NewEncoder(result).Encode(output); err != nil {
+		return fmt.Errorf("error encoding the targeting result: %w", err)
+	}
+	return nil
+}
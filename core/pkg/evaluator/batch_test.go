@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const batchConfig = `{
+	"flags": {
+		"color": {
+			"state": "ENABLED",
+			"variants": {"red": "red", "blue": "blue", "green": "green"},
+			"defaultVariant": "green",
+			"targeting": {
+				"if": [
+					{"in": ["@example.com", {"var": "email"}]},
+					{"fractional": [{"var": "email"}, ["red", 50], ["blue", 50]]},
+					{"if": [{"var": "invalid"}, "purple", null]}
+				]
+			}
+		}
+	}
+}`
+
+func TestResolveBatchValues(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: batchConfig})
+	require.NoError(t, err)
+
+	contexts := []map[string]any{
+		{"email": "ada@example.com"},
+		{"email": "grace@example.com"},
+		{"email": "ada@example.org"},
+		{"invalid": true},
+		nil,
+	}
+	values := evaluator.ResolveBatchValues(context.Background(), "req", "color", contexts)
+	require.Len(t, values, len(contexts))
+
+	// each result equals the result of evaluating the context on its own
+	for i, evalCtx := range contexts {
+		expected := evaluator.ResolveAsAnyValue(context.Background(), "req", "color", evalCtx)
+		assert.Equal(t, expected, values[i], "context %d", i)
+	}
+	assert.Equal(t, model.TargetingMatchReason, values[0].Reason)
+	assert.Equal(t, model.DefaultReason, values[2].Reason)
+	assert.Equal(t, model.ErrorReason, values[3].Reason, "errors are returned per context")
+	require.Error(t, values[3].Error)
+	require.NoError(t, values[4].Error)
+
+	values = evaluator.ResolveBatchValues(context.Background(), "req", "missing", contexts[:2])
+	require.Len(t, values, 2)
+	for _, value := range values {
+		require.EqualError(t, value.Error, model.FlagNotFoundErrorCode)
+	}
+}
+
+func TestBatchTargetingCompilesOnce(t *testing.T) {
+	batch := &batchTargeting{rules: map[string]any{}}
+	targeting := []byte(`{"==": [{"var": "tier"}, "gold"]}`)
+
+	for evalCtx, expected := range map[string]string{`{"tier": "gold"}`: "true", `{"tier": "silver"}`: "false"} {
+		var result bytes.Buffer
+		require.NoError(t, batch.apply(targeting, []byte(evalCtx), &result))
+		assert.Equal(t, expected, strings.TrimSpace(result.String()))
+	}
+	assert.Len(t, batch.rules, 1)
+
+	require.Error(t, batch.apply([]byte(`{invalid`), []byte(`{}`), &bytes.Buffer{}))
+}
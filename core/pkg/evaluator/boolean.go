@@ -0,0 +1,195 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// resultKind classifies the statically known result of a targeting rule
+type resultKind int
+
+const (
+	// unknownResult - the result depends on the evaluation context or is not analyzed
+	unknownResult resultKind = iota
+	booleanResult
+	numberResult
+	arrayResult
+)
+
+// booleanOperators always produce a boolean result
+var booleanOperators = map[string]struct{}{
+	"==": {}, "===": {}, "!=": {}, "!==": {}, "!": {}, "!!": {},
+	"<": {}, "<=": {}, ">": {}, ">=": {}, "in": {}, "some": {}, "all": {}, "none": {},
+	StartsWithEvaluationName: {}, EndsWithEvaluationName: {}, SemVerEvaluationName: {}, DateOffsetEvaluationName: {},
+}
+
+// numberOperators always produce a numeric result
+var numberOperators = map[string]struct{}{
+	"+": {}, "-": {}, "*": {}, "/": {}, "%": {}, "min": {}, "max": {},
+}
+
+// arrayOperators always produce an array result
+var arrayOperators = map[string]struct{}{
+	"map": {}, "filter": {}, "merge": {},
+}
+
+// isBooleanFlag reports whether all variants of the flag are boolean values
+func isBooleanFlag(flag model.Flag) bool {
+	if len(flag.Variants) == 0 {
+		return false
+	}
+	for _, value := range flag.Variants {
+		if _, ok := value.(bool); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// booleanVariant maps the boolean result of a targeting rule to the variant of a boolean flag holding the same value.
+// The mapping only applies if exactly one variant holds the value.
+func booleanVariant(flag model.Flag, result bool) (string, bool) {
+	matched := ""
+	for name, value := range flag.Variants {
+		if value != result {
+			continue
+		}
+		if matched != "" {
+			return "", false
+		}
+		matched = name
+	}
+	return matched, matched != ""
+}
+
+// booleanTargetingResult returns the boolean a targeting rule resolved to, only the JSON literals true and false are
+// booleans
+func booleanTargetingResult(result string) (bool, bool) {
+	switch result {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// validateBooleanTargeting returns an error if the targeting of a boolean flag statically resolves to a value which can
+// neither be mapped to a variant name nor to a boolean variant
+func validateBooleanTargeting(flags *Flags) error {
+	for name, flag := range flags.Flags {
+		if !isBooleanFlag(flag) || len(flag.Targeting) == 0 {
+			continue
+		}
+
+		var rule any
+		if err := json.Unmarshal(flag.Targeting, &rule); err != nil {
+			// parsing errors are reported at evaluation time
+			continue
+		}
+
+		for _, result := range targetingResults(rule) {
+			if !canResolveBooleanVariant(flag, result) {
+				return fmt.Errorf(
+					"targeting of boolean flag: '%s' resolves to %v, which is neither a boolean nor a variant", name, result,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// targetingResults collects the statically known values a targeting rule may resolve to. Results which depend on the
+// evaluation context are reported as unknownResult.
+func targetingResults(rule any) []any {
+	switch r := rule.(type) {
+	case map[string]any:
+		if len(r) != 1 {
+			return []any{unknownResult}
+		}
+		for operator, args := range r {
+			return operatorResults(operator, args)
+		}
+	case []any:
+		return []any{arrayResult}
+	}
+	return []any{rule}
+}
+
+func operatorResults(operator string, args any) []any {
+	if _, ok := booleanOperators[operator]; ok {
+		return []any{booleanResult}
+	}
+	if _, ok := numberOperators[operator]; ok {
+		return []any{numberResult}
+	}
+	if _, ok := arrayOperators[operator]; ok {
+		return []any{arrayResult}
+	}
+
+	list, ok := args.([]any)
+	if !ok {
+		return []any{unknownResult}
+	}
+
+	var results []any
+	switch operator {
+	case FractionEvaluationName, LegacyFractionEvaluationName:
+		// fractional operations resolve to the variant of one of their distributions
+		for _, arg := range list {
+			if distribution, ok := arg.([]any); ok && len(distribution) > 0 {
+				results = append(results, distribution[0])
+			}
+		}
+		if len(results) == 0 {
+			results = append(results, unknownResult)
+		}
+	case "if", "?:":
+		// results are located at the odd positions, followed by an optional trailing else branch
+		for i := 1; i < len(list); i += 2 {
+			results = append(results, targetingResults(list[i])...)
+		}
+		if len(list)%2 == 1 {
+			results = append(results, targetingResults(list[len(list)-1])...)
+		}
+	case "and", "or":
+		// and/or resolve to one of their arguments
+		for _, arg := range list {
+			results = append(results, targetingResults(arg)...)
+		}
+	default:
+		results = append(results, unknownResult)
+	}
+	return results
+}
+
+func canResolveBooleanVariant(flag model.Flag, result any) bool {
+	switch r := result.(type) {
+	case float64:
+		_, ok := flag.Variants[strconv.FormatFloat(r, 'f', -1, 64)]
+		return ok
+	case resultKind:
+		switch r {
+		case arrayResult:
+			return false
+		case numberResult:
+			// numbers may still be stringified to a variant name
+			for variant := range flag.Variants {
+				if _, err := strconv.ParseFloat(variant, 64); err == nil {
+					return true
+				}
+			}
+			return false
+		default:
+			return true
+		}
+	default:
+		// nil, boolean and string values
+		return true
+	}
+}
@@ -0,0 +1,194 @@
+package evaluator
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	gosync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/twmb/murmur3"
+)
+
+const (
+	// EvaluationCacheTTLMetadataKey is the flag or flag set metadata key opting a flag into the evaluation cache, its
+	// value is the duration cached results are served for, e.g. "5s"
+	EvaluationCacheTTLMetadataKey = "evaluationCacheTTL"
+	// DefaultEvaluationCacheSize is the default maximum number of cached evaluation results
+	DefaultEvaluationCacheSize = 10000
+)
+
+// WithEvaluationCacheSize bounds the number of evaluation results cached for flags opted into the evaluation cache
+// with the EvaluationCacheTTLMetadataKey, zero disables the cache
+func WithEvaluationCacheSize(size int) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.cache = nil
+		if size > 0 {
+			je.cache = newEvaluationCache(size)
+		}
+	}
+}
+
+// cacheKey identifies the cached result of a flag evaluated against a context, by the hash of the context, and the
+// version of the store snapshot it was evaluated against, if any
+type cacheKey struct {
+	flagKey string
+	flagSet string
+	version string
+	h1, h2  uint64
+}
+
+type cachedEvaluation struct {
+	evaluation
+	key     cacheKey
+	expires time.Time
+}
+
+// evaluationCache caches the results of flags opted into caching until they expire or the configuration changes. The
+// least recently used result is evicted if the cache is full.
+type evaluationCache struct {
+	mu      gosync.Mutex
+	size    int
+	entries map[cacheKey]*list.Element
+	// recency orders the cached results from the most to the least recently used one
+	recency *list.List
+}
+
+func newEvaluationCache(size int) *evaluationCache {
+	return &evaluationCache{size: size, entries: map[cacheKey]*list.Element{}, recency: list.New()}
+}
+
+// get returns the cached result of a key, unless it expired
+func (c *evaluationCache) get(key cacheKey, now time.Time) (evaluation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return evaluation{}, false
+	}
+	entry, _ := element.Value.(*cachedEvaluation)
+	if !now.Before(entry.expires) {
+		c.remove(element)
+		return evaluation{}, false
+	}
+	c.recency.MoveToFront(element)
+	return entry.evaluation, true
+}
+
+// put caches the result of a key until it expires, evicting the least recently used result if the cache is full
+func (c *evaluationCache) put(key cacheKey, e evaluation, now time.Time, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cachedEvaluation{evaluation: e, key: key, expires: now.Add(ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.recency.MoveToFront(element)
+		return
+	}
+	if len(c.entries) >= c.size {
+		c.remove(c.recency.Back())
+	}
+	c.entries[key] = c.recency.PushFront(entry)
+}
+
+// remove drops a cached result, the caller must hold the lock
+func (c *evaluationCache) remove(element *list.Element) {
+	entry, _ := c.recency.Remove(element).(*cachedEvaluation)
+	delete(c.entries, entry.key)
+}
+
+// clear drops all cached results, e.g. as the configuration changed
+func (c *evaluationCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[cacheKey]*list.Element{}
+	c.recency.Init()
+}
+
+// evaluationCacheTTL returns the duration the results of a flag are cached for, zero if the flag isn't opted into
+// caching
+func evaluationCacheTTL(metadata map[string]interface{}) (time.Duration, error) {
+	value, ok := metadata[EvaluationCacheTTLMetadataKey]
+	if !ok {
+		return 0, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("expected a duration string but got %v", value)
+	}
+	ttl, err := time.ParseDuration(s)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("expected a non-negative duration but got %s", s)
+	}
+	return ttl, nil
+}
+
+// warnInvalidEvaluationCacheTTLs logs the flags whose evaluation cache metadata is invalid, these are not cached
+func warnInvalidEvaluationCacheTTLs(log *logger.Logger, flags *Flags) {
+	for key, flag := range flags.Flags {
+		if _, err := evaluationCacheTTL(flag.Metadata); err != nil {
+			log.Warn(fmt.Sprintf("ignoring invalid %s metadata of flag %s: %v", EvaluationCacheTTLMetadataKey, key,
+				err))
+		}
+	}
+}
+
+// cachedVariant serves the cached result of flags opted into the evaluation cache, evaluating and caching the flag if
+// there is no result for the flag and context yet. Flags without targeting and region defaults are cached regardless of
+// the context, as their result doesn't depend on it. Only successful evaluations are cached.
+func (je *Resolver) cachedVariant(ctx context.Context, reqID string, flagKey string, evalCtx map[string]any) (
+	variant string, variants map[string]interface{}, reason string, metadata map[string]interface{}, err error,
+) {
+	flag, flagSet, key, ok := je.lookup(ctx, flagKey)
+	if !ok || flag.State == Disabled {
+		return je.boundedVariant(ctx, reqID, flagKey, evalCtx)
+	}
+	ttl, ttlErr := evaluationCacheTTL(flag.Metadata)
+	if ttlErr != nil || ttl == 0 {
+		return je.boundedVariant(ctx, reqID, flagKey, evalCtx)
+	}
+
+	entryKey := cacheKey{flagKey: key, flagSet: flagSet, version: je.snapshotVersion}
+	// the region defaults depend on the context as well
+	_, regional := flag.Metadata[RegionDefaultsMetadataKey]
+	if regional || len(flag.Targeting) > 0 && string(flag.Targeting) != "{}" {
+		b, marshalErr := json.Marshal(evalCtx)
+		if marshalErr != nil {
+			return je.boundedVariant(ctx, reqID, flagKey, evalCtx)
+		}
+		entryKey.h1, entryKey.h2 = murmur3.Sum128(b)
+	}
+
+	now := time.Now()
+	if e, ok := je.cache.get(entryKey, now); ok {
+		telemetry.CacheMetrics(je.metrics).EvaluationCacheLookup(ctx, key, telemetry.EvaluationCacheHit)
+		return e.variant, e.variants, e.reason, copyMetadata(e.metadata), nil
+	}
+	telemetry.CacheMetrics(je.metrics).EvaluationCacheLookup(ctx, key, telemetry.EvaluationCacheMiss)
+
+	variant, variants, reason, metadata, err = je.boundedVariant(ctx, reqID, flagKey, evalCtx)
+	if err == nil && reason != model.ErrorReason {
+		je.cache.put(entryKey, evaluation{
+			variant:  variant,
+			variants: variants,
+			reason:   reason,
+			metadata: copyMetadata(metadata),
+		}, now, ttl)
+	}
+	return variant, variants, reason, metadata, err
+}
+
+// copyMetadata returns a shallow copy of evaluation metadata, so that callers can't modify cached metadata
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied
+}
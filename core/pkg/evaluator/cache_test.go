@@ -0,0 +1,195 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheRecorder struct {
+	telemetry.NoopMetricsRecorder
+	lookups []string
+}
+
+func (r *cacheRecorder) EvaluationCacheLookup(_ context.Context, key, result string) {
+	r.lookups = append(r.lookups, key+"/"+result)
+}
+
+const cacheConfig = `{
+	"flags": {
+		"targeted": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "off",
+			"targeting": {"if": [{"==": [{"var": "tier"}, "gold"]}, "on", "off"]},
+			"metadata": {"evaluationCacheTTL": "1m"}
+		},
+		"static": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "on",
+			"metadata": {"evaluationCacheTTL": "1m"}
+		},
+		"uncached": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "on"
+		},
+		"invalid": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "on",
+			"metadata": {"evaluationCacheTTL": "soon"}
+		}
+	}
+}`
+
+func TestEvaluationCache(t *testing.T) {
+	tests := map[string]struct {
+		flagKey  string
+		contexts []map[string]any
+		expected []string
+	}{
+		"same context": {
+			flagKey:  "targeted",
+			contexts: []map[string]any{{"tier": "gold"}, {"tier": "gold"}},
+			expected: []string{"targeted/miss", "targeted/hit"},
+		},
+		"different contexts": {
+			flagKey:  "targeted",
+			contexts: []map[string]any{{"tier": "gold"}, {"tier": "silver"}, {"tier": "gold"}},
+			expected: []string{"targeted/miss", "targeted/miss", "targeted/hit"},
+		},
+		"flag without targeting ignores the context": {
+			flagKey:  "static",
+			contexts: []map[string]any{{"tier": "gold"}, {"tier": "silver"}},
+			expected: []string{"static/miss", "static/hit"},
+		},
+		"flag not opted in": {
+			flagKey:  "uncached",
+			contexts: []map[string]any{{}, {}},
+		},
+		"invalid ttl": {
+			flagKey:  "invalid",
+			contexts: []map[string]any{{}, {}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &cacheRecorder{}
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: cacheConfig})
+			require.NoError(t, err)
+
+			for _, evalCtx := range tt.contexts {
+				_, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", tt.flagKey, evalCtx)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, recorder.lookups)
+		})
+	}
+}
+
+func TestEvaluationCacheResult(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: cacheConfig})
+	require.NoError(t, err)
+
+	for range 2 {
+		value, variant, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", "targeted",
+			map[string]any{"tier": "gold"})
+		require.NoError(t, err)
+		assert.True(t, value)
+		assert.Equal(t, "on", variant)
+		assert.Equal(t, model.TargetingMatchReason, reason)
+	}
+}
+
+func TestEvaluationCacheInvalidation(t *testing.T) {
+	recorder := &cacheRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: cacheConfig})
+	require.NoError(t, err)
+
+	value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", "static", nil)
+	require.NoError(t, err)
+	assert.True(t, value)
+
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"static": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"metadata": {"evaluationCacheTTL": "1m"}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	value, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "static", nil)
+	require.NoError(t, err)
+	assert.False(t, value)
+	assert.Equal(t, []string{"static/miss", "static/miss"}, recorder.lookups)
+}
+
+func TestEvaluationCacheDisabled(t *testing.T) {
+	recorder := &cacheRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder),
+		WithEvaluationCacheSize(0))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: cacheConfig})
+	require.NoError(t, err)
+
+	for range 2 {
+		_, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", "static", nil)
+		require.NoError(t, err)
+	}
+	assert.Empty(t, recorder.lookups)
+}
+
+func TestEvaluationCacheExpiry(t *testing.T) {
+	cache := newEvaluationCache(10)
+	key := cacheKey{flagKey: "flag"}
+	now := time.Now()
+
+	cache.put(key, evaluation{variant: "on"}, now, time.Second)
+	e, ok := cache.get(key, now.Add(500*time.Millisecond))
+	require.True(t, ok)
+	assert.Equal(t, "on", e.variant)
+
+	_, ok = cache.get(key, now.Add(time.Second))
+	assert.False(t, ok)
+}
+
+func TestEvaluationCacheSize(t *testing.T) {
+	cache := newEvaluationCache(2)
+	now := time.Now()
+
+	cache.put(cacheKey{flagKey: "expired"}, evaluation{}, now, time.Millisecond)
+	cache.put(cacheKey{flagKey: "a"}, evaluation{}, now, time.Minute)
+	cache.put(cacheKey{flagKey: "b"}, evaluation{}, now.Add(time.Second), time.Minute)
+	require.Len(t, cache.entries, 2)
+	assert.NotContains(t, cache.entries, cacheKey{flagKey: "expired"})
+
+	// the least recently used result is evicted
+	_, ok := cache.get(cacheKey{flagKey: "a"}, now.Add(time.Second))
+	require.True(t, ok)
+	cache.put(cacheKey{flagKey: "c"}, evaluation{}, now.Add(time.Second), time.Minute)
+	require.Len(t, cache.entries, 2)
+	assert.Contains(t, cache.entries, cacheKey{flagKey: "a"})
+	assert.Contains(t, cache.entries, cacheKey{flagKey: "c"})
+
+	// replacing a result doesn't evict another one
+	cache.put(cacheKey{flagKey: "a"}, evaluation{variant: "on"}, now.Add(time.Second), time.Minute)
+	require.Len(t, cache.entries, 2)
+	assert.Equal(t, 2, cache.recency.Len())
+}
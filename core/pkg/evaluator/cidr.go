@@ -0,0 +1,210 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+const CIDREvaluationName = "cidr"
+
+const cidrOperands = 2
+
+type CIDR struct {
+	Logger *logger.Logger
+	// blocks caches the parsed networks by their literal list of CIDR blocks, so that the blocks of a rule are only
+	// parsed once. The cache is reset by the resolver whenever a configuration is applied, so that it is bounded by
+	// the blocks of the loaded rules.
+	blocks atomic.Pointer[sync.Map]
+}
+
+func NewCIDR(log *logger.Logger) *CIDR {
+	c := &CIDR{Logger: log}
+	c.reset()
+	return c
+}
+
+// reset drops the cached networks, e.g. as the rules holding them changed
+func (c *CIDR) reset() {
+	c.blocks.Store(&sync.Map{})
+}
+
+// CIDREvaluation checks if the given IP address is contained in one of the given CIDR blocks.
+// It returns 'true', if the IP address is contained in any of the blocks, 'false' if not.
+// As an example, it can be used in the following way inside an 'if' evaluation:
+//
+//	{
+//	  "if": [
+//			{
+//				"cidr": [{"var": "ip"}, ["10.0.0.0/8", "192.168.0.0/16", "fd00::/8"]]
+//			},
+//			"internal", "external"
+//			]
+//	}
+//
+// This rule can be applied to the following data object, where the evaluation will resolve to 'true':
+//
+// { "ip": "10.1.2.3" }
+//
+// Note that the 'cidr' evaluation rule must contain exactly two items:
+// 1. Target property: a string holding an IPv4 or IPv6 address
+// 2. Blocks: a CIDR block, or an array of CIDR blocks, of IPv4 and IPv6 networks
+//
+// IPv4-mapped IPv6 addresses and blocks match their IPv4 counterparts.
+func (c *CIDR) CIDREvaluation(values, _ interface{}) interface{} {
+	parsed, ok := values.([]interface{})
+	if !ok || len(parsed) != cidrOperands {
+		c.Logger.Error("parse cidr evaluation data: cidr evaluation must contain an IP address and CIDR blocks")
+		return nil
+	}
+
+	networks, err := c.networks(parsed[1])
+	if err != nil {
+		c.Logger.Error(fmt.Sprintf("parse cidr evaluation data: %v", err))
+		return nil
+	}
+
+	value, ok := parsed[0].(string)
+	if !ok {
+		c.Logger.Debug("cidr evaluation: IP address did not resolve to a string value")
+		return false
+	}
+	ip, err := netip.ParseAddr(value)
+	if err != nil {
+		c.Logger.Debug(fmt.Sprintf("cidr evaluation: invalid IP address: %v", err))
+		return false
+	}
+	ip = ip.Unmap().WithZone("")
+
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// networks returns the parsed CIDR blocks, from the cache if they were parsed before
+func (c *CIDR) networks(arg interface{}) ([]netip.Prefix, error) {
+	blocks, err := cidrBlocks(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.Join(blocks, ",")
+	cache := c.blocks.Load()
+	if cached, ok := cache.Load(key); ok {
+		networks, _ := cached.([]netip.Prefix)
+		return networks, nil
+	}
+
+	networks, err := parseCIDRBlocks(blocks)
+	if err != nil {
+		return nil, err
+	}
+	cache.Store(key, networks)
+	return networks, nil
+}
+
+// cidrBlocks returns the CIDR blocks of a rule, which are either a single string or an array of strings
+func cidrBlocks(arg interface{}) ([]string, error) {
+	switch blocks := arg.(type) {
+	case string:
+		return []string{blocks}, nil
+	case []interface{}:
+		if len(blocks) == 0 {
+			return nil, errors.New("cidr evaluation: no CIDR blocks")
+		}
+		parsed := make([]string, len(blocks))
+		for i, block := range blocks {
+			s, ok := block.(string)
+			if !ok {
+				return nil, errors.New("cidr evaluation: CIDR blocks must be strings")
+			}
+			parsed[i] = s
+		}
+		return parsed, nil
+	default:
+		return nil, errors.New("cidr evaluation: CIDR blocks must be a string or an array of strings")
+	}
+}
+
+func parseCIDRBlocks(blocks []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, len(blocks))
+	for i, block := range blocks {
+		network, err := netip.ParsePrefix(strings.TrimSpace(block))
+		if err != nil {
+			return nil, fmt.Errorf("cidr evaluation: invalid CIDR block: %w", err)
+		}
+		networks[i] = unmapPrefix(network).Masked()
+	}
+	return networks, nil
+}
+
+// unmapPrefix converts IPv4-mapped IPv6 networks to IPv4 networks, as the matched addresses are unmapped
+func unmapPrefix(network netip.Prefix) netip.Prefix {
+	addr := network.Addr()
+	if addr.Is4In6() && network.Bits() >= 96 {
+		return netip.PrefixFrom(addr.Unmap(), network.Bits()-96)
+	}
+	return network
+}
+
+// validateCIDRBlocks returns an error if a cidr rule of a flag's targeting holds an invalid CIDR block. The blocks must
+// be literals, so that they are known at load time.
+func validateCIDRBlocks(flags *Flags) error {
+	for name, flag := range flags.Flags {
+		if len(flag.Targeting) == 0 {
+			continue
+		}
+
+		var rule any
+		if err := json.Unmarshal(flag.Targeting, &rule); err != nil {
+			// parsing errors are reported at evaluation time
+			continue
+		}
+		if err := validateCIDRRules(rule); err != nil {
+			return fmt.Errorf("invalid targeting of flag: '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateCIDRRules(rule any) error {
+	switch r := rule.(type) {
+	case []any:
+		for _, item := range r {
+			if err := validateCIDRRules(item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for operator, args := range r {
+			if operator == CIDREvaluationName {
+				parsed, ok := args.([]any)
+				if !ok || len(parsed) != cidrOperands {
+					return errors.New("cidr evaluation must contain an IP address and CIDR blocks")
+				}
+				blocks, err := cidrBlocks(parsed[1])
+				if err != nil {
+					return err
+				}
+				if _, err := parseCIDRBlocks(blocks); err != nil {
+					return err
+				}
+			}
+			if err := validateCIDRRules(args); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCIDREvaluation(t *testing.T) {
+	blocks := []interface{}{"10.0.0.0/8", "192.168.1.0/24", "2001:db8::/32", "::ffff:172.16.0.0/108"}
+
+	tests := map[string]struct {
+		values   interface{}
+		expected interface{}
+	}{
+		"single block": {
+			values:   []interface{}{"10.1.2.3", "10.0.0.0/8"},
+			expected: true,
+		},
+		"outside single block": {
+			values:   []interface{}{"11.1.2.3", "10.0.0.0/8"},
+			expected: false,
+		},
+		"ipv4 in list": {
+			values:   []interface{}{"192.168.1.20", blocks},
+			expected: true,
+		},
+		"ipv6 in list": {
+			values:   []interface{}{"2001:db8:1::1", blocks},
+			expected: true,
+		},
+		"not in list": {
+			values:   []interface{}{"192.168.2.20", blocks},
+			expected: false,
+		},
+		"ipv4-mapped address": {
+			values:   []interface{}{"::ffff:10.1.2.3", blocks},
+			expected: true,
+		},
+		"ipv4-mapped block": {
+			values:   []interface{}{"172.16.5.1", blocks},
+			expected: true,
+		},
+		"ipv4 not matching ipv6 block": {
+			// 32.1.13.184 shares its 32 bits with the 2001:db8::/32 prefix
+			values:   []interface{}{"32.1.13.184", blocks},
+			expected: false,
+		},
+		"ipv6 not matching ipv4 block": {
+			values:   []interface{}{"a00::1", blocks},
+			expected: false,
+		},
+		"invalid address": {
+			values:   []interface{}{"not-an-ip", blocks},
+			expected: false,
+		},
+		"missing address": {
+			values:   []interface{}{nil, blocks},
+			expected: false,
+		},
+		"invalid block": {
+			values: []interface{}{"10.1.2.3", []interface{}{"10.0.0.0/8", "10.0.0.0/33"}},
+		},
+		"non-string block": {
+			values: []interface{}{"10.1.2.3", []interface{}{"10.0.0.0/8", 10}},
+		},
+		"empty list": {
+			values: []interface{}{"10.1.2.3", []interface{}{}},
+		},
+		"missing blocks": {
+			values: []interface{}{"10.1.2.3"},
+		},
+		"not an array": {
+			values: "10.1.2.3",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := NewCIDR(logger.NewLogger(nil, false))
+			assert.Equal(t, tt.expected, c.CIDREvaluation(tt.values, nil))
+		})
+	}
+}
+
+func TestCIDREvaluation_CachesBlocks(t *testing.T) {
+	c := NewCIDR(logger.NewLogger(nil, false))
+	blocks := []interface{}{"10.0.0.0/8", "192.168.1.0/24"}
+
+	assert.Equal(t, true, c.CIDREvaluation([]interface{}{"10.1.2.3", blocks}, nil))
+	assert.Equal(t, false, c.CIDREvaluation([]interface{}{"11.1.2.3", blocks}, nil))
+
+	cached := func() int {
+		count := 0
+		c.blocks.Load().Range(func(_, _ any) bool {
+			count++
+			return true
+		})
+		return count
+	}
+	assert.Equal(t, 1, cached())
+
+	c.reset()
+	assert.Equal(t, 0, cached())
+}
+
+func TestCIDRBlocksResetOnSetState(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	apply := func(block string) {
+		t.Helper()
+		_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: fmt.Sprintf(`{
+			"flags": {
+				"network": {
+					"state": "ENABLED",
+					"variants": {"internal": "internal", "external": "external"},
+					"defaultVariant": "external",
+					"targeting": {"if": [{"cidr": [{"var": "ip"}, "%s"]}, "internal", null]}
+				}
+			}
+		}`, block)})
+		require.NoError(t, err)
+	}
+
+	for _, block := range []string{"10.0.0.0/8", "192.168.0.0/16"} {
+		apply(block)
+		_, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "req", "network",
+			map[string]any{"ip": "10.1.2.3"})
+		require.NoError(t, err)
+	}
+
+	// only the networks of the loaded rules are cached
+	var cached []any
+	evaluator.cidr.blocks.Load().Range(func(key, _ any) bool {
+		cached = append(cached, key)
+		return true
+	})
+	assert.Equal(t, []any{"192.168.0.0/16"}, cached)
+}
+
+func TestCIDRTargeting(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"network": {
+				"state": "ENABLED",
+				"variants": {"internal": "internal", "external": "external"},
+				"defaultVariant": "external",
+				"targeting": {
+					"if": [
+						{"cidr": [{"var": "ip"}, ["10.0.0.0/8", "fd00::/8"]]},
+						"internal", "external"
+					]
+				}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		ip       any
+		expected string
+	}{
+		"ipv4 in block": {ip: "10.1.2.3", expected: "internal"},
+		"ipv6 in block": {ip: "fd12::1", expected: "internal"},
+		"outside":       {ip: "8.8.8.8", expected: "external"},
+		"missing ip":    {expected: "external"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evalCtx := map[string]any{}
+			if tt.ip != nil {
+				evalCtx["ip"] = tt.ip
+			}
+			value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "", "network", evalCtx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestValidateCIDRBlocks(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		wantErr   bool
+	}{
+		"single block": {
+			targeting: `{"if": [{"cidr": [{"var": "ip"}, "10.0.0.0/8"]}, "on", "off"]}`,
+		},
+		"list of blocks": {
+			targeting: `{"if": [{"cidr": [{"var": "ip"}, ["10.0.0.0/8", "2001:db8::/32"]]}, "on", "off"]}`,
+		},
+		"invalid block": {
+			targeting: `{"if": [{"cidr": [{"var": "ip"}, ["10.0.0.0/8", "10.0.0.1"]]}, "on", "off"]}`,
+			wantErr:   true,
+		},
+		"blocks from context": {
+			targeting: `{"if": [{"cidr": [{"var": "ip"}, {"var": "blocks"}]}, "on", "off"]}`,
+			wantErr:   true,
+		},
+		"missing blocks": {
+			targeting: `{"if": [{"cidr": [{"var": "ip"}]}, "on", "off"]}`,
+			wantErr:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+				"flags": {
+					"flag": {
+						"state": "ENABLED",
+						"variants": {"on": "on", "off": "off"},
+						"defaultVariant": "off",
+						"targeting": ` + tt.targeting + `
+					}
+				}
+			}`})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+)
+
+// targetingCompiler compiles the targeting of flags into the rules applied by the evaluator, resolving what their
+// operations reference:
+//   - the 'var' operands of exists operations are replaced by their paths
+//   - the table names of lookup operations are replaced by the tables
+//   - the enum names of in_enum operations are replaced by the sets of their members
+//   - the settings of the flag are prepended to its fractional operations
+//
+// and the comparisons of flags evaluated in strict mode are replaced by their strict counterparts.
+type targetingCompiler struct {
+	lookups map[string]LookupTable
+	enums   map[string]map[string]any
+}
+
+// compiledFlag holds the settings of the flag whose targeting is compiled
+type compiledFlag struct {
+	strict     bool
+	fractional fractionalSettings
+}
+
+// compilerOperations are the operations rewritten by the targeting compiler
+var compilerOperations = []string{
+	ExistsEvaluationName, LookupEvaluationName, InEnumEvaluationName, FractionEvaluationName,
+}
+
+// compileTargeting sets the compiled targeting of the flags whose targeting is rewritten by the compilation, flags
+// are evaluated in strict mode unless overridden by their metadata if defaultStrict is set. The targeting itself is
+// kept as written, as it is stored and served to the consumers of the flag configuration.
+func compileTargeting(log *logger.Logger, flags *Flags, defaultStrict bool) error {
+	enums, err := enumSets(flags.enums)
+	if err != nil {
+		return err
+	}
+	compiler := targetingCompiler{lookups: flags.lookups, enums: enums}
+	for key, flag := range flags.Flags {
+		if len(flag.Targeting) == 0 {
+			continue
+		}
+		strict := strictTargeting(log, key, flag.Metadata, defaultStrict)
+		if !strict && !compiler.references(flag.Targeting) {
+			continue
+		}
+
+		// numbers are decoded as json.Number to retain their literal representation
+		var rule any
+		if err := unmarshalWithNumbers(flag.Targeting, &rule); err != nil {
+			return fmt.Errorf("unmarshalling targeting of flag %s: %w", key, err)
+		}
+		compiled, err := compiler.compile(rule, compiledFlag{strict: strict, fractional: newFractionalSettings(flag)})
+		if err != nil {
+			return fmt.Errorf("invalid targeting of flag: '%s': %w", key, err)
+		}
+		flag.CompiledTargeting, err = marshalTargeting(compiled)
+		if err != nil {
+			return fmt.Errorf("marshalling compiled targeting of flag %s: %w", key, err)
+		}
+		flags.Flags[key] = flag
+	}
+	return nil
+}
+
+// references reports whether a targeting rule may hold operations rewritten by the compilation
+func (c *targetingCompiler) references(targeting json.RawMessage) bool {
+	for _, operation := range compilerOperations {
+		if bytes.Contains(targeting, []byte(`"`+operation+`"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile recursively rewrites the operations of the rule of a flag
+func (c *targetingCompiler) compile(rule any, flag compiledFlag) (any, error) {
+	switch r := rule.(type) {
+	case map[string]any:
+		compiled := make(map[string]any, len(r))
+		for operator, args := range r {
+			if _, ok := strictComparisons[operator]; ok && flag.strict {
+				operator = StrictOperatorPrefix + operator
+			}
+			switch operator {
+			case ExistsEvaluationName:
+				args = existsPath(args)
+			case FractionEvaluationName:
+				if list, ok := args.([]any); ok {
+					args = append([]any{flag.fractional.operand()}, list...)
+				}
+			case LookupEvaluationName:
+				lookup, err := lookupTable(args, c.lookups)
+				if err != nil {
+					return nil, err
+				}
+				// the table is static, only the key operand may hold nested operations
+				key, err := c.compile(lookup[1], flag)
+				if err != nil {
+					return nil, err
+				}
+				compiled[operator] = []any{lookup[0], key}
+				continue
+			case InEnumEvaluationName:
+				operation, err := enumOperation(args, c.enums)
+				if err != nil {
+					return nil, err
+				}
+				// the enum is static, only the value operand may hold nested operations
+				value, err := c.compile(operation[0], flag)
+				if err != nil {
+					return nil, err
+				}
+				compiled[operator] = []any{value, operation[1]}
+				continue
+			}
+			compiledArgs, err := c.compile(args, flag)
+			if err != nil {
+				return nil, err
+			}
+			compiled[operator] = compiledArgs
+		}
+		return compiled, nil
+	case []any:
+		compiled := make([]any, len(r))
+		for i, arg := range r {
+			compiledArg, err := c.compile(arg, flag)
+			if err != nil {
+				return nil, err
+			}
+			compiled[i] = compiledArg
+		}
+		return compiled, nil
+	default:
+		return rule, nil
+	}
+}
+
+// marshalTargeting encodes a rewritten targeting rule. The operators are not HTML escaped to keep the rule readable.
+func marshalTargeting(rule any) (json.RawMessage, error) {
+	var targeting bytes.Buffer
+	encoder := json.NewEncoder(&targeting)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(rule); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(targeting.Bytes()), nil
+}
+
+// declarationKinds are the top-level fields of a configuration declaring what the targeting references by name
+var declarationKinds = []string{"$lookups", "$enums"}
+
+// configDeclarations returns the declarations of a configuration as written
+func configDeclarations(config string) (store.Declarations, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(config), &fields); err != nil {
+		return nil, fmt.Errorf("unmarshalling declarations: %w", err)
+	}
+	declarations := store.Declarations{}
+	for _, kind := range declarationKinds {
+		raw, ok := fields[kind]
+		if !ok {
+			continue
+		}
+		var named map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &named); err != nil {
+			return nil, fmt.Errorf("unmarshalling %s declarations: %w", kind, err)
+		}
+		if len(named) > 0 {
+			declarations[kind] = named
+		}
+	}
+	return declarations, nil
+}
+
+// evaluatedTargeting returns the targeting of a flag as applied by the evaluator
+func evaluatedTargeting(flag model.Flag) json.RawMessage {
+	if len(flag.CompiledTargeting) > 0 {
+		return flag.CompiledTargeting
+	}
+	return flag.Targeting
+}
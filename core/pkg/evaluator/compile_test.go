@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServedFlags(t *testing.T) {
+	const config = `{
+		"$lookups": {
+			"countryToTier": {"entries": {"DE": "gold"}, "default": "bronze"}
+		},
+		"$enums": {
+			"plans": ["free", "pro"]
+		},
+		"flags": {
+			"tier": {
+				"state": "ENABLED",
+				"variants": {"none": "none", "gold": "gold", "bronze": "bronze"},
+				"defaultVariant": "none",
+				"targeting": {"if": [
+					{"in_enum": [{"var": "plan"}, "plans"]},
+					{"lookup": ["countryToTier", {"var": "country"}]}
+				]}
+			},
+			"known": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [{"exists": {"var": "country"}}, "on", "off"]}
+			},
+			"strict": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"==": [{"var": "seats"}, 5]},
+				"metadata": {"strictTargeting": true}
+			}
+		}
+	}`
+	flags := store.NewFlags()
+	evaluator := NewJSON(logger.NewLogger(nil, false), flags)
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config, Source: "upstream"})
+	require.NoError(t, err)
+
+	all, err := flags.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.JSONEq(t,
+		`{"if": [{"in_enum": [{"var": "plan"}, "plans"]}, {"lookup": ["countryToTier", {"var": "country"}]}]}`,
+		string(all["tier"].Targeting))
+	assert.JSONEq(t, `{"if": [{"exists": {"var": "country"}}, "on", "off"]}`, string(all["known"].Targeting))
+	assert.JSONEq(t, `{"==": [{"var": "seats"}, 5]}`, string(all["strict"].Targeting))
+
+	// the flags are served along with their declarations, as by the flag sync
+	served := map[string]any{"flags": all}
+	for kind, named := range flags.GetDeclarations("") {
+		served[kind] = named
+	}
+	servedConfig, err := json.Marshal(served)
+	require.NoError(t, err)
+
+	downstream := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err = downstream.SetState(sync.DataSync{FlagData: string(servedConfig), Source: "downstream"})
+	require.NoError(t, err)
+	evalCtx := map[string]any{"plan": "pro", "country": "DE", "seats": "5"}
+	tier, _, _, _, err := downstream.ResolveStringValue(context.Background(), "req", "tier", evalCtx)
+	require.NoError(t, err)
+	assert.Equal(t, "gold", tier)
+	known, _, _, _, err := downstream.ResolveBooleanValue(context.Background(), "req", "known", evalCtx)
+	require.NoError(t, err)
+	assert.True(t, known)
+	strict, _, _, _, err := downstream.ResolveBooleanValue(context.Background(), "req", "strict", evalCtx)
+	require.NoError(t, err)
+	assert.False(t, strict)
+}
@@ -0,0 +1,201 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+const DateOffsetEvaluationName = "date_offset"
+
+const (
+	day                = 24 * time.Hour
+	dateOffsetOperands = 3
+)
+
+type DateOffsetOperator string
+
+const (
+	DateBefore        DateOffsetOperator = "<"
+	DateBeforeOrEqual DateOffsetOperator = "<="
+	DateAfter         DateOffsetOperator = ">"
+	DateAfterOrEqual  DateOffsetOperator = ">="
+)
+
+func (doo DateOffsetOperator) compare(actual, target time.Time) (bool, error) {
+	switch doo {
+	case DateBefore:
+		return actual.Before(target), nil
+	case DateBeforeOrEqual:
+		return !actual.After(target), nil
+	case DateAfter:
+		return actual.After(target), nil
+	case DateAfterOrEqual:
+		return !actual.Before(target), nil
+	default:
+		return false, errors.New("invalid operator")
+	}
+}
+
+type DateOffset struct {
+	Logger *logger.Logger
+	now    func() time.Time
+}
+
+type DateOffsetOption func(do *DateOffset)
+
+// WithDateOffsetClock overrides the source of the current time the offsets are applied to, mainly useful for testing
+func WithDateOffsetClock(now func() time.Time) DateOffsetOption {
+	return func(do *DateOffset) {
+		do.now = now
+	}
+}
+
+func NewDateOffset(log *logger.Logger, opts ...DateOffsetOption) *DateOffset {
+	do := &DateOffset{Logger: log, now: time.Now}
+	for _, o := range opts {
+		o(do)
+	}
+	return do
+}
+
+// DateOffsetEvaluation checks if the given date property matches a condition relative to the current time.
+// It returns 'true', if the value of the given property meets the condition, 'false' if not.
+// As an example, it can be used in the following way inside an 'if' evaluation to target users that signed up within
+// the last 30 days:
+//
+//	{
+//	  "if": [
+//			{
+//				"date_offset": [{"var": "signupDate"}, ">=", "-30d"]
+//			},
+//			"red", null
+//			]
+//	}
+//
+// This rule can be applied to the following data object, where the evaluation will resolve to 'true' if the current
+// date is before 2024-02-01:
+//
+// { "signupDate": "2024-01-02T10:00:00Z" }
+//
+// Note that the 'date_offset' evaluation rule must contain exactly three items:
+// 1. Target property: an RFC 3339 timestamp string or a number of seconds since the unix epoch
+// 2. Operator: One of the following: '<', '<=', '>', '>='
+// 3. Offset: a duration added to the current time, accepting Go duration syntax (e.g. '-1h30m') extended by a
+// leading day unit (e.g. '-30d' or '1d12h')
+func (do *DateOffset) DateOffsetEvaluation(values, _ interface{}) interface{} {
+	actual, operator, offset, err := parseDateOffsetEvaluationData(values)
+	if err != nil {
+		do.Logger.Error(fmt.Sprintf("parse date_offset evaluation data: %v", err))
+		return false
+	}
+	res, err := operator.compare(actual, do.now().Add(offset))
+	if err != nil {
+		do.Logger.Error(fmt.Sprintf("date_offset evaluation: %v", err))
+		return false
+	}
+	return res
+}
+
+func parseDateOffsetEvaluationData(values interface{}) (time.Time, DateOffsetOperator, time.Duration, error) {
+	parsed, ok := values.([]interface{})
+	if !ok {
+		return time.Time{}, "", 0, errors.New("date_offset evaluation is not an array")
+	}
+
+	if len(parsed) != dateOffsetOperands {
+		return time.Time{}, "", 0,
+			errors.New("date_offset evaluation must contain a value, an operator and an offset")
+	}
+
+	actual, err := parseDate(parsed[0])
+	if err != nil {
+		return time.Time{}, "", 0, fmt.Errorf("date_offset evaluation: could not parse target property value: %w", err)
+	}
+
+	operator, ok := parsed[1].(string)
+	if !ok {
+		return time.Time{}, "", 0, errors.New("date_offset evaluation: could not parse operator")
+	}
+
+	rawOffset, ok := parsed[2].(string)
+	if !ok {
+		return time.Time{}, "", 0, errors.New("date_offset evaluation: offset did not resolve to a string value")
+	}
+
+	offset, err := parseOffset(rawOffset)
+	if err != nil {
+		return time.Time{}, "", 0, fmt.Errorf("date_offset evaluation: could not parse offset: %w", err)
+	}
+
+	return actual, DateOffsetOperator(operator), offset, nil
+}
+
+// parseDate accepts RFC 3339 timestamps as well as numeric unix timestamps in seconds, such as $flagd.timestamp
+func parseDate(v interface{}) (time.Time, error) {
+	switch date := v.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("not a valid RFC 3339 timestamp: %w", err)
+		}
+		return t, nil
+	case float64:
+		sec, frac := math.Modf(date)
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+	case json.Number:
+		f, err := date.Float64()
+		if err != nil {
+			return time.Time{}, fmt.Errorf("not a valid unix timestamp: %w", err)
+		}
+		return parseDate(f)
+	case int64:
+		return time.Unix(date, 0), nil
+	case int:
+		return time.Unix(int64(date), 0), nil
+	default:
+		return time.Time{}, errors.New("property did not resolve to a timestamp string or number")
+	}
+}
+
+// parseOffset parses a signed duration in Go duration syntax, additionally accepting a leading day component
+// such as '30d' or '-1d12h'
+func parseOffset(offset string) (time.Duration, error) {
+	value := offset
+	sign := time.Duration(1)
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		if value[0] == '-' {
+			sign = -1
+		}
+		value = value[1:]
+	}
+
+	var days time.Duration
+	if idx := strings.Index(value, "d"); idx >= 0 {
+		d, err := strconv.ParseFloat(value[:idx], 64)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid day component in %q", offset)
+		}
+		days = time.Duration(d * float64(day))
+		value = value[idx+1:]
+		if value == "" {
+			return sign * days, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", offset, err)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q: sign must lead the offset", offset)
+	}
+
+	return sign * (days + duration), nil
+}
@@ -0,0 +1,145 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOffset(t *testing.T) {
+	tests := map[string]struct {
+		offset   string
+		expected time.Duration
+		wantErr  bool
+	}{
+		"go duration": {
+			offset:   "1h30m",
+			expected: 90 * time.Minute,
+		},
+		"negative go duration": {
+			offset:   "-15m",
+			expected: -15 * time.Minute,
+		},
+		"days": {
+			offset:   "30d",
+			expected: 30 * 24 * time.Hour,
+		},
+		"negative days": {
+			offset:   "-30d",
+			expected: -30 * 24 * time.Hour,
+		},
+		"days with go duration": {
+			offset:   "-1d12h",
+			expected: -36 * time.Hour,
+		},
+		"explicit positive sign": {
+			offset:   "+2d",
+			expected: 48 * time.Hour,
+		},
+		"fractional days": {
+			offset:   "1.5d",
+			expected: 36 * time.Hour,
+		},
+		"empty": {
+			offset:  "",
+			wantErr: true,
+		},
+		"missing day value": {
+			offset:  "d",
+			wantErr: true,
+		},
+		"sign within duration": {
+			offset:  "1d-2h",
+			wantErr: true,
+		},
+		"unknown unit": {
+			offset:  "3w",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseOffset(tt.offset)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestDateOffsetEvaluation(t *testing.T) {
+	now := time.Date(2024, time.February, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		values   interface{}
+		expected interface{}
+	}{
+		"signed up within the last 30 days": {
+			values:   []interface{}{"2024-01-15T00:00:00Z", ">=", "-30d"},
+			expected: true,
+		},
+		"signed up before the last 30 days": {
+			values:   []interface{}{"2023-12-01T00:00:00Z", ">=", "-30d"},
+			expected: false,
+		},
+		"boundary is inclusive": {
+			values:   []interface{}{"2024-01-02T12:00:00Z", ">=", "-30d"},
+			expected: true,
+		},
+		"boundary is exclusive": {
+			values:   []interface{}{"2024-01-02T12:00:00Z", ">", "-30d"},
+			expected: false,
+		},
+		"before a future date": {
+			values:   []interface{}{"2024-02-01T13:00:00Z", "<", "2h"},
+			expected: true,
+		},
+		"unix timestamp": {
+			values:   []interface{}{float64(now.Add(-time.Hour).Unix()), "<=", "-1h"},
+			expected: true,
+		},
+		"timestamp offset in another zone": {
+			values:   []interface{}{"2024-02-01T10:30:00-01:00", ">", "-1h"},
+			expected: true,
+		},
+		"invalid operator": {
+			values:   []interface{}{"2024-01-15T00:00:00Z", "=", "-30d"},
+			expected: false,
+		},
+		"invalid date": {
+			values:   []interface{}{"15/01/2024", ">=", "-30d"},
+			expected: false,
+		},
+		"invalid offset": {
+			values:   []interface{}{"2024-01-15T00:00:00Z", ">=", "a month ago"},
+			expected: false,
+		},
+		"non string offset": {
+			values:   []interface{}{"2024-01-15T00:00:00Z", ">=", 30},
+			expected: false,
+		},
+		"missing operand": {
+			values:   []interface{}{"2024-01-15T00:00:00Z", ">="},
+			expected: false,
+		},
+		"not an array": {
+			values:   "2024-01-15T00:00:00Z",
+			expected: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			log := logger.NewLogger(nil, false)
+			do := NewDateOffset(log, WithDateOffsetClock(func() time.Time { return now }))
+
+			assert.Equal(t, tt.expected, do.DateOffsetEvaluation(tt.values, nil))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// DefaultMaxTargetingDepth is the default maximum nesting depth of targeting rules, generous enough for any rule
+// written by hand while keeping the recursive JsonLogic evaluation far from exhausting the stack
+const DefaultMaxTargetingDepth = 1000
+
+// WithMaxTargetingDepth limits the nesting depth of targeting rules, each object and array of a rule adds a level,
+// e.g. {"==": [{"var": "email"}, "a@example.com"]} is nested 3 levels deep. Configurations with rules exceeding the
+// limit are rejected, so that these are never evaluated. Zero doesn't limit the depth.
+func WithMaxTargetingDepth(depth int) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.maxDepth = depth
+	}
+}
+
+// checkTargetingDepth rejects configurations with targeting rules nested deeper than the maximum depth, deletions are
+// never rejected. The depth is measured once per configuration rather than per evaluation.
+func (je *JSON) checkTargetingDepth(payload sync.DataSync, flags *Flags) error {
+	if je.maxDepth <= 0 || payload.Type == sync.DELETE {
+		return nil
+	}
+	for key, flag := range flags.Flags {
+		if depth := targetingDepth(flag.Targeting); depth > je.maxDepth {
+			return fmt.Errorf("invalid targeting of flag: '%s': nested %d levels deep, exceeding the maximum depth of %d",
+				key, depth, je.maxDepth)
+		}
+	}
+	return nil
+}
+
+// targetingDepth returns the maximum nesting depth of the objects and arrays of a JSON document. The document is
+// scanned without being decoded, so that measuring a pathologically deep rule doesn't recurse.
+func targetingDepth(data []byte) int {
+	depth, maxDepth := 0, 0
+	inString, escaped := false, false
+	for _, c := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return maxDepth
+}
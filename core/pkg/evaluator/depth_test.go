@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetingDepth(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		expected  int
+	}{
+		"scalar": {
+			targeting: `"on"`,
+			expected:  0,
+		},
+		"comparison": {
+			targeting: `{"==": [{"var": "email"}, "a@example.com"]}`,
+			expected:  3,
+		},
+		"brackets in strings": {
+			targeting: `{"==": [{"var": "email"}, "[{\"[{"]}`,
+			expected:  3,
+		},
+		"siblings": {
+			targeting: `{"and": [{"var": "a"}, {"var": "b"}, [1, 2]]}`,
+			expected:  3,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, targetingDepth([]byte(tt.targeting)))
+		})
+	}
+}
+
+// deepConfig returns a configuration of a flag whose targeting is nested the given number of levels deep, the
+// condition of its if operation is wrapped in nested arrays as these are cheap to validate
+func deepConfig(depth int) string {
+	condition := strings.Repeat("[", depth-2) + "true" + strings.Repeat("]", depth-2)
+	return fmt.Sprintf(`{
+		"flags": {
+			"deep": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [%s, "on", "off"]}
+			}
+		}
+	}`, condition)
+}
+
+func TestMaxTargetingDepth(t *testing.T) {
+	tests := map[string]struct {
+		depth    int
+		opts     []JSONEvaluatorOption
+		expected bool
+		reason   string
+		err      string
+	}{
+		"within the default depth": {
+			depth:    DefaultMaxTargetingDepth,
+			expected: true,
+			reason:   model.TargetingMatchReason,
+		},
+		"exceeding the default depth": {
+			depth: 5000,
+			err:   "invalid targeting of flag: 'deep': nested 5000 levels deep, exceeding the maximum depth of 1000",
+		},
+		"exceeding a configured depth": {
+			depth: 11,
+			opts:  []JSONEvaluatorOption{WithMaxTargetingDepth(10)},
+			err:   "invalid targeting of flag: 'deep': nested 11 levels deep, exceeding the maximum depth of 10",
+		},
+		"unlimited depth": {
+			depth:    5000,
+			opts:     []JSONEvaluatorOption{WithMaxTargetingDepth(0)},
+			expected: true,
+			reason:   model.TargetingMatchReason,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), tt.opts...)
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: deepConfig(tt.depth)})
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", "deep", nil)
+			assert.Equal(t, tt.reason, reason)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
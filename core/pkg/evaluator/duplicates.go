@@ -0,0 +1,83 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+// duplicateFlagKeys returns the keys defined more than once in the flags object of a configuration, in the order of
+// their first redefinition. Decoding the configuration silently keeps the last definition of these flags. Malformed
+// configurations yield no keys, as their errors are reported when decoding them.
+func duplicateFlagKeys(config string) []string {
+	decoder := json.NewDecoder(strings.NewReader(config))
+	if !expectDelim(decoder, '{') {
+		return nil
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+		if token != "flags" {
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return nil
+			}
+			continue
+		}
+
+		if !expectDelim(decoder, '{') {
+			return nil
+		}
+		seen := map[string]int{}
+		var duplicates []string
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil
+			}
+			key, ok := token.(string)
+			if !ok {
+				return nil
+			}
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return nil
+			}
+			seen[key]++
+			if seen[key] == 2 {
+				duplicates = append(duplicates, key)
+			}
+		}
+		return duplicates
+	}
+	return nil
+}
+
+// expectDelim reads the next token of the decoder, reporting whether it is the given delimiter
+func expectDelim(decoder *json.Decoder, delim json.Delim) bool {
+	token, err := decoder.Token()
+	return err == nil && token == delim
+}
+
+// duplicateFlagKeysError is returned for configurations defining flags more than once, if duplicates are rejected
+func duplicateFlagKeysError(keys []string) error {
+	return fmt.Errorf("flags defined more than once: '%s'", strings.Join(keys, "', '"))
+}
+
+// duplicateFlagWarnings returns the warnings of flags defined more than once, if duplicates are tolerated
+func duplicateFlagWarnings(keys []string) []configWarning {
+	warnings := make([]configWarning, 0, len(keys))
+	for _, key := range keys {
+		warnings = append(warnings, configWarning{
+			flag:     key,
+			category: telemetry.ConfigDuplicateFlag,
+			message:  "flag is defined more than once, the last definition is used",
+		})
+	}
+	return warnings
+}
@@ -0,0 +1,85 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const duplicateFlagsConfig = `{
+	"flags": {
+		"color": {
+			"state": "ENABLED",
+			"variants": {"red": "red", "blue": "blue"},
+			"defaultVariant": "red"
+		},
+		"color": {
+			"state": "ENABLED",
+			"variants": {"red": "red", "blue": "blue"},
+			"defaultVariant": "blue"
+		}
+	}
+}`
+
+func TestDuplicateFlagKeys(t *testing.T) {
+	tests := map[string]struct {
+		config   string
+		expected []string
+	}{
+		"unique keys": {
+			config: `{"flags": {"a": {}, "b": {}}, "metadata": {"a": 1}}`,
+		},
+		"duplicate keys": {
+			config:   `{"$evaluators": {"x": {"in": ["a", "b"]}}, "flags": {"b": {}, "a": {}, "b": {}, "a": {}, "b": {}}}`,
+			expected: []string{"b", "a"},
+		},
+		"duplicate keys of nested objects are ignored": {
+			config: `{"flags": {"a": {"variants": {"on": true, "on": false}}}}`,
+		},
+		"duplicate keys outside of the flags are ignored": {
+			config: `{"metadata": {"id": "a", "id": "b"}, "flags": {"a": {}}}`,
+		},
+		"malformed configuration": {
+			config: `{"flags": {"a": {}, "a": `,
+		},
+		"flags aren't an object": {
+			config: `{"flags": []}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, duplicateFlagKeys(tt.config))
+		})
+	}
+}
+
+func TestSetStateDuplicateFlagKeys(t *testing.T) {
+	t.Run("warns by default", func(t *testing.T) {
+		recorder := &warningRecorder{}
+		evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+		_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: duplicateFlagsConfig})
+		require.NoError(t, err)
+
+		// the last definition is used
+		value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "req", "color", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "blue", value)
+		assert.Equal(t, [][2]string{{"file", telemetry.ConfigDuplicateFlag}}, recorder.warnings)
+	})
+
+	t.Run("rejects duplicates", func(t *testing.T) {
+		evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithRejectDuplicateFlagKeys())
+		_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: duplicateFlagsConfig})
+		require.EqualError(t, err, "flags defined more than once: 'color'")
+
+		_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "req", "color", nil)
+		require.Error(t, err)
+	})
+}
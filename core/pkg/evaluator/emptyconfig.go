@@ -0,0 +1,34 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// ErrEmptyConfig is returned for configurations defining no flags, unless empty configurations are allowed
+var ErrEmptyConfig = errors.New("empty flag configuration")
+
+// WithAllowEmptyConfig applies configurations defining no flags, which remove all flags of their source. By default,
+// such configurations are rejected and the last valid configuration of the source is kept, as an empty configuration
+// is almost always the result of a faulty source.
+func WithAllowEmptyConfig() JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.allowEmptyConfig = true
+	}
+}
+
+// isEmptyConfig reports whether a configuration replaces all flags of its source by none. Additions and updates without
+// flags don't change any flag, deletions without flags deliberately remove the source, e.g. a deleted file.
+func isEmptyConfig(payload sync.DataSync, flags *Flags) bool {
+	return payload.Type == sync.ALL && len(flags.Flags) == 0
+}
+
+// checkEmptyConfig rejects empty configurations, unless empty configurations are allowed
+func (je *JSON) checkEmptyConfig(payload sync.DataSync, flags *Flags) error {
+	if je.allowEmptyConfig || !isEmptyConfig(payload, flags) {
+		return nil
+	}
+	return fmt.Errorf("%w: configuration of source %s defines no flags", ErrEmptyConfig, payload.Source)
+}
@@ -0,0 +1,83 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type emptyConfigRecorder struct {
+	telemetry.NoopMetricsRecorder
+	applied []string
+}
+
+func (r *emptyConfigRecorder) EmptyConfigApplied(_ context.Context, source string) {
+	r.applied = append(r.applied, source)
+}
+
+func TestEmptyConfig(t *testing.T) {
+	const oneFlag = `{
+		"flags": {
+			"a": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`
+	const noFlags = `{"flags": {}}`
+
+	tests := map[string]struct {
+		allow    bool
+		syncType sync.Type
+		err      bool
+		applied  []string
+	}{
+		"rejected by default": {
+			syncType: sync.ALL,
+			err:      true,
+		},
+		"allowed": {
+			allow:    true,
+			syncType: sync.ALL,
+			applied:  []string{"file"},
+		},
+		"additions without flags": {
+			syncType: sync.ADD,
+		},
+		"updates without flags": {
+			syncType: sync.UPDATE,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &emptyConfigRecorder{}
+			opts := []JSONEvaluatorOption{WithMetricsRecorder(recorder)}
+			if tt.allow {
+				opts = append(opts, WithAllowEmptyConfig())
+			}
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), opts...)
+			_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: oneFlag})
+			require.NoError(t, err)
+
+			_, _, err = evaluator.SetState(sync.DataSync{Source: "file", Type: tt.syncType, FlagData: noFlags})
+			assert.Equal(t, tt.applied, recorder.applied)
+			_, _, _, _, resolveErr := evaluator.ResolveBooleanValue(context.Background(), "req", "a", nil)
+			if tt.allow {
+				require.NoError(t, err)
+				require.EqualError(t, resolveErr, model.FlagNotFoundErrorCode, "the flags of the source are removed")
+				return
+			}
+			if tt.err {
+				require.ErrorIs(t, err, ErrEmptyConfig)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, resolveErr, "the last valid configuration is kept")
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+const InEnumEvaluationName = "in_enum"
+
+type InEnum struct {
+	Logger *logger.Logger
+}
+
+func NewInEnum(log *logger.Logger) *InEnum {
+	return &InEnum{Logger: log}
+}
+
+// InEnumEvaluation checks if a value is a member of an enum declared in the $enums of the configuration.
+// As an example, the plans of a product can be declared as:
+//
+//	"$enums": {
+//	  "plans": ["free", "pro", "enterprise"]
+//	}
+//
+// and used in the following way inside an 'if' evaluation:
+//
+//	{
+//	  "if": [{"in_enum": [{"var": "plan"}, "plans"]}, "known", "unknown"]
+//	}
+//
+// The enum name is replaced by the set of its members when the flag definition is loaded, so that the membership
+// check is a single map access, referencing an undeclared enum fails the load. Members are compared by type and value
+// like the elements of the intersects operation, e.g. the number 1 isn't a member of ["1"].
+func (e *InEnum) InEnumEvaluation(values, _ interface{}) interface{} {
+	args, ok := values.([]any)
+	if !ok || len(args) != 2 {
+		e.Logger.Error(fmt.Sprintf("parse in_enum evaluation data: expected a value and an enum, got %v", values))
+		return false
+	}
+	enum, ok := args[1].([]any)
+	if !ok || len(enum) != 1 {
+		e.Logger.Error(fmt.Sprintf("parse in_enum evaluation data: unresolved enum %v", args[1]))
+		return false
+	}
+	members, ok := enum[0].(map[string]any)
+	if !ok {
+		e.Logger.Error(fmt.Sprintf("parse in_enum evaluation data: unresolved enum %v", args[1]))
+		return false
+	}
+
+	key, ok := intersectsKey(args[0])
+	if !ok {
+		return false
+	}
+	_, ok = members[key]
+	return ok
+}
+
+// enumSets returns the declared enums as sets of the keys of their members, failing for members which aren't
+// strings, numbers or booleans
+func enumSets(enums map[string][]any) (map[string]map[string]any, error) {
+	sets := make(map[string]map[string]any, len(enums))
+	for name, members := range enums {
+		set := make(map[string]any, len(members))
+		for _, member := range members {
+			key, ok := intersectsKey(member)
+			if !ok {
+				return nil, fmt.Errorf("invalid member of enum '%s': expected a string, number or boolean but got %v",
+					name, member)
+			}
+			set[key] = true
+		}
+		sets[name] = set
+	}
+	return sets, nil
+}
+
+// enumOperation returns the arguments of an in_enum operation with its enum name replaced by the [members] array of
+// the enum. The value operand is returned as is, so that nested rules are resolved by the caller.
+func enumOperation(args any, sets map[string]map[string]any) ([]any, error) {
+	list, ok := args.([]any)
+	if !ok || len(list) != 2 {
+		return nil, errors.New("in_enum expects a value and an enum name")
+	}
+	name, ok := list[1].(string)
+	if !ok {
+		return nil, errors.New("in_enum enum name must be a string")
+	}
+	set, ok := sets[name]
+	if !ok {
+		return nil, fmt.Errorf("enum '%s' is not declared", name)
+	}
+	return []any{list[0], []any{set}}, nil
+}
@@ -0,0 +1,163 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const enumConfig = `{
+	"$enums": {
+		"plans": ["free", "pro", "enterprise", 1]
+	},
+	"flags": {
+		"plan": {
+			"state": "ENABLED",
+			"variants": {"known": "known", "unknown": "unknown"},
+			"defaultVariant": "unknown",
+			"targeting": {"if": [{"in_enum": [{"var": "plan"}, "plans"]}, "known", "unknown"]}
+		}
+	}
+}`
+
+func TestInEnumEvaluation(t *testing.T) {
+	enum := []any{map[string]any{"s:free": true, "n:1": true, "b:true": true}}
+
+	tests := map[string]struct {
+		values   any
+		expected bool
+	}{
+		"string member":     {values: []any{"free", enum}, expected: true},
+		"number member":     {values: []any{float64(1), enum}, expected: true},
+		"json number":       {values: []any{json.Number("1.0"), enum}, expected: true},
+		"boolean member":    {values: []any{true, enum}, expected: true},
+		"no member":         {values: []any{"team", enum}},
+		"type mismatch":     {values: []any{"1", enum}},
+		"null value":        {values: []any{nil, enum}},
+		"array value":       {values: []any{[]any{"free"}, enum}},
+		"unresolved enum":   {values: []any{"free", "plans"}},
+		"missing enum name": {values: []any{"free"}},
+	}
+
+	inEnum := NewInEnum(logger.NewLogger(nil, false))
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, inEnum.InEnumEvaluation(tt.values, nil))
+		})
+	}
+}
+
+func TestCompileEnums(t *testing.T) {
+	enums := map[string][]any{"plans": {"free", float64(2), true}}
+
+	tests := map[string]struct {
+		targeting string
+		enums     map[string][]any
+		expected  string
+		err       string
+	}{
+		"resolved enum": {
+			targeting: `{"in_enum": [{"var": "plan"}, "plans"]}`,
+			expected:  `{"in_enum": [{"var": "plan"}, [{"s:free": true, "n:2": true, "b:true": true}]]}`,
+		},
+		"nested in_enum": {
+			targeting: `{"if": [{"in_enum": [{"var": "plan"}, "plans"]}, "on", "off"]}`,
+			expected:  `{"if": [{"in_enum": [{"var": "plan"}, [{"s:free": true, "n:2": true, "b:true": true}]]}, "on", "off"]}`,
+		},
+		"without in_enum": {
+			targeting: `{"in": [{"var": "plan"}, ["free", "pro"]]}`,
+			expected:  `{"in": [{"var": "plan"}, ["free", "pro"]]}`,
+		},
+		"undeclared enum": {
+			targeting: `{"in_enum": [{"var": "plan"}, "tiers"]}`,
+			err:       "invalid targeting of flag: 'flag': enum 'tiers' is not declared",
+		},
+		"enum name isn't a string": {
+			targeting: `{"in_enum": [{"var": "plan"}, 1]}`,
+			err:       "invalid targeting of flag: 'flag': in_enum enum name must be a string",
+		},
+		"missing enum name": {
+			targeting: `{"in_enum": [{"var": "plan"}]}`,
+			err:       "invalid targeting of flag: 'flag': in_enum expects a value and an enum name",
+		},
+		"invalid member": {
+			targeting: `{"in_enum": [{"var": "plan"}, "plans"]}`,
+			enums:     map[string][]any{"plans": {"free", map[string]any{}}},
+			err:       "invalid member of enum 'plans': expected a string, number or boolean but got map[]",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			declared := enums
+			if tt.enums != nil {
+				declared = tt.enums
+			}
+			flags := &Flags{
+				Flags: map[string]model.Flag{"flag": {Targeting: json.RawMessage(tt.targeting)}},
+				enums: declared,
+			}
+			err := compileTargeting(logger.NewLogger(nil, false), flags, false)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(evaluatedTargeting(flags.Flags["flag"])))
+			// the targeting is kept as written
+			assert.JSONEq(t, tt.targeting, string(flags.Flags["flag"].Targeting))
+		})
+	}
+}
+
+func TestInEnumFlag(t *testing.T) {
+	for name, options := range map[string][]JSONEvaluatorOption{
+		"float64 numbers": nil,
+		"json numbers":    {WithJSONNumbers()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), options...)
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: enumConfig})
+			require.NoError(t, err)
+
+			for _, tt := range []struct {
+				context  map[string]any
+				expected string
+			}{
+				{context: map[string]any{"plan": "pro"}, expected: "known"},
+				{context: map[string]any{"plan": 1}, expected: "known"},
+				{context: map[string]any{"plan": "team"}, expected: "unknown"},
+				{context: map[string]any{}, expected: "unknown"},
+			} {
+				value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", "plan",
+					tt.context)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, value, "context %v", tt.context)
+				assert.Equal(t, model.TargetingMatchReason, reason)
+			}
+		})
+	}
+}
+
+func TestInEnumUndeclaredEnum(t *testing.T) {
+	const config = `{
+		"flags": {
+			"plan": {
+				"state": "ENABLED",
+				"variants": {"known": "known", "unknown": "unknown"},
+				"defaultVariant": "unknown",
+				"targeting": {"if": [{"in_enum": [{"var": "plan"}, "plans"]}, "known", "unknown"]}
+			}
+		}
+	}`
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.ErrorContains(t, err, "enum 'plans' is not declared")
+}
@@ -0,0 +1,99 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+const ExistsEvaluationName = "exists"
+
+type Exists struct {
+	Logger *logger.Logger
+}
+
+func NewExists(log *logger.Logger) *Exists {
+	return &Exists{Logger: log}
+}
+
+// ExistsEvaluation checks if the given path of the evaluation context resolves to a present value, regardless of the
+// value. Unlike a 'var' lookup, it distinguishes missing properties from properties explicitly set to null.
+// As an example, it can be used in the following way inside an 'if' evaluation:
+//
+//	{
+//	  "if": [
+//			{
+//				"exists": {"var": "profile.address.zip"}
+//			},
+//			"known", "unknown"
+//			]
+//	}
+//
+// This rule can be applied to the following data object, where the evaluation will resolve to 'true':
+//
+// { "profile": { "address": { "zip": null } } }
+//
+// Path segments are separated by dots, numeric segments index arrays, e.g. 'addresses.0.zip'.
+// The 'var' operand is replaced by its path when the flag definition is loaded, as JsonLogic would otherwise resolve
+// it to its value before the operation is applied. The path can thus also be given directly, e.g. {"exists": "zip"}.
+func (e *Exists) ExistsEvaluation(values, data interface{}) interface{} {
+	var path string
+	switch v := values.(type) {
+	case string:
+		path = v
+	case float64:
+		path = strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		path = v.String()
+	default:
+		e.Logger.Error(fmt.Sprintf("parse exists evaluation data: path must be a string, got %v", values))
+		return false
+	}
+
+	if path == "" {
+		return data != nil
+	}
+	current := data
+	for _, segment := range strings.Split(path, ".") {
+		switch c := current.(type) {
+		case map[string]any:
+			value, ok := c[segment]
+			if !ok {
+				return false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(c) {
+				return false
+			}
+			current = c[index]
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// existsPath returns the path of the operand of an exists operation, given either as {"var": path}, as
+// {"var": [path, default]} or wrapped in a single element array
+func existsPath(args any) any {
+	if list, ok := args.([]any); ok && len(list) == 1 {
+		args = list[0]
+	}
+	operand, ok := args.(map[string]any)
+	if !ok || len(operand) != 1 {
+		return args
+	}
+	path, ok := operand["var"]
+	if !ok {
+		return args
+	}
+	if list, ok := path.([]any); ok && len(list) > 0 {
+		return list[0]
+	}
+	return path
+}
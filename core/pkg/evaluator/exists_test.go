@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExistsEvaluation(t *testing.T) {
+	data := map[string]any{
+		"profile": map[string]any{
+			"address": map[string]any{"zip": "12345", "street": nil},
+		},
+		"addresses": []any{
+			map[string]any{"zip": "12345"},
+			map[string]any{"zip": nil},
+		},
+		"tags":  []any{"beta"},
+		"empty": "",
+	}
+
+	tests := map[string]struct {
+		path     interface{}
+		expected bool
+	}{
+		"top level key":              {path: "empty", expected: true},
+		"nested key":                 {path: "profile.address.zip", expected: true},
+		"nested null":                {path: "profile.address.street", expected: true},
+		"missing nested key":         {path: "profile.address.city", expected: false},
+		"missing parent":             {path: "account.address.zip", expected: false},
+		"path through a value":       {path: "profile.address.zip.code", expected: false},
+		"array index":                {path: "addresses.0.zip", expected: true},
+		"null in array element":      {path: "addresses.1.zip", expected: true},
+		"array index out of range":   {path: "addresses.2.zip", expected: false},
+		"negative array index":       {path: "tags.-1", expected: false},
+		"non-numeric array index":    {path: "tags.first", expected: false},
+		"empty path":                 {path: "", expected: true},
+		"numeric path":               {path: float64(0), expected: false},
+		"numeric path as json value": {path: json.Number("1"), expected: false},
+		"invalid path":               {path: []interface{}{"profile"}, expected: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewExists(logger.NewLogger(nil, false))
+			assert.Equal(t, tt.expected, e.ExistsEvaluation(tt.path, data))
+		})
+	}
+
+	e := NewExists(logger.NewLogger(nil, false))
+	assert.Equal(t, true, e.ExistsEvaluation(float64(1), []any{"a", "b"}))
+	assert.Equal(t, false, e.ExistsEvaluation("", nil))
+}
+
+func TestExistsTargeting(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"zip": {
+				"state": "ENABLED",
+				"variants": {"known": "known", "unknown": "unknown"},
+				"defaultVariant": "unknown",
+				"targeting": {
+					"if": [{"exists": {"var": "profile.address.zip"}}, "known", "unknown"]
+				}
+			},
+			"firstAddress": {
+				"state": "ENABLED",
+				"variants": {"known": "known", "unknown": "unknown"},
+				"defaultVariant": "unknown",
+				"targeting": {
+					"if": [{"exists": [{"var": ["addresses.0", "fallback"]}]}, "known", "unknown"]
+				}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		flag     string
+		evalCtx  map[string]any
+		expected string
+	}{
+		"present value": {
+			flag:     "zip",
+			evalCtx:  map[string]any{"profile": map[string]any{"address": map[string]any{"zip": "12345"}}},
+			expected: "known",
+		},
+		"explicit null": {
+			flag:     "zip",
+			evalCtx:  map[string]any{"profile": map[string]any{"address": map[string]any{"zip": nil}}},
+			expected: "known",
+		},
+		"missing value": {
+			flag:     "zip",
+			evalCtx:  map[string]any{"profile": map[string]any{"address": map[string]any{}}},
+			expected: "unknown",
+		},
+		"missing parent": {
+			flag:     "zip",
+			expected: "unknown",
+		},
+		"array element": {
+			flag:     "firstAddress",
+			evalCtx:  map[string]any{"addresses": []any{nil}},
+			expected: "known",
+		},
+		"empty array": {
+			flag:     "firstAddress",
+			evalCtx:  map[string]any{"addresses": []any{}},
+			expected: "unknown",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "", tt.flag, tt.evalCtx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestCompileExistsRules(t *testing.T) {
+	const targeting = `{"if": [{"and": [{"exists": {"var": "a.b"}}, {"<": [{"var": "n"}, 1.50]}]}, "on", "off"]}`
+	flags := Flags{Flags: map[string]model.Flag{
+		"exists": {Targeting: json.RawMessage(targeting)},
+		"var":    {Targeting: json.RawMessage(`{"if": [{"var": "exists"}, "on", "off"]}`)},
+	}}
+
+	require.NoError(t, compileTargeting(logger.NewLogger(nil, false), &flags, false))
+	compiled := string(evaluatedTargeting(flags.Flags["exists"]))
+	assert.JSONEq(t, `{"if": [{"and": [{"exists": "a.b"}, {"<": [{"var": "n"}, 1.50]}]}, "on", "off"]}`, compiled)
+	// numbers keep their literal representation
+	assert.Contains(t, compiled, "1.50")
+	assert.JSONEq(t, targeting, string(flags.Flags["exists"].Targeting))
+	assert.JSONEq(t, `{"if": [{"var": "exists"}, "on", "off"]}`, string(evaluatedTargeting(flags.Flags["var"])))
+}
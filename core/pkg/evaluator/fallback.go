@@ -0,0 +1,52 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// defaultOnTargetingError reports whether a flag falls back to its default variant if its targeting fails. The
+// DefaultOnTargetingErrorMetadataKey of the flag or flag set metadata takes precedence over the default, invalid
+// metadata is ignored.
+func defaultOnTargetingError(metadata map[string]interface{}, defaultFallback bool) (bool, error) {
+	value, ok := metadata[DefaultOnTargetingErrorMetadataKey]
+	if !ok {
+		return defaultFallback, nil
+	}
+	fallback, ok := value.(bool)
+	if !ok {
+		return defaultFallback, fmt.Errorf("expected a boolean but got %v", value)
+	}
+	return fallback, nil
+}
+
+// warnInvalidDefaultOnTargetingError warns about flags with an invalid DefaultOnTargetingErrorMetadataKey, which is
+// ignored when their targeting fails
+func warnInvalidDefaultOnTargetingError(log *logger.Logger, flags *Flags) {
+	for key, flag := range flags.Flags {
+		if _, err := defaultOnTargetingError(flag.Metadata, false); err != nil {
+			log.Warn(fmt.Sprintf("ignoring invalid %s metadata of flag %s: %v", DefaultOnTargetingErrorMetadataKey,
+				key, err))
+		}
+	}
+}
+
+// targetingError returns the result of a flag whose targeting failed with the given error code. Flags falling back
+// on targeting errors resolve to their default variant instead, still with an ERROR reason and with the error code
+// added to the metadata, so that clients unable to handle errors receive a usable value.
+func (je *Resolver) targetingError(reqID string, flagKey string, flag model.Flag, metadata map[string]interface{},
+	code string,
+) (string, map[string]interface{}, string, map[string]interface{}, error) {
+	fallback, _ := defaultOnTargetingError(flag.Metadata, je.defaultOnError)
+	if flag.DefaultVariant == "" || !fallback {
+		return "", flag.Variants, model.ErrorReason, metadata, errors.New(code)
+	}
+
+	je.Logger.DebugWithID(reqID, fmt.Sprintf("targeting of flag %s failed with %s, falling back to the default "+
+		"variant %s", flagKey, code, flag.DefaultVariant))
+	metadata[ErrorCodeMetadataKey] = code
+	return flag.DefaultVariant, flag.Variants, model.ErrorReason, metadata, nil
+}
@@ -0,0 +1,116 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDefaultOnTargetingError(t *testing.T) {
+	const config = `{
+		"flags": {
+			"fallback": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"if": [{"var": "color"}, {"var": "color"}, "blue"]},
+				"metadata": {"defaultOnTargetingError": true}
+			},
+			"no-fallback": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"if": [{"var": "color"}, {"var": "color"}, "blue"]},
+				"metadata": {"defaultOnTargetingError": false}
+			},
+			"default": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"if": [{"var": "color"}, {"var": "color"}, "blue"]}
+			}
+		}
+	}`
+
+	tests := map[string]struct {
+		opts     []JSONEvaluatorOption
+		fallback map[string]bool
+	}{
+		"disabled by default": {
+			fallback: map[string]bool{"fallback": true, "no-fallback": false, "default": false},
+		},
+		"enabled globally": {
+			opts:     []JSONEvaluatorOption{WithDefaultOnTargetingError()},
+			fallback: map[string]bool{"fallback": true, "no-fallback": false, "default": true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), tt.opts...)
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+			require.NoError(t, err)
+
+			for key, fallback := range tt.fallback {
+				// valid targeting results are unaffected
+				value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", key, nil)
+				require.NoError(t, err, key)
+				assert.Equal(t, "blue", value, key)
+				assert.Equal(t, model.TargetingMatchReason, reason, key)
+
+				// the targeting returns a variant which is not defined
+				value, variant, reason, metadata, err := evaluator.ResolveStringValue(context.Background(), "req", key,
+					map[string]any{"color": "green"})
+				assert.Equal(t, model.ErrorReason, reason, key)
+				if fallback {
+					require.NoError(t, err, key)
+					assert.Equal(t, "red", value, key)
+					assert.Equal(t, "red", variant, key)
+					assert.Equal(t, model.ParseErrorCode, metadata[ErrorCodeMetadataKey], key)
+				} else {
+					require.EqualError(t, err, model.ParseErrorCode, key)
+					assert.Empty(t, value, key)
+					assert.NotContains(t, metadata, ErrorCodeMetadataKey, key)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidDefaultOnTargetingError(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	evaluator := NewJSON(logger.NewLogger(zap.New(core), false), store.NewFlags(), WithDefaultOnTargetingError())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"invalid": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"if": [{"var": "color"}, {"var": "color"}, "blue"]},
+				"metadata": {"defaultOnTargetingError": "yes"}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+	require.Equal(t, 1, logs.FilterMessageSnippet("ignoring invalid defaultOnTargetingError metadata").Len())
+
+	// the invalid metadata is ignored in favor of the default, without warning on each evaluation
+	for range 2 {
+		value, _, reason, metadata, err := evaluator.ResolveStringValue(context.Background(), "req", "invalid",
+			map[string]any{"color": "green"})
+		require.NoError(t, err)
+		assert.Equal(t, "red", value)
+		assert.Equal(t, model.ErrorReason, reason)
+		assert.Equal(t, model.ParseErrorCode, metadata[ErrorCodeMetadataKey])
+	}
+	assert.Equal(t, 1, logs.Len())
+}
@@ -0,0 +1,13 @@
+package evaluator
+
+// FallbackConfigMetadataKey is the returned metadata key marking the evaluations of flags of the fallback
+// configuration, which is served until a sync source delivers a configuration
+const FallbackConfigMetadataKey = "fallbackConfig"
+
+// WithFallbackConfigSource marks the evaluations of the flags of the source with the FallbackConfigMetadataKey, so
+// that clients can tell that the fallback configuration is served instead of the configuration of a sync source
+func WithFallbackConfigSource(source string) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.fallbackConfigSource = source
+	}
+}
@@ -0,0 +1,48 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFallbackConfigSource(t *testing.T) {
+	const fallbackConfig = `{
+		"flags": {
+			"color": {"state": "ENABLED", "variants": {"red": "red", "blue": "blue"}, "defaultVariant": "red"},
+			"shape": {"state": "ENABLED", "variants": {"round": "round"}, "defaultVariant": "round"}
+		}
+	}`
+	const liveConfig = `{
+		"flags": {
+			"color": {"state": "ENABLED", "variants": {"red": "red", "blue": "blue"}, "defaultVariant": "blue"}
+		}
+	}`
+
+	s := store.NewFlags()
+	s.FlagSources = []string{"fallback", "live"}
+	evaluator := NewJSON(logger.NewLogger(nil, false), s, WithFallbackConfigSource("fallback"))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: fallbackConfig, Source: "fallback", Type: sync.ALL})
+	require.NoError(t, err)
+	value, _, _, metadata, err := evaluator.ResolveStringValue(context.Background(), "req", "color", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "red", value)
+	assert.Equal(t, true, metadata[FallbackConfigMetadataKey])
+
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: liveConfig, Source: "live", Type: sync.ALL})
+	require.NoError(t, err)
+	value, _, _, metadata, err = evaluator.ResolveStringValue(context.Background(), "req", "color", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "blue", value, "the flags of sync sources override the fallback configuration")
+	assert.NotContains(t, metadata, FallbackConfigMetadataKey)
+
+	_, _, _, metadata, err = evaluator.ResolveStringValue(context.Background(), "req", "shape", nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, metadata[FallbackConfigMetadataKey])
+}
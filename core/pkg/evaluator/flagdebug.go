@@ -0,0 +1,130 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	gosync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// flagDebugLogsPerSecond bounds the evaluations of the debugged flag logged per second, so that debugging a hot flag
+// doesn't flood the logs
+const flagDebugLogsPerSecond = 10
+
+// WithFlagDebugger logs the evaluations of the flag debugged by the given debugger in detail
+func WithFlagDebugger(debugger *FlagDebugger) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.debugger = debugger
+	}
+}
+
+// FlagDebugStatus is the flag currently debugged and the time its debugging expires, the flag key is empty if no flag
+// is debugged
+type FlagDebugStatus struct {
+	FlagKey string    `json:"flagKey,omitempty"`
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// FlagDebugger logs the full evaluation context, targeting and result of the evaluations of a single flag key, so that
+// the evaluations of a misbehaving flag can be inspected at runtime. Debugging is enabled for a limited duration,
+// expiring on its own, and the logged evaluations are rate limited.
+type FlagDebugger struct {
+	mx       gosync.Mutex
+	duration time.Duration
+	flagKey  string
+	expires  time.Time
+	// window is the start of the second in which logged counts the logged evaluations
+	window time.Time
+	logged int
+	now    func() time.Time
+}
+
+// NewFlagDebugger returns a debugger whose debugging expires after the given duration at the latest
+func NewFlagDebugger(duration time.Duration) *FlagDebugger {
+	return &FlagDebugger{duration: duration, now: time.Now}
+}
+
+// Debug starts debugging the flag of the given key, replacing the flag debugged before. Debugging expires after the
+// given duration, which is bounded by the duration of the debugger and defaults to it.
+func (d *FlagDebugger) Debug(flagKey string, duration time.Duration) FlagDebugStatus {
+	if duration <= 0 || duration > d.duration {
+		duration = d.duration
+	}
+
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.flagKey = flagKey
+	d.expires = d.now().Add(duration)
+	d.logged = 0
+	return FlagDebugStatus{FlagKey: d.flagKey, Expires: d.expires}
+}
+
+// Stop stops debugging the debugged flag, if any
+func (d *FlagDebugger) Stop() {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.flagKey = ""
+}
+
+// Status returns the debugged flag, if its debugging didn't expire yet
+func (d *FlagDebugger) Status() FlagDebugStatus {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	if d.flagKey == "" || !d.now().Before(d.expires) {
+		return FlagDebugStatus{}
+	}
+	return FlagDebugStatus{FlagKey: d.flagKey, Expires: d.expires}
+}
+
+// admit reports whether an evaluation of the given flag is logged, which is the case for the debugged flag unless its
+// debugging expired or the evaluations logged in the current second exceed the rate limit
+func (d *FlagDebugger) admit(flagKey string) bool {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	if d.flagKey == "" || d.flagKey != flagKey {
+		return false
+	}
+	now := d.now()
+	if !now.Before(d.expires) {
+		d.flagKey = ""
+		return false
+	}
+	if now.Sub(d.window) >= time.Second {
+		d.window = now
+		d.logged = 0
+	}
+	if d.logged >= flagDebugLogsPerSecond {
+		return false
+	}
+	d.logged++
+	return true
+}
+
+// debugEvaluation logs an evaluation of the debugged flag with its redacted evaluation context and the targeting
+// rules it was evaluated by
+func (je *Resolver) debugEvaluation(ctx context.Context, reqID string, flagKey string, evalCtx map[string]any,
+	e evaluation,
+) {
+	var targeting json.RawMessage
+	if flag, _, _, ok := je.lookup(ctx, flagKey); ok {
+		targeting = flag.Targeting
+	}
+	fields := []zap.Field{
+		zap.String(logger.RequestIDFieldName, reqID),
+		zap.String("flagKey", flagKey),
+		zap.Any("context", je.redact(evalCtx)),
+		zap.String("targeting", string(targeting)),
+		zap.Any("value", e.variants[e.variant]),
+		zap.String("variant", e.variant),
+		zap.String("reason", e.reason),
+	}
+	if e.err != nil {
+		fields = append(fields, zap.Error(e.err))
+	}
+	// logged regardless of the request ID logging, as debugging is enabled explicitly
+	je.Logger.Info(fmt.Sprintf("debugged evaluation of flag %s", flagKey), fields...)
+}
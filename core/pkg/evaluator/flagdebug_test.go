@@ -0,0 +1,90 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const flagDebugConfig = `{
+	"flags": {
+		"debugged": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "off",
+			"targeting": {"if": [{"ends_with": [{"var": "email"}, "@example.com"]}, "on", "off"]}
+		},
+		"other": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "off"
+		}
+	}
+}`
+
+func TestFlagDebugger(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	debugger := NewFlagDebugger(time.Minute)
+	debugger.now = func() time.Time { return now }
+
+	assert.False(t, debugger.admit("flag"), "no flag is debugged")
+	assert.Equal(t, FlagDebugStatus{}, debugger.Status())
+
+	status := debugger.Debug("flag", time.Hour)
+	assert.Equal(t, FlagDebugStatus{FlagKey: "flag", Expires: now.Add(time.Minute)}, status,
+		"the duration is bounded by the duration of the debugger")
+	assert.Equal(t, status, debugger.Status())
+	assert.False(t, debugger.admit("other"), "only the debugged flag is logged")
+
+	for range flagDebugLogsPerSecond {
+		assert.True(t, debugger.admit("flag"))
+	}
+	assert.False(t, debugger.admit("flag"), "evaluations exceeding the rate limit are not logged")
+	now = now.Add(time.Second)
+	assert.True(t, debugger.admit("flag"), "the rate limit applies per second")
+
+	now = now.Add(time.Minute)
+	assert.False(t, debugger.admit("flag"), "debugging expires")
+	assert.Equal(t, FlagDebugStatus{}, debugger.Status())
+
+	debugger.Debug("flag", time.Second)
+	debugger.Stop()
+	assert.False(t, debugger.admit("flag"), "debugging is stopped")
+}
+
+func TestFlagDebuggerLogsEvaluations(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	debugger := NewFlagDebugger(time.Minute)
+	evaluator := NewJSON(logger.NewLogger(zap.New(core), false), store.NewFlags(),
+		WithContextRedactor(RedactKeys("email")), WithFlagDebugger(debugger))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: flagDebugConfig})
+	require.NoError(t, err)
+
+	evalCtx := map[string]any{"email": "user@example.com", "plan": "pro"}
+	debugger.Debug("debugged", 0)
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "debugged", evalCtx)
+	require.NoError(t, err)
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "other", evalCtx)
+	require.NoError(t, err)
+
+	entries := logs.FilterMessageSnippet("debugged evaluation").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "debugged", fields["flagKey"])
+	assert.Equal(t, map[string]any{"email": RedactedValue, "plan": "pro"}, fields["context"])
+	assert.JSONEq(t, `{"if": [{"ends_with": [{"var": "email"}, "@example.com"]}, "on", "off"]}`,
+		fields["targeting"].(string))
+	assert.Equal(t, true, fields["value"])
+	assert.Equal(t, "on", fields["variant"])
+	assert.Equal(t, "TARGETING_MATCH", fields["reason"])
+	assert.Equal(t, "user@example.com", evalCtx["email"], "the evaluation context isn't modified")
+}
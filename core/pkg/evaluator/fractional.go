@@ -1,18 +1,33 @@
 package evaluator
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"sync"
 
 	"github.com/open-feature/flagd/core/pkg/logger"
-	"github.com/twmb/murmur3"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"go.uber.org/zap"
 )
 
-const FractionEvaluationName = "fractional"
+const (
+	FractionEvaluationName = "fractional"
+	// FractionalMetricsMetadataKey is the flag or flag set metadata key enabling the metric of the buckets served by
+	// the fractional evaluations of a flag. It is opt-in, as the metric adds a series per flag and variant.
+	FractionalMetricsMetadataKey = "fractionalMetrics"
+)
 
 type Fractional struct {
 	Logger *logger.Logger
+	// buckets is set by the resolver to collect the served buckets of flags opting into fractional metrics
+	buckets *fractionalBuckets
+	// reasonPaths is set by the resolver to mark the evaluations split by a fractional operation
+	reasonPaths *reasonPaths
+	// rings caches the hash rings of flags opting into consistent bucketing
+	rings hashRings
 }
 
 type fractionalEvaluationDistribution struct {
@@ -38,27 +53,176 @@ func NewFractional(logger *logger.Logger) *Fractional {
 }
 
 func (fe *Fractional) Evaluate(values, data any) any {
-	valueToDistribute, feDistributions, err := parseFractionalEvaluationData(values, data)
+	valueToDistribute, feDistributions, settings, properties, err := parseFractionalEvaluationData(values, data)
 	if err != nil {
 		fe.Logger.Warn(fmt.Sprintf("parse fractional evaluation data: %v", err))
 		return nil
 	}
 
-	return distributeValue(valueToDistribute, feDistributions)
+	variant := fe.distribute(settings.bucketing, settings.hash, valueToDistribute, feDistributions)
+	if fe.Logger.Logger.Core().Enabled(zap.DebugLevel) {
+		fe.Logger.Debug("fractional evaluation", zap.String("flag-key", properties.FlagKey),
+			zap.String("bucketing", settings.bucketing), zap.String("hash", settings.hash),
+			zap.String("variant", variant))
+		fe.compareHashCandidate(properties.FlagKey, settings, valueToDistribute, variant, feDistributions)
+	}
+	fe.recordBucket(properties.EvaluationID, settings, variant, feDistributions)
+	if fe.reasonPaths != nil && variant != "" {
+		fe.reasonPaths.split(properties.EvaluationID)
+	}
+
+	return variant
+}
+
+// fractionalSettings are the settings of the fractional operations of a flag. They are read from the flag metadata
+// when the flag is loaded and compiled into its targeting, so that they aren't looked up by every evaluation.
+type fractionalSettings struct {
+	bucketing string
+	hash      string
+	// candidate is the hash algorithm the flag is migrated to, empty if the flag isn't migrated
+	candidate string
+	// metrics records the served buckets of the flag
+	metrics bool
+}
+
+// defaultFractionalSettings apply to fractional operations whose targeting isn't compiled
+var defaultFractionalSettings = fractionalSettings{
+	bucketing: FractionalBucketingModulo,
+	hash:      FractionalHashMurmur3,
+}
+
+// newFractionalSettings returns the settings of the fractional operations of a flag
+func newFractionalSettings(flag model.Flag) fractionalSettings {
+	settings := fractionalSettings{
+		bucketing: fractionalBucketing(flag),
+		hash:      fractionalHashAlgorithm(flag),
+	}
+	settings.candidate, _ = fractionalHashCandidate(flag, settings.hash)
+	settings.metrics, _ = flag.Metadata[FractionalMetricsMetadataKey].(bool)
+	return settings
+}
+
+// operand returns the settings as the [settings] operand prepended to the fractional operations by the compilation,
+// as JsonLogic would apply an object operand as rule. The object always holds more than one key, so that it isn't
+// taken for an operation either.
+func (s fractionalSettings) operand() []any {
+	return []any{map[string]any{
+		"bucketing": s.bucketing,
+		"hash":      s.hash,
+		"candidate": s.candidate,
+		"metrics":   s.metrics,
+	}}
+}
+
+// compiledFractionalSettings returns the settings compiled into the operands of a fractional operation along with the
+// remaining operands, or the default settings if the operation isn't compiled
+func compiledFractionalSettings(values []any) (fractionalSettings, []any) {
+	operand, ok := values[0].([]any)
+	if !ok || len(operand) != 1 {
+		return defaultFractionalSettings, values
+	}
+	compiled, ok := operand[0].(map[string]any)
+	if !ok {
+		return defaultFractionalSettings, values
+	}
+	settings := defaultFractionalSettings
+	if bucketing, ok := compiled["bucketing"].(string); ok {
+		settings.bucketing = bucketing
+	}
+	if hash, ok := compiled["hash"].(string); ok {
+		settings.hash = hash
+	}
+	settings.candidate, _ = compiled["candidate"].(string)
+	settings.metrics, _ = compiled["metrics"].(bool)
+	return settings, values[1:]
+}
+
+// fractionalBucketing returns the bucketing of the fractional evaluations of a flag, unknown bucketings fall back to
+// FractionalBucketingModulo
+func fractionalBucketing(flag model.Flag) string {
+	bucketing, _ := flag.Metadata[FractionalBucketingMetadataKey].(string)
+	if bucketing == FractionalBucketingConsistent {
+		return FractionalBucketingConsistent
+	}
+	return FractionalBucketingModulo
+}
+
+// recordBucket collects the served bucket along with its configured percentage for the evaluation with the given id,
+// if the flag opted into fractional metrics
+func (fe *Fractional) recordBucket(
+	id uint64, settings fractionalSettings, variant string, feDistribution *fractionalEvaluationDistribution,
+) {
+	if fe.buckets == nil || variant == "" || !settings.metrics {
+		return
+	}
+
+	for _, weightedVariant := range feDistribution.weightedVariants {
+		if weightedVariant.variant == variant {
+			fe.buckets.record(id, fractionalBucket{
+				variant:    variant,
+				percentage: weightedVariant.getPercentage(feDistribution.totalWeight),
+			})
+			return
+		}
+	}
+}
+
+// fractionalBucket is a bucket served by a fractional operation
+type fractionalBucket struct {
+	variant    string
+	percentage float64
+}
+
+// fractionalBuckets collects the buckets served to the in-flight evaluations of flags opting into fractional
+// metrics, which are recorded by the resolver evaluating the flag. Custom operators have no access to the evaluation
+// they are applied in, hence evaluations are identified by an id passed along in the $flagd properties.
+type fractionalBuckets struct {
+	served sync.Map
+}
+
+// start registers a new evaluation with the given id
+func (b *fractionalBuckets) start(id uint64) {
+	b.served.Store(id, []fractionalBucket(nil))
+}
+
+// record adds a bucket served to the evaluation with the given id. The operations of an evaluation are applied in
+// sequence, hence the buckets of an id aren't recorded concurrently.
+func (b *fractionalBuckets) record(id uint64, bucket fractionalBucket) {
+	if id == 0 {
+		return
+	}
+	if served, ok := b.served.Load(id); ok {
+		b.served.Store(id, append(served.([]fractionalBucket), bucket))
+	}
+}
+
+// end unregisters the evaluation with the given id, returning the buckets served to it
+func (b *fractionalBuckets) end(id uint64) []fractionalBucket {
+	served, _ := b.served.LoadAndDelete(id)
+	buckets, _ := served.([]fractionalBucket)
+	return buckets
 }
 
-func parseFractionalEvaluationData(values, data any) (string, *fractionalEvaluationDistribution, error) {
+func parseFractionalEvaluationData(values, data any) (
+	string, *fractionalEvaluationDistribution, fractionalSettings, flagdProperties, error,
+) {
 	valuesArray, ok := values.([]any)
 	if !ok {
-		return "", nil, errors.New("fractional evaluation data is not an array")
+		return "", nil, fractionalSettings{}, flagdProperties{},
+			errors.New("fractional evaluation data is not an array")
+	}
+	var settings fractionalSettings
+	if len(valuesArray) > 0 {
+		settings, valuesArray = compiledFractionalSettings(valuesArray)
 	}
 	if len(valuesArray) < 2 {
-		return "", nil, errors.New("fractional evaluation data has length under 2")
+		return "", nil, fractionalSettings{}, flagdProperties{},
+			errors.New("fractional evaluation data has length under 2")
 	}
 
 	dataMap, ok := data.(map[string]any)
 	if !ok {
-		return "", nil, errors.New("data isn't of type map[string]any")
+		return "", nil, fractionalSettings{}, flagdProperties{}, errors.New("data isn't of type map[string]any")
 	}
 
 	// Ignore the error as we can't really do anything if the properties are
@@ -76,7 +240,8 @@ func parseFractionalEvaluationData(values, data any) (string, *fractionalEvaluat
 
 		targetingKey, ok := dataMap[targetingKeyKey].(string)
 		if !ok {
-			return "", nil, errors.New("bucketing value not supplied and no targetingKey in context")
+			return "", nil, fractionalSettings{}, flagdProperties{},
+				errors.New("bucketing value not supplied and no targetingKey in context")
 		}
 
 		bucketBy = fmt.Sprintf("%s%s", properties.FlagKey, targetingKey)
@@ -84,10 +249,10 @@ func parseFractionalEvaluationData(values, data any) (string, *fractionalEvaluat
 
 	feDistributions, err := parseFractionalEvaluationDistributions(valuesArray)
 	if err != nil {
-		return "", nil, err
+		return "", nil, fractionalSettings{}, flagdProperties{}, err
 	}
 
-	return bucketBy, feDistributions, nil
+	return bucketBy, feDistributions, settings, properties, nil
 }
 
 func parseFractionalEvaluationDistributions(values []any) (*fractionalEvaluationDistribution, error) {
@@ -111,13 +276,14 @@ func parseFractionalEvaluationDistributions(values []any) (*fractionalEvaluation
 			return nil, errors.New("first element of distribution element isn't string")
 		}
 
+		// default the weight to 1 if not specified explicitly
 		weight := 1.0
 		if len(distributionArray) >= 2 {
-			distributionWeight, ok := distributionArray[1].(float64)
-			if ok {
-				// default the weight to 1 if not specified explicitly
-				weight = distributionWeight
+			distributionWeight, err := fractionalWeight(variant, distributionArray[1])
+			if err != nil {
+				return nil, err
 			}
+			weight = distributionWeight
 		}
 
 		feDistributions.totalWeight += int(weight)
@@ -127,12 +293,114 @@ func parseFractionalEvaluationDistributions(values []any) (*fractionalEvaluation
 		}
 	}
 
+	if feDistributions.totalWeight <= 0 {
+		return nil, errors.New("total weight of the fractional distribution must be positive")
+	}
+
 	return feDistributions, nil
 }
 
-// distributeValue calculate hash for given hash key and find the bucket distributions belongs to
-func distributeValue(value string, feDistribution *fractionalEvaluationDistribution) string {
-	hashValue := int32(murmur3.StringSum32(value))
+// fractionalWeight returns the weight of a variant of a fractional distribution, which must be a number that isn't
+// negative. Weights are relative to the total weight of the distribution, their fractional part is truncated.
+func fractionalWeight(variant string, value any) (float64, error) {
+	weight, ok := value.(float64)
+	if !ok {
+		return 0, fmt.Errorf("weight of variant '%s' is not a number: %v", variant, value)
+	}
+	if weight < 0 {
+		return 0, fmt.Errorf("weight of variant '%s' is negative: %v", variant, weight)
+	}
+	return weight, nil
+}
+
+// validateFractionalWeights returns an error if a fractional operation of a flag defines a weight which isn't a
+// number, a negative weight or a distribution with a total weight of zero. Weights computed by rules are validated
+// at evaluation time.
+func validateFractionalWeights(flags *Flags) error {
+	for name, flag := range flags.Flags {
+		if !bytes.Contains(flag.Targeting, []byte(`"`+FractionEvaluationName+`"`)) {
+			continue
+		}
+
+		var rule any
+		if err := json.Unmarshal(flag.Targeting, &rule); err != nil {
+			// parsing errors are reported at evaluation time
+			continue
+		}
+		if err := validateFractionalRules(rule); err != nil {
+			return fmt.Errorf("invalid targeting of flag: '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateFractionalRules(rule any) error {
+	switch r := rule.(type) {
+	case []any:
+		for _, item := range r {
+			if err := validateFractionalRules(item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for operator, args := range r {
+			if operator == FractionEvaluationName {
+				if err := validateFractionalDistribution(args); err != nil {
+					return err
+				}
+			}
+			if err := validateFractionalRules(args); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateFractionalDistribution(args any) error {
+	values, ok := args.([]any)
+	if !ok || len(values) == 0 {
+		// malformed operations are reported at evaluation time
+		return nil
+	}
+	if _, ok := values[0].([]any); !ok {
+		// the bucketing value
+		values = values[1:]
+	}
+
+	totalWeight := 0
+	for _, value := range values {
+		distribution, ok := value.([]any)
+		if !ok || len(distribution) == 0 {
+			return nil
+		}
+		variant, _ := distribution[0].(string)
+		if len(distribution) < 2 {
+			totalWeight++
+			continue
+		}
+		if _, ok := distribution[1].(map[string]any); ok {
+			// the weight is computed by a rule
+			return nil
+		}
+		weight, err := fractionalWeight(variant, distribution[1])
+		if err != nil {
+			return err
+		}
+		totalWeight += int(weight)
+	}
+	if len(values) > 0 && totalWeight == 0 {
+		return errors.New("total weight of the fractional distribution must be positive")
+	}
+
+	return nil
+}
+
+// distributeValue finds the bucket the hash of a bucketing value belongs to
+func distributeValue(hash uint32, feDistribution *fractionalEvaluationDistribution) string {
+	hashValue := int32(hash)
 	hashRatio := math.Abs(float64(hashValue)) / math.MaxInt32
 	bucket := hashRatio * 100 // in range [0, 100]
 
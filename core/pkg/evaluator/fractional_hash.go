@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"hash/fnv"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/twmb/murmur3"
+	"go.uber.org/zap"
+)
+
+const (
+	// FractionalHashMetadataKey is the flag or flag set metadata key selecting the hash algorithm of the bucketing
+	// values of the fractional evaluations of a flag, either FractionalHashMurmur3 (default) or FractionalHashFNV1a
+	FractionalHashMetadataKey = "fractionalHash"
+	// FractionalHashMigrationMetadataKey is the flag or flag set metadata key naming a candidate hash algorithm. The
+	// fractional evaluations of the flag are also bucketed by the candidate, and buckets diverging from the served
+	// ones are logged at debug level, so that the candidate can be verified before switching to it.
+	FractionalHashMigrationMetadataKey = "fractionalHashMigration"
+	// FractionalHashMurmur3 hashes bucketing values with the 32-bit murmur3 hash
+	FractionalHashMurmur3 = "murmur3"
+	// FractionalHashFNV1a hashes bucketing values with the 32-bit FNV-1a hash
+	FractionalHashFNV1a = "fnv1a"
+)
+
+// fractionalHash hashes the bucketing value of a fractional evaluation
+type fractionalHash func(value string) uint32
+
+// fractionalHashes are the supported hash algorithms of bucketing values
+var fractionalHashes = map[string]fractionalHash{
+	FractionalHashMurmur3: murmur3.StringSum32,
+	FractionalHashFNV1a:   fnv1a32,
+}
+
+func fnv1a32(value string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(value))
+	return h.Sum32()
+}
+
+// fractionalHashAlgorithm returns the hash algorithm of the fractional evaluations of a flag, unknown algorithms fall
+// back to FractionalHashMurmur3
+func fractionalHashAlgorithm(flag model.Flag) string {
+	algorithm, _ := flag.Metadata[FractionalHashMetadataKey].(string)
+	if _, ok := fractionalHashes[algorithm]; ok {
+		return algorithm
+	}
+	return FractionalHashMurmur3
+}
+
+// fractionalHashCandidate returns the candidate hash algorithm a flag is migrated to, if it names a supported
+// algorithm other than the served one
+func fractionalHashCandidate(flag model.Flag, served string) (string, bool) {
+	candidate, _ := flag.Metadata[FractionalHashMigrationMetadataKey].(string)
+	if _, ok := fractionalHashes[candidate]; !ok || candidate == served {
+		return "", false
+	}
+	return candidate, true
+}
+
+// distribute returns the variant of a bucketing value for the bucketing and hash algorithm
+func (fe *Fractional) distribute(
+	bucketing, algorithm, value string, feDistribution *fractionalEvaluationDistribution,
+) string {
+	hash := fractionalHashes[algorithm]
+	if bucketing == FractionalBucketingConsistent {
+		return fe.rings.distribute(hash(value), feDistribution)
+	}
+	return distributeValue(hash(value), feDistribution)
+}
+
+// compareHashCandidate buckets a value by the candidate hash algorithm of a flag and logs the candidate's variant if
+// it diverges from the served variant. It is only called if debug logs are enabled.
+func (fe *Fractional) compareHashCandidate(
+	flagKey string, settings fractionalSettings, value, variant string,
+	feDistribution *fractionalEvaluationDistribution,
+) {
+	if settings.candidate == "" {
+		return
+	}
+	candidateVariant := fe.distribute(settings.bucketing, settings.candidate, value, feDistribution)
+	if candidateVariant != variant {
+		fe.Logger.Debug("fractional hash migration diverges", zap.String("flag-key", flagKey),
+			zap.String("hash", settings.hash), zap.String("variant", variant),
+			zap.String("candidate-hash", settings.candidate), zap.String("candidate-variant", candidateVariant))
+	}
+}
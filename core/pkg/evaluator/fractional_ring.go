@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	gosync "sync"
+
+	"github.com/twmb/murmur3"
+)
+
+const (
+	// FractionalBucketingMetadataKey is the flag or flag set metadata key selecting the bucketing of the fractional
+	// evaluations of a flag, either FractionalBucketingModulo (default) or FractionalBucketingConsistent
+	FractionalBucketingMetadataKey = "fractionalBucketing"
+	// FractionalBucketingModulo assigns the hash of the bucketing value to the consecutive ranges of the variants.
+	// Changing a weight shifts the ranges of all following variants.
+	FractionalBucketingModulo = "modulo"
+	// FractionalBucketingConsistent assigns the hash of the bucketing value to the closest point of a hash ring, on
+	// which each variant owns a number of points proportional to its weight. Changing a weight only adds or removes
+	// points of the changed variants, so that only bucketing values of these points are reassigned.
+	FractionalBucketingConsistent = "consistent"
+
+	// ringPoints is the number of points of a hash ring, bounding the resolution of the weights to 0.1%
+	ringPoints = 1000
+	// maxCachedRings bounds the hash rings cached per distinct distribution
+	maxCachedRings = 1024
+)
+
+// ringPoint is a point of a hash ring owned by a variant
+type ringPoint struct {
+	hash    uint32
+	variant string
+}
+
+// hashRings caches the hash rings of distributions, as building a ring hashes all of its points
+type hashRings struct {
+	mu    gosync.RWMutex
+	rings map[string][]ringPoint
+}
+
+// distribute returns the variant owning the first point of the hash ring of the distribution following the hash of
+// a bucketing value. The points are placed by murmur3 regardless of the hash algorithm of the bucketing values.
+func (r *hashRings) distribute(hash uint32, feDistribution *fractionalEvaluationDistribution) string {
+	ring := r.ring(feDistribution)
+	if len(ring) == 0 {
+		return ""
+	}
+
+	i := sort.Search(len(ring), func(i int) bool { return ring[i].hash >= hash })
+	if i == len(ring) {
+		// wrap around the ring
+		i = 0
+	}
+	return ring[i].variant
+}
+
+// ring returns the cached hash ring of a distribution, building it if it isn't cached yet
+func (r *hashRings) ring(feDistribution *fractionalEvaluationDistribution) []ringPoint {
+	key := ringKey(feDistribution)
+
+	r.mu.RLock()
+	ring, ok := r.rings[key]
+	r.mu.RUnlock()
+	if ok {
+		return ring
+	}
+
+	ring = buildRing(feDistribution)
+	r.mu.Lock()
+	if r.rings == nil || len(r.rings) >= maxCachedRings {
+		// distributions with computed weights may be unbounded, start over instead of growing without limit
+		r.rings = map[string][]ringPoint{}
+	}
+	r.rings[key] = ring
+	r.mu.Unlock()
+	return ring
+}
+
+// ringKey identifies the hash ring of a distribution by its variants and weights
+func ringKey(feDistribution *fractionalEvaluationDistribution) string {
+	var key strings.Builder
+	for _, weightedVariant := range feDistribution.weightedVariants {
+		key.WriteString(strconv.Quote(weightedVariant.variant))
+		key.WriteByte(':')
+		key.WriteString(strconv.Itoa(weightedVariant.weight))
+		key.WriteByte(',')
+	}
+	return key.String()
+}
+
+// buildRing places the points of each variant of a distribution on a hash ring, sorted by hash. The points of a
+// variant are identified by the variant and their index, so that they are stable across weight changes.
+func buildRing(feDistribution *fractionalEvaluationDistribution) []ringPoint {
+	ring := make([]ringPoint, 0, ringPoints)
+	for _, weightedVariant := range feDistribution.weightedVariants {
+		points := int(math.Round(ringPoints * weightedVariant.getPercentage(feDistribution.totalWeight) / 100))
+		for i := range points {
+			ring = append(ring, ringPoint{
+				hash:    murmur3.StringSum32(weightedVariant.variant + "#" + strconv.Itoa(i)),
+				variant: weightedVariant.variant,
+			})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool {
+		if ring[i].hash != ring[j].hash {
+			return ring[i].hash < ring[j].hash
+		}
+		return ring[i].variant < ring[j].variant
+	})
+	return ring
+}
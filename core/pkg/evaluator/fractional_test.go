@@ -2,14 +2,36 @@ package evaluator
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
 	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/murmur3"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
+type bucketRecorder struct {
+	telemetry.NoopMetricsRecorder
+	buckets map[string]map[string]float64
+	counts  map[string]int
+}
+
+func (r *bucketRecorder) FractionalBucket(_ context.Context, key, variant string, percentage float64) {
+	if r.buckets[key] == nil {
+		r.buckets[key] = map[string]float64{}
+	}
+	r.buckets[key][variant] = percentage
+	r.counts[key]++
+}
+
 func TestFractionalEvaluation(t *testing.T) {
 	ctx := context.Background()
 
@@ -660,3 +682,342 @@ func Test_fractionalEvaluationVariant_getPercentage(t *testing.T) {
 		})
 	}
 }
+
+func TestFractionalBucketMetrics(t *testing.T) {
+	recorder := &bucketRecorder{buckets: map[string]map[string]float64{}, counts: map[string]int{}}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"opted-in": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"fractional": [["red", 1], ["blue", 3]]},
+				"metadata": {"fractionalMetrics": true}
+			},
+			"not-opted-in": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"fractional": [["red", 50], ["blue", 50]]}
+			},
+			"opted-out": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"fractional": [["red", 50], ["blue", 50]]},
+				"metadata": {"fractionalMetrics": false}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	users := []string{"alice", "bob", "carol", "dave", "eve", "frank", "grace", "heidi"}
+	for _, key := range []string{"opted-in", "not-opted-in", "opted-out"} {
+		for _, user := range users {
+			_, _, _, _, err := evaluator.ResolveStringValue(
+				context.Background(), "", key, map[string]any{"targetingKey": user})
+			require.NoError(t, err)
+		}
+	}
+
+	assert.Equal(t, len(users), recorder.counts["opted-in"])
+	for variant, percentage := range recorder.buckets["opted-in"] {
+		assert.Equal(t, map[string]float64{"red": 25, "blue": 75}[variant], percentage)
+	}
+	assert.NotContains(t, recorder.counts, "not-opted-in")
+	assert.NotContains(t, recorder.counts, "opted-out")
+}
+
+func TestFractionalWeightedVariants(t *testing.T) {
+	distribution := func(weights ...int) *fractionalEvaluationDistribution {
+		d := &fractionalEvaluationDistribution{}
+		for i, weight := range weights {
+			d.totalWeight += weight
+			d.weightedVariants = append(d.weightedVariants,
+				fractionalEvaluationVariant{variant: string(rune('a' + i)), weight: weight})
+		}
+		return d
+	}
+	assign := func(d *fractionalEvaluationDistribution, n int) []string {
+		variants := make([]string, n)
+		for i := range variants {
+			variants[i] = distributeValue(murmur3.StringSum32(fmt.Sprintf("flag-user-%d", i)), d)
+		}
+		return variants
+	}
+	const n = 20000
+
+	// weights are normalized by their total
+	counts := map[string]int{}
+	for _, variant := range assign(distribution(14, 4, 1, 1), n) {
+		counts[variant]++
+	}
+	for variant, expected := range map[string]float64{"a": 0.7, "b": 0.2, "c": 0.05, "d": 0.05} {
+		assert.InDelta(t, expected, float64(counts[variant])/n, 0.01, variant)
+	}
+
+	// moving weight between adjacent variants only reassigns the reallocated portion
+	before := assign(distribution(70, 20, 5, 5), n)
+	after := assign(distribution(60, 30, 5, 5), n)
+	moved := 0
+	for i := range before {
+		if before[i] != after[i] {
+			require.Equal(t, []string{"a", "b"}, []string{before[i], after[i]})
+			moved++
+		}
+	}
+	assert.InDelta(t, 0.1, float64(moved)/n, 0.01)
+}
+
+func TestFractionalConsistentBucketing(t *testing.T) {
+	distribution := func(weights ...int) *fractionalEvaluationDistribution {
+		d := &fractionalEvaluationDistribution{}
+		for i, weight := range weights {
+			d.totalWeight += weight
+			d.weightedVariants = append(d.weightedVariants,
+				fractionalEvaluationVariant{variant: string(rune('a' + i)), weight: weight})
+		}
+		return d
+	}
+	var rings hashRings
+	assign := func(
+		d *fractionalEvaluationDistribution, n int, distribute func(uint32, *fractionalEvaluationDistribution) string,
+	) []string {
+		variants := make([]string, n)
+		for i := range variants {
+			variants[i] = distribute(murmur3.StringSum32(fmt.Sprintf("flag-user-%d", i)), d)
+		}
+		return variants
+	}
+	moved := func(before []string, after []string) int {
+		count := 0
+		for i := range before {
+			if before[i] != after[i] {
+				count++
+			}
+		}
+		return count
+	}
+	const n = 20000
+
+	// the ring approximates the weights
+	counts := map[string]int{}
+	for _, variant := range assign(distribution(14, 4, 1, 1), n, rings.distribute) {
+		counts[variant]++
+	}
+	for variant, expected := range map[string]float64{"a": 0.7, "b": 0.2, "c": 0.05, "d": 0.05} {
+		assert.InDelta(t, expected, float64(counts[variant])/n, 0.03, variant)
+	}
+
+	// moving 10% of the weight between non-adjacent variants only reassigns the values of the removed and added
+	// points, close to the reallocated portion
+	before := assign(distribution(70, 20, 5, 5), n, rings.distribute)
+	after := assign(distribution(60, 20, 5, 15), n, rings.distribute)
+	assert.Less(t, float64(moved(before, after))/n, 0.15)
+
+	// whereas the ranges of the modulo bucketing shift all variants in between
+	modulo := moved(assign(distribution(70, 20, 5, 5), n, distributeValue),
+		assign(distribution(60, 20, 5, 15), n, distributeValue))
+	assert.Greater(t, modulo, moved(before, after))
+}
+
+func TestFractionalBucketingMetadata(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"consistent": {
+				"state": "ENABLED",
+				"variants": {"a": "a", "b": "b", "c": "c", "d": "d"},
+				"defaultVariant": "a",
+				"targeting": {"fractional": [["a", 25], ["b", 25], ["c", 25], ["d", 25]]},
+				"metadata": {"fractionalBucketing": "consistent"}
+			},
+			"modulo": {
+				"state": "ENABLED",
+				"variants": {"a": "a", "b": "b", "c": "c", "d": "d"},
+				"defaultVariant": "a",
+				"targeting": {"fractional": [["a", 25], ["b", 25], ["c", 25], ["d", 25]]}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	distribution := &fractionalEvaluationDistribution{totalWeight: 100, weightedVariants: []fractionalEvaluationVariant{
+		{variant: "a", weight: 25}, {variant: "b", weight: 25}, {variant: "c", weight: 25}, {variant: "d", weight: 25},
+	}}
+	var rings hashRings
+	for _, user := range []string{"alice", "bob", "carol", "dave", "eve", "frank", "grace", "heidi"} {
+		for flagKey, distribute := range map[string]func(uint32, *fractionalEvaluationDistribution) string{
+			"consistent": rings.distribute,
+			"modulo":     distributeValue,
+		} {
+			value, _, _, _, err := evaluator.ResolveStringValue(
+				context.Background(), "", flagKey, map[string]any{"targetingKey": user})
+			require.NoError(t, err)
+			assert.Equal(t, distribute(murmur3.StringSum32(flagKey+user), distribution), value, flagKey)
+		}
+	}
+}
+
+func TestFractionalHashMetadata(t *testing.T) {
+	const config = `{
+		"flags": {
+			"fnv1a": {
+				"state": "ENABLED",
+				"variants": {"a": "a", "b": "b", "c": "c", "d": "d"},
+				"defaultVariant": "a",
+				"targeting": {"fractional": [["a", 25], ["b", 25], ["c", 25], ["d", 25]]},
+				"metadata": {"fractionalHash": "fnv1a"}
+			},
+			"migration": {
+				"state": "ENABLED",
+				"variants": {"a": "a", "b": "b", "c": "c", "d": "d"},
+				"defaultVariant": "a",
+				"targeting": {"fractional": [["a", 25], ["b", 25], ["c", 25], ["d", 25]]},
+				"metadata": {"fractionalHashMigration": "fnv1a"}
+			}
+		}
+	}`
+	distribution := &fractionalEvaluationDistribution{totalWeight: 100, weightedVariants: []fractionalEvaluationVariant{
+		{variant: "a", weight: 25}, {variant: "b", weight: 25}, {variant: "c", weight: 25}, {variant: "d", weight: 25},
+	}}
+	users := []string{"alice", "bob", "carol", "dave", "eve", "frank", "grace", "heidi"}
+
+	for _, level := range []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel} {
+		t.Run(level.String(), func(t *testing.T) {
+			core, logs := observer.New(level)
+			evaluator := NewJSON(logger.NewLogger(zap.New(core), false), store.NewFlags())
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+			require.NoError(t, err)
+
+			diverging := 0
+			for _, user := range users {
+				value, _, _, _, err := evaluator.ResolveStringValue(
+					context.Background(), "", "fnv1a", map[string]any{"targetingKey": user})
+				require.NoError(t, err)
+				assert.Equal(t, distributeValue(fnv1a32("fnv1a"+user), distribution), value)
+
+				// the migrating flag is served by murmur3, the candidate is only logged
+				value, _, _, _, err = evaluator.ResolveStringValue(
+					context.Background(), "", "migration", map[string]any{"targetingKey": user})
+				require.NoError(t, err)
+				assert.Equal(t, distributeValue(murmur3.StringSum32("migration"+user), distribution), value)
+				if value != distributeValue(fnv1a32("migration"+user), distribution) {
+					diverging++
+				}
+			}
+			require.Positive(t, diverging)
+
+			divergences := logs.FilterMessage("fractional hash migration diverges")
+			if level == zapcore.DebugLevel {
+				assert.Equal(t, diverging, divergences.Len())
+			} else {
+				assert.Zero(t, divergences.Len())
+			}
+		})
+	}
+}
+
+func TestCompileFractionalSettings(t *testing.T) {
+	const targeting = `{"fractional": [{"var": "email"}, ["a", 50], ["b", 50]]}`
+	flags := &Flags{Flags: map[string]model.Flag{
+		"migration": {
+			Targeting: []byte(targeting),
+			Metadata: map[string]any{
+				FractionalBucketingMetadataKey:     FractionalBucketingConsistent,
+				FractionalHashMigrationMetadataKey: "fnv1a",
+				FractionalMetricsMetadataKey:       true,
+			},
+		},
+		"default": {Targeting: []byte(targeting)},
+	}}
+	require.NoError(t, compileTargeting(logger.NewLogger(nil, false), flags, false))
+
+	assert.JSONEq(t, targeting, string(flags.Flags["migration"].Targeting))
+	assert.JSONEq(t, `{"fractional": [
+		[{"bucketing": "consistent", "hash": "murmur3", "candidate": "fnv1a", "metrics": true}],
+		{"var": "email"}, ["a", 50], ["b", 50]
+	]}`, string(flags.Flags["migration"].CompiledTargeting))
+	assert.JSONEq(t, `{"fractional": [
+		[{"bucketing": "modulo", "hash": "murmur3", "candidate": "", "metrics": false}],
+		{"var": "email"}, ["a", 50], ["b", 50]
+	]}`, string(flags.Flags["default"].CompiledTargeting))
+
+	settings, values := compiledFractionalSettings([]any{[]any{"a", 50.0}, []any{"b", 50.0}})
+	assert.Equal(t, defaultFractionalSettings, settings, "uncompiled operations fall back to the default settings")
+	assert.Len(t, values, 2)
+}
+
+func TestValidateFractionalWeights(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		err       string
+	}{
+		"weighted variants": {
+			targeting: `{"fractional": [{"var": "email"}, ["a", 70], ["b", 20], ["c", 5], ["d", 5]]}`,
+		},
+		"shorthand with default weights": {
+			targeting: `{"fractional": [["a"], ["b"]]}`,
+		},
+		"zero weight": {
+			targeting: `{"fractional": [["a", 0], ["b", 100]]}`,
+		},
+		"computed weight": {
+			targeting: `{"fractional": [["a", {"var": "weight"}], ["b", 50]]}`,
+		},
+		"negative weight": {
+			targeting: `{"if": [true, {"fractional": [["a", -10], ["b", 50]]}]}`,
+			err:       "invalid targeting of flag: 'flag': weight of variant 'a' is negative: -10",
+		},
+		"weight of another type": {
+			targeting: `{"fractional": [["a", "50"], ["b", 50]]}`,
+			err:       "invalid targeting of flag: 'flag': weight of variant 'a' is not a number: 50",
+		},
+		"total weight of zero": {
+			targeting: `{"fractional": [["a", 0], ["b", 0]]}`,
+			err:       "invalid targeting of flag: 'flag': total weight of the fractional distribution must be positive",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateFractionalWeights(&Flags{Flags: map[string]model.Flag{
+				"flag": {Targeting: []byte(tt.targeting)},
+			}})
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFractionalComputedWeights(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"computed": {
+				"state": "ENABLED",
+				"variants": {"a": "a", "b": "b"},
+				"defaultVariant": "a",
+				"targeting": {"fractional": [["a", {"var": "weight"}], ["b", 0]]}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", "computed",
+		map[string]any{"targetingKey": "user", "weight": 10})
+	require.NoError(t, err)
+	assert.Equal(t, "a", value)
+
+	// invalid weights fail the fractional operation, which then evaluates to null and hence to the default variant
+	value, _, reason, _, err = evaluator.ResolveStringValue(context.Background(), "req", "computed",
+		map[string]any{"targetingKey": "user", "weight": -10})
+	require.NoError(t, err)
+	assert.Equal(t, "a", value)
+	assert.Equal(t, model.DefaultReason, reason)
+}
@@ -0,0 +1,186 @@
+package evaluator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/twmb/murmur3"
+)
+
+const HashEvaluationName = "hash"
+
+const hashOperands = 2
+
+type HashAlgorithm string
+
+const (
+	// HashSHA256 hashes to the hex encoded SHA-256 digest
+	HashSHA256 HashAlgorithm = "sha256"
+	// HashMurmur3 hashes to the unsigned 32-bit murmur3 sum
+	HashMurmur3 HashAlgorithm = "murmur3"
+)
+
+func (ha HashAlgorithm) hash(value []byte) (interface{}, error) {
+	switch ha {
+	case HashSHA256:
+		digest := sha256.Sum256(value)
+		return hex.EncodeToString(digest[:]), nil
+	case HashMurmur3:
+		return float64(murmur3.Sum32(value)), nil
+	default:
+		return nil, fmt.Errorf("unknown algorithm '%s'", ha)
+	}
+}
+
+type Hash struct {
+	Logger *logger.Logger
+}
+
+func NewHash(log *logger.Logger) *Hash {
+	return &Hash{Logger: log}
+}
+
+// HashEvaluation hashes the given property, so that rules can bucket on a value without exposing it.
+// It returns the hex encoded digest for 'sha256' and a number for 'murmur3'.
+// As an example, it can be used in the following way to bucket on a salted hash of the user ID:
+//
+//	{
+//	  "fractional": [
+//			{
+//				"hash": [{"cat": ["my-salt", {"var": "userId"}]}, "sha256"]
+//			},
+//			["red", 50], ["blue", 50]
+//			]
+//	}
+//
+// Note that the 'hash' evaluation rule must contain exactly two items:
+// 1. Target property: a string, number or boolean value
+// 2. Algorithm: One of the following: 'sha256', 'murmur3'
+//
+// Errors never contain the value of the target property.
+func (h *Hash) HashEvaluation(values, _ interface{}) interface{} {
+	value, algorithm, err := parseHashEvaluationData(values)
+	if err != nil {
+		h.Logger.Error(fmt.Sprintf("parse hash evaluation data: %v", err))
+		return nil
+	}
+	res, err := algorithm.hash(value)
+	if err != nil {
+		h.Logger.Error(fmt.Sprintf("hash evaluation: %v", err))
+		return nil
+	}
+	return res
+}
+
+func parseHashEvaluationData(values interface{}) ([]byte, HashAlgorithm, error) {
+	parsed, ok := values.([]interface{})
+	if !ok {
+		return nil, "", errors.New("hash evaluation is not an array")
+	}
+
+	if len(parsed) != hashOperands {
+		return nil, "", errors.New("hash evaluation must contain a value and an algorithm")
+	}
+
+	value, err := hashInput(parsed[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("hash evaluation: could not parse target property value: %w", err)
+	}
+
+	algorithm, ok := parsed[1].(string)
+	if !ok {
+		return nil, "", errors.New("hash evaluation: algorithm did not resolve to a string value")
+	}
+
+	return value, HashAlgorithm(algorithm), nil
+}
+
+// hashInput converts the target property to the bytes being hashed. Numbers use their shortest decimal notation, so
+// that a value hashes the same regardless of whether it was decoded as float64 or json.Number.
+func hashInput(v interface{}) ([]byte, error) {
+	switch value := v.(type) {
+	case string:
+		return []byte(value), nil
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return nil, errors.New("not a valid number")
+		}
+		return hashInput(f)
+	case float64:
+		return []byte(strconv.FormatFloat(value, 'f', -1, 64)), nil
+	case bool:
+		return []byte(strconv.FormatBool(value)), nil
+	default:
+		return nil, errors.New("property did not resolve to a string, number or boolean")
+	}
+}
+
+// validateHashAlgorithms returns an error if a hash rule of a flag's targeting doesn't name a supported algorithm.
+// The algorithm must be a string literal, so that it is known at load time.
+func validateHashAlgorithms(flags *Flags) error {
+	for name, flag := range flags.Flags {
+		if len(flag.Targeting) == 0 {
+			continue
+		}
+
+		var rule any
+		if err := json.Unmarshal(flag.Targeting, &rule); err != nil {
+			// parsing errors are reported at evaluation time
+			continue
+		}
+		if err := validateHashRules(rule); err != nil {
+			return fmt.Errorf("invalid targeting of flag: '%s': %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func validateHashRules(rule any) error {
+	switch r := rule.(type) {
+	case []any:
+		for _, item := range r {
+			if err := validateHashRules(item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for operator, args := range r {
+			if operator == HashEvaluationName {
+				if err := validateHashAlgorithm(args); err != nil {
+					return err
+				}
+			}
+			if err := validateHashRules(args); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateHashAlgorithm(args any) error {
+	parsed, ok := args.([]any)
+	if !ok || len(parsed) != hashOperands {
+		return errors.New("hash evaluation must contain a value and an algorithm")
+	}
+
+	algorithm, ok := parsed[1].(string)
+	if !ok {
+		return errors.New("hash evaluation: algorithm must be a string")
+	}
+
+	switch HashAlgorithm(algorithm) {
+	case HashSHA256, HashMurmur3:
+		return nil
+	default:
+		return fmt.Errorf("hash evaluation: unknown algorithm '%s'", algorithm)
+	}
+}
@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashEvaluation(t *testing.T) {
+	tests := map[string]struct {
+		values   interface{}
+		expected interface{}
+	}{
+		"sha256": {
+			values:   []interface{}{"user-1", "sha256"},
+			expected: "c6c289e49e9c05b2145860387b73bcb18df43fb09a1e4a4a9713c76c88bb541b",
+		},
+		"murmur3": {
+			values:   []interface{}{"user-1", "murmur3"},
+			expected: float64(4171401059),
+		},
+		"number": {
+			values:   []interface{}{float64(42), "murmur3"},
+			expected: float64(3159925814),
+		},
+		"json number": {
+			values:   []interface{}{json.Number("42.0"), "murmur3"},
+			expected: float64(3159925814),
+		},
+		"boolean": {
+			values:   []interface{}{true, "murmur3"},
+			expected: float64(888000370),
+		},
+		"unknown algorithm": {
+			values: []interface{}{"user-1", "md5"},
+		},
+		"missing value": {
+			values: []interface{}{nil, "sha256"},
+		},
+		"non-string algorithm": {
+			values: []interface{}{"user-1", 256},
+		},
+		"missing algorithm": {
+			values: []interface{}{"user-1"},
+		},
+		"not an array": {
+			values: "user-1",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewHash(logger.NewLogger(nil, false))
+			assert.Equal(t, tt.expected, h.HashEvaluation(tt.values, nil))
+		})
+	}
+}
+
+func TestHashTargeting(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"hashed": {
+				"state": "ENABLED",
+				"variants": {"match": "match", "other": "other"},
+				"defaultVariant": "other",
+				"targeting": {
+					"if": [
+						{"==": [
+							{"hash": [{"cat": ["salt-", {"var": "userId"}]}, "sha256"]},
+							"f705995c26c16680c9a6caf6916a513624bad7eafa8955ae6a39119ccea0b133"
+						]},
+						"match", "other"
+					]
+				}
+			},
+			"bucketed": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {
+					"fractional": [
+						{"hash": [{"var": "userId"}, "sha256"]},
+						["red", 50], ["blue", 50]
+					]
+				}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	value, variant, _, _, err := evaluator.ResolveStringValue(
+		context.Background(), "", "hashed", map[string]any{"userId": "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "match", value)
+	assert.Equal(t, "match", variant)
+
+	value, _, _, _, err = evaluator.ResolveStringValue(
+		context.Background(), "", "hashed", map[string]any{"userId": "user-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "other", value)
+
+	// bucketing on the hash is stable
+	first, _, _, _, err := evaluator.ResolveStringValue(
+		context.Background(), "", "bucketed", map[string]any{"userId": "user-1"})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		value, _, _, _, err = evaluator.ResolveStringValue(
+			context.Background(), "", "bucketed", map[string]any{"userId": "user-1"})
+		require.NoError(t, err)
+		assert.Equal(t, first, value)
+	}
+}
+
+func TestValidateHashAlgorithms(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		wantErr   bool
+	}{
+		"sha256": {
+			targeting: `{"if": [{"==": [{"hash": [{"var": "userId"}, "sha256"]}, "abc"]}, "on", "off"]}`,
+		},
+		"murmur3": {
+			targeting: `{"if": [{"<": [{"%": [{"hash": [{"var": "userId"}, "murmur3"]}, 100]}, 30]}, "on", "off"]}`,
+		},
+		"unknown algorithm": {
+			targeting: `{"if": [{"==": [{"hash": [{"var": "userId"}, "md5"]}, "abc"]}, "on", "off"]}`,
+			wantErr:   true,
+		},
+		"algorithm from context": {
+			targeting: `{"if": [{"==": [{"hash": [{"var": "userId"}, {"var": "algorithm"}]}, "abc"]}, "on", "off"]}`,
+			wantErr:   true,
+		},
+		"missing algorithm": {
+			targeting: `{"if": [{"==": [{"hash": [{"var": "userId"}]}, "abc"]}, "on", "off"]}`,
+			wantErr:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+				"flags": {
+					"flag": {
+						"state": "ENABLED",
+						"variants": {"on": "on", "off": "off"},
+						"defaultVariant": "off",
+						"targeting": ` + tt.targeting + `
+					}
+				}
+			}`})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
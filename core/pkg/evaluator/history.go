@@ -0,0 +1,108 @@
+package evaluator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+// ConfigVersion identifies a retained flag configuration by the version of the flag store, along with the time it
+// was applied
+type ConfigVersion struct {
+	Version string    `json:"version"`
+	Applied time.Time `json:"applied"`
+}
+
+// ConfigHistory retains the most recently applied flag configurations, so that evaluations can be reproduced against
+// a prior configuration. The configurations are kept as snapshots of the flag store, which share the flags with the
+// store, and the oldest configuration is evicted once the configured number of versions is retained.
+type ConfigHistory struct {
+	mx       sync.RWMutex
+	size     int
+	entries  []historyEntry
+	resolver Resolver
+}
+
+type historyEntry struct {
+	ConfigVersion
+	store *store.Flags
+}
+
+// NewConfigHistory returns a ConfigHistory retaining up to size configurations
+func NewConfigHistory(size int) *ConfigHistory {
+	if size < 1 {
+		size = 1
+	}
+	return &ConfigHistory{size: size}
+}
+
+// Versions returns the retained configurations, oldest first
+func (h *ConfigHistory) Versions() []ConfigVersion {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+
+	versions := make([]ConfigVersion, 0, len(h.entries))
+	for _, entry := range h.entries {
+		versions = append(versions, entry.ConfigVersion)
+	}
+	return versions
+}
+
+// Clear evicts all retained configurations
+func (h *ConfigHistory) Clear() {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	h.entries = nil
+}
+
+// Resolver returns a Resolver evaluating flags against the retained configuration of the given version. Evaluations
+// of the returned resolver are not recorded by metrics, so that they don't distort the metrics of served evaluations.
+func (h *ConfigHistory) Resolver(version string) (*Resolver, bool) {
+	h.mx.RLock()
+	defer h.mx.RUnlock()
+
+	for _, entry := range h.entries {
+		if entry.Version == version {
+			resolver := h.resolver
+			resolver.store = entry.store
+			resolver.metrics = &telemetry.NoopMetricsRecorder{}
+			// cached results are results of the current configuration
+			resolver.cache = nil
+			// only served evaluations of the debugged flag are logged
+			resolver.debugger = nil
+			return &resolver, true
+		}
+	}
+	return nil, false
+}
+
+// record retains a snapshot of the given store, unless its version is the most recently retained one. A version
+// which is applied again is moved to the end of the history.
+func (h *ConfigHistory) record(s *store.Flags) {
+	version := s.Version()
+	if version == "" {
+		return
+	}
+
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1].Version == version {
+		return
+	}
+
+	entries := make([]historyEntry, 0, h.size)
+	for _, entry := range h.entries {
+		if entry.Version != version {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) >= h.size {
+		entries = entries[len(entries)-h.size+1:]
+	}
+	h.entries = append(entries, historyEntry{
+		ConfigVersion: ConfigVersion{Version: version, Applied: time.Now().UTC()},
+		store:         s.Snapshot(),
+	})
+}
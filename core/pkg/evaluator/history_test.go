@@ -0,0 +1,109 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHistory(t *testing.T) {
+	history := NewConfigHistory(2)
+	s := store.NewFlags()
+	evaluator := NewJSON(logger.NewLogger(nil, false), s, WithConfigHistory(history))
+
+	apply := func(color string) string {
+		t.Helper()
+		_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: fmt.Sprintf(`{
+			"flags": {
+				"color": {
+					"state": "ENABLED",
+					"variants": {"red": "red", "blue": "blue", "green": "green"},
+					"defaultVariant": "%s",
+					"targeting": {"if": [{"==": [{"var": "tier"}, "premium"]}, "green", null]}
+				}
+			}
+		}`, color)})
+		require.NoError(t, err)
+		return s.Version()
+	}
+	versionOf := func(versions []ConfigVersion) []string {
+		result := []string{}
+		for _, v := range versions {
+			result = append(result, v.Version)
+		}
+		return result
+	}
+
+	red := apply("red")
+	// unchanged configurations are retained once
+	apply("red")
+	blue := apply("blue")
+	require.Equal(t, []string{red, blue}, versionOf(history.Versions()))
+
+	resolver, ok := history.Resolver(red)
+	require.True(t, ok)
+	value, _, reason, _, err := resolver.ResolveStringValue(context.Background(), "req", "color", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "red", value)
+	assert.Equal(t, model.DefaultReason, reason)
+	value, _, _, _, err = resolver.ResolveStringValue(context.Background(), "req", "color",
+		map[string]any{"tier": "premium"})
+	require.NoError(t, err)
+	assert.Equal(t, "green", value)
+
+	// the current configuration is served unaffected by historical evaluations
+	value, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "req", "color", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "blue", value)
+
+	// the oldest configuration is evicted, a reapplied configuration moves to the end
+	green := apply("green")
+	require.Equal(t, []string{blue, green}, versionOf(history.Versions()))
+	_, ok = history.Resolver(red)
+	require.False(t, ok)
+	apply("blue")
+	require.Equal(t, []string{green, blue}, versionOf(history.Versions()))
+
+	history.Clear()
+	require.Empty(t, history.Versions())
+	_, ok = history.Resolver(blue)
+	require.False(t, ok)
+}
+
+func TestConfigHistoryMetrics(t *testing.T) {
+	history := NewConfigHistory(2)
+	recorder := &bucketRecorder{buckets: map[string]map[string]float64{}, counts: map[string]int{}}
+	s := store.NewFlags()
+	evaluator := NewJSON(logger.NewLogger(nil, false), s, WithConfigHistory(history), WithMetricsRecorder(recorder))
+	_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: `{
+		"flags": {
+			"color": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"fractional": [["red", 50], ["blue", 50]]},
+				"metadata": {"fractionalMetrics": true}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	resolver, ok := history.Resolver(s.Version())
+	require.True(t, ok)
+	_, _, _, _, err = resolver.ResolveStringValue(context.Background(), "req", "color",
+		map[string]any{"targetingKey": "alice"})
+	require.NoError(t, err)
+	assert.NotContains(t, recorder.counts, "color", "historical evaluations are not recorded")
+
+	_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "req", "color",
+		map[string]any{"targetingKey": "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, recorder.counts["color"])
+}
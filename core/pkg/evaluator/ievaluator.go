@@ -35,6 +35,8 @@ IEvaluator is an extension of IResolver, allowing storage updates and retrievals
 type IEvaluator interface {
 	GetState() (string, error)
 	SetState(payload sync.DataSync) (map[string]interface{}, bool, error)
+	// SetStateWithContext is SetState, tracing the update as part of the trace of the given context
+	SetStateWithContext(ctx context.Context, payload sync.DataSync) (map[string]interface{}, bool, error)
 	IResolver
 }
 
@@ -78,4 +80,13 @@ type IResolver interface {
 		ctx context.Context,
 		reqID string,
 		context map[string]any) (values []AnyValue, err error)
+	ResolveAllValuesWithVersion(
+		ctx context.Context,
+		reqID string,
+		context map[string]any) (values []AnyValue, version string, err error)
+	ResolveBatchValues(
+		ctx context.Context,
+		reqID string,
+		flagKey string,
+		contexts []map[string]any) []AnyValue
 }
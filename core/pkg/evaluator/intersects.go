@@ -0,0 +1,92 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+const IntersectsEvaluationName = "intersects"
+
+type Intersects struct {
+	Logger *logger.Logger
+}
+
+func NewIntersects(log *logger.Logger) *Intersects {
+	return &Intersects{Logger: log}
+}
+
+// IntersectsEvaluation checks if two collections share at least one element, e.g. if a user holds any of the given
+// roles. As an example, it can be used in the following way inside an 'if' evaluation:
+//
+//	{
+//	  "if": [
+//			{
+//				"intersects": [{"var": "roles"}, ["admin", "owner"]]
+//			},
+//			"privileged", "regular"
+//			]
+//	}
+//
+// This rule can be applied to the following data object, where the evaluation will resolve to 'true':
+//
+// { "roles": ["viewer", "owner"] }
+//
+// A scalar operand is treated as collection of a single element and a null operand, e.g. a missing property, as empty
+// collection, which intersects no collection. Strings, numbers and booleans are compared by value without type
+// coercion, objects and arrays nested in a collection never match.
+func (i *Intersects) IntersectsEvaluation(values, _ interface{}) interface{} {
+	args, ok := values.([]any)
+	if !ok || len(args) != 2 {
+		i.Logger.Error(fmt.Sprintf("parse intersects evaluation data: expected two operands, got %v", values))
+		return false
+	}
+
+	elements := map[string]bool{}
+	for _, element := range intersectsElements(args[0]) {
+		if key, ok := intersectsKey(element); ok {
+			elements[key] = true
+		}
+	}
+	for _, element := range intersectsElements(args[1]) {
+		if key, ok := intersectsKey(element); ok && elements[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectsElements returns the elements of an operand, a scalar is a single element and null has no elements
+func intersectsElements(operand any) []any {
+	switch o := operand.(type) {
+	case nil:
+		return nil
+	case []any:
+		return o
+	default:
+		return []any{o}
+	}
+}
+
+// intersectsKey returns the key comparing an element by type and value, numbers are compared by their value regardless
+// of their representation. Objects and arrays have no key.
+func intersectsKey(element any) (string, bool) {
+	switch e := element.(type) {
+	case string:
+		return "s:" + e, true
+	case float64:
+		return "n:" + strconv.FormatFloat(e, 'g', -1, 64), true
+	case json.Number:
+		f, err := e.Float64()
+		if err != nil {
+			return "n:" + e.String(), true
+		}
+		return "n:" + strconv.FormatFloat(f, 'g', -1, 64), true
+	case bool:
+		return "b:" + strconv.FormatBool(e), true
+	default:
+		return "", false
+	}
+}
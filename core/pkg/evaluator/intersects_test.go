@@ -0,0 +1,94 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntersectsEvaluation(t *testing.T) {
+	tests := map[string]struct {
+		values   any
+		expected bool
+	}{
+		"overlapping arrays":        {values: []any{[]any{"viewer", "owner"}, []any{"admin", "owner"}}, expected: true},
+		"disjoint arrays":           {values: []any{[]any{"viewer"}, []any{"admin", "owner"}}, expected: false},
+		"scalar in array":           {values: []any{"admin", []any{"admin", "owner"}}, expected: true},
+		"scalar not in array":       {values: []any{"viewer", []any{"admin", "owner"}}, expected: false},
+		"equal scalars":             {values: []any{"admin", "admin"}, expected: true},
+		"empty array":               {values: []any{[]any{}, []any{"admin"}}, expected: false},
+		"both empty":                {values: []any{[]any{}, []any{}}, expected: false},
+		"null operand":              {values: []any{nil, []any{"admin"}}, expected: false},
+		"numbers":                   {values: []any{[]any{float64(1), float64(2)}, []any{json.Number("2.0")}}, expected: true},
+		"no type coercion":          {values: []any{[]any{"1"}, []any{float64(1)}}, expected: false},
+		"booleans":                  {values: []any{[]any{true}, true}, expected: true},
+		"nested arrays never match": {values: []any{[]any{[]any{"a"}}, []any{[]any{"a"}}}, expected: false},
+		"missing operand":           {values: []any{[]any{"admin"}}, expected: false},
+		"invalid operands":          {values: "admin", expected: false},
+	}
+
+	intersects := NewIntersects(logger.NewLogger(nil, false))
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, intersects.IntersectsEvaluation(tt.values, nil))
+		})
+	}
+}
+
+func TestIntersectsFlag(t *testing.T) {
+	const config = `{
+		"flags": {
+			"adminPanel": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [{"intersects": [{"var": "roles"}, ["admin", "owner"]]}, "on", "off"]}
+			}
+		}
+	}`
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		context  map[string]any
+		expected bool
+	}{
+		"any role matches": {
+			context:  map[string]any{"roles": []any{"viewer", "owner"}},
+			expected: true,
+		},
+		"no role matches": {
+			context:  map[string]any{"roles": []any{"viewer"}},
+			expected: false,
+		},
+		"single role": {
+			context:  map[string]any{"roles": "admin"},
+			expected: true,
+		},
+		"no roles": {
+			context:  map[string]any{"roles": []any{}},
+			expected: false,
+		},
+		"missing roles": {
+			context:  map[string]any{},
+			expected: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", "adminPanel", tt.context)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+			assert.Equal(t, model.TargetingMatchReason, reason)
+		})
+	}
+}
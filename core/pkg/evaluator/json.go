@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/diegoholiveira/jsonlogic/v3"
@@ -17,6 +20,7 @@ import (
 	"github.com/open-feature/flagd/core/pkg/model"
 	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/xeipuuv/gojsonschema"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -28,11 +32,25 @@ import (
 
 const (
 	SelectorMetadataKey = "scope"
-	flagdPropertiesKey  = "$flagd"
+	// LastModifiedMetadataKey is the flag or flag set metadata key holding the last modification timestamp of the
+	// configuration, either as RFC 3339 string or as seconds since the unix epoch
+	LastModifiedMetadataKey = "lastModified"
+	// StrictTargetingMetadataKey is the flag or flag set metadata key enabling or disabling strict type checking of the
+	// comparisons in the targeting, overriding the evaluator default
+	StrictTargetingMetadataKey = "strictTargeting"
+	// DefaultOnTargetingErrorMetadataKey is the flag or flag set metadata key enabling or disabling the fallback to the
+	// default variant if the targeting of the flag fails, overriding the evaluator default
+	DefaultOnTargetingErrorMetadataKey = "defaultOnTargetingError"
+	// ErrorCodeMetadataKey is the returned metadata key holding the error code of a targeting error which was answered
+	// with the default variant
+	ErrorCodeMetadataKey = "errorCode"
+	flagdPropertiesKey   = "$flagd"
 	// targetingKeyKey is used to extract the targetingKey to bucket on in fractional
 	// evaluation if the user did not supply the optional bucketing property.
 	targetingKeyKey = "targetingKey"
-	Disabled        = "DISABLED"
+	// deadlineKey is the $flagd property holding the deadline of an evaluation
+	deadlineKey = "deadline"
+	Disabled    = "DISABLED"
 )
 
 var regBrace *regexp.Regexp
@@ -50,6 +68,12 @@ type JSONEvaluatorOption func(je *JSON)
 type flagdProperties struct {
 	FlagKey   string `json:"flagKey"`
 	Timestamp int64  `json:"timestamp"`
+	// EvaluationID identifies evaluations of flags opting into the reason path or fractional metrics, or whose
+	// operators are counted
+	EvaluationID uint64 `json:"evaluationId,omitempty"`
+	// Deadline is the time in milliseconds since the unix epoch after which the custom operations of an evaluation
+	// bounded by the evaluation timeout are skipped
+	Deadline int64 `json:"deadline,omitempty"`
 }
 
 type variantEvaluator func(context.Context, string, string, map[string]any) (
@@ -67,9 +91,96 @@ type JSON struct {
 	store          *store.Flags
 	Logger         *logger.Logger
 	jsonEvalTracer trace.Tracer
+	jsonNumbers    bool
+	strict         bool
+	// rejectDuplicates fails configurations defining a flag more than once, instead of warning about them
+	rejectDuplicates bool
+	// allowEmptyConfig applies configurations defining no flags, instead of rejecting them
+	allowEmptyConfig bool
+	// variantRefRejection selects how flags referencing variants they don't define are rejected, see
+	// WithVariantRefRejection
+	variantRefRejection string
+	// maxFlags is the maximum number of flags of a configuration, zero doesn't limit the number of flags
+	maxFlags int
+	// maxDepth is the maximum nesting depth of targeting rules, zero doesn't limit the depth
+	maxDepth int
+	// rejectVersionRegressions fails configurations older than the applied configuration of their source, instead of
+	// warning about them
+	rejectVersionRegressions bool
+	versions                 sourceVersions
+	history                  *ConfigHistory
+	unknownFields            unknownFieldsLog
 	Resolver
 }
 
+// WithMetricsRecorder sets the recorder used to report metrics about applied flag configurations and evaluations
+func WithMetricsRecorder(recorder telemetry.IMetricsRecorder) JSONEvaluatorOption {
+	return func(je *JSON) {
+		if recorder != nil {
+			je.metrics = recorder
+		}
+	}
+}
+
+// WithJSONNumbers decodes numbers of flag configurations as json.Number instead of float64. This preserves integer
+// values through evaluation and response encoding, including integers exceeding the precision of float64.
+func WithJSONNumbers() JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.jsonNumbers = true
+	}
+}
+
+// WithStrictTargeting evaluates the comparisons of targeting rules without type coercion, so that comparisons of
+// operands with mismatching types are false. Flags may override this default with the StrictTargetingMetadataKey.
+func WithStrictTargeting() JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.strict = true
+	}
+}
+
+// WithDefaultOnTargetingError resolves flags whose targeting fails to their default variant, with an ERROR reason
+// and the error code in the metadata. Flags may override this default with the DefaultOnTargetingErrorMetadataKey.
+func WithDefaultOnTargetingError() JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.defaultOnError = true
+	}
+}
+
+// WithRejectDuplicateFlagKeys rejects configurations defining a flag key more than once within their flags object.
+// Otherwise, the last definition of the flag is used and a warning identifying the flag and its source is reported.
+func WithRejectDuplicateFlagKeys() JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.rejectDuplicates = true
+	}
+}
+
+// WithEvaluationTimeout limits the duration of a single evaluation, evaluations exceeding the timeout result in an
+// error and are recorded by the evaluation timeout metric. The remaining custom operations of an evaluation exceeding
+// the timeout are skipped. Zero doesn't limit evaluations.
+func WithEvaluationTimeout(timeout time.Duration) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.timeout = timeout
+	}
+}
+
+// WithContextRedactor sets the redactor applied to evaluation contexts before they are logged, e.g. to mask personal
+// data. Evaluation contexts are logged unmodified by default.
+func WithContextRedactor(redact Redactor) JSONEvaluatorOption {
+	return func(je *JSON) {
+		if redact != nil {
+			je.redact = redact
+		}
+	}
+}
+
+// WithConfigHistory retains the applied flag configurations in the given history, so that flags can be evaluated
+// against prior configurations
+func WithConfigHistory(history *ConfigHistory) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.history = history
+	}
+}
+
 func NewJSON(logger *logger.Logger, s *store.Flags, opts ...JSONEvaluatorOption) *JSON {
 	logger = logger.WithFields(
 		zap.String("component", "evaluator"),
@@ -82,11 +193,21 @@ func NewJSON(logger *logger.Logger, s *store.Flags, opts ...JSONEvaluatorOption)
 		Logger:         logger,
 		jsonEvalTracer: tracer,
 		Resolver:       NewResolver(s, logger, tracer),
+		maxDepth:       DefaultMaxTargetingDepth,
 	}
 
 	for _, o := range opts {
 		o(&ev)
 	}
+	if ev.lastEvaluated != nil {
+		ev.lastEvaluated.store = s
+	}
+	if ev.history != nil {
+		// historical evaluations are configured as the evaluations of the current configuration, but don't count as
+		// evaluations of the flags
+		ev.history.resolver = ev.Resolver
+		ev.history.resolver.lastEvaluated = nil
+	}
 
 	return &ev
 }
@@ -99,9 +220,18 @@ func (je *JSON) GetState() (string, error) {
 	return s, nil
 }
 
+// SetState applies the configuration of a sync to the store, tracing the sync as a root span
 func (je *JSON) SetState(payload sync.DataSync) (map[string]interface{}, bool, error) {
-	_, span := je.jsonEvalTracer.Start(
-		context.Background(),
+	return je.SetStateWithContext(context.Background(), payload)
+}
+
+// SetStateWithContext applies the configuration of a sync to the store. The span of the sync is a child of the span of
+// the context, if any, and holds the spans of its parsing, validation and store update.
+func (je *JSON) SetStateWithContext(
+	ctx context.Context, payload sync.DataSync,
+) (map[string]interface{}, bool, error) {
+	ctx, span := je.jsonEvalTracer.Start(
+		ctx,
 		"flagSync",
 		trace.WithAttributes(attribute.String("feature_flag.source", payload.Source)),
 		trace.WithAttributes(attribute.String("feature_flag.sync_type", payload.Type.String())))
@@ -109,7 +239,50 @@ func (je *JSON) SetState(payload sync.DataSync) (map[string]interface{}, bool, e
 
 	var newFlags Flags
 
-	err := configToFlags(je.Logger, payload.FlagData, &newFlags)
+	var warnings []configWarning
+	var version uint64
+	var versioned bool
+	var err error
+	parseStart := time.Now()
+	_, parseSpan := je.jsonEvalTracer.Start(ctx, "parse")
+	duplicates := duplicateFlagKeys(payload.FlagData)
+	if len(duplicates) > 0 && je.rejectDuplicates {
+		err = duplicateFlagKeysError(duplicates)
+	}
+	if err == nil {
+		err = je.checkUnknownFields(payload)
+	}
+	if err == nil {
+		err = configToFlags(je.Logger, payload.FlagData, &newFlags, je.jsonNumbers)
+	}
+	endSyncSpan(parseSpan, err)
+	if err == nil {
+		_, validateSpan := je.jsonEvalTracer.Start(ctx, "validate")
+		err = je.checkFlagLimit(payload, &newFlags)
+		if err == nil {
+			err = je.checkTargetingDepth(payload, &newFlags)
+		}
+		if err == nil {
+			err = je.checkVariantRefs(ctx, payload, &newFlags)
+		}
+		if err == nil {
+			err = je.checkEmptyConfig(payload, &newFlags)
+		}
+		if err == nil {
+			version, versioned, err = je.checkVersionRegression(ctx, payload, &newFlags)
+		}
+		if err == nil {
+			warnings = append(duplicateFlagWarnings(duplicates), configWarnings(&newFlags)...)
+			warnInvalidEvaluationCacheTTLs(je.Logger, &newFlags)
+			warnInvalidRegionDefaults(je.Logger, &newFlags)
+			warnInvalidValueTemplates(je.Logger, &newFlags)
+			warnInvalidDefaultOnTargetingError(je.Logger, &newFlags)
+			err = compileTargeting(je.Logger, &newFlags, je.strict)
+		}
+		validateSpan.SetAttributes(attribute.Int("feature_flag.flag_count", len(newFlags.Flags)))
+		endSyncSpan(validateSpan, err)
+	}
+	telemetry.ConfigMetrics(je.metrics).ConfigParseDuration(ctx, payload.Source, time.Since(parseStart))
 	if err != nil {
 		span.SetStatus(codes.Error, "flagSync error")
 		span.RecordError(err)
@@ -119,51 +292,183 @@ func (je *JSON) SetState(payload sync.DataSync) (map[string]interface{}, bool, e
 	var events map[string]interface{}
 	var reSync bool
 
+	_, storeSpan := je.jsonEvalTracer.Start(ctx, "storeUpdate")
 	switch payload.Type {
 	case sync.ALL:
 		events, reSync = je.store.Merge(je.Logger, payload.Source, payload.Selector, newFlags.Flags)
+		je.store.SetDeclarations(payload.Source, newFlags.declarations, true)
 	case sync.ADD:
 		events = je.store.Add(je.Logger, payload.Source, payload.Selector, newFlags.Flags)
+		je.store.SetDeclarations(payload.Source, newFlags.declarations, false)
 	case sync.UPDATE:
 		events = je.store.Update(je.Logger, payload.Source, payload.Selector, newFlags.Flags)
+		je.store.SetDeclarations(payload.Source, newFlags.declarations, false)
 	case sync.DELETE:
 		events = je.store.DeleteFlags(je.Logger, payload.Source, newFlags.Flags)
 	default:
-		return nil, false, fmt.Errorf("unsupported sync type: %d", payload.Type)
+		err = fmt.Errorf("unsupported sync type: %d", payload.Type)
+		endSyncSpan(storeSpan, err)
+		span.SetStatus(codes.Error, "flagSync error")
+		span.RecordError(err)
+		return nil, false, err
+	}
+	storeSpan.SetAttributes(changeCountAttributes(events)...)
+	storeSpan.End()
+
+	if versioned {
+		je.versions.set(payload.Source, version)
+	}
+
+	if isEmptyConfig(payload, &newFlags) {
+		je.Logger.Warn(fmt.Sprintf("applied an empty configuration of source %s, removing all flags of the source",
+			payload.Source))
+		telemetry.ConfigMetrics(je.metrics).EmptyConfigApplied(ctx, payload.Source)
 	}
 
+	if je.cache != nil {
+		// cached results may be stale for the changed flags, or for the flags of their flag sets
+		je.cache.clear()
+	}
+	// the networks of removed rules are dropped along with the other cached networks
+	je.cidr.reset()
+
 	// Number of events correlates to the number of flags changed through this sync, record it
-	span.SetAttributes(attribute.Int("feature_flag.change_count", len(events)))
+	span.SetAttributes(changeCountAttributes(events)...)
+
+	if payload.Type != sync.DELETE {
+		je.recordConfigWarnings(ctx, payload.Source, warnings)
+	}
+
+	if je.history != nil {
+		je.history.record(je.store)
+	}
+
+	if je.lastEvaluated != nil {
+		je.pruneLastEvaluated(ctx)
+	}
+
+	// the staleness is only known if the configuration carries a modification timestamp
+	if modified, ok := lastModified(je.Logger, newFlags.Flags); ok {
+		telemetry.ConfigMetrics(je.metrics).ConfigStaleness(ctx, payload.Source, time.Since(modified))
+	}
 
 	return events, reSync, nil
 }
 
 // Resolver implementation for flagd flags. This resolver should be kept reusable, hence must interact with interfaces.
 type Resolver struct {
-	store  store.IStore
-	Logger *logger.Logger
-	tracer trace.Tracer
+	store        store.IStore
+	Logger       *logger.Logger
+	tracer       trace.Tracer
+	metrics      telemetry.IMetricsRecorder
+	stackSampler *stackSampler
+	fractional   *Fractional
+	reasonPaths  *reasonPaths
+	// buckets collects the buckets served by the fractional operations of each evaluation, recorded by metrics
+	buckets *fractionalBuckets
+	// cidr caches the networks of the cidr operations of the loaded rules
+	cidr *CIDR
+	// evaluationIDs allocates the ids identifying in-flight evaluations in the $flagd properties
+	evaluationIDs *atomic.Uint64
+	// operators counts the operators executed by the targeting of each evaluation, nil disables counting
+	operators *operatorCounts
+	// timeout is the deadline of a single evaluation, zero doesn't limit evaluations
+	timeout time.Duration
+	// cache holds the results of flags opted into the evaluation cache, nil disables the cache
+	cache *evaluationCache
+	// snapshotVersion is the version of the store snapshot evaluated by resolvers of a snapshot, empty otherwise
+	snapshotVersion string
+	// redact removes sensitive values from evaluation contexts before they are logged
+	redact Redactor
+	// defaultOnError falls back to the default variant on targeting errors, unless overridden by the flag metadata
+	defaultOnError bool
+	// defaultTargetingKey is the JsonLogic expression synthesizing the targeting key of contexts lacking one, if set
+	defaultTargetingKey json.RawMessage
+	// missingKeys tracks the context keys whose absence is recorded, if any
+	missingKeys *missingContextKeys
+	// debugger selects the flag whose evaluations are logged in detail, nil disables debugging
+	debugger *FlagDebugger
+	// fallbackConfigSource is the source of the fallback configuration, whose evaluations are marked in the metadata
+	fallbackConfigSource string
+	// now is the clock capturing the timestamp of each evaluation
+	now func() time.Time
+	// lastEvaluated records the time each flag was last evaluated at, nil disables the tracking
+	lastEvaluated *LastEvaluated
+	// regionContextKey is the evaluation context key holding the region the region defaults are selected by
+	regionContextKey string
 }
 
 func NewResolver(store store.IStore, logger *logger.Logger, jsonEvalTracer trace.Tracer) Resolver {
-	// register supported json logic custom operator implementations
-	jsonlogic.AddOperator(FractionEvaluationName, NewFractional(logger).Evaluate)
-	jsonlogic.AddOperator(StartsWithEvaluationName, NewStringComparisonEvaluator(logger).StartsWithEvaluation)
-	jsonlogic.AddOperator(EndsWithEvaluationName, NewStringComparisonEvaluator(logger).EndsWithEvaluation)
-	jsonlogic.AddOperator(SemVerEvaluationName, NewSemVerComparison(logger).SemVerEvaluation)
-	jsonlogic.AddOperator(LegacyFractionEvaluationName, NewLegacyFractional(logger).LegacyFractionalEvaluation)
-
-	return Resolver{store: store, Logger: logger, tracer: jsonEvalTracer}
+	paths := &reasonPaths{}
+	buckets := &fractionalBuckets{}
+	fractional := NewFractional(logger)
+	fractional.reasonPaths = paths
+	fractional.buckets = buckets
+
+	// register supported json logic custom operator implementations, operations exceeding the evaluation timeout are
+	// skipped
+	addOperator := func(name string, operator func(values, data any) any) {
+		jsonlogic.AddOperator(name, interruptible(operator))
+	}
+	addOperator(FractionEvaluationName, fractional.Evaluate)
+	addOperator(StartsWithEvaluationName, NewStringComparisonEvaluator(logger).StartsWithEvaluation)
+	addOperator(EndsWithEvaluationName, NewStringComparisonEvaluator(logger).EndsWithEvaluation)
+	addOperator(SemVerEvaluationName, NewSemVerComparison(logger).SemVerEvaluation)
+	addOperator(DateOffsetEvaluationName, NewDateOffset(logger).DateOffsetEvaluation)
+	addOperator(LegacyFractionEvaluationName, NewLegacyFractional(logger).LegacyFractionalEvaluation)
+	addOperator(HashEvaluationName, NewHash(logger).HashEvaluation)
+	cidr := NewCIDR(logger)
+	addOperator(CIDREvaluationName, cidr.CIDREvaluation)
+	addOperator(ExistsEvaluationName, NewExists(logger).ExistsEvaluation)
+	addOperator(LookupEvaluationName, NewLookup(logger).LookupEvaluation)
+	addOperator(InEnumEvaluationName, NewInEnum(logger).InEnumEvaluation)
+	addOperator(IntersectsEvaluationName, NewIntersects(logger).IntersectsEvaluation)
+	addOperator(NowEvaluationName, NewNow(logger).NowEvaluation)
+	arithmetic := NewArithmetic(logger)
+	for operator := range arithmeticOperations {
+		addOperator(operator, arithmetic.ArithmeticEvaluation(operator))
+	}
+	registerStrictOperators()
+
+	return Resolver{
+		store:        store,
+		Logger:       logger,
+		tracer:       jsonEvalTracer,
+		metrics:      &telemetry.NoopMetricsRecorder{},
+		stackSampler: newStackSampler(panicStackLogInterval),
+		fractional:   fractional,
+		reasonPaths:  paths,
+		buckets:      buckets,
+		cidr:         cidr,
+		redact:       RedactKeys(),
+		cache:        newEvaluationCache(DefaultEvaluationCacheSize),
+		now:          time.Now,
+		// the region of clients is commonly sent as "region"
+		regionContextKey: DefaultRegionContextKey,
+		// the ids are shared by the reason paths, the fractional buckets and the operator counts
+		evaluationIDs: &atomic.Uint64{},
+	}
 }
 
+// ResolveAllValues evaluates all flags against a snapshot of the store taken at the start of the evaluation
 func (je *Resolver) ResolveAllValues(ctx context.Context, reqID string, context map[string]any) ([]AnyValue, error) {
+	values, _, err := je.ResolveAllValuesWithVersion(ctx, reqID, context)
+	return values, err
+}
+
+// ResolveAllValuesWithVersion evaluates all flags as ResolveAllValues, returning the version of the evaluated
+// configuration along with the results
+func (je *Resolver) ResolveAllValuesWithVersion(ctx context.Context, reqID string, context map[string]any) (
+	[]AnyValue, string, error,
+) {
 	_, span := je.tracer.Start(ctx, "resolveAll")
 	defer span.End()
 
+	je, version := je.snapshot()
 	var err error
 	allFlags, err := je.store.GetAll(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error retreiving flags from the store: %w", err)
+		return nil, "", fmt.Errorf("error retreiving flags from the store: %w", err)
 	}
 
 	values := []AnyValue{}
@@ -172,13 +477,21 @@ func (je *Resolver) ResolveAllValues(ctx context.Context, reqID string, context
 	var reason string
 	var metadata map[string]interface{}
 
-	for flagKey, flag := range allFlags {
+	for flagKey := range allFlags {
+		// flags are resolved along the flag set fallback chain, skipping flags of flag sets outside the chain
+		flag, _, ok := je.store.Lookup(ctx, flagKey)
+		if !ok {
+			continue
+		}
 		if flag.State == Disabled {
 			// ignore evaluation of disabled flag
 			continue
 		}
 
 		defaultValue := flag.Variants[flag.DefaultVariant]
+		if !matchesValueType(ctx, defaultValue) {
+			continue
+		}
 		switch defaultValue.(type) {
 		case bool:
 			value, variant, reason, metadata, err = resolve[bool](ctx, reqID, flagKey, context, je.evaluateVariant)
@@ -186,6 +499,8 @@ func (je *Resolver) ResolveAllValues(ctx context.Context, reqID string, context
 			value, variant, reason, metadata, err = resolve[string](ctx, reqID, flagKey, context, je.evaluateVariant)
 		case float64:
 			value, variant, reason, metadata, err = resolve[float64](ctx, reqID, flagKey, context, je.evaluateVariant)
+		case json.Number:
+			value, variant, reason, metadata, err = resolve[json.Number](ctx, reqID, flagKey, context, je.evaluateVariant)
 		case map[string]any:
 			value, variant, reason, metadata, err = resolve[map[string]any](ctx, reqID, flagKey, context, je.evaluateVariant)
 		}
@@ -195,7 +510,7 @@ func (je *Resolver) ResolveAllValues(ctx context.Context, reqID string, context
 		values = append(values, NewAnyValue(value, variant, reason, flagKey, metadata, err))
 	}
 
-	return values, nil
+	return values, version, nil
 }
 
 func (je *Resolver) ResolveBooleanValue(
@@ -208,6 +523,9 @@ func (je *Resolver) ResolveBooleanValue(
 ) {
 	_, span := je.tracer.Start(ctx, "resolveBoolean")
 	defer span.End()
+	defer func() {
+		je.recordTypeMismatch(ctx, flagKey, variant, telemetry.TypeBoolean, err)
+	}()
 
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating boolean flag: %s", flagKey))
 	return resolve[bool](ctx, reqID, flagKey, context, je.evaluateVariant)
@@ -223,6 +541,9 @@ func (je *Resolver) ResolveStringValue(
 ) {
 	_, span := je.tracer.Start(ctx, "resolveString")
 	defer span.End()
+	defer func() {
+		je.recordTypeMismatch(ctx, flagKey, variant, telemetry.TypeString, err)
+	}()
 
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating string flag: %s", flagKey))
 	return resolve[string](ctx, reqID, flagKey, context, je.evaluateVariant)
@@ -238,10 +559,29 @@ func (je *Resolver) ResolveFloatValue(
 ) {
 	_, span := je.tracer.Start(ctx, "resolveFloat")
 	defer span.End()
+	defer func() {
+		je.recordTypeMismatch(ctx, flagKey, variant, telemetry.TypeFloat, err)
+	}()
 
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating float flag: %s", flagKey))
-	value, variant, reason, metadata, err = resolve[float64](ctx, reqID, flagKey, context, je.evaluateVariant)
-	return
+	var val interface{}
+	val, variant, reason, metadata, err = resolve[interface{}](ctx, reqID, flagKey, context, je.evaluateVariant)
+	if err != nil {
+		return value, variant, reason, metadata, err
+	}
+
+	switch v := val.(type) {
+	case float64:
+		value = v
+	case json.Number:
+		value, err = v.Float64()
+	default:
+		err = errors.New(model.TypeMismatchErrorCode)
+	}
+	if err != nil {
+		return value, variant, model.ErrorReason, metadata, errors.New(model.TypeMismatchErrorCode)
+	}
+	return value, variant, reason, metadata, nil
 }
 
 func (je *Resolver) ResolveIntValue(ctx context.Context, reqID string, flagKey string, context map[string]any) (
@@ -253,12 +593,42 @@ func (je *Resolver) ResolveIntValue(ctx context.Context, reqID string, flagKey s
 ) {
 	_, span := je.tracer.Start(ctx, "resolveInt")
 	defer span.End()
+	defer func() {
+		je.recordTypeMismatch(ctx, flagKey, variant, telemetry.TypeInteger, err)
+	}()
 
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating int flag: %s", flagKey))
-	var val float64
-	val, variant, reason, metadata, err = resolve[float64](ctx, reqID, flagKey, context, je.evaluateVariant)
-	value = int64(val)
-	return
+	var val interface{}
+	val, variant, reason, metadata, err = resolve[interface{}](ctx, reqID, flagKey, context, je.evaluateVariant)
+	if err != nil {
+		return value, variant, reason, metadata, err
+	}
+
+	switch v := val.(type) {
+	case float64:
+		value = int64(v)
+	case json.Number:
+		value, err = jsonNumberToInt64(v)
+	default:
+		err = errors.New(model.TypeMismatchErrorCode)
+	}
+	if err != nil {
+		return value, variant, model.ErrorReason, metadata, errors.New(model.TypeMismatchErrorCode)
+	}
+	return value, variant, reason, metadata, nil
+}
+
+// jsonNumberToInt64 converts a json.Number to an int64 without loss of precision for integer values. Non integer
+// values are truncated, which is consistent with the conversion of float64 values.
+func jsonNumberToInt64(n json.Number) (int64, error) {
+	if i, err := n.Int64(); err == nil {
+		return i, nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %s: %w", n, err)
+	}
+	return int64(f), nil
 }
 
 func (je *Resolver) ResolveObjectValue(
@@ -271,6 +641,9 @@ func (je *Resolver) ResolveObjectValue(
 ) {
 	_, span := je.tracer.Start(ctx, "resolveObject")
 	defer span.End()
+	defer func() {
+		je.recordTypeMismatch(ctx, flagKey, variant, telemetry.TypeObject, err)
+	}()
 
 	je.Logger.DebugWithID(reqID, fmt.Sprintf("evaluating object flag: %s", flagKey))
 	return resolve[map[string]any](ctx, reqID, flagKey, context, je.evaluateVariant)
@@ -308,18 +681,69 @@ func resolve[T constraints](ctx context.Context, reqID string, key string, conte
 	return value, variant, reason, metadata, nil
 }
 
+// recordTypeMismatch reports an evaluation which failed as the requested type differs from the type of the variant
+func (je *Resolver) recordTypeMismatch(ctx context.Context, flagKey string, variant string, requestedType string,
+	err error,
+) {
+	if err == nil || err.Error() != model.TypeMismatchErrorCode {
+		return
+	}
+
+	actualType := telemetry.TypeUnknown
+	if flag, _, _, ok := je.lookup(ctx, flagKey); ok {
+		actualType = valueType(flag.Variants[variant])
+	}
+	telemetry.EvaluationMetrics(je.metrics).TypeMismatch(ctx, requestedType, actualType)
+}
+
+// valueType returns the type of a variant value, numbers without fractional part are integers
+func valueType(value any) string {
+	switch v := value.(type) {
+	case bool:
+		return telemetry.TypeBoolean
+	case string:
+		return telemetry.TypeString
+	case float64:
+		if v == math.Trunc(v) {
+			return telemetry.TypeInteger
+		}
+		return telemetry.TypeFloat
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return telemetry.TypeInteger
+		}
+		return telemetry.TypeFloat
+	case map[string]any:
+		return telemetry.TypeObject
+	default:
+		return telemetry.TypeUnknown
+	}
+}
+
 // nolint: funlen
-func (je *Resolver) evaluateVariant(ctx context.Context, reqID string, flagKey string, evalCtx map[string]any) (
+func (je *Resolver) evaluate(ctx context.Context, reqID string, flagKey string, evalCtx map[string]any) (
 	variant string, variants map[string]interface{}, reason string, metadata map[string]interface{}, err error,
 ) {
 	metadata = map[string]interface{}{}
 
-	flag, ok := je.store.Get(ctx, flagKey)
+	flag, flagSet, key, ok := je.lookup(ctx, flagKey)
 	if !ok {
 		// flag not found
 		je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag could not be found: %s", flagKey))
 		return "", map[string]interface{}{}, model.ErrorReason, metadata, errors.New(model.FlagNotFoundErrorCode)
 	}
+	if key != flagKey {
+		// the flag is evaluated under its own key, so that its evaluation doesn't depend on the requested alias
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag %s resolved as alias of flag %s", flagKey, key))
+		telemetry.EvaluationMetrics(je.metrics).AliasHit(ctx, flagKey, key)
+		flagKey = key
+	}
+	if flagSet != "" {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag %s resolved from flag set %s", flagKey, flagSet))
+	}
+	if je.lastEvaluated != nil {
+		je.lastEvaluated.record(flagKey)
+	}
 
 	// add selector to evaluation metadata
 	selector := je.store.SelectorForFlag(ctx, flag)
@@ -333,46 +757,94 @@ func (je *Resolver) evaluateVariant(ctx context.Context, reqID string, flagKey s
 			metadata[key] = value
 		}
 	}
+	if je.fallbackConfigSource != "" && flag.Source == je.fallbackConfigSource {
+		metadata[FallbackConfigMetadataKey] = true
+	}
 
 	if flag.State == Disabled {
 		je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag is disabled: %s", flagKey))
 		return "", flag.Variants, model.ErrorReason, metadata, errors.New(model.FlagDisabledErrorCode)
 	}
 
+	var evaluationID uint64
+	var targeted bool
+	if enabled, _ := flag.Metadata[ReasonPathMetadataKey].(bool); enabled {
+		evaluationID = je.evaluationIDs.Add(1)
+		je.reasonPaths.start(evaluationID)
+		defer func() {
+			metadata[ReasonsMetadataKey] = reasonPath(reason, targeted, je.reasonPaths.end(evaluationID))
+		}()
+	}
+
 	// get the targeting logic, if any
-	targeting := flag.Targeting
+	targeting := evaluatedTargeting(flag)
 
 	if targeting != nil && string(targeting) != "{}" {
 		targetingBytes, err := targeting.MarshalJSON()
 		if err != nil {
 			je.Logger.ErrorWithID(reqID, fmt.Sprintf("Error parsing rules for flag: %s, %s", flagKey, err))
-			return "", flag.Variants, model.ErrorReason, metadata, errors.New(model.ParseErrorCode)
+			return je.targetingError(reqID, flagKey, flag, metadata, model.ParseErrorCode)
 		}
 
-		evalCtx = setFlagdProperties(je.Logger, evalCtx, flagdProperties{
-			FlagKey:   flagKey,
-			Timestamp: time.Now().Unix(),
-		})
+		targeted = true
+		if enabled, _ := flag.Metadata[FractionalMetricsMetadataKey].(bool); enabled {
+			if evaluationID == 0 {
+				evaluationID = je.evaluationIDs.Add(1)
+			}
+			je.buckets.start(evaluationID)
+			defer func() {
+				for _, bucket := range je.buckets.end(evaluationID) {
+					telemetry.EvaluationMetrics(je.metrics).FractionalBucket(
+						ctx, flagKey, bucket.variant, bucket.percentage)
+				}
+			}()
+		}
+		if je.operators != nil {
+			if evaluationID == 0 {
+				evaluationID = je.evaluationIDs.Add(1)
+			}
+			je.operators.start(evaluationID)
+			defer func() {
+				telemetry.EvaluationMetrics(je.metrics).OperatorsExecuted(ctx, flagKey, je.operators.end(evaluationID))
+			}()
+		}
+		evalCtx = je.withDefaultTargetingKey(ctx, reqID, flagKey, evalCtx)
+		properties := flagdProperties{
+			FlagKey:      flagKey,
+			Timestamp:    je.now().Unix(),
+			EvaluationID: evaluationID,
+		}
+		if deadline, ok := ctx.Deadline(); ok && je.timeout > 0 {
+			properties.Deadline = deadline.UnixMilli()
+		}
+		evalCtx = setFlagdProperties(je.Logger, evalCtx, properties)
+		je.recordMissingContextKeys(ctx, targetingBytes, evalCtx)
 
 		b, err := json.Marshal(evalCtx)
 		if err != nil {
-			je.Logger.ErrorWithID(reqID, fmt.Sprintf("error parsing context for flag: %s, %s, %v", flagKey, err, evalCtx))
+			je.Logger.ErrorWithID(reqID, fmt.Sprintf("error parsing context for flag: %s, %s, %v", flagKey, err,
+				je.redact(evalCtx)))
 
-			return "", flag.Variants, model.ErrorReason, metadata, errors.New(model.ErrorReason)
+			return je.targetingError(reqID, flagKey, flag, metadata, model.ErrorReason)
 		}
 
 		var result bytes.Buffer
 		// evaluate JsonLogic rules to determine the variant
-		err = jsonlogic.Apply(bytes.NewReader(targetingBytes), bytes.NewReader(b), &result)
+		if batch, ok := ctx.Value(batchTargetingKey{}).(*batchTargeting); ok {
+			err = batch.apply(je.operators.targeting(targetingBytes), b, &result)
+		} else {
+			err = jsonlogic.Apply(bytes.NewReader(je.operators.targeting(targetingBytes)), bytes.NewReader(b), &result)
+		}
 		if err != nil {
 			je.Logger.ErrorWithID(reqID, fmt.Sprintf("error applying targeting rules: %s", err))
-			return "", flag.Variants, model.ErrorReason, metadata, errors.New(model.ParseErrorCode)
+			return je.targetingError(reqID, flagKey, flag, metadata, model.ParseErrorCode)
 		}
 
 		// check if string is "null" before we strip quotes, so we can differentiate between JSON null and "null"
 		trimmed := strings.TrimSpace(result.String())
 		if trimmed == "null" {
-			return flag.DefaultVariant, flag.Variants, model.DefaultReason, metadata, nil
+			variant, reason = je.defaultVariant(flag, evalCtx, model.DefaultReason)
+			return variant, flag.Variants, reason, metadata, nil
 		}
 
 		// strip whitespace and quotes from the variant
@@ -382,11 +854,20 @@ func (je *Resolver) evaluateVariant(ctx context.Context, reqID string, flagKey s
 		if _, ok := flag.Variants[variant]; ok {
 			return variant, flag.Variants, model.TargetingMatchReason, metadata, nil
 		}
+
+		// boolean results of boolean flags map to the variant holding the same value
+		if result, ok := booleanTargetingResult(trimmed); ok && isBooleanFlag(flag) {
+			if booleanVariant, ok := booleanVariant(flag, result); ok {
+				return booleanVariant, flag.Variants, model.TargetingMatchReason, metadata, nil
+			}
+		}
+
 		je.Logger.ErrorWithID(reqID,
 			fmt.Sprintf("invalid or missing variant: %s for flagKey: %s, variant is not valid", variant, flagKey))
-		return "", flag.Variants, model.ErrorReason, metadata, errors.New(model.ParseErrorCode)
+		return je.targetingError(reqID, flagKey, flag, metadata, model.ParseErrorCode)
 	}
-	return flag.DefaultVariant, flag.Variants, model.StaticReason, metadata, nil
+	variant, reason = je.defaultVariant(flag, evalCtx, model.StaticReason)
+	return variant, flag.Variants, reason, metadata, nil
 }
 
 func setFlagdProperties(
@@ -447,8 +928,9 @@ func loadAndCompileSchema(log *logger.Logger) *gojsonschema.Schema {
 	return compiledSchema
 }
 
-// configToFlags convert string configurations to flags and store them to pointer newFlags
-func configToFlags(log *logger.Logger, config string, newFlags *Flags) error {
+// configToFlags convert string configurations to flags and store them to pointer newFlags. If jsonNumbers is set,
+// numbers are decoded as json.Number rather than float64.
+func configToFlags(log *logger.Logger, config string, newFlags *Flags, jsonNumbers bool) error {
 	compiledSchema := loadAndCompileSchema(log)
 
 	flagStringLoader := gojsonschema.NewStringLoader(config)
@@ -468,7 +950,11 @@ func configToFlags(log *logger.Logger, config string, newFlags *Flags) error {
 	}
 
 	var configData ConfigWithMetadata
-	err = json.Unmarshal([]byte(transposedConfig), &configData)
+	if jsonNumbers {
+		err = unmarshalWithNumbers([]byte(transposedConfig), &configData)
+	} else {
+		err = json.Unmarshal([]byte(transposedConfig), &configData)
+	}
 	if err != nil {
 		return fmt.Errorf("unmarshalling provided configurations: %w", err)
 	}
@@ -489,22 +975,71 @@ func configToFlags(log *logger.Logger, config string, newFlags *Flags) error {
 		newFlags.Flags[key] = flag
 	}
 
-	return validateDefaultVariants(newFlags)
-}
+	if err := resolveParentVariants(newFlags); err != nil {
+		return err
+	}
 
-// validateDefaultVariants returns an error if any of the default variants aren't valid
-func validateDefaultVariants(flags *Flags) error {
-	for name, flag := range flags.Flags {
-		if _, ok := flag.Variants[flag.DefaultVariant]; !ok {
-			return fmt.Errorf(
-				"default variant: '%s' isn't a valid variant of flag: '%s'", flag.DefaultVariant, name,
-			)
-		}
+	if err := validateHashAlgorithms(newFlags); err != nil {
+		return err
+	}
+
+	if err := validateCIDRBlocks(newFlags); err != nil {
+		return err
 	}
 
+	if err := validateFractionalWeights(newFlags); err != nil {
+		return err
+	}
+
+	if err := validateAliases(newFlags); err != nil {
+		return err
+	}
+
+	newFlags.lookups = configData.Lookups
+	newFlags.enums = configData.Enums
+	if newFlags.declarations, err = configDeclarations(transposedConfig); err != nil {
+		return err
+	}
+
+	return validateBooleanTargeting(newFlags)
+}
+
+// unmarshalWithNumbers behaves like json.Unmarshal, but decodes numbers as json.Number
+func unmarshalWithNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("invalid character after top-level value")
+	}
 	return nil
 }
 
+// lastModified returns the most recent modification timestamp found in the metadata of the given flags. Note that
+// the flag set metadata is part of the metadata of each flag.
+func lastModified(log *logger.Logger, flags map[string]model.Flag) (time.Time, bool) {
+	var latest time.Time
+	found := false
+	for key, flag := range flags {
+		raw, ok := flag.Metadata[LastModifiedMetadataKey]
+		if !ok {
+			continue
+		}
+		modified, err := parseDate(raw)
+		if err != nil {
+			log.Warn(fmt.Sprintf("ignoring invalid %s metadata of flag %s: %v", LastModifiedMetadataKey, key, err))
+			continue
+		}
+		if !found || modified.After(latest) {
+			latest = modified
+			found = true
+		}
+	}
+	return latest, found
+}
+
 func transposeEvaluators(state string) (string, error) {
 	var evaluators Evaluators
 	if err := json.Unmarshal([]byte(state), &evaluators); err != nil {
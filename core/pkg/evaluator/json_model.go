@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
 )
 
 type Evaluators struct {
@@ -13,8 +14,15 @@ type Evaluators struct {
 type ConfigWithMetadata struct {
 	Flags    map[string]model.Flag  `json:"flags"`
 	Metadata map[string]interface{} `json:"metadata"`
+	Lookups  map[string]LookupTable `json:"$lookups"`
+	Enums    map[string][]any       `json:"$enums"`
 }
 
 type Flags struct {
 	Flags map[string]model.Flag `json:"flags"`
+	// lookups and enums are the declarations the targeting of the flags is compiled with
+	lookups map[string]LookupTable
+	enums   map[string][]any
+	// declarations are the declarations of the configuration as written, which are stored along with the flags
+	declarations store.Declarations
 }
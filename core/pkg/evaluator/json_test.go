@@ -15,6 +15,7 @@ import (
 	"github.com/open-feature/flagd/core/pkg/model"
 	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -794,6 +795,290 @@ func TestResolveAsAnyValue(t *testing.T) {
 	}
 }
 
+func TestJSONNumbers(t *testing.T) {
+	// 2^53 + 1 is the smallest positive integer which can not be represented as float64
+	const largeIntFlags = `{
+		"flags": {
+			"largeInt": {
+				"state": "ENABLED",
+				"variants": {
+					"large": 9007199254740993,
+					"small": 1
+				},
+				"defaultVariant": "large"
+			},
+			"float": {
+				"state": "ENABLED",
+				"variants": {
+					"pi": 3.14
+				},
+				"defaultVariant": "pi"
+			}
+		}
+	}`
+
+	tests := map[string]struct {
+		opts          []evaluator.JSONEvaluatorOption
+		expectedInt   int64
+		expectedJSON  string
+		expectedValue interface{}
+	}{
+		"float64 numbers by default": {
+			expectedInt:   9007199254740992,
+			expectedJSON:  "9007199254740992",
+			expectedValue: float64(9007199254740992),
+		},
+		"json numbers preserve integers": {
+			opts:          []evaluator.JSONEvaluatorOption{evaluator.WithJSONNumbers()},
+			expectedInt:   9007199254740993,
+			expectedJSON:  "9007199254740993",
+			expectedValue: json.Number("9007199254740993"),
+		},
+	}
+
+	const reqID = "default"
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags(), tt.opts...)
+			_, _, err := je.SetState(sync.DataSync{FlagData: largeIntFlags})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			intVal, _, reason, _, err := je.ResolveIntValue(context.TODO(), reqID, "largeInt", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, model.StaticReason, reason)
+			assert.Equal(t, tt.expectedInt, intVal)
+
+			floatVal, _, _, _, err := je.ResolveFloatValue(context.TODO(), reqID, "float", nil)
+			assert.NoError(t, err)
+			assert.Equal(t, 3.14, floatVal)
+
+			_, _, reason, _, err = je.ResolveBooleanValue(context.TODO(), reqID, "largeInt", nil)
+			assert.Equal(t, model.ErrorReason, reason)
+			assert.EqualError(t, err, model.TypeMismatchErrorCode)
+
+			anyVal := je.ResolveAsAnyValue(context.TODO(), reqID, "largeInt", nil)
+			assert.NoError(t, anyVal.Error)
+			assert.Equal(t, tt.expectedValue, anyVal.Value)
+			encoded, err := json.Marshal(anyVal.Value)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedJSON, string(encoded))
+
+			values, err := je.ResolveAllValues(context.TODO(), reqID, nil)
+			assert.NoError(t, err)
+			assert.Len(t, values, 2)
+			for _, value := range values {
+				assert.NoError(t, value.Error)
+				if value.FlagKey == "largeInt" {
+					assert.Equal(t, tt.expectedValue, value.Value)
+				}
+			}
+		})
+	}
+}
+
+type stalenessRecorder struct {
+	telemetry.NoopMetricsRecorder
+	recorded  bool
+	source    string
+	staleness time.Duration
+}
+
+func (r *stalenessRecorder) ConfigStaleness(_ context.Context, source string, staleness time.Duration) {
+	r.recorded = true
+	r.source = source
+	r.staleness = staleness
+}
+
+func TestSetState_ConfigStaleness(t *testing.T) {
+	const flagTemplate = `{
+		"flags": {
+			"flagA": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "on"%s
+			},
+			"flagB": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "on"
+			}
+		}%s
+	}`
+	hourAgo := time.Now().Add(-time.Hour)
+
+	tests := map[string]struct {
+		flagMetadata      string
+		flagSetMetadata   string
+		expectRecorded    bool
+		expectedStaleness time.Duration
+	}{
+		"flag set timestamp": {
+			flagSetMetadata:   fmt.Sprintf(`, "metadata": {"lastModified": "%s"}`, hourAgo.Format(time.RFC3339)),
+			expectRecorded:    true,
+			expectedStaleness: time.Hour,
+		},
+		"unix timestamp": {
+			flagSetMetadata:   fmt.Sprintf(`, "metadata": {"lastModified": %d}`, hourAgo.Unix()),
+			expectRecorded:    true,
+			expectedStaleness: time.Hour,
+		},
+		"most recent flag timestamp": {
+			flagMetadata: fmt.Sprintf(`, "metadata": {"lastModified": "%s"}`,
+				time.Now().Add(-time.Minute).Format(time.RFC3339)),
+			flagSetMetadata:   fmt.Sprintf(`, "metadata": {"lastModified": "%s"}`, hourAgo.Format(time.RFC3339)),
+			expectRecorded:    true,
+			expectedStaleness: time.Minute,
+		},
+		"no timestamp": {
+			flagSetMetadata: `, "metadata": {"version": "v1"}`,
+		},
+		"invalid timestamp": {
+			flagSetMetadata: `, "metadata": {"lastModified": "yesterday"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &stalenessRecorder{}
+			je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags(),
+				evaluator.WithMetricsRecorder(recorder))
+
+			_, _, err := je.SetState(sync.DataSync{
+				FlagData: fmt.Sprintf(flagTemplate, tt.flagMetadata, tt.flagSetMetadata),
+				Source:   "my-source",
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			assert.Equal(t, tt.expectRecorded, recorder.recorded)
+			if tt.expectRecorded {
+				assert.Equal(t, "my-source", recorder.source)
+				// timestamps are serialized with second precision
+				assert.InDelta(t, tt.expectedStaleness.Seconds(), recorder.staleness.Seconds(), 5)
+			}
+		})
+	}
+}
+
+type parseDurationRecorder struct {
+	telemetry.NoopMetricsRecorder
+	sources []string
+}
+
+func (r *parseDurationRecorder) ConfigParseDuration(_ context.Context, source string, _ time.Duration) {
+	r.sources = append(r.sources, source)
+}
+
+func TestSetState_ConfigParseDuration(t *testing.T) {
+	recorder := &parseDurationRecorder{}
+	je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags(), evaluator.WithMetricsRecorder(recorder))
+
+	if _, _, err := je.SetState(sync.DataSync{FlagData: ValidFlags, Source: "valid-source"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// invalid configurations are recorded as well
+	if _, _, err := je.SetState(sync.DataSync{FlagData: InvalidFlags, Source: "invalid-source"}); err == nil {
+		t.Fatal("expected an error for invalid flags")
+	}
+
+	assert.Equal(t, []string{"valid-source", "invalid-source"}, recorder.sources)
+}
+
+type typeMismatchRecorder struct {
+	telemetry.NoopMetricsRecorder
+	mismatches map[string]int
+}
+
+func (r *typeMismatchRecorder) TypeMismatch(_ context.Context, requestedType, actualType string) {
+	r.mismatches[requestedType+"/"+actualType]++
+}
+
+func TestTypeMismatchMetric(t *testing.T) {
+	recorder := &typeMismatchRecorder{mismatches: map[string]int{}}
+	je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags(), evaluator.WithMetricsRecorder(recorder))
+	_, _, err := je.SetState(sync.DataSync{FlagData: Flags})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, _, _, _, err = je.ResolveStringValue(context.TODO(), "", StaticBoolFlag, nil)
+	assert.EqualError(t, err, model.TypeMismatchErrorCode)
+	_, _, _, _, err = je.ResolveStringValue(context.TODO(), "", StaticBoolFlag, nil)
+	assert.EqualError(t, err, model.TypeMismatchErrorCode)
+	_, _, _, _, err = je.ResolveBooleanValue(context.TODO(), "", StaticIntFlag, nil)
+	assert.EqualError(t, err, model.TypeMismatchErrorCode)
+	_, _, _, _, err = je.ResolveIntValue(context.TODO(), "", StaticObjectFlag, nil)
+	assert.EqualError(t, err, model.TypeMismatchErrorCode)
+	_, _, _, _, err = je.ResolveObjectValue(context.TODO(), "", StaticFloatFlag, nil)
+	assert.EqualError(t, err, model.TypeMismatchErrorCode)
+	_, _, _, _, err = je.ResolveFloatValue(context.TODO(), "", StaticStringFlag, nil)
+	assert.EqualError(t, err, model.TypeMismatchErrorCode)
+
+	// other errors and matching types are not recorded
+	_, _, _, _, err = je.ResolveStringValue(context.TODO(), "", MissingFlag, nil)
+	assert.EqualError(t, err, model.FlagNotFoundErrorCode)
+	_, _, _, _, err = je.ResolveBooleanValue(context.TODO(), "", StaticBoolFlag, nil)
+	assert.NoError(t, err)
+
+	// numbers without fractional part, such as the value of the float flag, are integers
+	assert.Equal(t, map[string]int{
+		telemetry.TypeString + "/" + telemetry.TypeBoolean:  2,
+		telemetry.TypeBoolean + "/" + telemetry.TypeInteger: 1,
+		telemetry.TypeInteger + "/" + telemetry.TypeObject:  1,
+		telemetry.TypeObject + "/" + telemetry.TypeInteger:  1,
+		telemetry.TypeFloat + "/" + telemetry.TypeString:    1,
+	}, recorder.mismatches)
+}
+
+func TestFlagSetFallback(t *testing.T) {
+	flags := store.NewFlags()
+	flags.FlagSources = []string{"base", "tenant"}
+	flags.FlagSetFallback = []string{"tenant-a", "base"}
+	je := evaluator.NewJSON(logger.NewLogger(nil, false), flags)
+
+	_, _, err := je.SetState(sync.DataSync{Source: "base", FlagData: `{
+		"metadata": {"flagSetId": "base"},
+		"flags": {
+			"banner": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off"},
+			"checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off"}
+		}
+	}`})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	_, _, err = je.SetState(sync.DataSync{Source: "tenant", FlagData: `{
+		"metadata": {"flagSetId": "tenant-a"},
+		"flags": {
+			"banner": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// the tenant flag set answers if it defines the flag
+	value, _, reason, metadata, err := je.ResolveBooleanValue(context.TODO(), "", "banner", nil)
+	assert.NoError(t, err)
+	assert.True(t, value)
+	assert.Equal(t, model.StaticReason, reason)
+	assert.Equal(t, "tenant-a", metadata["flagSetId"])
+
+	// otherwise the lookup falls back to the base flag set
+	value, _, reason, metadata, err = je.ResolveBooleanValue(context.TODO(), "", "checkout", nil)
+	assert.NoError(t, err)
+	assert.False(t, value)
+	assert.Equal(t, model.StaticReason, reason)
+	assert.Equal(t, "base", metadata["flagSetId"])
+
+	values, err := je.ResolveAllValues(context.TODO(), "", nil)
+	assert.NoError(t, err)
+	assert.Len(t, values, 2)
+}
+
 func TestSetState_DefaultVariantValidation(t *testing.T) {
 	tests := map[string]struct {
 		jsonFlags string
@@ -1750,4 +2035,188 @@ func TestTargetingVariantBehavior(t *testing.T) {
 			t.Fatal("did not map to stringified boolean")
 		}
 	})
+
+	t.Run("map boolean result to boolean variant", func(t *testing.T) {
+		evaluator := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+		_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+			"flags": {
+				"dynamic-boolean": {
+					"state": "ENABLED",
+					"variants": {
+						"on": true,
+						"off": false
+					},
+					"defaultVariant": "off",
+					"targeting": {
+						"==": [{ "var": "tier" }, "premium"]
+					}
+				}
+			}
+		}`})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for tier, expected := range map[string]string{"premium": "on", "free": "off"} {
+			value, variant, reason, _, err := evaluator.ResolveBooleanValue(
+				context.Background(), "default", "dynamic-boolean", map[string]any{"tier": tier})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if variant != expected || value != (expected == "on") || reason != model.TargetingMatchReason {
+				t.Fatalf("tier %s did not map to variant %s, got %s", tier, expected, variant)
+			}
+		}
+	})
+
+	t.Run("ambiguous boolean variants error", func(t *testing.T) {
+		evaluator := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+		_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+			"flags": {
+				"ambiguous-boolean": {
+					"state": "ENABLED",
+					"variants": {
+						"on": true,
+						"enabled": true,
+						"off": false
+					},
+					"defaultVariant": "off",
+					"targeting": {
+						"==": [1, 1]
+					}
+				}
+			}
+		}`})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "default", "ambiguous-boolean", nil)
+		if err == nil {
+			t.Fatal("ambiguous boolean variant did not result in error")
+		}
+	})
+
+	t.Run("only boolean results map to boolean variants", func(t *testing.T) {
+		evaluator := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+		_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+			"flags": {
+				"context-boolean": {
+					"state": "ENABLED",
+					"variants": {
+						"on": true,
+						"off": false
+					},
+					"defaultVariant": "off",
+					"targeting": {
+						"var": "enabled"
+					}
+				}
+			}
+		}`})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, enabled := range []any{1, 0, "t", "F", "true"} {
+			_, _, _, _, err = evaluator.ResolveBooleanValue(
+				context.Background(), "default", "context-boolean", map[string]any{"enabled": enabled})
+			if err == nil {
+				t.Fatalf("result %v mapped to a boolean variant", enabled)
+			}
+		}
+	})
+
+	t.Run("variant names take precedence over boolean mapping", func(t *testing.T) {
+		evaluator := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+		//nolint:dupword
+		_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+			"flags": {
+				"inverted-boolean": {
+					"state": "ENABLED",
+					"variants": {
+						"true": false,
+						"false": true
+					},
+					"defaultVariant": "false",
+					"targeting": {
+						"==": [1, 1]
+					}
+				}
+			}
+		}`})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		value, variant, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "default", "inverted-boolean", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value || variant != "true" || reason != model.TargetingMatchReason {
+			t.Fatal("did not resolve the variant named after the boolean result")
+		}
+	})
+}
+
+func TestBooleanTargetingValidation(t *testing.T) {
+	tests := map[string]struct {
+		targeting string
+		wantErr   bool
+	}{
+		"boolean operator": {
+			targeting: `{"in": ["@example.com", {"var": "email"}]}`,
+		},
+		"boolean literals": {
+			targeting: `{"if": [{"var": "beta"}, true, false]}`,
+		},
+		"variant names": {
+			targeting: `{"if": [{"var": "beta"}, "on", "off"]}`,
+		},
+		"context dependent result": {
+			targeting: `{"var": "enabled"}`,
+		},
+		"number literal": {
+			targeting: `{"if": [{"var": "beta"}, 1, "off"]}`,
+			wantErr:   true,
+		},
+		"arithmetic result": {
+			targeting: `{"or": [{"var": "enabled"}, {"+": [1, 2]}]}`,
+			wantErr:   true,
+		},
+		"array result": {
+			targeting: `{"if": [{"var": "beta"}, {"merge": [[true], [false]]}]}`,
+			wantErr:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: fmt.Sprintf(`{
+				"flags": {
+					"boolean-flag": {
+						"state": "ENABLED",
+						"variants": {
+							"on": true,
+							"off": false
+						},
+						"defaultVariant": "off",
+						"targeting": %s
+					}
+				}
+			}`, tt.targeting)})
+
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
 }
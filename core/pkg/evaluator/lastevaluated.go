@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/store"
+)
+
+// WithLastEvaluated records the time each flag was last evaluated at in the given tracker
+func WithLastEvaluated(tracker *LastEvaluated) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.lastEvaluated = tracker
+	}
+}
+
+// FlagLastEvaluated is the time a flag was last evaluated at, with a second granularity. The time is nil if the flag
+// wasn't evaluated since the tracking started.
+type FlagLastEvaluated struct {
+	FlagKey       string     `json:"flagKey"`
+	LastEvaluated *time.Time `json:"lastEvaluated"`
+}
+
+// LastEvaluated tracks the time each flag was last evaluated at in memory, so that flags which aren't evaluated
+// anymore can be identified for cleanup. Recording an evaluation is an atomic store of a timestamp in seconds, which is
+// skipped if the flag was already evaluated within the same second. At most the configured number of flags is tracked,
+// evaluations of further flags are not recorded until flags are removed from the configuration.
+type LastEvaluated struct {
+	size    int64
+	tracked atomic.Int64
+	// flags maps the flag keys to the unix timestamp of their last evaluation, as *atomic.Int64
+	flags sync.Map
+	since time.Time
+	store store.IStore
+	now   func() time.Time
+}
+
+// NewLastEvaluated returns a LastEvaluated tracking up to size flags
+func NewLastEvaluated(size int) *LastEvaluated {
+	if size < 1 {
+		size = 1
+	}
+	return &LastEvaluated{size: int64(size), since: time.Now().UTC(), now: time.Now}
+}
+
+// Since returns the time the tracking started at. Flags without evaluation may still be evaluated less frequently than
+// the time passed since.
+func (l *LastEvaluated) Since() time.Time {
+	return l.since
+}
+
+// record records an evaluation of the flag of the given key
+func (l *LastEvaluated) record(flagKey string) {
+	now := l.now().Unix()
+	last, ok := l.flags.Load(flagKey)
+	if !ok {
+		if l.tracked.Load() >= l.size {
+			return
+		}
+		var loaded bool
+		if last, loaded = l.flags.LoadOrStore(flagKey, &atomic.Int64{}); !loaded {
+			l.tracked.Add(1)
+		}
+	}
+	timestamp, _ := last.(*atomic.Int64)
+	if timestamp.Load() != now {
+		timestamp.Store(now)
+	}
+}
+
+// prune stops tracking the flags which are no longer defined
+func (l *LastEvaluated) prune(flags map[string]bool) {
+	l.flags.Range(func(key, _ any) bool {
+		if flagKey, _ := key.(string); !flags[flagKey] {
+			if _, deleted := l.flags.LoadAndDelete(key); deleted {
+				l.tracked.Add(-1)
+			}
+		}
+		return true
+	})
+}
+
+// Flags returns the time each defined flag was last evaluated at, flags which weren't evaluated first and the least
+// recently evaluated flags next, so that the flags evaluated the longest time ago are listed first
+func (l *LastEvaluated) Flags(ctx context.Context) ([]FlagLastEvaluated, error) {
+	flags, err := l.store.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving flags from the store: %w", err)
+	}
+
+	result := make([]FlagLastEvaluated, 0, len(flags))
+	for flagKey := range flags {
+		entry := FlagLastEvaluated{FlagKey: flagKey}
+		if last, ok := l.flags.Load(flagKey); ok {
+			if timestamp, _ := last.(*atomic.Int64); timestamp.Load() != 0 {
+				evaluated := time.Unix(timestamp.Load(), 0).UTC()
+				entry.LastEvaluated = &evaluated
+			}
+		}
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].LastEvaluated, result[j].LastEvaluated
+		switch {
+		case a == nil && b == nil:
+			return result[i].FlagKey < result[j].FlagKey
+		case a == nil || b == nil:
+			return a == nil
+		case a.Equal(*b):
+			return result[i].FlagKey < result[j].FlagKey
+		default:
+			return a.Before(*b)
+		}
+	})
+	return result, nil
+}
+
+// pruneLastEvaluated stops tracking the flags removed by an applied configuration
+func (je *JSON) pruneLastEvaluated(ctx context.Context) {
+	flags, err := je.store.GetAll(ctx)
+	if err != nil {
+		je.Logger.Warn(fmt.Sprintf("error pruning the last evaluated flags: %v", err))
+		return
+	}
+	defined := make(map[string]bool, len(flags))
+	for flagKey := range flags {
+		defined[flagKey] = true
+	}
+	je.lastEvaluated.prune(defined)
+}
@@ -0,0 +1,97 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastEvaluated(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewLastEvaluated(2)
+	tracker.now = func() time.Time { return clock }
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithLastEvaluated(tracker))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"a": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"},
+			"b": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"},
+			"c": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"},
+			"d": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+
+	evaluate := func(flagKey string) {
+		_, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", flagKey, nil)
+		require.NoError(t, err)
+	}
+	evaluate("b")
+	clock = clock.Add(time.Minute)
+	evaluate("a")
+	evaluate("c")
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "unknown", nil)
+	require.Error(t, err)
+
+	first := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Minute)
+	flags, err := tracker.Flags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []FlagLastEvaluated{
+		{FlagKey: "c"},
+		{FlagKey: "d"},
+		{FlagKey: "b", LastEvaluated: &first},
+		{FlagKey: "a", LastEvaluated: &second},
+	}, flags, "at most two flags are tracked")
+
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"c": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+	evaluate("c")
+
+	flags, err = tracker.Flags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []FlagLastEvaluated{{FlagKey: "c", LastEvaluated: &second}}, flags,
+		"removed flags aren't tracked anymore")
+}
+
+func TestLastEvaluatedIgnoresHistoricalEvaluations(t *testing.T) {
+	tracker := NewLastEvaluated(10)
+	history := NewConfigHistory(2)
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithLastEvaluated(tracker),
+		WithConfigHistory(history))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"a": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+
+	resolver, ok := history.Resolver(history.Versions()[0].Version)
+	require.True(t, ok)
+	_, _, _, _, err = resolver.ResolveBooleanValue(context.Background(), "req", "a", nil)
+	require.NoError(t, err)
+
+	flags, err := tracker.Flags(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []FlagLastEvaluated{{FlagKey: "a"}}, flags)
+}
+
+func BenchmarkLastEvaluatedRecord(b *testing.B) {
+	tracker := NewLastEvaluated(10)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tracker.record("flag")
+		}
+	})
+}
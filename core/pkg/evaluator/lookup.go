@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+const LookupEvaluationName = "lookup"
+
+// LookupTable is a static mapping declared in the $lookups of a configuration, keys missing from the entries map to
+// the default value
+type LookupTable struct {
+	Entries map[string]json.RawMessage `json:"entries"`
+	Default json.RawMessage            `json:"default"`
+}
+
+type Lookup struct {
+	Logger *logger.Logger
+}
+
+func NewLookup(log *logger.Logger) *Lookup {
+	return &Lookup{Logger: log}
+}
+
+// LookupEvaluation returns the value mapped to a key by a lookup table declared in the $lookups of the configuration,
+// or the default value of the table if the key is missing.
+// As an example, a table mapping countries to tiers can be declared as:
+//
+//	"$lookups": {
+//	  "countryToTier": {
+//	    "entries": {"DE": "gold", "FR": "silver"},
+//	    "default": "bronze"
+//	  }
+//	}
+//
+// and used in the following way to select the variant of a flag:
+//
+//	{
+//	  "lookup": ["countryToTier", {"var": "country"}]
+//	}
+//
+// The table name is replaced by the table when the flag definition is loaded, referencing an undeclared table fails
+// the load. Numbers and booleans are looked up by their JSON representation, e.g. a key of 1 matches the entry "1".
+func (l *Lookup) LookupEvaluation(values, _ interface{}) interface{} {
+	args, ok := values.([]any)
+	if !ok || len(args) != 2 {
+		l.Logger.Error(fmt.Sprintf("parse lookup evaluation data: expected a table and a key, got %v", values))
+		return nil
+	}
+	table, ok := args[0].([]any)
+	if !ok || len(table) != 2 {
+		l.Logger.Error(fmt.Sprintf("parse lookup evaluation data: unresolved lookup table %v", args[0]))
+		return nil
+	}
+	entries, ok := table[0].(map[string]any)
+	if !ok {
+		l.Logger.Error(fmt.Sprintf("parse lookup evaluation data: unresolved lookup table %v", args[0]))
+		return nil
+	}
+
+	key, ok := lookupKey(args[1])
+	if !ok {
+		return table[1]
+	}
+	if value, ok := entries[key]; ok {
+		return value
+	}
+	return table[1]
+}
+
+// lookupKey returns the key of a looked up value, null and compound values aren't keys
+func lookupKey(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		return "", false
+	}
+}
+
+// lookupTable returns the arguments of a lookup operation with its table name replaced by the [entries, default]
+// array of the table. The key operand is returned as is, so that nested rules are resolved by the caller.
+func lookupTable(args any, tables map[string]LookupTable) ([]any, error) {
+	list, ok := args.([]any)
+	if !ok || len(list) != 2 {
+		return nil, errors.New("lookup expects a table name and a key")
+	}
+	name, ok := list[0].(string)
+	if !ok {
+		return nil, errors.New("lookup table name must be a string")
+	}
+	table, ok := tables[name]
+	if !ok {
+		return nil, fmt.Errorf("lookup table '%s' is not declared", name)
+	}
+
+	entries := make(map[string]any, len(table.Entries))
+	for key, value := range table.Entries {
+		entries[key] = value
+	}
+	// a missing default is encoded as null
+	return []any{[]any{entries, table.Default}, list[1]}, nil
+}
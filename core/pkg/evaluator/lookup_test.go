@@ -0,0 +1,186 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lookupConfig = `{
+	"$lookups": {
+		"countryToTier": {
+			"entries": {"DE": "gold", "FR": "silver", "1": "silver"},
+			"default": "bronze"
+		}
+	},
+	"flags": {
+		"tier": {
+			"state": "ENABLED",
+			"variants": {"none": "none", "gold": "gold", "silver": "silver", "bronze": "bronze"},
+			"defaultVariant": "none",
+			"targeting": {"lookup": ["countryToTier", {"var": "country"}]}
+		}
+	}
+}`
+
+func TestLookupEvaluation(t *testing.T) {
+	table := []any{map[string]any{"DE": "gold", "1": "silver", "true": "platinum"}, "bronze"}
+
+	tests := map[string]struct {
+		values   any
+		expected any
+	}{
+		"mapped key": {
+			values:   []any{table, "DE"},
+			expected: "gold",
+		},
+		"missing key": {
+			values:   []any{table, "US"},
+			expected: "bronze",
+		},
+		"number key": {
+			values:   []any{table, float64(1)},
+			expected: "silver",
+		},
+		"boolean key": {
+			values:   []any{table, true},
+			expected: "platinum",
+		},
+		"null key": {
+			values:   []any{table, nil},
+			expected: "bronze",
+		},
+		"unresolved table": {
+			values: []any{"countryToTier", "DE"},
+		},
+		"missing key operand": {
+			values: []any{table},
+		},
+	}
+
+	lookup := NewLookup(logger.NewLogger(nil, false))
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, lookup.LookupEvaluation(tt.values, nil))
+		})
+	}
+}
+
+func TestResolveLookupTables(t *testing.T) {
+	tables := map[string]LookupTable{
+		"countryToTier": {
+			Entries: map[string]json.RawMessage{"DE": json.RawMessage(`"gold"`)},
+			Default: json.RawMessage(`"bronze"`),
+		},
+	}
+
+	tests := map[string]struct {
+		targeting string
+		expected  string
+		err       string
+	}{
+		"resolved table": {
+			targeting: `{"lookup": ["countryToTier", {"var": "country"}]}`,
+			expected:  `{"lookup": [[{"DE": "gold"}, "bronze"], {"var": "country"}]}`,
+		},
+		"nested lookup": {
+			targeting: `{"if": [{"==": [{"lookup": ["countryToTier", {"var": "country"}]}, "gold"]}, "on", "off"]}`,
+			expected:  `{"if": [{"==": [{"lookup": [[{"DE": "gold"}, "bronze"], {"var": "country"}]}, "gold"]}, "on", "off"]}`,
+		},
+		"without lookup": {
+			targeting: `{"in": [{"var": "country"}, ["DE", "FR"]]}`,
+			expected:  `{"in": [{"var": "country"}, ["DE", "FR"]]}`,
+		},
+		"undeclared table": {
+			targeting: `{"lookup": ["countryToRegion", {"var": "country"}]}`,
+			err:       "invalid targeting of flag: 'flag': lookup table 'countryToRegion' is not declared",
+		},
+		"table name isn't a string": {
+			targeting: `{"lookup": [1, {"var": "country"}]}`,
+			err:       "invalid targeting of flag: 'flag': lookup table name must be a string",
+		},
+		"missing key operand": {
+			targeting: `{"lookup": ["countryToTier"]}`,
+			err:       "invalid targeting of flag: 'flag': lookup expects a table name and a key",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := &Flags{
+				Flags:   map[string]model.Flag{"flag": {Targeting: json.RawMessage(tt.targeting)}},
+				lookups: tables,
+			}
+			err := compileTargeting(logger.NewLogger(nil, false), flags, false)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(evaluatedTargeting(flags.Flags["flag"])))
+			// the targeting is kept as written
+			assert.JSONEq(t, tt.targeting, string(flags.Flags["flag"].Targeting))
+		})
+	}
+}
+
+func TestLookupFlag(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: lookupConfig})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		context  map[string]any
+		expected string
+	}{
+		"mapped country": {
+			context:  map[string]any{"country": "DE"},
+			expected: "gold",
+		},
+		"unmapped country": {
+			context:  map[string]any{"country": "US"},
+			expected: "bronze",
+		},
+		"number key": {
+			context:  map[string]any{"country": 1},
+			expected: "silver",
+		},
+		"missing country": {
+			context:  map[string]any{},
+			expected: "bronze",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, variant, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", "tier", tt.context)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+			assert.Equal(t, tt.expected, variant)
+			assert.Equal(t, model.TargetingMatchReason, reason)
+		})
+	}
+}
+
+func TestLookupUndeclaredTable(t *testing.T) {
+	const config = `{
+		"flags": {
+			"tier": {
+				"state": "ENABLED",
+				"variants": {"gold": "gold", "bronze": "bronze"},
+				"defaultVariant": "bronze",
+				"targeting": {"lookup": ["countryToTier", {"var": "country"}]}
+			}
+		}
+	}`
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.ErrorContains(t, err, "lookup table 'countryToTier' is not declared")
+}
@@ -0,0 +1,28 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// ErrFlagLimitExceeded is returned for configurations defining more flags than the configured maximum
+var ErrFlagLimitExceeded = errors.New("flag limit exceeded")
+
+// WithMaxFlags rejects configurations defining more than the given number of flags, protecting a shared flagd from
+// runaway configuration generators. Zero doesn't limit the number of flags.
+func WithMaxFlags(maxFlags int) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.maxFlags = maxFlags
+	}
+}
+
+// checkFlagLimit rejects configurations defining more flags than the maximum, deletions are never rejected
+func (je *JSON) checkFlagLimit(payload sync.DataSync, flags *Flags) error {
+	if je.maxFlags <= 0 || payload.Type == sync.DELETE || len(flags.Flags) <= je.maxFlags {
+		return nil
+	}
+	return fmt.Errorf("%w: configuration defines %d flags, exceeding the maximum of %d flags",
+		ErrFlagLimitExceeded, len(flags.Flags), je.maxFlags)
+}
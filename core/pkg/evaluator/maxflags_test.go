@@ -0,0 +1,70 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxFlags(t *testing.T) {
+	const oneFlag = `{
+		"flags": {
+			"a": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`
+	const twoFlags = `{
+		"flags": {
+			"a": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off"},
+			"b": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`
+
+	tests := map[string]struct {
+		maxFlags int
+		syncType sync.Type
+		err      string
+	}{
+		"unlimited": {
+			syncType: sync.ALL,
+		},
+		"within the limit": {
+			maxFlags: 2,
+			syncType: sync.ALL,
+		},
+		"exceeding the limit": {
+			maxFlags: 1,
+			syncType: sync.ALL,
+			err:      "flag limit exceeded: configuration defines 2 flags, exceeding the maximum of 1 flags",
+		},
+		"deletions aren't limited": {
+			maxFlags: 1,
+			syncType: sync.DELETE,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMaxFlags(tt.maxFlags))
+			_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: oneFlag})
+			require.NoError(t, err)
+
+			_, _, err = evaluator.SetState(sync.DataSync{Source: "file", Type: tt.syncType, FlagData: twoFlags})
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrFlagLimitExceeded)
+			require.EqualError(t, err, tt.err)
+
+			// the last valid configuration is kept
+			value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", "a", nil)
+			require.NoError(t, err)
+			assert.True(t, value)
+		})
+	}
+}
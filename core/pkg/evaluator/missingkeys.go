@@ -0,0 +1,135 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	gosync "sync"
+
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+// maxCachedTargetings bounds the targeting rules whose referenced context keys are cached
+const maxCachedTargetings = 1024
+
+// WithMissingContextKeys records the missing context key metric for the given context keys, whenever a targeting rule
+// referencing one of them is evaluated against a context lacking it. Nested keys are addressed by their dot separated
+// path, e.g. "user.email". Keys that aren't listed are never recorded, bounding the cardinality of the metric.
+func WithMissingContextKeys(keys ...string) JSONEvaluatorOption {
+	return func(je *JSON) {
+		if len(keys) > 0 {
+			je.missingKeys = newMissingContextKeys(keys)
+		}
+	}
+}
+
+// missingContextKeys tracks the context keys referenced by targeting rules, limited to the tracked keys
+type missingContextKeys struct {
+	tracked map[string]bool
+	mu      gosync.RWMutex
+	// referenced caches the tracked keys referenced by a targeting rule, keyed by the rule
+	referenced map[string][]string
+}
+
+func newMissingContextKeys(keys []string) *missingContextKeys {
+	tracked := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		tracked[key] = true
+	}
+	return &missingContextKeys{tracked: tracked}
+}
+
+// missing returns the tracked context keys referenced by a targeting rule which are absent from the context
+func (m *missingContextKeys) missing(targeting []byte, evalCtx map[string]any) []string {
+	var missing []string
+	for _, key := range m.references(targeting) {
+		if !hasContextKey(evalCtx, key) {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// references returns the cached tracked keys referenced by a targeting rule, collecting them if they aren't cached yet
+func (m *missingContextKeys) references(targeting []byte) []string {
+	m.mu.RLock()
+	keys, ok := m.referenced[string(targeting)]
+	m.mu.RUnlock()
+	if ok {
+		return keys
+	}
+
+	var rule any
+	if err := json.Unmarshal(targeting, &rule); err == nil {
+		keys = m.collect(rule, keys)
+	}
+	m.mu.Lock()
+	if m.referenced == nil || len(m.referenced) >= maxCachedTargetings {
+		// start over instead of growing without limit, e.g. for frequently changing configurations
+		m.referenced = map[string][]string{}
+	}
+	m.referenced[string(targeting)] = keys
+	m.mu.Unlock()
+	return keys
+}
+
+// collect recursively appends the tracked keys referenced by the var operations of a rule. Only literal keys are
+// collected, keys computed by nested rules are unknown until evaluation.
+func (m *missingContextKeys) collect(rule any, keys []string) []string {
+	switch r := rule.(type) {
+	case map[string]any:
+		for operator, args := range r {
+			if operator == "var" {
+				if key, ok := varKey(args); ok && m.tracked[key] && !slices.Contains(keys, key) {
+					keys = append(keys, key)
+				}
+				continue
+			}
+			keys = m.collect(args, keys)
+		}
+	case []any:
+		for _, arg := range r {
+			keys = m.collect(arg, keys)
+		}
+	}
+	return keys
+}
+
+// varKey returns the literal key of a var operation, given either as the key or as [key, default]
+func varKey(args any) (string, bool) {
+	if list, ok := args.([]any); ok {
+		if len(list) == 0 {
+			return "", false
+		}
+		args = list[0]
+	}
+	key, ok := args.(string)
+	return key, ok
+}
+
+// hasContextKey reports whether the context holds a dot separated key path
+func hasContextKey(evalCtx map[string]any, key string) bool {
+	var current any = evalCtx
+	for _, part := range strings.Split(key, ".") {
+		nested, ok := current.(map[string]any)
+		if !ok {
+			return false
+		}
+		if current, ok = nested[part]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// recordMissingContextKeys records the tracked context keys referenced by the targeting of a flag which are absent
+// from the evaluation context, if any keys are tracked
+func (je *Resolver) recordMissingContextKeys(ctx context.Context, targeting []byte, evalCtx map[string]any) {
+	if je.missingKeys == nil {
+		return
+	}
+	for _, key := range je.missingKeys.missing(targeting, evalCtx) {
+		telemetry.EvaluationMetrics(je.metrics).MissingContextKey(ctx, key)
+	}
+}
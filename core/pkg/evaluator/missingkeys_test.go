@@ -0,0 +1,108 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type missingKeyRecorder struct {
+	telemetry.NoopMetricsRecorder
+	keys []string
+}
+
+func (r *missingKeyRecorder) MissingContextKey(_ context.Context, contextKey string) {
+	r.keys = append(r.keys, contextKey)
+}
+
+func TestMissingContextKeys(t *testing.T) {
+	const config = `{
+		"flags": {
+			"premium": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [{"and": [
+					{"ends_with": [{"var": "email"}, "@example.com"]},
+					{"==": [{"var": ["user.tier", "free"]}, "gold"]},
+					{"!=": [{"var": "country"}, "US"]}
+				]}, "on", "off"]}
+			},
+			"static": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off"
+			}
+		}
+	}`
+
+	tests := map[string]struct {
+		flagKey  string
+		context  map[string]any
+		expected []string
+	}{
+		"all keys present": {
+			flagKey: "premium",
+			context: map[string]any{"email": "a@example.com", "user": map[string]any{"tier": "gold"}},
+		},
+		"missing key": {
+			flagKey:  "premium",
+			context:  map[string]any{"user": map[string]any{"tier": "gold"}},
+			expected: []string{"email"},
+		},
+		"missing nested key": {
+			flagKey:  "premium",
+			context:  map[string]any{"email": "a@example.com", "user": "gold"},
+			expected: []string{"user.tier"},
+		},
+		"untracked keys aren't recorded": {
+			flagKey:  "premium",
+			context:  map[string]any{},
+			expected: []string{"email", "user.tier"},
+		},
+		"flag without targeting": {
+			flagKey: "static",
+			context: map[string]any{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &missingKeyRecorder{}
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(),
+				WithMetricsRecorder(recorder), WithMissingContextKeys("email", "user.tier"))
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+			require.NoError(t, err)
+
+			_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", tt.flagKey, tt.context)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expected, recorder.keys)
+		})
+	}
+}
+
+func TestMissingContextKeysDisabled(t *testing.T) {
+	recorder := &missingKeyRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"premium": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [{"var": "email"}, "on", "off"]}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "premium", map[string]any{})
+	require.NoError(t, err)
+	assert.Empty(t, recorder.keys)
+}
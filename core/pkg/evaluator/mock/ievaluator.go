@@ -22,6 +22,7 @@ import (
 type MockIEvaluator struct {
 	ctrl     *gomock.Controller
 	recorder *MockIEvaluatorMockRecorder
+	isgomock struct{}
 }
 
 // MockIEvaluatorMockRecorder is the mock recorder for MockIEvaluator.
@@ -71,6 +72,22 @@ func (mr *MockIEvaluatorMockRecorder) ResolveAllValues(ctx, reqID, context any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAllValues", reflect.TypeOf((*MockIEvaluator)(nil).ResolveAllValues), ctx, reqID, context)
 }
 
+// ResolveAllValuesWithVersion mocks base method.
+func (m *MockIEvaluator) ResolveAllValuesWithVersion(ctx context.Context, reqID string, context map[string]any) ([]evaluator.AnyValue, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveAllValuesWithVersion", ctx, reqID, context)
+	ret0, _ := ret[0].([]evaluator.AnyValue)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResolveAllValuesWithVersion indicates an expected call of ResolveAllValuesWithVersion.
+func (mr *MockIEvaluatorMockRecorder) ResolveAllValuesWithVersion(ctx, reqID, context any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAllValuesWithVersion", reflect.TypeOf((*MockIEvaluator)(nil).ResolveAllValuesWithVersion), ctx, reqID, context)
+}
+
 // ResolveAsAnyValue mocks base method.
 func (m *MockIEvaluator) ResolveAsAnyValue(ctx context.Context, reqID, flagKey string, context map[string]any) evaluator.AnyValue {
 	m.ctrl.T.Helper()
@@ -85,6 +102,20 @@ func (mr *MockIEvaluatorMockRecorder) ResolveAsAnyValue(ctx, reqID, flagKey, con
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAsAnyValue", reflect.TypeOf((*MockIEvaluator)(nil).ResolveAsAnyValue), ctx, reqID, flagKey, context)
 }
 
+// ResolveBatchValues mocks base method.
+func (m *MockIEvaluator) ResolveBatchValues(ctx context.Context, reqID, flagKey string, contexts []map[string]any) []evaluator.AnyValue {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveBatchValues", ctx, reqID, flagKey, contexts)
+	ret0, _ := ret[0].([]evaluator.AnyValue)
+	return ret0
+}
+
+// ResolveBatchValues indicates an expected call of ResolveBatchValues.
+func (mr *MockIEvaluatorMockRecorder) ResolveBatchValues(ctx, reqID, flagKey, contexts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveBatchValues", reflect.TypeOf((*MockIEvaluator)(nil).ResolveBatchValues), ctx, reqID, flagKey, contexts)
+}
+
 // ResolveBooleanValue mocks base method.
 func (m *MockIEvaluator) ResolveBooleanValue(ctx context.Context, reqID, flagKey string, context map[string]any) (bool, string, string, map[string]any, error) {
 	m.ctrl.T.Helper()
@@ -191,10 +222,27 @@ func (mr *MockIEvaluatorMockRecorder) SetState(payload any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetState", reflect.TypeOf((*MockIEvaluator)(nil).SetState), payload)
 }
 
+// SetStateWithContext mocks base method.
+func (m *MockIEvaluator) SetStateWithContext(ctx context.Context, payload sync.DataSync) (map[string]any, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStateWithContext", ctx, payload)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SetStateWithContext indicates an expected call of SetStateWithContext.
+func (mr *MockIEvaluatorMockRecorder) SetStateWithContext(ctx, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStateWithContext", reflect.TypeOf((*MockIEvaluator)(nil).SetStateWithContext), ctx, payload)
+}
+
 // MockIResolver is a mock of IResolver interface.
 type MockIResolver struct {
 	ctrl     *gomock.Controller
 	recorder *MockIResolverMockRecorder
+	isgomock struct{}
 }
 
 // MockIResolverMockRecorder is the mock recorder for MockIResolver.
@@ -229,6 +277,22 @@ func (mr *MockIResolverMockRecorder) ResolveAllValues(ctx, reqID, context any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAllValues", reflect.TypeOf((*MockIResolver)(nil).ResolveAllValues), ctx, reqID, context)
 }
 
+// ResolveAllValuesWithVersion mocks base method.
+func (m *MockIResolver) ResolveAllValuesWithVersion(ctx context.Context, reqID string, context map[string]any) ([]evaluator.AnyValue, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveAllValuesWithVersion", ctx, reqID, context)
+	ret0, _ := ret[0].([]evaluator.AnyValue)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ResolveAllValuesWithVersion indicates an expected call of ResolveAllValuesWithVersion.
+func (mr *MockIResolverMockRecorder) ResolveAllValuesWithVersion(ctx, reqID, context any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAllValuesWithVersion", reflect.TypeOf((*MockIResolver)(nil).ResolveAllValuesWithVersion), ctx, reqID, context)
+}
+
 // ResolveAsAnyValue mocks base method.
 func (m *MockIResolver) ResolveAsAnyValue(ctx context.Context, reqID, flagKey string, context map[string]any) evaluator.AnyValue {
 	m.ctrl.T.Helper()
@@ -243,6 +307,20 @@ func (mr *MockIResolverMockRecorder) ResolveAsAnyValue(ctx, reqID, flagKey, cont
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveAsAnyValue", reflect.TypeOf((*MockIResolver)(nil).ResolveAsAnyValue), ctx, reqID, flagKey, context)
 }
 
+// ResolveBatchValues mocks base method.
+func (m *MockIResolver) ResolveBatchValues(ctx context.Context, reqID, flagKey string, contexts []map[string]any) []evaluator.AnyValue {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveBatchValues", ctx, reqID, flagKey, contexts)
+	ret0, _ := ret[0].([]evaluator.AnyValue)
+	return ret0
+}
+
+// ResolveBatchValues indicates an expected call of ResolveBatchValues.
+func (mr *MockIResolverMockRecorder) ResolveBatchValues(ctx, reqID, flagKey, contexts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveBatchValues", reflect.TypeOf((*MockIResolver)(nil).ResolveBatchValues), ctx, reqID, flagKey, contexts)
+}
+
 // ResolveBooleanValue mocks base method.
 func (m *MockIResolver) ResolveBooleanValue(ctx context.Context, reqID, flagKey string, context map[string]any) (bool, string, string, map[string]any, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,52 @@
+package evaluator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+const NowEvaluationName = "now"
+
+// WithEvaluationClock overrides the source of the evaluation timestamp, the '$flagd.timestamp' property returned by
+// the 'now' operation, mainly useful for testing
+func WithEvaluationClock(now func() time.Time) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.now = now
+	}
+}
+
+type Now struct {
+	Logger *logger.Logger
+}
+
+func NewNow(log *logger.Logger) *Now {
+	return &Now{Logger: log}
+}
+
+// NowEvaluation returns the timestamp of the evaluation in seconds since the unix epoch. The timestamp is captured once
+// at the start of the evaluation, hence all references within the targeting of a flag agree on the same instant.
+// As an example, it can be used in the following way inside an 'if' evaluation to target the evaluations after the
+// 1st of January 2025:
+//
+//	{
+//	  "if": [
+//			{
+//				">": [{"now": []}, 1735689600]
+//			},
+//			"new-year", null
+//			]
+//	}
+//
+// The operation takes no operands, and returns null if the data lacks the evaluation timestamp.
+func (n *Now) NowEvaluation(_, data interface{}) interface{} {
+	context, _ := data.(map[string]any)
+	properties, _ := context[flagdPropertiesKey].(map[string]any)
+	timestamp, ok := properties["timestamp"]
+	if !ok {
+		n.Logger.Error(fmt.Sprintf("now evaluation: the data lacks the %s.timestamp property", flagdPropertiesKey))
+		return nil
+	}
+	return timestamp
+}
@@ -0,0 +1,74 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNowEvaluation(t *testing.T) {
+	n := NewNow(logger.NewLogger(nil, false))
+
+	data := map[string]any{flagdPropertiesKey: map[string]any{"timestamp": float64(1735689600)}}
+	assert.Equal(t, float64(1735689600), n.NowEvaluation([]any{}, data))
+	assert.Nil(t, n.NowEvaluation([]any{}, map[string]any{}))
+	assert.Nil(t, n.NowEvaluation([]any{}, nil))
+}
+
+func TestNowTargeting(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithEvaluationClock(func() time.Time {
+		calls++
+		return clock
+	}))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"newYear": {
+				"state": "ENABLED",
+				"variants": {"before": "before", "after": "after", "inconsistent": "inconsistent"},
+				"defaultVariant": "before",
+				"targeting": {
+					"if": [
+						{"!=": [{"now": []}, {"now": []}]}, "inconsistent",
+						{">=": [{"now": []}, 1735689600]}, "after",
+						"before"
+					]
+				}
+			},
+			"dateOffset": {
+				"state": "ENABLED",
+				"variants": {"recent": "recent", "old": "old"},
+				"defaultVariant": "old",
+				"targeting": {
+					"if": [{"date_offset": [{"now": []}, ">=", "-1h"]}, "recent", "old"]
+				}
+			}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+
+	value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", "newYear", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "after", value)
+	assert.Equal(t, model.TargetingMatchReason, reason)
+	assert.Equal(t, 1, calls, "the timestamp is captured once per evaluation")
+
+	clock = time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC)
+	value, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "req", "newYear", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "before", value)
+
+	clock = time.Now()
+	value, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "req", "dateOffset", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "recent", value, "the timestamp is accepted as date")
+}
@@ -0,0 +1,141 @@
+package evaluator
+
+import (
+	gosync "sync"
+	"sync/atomic"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+)
+
+const (
+	// operatorCountName is the internal operator counting the operators of the targeting of flags, each operator is
+	// wrapped in it while operators are counted
+	operatorCountName = "$flagd.count"
+	// maxCountedTargetings bounds the cached targeting rules wrapped for counting
+	maxCountedTargetings = 1024
+)
+
+// iterationOperators apply a rule to each element of an array with the element as data, so that the $flagd
+// properties aren't available to the operators of the rule, these are counted as a single operator
+var iterationOperators = map[string]bool{
+	"map":    true,
+	"filter": true,
+	"reduce": true,
+	"all":    true,
+	"none":   true,
+	"some":   true,
+}
+
+// WithOperatorMetrics records the number of operators executed by each evaluation of targeting rules with the
+// flagd.operators.executed metric. As JsonLogic short-circuits, the count reflects the actual cost of an evaluation
+// rather than the size of the rules. It is opt-in, as counting adds bookkeeping to each executed operator.
+func WithOperatorMetrics() JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.operators = &operatorCounts{}
+		jsonlogic.AddOperator(operatorCountName, je.operators.count)
+	}
+}
+
+// operatorCounts counts the operators executed by the in-flight evaluations, identified by the id passed along in
+// the $flagd properties as custom operators have no access to the evaluation they are applied in
+type operatorCounts struct {
+	counts gosync.Map
+	mu     gosync.RWMutex
+	// counted caches the targeting rules wrapped for counting by their original rules
+	counted map[string][]byte
+}
+
+// start registers a new evaluation with the given id
+func (c *operatorCounts) start(id uint64) {
+	c.counts.Store(id, &atomic.Int64{})
+}
+
+// end unregisters the evaluation with the given id, returning the number of operators it executed
+func (c *operatorCounts) end(id uint64) int64 {
+	count, ok := c.counts.LoadAndDelete(id)
+	if !ok {
+		return 0
+	}
+	return count.(*atomic.Int64).Load()
+}
+
+// count counts the operator it wraps for the evaluation it is applied in, returning the result of the operator
+func (c *operatorCounts) count(values, data any) any {
+	if count, ok := c.counts.Load(operatorCountEvaluationID(data)); ok {
+		count.(*atomic.Int64).Add(1)
+	}
+	if args, ok := values.([]any); ok && len(args) == 1 {
+		return args[0]
+	}
+	return nil
+}
+
+// targeting returns the targeting rules with each operator wrapped for counting, the rules are returned as they are if
+// operators aren't counted or the rules can't be wrapped
+func (c *operatorCounts) targeting(targeting []byte) []byte {
+	if c == nil {
+		return targeting
+	}
+
+	key := string(targeting)
+	c.mu.RLock()
+	counted, ok := c.counted[key]
+	c.mu.RUnlock()
+	if ok {
+		return counted
+	}
+
+	// numbers are decoded as json.Number to retain their literal representation
+	var rule any
+	if err := unmarshalWithNumbers(targeting, &rule); err != nil {
+		return targeting
+	}
+	counted, err := marshalTargeting(countedOperators(rule))
+	if err != nil {
+		return targeting
+	}
+
+	c.mu.Lock()
+	if c.counted == nil || len(c.counted) >= maxCountedTargetings {
+		// computed rules may be unbounded, start over instead of growing without limit
+		c.counted = map[string][]byte{}
+	}
+	c.counted[key] = counted
+	c.mu.Unlock()
+	return counted
+}
+
+// countedOperators recursively wraps each operator of a rule in the counting operator. Objects with more than one key
+// are data rather than operators and are left as they are.
+func countedOperators(rule any) any {
+	switch r := rule.(type) {
+	case map[string]any:
+		if len(r) != 1 {
+			return rule
+		}
+		for operator, args := range r {
+			if !iterationOperators[operator] {
+				args = countedOperators(args)
+			}
+			return map[string]any{operatorCountName: []any{map[string]any{operator: args}}}
+		}
+		return rule
+	case []any:
+		counted := make([]any, len(r))
+		for i, arg := range r {
+			counted[i] = countedOperators(arg)
+		}
+		return counted
+	default:
+		return rule
+	}
+}
+
+// operatorCountEvaluationID returns the evaluation id of the $flagd properties of the data an operator is applied to,
+// zero if there is none
+func operatorCountEvaluationID(data any) uint64 {
+	context, _ := data.(map[string]any)
+	properties, _ := context[flagdPropertiesKey].(map[string]any)
+	id, _ := properties["evaluationId"].(float64)
+	return uint64(id)
+}
@@ -0,0 +1,123 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type operatorsRecorder struct {
+	telemetry.NoopMetricsRecorder
+	executed []string
+}
+
+func (r *operatorsRecorder) OperatorsExecuted(_ context.Context, key string, operators int64) {
+	r.executed = append(r.executed, fmt.Sprintf("%s/%d", key, operators))
+}
+
+const operatorsConfig = `{
+	"flags": {
+		"tiered": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "off",
+			"targeting": {"if": [{"==": [{"var": "tier"}, "gold"]}, "on", "off"]}
+		},
+		"either": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "off",
+			"targeting": {
+				"if": [{"or": [{"==": [{"var": "a"}, 1]}, {"==": [{"var": "b"}, 2]}]}, "on", "off"]
+			}
+		},
+		"iterated": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "off",
+			"targeting": {"if": [{"some": [{"var": "roles"}, {"==": [{"var": ""}, "admin"]}]}, "on", "off"]}
+		},
+		"static": {
+			"state": "ENABLED",
+			"variants": {"on": true, "off": false},
+			"defaultVariant": "on"
+		}
+	}
+}`
+
+func TestOperatorsExecuted(t *testing.T) {
+	tests := map[string]struct {
+		flagKey  string
+		evalCtx  map[string]any
+		expected bool
+		executed []string
+	}{
+		"each executed operator is counted": {
+			flagKey:  "tiered",
+			evalCtx:  map[string]any{"tier": "gold"},
+			expected: true,
+			executed: []string{"tiered/3"},
+		},
+		"short-circuited operators are not counted": {
+			flagKey:  "either",
+			evalCtx:  map[string]any{"a": 1.0},
+			expected: true,
+			executed: []string{"either/4"},
+		},
+		"operators evaluated after a false operand are counted": {
+			flagKey:  "either",
+			evalCtx:  map[string]any{"a": 0.0, "b": 2.0},
+			expected: true,
+			executed: []string{"either/6"},
+		},
+		"iterations count as a single operator": {
+			flagKey:  "iterated",
+			evalCtx:  map[string]any{"roles": []any{"viewer", "admin"}},
+			expected: true,
+			executed: []string{"iterated/2"},
+		},
+		"flags without targeting are not recorded": {
+			flagKey:  "static",
+			expected: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &operatorsRecorder{}
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder),
+				WithOperatorMetrics())
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: operatorsConfig})
+			require.NoError(t, err)
+
+			value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", tt.flagKey, tt.evalCtx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+			assert.Equal(t, tt.executed, recorder.executed)
+
+			tracked := 0
+			evaluator.operators.counts.Range(func(_, _ any) bool {
+				tracked++
+				return true
+			})
+			assert.Zero(t, tracked, "finished evaluations are not tracked")
+		})
+	}
+}
+
+func TestCountedOperators(t *testing.T) {
+	counted := (&operatorCounts{}).targeting([]byte(`{"in": [{"var": "email"}, ["a", {"k": 1, "l": 2}]]}`))
+	assert.JSONEq(t, `{"$flagd.count": [{"in": [{"$flagd.count": [{"var": "email"}]}, ["a", {"k": 1, "l": 2}]]}]}`,
+		string(counted), "objects with multiple keys are data")
+
+	var counts *operatorCounts
+	assert.Equal(t, `{"var": "email"}`, string(counts.targeting([]byte(`{"var": "email"}`))),
+		"rules are not wrapped if operators aren't counted")
+}
@@ -0,0 +1,69 @@
+package evaluator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// resolveParentVariants merges the variants of the parent of each flag declaring one into the variants of the flag,
+// variants declared by the flag override inherited variants of the same name. Parents may inherit variants from their
+// own parents. Parents must be flags of the same configuration, missing parents and cycles of parents are rejected.
+func resolveParentVariants(flags *Flags) error {
+	keys := make([]string, 0, len(flags.Flags))
+	for key := range flags.Flags {
+		keys = append(keys, key)
+	}
+	// sorted for deterministic errors
+	sort.Strings(keys)
+
+	resolved := map[string]map[string]any{}
+	for _, key := range keys {
+		if _, err := parentVariants(flags, key, resolved, nil); err != nil {
+			return err
+		}
+	}
+	for key, variants := range resolved {
+		flag := flags.Flags[key]
+		flag.Variants = variants
+		flags.Flags[key] = flag
+	}
+	return nil
+}
+
+// parentVariants returns the effective variants of a flag, resolving the variants of its parents first. The path holds
+// the flags whose parents are being resolved, so that a flag found on its own path closes a cycle.
+func parentVariants(flags *Flags, key string, resolved map[string]map[string]any, path []string) (
+	map[string]any, error,
+) {
+	flag := flags.Flags[key]
+	if flag.Parent == "" {
+		return flag.Variants, nil
+	}
+	if variants, ok := resolved[key]; ok {
+		return variants, nil
+	}
+	for i, visited := range path {
+		if visited == key {
+			return nil, fmt.Errorf("cyclic parents of flag: '%s': %s", key,
+				strings.Join(append(path[i:], key), " -> "))
+		}
+	}
+	if _, ok := flags.Flags[flag.Parent]; !ok {
+		return nil, fmt.Errorf("parent: '%s' of flag: '%s' isn't a flag of the configuration", flag.Parent, key)
+	}
+
+	inherited, err := parentVariants(flags, flag.Parent, resolved, append(path, key))
+	if err != nil {
+		return nil, err
+	}
+	variants := make(map[string]any, len(inherited)+len(flag.Variants))
+	for variant, value := range inherited {
+		variants[variant] = value
+	}
+	for variant, value := range flag.Variants {
+		variants[variant] = value
+	}
+	resolved[key] = variants
+	return variants, nil
+}
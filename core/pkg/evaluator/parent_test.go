@@ -0,0 +1,140 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveParentVariants(t *testing.T) {
+	colors := map[string]any{"red": "#ff0000", "blue": "#0000ff"}
+
+	tests := map[string]struct {
+		flags    map[string]model.Flag
+		expected map[string]map[string]any
+		err      string
+	}{
+		"inherited variants": {
+			flags: map[string]model.Flag{
+				"colors": {Variants: colors},
+				"header": {Parent: "colors"},
+			},
+			expected: map[string]map[string]any{
+				"colors": colors,
+				"header": colors,
+			},
+		},
+		"overridden and added variants": {
+			flags: map[string]model.Flag{
+				"colors": {Variants: colors},
+				"header": {Parent: "colors", Variants: map[string]any{"red": "#cc0000", "green": "#00ff00"}},
+			},
+			expected: map[string]map[string]any{
+				"colors": colors,
+				"header": {"red": "#cc0000", "blue": "#0000ff", "green": "#00ff00"},
+			},
+		},
+		"inherited from the parent of the parent": {
+			flags: map[string]model.Flag{
+				"colors": {Variants: colors},
+				"header": {Parent: "colors", Variants: map[string]any{"green": "#00ff00"}},
+				"footer": {Parent: "header"},
+			},
+			expected: map[string]map[string]any{
+				"colors": colors,
+				"header": {"red": "#ff0000", "blue": "#0000ff", "green": "#00ff00"},
+				"footer": {"red": "#ff0000", "blue": "#0000ff", "green": "#00ff00"},
+			},
+		},
+		"missing parent": {
+			flags: map[string]model.Flag{
+				"header": {Parent: "colors"},
+			},
+			err: "parent: 'colors' of flag: 'header' isn't a flag of the configuration",
+		},
+		"own parent": {
+			flags: map[string]model.Flag{
+				"header": {Parent: "header"},
+			},
+			err: "cyclic parents of flag: 'header': header -> header",
+		},
+		"cycle": {
+			flags: map[string]model.Flag{
+				"a": {Parent: "b"},
+				"b": {Parent: "c"},
+				"c": {Parent: "a"},
+			},
+			err: "cyclic parents of flag: 'a': a -> b -> c -> a",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			flags := &Flags{Flags: tt.flags}
+			err := resolveParentVariants(flags)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			for key, variants := range tt.expected {
+				assert.Equal(t, variants, flags.Flags[key].Variants, key)
+			}
+		})
+	}
+}
+
+func TestParentVariantsEvaluation(t *testing.T) {
+	const config = `{
+		"flags": {
+			"colors": {
+				"state": "ENABLED",
+				"variants": {"red": "#ff0000", "blue": "#0000ff"},
+				"defaultVariant": "red"
+			},
+			"header": {
+				"state": "ENABLED",
+				"parent": "colors",
+				"variants": {"blue": "#000099"},
+				"defaultVariant": "red",
+				"targeting": {"if": [{"==": [{"var": "tier"}, "gold"]}, "blue", null]}
+			}
+		}
+	}`
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+
+	value, variant, _, _, err := evaluator.ResolveStringValue(context.Background(), "req", "header", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "red", variant)
+	assert.Equal(t, "#ff0000", value)
+
+	value, variant, _, _, err = evaluator.ResolveStringValue(context.Background(), "req", "header",
+		map[string]any{"tier": "gold"})
+	require.NoError(t, err)
+	assert.Equal(t, "blue", variant)
+	assert.Equal(t, "#000099", value)
+}
+
+func TestParentVariantsMissingParent(t *testing.T) {
+	const config = `{
+		"flags": {
+			"header": {
+				"state": "ENABLED",
+				"parent": "colors",
+				"variants": {"blue": "#000099"},
+				"defaultVariant": "blue"
+			}
+		}
+	}`
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.EqualError(t, err, "parent: 'colors' of flag: 'header' isn't a flag of the configuration")
+}
@@ -0,0 +1,67 @@
+package evaluator
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+const (
+	// ReasonPathMetadataKey is the flag or flag set metadata key enabling the reason path of the evaluations of a flag.
+	// It is opt-in, as tracking the path adds bookkeeping to each evaluation.
+	ReasonPathMetadataKey = "reasonPath"
+	// ReasonsMetadataKey is the evaluation metadata key holding the reason path, the comma separated reasons
+	// encountered while evaluating a flag, in order, e.g. "DEFAULT,TARGETING_MATCH,SPLIT"
+	ReasonsMetadataKey = "reasons"
+)
+
+// reasonPaths tracks the fractional splits of the in-flight evaluations of flags opting into the reason path. Custom
+// operators have no access to the evaluation they are applied in, hence evaluations are identified by an id passed
+// along in the $flagd properties.
+type reasonPaths struct {
+	splits sync.Map
+}
+
+// start registers a new evaluation with the given id
+func (p *reasonPaths) start(id uint64) {
+	p.splits.Store(id, false)
+}
+
+// split marks that a fractional operation split the evaluation with the given id
+func (p *reasonPaths) split(id uint64) {
+	if id == 0 {
+		return
+	}
+	if _, ok := p.splits.Load(id); ok {
+		p.splits.Store(id, true)
+	}
+}
+
+// end unregisters the evaluation with the given id, reporting whether a fractional operation split it
+func (p *reasonPaths) end(id uint64) bool {
+	split, _ := p.splits.LoadAndDelete(id)
+	ok, _ := split.(bool)
+	return ok
+}
+
+// reasonPath returns the reasons encountered by an evaluation resulting in the given reason. Evaluations of targeting
+// start at the default variant, followed by the matched targeting and a fractional split deciding the variant, if any.
+func reasonPath(reason string, targeted bool, split bool) string {
+	if !targeted {
+		return reason
+	}
+
+	path := []string{model.DefaultReason}
+	switch reason {
+	case model.TargetingMatchReason:
+		path = append(path, model.TargetingMatchReason)
+		if split {
+			path = append(path, model.SplitReason)
+		}
+	case model.DefaultReason:
+	default:
+		path = append(path, reason)
+	}
+	return strings.Join(path, ",")
+}
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReasonPath(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"static": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"metadata": {"reasonPath": true}
+			},
+			"targeted": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"metadata": {"reasonPath": true},
+				"targeting": {
+					"if": [
+						{"==": [{"var": "split"}, true]},
+						{"fractional": [{"var": "targetingKey"}, ["blue", 100]]},
+						{"if": [{"==": [{"var": "match"}, true]}, "blue", null]}
+					]
+				}
+			},
+			"invalid": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"metadata": {"reasonPath": true},
+				"targeting": {"if": [true, "green", "red"]}
+			},
+			"untracked": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue"},
+				"defaultVariant": "red",
+				"targeting": {"fractional": [{"var": "targetingKey"}, ["blue", 100]]}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		flagKey string
+		context map[string]any
+		reason  string
+		path    string
+	}{
+		"static flag": {
+			flagKey: "static",
+			reason:  model.StaticReason,
+			path:    model.StaticReason,
+		},
+		"targeting without match": {
+			flagKey: "targeted",
+			reason:  model.DefaultReason,
+			path:    "DEFAULT",
+		},
+		"targeting match": {
+			flagKey: "targeted",
+			context: map[string]any{"match": true},
+			reason:  model.TargetingMatchReason,
+			path:    "DEFAULT,TARGETING_MATCH",
+		},
+		"fractional split": {
+			flagKey: "targeted",
+			context: map[string]any{"split": true, "targetingKey": "user"},
+			reason:  model.TargetingMatchReason,
+			path:    "DEFAULT,TARGETING_MATCH,SPLIT",
+		},
+		"invalid targeting result": {
+			flagKey: "invalid",
+			reason:  model.ErrorReason,
+			path:    "DEFAULT,ERROR",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, reason, metadata, _ := evaluator.ResolveStringValue(context.TODO(), "", tt.flagKey, tt.context)
+			assert.Equal(t, tt.reason, reason)
+			assert.Equal(t, tt.path, metadata[ReasonsMetadataKey])
+		})
+	}
+
+	// flags not opting in do not report the path, nor leave evaluations tracked
+	_, _, _, metadata, err := evaluator.ResolveStringValue(context.TODO(), "", "untracked",
+		map[string]any{"targetingKey": "user"})
+	require.NoError(t, err)
+	assert.NotContains(t, metadata, ReasonsMetadataKey)
+
+	tracked := 0
+	evaluator.reasonPaths.splits.Range(func(_, _ any) bool {
+		tracked++
+		return true
+	})
+	assert.Zero(t, tracked)
+}
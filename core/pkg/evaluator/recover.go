@@ -0,0 +1,151 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"go.uber.org/zap"
+)
+
+// panicStackLogInterval is the minimum interval between two logged stack traces of evaluation panics
+const panicStackLogInterval = time.Minute
+
+// stackSampler limits how often stack traces are logged, so that a flag panicking on every evaluation does not flood
+// the logs
+type stackSampler struct {
+	interval time.Duration
+	last     atomic.Int64
+}
+
+func newStackSampler(interval time.Duration) *stackSampler {
+	return &stackSampler{interval: interval}
+}
+
+// allow reports whether a stack trace may be logged at the given time
+func (s *stackSampler) allow(now time.Time) bool {
+	last := s.last.Load()
+	if last != 0 && now.Sub(time.Unix(0, last)) < s.interval {
+		return false
+	}
+	return s.last.CompareAndSwap(last, now.UnixNano())
+}
+
+// evaluation is the result of an evaluation running against the evaluation timeout
+type evaluation struct {
+	variant  string
+	variants map[string]interface{}
+	reason   string
+	metadata map[string]interface{}
+	err      error
+}
+
+// evaluateVariant evaluates the variant of a flag, serving the cached result of flags opted into the evaluation cache
+// and templating the value of flags opted into value templating
+func (je *Resolver) evaluateVariant(ctx context.Context, reqID string, flagKey string, evalCtx map[string]any) (
+	variant string, variants map[string]interface{}, reason string, metadata map[string]interface{}, err error,
+) {
+	if je.debugger != nil && je.debugger.admit(flagKey) {
+		defer func() {
+			je.debugEvaluation(ctx, reqID, flagKey, evalCtx, evaluation{
+				variant: variant, variants: variants, reason: reason, metadata: metadata, err: err,
+			})
+		}()
+	}
+	if je.cache != nil {
+		variant, variants, reason, metadata, err = je.cachedVariant(ctx, reqID, flagKey, evalCtx)
+	} else {
+		variant, variants, reason, metadata, err = je.boundedVariant(ctx, reqID, flagKey, evalCtx)
+	}
+	if err != nil {
+		return variant, variants, reason, metadata, err
+	}
+	// templated after caching, as results of flags without targeting are cached regardless of the context
+	return je.templateVariant(reqID, flagKey, evalCtx, variant, variants, reason, metadata)
+}
+
+// boundedVariant evaluates the variant of a flag, converting panics raised during the evaluation, e.g. by a custom
+// operator, into an error result. This prevents a single bad flag from crashing the server or a shared stream.
+// If an evaluation timeout is configured, evaluations exceeding it result in an error as well.
+func (je *Resolver) boundedVariant(ctx context.Context, reqID string, flagKey string, evalCtx map[string]any) (
+	variant string, variants map[string]interface{}, reason string, metadata map[string]interface{}, err error,
+) {
+	if je.timeout <= 0 {
+		return je.recoverVariant(ctx, reqID, flagKey, evalCtx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, je.timeout)
+	defer cancel()
+
+	// targeting rules can't be interrupted, the custom operations of an evaluation exceeding the deadline are skipped
+	// instead, see interruptible, and its result is discarded
+	variant, variants, reason, metadata, err = je.recoverVariant(ctx, reqID, flagKey, evalCtx)
+	if ctx.Err() == nil {
+		return variant, variants, reason, metadata, err
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		telemetry.EvaluationMetrics(je.metrics).EvaluationTimeout(ctx, flagKey)
+		je.Logger.WarnWithID(reqID, fmt.Sprintf("evaluation of flag %s exceeded its deadline, the targeting "+
+			"rules may be pathological", flagKey))
+	}
+	return "", map[string]interface{}{}, model.ErrorReason, map[string]interface{}{},
+		errors.New(model.GeneralErrorCode)
+}
+
+// interruptible wraps a custom operator, so that the operations of an evaluation exceeding the deadline set in its
+// $flagd properties are skipped. JsonLogic doesn't take a context, hence the deadline is passed with the data.
+func interruptible(operator func(values, data any) any) func(values, data any) any {
+	return func(values, data any) any {
+		if deadlineExceeded(data) {
+			return nil
+		}
+		return operator(values, data)
+	}
+}
+
+// deadlineExceeded reports whether the deadline of the $flagd properties of the data has passed. The properties are
+// read from the data directly instead of decoding them, as this is checked by every custom operation.
+func deadlineExceeded(data any) bool {
+	evalCtx, ok := data.(map[string]any)
+	if !ok {
+		return false
+	}
+	properties, ok := evalCtx[flagdPropertiesKey].(map[string]any)
+	if !ok {
+		return false
+	}
+	deadline, ok := properties[deadlineKey].(float64)
+	return ok && time.Now().UnixMilli() >= int64(deadline)
+}
+
+// recoverVariant evaluates the variant of a flag, recovering from panics raised during the evaluation
+func (je *Resolver) recoverVariant(ctx context.Context, reqID string, flagKey string, evalCtx map[string]any) (
+	variant string, variants map[string]interface{}, reason string, metadata map[string]interface{}, err error,
+) {
+	defer func() {
+		if r := recover(); r != nil {
+			telemetry.EvaluationMetrics(je.metrics).EvaluationPanic(ctx, flagKey)
+
+			// panics are logged independent of request ID logging, the stack is only taken if the entry is written
+			if je.Logger.Logger.Core().Enabled(zap.ErrorLevel) {
+				fields := []zap.Field{zap.String(logger.RequestIDFieldName, reqID)}
+				if je.stackSampler.allow(time.Now()) {
+					fields = append(fields, zap.ByteString("stack", debug.Stack()))
+				}
+				je.Logger.Error(fmt.Sprintf("recovered from panic during evaluation of flag: %s: %v", flagKey, r),
+					fields...)
+			}
+
+			variant, variants, reason, metadata = "", map[string]interface{}{}, model.ErrorReason, map[string]interface{}{}
+			err = errors.New(model.GeneralErrorCode)
+		}
+	}()
+
+	return je.evaluate(ctx, reqID, flagKey, evalCtx)
+}
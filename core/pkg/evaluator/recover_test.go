@@ -0,0 +1,178 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type panicRecorder struct {
+	telemetry.NoopMetricsRecorder
+	keys []string
+}
+
+func (r *panicRecorder) EvaluationPanic(_ context.Context, key string) {
+	r.keys = append(r.keys, key)
+}
+
+func TestEvaluationPanic(t *testing.T) {
+	jsonlogic.AddOperator("test_panic", func(_, _ interface{}) interface{} {
+		panic("boom")
+	})
+
+	recorder := &panicRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"panicking": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"test_panic": [1]}
+			},
+			"healthy": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "on"
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	_, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", "panicking", nil)
+	require.EqualError(t, err, model.GeneralErrorCode)
+	assert.Equal(t, model.ErrorReason, reason)
+	assert.Equal(t, []string{"panicking"}, recorder.keys)
+
+	values, err := evaluator.ResolveAllValues(context.Background(), "req", nil)
+	require.NoError(t, err)
+	require.Len(t, values, 2)
+	for _, value := range values {
+		if value.FlagKey == "panicking" {
+			assert.Equal(t, model.ErrorReason, value.Reason)
+			assert.Error(t, value.Error)
+		} else {
+			assert.Equal(t, true, value.Value)
+			assert.NoError(t, value.Error)
+		}
+	}
+	assert.Equal(t, []string{"panicking", "panicking"}, recorder.keys)
+}
+
+func TestEvaluationPanicLog(t *testing.T) {
+	jsonlogic.AddOperator("test_panic", func(_, _ interface{}) interface{} {
+		panic("boom")
+	})
+	const config = `{
+		"flags": {
+			"panicking": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"test_panic": [1]}
+			}
+		}
+	}`
+
+	// panics are logged without request ID logging
+	core, logs := observer.New(zapcore.InfoLevel)
+	evaluator := NewJSON(logger.NewLogger(zap.New(core), false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "panicking", nil)
+	require.Error(t, err)
+	entries := logs.FilterMessageSnippet("recovered from panic").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "req", entries[0].ContextMap()[logger.RequestIDFieldName])
+	assert.Contains(t, entries[0].ContextMap(), "stack")
+
+	// stacks aren't taken for entries which aren't written
+	evaluator = NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "panicking", nil)
+	require.Error(t, err)
+	assert.Zero(t, evaluator.stackSampler.last.Load())
+}
+
+type timeoutRecorder struct {
+	telemetry.NoopMetricsRecorder
+	keys []string
+}
+
+func (r *timeoutRecorder) EvaluationTimeout(_ context.Context, key string) {
+	r.keys = append(r.keys, key)
+}
+
+func TestEvaluationTimeout(t *testing.T) {
+	recorder := &timeoutRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(),
+		WithMetricsRecorder(recorder), WithEvaluationTimeout(time.Minute))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"flag": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [{"starts_with": [{"var": "email"}, "admin"]}, "on", "off"]}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+	evalCtx := map[string]any{"email": "admin@example.com"}
+
+	// the deadline of the evaluation is the earlier of the timeout and the deadline of the request
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	_, _, reason, _, err := evaluator.ResolveBooleanValue(expired, "req", "flag", evalCtx)
+	require.EqualError(t, err, model.GeneralErrorCode)
+	assert.Equal(t, model.ErrorReason, reason)
+	assert.Equal(t, []string{"flag"}, recorder.keys)
+
+	value, _, reason, _, err := evaluator.ResolveBooleanValue(context.Background(), "req", "flag", evalCtx)
+	require.NoError(t, err)
+	assert.True(t, value)
+	assert.Equal(t, model.TargetingMatchReason, reason)
+	assert.Equal(t, []string{"flag"}, recorder.keys)
+}
+
+func TestInterruptible(t *testing.T) {
+	calls := 0
+	operator := interruptible(func(_, _ any) any {
+		calls++
+		return true
+	})
+	withDeadline := func(deadline time.Time) map[string]any {
+		return map[string]any{flagdPropertiesKey: map[string]any{deadlineKey: float64(deadline.UnixMilli())}}
+	}
+
+	assert.Equal(t, true, operator(nil, withDeadline(time.Now().Add(time.Minute))))
+	assert.Nil(t, operator(nil, withDeadline(time.Now().Add(-time.Minute))))
+	assert.Equal(t, true, operator(nil, map[string]any{}))
+	assert.Equal(t, true, operator(nil, nil))
+	assert.Equal(t, 3, calls)
+}
+
+func TestStackSampler(t *testing.T) {
+	sampler := newStackSampler(time.Minute)
+	now := time.Now()
+
+	assert.True(t, sampler.allow(now))
+	assert.False(t, sampler.allow(now.Add(30*time.Second)))
+	assert.True(t, sampler.allow(now.Add(time.Minute)))
+	assert.False(t, sampler.allow(now.Add(time.Minute+time.Second)))
+}
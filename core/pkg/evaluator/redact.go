@@ -0,0 +1,99 @@
+package evaluator
+
+import "strings"
+
+// RedactedValue replaces the values of redacted evaluation context keys in logs and captured samples
+const RedactedValue = "[REDACTED]"
+
+// Redactor returns a copy of the evaluation context to log or capture, without sensitive values. It must not modify
+// the given context.
+type Redactor func(context map[string]any) map[string]any
+
+// RedactKeys returns a Redactor replacing the values of the given evaluation context keys with RedactedValue. Nested
+// keys are addressed by their dot separated path, e.g. "peer.ip".
+func RedactKeys(keys ...string) Redactor {
+	redacted := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		redacted[key] = struct{}{}
+	}
+	return func(context map[string]any) map[string]any {
+		return redactMap(context, "", redacted)
+	}
+}
+
+// RedactAllExcept returns a Redactor replacing all values of the evaluation context with RedactedValue, except the
+// values of the given keys. Nested keys are addressed by their dot separated path, an allowed key keeps its nested
+// values.
+func RedactAllExcept(keys ...string) Redactor {
+	allowed := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		allowed[key] = struct{}{}
+	}
+	return func(context map[string]any) map[string]any {
+		return allowMap(context, "", allowed)
+	}
+}
+
+// ChainRedactors returns a Redactor applying the given redactors in order
+func ChainRedactors(redactors ...Redactor) Redactor {
+	return func(context map[string]any) map[string]any {
+		// the copy protects the given context from redactors modifying their input
+		result := redactMap(context, "", nil)
+		for _, redact := range redactors {
+			if redact != nil {
+				result = redact(result)
+			}
+		}
+		return result
+	}
+}
+
+func redactMap(context map[string]any, prefix string, redacted map[string]struct{}) map[string]any {
+	if context == nil {
+		return nil
+	}
+	result := make(map[string]any, len(context))
+	for key, value := range context {
+		path := prefix + key
+		if _, ok := redacted[path]; ok {
+			result[key] = RedactedValue
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			result[key] = redactMap(nested, path+".", redacted)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+func allowMap(context map[string]any, prefix string, allowed map[string]struct{}) map[string]any {
+	if context == nil {
+		return nil
+	}
+	result := make(map[string]any, len(context))
+	for key, value := range context {
+		path := prefix + key
+		if _, ok := allowed[path]; ok {
+			result[key] = value
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok && allowsNested(allowed, path+".") {
+			result[key] = allowMap(nested, path+".", allowed)
+			continue
+		}
+		result[key] = RedactedValue
+	}
+	return result
+}
+
+// allowsNested reports whether a key nested below the given path prefix is allowed
+func allowsNested(allowed map[string]struct{}, prefix string) bool {
+	for key := range allowed {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
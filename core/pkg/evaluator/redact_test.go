@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRedactors(t *testing.T) {
+	evalCtx := func() map[string]any {
+		return map[string]any{
+			"targetingKey": "user-1",
+			"email":        "user@example.com",
+			"peer":         map[string]any{"ip": "10.0.0.1", "country": "DE"},
+			"device":       map[string]any{"os": "linux"},
+			"tags":         []any{"beta"},
+		}
+	}
+
+	tests := map[string]struct {
+		redact   Redactor
+		expected map[string]any
+	}{
+		"redact keys": {
+			redact: RedactKeys("email", "peer.ip", "unknown"),
+			expected: map[string]any{
+				"targetingKey": "user-1",
+				"email":        RedactedValue,
+				"peer":         map[string]any{"ip": RedactedValue, "country": "DE"},
+				"device":       map[string]any{"os": "linux"},
+				"tags":         []any{"beta"},
+			},
+		},
+		"redact all except": {
+			redact: RedactAllExcept("targetingKey", "peer.country", "device"),
+			expected: map[string]any{
+				"targetingKey": "user-1",
+				"email":        RedactedValue,
+				"peer":         map[string]any{"ip": RedactedValue, "country": "DE"},
+				"device":       map[string]any{"os": "linux"},
+				"tags":         RedactedValue,
+			},
+		},
+		"redact all": {
+			redact: RedactAllExcept(),
+			expected: map[string]any{
+				"targetingKey": RedactedValue,
+				"email":        RedactedValue,
+				"peer":         RedactedValue,
+				"device":       RedactedValue,
+				"tags":         RedactedValue,
+			},
+		},
+		"chained redactors": {
+			redact: ChainRedactors(RedactKeys("email"), nil, RedactAllExcept("email", "peer.ip")),
+			expected: map[string]any{
+				"targetingKey": RedactedValue,
+				"email":        RedactedValue,
+				"peer":         map[string]any{"ip": "10.0.0.1", "country": RedactedValue},
+				"device":       RedactedValue,
+				"tags":         RedactedValue,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			original := evalCtx()
+			assert.Equal(t, tt.expected, tt.redact(original))
+			assert.Equal(t, evalCtx(), original, "the evaluation context must not be modified")
+			assert.Nil(t, tt.redact(nil))
+		})
+	}
+}
+
+func TestContextRedactor(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	evaluator := NewJSON(logger.NewLogger(zap.New(core), true), store.NewFlags(),
+		WithContextRedactor(RedactKeys("email")))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"targeted": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [{"var": "email"}, "on", "off"]}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	// the context can't be marshalled, hence it is logged along with the error
+	_, _, _, _, err = evaluator.ResolveBooleanValue(context.Background(), "req", "targeted", map[string]any{
+		"email":   "user@example.com",
+		"invalid": make(chan int),
+	})
+	require.Error(t, err)
+
+	entries := logs.FilterMessageSnippet("error parsing context").All()
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0].Message, "user@example.com")
+	assert.Contains(t, entries[0].Message, RedactedValue)
+}
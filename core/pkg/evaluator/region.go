@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+const (
+	// RegionDefaultsMetadataKey is the flag or flag set metadata key mapping regions to the variant served instead of
+	// the default variant to evaluation contexts of the region, e.g. {"de": "german", "fr": "french"}
+	RegionDefaultsMetadataKey = "regionDefaults"
+	// DefaultRegionContextKey is the default evaluation context key holding the region the region defaults are
+	// selected by
+	DefaultRegionContextKey = "region"
+)
+
+// WithRegionContextKey selects the evaluation context key holding the region the region defaults of flags are
+// selected by, DefaultRegionContextKey if empty
+func WithRegionContextKey(key string) JSONEvaluatorOption {
+	return func(je *JSON) {
+		if key != "" {
+			je.regionContextKey = key
+		}
+	}
+}
+
+// regionDefaults returns the region defaults of a flag, empty if the flag doesn't define any
+func regionDefaults(metadata map[string]interface{}) (map[string]string, error) {
+	value, ok := metadata[RegionDefaultsMetadataKey]
+	if !ok {
+		return map[string]string{}, nil
+	}
+	regions, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object mapping regions to variants but got %v", value)
+	}
+	defaults := make(map[string]string, len(regions))
+	for region, variant := range regions {
+		name, ok := variant.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a variant name for region %s but got %v", region, variant)
+		}
+		defaults[region] = name
+	}
+	return defaults, nil
+}
+
+// warnInvalidRegionDefaults logs the flags whose region defaults metadata is invalid, these are served their default
+// variant in all regions
+func warnInvalidRegionDefaults(log *logger.Logger, flags *Flags) {
+	for key, flag := range flags.Flags {
+		if _, err := regionDefaults(flag.Metadata); err != nil {
+			log.Warn(fmt.Sprintf("ignoring invalid %s metadata of flag %s: %v", RegionDefaultsMetadataKey, key, err))
+		}
+	}
+}
+
+// defaultVariant returns the variant served instead of the targeting, either the region default of the region of the
+// evaluation context, or the default variant of the flag
+func (je *Resolver) defaultVariant(flag model.Flag, evalCtx map[string]any, reason string) (string, string) {
+	regions, ok := flag.Metadata[RegionDefaultsMetadataKey].(map[string]interface{})
+	if !ok {
+		return flag.DefaultVariant, reason
+	}
+	region, ok := evalCtx[je.regionContextKey].(string)
+	if !ok {
+		return flag.DefaultVariant, reason
+	}
+	variant, ok := regions[region].(string)
+	if !ok {
+		return flag.DefaultVariant, reason
+	}
+	if _, ok := flag.Variants[variant]; !ok {
+		return flag.DefaultVariant, reason
+	}
+	return variant, model.RegionDefaultReason
+}
@@ -0,0 +1,161 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const regionDefaultsConfig = `{
+	"flags": {
+		"greeting": {
+			"state": "ENABLED",
+			"variants": {"english": "hello", "german": "hallo", "french": "bonjour"},
+			"defaultVariant": "english",
+			"metadata": {"regionDefaults": {"de": "german", "fr": "french"}}
+		},
+		"banner": {
+			"state": "ENABLED",
+			"variants": {"english": "sale", "german": "angebot", "beta": "beta"},
+			"defaultVariant": "english",
+			"targeting": {"if": [{"==": [{"var": "tier"}, "beta"]}, "beta", null]},
+			"metadata": {"regionDefaults": {"de": "german"}, "evaluationCacheTTL": "1m"}
+		}
+	}
+}`
+
+func TestRegionDefaults(t *testing.T) {
+	tests := map[string]struct {
+		flagKey string
+		context map[string]any
+		value   string
+		reason  string
+	}{
+		"region default": {
+			flagKey: "greeting", context: map[string]any{"region": "de"},
+			value: "hallo", reason: model.RegionDefaultReason,
+		},
+		"other region default": {
+			flagKey: "greeting", context: map[string]any{"region": "fr"},
+			value: "bonjour", reason: model.RegionDefaultReason,
+		},
+		"region without default": {
+			flagKey: "greeting", context: map[string]any{"region": "us"},
+			value: "hello", reason: model.StaticReason,
+		},
+		"no region": {
+			flagKey: "greeting", context: map[string]any{},
+			value: "hello", reason: model.StaticReason,
+		},
+		"non-string region": {
+			flagKey: "greeting", context: map[string]any{"region": 49.0},
+			value: "hello", reason: model.StaticReason,
+		},
+		"targeting match takes precedence": {
+			flagKey: "banner", context: map[string]any{"region": "de", "tier": "beta"},
+			value: "beta", reason: model.TargetingMatchReason,
+		},
+		"region default instead of targeting default": {
+			flagKey: "banner", context: map[string]any{"region": "de"},
+			value: "angebot", reason: model.RegionDefaultReason,
+		},
+		"targeting default": {
+			flagKey: "banner", context: map[string]any{"region": "us"},
+			value: "sale", reason: model.DefaultReason,
+		},
+	}
+
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: regionDefaultsConfig,
+		Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", tt.flagKey,
+				tt.context)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, value)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestRegionContextKey(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithRegionContextKey("country"))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: regionDefaultsConfig,
+		Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+
+	value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", "greeting",
+		map[string]any{"country": "de", "region": "fr"})
+	require.NoError(t, err)
+	assert.Equal(t, "hallo", value)
+	assert.Equal(t, model.RegionDefaultReason, reason)
+}
+
+func TestRegionDefaultsValidation(t *testing.T) {
+	recorder := &variantRefRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"greeting": {
+				"state": "ENABLED",
+				"variants": {"english": "hello"},
+				"defaultVariant": "english",
+				"metadata": {"regionDefaults": {"de": "german"}}
+			}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.ErrorIs(t, err, ErrUnknownVariant)
+	assert.Contains(t, err.Error(), "region default: 'german' of region: 'de' isn't a valid variant of flag: 'greeting'")
+	assert.Equal(t, []string{telemetry.VariantRefRegionDefault}, recorder.kinds)
+
+	// invalid region defaults are logged and never served
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"greeting": {
+				"state": "ENABLED",
+				"variants": {"english": "hello"},
+				"defaultVariant": "english",
+				"metadata": {"regionDefaults": "german"}
+			}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+	value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", "greeting",
+		map[string]any{"region": "de"})
+	require.NoError(t, err)
+	assert.Equal(t, "hello", value)
+	assert.Equal(t, model.StaticReason, reason)
+}
+
+func TestRegionDefaultsCachedPerRegion(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"greeting": {
+				"state": "ENABLED",
+				"variants": {"english": "hello", "german": "hallo"},
+				"defaultVariant": "english",
+				"metadata": {"regionDefaults": {"de": "german"}, "evaluationCacheTTL": "1m"}
+			}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+
+	for _, expected := range []struct{ region, value string }{{"de", "hallo"}, {"us", "hello"}, {"de", "hallo"}} {
+		value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "req", "greeting",
+			map[string]any{"region": expected.region})
+		require.NoError(t, err)
+		assert.Equal(t, expected.value, value, "region %s", expected.region)
+	}
+}
@@ -0,0 +1,161 @@
+package evaluator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Sample is a captured evaluation, holding the evaluation context, the evaluated flag and its result
+type Sample struct {
+	Time    time.Time      `json:"time"`
+	FlagKey string         `json:"flagKey"`
+	Context map[string]any `json:"context"`
+	Value   any            `json:"value"`
+	Variant string         `json:"variant"`
+	Reason  string         `json:"reason"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// SampleRecorder is an IEvaluator capturing the most recent evaluations in a ring buffer of fixed size, so that they can
+// be inspected to reproduce issues. As the samples contain request data, capturing must be enabled explicitly.
+type SampleRecorder struct {
+	IEvaluator
+	redact  Redactor
+	mx      sync.Mutex
+	samples []Sample
+	next    int
+	full    bool
+}
+
+// NewSampleRecorder wraps the given evaluator, capturing up to size evaluations. The evaluation context of each sample
+// is passed through the redactor, which defaults to copying the context.
+func NewSampleRecorder(eval IEvaluator, size int, redact Redactor) *SampleRecorder {
+	if size < 1 {
+		size = 1
+	}
+	if redact == nil {
+		redact = RedactKeys()
+	}
+	return &SampleRecorder{
+		IEvaluator: eval,
+		redact:     redact,
+		samples:    make([]Sample, size),
+	}
+}
+
+// Samples returns the captured evaluations, oldest first
+func (r *SampleRecorder) Samples() []Sample {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if !r.full {
+		return append([]Sample{}, r.samples[:r.next]...)
+	}
+	return append(append([]Sample{}, r.samples[r.next:]...), r.samples[:r.next]...)
+}
+
+// record adds an evaluation to the buffer, the given context must already be redacted
+func (r *SampleRecorder) record(
+	flagKey string, context map[string]any, value any, variant string, reason string, err error,
+) {
+	sample := Sample{
+		Time:    time.Now().UTC(),
+		FlagKey: flagKey,
+		Context: context,
+		Value:   value,
+		Variant: variant,
+		Reason:  reason,
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.samples[r.next] = sample
+	r.next = (r.next + 1) % len(r.samples)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+func (r *SampleRecorder) ResolveBooleanValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (bool, string, string, map[string]interface{}, error) {
+	value, variant, reason, metadata, err := r.IEvaluator.ResolveBooleanValue(ctx, reqID, flagKey, context)
+	r.record(flagKey, r.redact(context), value, variant, reason, err)
+	return value, variant, reason, metadata, err
+}
+
+func (r *SampleRecorder) ResolveStringValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (string, string, string, map[string]interface{}, error) {
+	value, variant, reason, metadata, err := r.IEvaluator.ResolveStringValue(ctx, reqID, flagKey, context)
+	r.record(flagKey, r.redact(context), value, variant, reason, err)
+	return value, variant, reason, metadata, err
+}
+
+func (r *SampleRecorder) ResolveIntValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (int64, string, string, map[string]interface{}, error) {
+	value, variant, reason, metadata, err := r.IEvaluator.ResolveIntValue(ctx, reqID, flagKey, context)
+	r.record(flagKey, r.redact(context), value, variant, reason, err)
+	return value, variant, reason, metadata, err
+}
+
+func (r *SampleRecorder) ResolveFloatValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (float64, string, string, map[string]interface{}, error) {
+	value, variant, reason, metadata, err := r.IEvaluator.ResolveFloatValue(ctx, reqID, flagKey, context)
+	r.record(flagKey, r.redact(context), value, variant, reason, err)
+	return value, variant, reason, metadata, err
+}
+
+func (r *SampleRecorder) ResolveObjectValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (map[string]any, string, string, map[string]interface{}, error) {
+	value, variant, reason, metadata, err := r.IEvaluator.ResolveObjectValue(ctx, reqID, flagKey, context)
+	r.record(flagKey, r.redact(context), value, variant, reason, err)
+	return value, variant, reason, metadata, err
+}
+
+func (r *SampleRecorder) ResolveAsAnyValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) AnyValue {
+	value := r.IEvaluator.ResolveAsAnyValue(ctx, reqID, flagKey, context)
+	r.record(flagKey, r.redact(context), value.Value, value.Variant, value.Reason, value.Error)
+	return value
+}
+
+func (r *SampleRecorder) ResolveAllValues(ctx context.Context, reqID string, context map[string]any,
+) ([]AnyValue, error) {
+	values, err := r.IEvaluator.ResolveAllValues(ctx, reqID, context)
+	r.recordAll(context, values)
+	return values, err
+}
+
+func (r *SampleRecorder) ResolveAllValuesWithVersion(ctx context.Context, reqID string, context map[string]any,
+) ([]AnyValue, string, error) {
+	values, version, err := r.IEvaluator.ResolveAllValuesWithVersion(ctx, reqID, context)
+	r.recordAll(context, values)
+	return values, version, err
+}
+
+// recordAll records the values of a bulk evaluation against the given context
+func (r *SampleRecorder) recordAll(context map[string]any, values []AnyValue) {
+	redacted := r.redact(context)
+	for _, value := range values {
+		r.record(value.FlagKey, redacted, value.Value, value.Variant, value.Reason, value.Error)
+	}
+}
+
+func (r *SampleRecorder) ResolveBatchValues(ctx context.Context, reqID string, flagKey string,
+	contexts []map[string]any,
+) []AnyValue {
+	values := r.IEvaluator.ResolveBatchValues(ctx, reqID, flagKey, contexts)
+	for i, value := range values {
+		r.record(flagKey, r.redact(contexts[i]), value.Value, value.Variant, value.Reason, value.Error)
+	}
+	return values
+}
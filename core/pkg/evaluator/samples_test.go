@@ -0,0 +1,67 @@
+package evaluator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/evaluator"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleRecorder(t *testing.T) {
+	json := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := json.SetState(sync.DataSync{FlagData: Flags})
+	require.NoError(t, err)
+
+	recorder := evaluator.NewSampleRecorder(json, 2, evaluator.RedactKeys("email", "peer.ip"))
+	evalCtx := map[string]any{
+		ColorProp: ColorValue,
+		"email":   "user@example.com",
+		"peer":    map[string]any{"ip": "10.0.0.1", "country": "DE"},
+	}
+
+	val, _, _, _, err := recorder.ResolveBooleanValue(context.TODO(), "1", StaticBoolFlag, nil)
+	require.NoError(t, err)
+	assert.Equal(t, StaticBoolValue, val)
+	_, _, _, _, err = recorder.ResolveBooleanValue(context.TODO(), "2", DynamicBoolFlag, evalCtx)
+	require.NoError(t, err)
+	_, _, _, _, _ = recorder.ResolveStringValue(context.TODO(), "3", MissingFlag, nil)
+
+	// the oldest sample is evicted once the buffer is full
+	samples := recorder.Samples()
+	require.Len(t, samples, 2)
+
+	assert.Equal(t, DynamicBoolFlag, samples[0].FlagKey)
+	assert.Equal(t, model.TargetingMatchReason, samples[0].Reason)
+	assert.Equal(t, map[string]any{
+		ColorProp: ColorValue,
+		"email":   evaluator.RedactedValue,
+		"peer":    map[string]any{"ip": evaluator.RedactedValue, "country": "DE"},
+	}, samples[0].Context)
+	assert.Equal(t, "user@example.com", evalCtx["email"], "the evaluation context must not be modified")
+
+	assert.Equal(t, MissingFlag, samples[1].FlagKey)
+	assert.Equal(t, model.FlagNotFoundErrorCode, samples[1].Error)
+}
+
+func TestSampleRecorder_ResolveAll(t *testing.T) {
+	json := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := json.SetState(sync.DataSync{FlagData: Flags})
+	require.NoError(t, err)
+
+	recorder := evaluator.NewSampleRecorder(json, 100, nil)
+	values, err := recorder.ResolveAllValues(context.TODO(), "1", nil)
+	require.NoError(t, err)
+
+	samples := recorder.Samples()
+	require.Len(t, samples, len(values))
+	for i, value := range values {
+		assert.Equal(t, value.FlagKey, samples[i].FlagKey)
+		assert.Equal(t, value.Variant, samples[i].Variant)
+	}
+}
@@ -0,0 +1,69 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+// SelfTest checks the default variant of each stored flag of the given source, or of all sources if the source is
+// empty. The default variant must be a variant of the flag, and its value must be of the type declared by the other
+// variants of the flag, integers and floats being numbers alike. Flags failing the check are reported in one error,
+// so that configuration errors are caught at startup instead of by the first evaluation of the flag.
+func (je *JSON) SelfTest(ctx context.Context, source string) error {
+	flags, err := je.store.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("error retrieving flags: %w", err)
+	}
+
+	keys := make([]string, 0, len(flags))
+	for key, flag := range flags {
+		if source == "" || flag.Source == source {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		if err := selfTestFlag(flags[key]); err != nil {
+			errs = append(errs, fmt.Errorf("flag '%s': %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// selfTestFlag checks that the default variant of a flag exists and matches the type declared by its other variants
+func selfTestFlag(flag model.Flag) error {
+	defaultValue, ok := flag.Variants[flag.DefaultVariant]
+	if !ok {
+		return fmt.Errorf("default variant '%s' isn't a variant of the flag", flag.DefaultVariant)
+	}
+	defaultType := selfTestType(defaultValue)
+
+	variants := make([]string, 0, len(flag.Variants))
+	for variant := range flag.Variants {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+	for _, variant := range variants {
+		if variantType := selfTestType(flag.Variants[variant]); variantType != defaultType {
+			return fmt.Errorf("default variant '%s' is of type %s, but variant '%s' is of type %s",
+				flag.DefaultVariant, defaultType, variant, variantType)
+		}
+	}
+	return nil
+}
+
+// selfTestType returns the type of a variant value, numbers are of the float type regardless of their fraction, as
+// integer flags may be evaluated as floats
+func selfTestType(value any) string {
+	if valueType := valueType(value); valueType != telemetry.TypeInteger {
+		return valueType
+	}
+	return telemetry.TypeFloat
+}
@@ -0,0 +1,70 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	tests := map[string]struct {
+		flags  map[string]model.Flag
+		source string
+		err    string
+	}{
+		"valid defaults": {
+			flags: map[string]model.Flag{
+				"bool":   {Variants: map[string]any{"on": true, "off": false}, DefaultVariant: "off"},
+				"number": {Variants: map[string]any{"one": 1.0, "half": 0.5}, DefaultVariant: "one"},
+				"object": {Variants: map[string]any{"a": map[string]any{}, "b": map[string]any{}}, DefaultVariant: "a"},
+			},
+		},
+		"missing default variant": {
+			flags: map[string]model.Flag{
+				"flag": {Variants: map[string]any{"on": true}, DefaultVariant: "off"},
+			},
+			err: "flag 'flag': default variant 'off' isn't a variant of the flag",
+		},
+		"mismatching default variant": {
+			flags: map[string]model.Flag{
+				"flag": {Variants: map[string]any{"on": true, "off": "false"}, DefaultVariant: "off"},
+			},
+			err: "flag 'flag': default variant 'off' is of type string, but variant 'on' is of type boolean",
+		},
+		"all failing flags": {
+			flags: map[string]model.Flag{
+				"a": {Variants: map[string]any{"on": true}, DefaultVariant: "off"},
+				"b": {Variants: map[string]any{"on": true}, DefaultVariant: "off"},
+			},
+			err: "flag 'a': default variant 'off' isn't a variant of the flag\n" +
+				"flag 'b': default variant 'off' isn't a variant of the flag",
+		},
+		"other sources are skipped": {
+			flags: map[string]model.Flag{
+				"flag": {Variants: map[string]any{"on": true}, DefaultVariant: "off", Source: "other"},
+			},
+			source: "source",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			flagStore := store.NewFlags()
+			for key, flag := range tt.flags {
+				flagStore.Set(key, flag)
+			}
+			evaluator := NewJSON(logger.NewLogger(nil, false), flagStore)
+
+			err := evaluator.SelfTest(context.Background(), tt.source)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
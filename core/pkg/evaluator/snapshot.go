@@ -0,0 +1,33 @@
+package evaluator
+
+import "github.com/open-feature/flagd/core/pkg/store"
+
+// ConfigVersionMetadataKey is the metadata key of bulk evaluation responses holding the version of the configuration
+// all flags of the response were evaluated with
+const ConfigVersionMetadataKey = "configVersion"
+
+// snapshotStore is implemented by stores providing immutable snapshots of their flags
+type snapshotStore interface {
+	Snapshot() *store.Flags
+}
+
+// snapshot returns a resolver evaluating against an immutable snapshot of the store and the version of the snapshot,
+// so that all evaluations of a multi-flag request reflect a single configuration even if the configuration changes
+// during the request. Stores without snapshots are evaluated as is, with an empty version.
+func (je *Resolver) snapshot() (*Resolver, string) {
+	s, ok := je.store.(snapshotStore)
+	if !ok {
+		return je, ""
+	}
+	if flags, ok := je.store.(*store.Flags); ok {
+		// the version of the store is retained by its snapshots, instead of being computed per snapshot
+		flags.Version()
+	}
+
+	snapshot := s.Snapshot()
+	resolver := *je
+	resolver.store = snapshot
+	// results are cached per snapshot version, as cached results may stem from a different configuration
+	resolver.snapshotVersion = snapshot.Version()
+	return &resolver, resolver.snapshotVersion
+}
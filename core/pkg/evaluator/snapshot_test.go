@@ -0,0 +1,100 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverSnapshot(t *testing.T) {
+	s := store.NewFlags()
+	evaluator := NewJSON(logger.NewLogger(nil, false), s)
+	apply := func(color string) {
+		t.Helper()
+		_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL,
+			FlagData: fmt.Sprintf(`{
+				"flags": {
+					"color": {"state": "ENABLED", "variants": {"red": "red", "blue": "blue"}, "defaultVariant": "%s"}
+				}
+			}`, color)})
+		require.NoError(t, err)
+	}
+
+	apply("red")
+	version := s.Version()
+	snapshot, snapshotVersion := evaluator.snapshot()
+	assert.Equal(t, version, snapshotVersion)
+
+	apply("blue")
+	require.NotEqual(t, version, s.Version())
+
+	// the snapshot is unaffected by the applied configuration
+	values, gotVersion, err := snapshot.ResolveAllValuesWithVersion(context.Background(), "req", nil)
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.Equal(t, "red", values[0].Value)
+	assert.Equal(t, version, gotVersion)
+
+	values, gotVersion, err = evaluator.ResolveAllValuesWithVersion(context.Background(), "req", nil)
+	require.NoError(t, err)
+	require.Len(t, values, 1)
+	assert.Equal(t, "blue", values[0].Value)
+	assert.Equal(t, s.Version(), gotVersion)
+}
+
+func TestResolverSnapshotCache(t *testing.T) {
+	recorder := &cacheRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+	apply := func(variant string) {
+		t.Helper()
+		_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: fmt.Sprintf(`{
+			"flags": {
+				"static": {
+					"state": "ENABLED",
+					"variants": {"on": true, "off": false},
+					"defaultVariant": "%s",
+					"metadata": {"evaluationCacheTTL": "1m"}
+				}
+			}
+		}`, variant)})
+		require.NoError(t, err)
+	}
+
+	apply("on")
+	snapshot, _ := evaluator.snapshot()
+	for range 2 {
+		values, err := evaluator.ResolveAllValues(context.Background(), "req", nil)
+		require.NoError(t, err)
+		require.Len(t, values, 1)
+		assert.Equal(t, true, values[0].Value)
+	}
+	assert.Equal(t, []string{"static/miss", "static/hit"}, recorder.lookups)
+
+	// results cached by the snapshot of a configuration aren't served by snapshots of other configurations
+	apply("off")
+	values, err := snapshot.ResolveAllValues(context.Background(), "req", nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, values[0].Value)
+	values, err = evaluator.ResolveAllValues(context.Background(), "req", nil)
+	require.NoError(t, err)
+	assert.Equal(t, false, values[0].Value)
+}
+
+func TestResolverSnapshotWithoutSnapshots(t *testing.T) {
+	resolver := NewResolver(nonSnapshotStore{store.NewFlags()}, logger.NewLogger(nil, false), nil)
+
+	snapshot, version := resolver.snapshot()
+	assert.Same(t, &resolver, snapshot)
+	assert.Empty(t, version)
+}
+
+// nonSnapshotStore hides the snapshots of the embedded store
+type nonSnapshotStore struct {
+	store.IStore
+}
@@ -0,0 +1,114 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// StaleGuard is an IEvaluator failing all evaluations with a general error while the flag configuration is stale, e.g.
+// as all sync sources were lost for too long, so that clients fall back to their defaults instead of being served an
+// outdated configuration
+type StaleGuard struct {
+	IEvaluator
+	stale func() bool
+}
+
+// NewStaleGuard wraps the given evaluator, failing evaluations whenever the stale function reports the configuration
+// as stale
+func NewStaleGuard(eval IEvaluator, stale func() bool) *StaleGuard {
+	return &StaleGuard{
+		IEvaluator: eval,
+		stale:      stale,
+	}
+}
+
+func (g *StaleGuard) ResolveBooleanValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (bool, string, string, map[string]interface{}, error) {
+	if g.stale() {
+		return false, "", model.ErrorReason, map[string]interface{}{}, staleError()
+	}
+	return g.IEvaluator.ResolveBooleanValue(ctx, reqID, flagKey, context)
+}
+
+func (g *StaleGuard) ResolveStringValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (string, string, string, map[string]interface{}, error) {
+	if g.stale() {
+		return "", "", model.ErrorReason, map[string]interface{}{}, staleError()
+	}
+	return g.IEvaluator.ResolveStringValue(ctx, reqID, flagKey, context)
+}
+
+func (g *StaleGuard) ResolveIntValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (int64, string, string, map[string]interface{}, error) {
+	if g.stale() {
+		return 0, "", model.ErrorReason, map[string]interface{}{}, staleError()
+	}
+	return g.IEvaluator.ResolveIntValue(ctx, reqID, flagKey, context)
+}
+
+func (g *StaleGuard) ResolveFloatValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (float64, string, string, map[string]interface{}, error) {
+	if g.stale() {
+		return 0, "", model.ErrorReason, map[string]interface{}{}, staleError()
+	}
+	return g.IEvaluator.ResolveFloatValue(ctx, reqID, flagKey, context)
+}
+
+func (g *StaleGuard) ResolveObjectValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) (map[string]any, string, string, map[string]interface{}, error) {
+	if g.stale() {
+		return nil, "", model.ErrorReason, map[string]interface{}{}, staleError()
+	}
+	return g.IEvaluator.ResolveObjectValue(ctx, reqID, flagKey, context)
+}
+
+func (g *StaleGuard) ResolveAsAnyValue(ctx context.Context, reqID string, flagKey string,
+	context map[string]any,
+) AnyValue {
+	if g.stale() {
+		return NewAnyValue(nil, "", model.ErrorReason, flagKey, map[string]interface{}{}, staleError())
+	}
+	return g.IEvaluator.ResolveAsAnyValue(ctx, reqID, flagKey, context)
+}
+
+func (g *StaleGuard) ResolveAllValues(ctx context.Context, reqID string, context map[string]any,
+) ([]AnyValue, error) {
+	if g.stale() {
+		return nil, staleError()
+	}
+	return g.IEvaluator.ResolveAllValues(ctx, reqID, context)
+}
+
+func (g *StaleGuard) ResolveAllValuesWithVersion(ctx context.Context, reqID string, context map[string]any,
+) ([]AnyValue, string, error) {
+	if g.stale() {
+		return nil, "", staleError()
+	}
+	return g.IEvaluator.ResolveAllValuesWithVersion(ctx, reqID, context)
+}
+
+func (g *StaleGuard) ResolveBatchValues(ctx context.Context, reqID string, flagKey string,
+	contexts []map[string]any,
+) []AnyValue {
+	if g.stale() {
+		values := make([]AnyValue, 0, len(contexts))
+		for range contexts {
+			values = append(values,
+				NewAnyValue(nil, "", model.ErrorReason, flagKey, map[string]interface{}{}, staleError()))
+		}
+		return values
+	}
+	return g.IEvaluator.ResolveBatchValues(ctx, reqID, flagKey, contexts)
+}
+
+// staleError is the error of evaluations of a stale configuration
+func staleError() error {
+	return errors.New(model.GeneralErrorCode)
+}
@@ -0,0 +1,50 @@
+package evaluator_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/evaluator"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaleGuard(t *testing.T) {
+	json := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := json.SetState(sync.DataSync{FlagData: Flags})
+	require.NoError(t, err)
+
+	stale := false
+	guard := evaluator.NewStaleGuard(json, func() bool { return stale })
+
+	val, _, reason, _, err := guard.ResolveBooleanValue(context.TODO(), "1", StaticBoolFlag, nil)
+	require.NoError(t, err)
+	assert.Equal(t, StaticBoolValue, val)
+	assert.Equal(t, model.StaticReason, reason)
+
+	stale = true
+	_, _, reason, _, err = guard.ResolveBooleanValue(context.TODO(), "2", StaticBoolFlag, nil)
+	require.EqualError(t, err, model.GeneralErrorCode)
+	assert.Equal(t, model.ErrorReason, reason)
+	_, _, _, _, err = guard.ResolveStringValue(context.TODO(), "3", StaticStringFlag, nil)
+	require.EqualError(t, err, model.GeneralErrorCode)
+	_, _, _, _, err = guard.ResolveIntValue(context.TODO(), "4", StaticIntFlag, nil)
+	require.EqualError(t, err, model.GeneralErrorCode)
+	_, _, _, _, err = guard.ResolveFloatValue(context.TODO(), "5", StaticFloatFlag, nil)
+	require.EqualError(t, err, model.GeneralErrorCode)
+	_, _, _, _, err = guard.ResolveObjectValue(context.TODO(), "6", StaticObjectFlag, nil)
+	require.EqualError(t, err, model.GeneralErrorCode)
+	value := guard.ResolveAsAnyValue(context.TODO(), "7", StaticBoolFlag, nil)
+	require.EqualError(t, value.Error, model.GeneralErrorCode)
+	assert.Equal(t, StaticBoolFlag, value.FlagKey)
+	_, err = guard.ResolveAllValues(context.TODO(), "8", nil)
+	require.EqualError(t, err, model.GeneralErrorCode)
+
+	// the configuration is still applied while it is stale
+	_, _, err = guard.SetState(sync.DataSync{FlagData: Flags})
+	require.NoError(t, err)
+}
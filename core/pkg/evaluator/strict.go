@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"fmt"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+// StrictOperatorPrefix prefixes the strict counterparts of the JsonLogic comparison operators
+const StrictOperatorPrefix = "strict_"
+
+type valueKind int
+
+const (
+	invalidKind valueKind = iota
+	nullKind
+	boolKind
+	numberKind
+	stringKind
+)
+
+// strictComparisons holds the comparison operators which are replaced by their strict counterparts in strict
+// targeting mode. Strict comparisons do not coerce their operands: type-mismatched operands are never equal and never
+// ordered.
+var strictComparisons = map[string]func(values []any) bool{
+	"==": strictEquals,
+	"!=": func(values []any) bool {
+		return len(values) == 2 && !strictEquals(values)
+	},
+	"<": func(values []any) bool {
+		return strictOrdered(values, func(c int) bool { return c < 0 })
+	},
+	"<=": func(values []any) bool {
+		return strictOrdered(values, func(c int) bool { return c <= 0 })
+	},
+	">": func(values []any) bool {
+		return strictOrdered(values, func(c int) bool { return c > 0 })
+	},
+	">=": func(values []any) bool {
+		return strictOrdered(values, func(c int) bool { return c >= 0 })
+	},
+}
+
+// registerStrictOperators registers the strict comparison operators with JsonLogic
+func registerStrictOperators() {
+	for operator, compare := range strictComparisons {
+		jsonlogic.AddOperator(StrictOperatorPrefix+operator, func(values, _ interface{}) interface{} {
+			list, ok := values.([]any)
+			if !ok {
+				return false
+			}
+			return compare(list)
+		})
+	}
+}
+
+func kindOf(value any) valueKind {
+	switch value.(type) {
+	case nil:
+		return nullKind
+	case bool:
+		return boolKind
+	case float64:
+		return numberKind
+	case string:
+		return stringKind
+	default:
+		return invalidKind
+	}
+}
+
+// strictEquals reports whether both operands are of the same primitive type and equal
+func strictEquals(values []any) bool {
+	if len(values) != 2 {
+		return false
+	}
+	kind := kindOf(values[0])
+	if kind == invalidKind || kind != kindOf(values[1]) {
+		return false
+	}
+	return values[0] == values[1]
+}
+
+// strictOrdered reports whether each pair of consecutive operands satisfies the ordering. All operands must either be
+// numbers or strings, which allows the two operand form as well as the three operand "between" form.
+func strictOrdered(values []any, ordered func(c int) bool) bool {
+	if len(values) != 2 && len(values) != 3 {
+		return false
+	}
+	kind := kindOf(values[0])
+	if kind != numberKind && kind != stringKind {
+		return false
+	}
+	for _, value := range values[1:] {
+		if kindOf(value) != kind {
+			return false
+		}
+	}
+	for i := 0; i < len(values)-1; i++ {
+		if !ordered(compare(values[i], values[i+1])) {
+			return false
+		}
+	}
+	return true
+}
+
+// compare returns the ordering of two numbers or two strings
+func compare(a, b any) int {
+	switch x := a.(type) {
+	case float64:
+		y, _ := b.(float64)
+		return compareOrdered(x, y)
+	case string:
+		y, _ := b.(string)
+		return compareOrdered(x, y)
+	}
+	return 0
+}
+
+func compareOrdered[T float64 | string](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// strictTargeting reports whether the targeting of a flag is evaluated in strict mode. The StrictTargetingMetadataKey
+// of the flag or flag set metadata takes precedence over the default.
+func strictTargeting(log *logger.Logger, key string, metadata map[string]interface{}, defaultStrict bool) bool {
+	value, ok := metadata[StrictTargetingMetadataKey]
+	if !ok {
+		return defaultStrict
+	}
+	strict, ok := value.(bool)
+	if !ok {
+		log.Warn(fmt.Sprintf("ignoring invalid %s metadata of flag %s, expected a boolean but got %v",
+			StrictTargetingMetadataKey, key, value))
+		return defaultStrict
+	}
+	return strict
+}
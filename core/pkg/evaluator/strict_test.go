@@ -0,0 +1,170 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strictFlagConfig(condition string, metadata string) string {
+	return fmt.Sprintf(`{
+		"flags": {
+			"strict-flag": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [%s, "on", "off"]}%s
+			}
+		}
+	}`, condition, metadata)
+}
+
+func TestStrictTargeting(t *testing.T) {
+	tests := map[string]struct {
+		condition string
+		loose     bool
+		strict    bool
+	}{
+		"== string and number":         {condition: `{"==": [{"var": "value"}, 5]}`, loose: true, strict: false},
+		"== numbers":                   {condition: `{"==": [5, 5]}`, loose: true, strict: true},
+		"== strings":                   {condition: `{"==": ["5", "5"]}`, loose: true, strict: true},
+		"== booleans":                  {condition: `{"==": [true, true]}`, loose: true, strict: true},
+		"== boolean and number":        {condition: `{"==": [true, 1]}`, loose: true, strict: false},
+		"== nulls":                     {condition: `{"==": [null, null]}`, loose: true, strict: true},
+		"== missing and number":        {condition: `{"==": [{"var": "missing"}, 0]}`, loose: false, strict: false},
+		"!= string and number":         {condition: `{"!=": [{"var": "value"}, 5]}`, loose: false, strict: true},
+		"!= numbers":                   {condition: `{"!=": [5, 6]}`, loose: true, strict: true},
+		"< string and number":          {condition: `{"<": [{"var": "value"}, 6]}`, loose: true, strict: false},
+		"< numbers":                    {condition: `{"<": [5, 6]}`, loose: true, strict: true},
+		"< strings":                    {condition: `{"<": ["a", "b"]}`, loose: true, strict: true},
+		"< missing and number":         {condition: `{"<": [{"var": "missing"}, 1]}`, loose: true, strict: false},
+		"< between numbers":            {condition: `{"<": [1, 5, 10]}`, loose: true, strict: true},
+		"< between string and numbers": {condition: `{"<": [1, {"var": "value"}, 10]}`, loose: true, strict: false},
+		"<= string and number":         {condition: `{"<=": [{"var": "value"}, 5]}`, loose: true, strict: false},
+		"<= numbers":                   {condition: `{"<=": [5, 5]}`, loose: true, strict: true},
+		"<= between numbers":           {condition: `{"<=": [1, 1, 10]}`, loose: true, strict: true},
+		"> string and number":          {condition: `{">": [{"var": "value"}, 4]}`, loose: true, strict: false},
+		"> numbers":                    {condition: `{">": [6, 5]}`, loose: true, strict: true},
+		">= string and number":         {condition: `{">=": [{"var": "value"}, 5]}`, loose: true, strict: false},
+		">= strings":                   {condition: `{">=": ["b", "b"]}`, loose: true, strict: true},
+		"=== is unchanged":             {condition: `{"===": [{"var": "value"}, 5]}`, loose: false, strict: false},
+		"nested comparison":            {condition: `{"and": [true, {"==": [{"var": "value"}, 5]}]}`, loose: true, strict: false},
+		"negated comparison":           {condition: `{"!": {"==": [{"var": "value"}, 5]}}`, loose: false, strict: true},
+	}
+
+	evalCtx := map[string]any{"value": "5"}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				var opts []JSONEvaluatorOption
+				expected := tt.loose
+				if strict {
+					opts = append(opts, WithStrictTargeting())
+					expected = tt.strict
+				}
+				evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), opts...)
+
+				_, _, err := evaluator.SetState(sync.DataSync{FlagData: strictFlagConfig(tt.condition, "")})
+				require.NoError(t, err)
+
+				value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "strict-flag", evalCtx)
+				require.NoError(t, err)
+				assert.Equal(t, expected, value, "strict: %v", strict)
+			}
+		})
+	}
+}
+
+func TestStrictTargeting_FlagOverride(t *testing.T) {
+	const condition = `{"==": [{"var": "value"}, 5]}`
+	evalCtx := map[string]any{"value": "5"}
+
+	tests := map[string]struct {
+		opts     []JSONEvaluatorOption
+		metadata string
+		expected bool
+	}{
+		"flag enables strict mode": {
+			metadata: `, "metadata": {"strictTargeting": true}`,
+			expected: false,
+		},
+		"flag disables strict mode": {
+			opts:     []JSONEvaluatorOption{WithStrictTargeting()},
+			metadata: `, "metadata": {"strictTargeting": false}`,
+			expected: true,
+		},
+		"invalid metadata falls back to default": {
+			opts:     []JSONEvaluatorOption{WithStrictTargeting()},
+			metadata: `, "metadata": {"strictTargeting": "no"}`,
+			expected: false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), tt.opts...)
+
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: strictFlagConfig(condition, tt.metadata)})
+			require.NoError(t, err)
+
+			value, _, _, _, err := evaluator.ResolveBooleanValue(context.Background(), "", "strict-flag", evalCtx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestStrictTargeting_FlagSetOverride(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"metadata": {"strictTargeting": true},
+		"flags": {
+			"strict-flag": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {"if": [{"==": [{"var": "value"}, 5]}, "on", "off"]}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	value, _, _, _, err := evaluator.ResolveBooleanValue(
+		context.Background(), "", "strict-flag", map[string]any{"value": "5"})
+	require.NoError(t, err)
+	assert.False(t, value)
+}
+
+func TestCompileStrictTargeting(t *testing.T) {
+	const targeting = `{"if": [{"<": [{"var": "age"}, 9007199254740993]}, "minor", {"===": [1, 1]}]}`
+	flags := Flags{
+		Flags: map[string]model.Flag{
+			"flag":   {Targeting: []byte(targeting)},
+			"lookup": {Targeting: []byte(`{"lookup": ["operators", {"var": "op"}]}`)},
+		},
+		lookups: map[string]LookupTable{
+			"operators": {Entries: map[string]json.RawMessage{"==": json.RawMessage(`"equals"`)}},
+		},
+	}
+
+	require.NoError(t, compileTargeting(logger.NewLogger(nil, false), &flags, true))
+	compiled := string(evaluatedTargeting(flags.Flags["flag"]))
+	assert.JSONEq(t,
+		`{"if": [{"strict_<": [{"var": "age"}, 9007199254740993]}, "minor", {"===": [1, 1]}]}`, compiled)
+	assert.Contains(t, compiled, "9007199254740993")
+	// the targeting is kept as written
+	assert.JSONEq(t, targeting, string(flags.Flags["flag"].Targeting))
+	// the entries of lookup tables aren't operators
+	assert.JSONEq(t, `{"lookup": [[{"==": "equals"}, null], {"var": "op"}]}`,
+		string(evaluatedTargeting(flags.Flags["lookup"])))
+}
@@ -0,0 +1,36 @@
+package evaluator
+
+import (
+	"github.com/open-feature/flagd/core/pkg/model"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// endSyncSpan ends a span of a stage of applying a sync, recording the error the stage failed with, if any
+func endSyncSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, "flagSync error")
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// changeCountAttributes are the span attributes counting the flags changed through a sync, in total and per type of
+// change
+func changeCountAttributes(events map[string]interface{}) []attribute.KeyValue {
+	counts := map[string]int{}
+	for _, event := range events {
+		if e, ok := event.(map[string]interface{}); ok {
+			if changeType, ok := e["type"].(string); ok {
+				counts[changeType]++
+			}
+		}
+	}
+	return []attribute.KeyValue{
+		attribute.Int("feature_flag.change_count", len(events)),
+		attribute.Int("feature_flag.change_count.created", counts[string(model.NotificationCreate)]),
+		attribute.Int("feature_flag.change_count.updated", counts[string(model.NotificationUpdate)]),
+		attribute.Int("feature_flag.change_count.deleted", counts[string(model.NotificationDelete)]),
+	}
+}
@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetStateSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	evaluator.jsonEvalTracer = provider.Tracer("test")
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "applySync")
+	_, _, err := evaluator.SetStateWithContext(ctx, sync.DataSync{FlagData: `{
+		"flags": {
+			"a": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"},
+			"b": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+	_, _, err = evaluator.SetStateWithContext(ctx, sync.DataSync{FlagData: `{
+		"flags": {
+			"a": {"state": "DISABLED", "variants": {"on": true}, "defaultVariant": "on"}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	names := []string{}
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{
+		"parse", "validate", "storeUpdate", "flagSync",
+		"parse", "validate", "storeUpdate", "flagSync",
+		"applySync",
+	}, names)
+
+	for _, span := range spans[:4] {
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID(), "the spans share the trace")
+	}
+	assert.Equal(t, spans[3].SpanContext().SpanID(), spans[0].Parent().SpanID(), "parse is a child of flagSync")
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[3].Parent().SpanID(), "flagSync is a child of the context")
+
+	assert.Contains(t, spans[3].Attributes(), attribute.Int("feature_flag.change_count.created", 2))
+	assert.Contains(t, spans[7].Attributes(), attribute.Int("feature_flag.change_count", 2))
+	assert.Contains(t, spans[7].Attributes(), attribute.Int("feature_flag.change_count.updated", 1))
+	assert.Contains(t, spans[7].Attributes(), attribute.Int("feature_flag.change_count.deleted", 1))
+	assert.Contains(t, spans[6].Attributes(), attribute.Int("feature_flag.change_count.deleted", 1))
+}
+
+func TestSetStateSpansOnParseError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	evaluator.jsonEvalTracer = provider.Tracer("test")
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{"flags": `, Source: "testSource",
+		Type: sync.ALL})
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "parse", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "flagSync", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
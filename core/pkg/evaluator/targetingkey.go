@@ -0,0 +1,97 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/diegoholiveira/jsonlogic/v3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TargetingKeySourceAttribute is the span attribute holding the source of a targeting key synthesized by the
+	// default targeting key expression
+	TargetingKeySourceAttribute = "feature_flag.flagd.targeting_key.source"
+	// defaultTargetingKeySource is the source of targeting keys synthesized by the default targeting key expression
+	defaultTargetingKeySource = "default_targeting_key"
+)
+
+// WithDefaultTargetingKey synthesizes the targeting key of evaluation contexts lacking one with the given JsonLogic
+// expression, e.g. {"cat": [{"var": "peer.ip"}, "/", {"var": "sessionId"}]}. This keeps the fractional assignments of
+// clients which don't send a targeting key stable across evaluations. The expression is applied to the evaluation
+// context and must result in a non-empty string or a number, otherwise no targeting key is set.
+func WithDefaultTargetingKey(rule json.RawMessage) JSONEvaluatorOption {
+	return func(je *JSON) {
+		if len(rule) > 0 {
+			je.defaultTargetingKey = rule
+		}
+	}
+}
+
+// withDefaultTargetingKey returns the evaluation context with the targeting key synthesized by the default targeting
+// key expression, if the context lacks a targeting key. The evaluation context is returned unmodified otherwise.
+func (je *Resolver) withDefaultTargetingKey(
+	ctx context.Context, reqID string, flagKey string, evalCtx map[string]any,
+) map[string]any {
+	if je.defaultTargetingKey == nil {
+		return evalCtx
+	}
+	if targetingKey, ok := evalCtx[targetingKeyKey].(string); ok && targetingKey != "" {
+		return evalCtx
+	}
+
+	data, err := json.Marshal(evalCtx)
+	if err != nil {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("not synthesizing the targeting key of flag %s, the context can't "+
+			"be encoded: %v", flagKey, err))
+		return evalCtx
+	}
+	var result bytes.Buffer
+	if err := jsonlogic.Apply(bytes.NewReader(je.defaultTargetingKey), bytes.NewReader(data), &result); err != nil {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("not synthesizing the targeting key of flag %s, the default "+
+			"targeting key expression failed: %v", flagKey, err))
+		return evalCtx
+	}
+
+	targetingKey, ok := targetingKeyFrom(result.Bytes())
+	if !ok {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("not synthesizing the targeting key of flag %s, the default "+
+			"targeting key expression resulted in %s", flagKey, bytes.TrimSpace(result.Bytes())))
+		return evalCtx
+	}
+
+	je.Logger.DebugWithID(reqID, fmt.Sprintf("synthesized the targeting key of flag %s from the default targeting "+
+		"key expression", flagKey))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String(TargetingKeySourceAttribute, defaultTargetingKeySource))
+
+	synthesized := maps.Clone(evalCtx)
+	if synthesized == nil {
+		synthesized = map[string]any{}
+	}
+	synthesized[targetingKeyKey] = targetingKey
+	return synthesized
+}
+
+// targetingKeyFrom converts the encoded result of the default targeting key expression to a targeting key, which must
+// be a non-empty string or a number
+func targetingKeyFrom(result []byte) (string, bool) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case json.Number:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,103 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultTargetingKeyRule = `{"if": [{"var": "peer.ip"}, {"cat": [{"var": "peer.ip"}, "/", {"var": "sessionId"}]}, null]}`
+
+func TestWithDefaultTargetingKey(t *testing.T) {
+	peer := map[string]any{"ip": "192.0.2.1"}
+
+	tests := map[string]struct {
+		rule     string
+		context  map[string]any
+		expected any
+	}{
+		"synthesized": {
+			rule:     defaultTargetingKeyRule,
+			context:  map[string]any{"peer": peer, "sessionId": "abc"},
+			expected: "192.0.2.1/abc",
+		},
+		"supplied targeting key": {
+			rule:     defaultTargetingKeyRule,
+			context:  map[string]any{"peer": peer, "targetingKey": "user"},
+			expected: "user",
+		},
+		"empty targeting key is replaced": {
+			rule:     defaultTargetingKeyRule,
+			context:  map[string]any{"peer": peer, "targetingKey": ""},
+			expected: "192.0.2.1/",
+		},
+		"number": {
+			rule:     `{"var": "accountId"}`,
+			context:  map[string]any{"accountId": 42},
+			expected: "42",
+		},
+		"expression without result": {
+			rule:    defaultTargetingKeyRule,
+			context: map[string]any{"sessionId": "abc"},
+		},
+		"expression resulting in an empty string": {
+			rule:    `{"cat": [{"var": "sessionId"}]}`,
+			context: map[string]any{},
+		},
+		"no expression": {
+			context: map[string]any{"peer": peer},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(),
+				WithDefaultTargetingKey(json.RawMessage(tt.rule)))
+			evalCtx := evaluator.withDefaultTargetingKey(context.Background(), "req", "flag", tt.context)
+
+			targetingKey, ok := evalCtx[targetingKeyKey]
+			if tt.expected == nil {
+				assert.Equal(t, tt.context[targetingKeyKey], targetingKey)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, targetingKey)
+		})
+	}
+}
+
+func TestDefaultTargetingKeyFractional(t *testing.T) {
+	const config = `{
+		"flags": {
+			"rollout": {
+				"state": "ENABLED",
+				"variants": {"none": "none", "a": "a", "b": "b", "c": "c", "d": "d"},
+				"defaultVariant": "none",
+				"targeting": {"fractional": [["a", 25], ["b", 25], ["c", 25], ["d", 25]]}
+			}
+		}
+	}`
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(),
+		WithDefaultTargetingKey(json.RawMessage(defaultTargetingKeyRule)))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+
+	// the assignment of a client without targeting key matches the assignment of the synthesized key
+	expected, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "req", "rollout",
+		map[string]any{"targetingKey": "192.0.2.1/abc"})
+	require.NoError(t, err)
+	for range 10 {
+		value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "req", "rollout",
+			map[string]any{"peer": map[string]any{"ip": "192.0.2.1"}, "sessionId": "abc"})
+		require.NoError(t, err)
+		assert.Equal(t, expected, value)
+		assert.Equal(t, model.TargetingMatchReason, reason)
+	}
+}
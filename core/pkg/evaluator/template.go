@@ -0,0 +1,160 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+const (
+	// ValueTemplateMetadataKey is the flag metadata key opting the string and object variants of a flag into value
+	// templating, selecting the handling of placeholders missing from the evaluation context, either
+	// ValueTemplateStrict or ValueTemplateLiteral
+	ValueTemplateMetadataKey = "valueTemplate"
+	// ValueTemplateStrict fails evaluations whose evaluation context misses a placeholder of the resolved value
+	ValueTemplateStrict = "strict"
+	// ValueTemplateLiteral leaves placeholders missing from the evaluation context in the resolved value as they are
+	ValueTemplateLiteral = "literal"
+)
+
+// placeholderPattern matches the placeholders of templated values, e.g. {{ user.region }}, naming a property of the
+// evaluation context by its dot separated path
+var placeholderPattern = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// valueTemplate returns the handling of missing placeholders of a flag opted into value templating, empty if the flag
+// isn't opted in
+func valueTemplate(metadata map[string]interface{}) (string, error) {
+	value, ok := metadata[ValueTemplateMetadataKey]
+	if !ok {
+		return "", nil
+	}
+	mode, ok := value.(string)
+	if !ok || (mode != ValueTemplateStrict && mode != ValueTemplateLiteral) {
+		return "", fmt.Errorf("expected %q or %q but got %v", ValueTemplateStrict, ValueTemplateLiteral, value)
+	}
+	return mode, nil
+}
+
+// warnInvalidValueTemplates logs the flags whose value template metadata is invalid, these are not templated
+func warnInvalidValueTemplates(log *logger.Logger, flags *Flags) {
+	for key, flag := range flags.Flags {
+		if _, err := valueTemplate(flag.Metadata); err != nil {
+			log.Warn(fmt.Sprintf("ignoring invalid %s metadata of flag %s: %v", ValueTemplateMetadataKey, key, err))
+		}
+	}
+}
+
+// templateVariant substitutes the placeholders of the resolved string or object value of a flag opted into value
+// templating by the properties of the evaluation context. The variants are copied rather than modified, as they are
+// shared with the stored flag and cached results.
+func (je *Resolver) templateVariant(reqID string, flagKey string, evalCtx map[string]any, variant string,
+	variants map[string]interface{}, reason string, metadata map[string]interface{},
+) (string, map[string]interface{}, string, map[string]interface{}, error) {
+	mode, err := valueTemplate(metadata)
+	if err != nil || mode == "" {
+		return variant, variants, reason, metadata, nil
+	}
+	value, ok := variants[variant]
+	if !ok {
+		return variant, variants, reason, metadata, nil
+	}
+	switch value.(type) {
+	case string, map[string]any:
+	default:
+		// only string and object values are templated
+		return variant, variants, reason, metadata, nil
+	}
+
+	templated, err := templateValue(value, evalCtx, mode == ValueTemplateStrict)
+	if err != nil {
+		je.Logger.WarnWithID(reqID, fmt.Sprintf("error templating variant %s of flag %s: %v", variant, flagKey, err))
+		return variant, map[string]interface{}{}, model.ErrorReason, metadata, errors.New(model.GeneralErrorCode)
+	}
+
+	templatedVariants := make(map[string]interface{}, len(variants))
+	for k, v := range variants {
+		templatedVariants[k] = v
+	}
+	templatedVariants[variant] = templated
+	return variant, templatedVariants, reason, metadata, nil
+}
+
+// templateValue substitutes the placeholders of a string, or of the strings nested in an object or array. Strict
+// templating fails on the first placeholder missing from the evaluation context.
+func templateValue(value any, evalCtx map[string]any, strict bool) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return templateString(v, evalCtx, strict)
+	case map[string]any:
+		templated := make(map[string]any, len(v))
+		for key, nested := range v {
+			t, err := templateValue(nested, evalCtx, strict)
+			if err != nil {
+				return nil, err
+			}
+			templated[key] = t
+		}
+		return templated, nil
+	case []any:
+		templated := make([]any, len(v))
+		for i, nested := range v {
+			t, err := templateValue(nested, evalCtx, strict)
+			if err != nil {
+				return nil, err
+			}
+			templated[i] = t
+		}
+		return templated, nil
+	default:
+		return value, nil
+	}
+}
+
+// templateString substitutes the placeholders of a string, strings are substituted as they are and other values by
+// their JSON representation. Null properties count as missing.
+func templateString(s string, evalCtx map[string]any, strict bool) (string, error) {
+	var err error
+	templated := placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if err != nil {
+			return placeholder
+		}
+		path := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		value, ok := contextValue(evalCtx, path)
+		if !ok {
+			if strict {
+				err = fmt.Errorf("placeholder %s is missing from the evaluation context", path)
+			}
+			return placeholder
+		}
+		if str, ok := value.(string); ok {
+			return str
+		}
+		b, marshalErr := json.Marshal(value)
+		if marshalErr != nil {
+			err = fmt.Errorf("marshalling placeholder %s: %w", path, marshalErr)
+			return placeholder
+		}
+		return string(b)
+	})
+	return templated, err
+}
+
+// contextValue returns the non-null property of the evaluation context at a dot separated path
+func contextValue(evalCtx map[string]any, path string) (any, bool) {
+	var value any = evalCtx
+	for _, segment := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[segment]; !ok {
+			return nil, false
+		}
+	}
+	return value, value != nil
+}
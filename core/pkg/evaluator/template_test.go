@@ -0,0 +1,153 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const templateConfig = `{
+	"flags": {
+		"greeting": {
+			"state": "ENABLED",
+			"variants": {"default": "Hello {{ name }} from {{user.region}}!"},
+			"defaultVariant": "default",
+			"metadata": {"valueTemplate": "strict"}
+		},
+		"banner": {
+			"state": "ENABLED",
+			"variants": {
+				"default": {"title": "Welcome to {{ user.region }}", "tags": ["{{plan}}", "static"], "limit": 3}
+			},
+			"defaultVariant": "default",
+			"metadata": {"valueTemplate": "literal"}
+		},
+		"untemplated": {
+			"state": "ENABLED",
+			"variants": {"default": "Hello {{ name }}"},
+			"defaultVariant": "default"
+		},
+		"invalid": {
+			"state": "ENABLED",
+			"variants": {"default": "Hello {{ name }}"},
+			"defaultVariant": "default",
+			"metadata": {"valueTemplate": "sometimes"}
+		}
+	}
+}`
+
+func TestValueTemplateString(t *testing.T) {
+	tests := map[string]struct {
+		flagKey  string
+		evalCtx  map[string]any
+		expected string
+		reason   string
+		err      string
+	}{
+		"substituted": {
+			flagKey:  "greeting",
+			evalCtx:  map[string]any{"name": "Ada", "user": map[string]any{"region": "eu-west"}},
+			expected: "Hello Ada from eu-west!",
+			reason:   model.StaticReason,
+		},
+		"non-string properties are substituted as JSON": {
+			flagKey:  "greeting",
+			evalCtx:  map[string]any{"name": 42.0, "user": map[string]any{"region": []any{"eu"}}},
+			expected: `Hello 42 from ["eu"]!`,
+			reason:   model.StaticReason,
+		},
+		"missing placeholder fails strict templating": {
+			flagKey: "greeting",
+			evalCtx: map[string]any{"name": "Ada"},
+			reason:  model.ErrorReason,
+			err:     model.GeneralErrorCode,
+		},
+		"null placeholder counts as missing": {
+			flagKey: "greeting",
+			evalCtx: map[string]any{"name": "Ada", "user": map[string]any{"region": nil}},
+			reason:  model.ErrorReason,
+			err:     model.GeneralErrorCode,
+		},
+		"flags not opted in are not templated": {
+			flagKey:  "untemplated",
+			evalCtx:  map[string]any{"name": "Ada"},
+			expected: "Hello {{ name }}",
+			reason:   model.StaticReason,
+		},
+		"invalid metadata is ignored": {
+			flagKey:  "invalid",
+			evalCtx:  map[string]any{"name": "Ada"},
+			expected: "Hello {{ name }}",
+			reason:   model.StaticReason,
+		},
+	}
+
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: templateConfig})
+	require.NoError(t, err)
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			value, _, reason, _, err := evaluator.ResolveStringValue(context.Background(), "", tt.flagKey, tt.evalCtx)
+			assert.Equal(t, tt.reason, reason)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestValueTemplateObject(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: templateConfig})
+	require.NoError(t, err)
+
+	value, _, _, _, err := evaluator.ResolveObjectValue(context.Background(), "", "banner",
+		map[string]any{"user": map[string]any{"region": "eu-west"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"title": "Welcome to eu-west",
+		"tags":  []any{"{{plan}}", "static"},
+		"limit": 3.0,
+	}, value, "missing placeholders are left as they are in literal templating")
+
+	value, _, _, _, err = evaluator.ResolveObjectValue(context.Background(), "", "banner",
+		map[string]any{"user": map[string]any{"region": "us-east"}, "plan": "pro"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"title": "Welcome to us-east",
+		"tags":  []any{"pro", "static"},
+		"limit": 3.0,
+	}, value, "the templated value doesn't leak into other evaluations")
+}
+
+func TestValueTemplateCachedFlag(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithEvaluationCacheSize(10))
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"greeting": {
+				"state": "ENABLED",
+				"variants": {"default": "Hello {{ name }}"},
+				"defaultVariant": "default",
+				"metadata": {"valueTemplate": "strict", "evaluationCacheTTL": "1m"}
+			}
+		}
+	}`})
+	require.NoError(t, err)
+
+	for _, name := range []string{"Ada", "Grace"} {
+		value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "", "greeting",
+			map[string]any{"name": name})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello "+name, value, "cached results of flags without targeting are templated per context")
+	}
+}
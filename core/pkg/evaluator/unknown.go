@@ -0,0 +1,131 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	gosync "sync"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+var (
+	// knownConfigFields are the top-level fields of a configuration, including the fields of the state exported by
+	// flagd, so that the state can be applied as a configuration
+	knownConfigFields = withJSONFields(
+		map[string]bool{"$schema": true, "$evaluators": true}, ConfigWithMetadata{}, (*store.Flags)(nil),
+	)
+	// knownFlagFields are the fields of a flag definition
+	knownFlagFields = withJSONFields(map[string]bool{}, model.Flag{})
+)
+
+// withJSONFields adds the lower case JSON field names of structs, or pointers to structs, to a set of fields. Fields
+// are matched case-insensitively, as encoding/json decodes them.
+func withJSONFields(fields map[string]bool, values ...any) map[string]bool {
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			if field.IsExported() && name != "-" {
+				fields[strings.ToLower(name)] = true
+			}
+		}
+	}
+	return fields
+}
+
+// isKnownField reports whether a field is in a set of known fields, ignoring its case
+func isKnownField(fields map[string]bool, field string) bool {
+	return fields[strings.ToLower(field)]
+}
+
+// unknownConfigFields returns the sorted unknown top-level and flag-level fields of a configuration, flag-level fields
+// are prefixed by the path of their flag, e.g. "flags.myFlag.owner". Malformed configurations yield no fields, as their
+// errors are reported when decoding them.
+func unknownConfigFields(config string) []string {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(config), &top); err != nil {
+		return nil
+	}
+
+	var unknown []string
+	for field := range top {
+		if !isKnownField(knownConfigFields, field) {
+			unknown = append(unknown, field)
+		}
+	}
+
+	var flags map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(top["flags"], &flags); err == nil {
+		for key, flag := range flags {
+			for field := range flag {
+				if !isKnownField(knownFlagFields, field) {
+					unknown = append(unknown, fmt.Sprintf("flags.%s.%s", key, field))
+				}
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// unknownConfigFieldsError is returned for configurations with unknown fields, unless their source is lenient
+func unknownConfigFieldsError(fields []string) error {
+	return fmt.Errorf("unknown fields in configuration: '%s'", strings.Join(fields, "', '"))
+}
+
+// unknownFieldsLog tracks the unknown fields already logged per source, so that the fields of lenient sources are
+// logged once instead of on every sync
+type unknownFieldsLog struct {
+	mu     gosync.Mutex
+	logged map[string]map[string]bool
+}
+
+// unlogged returns the fields not logged for the source yet, marking them as logged
+func (l *unknownFieldsLog) unlogged(source string, fields []string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.logged == nil {
+		l.logged = map[string]map[string]bool{}
+	}
+	if l.logged[source] == nil {
+		l.logged[source] = map[string]bool{}
+	}
+	var unlogged []string
+	for _, field := range fields {
+		if !l.logged[source][field] {
+			l.logged[source][field] = true
+			unlogged = append(unlogged, field)
+		}
+	}
+	return unlogged
+}
+
+// checkUnknownFields rejects configurations with unknown fields, unless their source ignores unknown fields, in which
+// case each unknown field is logged once per source
+func (je *JSON) checkUnknownFields(payload sync.DataSync) error {
+	unknown := unknownConfigFields(payload.FlagData)
+	if len(unknown) == 0 {
+		return nil
+	}
+	if !payload.IgnoreUnknownFields {
+		return unknownConfigFieldsError(unknown)
+	}
+
+	for _, field := range je.unknownFields.unlogged(payload.Source, unknown) {
+		je.Logger.Warn(fmt.Sprintf("ignoring unknown field %s of configuration from %s", field, payload.Source))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const unknownFieldsConfig = `{
+	"$schema": "https://flagd.dev/schema/v0/flags.json",
+	"rollouts": {},
+	"flags": {
+		"color": {
+			"state": "ENABLED",
+			"variants": {"red": "red", "blue": "blue"},
+			"defaultVariant": "blue",
+			"owner": "team-a"
+		}
+	}
+}`
+
+func TestUnknownConfigFields(t *testing.T) {
+	tests := map[string]struct {
+		config   string
+		expected []string
+	}{
+		"known fields": {
+			config: `{"$schema": "", "$evaluators": {}, "$lookups": {}, "metadata": {}, "flags": {"a": {"state": "ENABLED",
+				"variants": {}, "defaultVariant": "", "targeting": {}, "metadata": {}}}}`,
+		},
+		"fields are matched case-insensitively": {
+			config: `{"FlagSources": [], "FlagSetFallback": [],
+				"flags": {"a": {"State": "ENABLED", "defaultvariant": ""}}}`,
+		},
+		"unknown fields": {
+			config:   unknownFieldsConfig,
+			expected: []string{"flags.color.owner", "rollouts"},
+		},
+		"unknown fields of nested objects are ignored": {
+			config: `{"flags": {"a": {"variants": {"on": true}, "metadata": {"owner": "team-a"}}}}`,
+		},
+		"malformed configuration": {
+			config: `{"flags": {"a": `,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, unknownConfigFields(tt.config))
+		})
+	}
+}
+
+func TestSetStateUnknownFields(t *testing.T) {
+	t.Run("rejected by default", func(t *testing.T) {
+		evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+		_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: unknownFieldsConfig})
+		require.EqualError(t, err, "unknown fields in configuration: 'flags.color.owner', 'rollouts'")
+	})
+
+	t.Run("ignored and logged once if lenient", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		evaluator := NewJSON(logger.NewLogger(zap.New(core), false), store.NewFlags())
+
+		for range 2 {
+			_, _, err := evaluator.SetState(sync.DataSync{
+				Source: "file", Type: sync.ALL, FlagData: unknownFieldsConfig, IgnoreUnknownFields: true,
+			})
+			require.NoError(t, err)
+		}
+
+		value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "req", "color", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "blue", value)
+		assert.Equal(t, 2, logs.FilterMessageSnippet("ignoring unknown field").Len())
+	})
+}
+
+func TestSetStateExportedState(t *testing.T) {
+	upstream := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := upstream.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: `{
+		"metadata": {"team": "a"},
+		"flags": {
+			"color": {"state": "ENABLED", "variants": {"red": "red", "blue": "blue"}, "defaultVariant": "blue"}
+		}
+	}`})
+	require.NoError(t, err)
+	state, err := upstream.GetState()
+	require.NoError(t, err)
+	require.Contains(t, state, `"FlagSources"`)
+
+	// the exported state is accepted by strict sources
+	downstream := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err = downstream.SetState(sync.DataSync{Source: "state", Type: sync.ALL, FlagData: state})
+	require.NoError(t, err)
+	value, _, _, _, err := downstream.ResolveStringValue(context.Background(), "req", "color", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "blue", value)
+}
@@ -0,0 +1,40 @@
+package evaluator
+
+import (
+	"context"
+
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+type valueTypeKey struct{}
+
+// WithValueType scopes the bulk evaluation of ResolveAllValues to the flags of the given value type, one of
+// telemetry.TypeBoolean, telemetry.TypeString, telemetry.TypeInteger, telemetry.TypeFloat or telemetry.TypeObject.
+// The type of a flag is the type of its default variant, integers are floats as well.
+func WithValueType(ctx context.Context, valueType string) context.Context {
+	return context.WithValue(ctx, valueTypeKey{}, valueType)
+}
+
+// IsValueType reports whether flags can be scoped to the given value type
+func IsValueType(valueType string) bool {
+	switch valueType {
+	case telemetry.TypeBoolean, telemetry.TypeString, telemetry.TypeInteger, telemetry.TypeFloat, telemetry.TypeObject:
+		return true
+	default:
+		return false
+	}
+}
+
+// matchesValueType reports whether a value matches the value type the context is scoped to, if any
+func matchesValueType(ctx context.Context, value any) bool {
+	requested, ok := ctx.Value(valueTypeKey{}).(string)
+	if !ok || requested == "" {
+		return true
+	}
+
+	actual := valueType(value)
+	if requested == telemetry.TypeFloat {
+		return actual == telemetry.TypeFloat || actual == telemetry.TypeInteger
+	}
+	return actual == requested
+}
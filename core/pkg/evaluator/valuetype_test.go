@@ -0,0 +1,82 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAllValuesWithValueType(t *testing.T) {
+	const config = `{
+		"flags": {
+			"enabled": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"},
+			"color": {"state": "ENABLED", "variants": {"red": "red"}, "defaultVariant": "red"},
+			"limit": {"state": "ENABLED", "variants": {"low": 10}, "defaultVariant": "low"},
+			"ratio": {"state": "ENABLED", "variants": {"half": 0.5}, "defaultVariant": "half"},
+			"settings": {"state": "ENABLED", "variants": {"dark": {"theme": "dark"}}, "defaultVariant": "dark"}
+		}
+	}`
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		valueType string
+		expected  []string
+	}{
+		"boolean": {
+			valueType: telemetry.TypeBoolean,
+			expected:  []string{"enabled"},
+		},
+		"string": {
+			valueType: telemetry.TypeString,
+			expected:  []string{"color"},
+		},
+		"integer": {
+			valueType: telemetry.TypeInteger,
+			expected:  []string{"limit"},
+		},
+		"float includes integers": {
+			valueType: telemetry.TypeFloat,
+			expected:  []string{"limit", "ratio"},
+		},
+		"object": {
+			valueType: telemetry.TypeObject,
+			expected:  []string{"settings"},
+		},
+		"unscoped": {
+			expected: []string{"color", "enabled", "limit", "ratio", "settings"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.valueType != "" {
+				ctx = WithValueType(ctx, tt.valueType)
+			}
+			values, err := evaluator.ResolveAllValues(ctx, "req", nil)
+			require.NoError(t, err)
+
+			keys := make([]string, 0, len(values))
+			for _, value := range values {
+				keys = append(keys, value.FlagKey)
+			}
+			assert.ElementsMatch(t, tt.expected, keys)
+		})
+	}
+}
+
+func TestIsValueType(t *testing.T) {
+	for _, valueType := range []string{"boolean", "string", "integer", "float", "object"} {
+		assert.True(t, IsValueType(valueType), valueType)
+	}
+	assert.False(t, IsValueType("number"))
+	assert.False(t, IsValueType(""))
+}
@@ -0,0 +1,188 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"go.uber.org/zap"
+)
+
+const (
+	// VariantRefRejectFlag removes the flags referencing variants they don't define from a configuration, the other
+	// flags of the configuration are applied
+	VariantRefRejectFlag = "flag"
+	// VariantRefRejectConfig rejects configurations with a flag referencing a variant it doesn't define
+	VariantRefRejectConfig = "config"
+)
+
+// ErrUnknownVariant is returned for configurations with a flag referencing a variant it doesn't define
+var ErrUnknownVariant = errors.New("unknown variant")
+
+// WithVariantRefRejection selects how flags referencing variants they don't define are handled when a configuration is
+// loaded, either VariantRefRejectFlag or VariantRefRejectConfig. By default, unknown default variants and region
+// defaults reject the configuration, while unknown variants the targeting resolves to are only logged and fail at
+// evaluation time.
+func WithVariantRefRejection(mode string) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.variantRefRejection = mode
+	}
+}
+
+// variantRef is a reference of a flag to a variant it doesn't define
+type variantRef struct {
+	flag    string
+	variant string
+	kind    string
+	// region is the region of a region default
+	region string
+}
+
+func (ref variantRef) String() string {
+	if ref.kind == telemetry.VariantRefDefault {
+		return fmt.Sprintf("default variant: '%s' isn't a valid variant of flag: '%s'", ref.variant, ref.flag)
+	}
+	if ref.kind == telemetry.VariantRefRegionDefault {
+		return fmt.Sprintf("region default: '%s' of region: '%s' isn't a valid variant of flag: '%s'", ref.variant,
+			ref.region, ref.flag)
+	}
+	return fmt.Sprintf("targeting of flag: '%s' resolves to '%s', which isn't a valid variant", ref.flag, ref.variant)
+}
+
+// unknownVariantRefs returns the references of the flags of a configuration to variants they don't define, ordered by
+// flag key. Region defaults are checked along with the default variant. The targeting is checked for the statically
+// known variants it resolves to, variants computed from the evaluation context are only known at evaluation time.
+func unknownVariantRefs(flags *Flags) []variantRef {
+	keys := make([]string, 0, len(flags.Flags))
+	for key := range flags.Flags {
+		keys = append(keys, key)
+	}
+	// sorted for deterministic errors
+	sort.Strings(keys)
+
+	var refs []variantRef
+	for _, key := range keys {
+		flag := flags.Flags[key]
+		if _, ok := flag.Variants[flag.DefaultVariant]; !ok {
+			refs = append(refs, variantRef{flag: key, variant: flag.DefaultVariant, kind: telemetry.VariantRefDefault})
+		}
+		refs = append(refs, unknownRegionDefaults(key, flag)...)
+
+		if len(flag.Targeting) == 0 {
+			continue
+		}
+		var rule any
+		if err := json.Unmarshal(flag.Targeting, &rule); err != nil {
+			// parsing errors are reported at evaluation time
+			continue
+		}
+		seen := map[string]bool{}
+		for _, result := range targetingResults(rule) {
+			variant, ok := unknownTargetingVariant(flag, result)
+			if ok && !seen[variant] {
+				seen[variant] = true
+				refs = append(refs, variantRef{flag: key, variant: variant, kind: telemetry.VariantRefTargeting})
+			}
+		}
+	}
+	return refs
+}
+
+// unknownRegionDefaults returns the region defaults of a flag referencing variants it doesn't define, ordered by
+// region. Invalid region defaults metadata is logged when the configuration is loaded and not checked.
+func unknownRegionDefaults(key string, flag model.Flag) []variantRef {
+	defaults, err := regionDefaults(flag.Metadata)
+	if err != nil {
+		return nil
+	}
+	regions := make([]string, 0, len(defaults))
+	for region := range defaults {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	var refs []variantRef
+	for _, region := range regions {
+		if _, ok := flag.Variants[defaults[region]]; !ok {
+			refs = append(refs, variantRef{flag: key, variant: defaults[region], kind: telemetry.VariantRefRegionDefault,
+				region: region})
+		}
+	}
+	return refs
+}
+
+// unknownTargetingVariant returns the variant a statically known result of the targeting of a flag resolves to, if the
+// flag doesn't define it. Results depending on the evaluation context, null and booleans mapped to a boolean variant
+// are never unknown.
+func unknownTargetingVariant(flag model.Flag, result any) (string, bool) {
+	var variant string
+	switch r := result.(type) {
+	case string:
+		variant = r
+	case float64:
+		variant = strconv.FormatFloat(r, 'f', -1, 64)
+	case bool:
+		if _, ok := booleanVariant(flag, r); ok {
+			return "", false
+		}
+		variant = strconv.FormatBool(r)
+	default:
+		return "", false
+	}
+	_, ok := flag.Variants[variant]
+	return variant, !ok
+}
+
+// checkVariantRefs logs and counts the references of the flags of a configuration to variants they don't define, and
+// rejects the flags or the configuration as configured
+func (je *JSON) checkVariantRefs(ctx context.Context, payload sync.DataSync, flags *Flags) error {
+	if payload.Type == sync.DELETE {
+		// deletions only refer to the keys of the flags
+		return nil
+	}
+	refs := unknownVariantRefs(flags)
+	if len(refs) == 0 {
+		return nil
+	}
+
+	details := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		je.Logger.Warn(fmt.Sprintf("flag %s references the unknown variant %s", ref.flag, ref.variant),
+			zap.String("flag", ref.flag),
+			zap.String("variant", ref.variant),
+			zap.String("kind", ref.kind),
+			zap.String("source", payload.Source),
+		)
+		telemetry.ConfigMetrics(je.metrics).VariantReferenceError(ctx, payload.Source, ref.kind)
+		details = append(details, ref.String())
+	}
+
+	switch je.variantRefRejection {
+	case VariantRefRejectConfig:
+		return fmt.Errorf("%w: %s", ErrUnknownVariant, strings.Join(details, ", "))
+	case VariantRefRejectFlag:
+		for _, ref := range refs {
+			if _, ok := flags.Flags[ref.flag]; ok {
+				je.Logger.Warn(fmt.Sprintf("not applying flag %s of source %s: %s", ref.flag, payload.Source, ref))
+				delete(flags.Flags, ref.flag)
+			}
+		}
+		return nil
+	default:
+		// unknown default variants and region defaults can't be served, the targeting may still resolve to a valid
+		// variant
+		for _, ref := range refs {
+			if ref.kind == telemetry.VariantRefDefault || ref.kind == telemetry.VariantRefRegionDefault {
+				return fmt.Errorf("%w: %s", ErrUnknownVariant, ref)
+			}
+		}
+		return nil
+	}
+}
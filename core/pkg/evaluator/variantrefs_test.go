@@ -0,0 +1,134 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const variantRefsConfig = `{
+	"flags": {
+		"color": {
+			"state": "ENABLED",
+			"variants": {"red": "red", "blue": "blue"},
+			"defaultVariant": "red",
+			"targeting": {"if": [{"==": [{"var": "tier"}, "premium"]}, "gold", "blue"]}
+		},
+		"shape": {
+			"state": "ENABLED",
+			"variants": {"round": "round"},
+			"defaultVariant": "round"
+		}
+	}
+}`
+
+type variantRefRecorder struct {
+	telemetry.NoopMetricsRecorder
+	kinds []string
+}
+
+func (r *variantRefRecorder) VariantReferenceError(_ context.Context, _, kind string) {
+	r.kinds = append(r.kinds, kind)
+}
+
+func TestUnknownVariantRefs(t *testing.T) {
+	boolVariants := map[string]any{"on": true, "off": false}
+	flags := &Flags{Flags: map[string]model.Flag{
+		"valid": {
+			Variants:       map[string]any{"a": "a", "b": "b", "1": 1.0},
+			DefaultVariant: "a",
+			Targeting:      []byte(`{"if": [{"var": "x"}, "b", {"if": [{"var": "y"}, 1, null]}]}`),
+		},
+		"missingDefault": {
+			Variants:       map[string]any{"a": "a"},
+			DefaultVariant: "b",
+		},
+		"missingTargeting": {
+			Variants:       map[string]any{"a": "a"},
+			DefaultVariant: "a",
+			Targeting:      []byte(`{"fractional": [["a", 50], ["c", 50]]}`),
+		},
+		"repeatedVariant": {
+			Variants:       map[string]any{"a": "a"},
+			DefaultVariant: "a",
+			Targeting:      []byte(`{"if": [{"var": "x"}, "c", "c"]}`),
+		},
+		"contextVariant": {
+			Variants:       map[string]any{"a": "a"},
+			DefaultVariant: "a",
+			Targeting:      []byte(`{"var": "variant"}`),
+		},
+		"booleanResult": {
+			Variants:       boolVariants,
+			DefaultVariant: "off",
+			Targeting:      []byte(`{"if": [{"var": "x"}, true, false]}`),
+		},
+	}}
+
+	assert.Equal(t, []variantRef{
+		{flag: "missingDefault", variant: "b", kind: telemetry.VariantRefDefault},
+		{flag: "missingTargeting", variant: "c", kind: telemetry.VariantRefTargeting},
+		{flag: "repeatedVariant", variant: "c", kind: telemetry.VariantRefTargeting},
+	}, unknownVariantRefs(flags))
+}
+
+func TestVariantRefRejection(t *testing.T) {
+	tests := map[string]struct {
+		mode          string
+		err           bool
+		colorDefined  bool
+		unknownErrors int
+	}{
+		"logged by default": {colorDefined: true, unknownErrors: 1},
+		"flag rejected":     {mode: VariantRefRejectFlag, unknownErrors: 1},
+		"config rejected":   {mode: VariantRefRejectConfig, err: true, unknownErrors: 1},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &variantRefRecorder{}
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder),
+				WithVariantRefRejection(tt.mode))
+
+			_, _, err := evaluator.SetState(sync.DataSync{FlagData: variantRefsConfig,
+				Source: "testSource", Type: sync.ALL})
+			assert.Len(t, recorder.kinds, tt.unknownErrors)
+			if tt.err {
+				require.ErrorIs(t, err, ErrUnknownVariant)
+				assert.Contains(t, err.Error(), "targeting of flag: 'color' resolves to 'gold'")
+				return
+			}
+			require.NoError(t, err)
+
+			_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "req", "shape", nil)
+			require.NoError(t, err, "flags without unknown variants are applied")
+			_, _, _, _, err = evaluator.ResolveStringValue(context.Background(), "req", "color", nil)
+			if tt.colorDefined {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, model.FlagNotFoundErrorCode)
+			}
+		})
+	}
+}
+
+func TestUnknownDefaultVariantRejectsConfig(t *testing.T) {
+	recorder := &variantRefRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithMetricsRecorder(recorder))
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"color": {"state": "ENABLED", "variants": {"red": "red"}, "defaultVariant": "blue"}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.ErrorIs(t, err, ErrUnknownVariant)
+	assert.Contains(t, err.Error(), "default variant: 'blue' isn't a valid variant of flag: 'color'")
+	assert.Equal(t, []string{telemetry.VariantRefDefault}, recorder.kinds)
+}
@@ -0,0 +1,128 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	gosync "sync"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"go.uber.org/zap"
+)
+
+// MonotonicVersionMetadataKey is the flag set metadata key holding the version of a configuration, a non-negative
+// integer which increases with each change of the configuration of its source
+const MonotonicVersionMetadataKey = "monotonicVersion"
+
+// ErrVersionRegression is returned for configurations with an older monotonic version than the applied configuration
+// of their source, if version regressions are rejected
+var ErrVersionRegression = errors.New("configuration version regression")
+
+// WithRejectVersionRegressions rejects configurations whose monotonic version is older than the version of the applied
+// configuration of their source, e.g. replayed or reordered updates, keeping the applied configuration. By default,
+// such configurations are applied with a warning. Either way, regressions are counted by the version regression metric.
+func WithRejectVersionRegressions() JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.rejectVersionRegressions = true
+	}
+}
+
+// sourceVersions holds the monotonic versions of the applied configurations of the sources
+type sourceVersions struct {
+	mu      gosync.Mutex
+	applied map[string]uint64
+}
+
+func (v *sourceVersions) get(source string) (uint64, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	version, ok := v.applied[source]
+	return version, ok
+}
+
+func (v *sourceVersions) set(source string, version uint64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.applied == nil {
+		v.applied = map[string]uint64{}
+	}
+	v.applied[source] = version
+}
+
+// monotonicVersion returns the most recent monotonic version found in the metadata of the given flags. Note that the
+// flag set metadata is part of the metadata of each flag.
+func monotonicVersion(log *logger.Logger, flags map[string]model.Flag) (uint64, bool) {
+	var latest uint64
+	found := false
+	for key, flag := range flags {
+		raw, ok := flag.Metadata[MonotonicVersionMetadataKey]
+		if !ok {
+			continue
+		}
+		version, err := parseMonotonicVersion(raw)
+		if err != nil {
+			log.Warn(fmt.Sprintf("ignoring invalid %s metadata of flag %s: %v", MonotonicVersionMetadataKey, key,
+				err))
+			continue
+		}
+		if !found || version > latest {
+			latest = version
+			found = true
+		}
+	}
+	return latest, found
+}
+
+func parseMonotonicVersion(raw interface{}) (uint64, error) {
+	var value float64
+	switch v := raw.(type) {
+	case float64:
+		value = v
+	case json.Number:
+		if version, err := v.Int64(); err == nil && version >= 0 {
+			return uint64(version), nil
+		}
+		return 0, fmt.Errorf("expected a non-negative integer but got %s", v)
+	default:
+		return 0, fmt.Errorf("expected a non-negative integer but got %v", raw)
+	}
+	if value < 0 || value != math.Trunc(value) || value > math.MaxInt64 {
+		return 0, fmt.Errorf("expected a non-negative integer but got %v", raw)
+	}
+	return uint64(value), nil
+}
+
+// checkVersionRegression logs and counts configurations with an older monotonic version than the applied configuration
+// of their source, and rejects them if configured. The version of the configuration is returned, if it has one.
+func (je *JSON) checkVersionRegression(ctx context.Context, payload sync.DataSync, flags *Flags) (uint64, bool, error) {
+	if payload.Type == sync.DELETE {
+		// deletions only refer to the keys of the flags
+		return 0, false, nil
+	}
+	version, ok := monotonicVersion(je.Logger, flags.Flags)
+	if !ok {
+		return 0, false, nil
+	}
+	applied, ok := je.versions.get(payload.Source)
+	if !ok || version >= applied {
+		return version, true, nil
+	}
+
+	telemetry.ConfigMetrics(je.metrics).ConfigVersionRegression(ctx, payload.Source)
+	if je.rejectVersionRegressions {
+		return 0, false, fmt.Errorf("%w: version %d of source %s is older than the applied version %d",
+			ErrVersionRegression, version, payload.Source, applied)
+	}
+	je.Logger.Warn(fmt.Sprintf("applying version %d of source %s, which is older than the applied version %d",
+		version, payload.Source, applied),
+		zap.String("source", payload.Source),
+		zap.Uint64("version", version),
+		zap.Uint64("appliedVersion", applied),
+	)
+	return version, true, nil
+}
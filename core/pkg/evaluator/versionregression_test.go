@@ -0,0 +1,123 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionRegressionRecorder struct {
+	telemetry.NoopMetricsRecorder
+	sources []string
+}
+
+func (r *versionRegressionRecorder) ConfigVersionRegression(_ context.Context, source string) {
+	r.sources = append(r.sources, source)
+}
+
+func versionedConfig(version int, variant string) string {
+	return fmt.Sprintf(`{
+		"metadata": {"monotonicVersion": %d},
+		"flags": {
+			"greeting": {
+				"state": "ENABLED",
+				"variants": {"english": "hello", "german": "hallo"},
+				"defaultVariant": "%s"
+			}
+		}
+	}`, version, variant)
+}
+
+func TestVersionRegression(t *testing.T) {
+	tests := map[string]struct {
+		options []JSONEvaluatorOption
+		err     bool
+		value   string
+	}{
+		"older versions are applied by default": {value: "hello"},
+		"older versions are rejected": {
+			options: []JSONEvaluatorOption{WithRejectVersionRegressions()}, err: true, value: "hallo",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := &versionRegressionRecorder{}
+			evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(),
+				append(tt.options, WithMetricsRecorder(recorder))...)
+			setState := func(source string, version int, variant string) error {
+				_, _, err := evaluator.SetState(sync.DataSync{
+					FlagData: versionedConfig(version, variant), Source: source, Type: sync.ALL})
+				return err
+			}
+
+			require.NoError(t, setState("testSource", 2, "english"))
+			require.NoError(t, setState("testSource", 3, "german"))
+			require.NoError(t, setState("testSource", 3, "german"), "equal versions aren't regressions")
+			require.NoError(t, setState("otherSource", 1, "german"), "versions are compared per source")
+			assert.Empty(t, recorder.sources)
+
+			err := setState("testSource", 2, "english")
+			if tt.err {
+				require.ErrorIs(t, err, ErrVersionRegression)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, []string{"testSource"}, recorder.sources)
+
+			value, _, _, _, err := evaluator.ResolveStringValue(context.Background(), "req", "greeting", nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, value)
+		})
+	}
+}
+
+func TestVersionRegressionWithoutVersion(t *testing.T) {
+	recorder := &versionRegressionRecorder{}
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithRejectVersionRegressions(),
+		WithMetricsRecorder(recorder))
+
+	for _, config := range []string{
+		versionedConfig(5, "english"),
+		`{"flags": {"greeting": {"state": "ENABLED", "variants": {"english": "hello"}, "defaultVariant": "english"}}}`,
+		`{"metadata": {"monotonicVersion": "1"}, "flags": {"greeting": {"state": "ENABLED",
+			"variants": {"english": "hello"}, "defaultVariant": "english"}}}`,
+		`{"metadata": {"monotonicVersion": -1}, "flags": {"greeting": {"state": "ENABLED",
+			"variants": {"english": "hello"}, "defaultVariant": "english"}}}`,
+	} {
+		_, _, err := evaluator.SetState(sync.DataSync{FlagData: config, Source: "testSource",
+			Type: sync.ALL})
+		require.NoError(t, err, "configurations without a valid version aren't compared")
+	}
+	assert.Empty(t, recorder.sources)
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: versionedConfig(4, "english"),
+		Source: "testSource", Type: sync.ALL})
+	require.ErrorIs(t, err, ErrVersionRegression, "the last valid version is kept")
+}
+
+func TestMonotonicVersion(t *testing.T) {
+	evaluator := NewJSON(logger.NewLogger(nil, false), store.NewFlags(), WithJSONNumbers(),
+		WithRejectVersionRegressions())
+
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"a": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on",
+				"metadata": {"monotonicVersion": 7}},
+			"b": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on",
+				"metadata": {"monotonicVersion": 9}}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: versionedConfig(8, "english"),
+		Source: "testSource", Type: sync.ALL})
+	require.ErrorIs(t, err, ErrVersionRegression, "the most recent version of the flags is the configuration version")
+}
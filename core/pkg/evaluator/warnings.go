@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"go.uber.org/zap"
+)
+
+// deprecatedOperators maps the deprecated operators of targeting rules to their replacement
+var deprecatedOperators = map[string]string{
+	LegacyFractionEvaluationName: FractionEvaluationName,
+}
+
+// configWarning is a non-fatal issue of a flag of a configuration
+type configWarning struct {
+	flag     string
+	category string
+	message  string
+}
+
+// configWarnings returns the non-fatal issues of the flags of a configuration, ordered by flag key:
+//   - targeting rules using deprecated operators (telemetry.ConfigDeprecatedOperator)
+//   - empty targeting rules, which are ignored (telemetry.ConfigEmptyTargeting)
+//   - variants no statically known result of the targeting selects (telemetry.ConfigUnreachableVariant). Flags
+//     without targeting, or whose targeting may select a variant computed from the evaluation context, are skipped.
+func configWarnings(flags *Flags) []configWarning {
+	keys := make([]string, 0, len(flags.Flags))
+	for key := range flags.Flags {
+		keys = append(keys, key)
+	}
+	// sorted for deterministic warnings
+	sort.Strings(keys)
+
+	var warnings []configWarning
+	for _, key := range keys {
+		flag := flags.Flags[key]
+		trimmed := bytes.TrimSpace(flag.Targeting)
+		if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			continue
+		}
+		if bytes.Equal(trimmed, []byte("{}")) {
+			warnings = append(warnings, configWarning{
+				flag:     key,
+				category: telemetry.ConfigEmptyTargeting,
+				message:  "targeting is empty and ignored, remove it to serve the default variant statically",
+			})
+			continue
+		}
+
+		var rule any
+		if err := json.Unmarshal(flag.Targeting, &rule); err != nil {
+			// parsing errors are reported at evaluation time
+			continue
+		}
+
+		for _, operator := range usedDeprecatedOperators(rule) {
+			warnings = append(warnings, configWarning{
+				flag:     key,
+				category: telemetry.ConfigDeprecatedOperator,
+				message: fmt.Sprintf("targeting uses the deprecated %s operator, use %s instead", operator,
+					deprecatedOperators[operator]),
+			})
+		}
+
+		for _, variant := range unreachableVariants(flag, rule) {
+			warnings = append(warnings, configWarning{
+				flag:     key,
+				category: telemetry.ConfigUnreachableVariant,
+				message:  fmt.Sprintf("variant %s is neither the default variant nor selected by the targeting", variant),
+			})
+		}
+	}
+	return warnings
+}
+
+// recordConfigWarnings logs and counts the non-fatal issues of a configuration applied from a source
+func (je *JSON) recordConfigWarnings(ctx context.Context, source string, warnings []configWarning) {
+	for _, warning := range warnings {
+		je.Logger.Warn(fmt.Sprintf("flag %s: %s", warning.flag, warning.message),
+			zap.String("flag", warning.flag),
+			zap.String("category", warning.category),
+			zap.String("source", source),
+		)
+		telemetry.ConfigMetrics(je.metrics).ConfigWarning(ctx, source, warning.category)
+	}
+}
+
+// usedDeprecatedOperators returns the deprecated operators of a targeting rule, sorted and without duplicates
+func usedDeprecatedOperators(rule any) []string {
+	used := map[string]struct{}{}
+	var walk func(rule any)
+	walk = func(rule any) {
+		switch r := rule.(type) {
+		case []any:
+			for _, item := range r {
+				walk(item)
+			}
+		case map[string]any:
+			for operator, args := range r {
+				if _, ok := deprecatedOperators[operator]; ok {
+					used[operator] = struct{}{}
+				}
+				walk(args)
+			}
+		}
+	}
+	walk(rule)
+
+	operators := make([]string, 0, len(used))
+	for operator := range used {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+	return operators
+}
+
+// unreachableVariants returns the sorted variants of a flag which are neither the default variant nor a statically
+// known result of its targeting. No variants are returned if a result of the targeting isn't statically known.
+func unreachableVariants(flag model.Flag, rule any) []string {
+	reachable := map[string]struct{}{flag.DefaultVariant: {}}
+	reach := func(variant string) {
+		reachable[variant] = struct{}{}
+	}
+
+	for _, result := range targetingResults(rule) {
+		switch r := result.(type) {
+		case nil:
+			// null resolves to the default variant
+		case string:
+			reach(r)
+		case bool:
+			reach(strconv.FormatBool(r))
+			if variant, ok := booleanVariant(flag, r); ok {
+				reach(variant)
+			}
+		case float64:
+			reach(strconv.FormatFloat(r, 'f', -1, 64))
+		case resultKind:
+			if r != booleanResult {
+				return nil
+			}
+			for _, value := range []bool{true, false} {
+				reach(strconv.FormatBool(value))
+				if variant, ok := booleanVariant(flag, value); ok {
+					reach(variant)
+				}
+			}
+		default:
+			return nil
+		}
+	}
+
+	var unreachable []string
+	for variant := range flag.Variants {
+		if _, ok := reachable[variant]; !ok {
+			unreachable = append(unreachable, variant)
+		}
+	}
+	sort.Strings(unreachable)
+	return unreachable
+}
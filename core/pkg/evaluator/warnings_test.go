@@ -0,0 +1,120 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type warningRecorder struct {
+	telemetry.NoopMetricsRecorder
+	warnings [][2]string
+}
+
+func (r *warningRecorder) ConfigWarning(_ context.Context, source, category string) {
+	r.warnings = append(r.warnings, [2]string{source, category})
+}
+
+func TestConfigWarnings(t *testing.T) {
+	variants := map[string]any{"red": "red", "blue": "blue", "green": "green"}
+	flag := func(targeting string) model.Flag {
+		return model.Flag{DefaultVariant: "red", Variants: variants, Targeting: []byte(targeting)}
+	}
+
+	tests := map[string]struct {
+		flag     model.Flag
+		expected []configWarning
+	}{
+		"without targeting": {
+			flag: flag(""),
+		},
+		"all variants reachable": {
+			flag: flag(`{"if": [{"==": [{"var": "tier"}, "premium"]}, "blue", {"fractional": [["green", 50], ["red", 50]]}]}`),
+		},
+		"variant computed from the context": {
+			flag: flag(`{"if": [{"var": "beta"}, {"var": "color"}, null]}`),
+		},
+		"empty targeting": {
+			flag: flag(`{}`),
+			expected: []configWarning{{flag: "flag", category: telemetry.ConfigEmptyTargeting,
+				message: "targeting is empty and ignored, remove it to serve the default variant statically"}},
+		},
+		"deprecated operator": {
+			flag: flag(`{"fractionalEvaluation": ["email", ["red", 50], ["blue", 25], ["green", 25]]}`),
+			expected: []configWarning{{flag: "flag", category: telemetry.ConfigDeprecatedOperator,
+				message: "targeting uses the deprecated fractionalEvaluation operator, use fractional instead"}},
+		},
+		"unreachable variants": {
+			flag: flag(`{"if": [{"==": [{"var": "tier"}, "premium"]}, "blue", null]}`),
+			expected: []configWarning{{flag: "flag", category: telemetry.ConfigUnreachableVariant,
+				message: "variant green is neither the default variant nor selected by the targeting"}},
+		},
+		"boolean variants": {
+			flag: model.Flag{
+				DefaultVariant: "off",
+				Variants:       map[string]any{"on": true, "off": false, "legacy": "legacy"},
+				Targeting:      []byte(`{"==": [{"var": "tier"}, "premium"]}`),
+			},
+			expected: []configWarning{{flag: "flag", category: telemetry.ConfigUnreachableVariant,
+				message: "variant legacy is neither the default variant nor selected by the targeting"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, configWarnings(&Flags{Flags: map[string]model.Flag{"flag": tt.flag}}))
+		})
+	}
+}
+
+func TestRecordConfigWarnings(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	recorder := &warningRecorder{}
+	evaluator := NewJSON(logger.NewLogger(zap.New(core), false), store.NewFlags(), WithMetricsRecorder(recorder))
+
+	config := `{
+		"flags": {
+			"empty": {
+				"state": "ENABLED",
+				"variants": {"on": true, "off": false},
+				"defaultVariant": "off",
+				"targeting": {}
+			},
+			"unreachable": {
+				"state": "ENABLED",
+				"variants": {"red": "red", "blue": "blue", "green": "green"},
+				"defaultVariant": "red",
+				"targeting": {"if": [{"==": [{"var": "tier"}, "premium"]}, "blue", null]}
+			}
+		}
+	}`
+	_, _, err := evaluator.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: config})
+	require.NoError(t, err)
+
+	assert.Equal(t, [][2]string{
+		{"file", telemetry.ConfigEmptyTargeting},
+		{"file", telemetry.ConfigUnreachableVariant},
+	}, recorder.warnings)
+
+	entries := logs.FilterField(zap.String("category", telemetry.ConfigUnreachableVariant)).All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "flag unreachable: variant green is neither the default variant nor selected by the targeting",
+		entries[0].Message)
+	assert.Equal(t, "unreachable", entries[0].ContextMap()["flag"])
+	assert.Equal(t, "file", entries[0].ContextMap()["source"])
+
+	// deleted flags are not reported
+	_, _, err = evaluator.SetState(sync.DataSync{Source: "file", Type: sync.DELETE, FlagData: config})
+	require.NoError(t, err)
+	assert.Len(t, recorder.warnings, 2)
+}
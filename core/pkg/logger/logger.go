@@ -42,6 +42,10 @@ type Logger struct {
 	Logger        *zap.Logger
 	fields        []zap.Field
 	reqIDLogging  bool
+	// subsystem is the subsystem of the logger, retained by its child loggers
+	subsystem string
+	// levels holds the levels of subsystems, nil if these log at the level of the logger
+	levels *levels
 }
 
 func (l *Logger) DebugWithID(reqID string, msg string, fields ...zap.Field) {
@@ -216,10 +220,15 @@ func NewLogger(logger *zap.Logger, reqIDLogging bool) *Logger {
 // These fields will be added to each request, but the logger will still
 // read/write from the highest level logging wrappers field pool
 func (l *Logger) WithFields(fields ...zap.Field) *Logger {
+	if l.subsystem != "" {
+		fields = append([]zap.Field{zap.String(SubsystemFieldName, l.subsystem)}, fields...)
+	}
 	return &Logger{
 		Logger:        l.Logger,
 		requestFields: l.requestFields,
 		fields:        fields,
 		reqIDLogging:  l.reqIDLogging,
+		subsystem:     l.subsystem,
+		levels:        l.levels,
 	}
 }
@@ -2,10 +2,12 @@ package logger
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestFieldStorageAndRetrieval(t *testing.T) {
@@ -83,3 +85,60 @@ func TestLoggerChildOperation(t *testing.T) {
 		t.Error("field 2 is present in the parent logger getFieldsForLog response")
 	}
 }
+
+func TestSubsystemLevels(t *testing.T) {
+	levels, err := ParseSubsystemLevels(map[string]string{SubsystemSync: "warn", SubsystemEvaluation: "debug"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	core, logs := observer.New(MinLevel(zapcore.InfoLevel, levels))
+	l := NewLogger(zap.New(core), true).WithSubsystemLevels(zapcore.InfoLevel, levels)
+
+	sync := l.WithSubsystem(SubsystemSync, zap.String("component", "sync"))
+	evaluation := l.WithSubsystem(SubsystemEvaluation)
+	service := l.WithSubsystem(SubsystemService).WithFields(zap.String("component", "service"))
+
+	l.Debug("root debug")
+	l.Info("root info")
+	sync.Info("sync info")
+	sync.Warn("sync warn")
+	evaluation.Debug("evaluation debug")
+	service.Debug("service debug")
+	service.Info("service info")
+
+	want := map[string]string{
+		"root info":        "",
+		"sync warn":        SubsystemSync,
+		"evaluation debug": SubsystemEvaluation,
+		"service info":     SubsystemService,
+	}
+	got := map[string]string{}
+	for _, entry := range logs.All() {
+		got[entry.Message], _ = entry.ContextMap()[SubsystemFieldName].(string)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expected logs %v, got %v", want, got)
+	}
+}
+
+func TestParseSubsystemLevels(t *testing.T) {
+	tests := map[string]struct {
+		levels map[string]string
+		err    string
+	}{
+		"valid levels":      {levels: map[string]string{SubsystemSync: "warn", SubsystemService: "error"}},
+		"unknown subsystem": {levels: map[string]string{"store": "warn"}, err: "unknown subsystem 'store'"},
+		"unknown level":     {levels: map[string]string{SubsystemSync: "loud"}, err: "invalid log level of subsystem"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSubsystemLevels(test.levels)
+			if test.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SubsystemFieldName is the field holding the subsystem of logs of subsystem loggers
+const SubsystemFieldName = "subsystem"
+
+const (
+	// SubsystemSync logs the retrieval of flag configurations from the sync sources
+	SubsystemSync = "sync"
+	// SubsystemEvaluation logs the evaluation of flags
+	SubsystemEvaluation = "evaluation"
+	// SubsystemService logs the serving of requests by the evaluation, OFREP and sync services
+	SubsystemService = "service"
+)
+
+// Subsystems are the subsystems whose level can be overridden
+var Subsystems = []string{SubsystemSync, SubsystemEvaluation, SubsystemService}
+
+// levels holds the level of a logger and the overridden levels of its subsystems, which log through the unleveled
+// logger enabled at all of the levels
+type levels struct {
+	level      zapcore.Level
+	subsystems map[string]zapcore.Level
+	unleveled  *zap.Logger
+}
+
+// ParseSubsystemLevels parses the levels of subsystems, e.g. {"sync": "warn"}, failing for unknown subsystems or levels
+func ParseSubsystemLevels(subsystemLevels map[string]string) (map[string]zapcore.Level, error) {
+	levels := make(map[string]zapcore.Level, len(subsystemLevels))
+	for subsystem, text := range subsystemLevels {
+		if !slices.Contains(Subsystems, subsystem) {
+			return nil, fmt.Errorf("unknown subsystem '%s', must be one of %s", subsystem,
+				strings.Join(Subsystems, ", "))
+		}
+		level, err := zapcore.ParseLevel(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level of subsystem '%s': %w", subsystem, err)
+		}
+		levels[subsystem] = level
+	}
+	return levels, nil
+}
+
+// MinLevel returns the lowest of the given level and the levels of the subsystems, the level the *zap.Logger of
+// WithSubsystemLevels must be enabled at
+func MinLevel(level zapcore.Level, subsystemLevels map[string]zapcore.Level) zapcore.Level {
+	for _, subsystemLevel := range subsystemLevels {
+		level = min(level, subsystemLevel)
+	}
+	return level
+}
+
+// WithSubsystemLevels creates a new logging wrapper logging at the given level, whose subsystem loggers log at the
+// given levels of their subsystem instead. The wrapped *zap.Logger must be enabled at the MinLevel of the levels.
+func (l *Logger) WithSubsystemLevels(level zapcore.Level, subsystemLevels map[string]zapcore.Level) *Logger {
+	child := *l
+	child.levels = &levels{level: level, subsystems: subsystemLevels, unleveled: l.Logger}
+	child.Logger = leveled(l.Logger, level)
+	return &child
+}
+
+// WithSubsystem creates a new logging wrapper of the given subsystem with a predefined base set of fields, as
+// WithFields does. Logs carry the subsystem as SubsystemFieldName field and honor the level of the subsystem.
+func (l *Logger) WithSubsystem(subsystem string, fields ...zap.Field) *Logger {
+	child := *l
+	child.subsystem = subsystem
+	child.fields = append([]zap.Field{zap.String(SubsystemFieldName, subsystem)}, fields...)
+	if l.levels != nil {
+		level, ok := l.levels.subsystems[subsystem]
+		if !ok {
+			level = l.levels.level
+		}
+		child.Logger = leveled(l.levels.unleveled, level)
+	}
+	return &child
+}
+
+// leveled returns the logger logging at the given level, which must be enabled by the logger
+func leveled(logger *zap.Logger, level zapcore.Level) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return levelCore{Core: core, level: level}
+	}))
+}
+
+// levelCore overrides the level of the wrapped core
+type levelCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+func (c levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
@@ -10,6 +10,12 @@ type Flag struct {
 	Source         string                 `json:"source"`
 	Selector       string                 `json:"selector"`
 	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	// Parent is the key of the flag whose variants the flag inherits, empty if the flag declares all of its variants
+	Parent string `json:"parent,omitempty"`
+	// CompiledTargeting is the targeting as applied by the evaluator, with the declarations referenced by its
+	// operations resolved. It is derived when the flag is loaded and never serialized, so that the targeting is served
+	// as written. Empty if the targeting is applied as written.
+	CompiledTargeting json.RawMessage `json:"-"`
 }
 
 type Evaluators struct {
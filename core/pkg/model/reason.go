@@ -10,4 +10,7 @@ const (
 	UnknownReason        = "UNKNOWN"
 	ErrorReason          = "ERROR"
 	StaticReason         = "STATIC"
+	// RegionDefaultReason is the reason of evaluations served the region default of a flag instead of its default
+	// variant
+	RegionDefaultReason = "REGION_DEFAULT"
 )
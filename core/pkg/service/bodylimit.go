@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// BodyLimits are the maximum sizes in bytes of the request bodies of each class of endpoints, zero doesn't limit the
+// size
+type BodyLimits struct {
+	// Evaluation limits the requests evaluating a single flag
+	Evaluation int64
+	// Bulk limits the requests evaluating all flags, or a flag against many contexts
+	Bulk int64
+	// Admin limits the requests of the admin endpoints
+	Admin int64
+}
+
+// LimitBody limits the request body of the handler to the maximum size in bytes. Requests announcing a larger body are
+// answered with 413 right away, reading a larger body without announced length fails with an http.MaxBytesError, see
+// IsBodyTooLarge. Zero doesn't limit the size.
+func LimitBody(next http.Handler, maxBytes int64) http.Handler {
+	if maxBytes <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBytes {
+			http.Error(w, fmt.Sprintf("request body of %d bytes exceeds the maximum of %d bytes", r.ContentLength,
+				maxBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsBodyTooLarge reports whether reading a request body failed as the body exceeds the limit of LimitBody
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// BodyErrorStatus is the status answering a request whose body can't be read or is invalid, 413 if the body exceeds
+// the limit of LimitBody and 400 otherwise
+func BodyErrorStatus(err error) int {
+	if IsBodyTooLarge(err) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
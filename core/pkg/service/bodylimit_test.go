@@ -0,0 +1,61 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitBody(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(BodyErrorStatus(err))
+			return
+		}
+		_, _ = w.Write(body)
+	})
+
+	tests := map[string]struct {
+		maxBytes int64
+		body     string
+		chunked  bool
+		status   int
+	}{
+		"within the limit":           {maxBytes: 8, body: "12345678", status: http.StatusOK},
+		"announced larger body":      {maxBytes: 8, body: "123456789", status: http.StatusRequestEntityTooLarge},
+		"larger body without length": {maxBytes: 8, body: "123456789", chunked: true, status: http.StatusRequestEntityTooLarge},
+		"unlimited":                  {body: strings.Repeat("1", 1024), status: http.StatusOK},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var body io.Reader = bytes.NewBufferString(tt.body)
+			if tt.chunked {
+				// hides the length of the body from the request
+				body = io.MultiReader(body)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			recorder := httptest.NewRecorder()
+
+			LimitBody(echo, tt.maxBytes).ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.status, recorder.Code)
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.body, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestBodyErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusRequestEntityTooLarge, BodyErrorStatus(&http.MaxBytesError{Limit: 8}))
+	assert.Equal(t, http.StatusBadRequest, BodyErrorStatus(errors.New("invalid JSON")))
+	assert.Equal(t, http.StatusBadRequest, BodyErrorStatus(nil))
+}
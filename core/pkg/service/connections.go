@@ -0,0 +1,87 @@
+//nolint:wrapcheck
+package service
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// ConnectionLimiter is a listener capping the number of concurrently accepted connections. Once the limit is reached,
+// Accept blocks until an accepted connection is closed, so that further connections queue in the backlog of the
+// listener, and are refused by the operating system once the backlog is full, instead of exhausting file descriptors.
+type ConnectionLimiter struct {
+	net.Listener
+	// slots holds a token per accepted connection, nil doesn't limit the number of connections
+	slots     chan struct{}
+	open      atomic.Int64
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewConnectionLimiter wraps the given listener, a limit of zero or less doesn't limit the number of connections
+func NewConnectionLimiter(lis net.Listener, limit int) *ConnectionLimiter {
+	l := &ConnectionLimiter{
+		Listener: lis,
+		done:     make(chan struct{}),
+	}
+	if limit > 0 {
+		l.slots = make(chan struct{}, limit)
+	}
+	return l
+}
+
+// Accept waits for a free slot and then for the next connection of the listener
+func (l *ConnectionLimiter) Accept() (net.Conn, error) {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-l.done:
+			return nil, net.ErrClosed
+		}
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		l.release()
+		return nil, err
+	}
+	l.open.Add(1)
+	return &limitedConn{Conn: conn, limiter: l}, nil
+}
+
+// Close closes the listener, unblocking Accept calls waiting for a free slot
+func (l *ConnectionLimiter) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+	})
+	return l.Listener.Close()
+}
+
+// Open returns the number of currently accepted connections
+func (l *ConnectionLimiter) Open() int64 {
+	return l.open.Load()
+}
+
+// release frees the slot of a connection
+func (l *ConnectionLimiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// limitedConn frees its slot of the ConnectionLimiter once it is closed
+type limitedConn struct {
+	net.Conn
+	limiter   *ConnectionLimiter
+	closeOnce sync.Once
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		c.limiter.open.Add(-1)
+		c.limiter.release()
+	})
+	return err
+}
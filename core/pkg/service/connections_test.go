@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionLimiter(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	limiter := NewConnectionLimiter(lis, 1)
+	defer limiter.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := limiter.Accept()
+			if err != nil {
+				close(accepted)
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	clientA, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer clientA.Close()
+	clientB, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err, "connections beyond the limit queue in the backlog")
+	defer clientB.Close()
+
+	connA := <-accepted
+	assert.Equal(t, int64(1), limiter.Open())
+	select {
+	case <-accepted:
+		t.Fatal("a connection beyond the limit was accepted")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// closing twice only frees one slot
+	require.NoError(t, connA.Close())
+	_ = connA.Close()
+	select {
+	case connB := <-accepted:
+		assert.Equal(t, int64(1), limiter.Open())
+		require.NoError(t, connB.Close())
+	case <-time.After(time.Second):
+		t.Fatal("the queued connection wasn't accepted once a slot was freed")
+	}
+	assert.Equal(t, int64(0), limiter.Open())
+}
+
+func TestConnectionLimiterClose(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	limiter := NewConnectionLimiter(lis, 1)
+
+	conn, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = limiter.Accept()
+	require.NoError(t, err)
+
+	// the next accept waits for a free slot until the listener is closed
+	done := make(chan error)
+	go func() {
+		_, err := limiter.Accept()
+		done <- err
+	}()
+	require.NoError(t, limiter.Close())
+	select {
+	case err := <-done:
+		assert.True(t, errors.Is(err, net.ErrClosed))
+	case <-time.After(time.Second):
+		t.Fatal("accept wasn't unblocked by closing the listener")
+	}
+}
+
+func TestConnectionLimiterUnlimited(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	limiter := NewConnectionLimiter(lis, 0)
+	defer limiter.Close()
+
+	for range 3 {
+		client, err := net.Dial("tcp", lis.Addr().String())
+		require.NoError(t, err)
+		defer client.Close()
+		_, err = limiter.Accept()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(3), limiter.Open())
+}
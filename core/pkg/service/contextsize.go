@@ -0,0 +1,18 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrContextTooLarge is returned for evaluation contexts exceeding the maximum size in bytes
+var ErrContextTooLarge = errors.New("evaluation context too large")
+
+// CheckContextSize rejects evaluation contexts whose size in bytes exceeds the maximum before they are evaluated,
+// zero doesn't limit the size
+func CheckContextSize(size int, maxBytes int) error {
+	if maxBytes <= 0 || size <= maxBytes {
+		return nil
+	}
+	return fmt.Errorf("%w: %d bytes exceed the maximum of %d bytes", ErrContextTooLarge, size, maxBytes)
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckContextSize(t *testing.T) {
+	require.NoError(t, CheckContextSize(1<<20, 0), "zero doesn't limit the size")
+	require.NoError(t, CheckContextSize(1024, 1024))
+
+	err := CheckContextSize(1025, 1024)
+	require.ErrorIs(t, err, ErrContextTooLarge)
+	require.EqualError(t, err, "evaluation context too large: 1025 bytes exceed the maximum of 1024 bytes")
+}
@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"net/http"
 
 	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/evaluator"
+	"go.uber.org/zap"
 )
 
 type NotificationType string
@@ -15,6 +18,9 @@ const (
 	KeepAlive           NotificationType = "keep_alive"
 )
 
+// CompressionGzip is the compression of evaluation responses negotiated with clients advertising gzip
+const CompressionGzip = "gzip"
+
 type Notification struct {
 	Type NotificationType       `json:"type"`
 	Data map[string]interface{} `json:"data"`
@@ -33,6 +39,45 @@ type Configuration struct {
 	CORS           []string
 	Options        []connect.HandlerOption
 	ContextValues  map[string]any
+	AdminToken     string
+	Timeouts       ServerTimeouts
+	// Authentication wraps the evaluation handlers, rejecting unauthenticated requests
+	Authentication func(http.Handler) http.Handler
+	// PeerContext wraps the evaluation handlers, attaching the attributes of the peer to the request context
+	PeerContext func(http.Handler) http.Handler
+	// MaxStreams caps the number of concurrent event streams, zero doesn't limit them
+	MaxStreams int
+	// MaxConnections caps the number of concurrently accepted connections of the flag evaluation server, further
+	// connections wait until an accepted connection is closed. Zero doesn't limit them.
+	MaxConnections int
+	// MaxContextBytes rejects evaluation requests whose context exceeds the size in bytes, zero doesn't limit the size
+	MaxContextBytes int
+	// BodyLimits limit the request bodies of the evaluation and admin endpoints
+	BodyLimits BodyLimits
+	// Samples holds the captured evaluations exposed on the admin endpoints, nil if capturing is disabled
+	Samples *evaluator.SampleRecorder
+	// History holds the retained flag configurations exposed on the admin endpoints, nil if retention is disabled
+	History *evaluator.ConfigHistory
+	// Debugger selects the flag whose evaluations are logged in detail on the admin endpoints, nil if disabled
+	Debugger *evaluator.FlagDebugger
+	// LastEvaluated tracks the time each flag was last evaluated at, exposed on the admin endpoints, nil if disabled
+	LastEvaluated *evaluator.LastEvaluated
+	// AdminAudit receives an audit log entry for every call of an admin endpoint, nil writes them to the service log
+	AdminAudit *zap.Logger
+	// ConfigVersionHeader names the response header returning the ConfigVersion, empty if the version is not returned
+	ConfigVersionHeader string
+	ConfigVersion       func() string
+	// ManagementAddress is the host the management server, serving the metrics and probes, binds to. Empty binds to
+	// all interfaces.
+	ManagementAddress string
+	// ManagementCertPath and ManagementKeyPath enable TLS on the management server, independently of the evaluation
+	// server
+	ManagementCertPath string
+	ManagementKeyPath  string
+	// Compression is the compression of evaluation responses, e.g. CompressionGzip, empty doesn't compress responses
+	Compression string
+	// MetricsFormat is the exposition format of the metrics endpoint, e.g. telemetry.MetricsFormatOpenMetrics
+	MetricsFormat string
 }
 
 /*
@@ -1,7 +1,9 @@
 package ofrep
 
 import (
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/model"
@@ -20,7 +22,19 @@ type EvaluationSuccess struct {
 }
 
 type BulkEvaluationResponse struct {
-	Flags []interface{} `json:"flags"`
+	Flags    []interface{}          `json:"flags"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// BatchRequest is the request of a batch evaluation, evaluating a single flag against each of the contexts
+type BatchRequest struct {
+	Contexts []json.RawMessage `json:"contexts"`
+}
+
+// BatchEvaluationResponse holds the results of a batch evaluation in the order of the contexts of the request, each
+// result is either an EvaluationSuccess or an EvaluationError
+type BatchEvaluationResponse struct {
+	Results []interface{} `json:"results"`
 }
 
 type EvaluationError struct {
@@ -38,21 +52,78 @@ type InternalError struct {
 	ErrorDetails string `json:"errorDetails"`
 }
 
-func BulkEvaluationResponseFrom(values []evaluator.AnyValue) BulkEvaluationResponse {
+// ConfigurationResponse describes the capabilities of the provider to OFREP clients
+type ConfigurationResponse struct {
+	Name         string       `json:"name"`
+	Capabilities Capabilities `json:"capabilities"`
+}
+
+type Capabilities struct {
+	CacheInvalidation CacheInvalidation `json:"cacheInvalidation"`
+	FlagEvaluation    FlagEvaluation    `json:"flagEvaluation"`
+}
+
+type CacheInvalidation struct {
+	Polling Polling `json:"polling"`
+}
+
+// Polling describes the polling of the bulk evaluation, a zero MinPollingIntervalMs doesn't limit the polling
+type Polling struct {
+	Enabled              bool  `json:"enabled"`
+	MinPollingIntervalMs int64 `json:"minPollingIntervalMs,omitempty"`
+}
+
+type FlagEvaluation struct {
+	SupportedTypes []string `json:"supportedTypes"`
+}
+
+// supportedTypes are the OFREP flag types flagd evaluates, any variant value is served
+var supportedTypes = []string{"boolean", "string", "int", "float", "object"}
+
+// ConfigurationResponseFrom returns the capabilities of flagd, whose bulk evaluation clients may poll to refresh their
+// cached evaluations no more often than the given interval
+func ConfigurationResponseFrom(minPollingInterval time.Duration) ConfigurationResponse {
+	return ConfigurationResponse{
+		Name: "flagd",
+		Capabilities: Capabilities{
+			CacheInvalidation: CacheInvalidation{
+				Polling: Polling{
+					Enabled:              true,
+					MinPollingIntervalMs: minPollingInterval.Milliseconds(),
+				},
+			},
+			FlagEvaluation: FlagEvaluation{
+				SupportedTypes: supportedTypes,
+			},
+		},
+	}
+}
+
+// BulkEvaluationResponseFrom returns the response of a bulk evaluation, holding the version of the evaluated
+// configuration as metadata unless the version is empty
+func BulkEvaluationResponseFrom(values []evaluator.AnyValue, version string) BulkEvaluationResponse {
 	evaluations := make([]interface{}, 0)
 
 	for _, value := range values {
-		if value.Error != nil {
-			_, evaluationError := EvaluationErrorResponseFrom(value)
-			evaluations = append(evaluations, evaluationError)
-		} else {
-			evaluations = append(evaluations, SuccessResponseFrom(value))
-		}
+		evaluations = append(evaluations, EvaluationResponseFrom(value))
+	}
+
+	response := BulkEvaluationResponse{
+		Flags: evaluations,
 	}
+	if version != "" {
+		response.Metadata = map[string]interface{}{evaluator.ConfigVersionMetadataKey: version}
+	}
+	return response
+}
 
-	return BulkEvaluationResponse{
-		evaluations,
+// EvaluationResponseFrom returns the response of an evaluation, either an EvaluationSuccess or an EvaluationError
+func EvaluationResponseFrom(value evaluator.AnyValue) interface{} {
+	if value.Error != nil {
+		_, evaluationError := EvaluationErrorResponseFrom(value)
+		return evaluationError
 	}
+	return SuccessResponseFrom(value)
 }
 
 func SuccessResponseFrom(result evaluator.AnyValue) EvaluationSuccess {
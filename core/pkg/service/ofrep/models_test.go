@@ -5,6 +5,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/model"
@@ -50,6 +51,7 @@ func TestBulkEvaluationResponse(t *testing.T) {
 	tests := []struct {
 		name             string
 		input            []evaluator.AnyValue
+		version          string
 		marshalledOutput string
 	}{
 		{
@@ -79,11 +81,17 @@ func TestBulkEvaluationResponse(t *testing.T) {
 			},
 			marshalledOutput: "{\"flags\":[{\"value\":false,\"key\":\"key\",\"reason\":\"STATIC\",\"variant\":\"false\",\"metadata\":{\"key\":\"value\"}},{\"key\":\"errorFlag\",\"errorCode\":\"FLAG_NOT_FOUND\",\"errorDetails\":\"flag `errorFlag` does not exist\"}]}",
 		},
+		{
+			name:             "config version",
+			input:            nil,
+			version:          "0a1b2c",
+			marshalledOutput: "{\"flags\":[],\"metadata\":{\"configVersion\":\"0a1b2c\"}}",
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			response := BulkEvaluationResponseFrom(test.input)
+			response := BulkEvaluationResponseFrom(test.input, test.version)
 
 			marshal, err := json.Marshal(response)
 			if err != nil {
@@ -97,6 +105,38 @@ func TestBulkEvaluationResponse(t *testing.T) {
 	}
 }
 
+func TestConfigurationResponse(t *testing.T) {
+	tests := []struct {
+		name               string
+		minPollingInterval time.Duration
+		marshalledOutput   string
+	}{
+		{
+			name:               "unlimited polling",
+			minPollingInterval: 0,
+			marshalledOutput:   "{\"name\":\"flagd\",\"capabilities\":{\"cacheInvalidation\":{\"polling\":{\"enabled\":true}},\"flagEvaluation\":{\"supportedTypes\":[\"boolean\",\"string\",\"int\",\"float\",\"object\"]}}}",
+		},
+		{
+			name:               "minimum polling interval",
+			minPollingInterval: 30 * time.Second,
+			marshalledOutput:   "{\"name\":\"flagd\",\"capabilities\":{\"cacheInvalidation\":{\"polling\":{\"enabled\":true,\"minPollingIntervalMs\":30000}},\"flagEvaluation\":{\"supportedTypes\":[\"boolean\",\"string\",\"int\",\"float\",\"object\"]}}}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			marshal, err := json.Marshal(ConfigurationResponseFrom(test.minPollingInterval))
+			if err != nil {
+				t.Errorf("error marshalling the response: %v", err)
+			}
+
+			if test.marshalledOutput != string(marshal) {
+				t.Errorf("expected %s, got %s", test.marshalledOutput, string(marshal))
+			}
+		})
+	}
+}
+
 func TestErrorStatus(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultReadHeaderTimeout = 3 * time.Second
+	DefaultReadTimeout       = 10 * time.Second
+	DefaultWriteTimeout      = 10 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+)
+
+// ServerTimeouts bound the time a client may take to send a request, receive a response or keep an idle connection
+// open, protecting HTTP servers from slow clients. Zero values are replaced by the defaults, negative values disable
+// the timeout.
+type ServerTimeouts struct {
+	ReadHeader time.Duration
+	Read       time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// NewHTTPServer creates a http.Server with the given timeouts applied
+func NewHTTPServer(addr string, handler http.Handler, timeouts ServerTimeouts) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: timeout(timeouts.ReadHeader, DefaultReadHeaderTimeout),
+		ReadTimeout:       timeout(timeouts.Read, DefaultReadTimeout),
+		WriteTimeout:      timeout(timeouts.Write, DefaultWriteTimeout),
+		IdleTimeout:       timeout(timeouts.Idle, DefaultIdleTimeout),
+	}
+}
+
+func timeout(configured time.Duration, fallback time.Duration) time.Duration {
+	switch {
+	case configured == 0:
+		return fallback
+	case configured < 0:
+		// http.Server disables timeouts with zero values
+		return 0
+	default:
+		return configured
+	}
+}
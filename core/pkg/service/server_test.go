@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+	handler := http.NotFoundHandler()
+
+	tests := map[string]struct {
+		timeouts   ServerTimeouts
+		readHeader time.Duration
+		read       time.Duration
+		write      time.Duration
+		idle       time.Duration
+	}{
+		"defaults": {
+			readHeader: DefaultReadHeaderTimeout,
+			read:       DefaultReadTimeout,
+			write:      DefaultWriteTimeout,
+			idle:       DefaultIdleTimeout,
+		},
+		"configured": {
+			timeouts:   ServerTimeouts{ReadHeader: time.Second, Read: 2 * time.Second, Write: 3 * time.Second, Idle: time.Minute},
+			readHeader: time.Second,
+			read:       2 * time.Second,
+			write:      3 * time.Second,
+			idle:       time.Minute,
+		},
+		"disabled": {
+			timeouts:   ServerTimeouts{Read: -1, Write: -1},
+			readHeader: DefaultReadHeaderTimeout,
+			idle:       DefaultIdleTimeout,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := NewHTTPServer(":8013", handler, tt.timeouts)
+
+			assert.Equal(t, ":8013", server.Addr)
+			assert.NotNil(t, server.Handler)
+			assert.Equal(t, tt.readHeader, server.ReadHeaderTimeout)
+			assert.Equal(t, tt.read, server.ReadTimeout)
+			assert.Equal(t, tt.write, server.WriteTimeout)
+			assert.Equal(t, tt.idle, server.IdleTimeout)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+const (
+	// ServerContextKey is the reserved evaluation context key holding the attributes injected by flagd, e.g.
+	// {"var": "$server.claims.sub"}. Clients can't set it, a ServerContextKey sent by a client is dropped.
+	ServerContextKey = "$server"
+	// ServerValuesKey holds the static context values of the ServerContextKey namespace
+	ServerValuesKey = "values"
+	// ServerClaimsKey holds the verified claims of the ServerContextKey namespace
+	ServerClaimsKey = "claims"
+)
+
+// MergeContexts merges the attributes of the peer, the evaluation context of the client, the verified claims of the
+// request and the static context values into the evaluation context. Later contexts have a higher priority, so that
+// clients can override peer attributes, but not claims.
+//
+// As attributes of the client may still collide with the injected attributes, these are additionally held by the
+// ServerContextKey namespace, which clients can't set: the peer attributes under their own keys, e.g. $server.peer.ip,
+// the claims under ServerClaimsKey and the static context values under ServerValuesKey.
+func MergeContexts(peerContext, clientContext, claims, contextValues map[string]any) map[string]any {
+	merged := make(map[string]any, len(peerContext)+len(clientContext)+len(claims)+len(contextValues)+1)
+	for _, c := range []map[string]any{peerContext, clientContext, claims, contextValues} {
+		for k, v := range c {
+			merged[k] = v
+		}
+	}
+	delete(merged, ServerContextKey)
+
+	server := make(map[string]any, len(peerContext)+2)
+	for k, v := range peerContext {
+		server[k] = v
+	}
+	if len(claims) > 0 {
+		server[ServerClaimsKey] = claims
+	}
+	if len(contextValues) > 0 {
+		server[ServerValuesKey] = contextValues
+	}
+	if len(server) > 0 {
+		merged[ServerContextKey] = server
+	}
+	return merged
+}
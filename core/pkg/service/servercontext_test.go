@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeContexts(t *testing.T) {
+	peer := map[string]any{"peer": map[string]any{"ip": "192.0.2.1"}}
+
+	tests := map[string]struct {
+		peerContext, clientContext, claims, contextValues map[string]any
+		want                                              map[string]any
+	}{
+		"static context values take precedence": {
+			clientContext: map[string]any{"k1": "v1", "k2": "v2"},
+			contextValues: map[string]any{"k2": "v22", "k3": "v3"},
+			want: map[string]any{"k1": "v1", "k2": "v22", "k3": "v3",
+				ServerContextKey: map[string]any{ServerValuesKey: map[string]any{"k2": "v22", "k3": "v3"}}},
+		},
+		"claims take precedence over the client context": {
+			clientContext: map[string]any{"k1": "v1", "k2": "v2", "plan": "enterprise"},
+			claims:        map[string]any{"plan": "free", "sub": "user-1"},
+			contextValues: map[string]any{"k2": "v22"},
+			want: map[string]any{"k1": "v1", "k2": "v22", "plan": "free", "sub": "user-1",
+				ServerContextKey: map[string]any{
+					ServerClaimsKey: map[string]any{"plan": "free", "sub": "user-1"},
+					ServerValuesKey: map[string]any{"k2": "v22"},
+				}},
+		},
+		"the client context takes precedence over peer attributes": {
+			peerContext:   peer,
+			clientContext: map[string]any{"peer": map[string]any{"ip": "198.51.100.1"}},
+			want: map[string]any{"peer": map[string]any{"ip": "198.51.100.1"},
+				ServerContextKey: peer},
+		},
+		"clients can't set the server namespace": {
+			clientContext: map[string]any{"k1": "v1", ServerContextKey: map[string]any{"claims": "forged"}},
+			want:          map[string]any{"k1": "v1"},
+		},
+		"clients can't override the server namespace": {
+			peerContext:   peer,
+			clientContext: map[string]any{ServerContextKey: map[string]any{"peer": map[string]any{"ip": "10.0.0.1"}}},
+			claims:        map[string]any{"sub": "user-1"},
+			want: map[string]any{"peer": peer["peer"], "sub": "user-1",
+				ServerContextKey: map[string]any{
+					"peer":          peer["peer"],
+					ServerClaimsKey: map[string]any{"sub": "user-1"},
+				}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := MergeContexts(tt.peerContext, tt.clientContext, tt.claims, tt.contextValues)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+const (
+	SyncStream  = "sync"
+	EventStream = "event"
+)
+
+// StreamLimiter caps the number of concurrent streams of one type and tracks the open streams with the
+// flagd.streams.open metric
+type StreamLimiter struct {
+	streamType string
+	limit      int64
+	open       atomic.Int64
+	metrics    telemetry.IServiceMetricsRecorder
+}
+
+// NewStreamLimiter creates a StreamLimiter for the given stream type, a limit of zero or less doesn't limit the number of
+// streams
+func NewStreamLimiter(streamType string, limit int, metrics telemetry.IMetricsRecorder) *StreamLimiter {
+	return &StreamLimiter{
+		streamType: streamType,
+		limit:      int64(limit),
+		// a nil recorder records no metrics
+		metrics: telemetry.ServiceMetrics(metrics),
+	}
+}
+
+// Acquire reserves a stream. It returns false if the limit of concurrent streams has been reached, otherwise the
+// returned release function must be called once the stream ends. Calling it more than once has no effect.
+func (l *StreamLimiter) Acquire(ctx context.Context) (func(), bool) {
+	if l.open.Add(1) > l.limit && l.limit > 0 {
+		l.open.Add(-1)
+		l.metrics.StreamRejected(ctx, l.streamType)
+		return nil, false
+	}
+	l.metrics.StreamOpened(ctx, l.streamType)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.open.Add(-1)
+			// the stream context is usually done at this point, the metric must be recorded regardless
+			l.metrics.StreamClosed(context.WithoutCancel(ctx), l.streamType)
+		})
+	}, true
+}
+
+// Open returns the number of currently open streams
+func (l *StreamLimiter) Open() int64 {
+	return l.open.Load()
+}
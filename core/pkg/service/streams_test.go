@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type streamRecorder struct {
+	telemetry.NoopMetricsRecorder
+	open     int
+	rejected int
+}
+
+func (r *streamRecorder) StreamOpened(_ context.Context, _ string) {
+	r.open++
+}
+
+func (r *streamRecorder) StreamClosed(_ context.Context, _ string) {
+	r.open--
+}
+
+func (r *streamRecorder) StreamRejected(_ context.Context, _ string) {
+	r.rejected++
+}
+
+func TestStreamLimiter(t *testing.T) {
+	recorder := &streamRecorder{}
+	limiter := NewStreamLimiter(SyncStream, 2, recorder)
+
+	releaseA, ok := limiter.Acquire(context.Background())
+	require.True(t, ok)
+	releaseB, ok := limiter.Acquire(context.Background())
+	require.True(t, ok)
+
+	_, ok = limiter.Acquire(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, int64(2), limiter.Open())
+	assert.Equal(t, 2, recorder.open)
+	assert.Equal(t, 1, recorder.rejected)
+
+	// releasing twice only frees one stream
+	releaseA()
+	releaseA()
+	assert.Equal(t, int64(1), limiter.Open())
+	assert.Equal(t, 1, recorder.open)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	releaseC, ok := limiter.Acquire(ctx)
+	require.True(t, ok)
+	cancel()
+	releaseC()
+	releaseB()
+
+	assert.Equal(t, int64(0), limiter.Open())
+	assert.Equal(t, 0, recorder.open)
+}
+
+func TestStreamLimiter_Unlimited(t *testing.T) {
+	limiter := NewStreamLimiter(EventStream, 0, nil)
+
+	for i := 0; i < 100; i++ {
+		_, ok := limiter.Acquire(context.Background())
+		require.True(t, ok)
+	}
+	assert.Equal(t, int64(100), limiter.Open())
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// ContextFormatHeader selects the format of the evaluation context of a request, either the plain JSON context by
+	// default or TypedContextFormat
+	ContextFormatHeader = "Flagd-Context-Format"
+	// TypedContextFormat is the format of evaluation contexts whose attributes declare their type, e.g.
+	// {"phone": {"type": "string", "value": 5550100}}, so that values are coerced to the declared type
+	TypedContextFormat = "typed"
+
+	// the types an attribute of a typed context may declare
+	TypedString  = "string"
+	TypedInteger = "integer"
+	TypedFloat   = "float"
+	TypedBoolean = "boolean"
+	TypedObject  = "object"
+	TypedArray   = "array"
+
+	typedTypeKey  = "type"
+	typedValueKey = "value"
+)
+
+// IsTypedContext reports whether the evaluation context of a request is in the TypedContextFormat, failing if the
+// request selects an unknown format
+func IsTypedContext(header http.Header) (bool, error) {
+	switch format := header.Get(ContextFormatHeader); format {
+	case "":
+		return false, nil
+	case TypedContextFormat:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown context format '%s'", format)
+	}
+}
+
+// DecodeTypedContext decodes an evaluation context in the TypedContextFormat into a plain evaluation context. Each
+// attribute is an object of the declared type and the value, which is coerced to the type deterministically:
+//   - string: strings are kept, numbers are formatted without exponent and booleans as true or false
+//   - integer: numbers without fractional part and strings parsed as base 10 integer
+//   - float: numbers and strings parsed as number
+//   - boolean: booleans and the strings true and false
+//   - object: objects whose attributes are typed in turn
+//   - array: arrays whose elements are typed in turn
+//
+// Values which can't be coerced fail the decoding, as does a null value.
+func DecodeTypedContext(context map[string]any) (map[string]any, error) {
+	return decodeTypedObject("", context)
+}
+
+func decodeTypedObject(path string, object map[string]any) (map[string]any, error) {
+	decoded := make(map[string]any, len(object))
+	for key, attribute := range object {
+		value, err := decodeTypedValue(joinTypedPath(path, key), attribute)
+		if err != nil {
+			return nil, err
+		}
+		decoded[key] = value
+	}
+	return decoded, nil
+}
+
+func decodeTypedValue(path string, attribute any) (any, error) {
+	typed, ok := attribute.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("attribute %s: expected an object of type and value but got %v", path, attribute)
+	}
+	declared, ok := typed[typedTypeKey].(string)
+	if !ok {
+		return nil, fmt.Errorf("attribute %s: missing type", path)
+	}
+	value, ok := typed[typedValueKey]
+	if !ok || value == nil {
+		return nil, fmt.Errorf("attribute %s: missing value", path)
+	}
+
+	switch declared {
+	case TypedObject:
+		if object, ok := value.(map[string]any); ok {
+			return decodeTypedObject(path, object)
+		}
+	case TypedArray:
+		if array, ok := value.([]any); ok {
+			decoded := make([]any, len(array))
+			for i, element := range array {
+				d, err := decodeTypedValue(fmt.Sprintf("%s[%d]", path, i), element)
+				if err != nil {
+					return nil, err
+				}
+				decoded[i] = d
+			}
+			return decoded, nil
+		}
+	default:
+		coerce, ok := typedCoercions[declared]
+		if !ok {
+			return nil, fmt.Errorf("attribute %s: unknown type '%s'", path, declared)
+		}
+		if coerced, ok := coerce(value); ok {
+			return coerced, nil
+		}
+	}
+	return nil, fmt.Errorf("attribute %s: cannot coerce %v to %s", path, value, declared)
+}
+
+// typedCoercions coerce a value to a declared scalar type, reporting whether the value can be coerced
+var typedCoercions = map[string]func(value any) (any, bool){
+	TypedString: func(value any) (any, bool) {
+		switch v := value.(type) {
+		case string:
+			return v, true
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(v), true
+		}
+		return nil, false
+	},
+	TypedInteger: func(value any) (any, bool) {
+		switch v := value.(type) {
+		case float64:
+			return v, v == math.Trunc(v) && !math.IsInf(v, 0)
+		case string:
+			i, err := strconv.ParseInt(v, 10, 64)
+			return float64(i), err == nil
+		}
+		return nil, false
+	},
+	TypedFloat: func(value any) (any, bool) {
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			return f, err == nil && !math.IsInf(f, 0) && !math.IsNaN(f)
+		}
+		return nil, false
+	},
+	TypedBoolean: func(value any) (any, bool) {
+		switch v := value.(type) {
+		case bool:
+			return v, true
+		case string:
+			return v == "true", v == "true" || v == "false"
+		}
+		return nil, false
+	},
+}
+
+func joinTypedPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
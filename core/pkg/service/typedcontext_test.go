@@ -0,0 +1,80 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTypedContext(t *testing.T) {
+	typed, err := IsTypedContext(http.Header{})
+	require.NoError(t, err)
+	assert.False(t, typed, "contexts are plain by default")
+
+	typed, err = IsTypedContext(http.Header{ContextFormatHeader: []string{TypedContextFormat}})
+	require.NoError(t, err)
+	assert.True(t, typed)
+
+	_, err = IsTypedContext(http.Header{ContextFormatHeader: []string{"xml"}})
+	require.Error(t, err)
+}
+
+func TestDecodeTypedContext(t *testing.T) {
+	tests := map[string]struct {
+		attribute any
+		expected  any
+		err       string
+	}{
+		"string":            {attribute: typed(TypedString, "123"), expected: "123"},
+		"number as string":  {attribute: typed(TypedString, 5550100.0), expected: "5550100"},
+		"large number":      {attribute: typed(TypedString, 1e21), expected: "1000000000000000000000"},
+		"boolean as string": {attribute: typed(TypedString, true), expected: "true"},
+		"integer":           {attribute: typed(TypedInteger, 42.0), expected: 42.0},
+		"string as integer": {attribute: typed(TypedInteger, "42"), expected: 42.0},
+		"fraction":          {attribute: typed(TypedInteger, 4.2), err: "attribute a: cannot coerce 4.2 to integer"},
+		"float":             {attribute: typed(TypedFloat, 4.2), expected: 4.2},
+		"string as float":   {attribute: typed(TypedFloat, "4.2"), expected: 4.2},
+		"invalid float":     {attribute: typed(TypedFloat, "NaN"), err: "attribute a: cannot coerce NaN to float"},
+		"boolean":           {attribute: typed(TypedBoolean, false), expected: false},
+		"string as boolean": {attribute: typed(TypedBoolean, "true"), expected: true},
+		"invalid boolean":   {attribute: typed(TypedBoolean, "yes"), err: "attribute a: cannot coerce yes to boolean"},
+		"unknown type":      {attribute: typed("date", "2026-01-01"), err: "attribute a: unknown type 'date'"},
+		"plain attribute":   {attribute: "123", err: "attribute a: expected an object of type and value but got 123"},
+		"missing type":      {attribute: map[string]any{"value": 1.0}, err: "attribute a: missing type"},
+		"null value":        {attribute: typed(TypedString, nil), err: "attribute a: missing value"},
+		"object": {
+			attribute: typed(TypedObject, map[string]any{"zip": typed(TypedString, 1010.0)}),
+			expected:  map[string]any{"zip": "1010"},
+		},
+		"nested error": {
+			attribute: typed(TypedObject, map[string]any{"zip": typed(TypedInteger, "A-1010")}),
+			err:       "attribute a.zip: cannot coerce A-1010 to integer",
+		},
+		"array": {
+			attribute: typed(TypedArray, []any{typed(TypedString, 1.0), typed(TypedBoolean, "false")}),
+			expected:  []any{"1", false},
+		},
+		"array error": {
+			attribute: typed(TypedArray, []any{typed(TypedString, 1.0), "plain"}),
+			err:       "attribute a[1]: expected an object of type and value but got plain",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			decoded, err := DecodeTypedContext(map[string]any{"a": tt.attribute})
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"a": tt.expected}, decoded)
+		})
+	}
+}
+
+func typed(declared string, value any) map[string]any {
+	return map[string]any{"type": declared, "value": value}
+}
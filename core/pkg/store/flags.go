@@ -2,34 +2,77 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
 )
 
+// versionLength is the number of bytes of the flags hash making up the version
+const versionLength = 8
+
+// FlagSetIDMetadataKey is the flag or flag set metadata key assigning flags to a flag set
+const FlagSetIDMetadataKey = "flagSetId"
+
+// AliasesMetadataKey is the flag metadata key declaring the aliases of a flag as a comma separated list of keys, e.g.
+// the former keys of a renamed flag. Lookups of an alias resolve the flag declaring it.
+const AliasesMetadataKey = "aliases"
+
 type IStore interface {
 	GetAll(ctx context.Context) (map[string]model.Flag, error)
 	Get(ctx context.Context, key string) (model.Flag, bool)
 	SelectorForFlag(ctx context.Context, flag model.Flag) string
+	Lookup(ctx context.Context, key string) (model.Flag, string, bool)
+	Alias(ctx context.Context, alias string) (string, bool)
 }
 
 type Flags struct {
-	mx             sync.RWMutex
-	Flags          map[string]model.Flag `json:"flags"`
-	FlagSources    []string
-	SourceMetadata map[string]SourceDetails `json:"sourceMetadata,omitempty"`
-	Metadata       map[string]interface{}   `json:"metadata,omitempty"`
+	mx          sync.RWMutex
+	Flags       map[string]model.Flag `json:"flags"`
+	FlagSources []string
+	// FlagSetFallback is the ordered chain of flag set IDs flags are looked up in, the first flag set defining a
+	// flag answers the lookup. Flags are looked up in the merged flags of all sources if no chain is configured.
+	FlagSetFallback []string
+	SourceMetadata  map[string]SourceDetails `json:"sourceMetadata,omitempty"`
+	Metadata        map[string]interface{}   `json:"metadata,omitempty"`
+	// sourceMetadata holds the flag metadata as defined by each source, keyed by flag key and source, so that the
+	// metadata of a flag can be merged across all sources defining the flag
+	sourceMetadata map[string]map[string]sourceMetadata
+	// sourceFlags holds the flags as defined by each source, keyed by source and flag key, so that flags overridden
+	// by a source of higher priority can still answer lookups along the flag set fallback chain. It is only recorded
+	// if a chain is configured.
+	sourceFlags map[string]map[string]model.Flag
+	// version caches the hash of the stored flags, it is reset by any change of the stored flags
+	version string
+	// aliases caches the flag keys by their aliases, it is reset by any change of the stored flags
+	aliases map[string]string
+	// declarations holds the declarations referenced by the targeting of the flags of each source, keyed by source,
+	// declaration kind, e.g. "$lookups", and name
+	declarations map[string]Declarations
 }
 
+// Declarations are the top-level declarations of a configuration which the targeting of its flags references by name,
+// keyed by declaration kind, e.g. "$lookups", and name
+type Declarations map[string]map[string]json.RawMessage
+
 type SourceDetails struct {
 	Source   string
 	Selector string
 }
 
+type sourceMetadata struct {
+	selector string
+	values   map[string]interface{}
+}
+
 func (f *Flags) hasPriority(stored string, new string) bool {
 	if stored == new {
 		return true
@@ -45,6 +88,17 @@ func (f *Flags) hasPriority(stored string, new string) bool {
 	return true
 }
 
+// priority returns the merge priority of a source, sources defined later have a higher priority. Sources which are
+// not configured have the highest priority.
+func (f *Flags) priority(source string) int {
+	for i := len(f.FlagSources) - 1; i >= 0; i-- {
+		if f.FlagSources[i] == source {
+			return i
+		}
+	}
+	return len(f.FlagSources)
+}
+
 func NewFlags() *Flags {
 	return &Flags{
 		Flags:          map[string]model.Flag{},
@@ -56,6 +110,8 @@ func (f *Flags) Set(key string, flag model.Flag) {
 	f.mx.Lock()
 	defer f.mx.Unlock()
 	f.Flags[key] = flag
+	f.version = ""
+	f.aliases = nil
 }
 
 func (f *Flags) Get(_ context.Context, key string) (model.Flag, bool) {
@@ -66,6 +122,76 @@ func (f *Flags) Get(_ context.Context, key string) (model.Flag, bool) {
 	return flag, ok
 }
 
+// Lookup returns the flag of the given key along with the ID of the flag set answering the lookup. With a flag set
+// fallback chain, the flag is taken from the first flag set of the chain defining it, from the source of the highest
+// priority if several sources define the flag in that set. Without a chain, the merged flag is returned.
+func (f *Flags) Lookup(ctx context.Context, key string) (model.Flag, string, bool) {
+	if len(f.FlagSetFallback) == 0 {
+		flag, ok := f.Get(ctx, key)
+		return flag, "", ok
+	}
+
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+	for _, flagSet := range f.FlagSetFallback {
+		var found model.Flag
+		foundPriority := -1
+		for source, flags := range f.sourceFlags {
+			flag, defined := flags[key]
+			if !defined || flag.Metadata[FlagSetIDMetadataKey] != flagSet {
+				continue
+			}
+			// sources of equal priority win in lexical order, as for the merged metadata
+			if priority := f.priority(source); priority > foundPriority ||
+				priority == foundPriority && source > found.Source {
+				found = flag
+				foundPriority = priority
+			}
+		}
+		if foundPriority >= 0 {
+			return found, flagSet, true
+		}
+	}
+	return model.Flag{}, "", false
+}
+
+// setSourceFlags records the flags as defined by a source, replacing all flags previously recorded for the source
+// if replace is set
+func (f *Flags) setSourceFlags(source string, selector string, flags map[string]model.Flag, replace bool) {
+	if len(f.FlagSetFallback) == 0 {
+		return
+	}
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	if f.sourceFlags == nil {
+		f.sourceFlags = map[string]map[string]model.Flag{}
+	}
+	if replace || f.sourceFlags[source] == nil {
+		f.sourceFlags[source] = make(map[string]model.Flag, len(flags))
+	}
+	for key, flag := range flags {
+		flag.Source = source
+		flag.Selector = selector
+		f.sourceFlags[source][key] = flag
+	}
+}
+
+// deleteSourceFlags removes the given flags of a source, or all flags and declarations of the source if none are
+// given
+func (f *Flags) deleteSourceFlags(source string, flags map[string]model.Flag) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	if len(flags) == 0 {
+		delete(f.sourceFlags, source)
+		delete(f.declarations, source)
+		f.version = ""
+		return
+	}
+	for key := range flags {
+		delete(f.sourceFlags[source], key)
+	}
+}
+
 func (f *Flags) SelectorForFlag(_ context.Context, flag model.Flag) string {
 	f.mx.RLock()
 	defer f.mx.RUnlock()
@@ -77,6 +203,8 @@ func (f *Flags) Delete(key string) {
 	f.mx.Lock()
 	defer f.mx.Unlock()
 	delete(f.Flags, key)
+	f.version = ""
+	f.aliases = nil
 }
 
 func (f *Flags) String() (string, error) {
@@ -90,6 +218,188 @@ func (f *Flags) String() (string, error) {
 	return string(bytes), nil
 }
 
+// Snapshot returns a copy of the store answering lookups as the store does at the time of the snapshot, unaffected by
+// later changes of the store. The stored flags are shared with the store, as flags are replaced rather than modified.
+func (f *Flags) Snapshot() *Flags {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	snapshot := &Flags{
+		Flags:           make(map[string]model.Flag, len(f.Flags)),
+		FlagSources:     append([]string{}, f.FlagSources...),
+		FlagSetFallback: append([]string{}, f.FlagSetFallback...),
+		SourceMetadata:  make(map[string]SourceDetails, len(f.SourceMetadata)),
+		Metadata:        make(map[string]interface{}, len(f.Metadata)),
+		version:         f.version,
+	}
+	for key, flag := range f.Flags {
+		snapshot.Flags[key] = flag
+	}
+	for source, details := range f.SourceMetadata {
+		snapshot.SourceMetadata[source] = details
+	}
+	for key, value := range f.Metadata {
+		snapshot.Metadata[key] = value
+	}
+	if f.declarations != nil {
+		// the declarations of a source are replaced rather than modified
+		snapshot.declarations = make(map[string]Declarations, len(f.declarations))
+		for source, declarations := range f.declarations {
+			snapshot.declarations[source] = declarations
+		}
+	}
+	if f.sourceFlags != nil {
+		snapshot.sourceFlags = make(map[string]map[string]model.Flag, len(f.sourceFlags))
+		for source, flags := range f.sourceFlags {
+			snapshot.sourceFlags[source] = make(map[string]model.Flag, len(flags))
+			for key, flag := range flags {
+				snapshot.sourceFlags[source][key] = flag
+			}
+		}
+	}
+	return snapshot
+}
+
+// SetDeclarations records the declarations of the configuration of a source, replacing all declarations previously
+// recorded for the source if replace is set. Declarations are served along with the flags, so that served flags can be
+// applied as they are.
+func (f *Flags) SetDeclarations(source string, declarations Declarations, replace bool) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	merged := Declarations{}
+	if !replace {
+		for kind, named := range f.declarations[source] {
+			merged[kind] = maps.Clone(named)
+		}
+	}
+	for kind, named := range declarations {
+		if merged[kind] == nil {
+			merged[kind] = make(map[string]json.RawMessage, len(named))
+		}
+		for name, declaration := range named {
+			merged[kind][name] = declaration
+		}
+	}
+	if len(merged) == 0 && f.declarations[source] == nil {
+		return
+	}
+	if f.declarations == nil {
+		f.declarations = map[string]Declarations{}
+	}
+	f.declarations[source] = merged
+	f.version = ""
+}
+
+// GetDeclarations returns the declarations of the given source, or of all sources if the source is empty. A name
+// declared by several sources resolves to the declaration of the source of the highest priority.
+func (f *Flags) GetDeclarations(source string) Declarations {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+	return f.getDeclarationsLocked(source)
+}
+
+func (f *Flags) getDeclarationsLocked(source string) Declarations {
+	var sources []string
+	if source != "" {
+		sources = []string{source}
+	} else {
+		for s := range f.declarations {
+			sources = append(sources, s)
+		}
+		sort.Slice(sources, func(i, j int) bool {
+			pi, pj := f.priority(sources[i]), f.priority(sources[j])
+			return pi < pj || pi == pj && sources[i] < sources[j]
+		})
+	}
+
+	declarations := Declarations{}
+	for _, s := range sources {
+		for kind, named := range f.declarations[s] {
+			if declarations[kind] == nil {
+				declarations[kind] = make(map[string]json.RawMessage, len(named))
+			}
+			for name, declaration := range named {
+				declarations[kind][name] = declaration
+			}
+		}
+	}
+	return declarations
+}
+
+// Version returns a hash of the stored flags and declarations. It changes only if the stored flags or declarations
+// change, so that clients can detect that results evaluated with a previous version are stale.
+func (f *Flags) Version() string {
+	f.mx.RLock()
+	version := f.version
+	f.mx.RUnlock()
+	if version != "" {
+		return version
+	}
+
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	if f.version == "" {
+		// map keys are marshalled in sorted order, hence equal flags result in equal hashes
+		bytes, err := json.Marshal(f.Flags)
+		if err != nil {
+			return ""
+		}
+		if declarations := f.getDeclarationsLocked(""); len(declarations) > 0 {
+			// flags without declarations keep their version
+			marshalled, err := json.Marshal(declarations)
+			if err != nil {
+				return ""
+			}
+			bytes = append(bytes, marshalled...)
+		}
+		sum := sha256.Sum256(bytes)
+		f.version = hex.EncodeToString(sum[:versionLength])
+	}
+	return f.version
+}
+
+// Alias returns the key of the flag declaring the given alias. If several flags declare the alias, e.g. flags of
+// different sources, the lexically first key is returned.
+func (f *Flags) Alias(_ context.Context, alias string) (string, bool) {
+	f.mx.RLock()
+	aliases := f.aliases
+	f.mx.RUnlock()
+	if aliases == nil {
+		f.mx.Lock()
+		if f.aliases == nil {
+			f.aliases = map[string]string{}
+			for key, flag := range f.Flags {
+				for _, a := range Aliases(flag) {
+					if stored, ok := f.aliases[a]; !ok || key < stored {
+						f.aliases[a] = key
+					}
+				}
+			}
+		}
+		aliases = f.aliases
+		f.mx.Unlock()
+	}
+
+	key, ok := aliases[alias]
+	return key, ok
+}
+
+// Aliases returns the aliases declared by the metadata of a flag, ignoring empty entries and metadata other than
+// strings
+func Aliases(flag model.Flag) []string {
+	value, ok := flag.Metadata[AliasesMetadataKey].(string)
+	if !ok {
+		return nil
+	}
+	var aliases []string
+	for _, alias := range strings.Split(value, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
 // GetAll returns a copy of the store's state (copy in order to be concurrency safe)
 func (f *Flags) GetAll(_ context.Context) (map[string]model.Flag, error) {
 	f.mx.RLock()
@@ -107,8 +417,10 @@ func (f *Flags) GetAll(_ context.Context) (map[string]model.Flag, error) {
 func (f *Flags) Add(logger *logger.Logger, source string, selector string, flags map[string]model.Flag,
 ) map[string]interface{} {
 	notifications := map[string]interface{}{}
+	f.setSourceFlags(source, selector, flags, false)
 
 	for k, newFlag := range flags {
+		f.setSourceMetadata(k, source, selector, newFlag.Metadata)
 		storedFlag, ok := f.Get(context.Background(), k)
 		if ok && !f.hasPriority(storedFlag.Source, source) {
 			logger.Debug(
@@ -119,6 +431,7 @@ func (f *Flags) Add(logger *logger.Logger, source string, selector string, flags
 					storedFlag.Source,
 				),
 			)
+			f.notifyMetadataRefresh(logger, notifications, source, k)
 			continue
 		}
 
@@ -130,6 +443,9 @@ func (f *Flags) Add(logger *logger.Logger, source string, selector string, flags
 		// Store the new version of the flag
 		newFlag.Source = source
 		newFlag.Selector = selector
+		if merged := f.mergedMetadata(logger, k); merged != nil {
+			newFlag.Metadata = merged
+		}
 		f.Set(k, newFlag)
 	}
 
@@ -140,6 +456,7 @@ func (f *Flags) Add(logger *logger.Logger, source string, selector string, flags
 func (f *Flags) Update(logger *logger.Logger, source string, selector string, flags map[string]model.Flag,
 ) map[string]interface{} {
 	notifications := map[string]interface{}{}
+	f.setSourceFlags(source, selector, flags, false)
 
 	for k, flag := range flags {
 		storedFlag, ok := f.Get(context.Background(), k)
@@ -151,6 +468,7 @@ func (f *Flags) Update(logger *logger.Logger, source string, selector string, fl
 
 			continue
 		}
+		f.setSourceMetadata(k, source, selector, flag.Metadata)
 		if !f.hasPriority(storedFlag.Source, source) {
 			logger.Debug(
 				fmt.Sprintf(
@@ -160,6 +478,7 @@ func (f *Flags) Update(logger *logger.Logger, source string, selector string, fl
 					storedFlag.Source,
 				),
 			)
+			f.notifyMetadataRefresh(logger, notifications, source, k)
 			continue
 		}
 
@@ -170,6 +489,9 @@ func (f *Flags) Update(logger *logger.Logger, source string, selector string, fl
 
 		flag.Source = source
 		flag.Selector = selector
+		if merged := f.mergedMetadata(logger, k); merged != nil {
+			flag.Metadata = merged
+		}
 		f.Set(k, flag)
 	}
 
@@ -185,6 +507,8 @@ func (f *Flags) DeleteFlags(logger *logger.Logger, source string, flags map[stri
 		),
 	)
 	ctx := context.Background()
+	f.deleteSourceFlags(source, flags)
+	dropped := f.deleteSourceMetadata(source, flags)
 
 	notifications := map[string]interface{}{}
 	if len(flags) == 0 {
@@ -233,12 +557,134 @@ func (f *Flags) DeleteFlags(logger *logger.Logger, source string, flags map[stri
 					source))
 		}
 	}
+	// the metadata of the source is dropped from the flags stored from other sources as well
+	for _, k := range dropped {
+		if _, ok := notifications[k]; !ok {
+			f.notifyMetadataRefresh(logger, notifications, source, k)
+		}
+	}
 
 	return notifications
 }
 
+// setSourceMetadata records the metadata of a flag as defined by a source and selector, empty metadata removes the
+// record
+func (f *Flags) setSourceMetadata(key string, source string, selector string, metadata map[string]interface{}) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.setSourceMetadataLocked(key, source, selector, metadata)
+}
+
+func (f *Flags) setSourceMetadataLocked(key string, source string, selector string, metadata map[string]interface{}) {
+	if len(metadata) == 0 {
+		delete(f.sourceMetadata[key], source)
+		if len(f.sourceMetadata[key]) == 0 {
+			delete(f.sourceMetadata, key)
+		}
+		return
+	}
+	if f.sourceMetadata == nil {
+		f.sourceMetadata = map[string]map[string]sourceMetadata{}
+	}
+	if f.sourceMetadata[key] == nil {
+		f.sourceMetadata[key] = map[string]sourceMetadata{}
+	}
+	f.sourceMetadata[key][source] = sourceMetadata{selector: selector, values: metadata}
+}
+
+// deleteSourceMetadata removes the metadata of the given flags as defined by a source, all flags of the source if no
+// flags are given, and returns the keys of the flags whose metadata was removed
+func (f *Flags) deleteSourceMetadata(source string, flags map[string]model.Flag) []string {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	var dropped []string
+	for k, bySource := range f.sourceMetadata {
+		if _, ok := bySource[source]; !ok {
+			continue
+		}
+		if _, ok := flags[k]; ok || len(flags) == 0 {
+			f.setSourceMetadataLocked(k, source, "", nil)
+			dropped = append(dropped, k)
+		}
+	}
+	return dropped
+}
+
+// notifyMetadataRefresh refreshes the metadata of a stored flag of another source and adds an update notification if
+// the merged metadata changed
+func (f *Flags) notifyMetadataRefresh(
+	logger *logger.Logger, notifications map[string]interface{}, source string, key string,
+) {
+	if f.refreshMetadata(logger, key) {
+		notifications[key] = map[string]interface{}{
+			"type":   string(model.NotificationUpdate),
+			"source": source,
+		}
+	}
+}
+
+// mergedMetadata returns the metadata of a flag merged across all sources defining it, or nil if no source defines
+// metadata for the flag. Sources are applied in ascending order of priority, so that for each metadata key the value
+// of the source with the highest priority wins. Sources of equal priority are applied in lexical order.
+func (f *Flags) mergedMetadata(logger *logger.Logger, key string) map[string]interface{} {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	bySource := f.sourceMetadata[key]
+	if len(bySource) == 0 {
+		return nil
+	}
+	sources := make([]string, 0, len(bySource))
+	for source := range bySource {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		pi, pj := f.priority(sources[i]), f.priority(sources[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return sources[i] < sources[j]
+	})
+
+	merged := map[string]interface{}{}
+	origins := map[string]string{}
+	for _, source := range sources {
+		for metaKey, value := range bySource[source].values {
+			if origin, ok := origins[metaKey]; ok && !reflect.DeepEqual(merged[metaKey], value) {
+				logger.Debug(
+					fmt.Sprintf(
+						"overriding metadata: metadata %s of flag %s from source %s has priority over %s",
+						metaKey, key, source, origin,
+					),
+				)
+			}
+			merged[metaKey] = value
+			origins[metaKey] = source
+		}
+	}
+	return merged
+}
+
+// refreshMetadata updates the metadata of a stored flag after the metadata of another source changed, and reports
+// whether the merged metadata of the flag changed
+func (f *Flags) refreshMetadata(logger *logger.Logger, key string) bool {
+	storedFlag, ok := f.Get(context.Background(), key)
+	if !ok {
+		return false
+	}
+	merged := f.mergedMetadata(logger, key)
+	if len(merged) == 0 && len(storedFlag.Metadata) == 0 || reflect.DeepEqual(merged, storedFlag.Metadata) {
+		return false
+	}
+	storedFlag.Metadata = merged
+	f.Set(key, storedFlag)
+	return true
+}
+
 // Merge provided flags from source with currently stored flags.
-// nolint: funlen
+// The metadata of a flag is merged across all sources defining the flag, with the source of the highest priority
+// winning for each metadata key.
+// nolint: funlen, gocognit
 func (f *Flags) Merge(
 	logger *logger.Logger,
 	source string,
@@ -247,12 +693,15 @@ func (f *Flags) Merge(
 ) (map[string]interface{}, bool) {
 	notifications := map[string]interface{}{}
 	resyncRequired := false
+	f.setSourceFlags(source, selector, flags, true)
 	f.mx.Lock()
 	for k, v := range f.Flags {
 		if v.Source == source && v.Selector == selector {
 			if _, ok := flags[k]; !ok {
 				// flag has been deleted
 				delete(f.Flags, k)
+				f.version = ""
+				f.aliases = nil
 				notifications[k] = map[string]interface{}{
 					"type":   string(model.NotificationDelete),
 					"source": source,
@@ -268,10 +717,29 @@ func (f *Flags) Merge(
 			}
 		}
 	}
+	// metadata of flags which the source no longer defines, but which are stored from another source
+	var dropped []string
+	for k, bySource := range f.sourceMetadata {
+		if metadata, ok := bySource[source]; ok && metadata.selector == selector {
+			if _, ok := flags[k]; !ok {
+				f.setSourceMetadataLocked(k, source, selector, nil)
+				dropped = append(dropped, k)
+			}
+		}
+	}
 	f.mx.Unlock()
+	for _, k := range dropped {
+		if f.refreshMetadata(logger, k) {
+			notifications[k] = map[string]interface{}{
+				"type":   string(model.NotificationUpdate),
+				"source": source,
+			}
+		}
+	}
 	for k, newFlag := range flags {
 		newFlag.Source = source
 		newFlag.Selector = selector
+		f.setSourceMetadata(k, source, selector, newFlag.Metadata)
 		storedFlag, ok := f.Get(context.Background(), k)
 		if ok {
 			if !f.hasPriority(storedFlag.Source, source) {
@@ -281,8 +749,20 @@ func (f *Flags) Merge(
 						k, source, storedFlag.Source,
 					),
 				)
+				// the metadata of the source is still merged for the keys not defined by higher priority sources
+				if f.refreshMetadata(logger, k) {
+					notifications[k] = map[string]interface{}{
+						"type":   string(model.NotificationUpdate),
+						"source": source,
+					}
+				}
 				continue
 			}
+		}
+		if merged := f.mergedMetadata(logger, k); merged != nil {
+			newFlag.Metadata = merged
+		}
+		if ok {
 			if reflect.DeepEqual(storedFlag, newFlag) {
 				continue
 			}
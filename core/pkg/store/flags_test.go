@@ -1,6 +1,8 @@
 package store
 
 import (
+	"context"
+	"encoding/json"
 	"reflect"
 	"testing"
 
@@ -228,6 +230,310 @@ func TestMergeFlags(t *testing.T) {
 	}
 }
 
+func TestMergeFlags_Metadata(t *testing.T) {
+	t.Parallel()
+	low := map[string]model.Flag{
+		"hello": {DefaultVariant: "off", Metadata: map[string]interface{}{"owner": "team-a", "version": "1"}},
+	}
+	high := map[string]model.Flag{
+		"hello": {DefaultVariant: "on", Metadata: map[string]interface{}{"version": "2", "env": "prod"}},
+	}
+	want := map[string]interface{}{"owner": "team-a", "version": "2", "env": "prod"}
+
+	tests := []struct {
+		name  string
+		order []string
+	}{
+		{name: "lower priority source first", order: []string{"A", "B"}},
+		{name: "higher priority source first", order: []string{"B", "A"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			flags := &Flags{Flags: map[string]model.Flag{}, FlagSources: []string{"A", "B"}}
+			sources := map[string]map[string]model.Flag{"A": low, "B": high}
+			for _, source := range tt.order {
+				flags.Merge(logger.NewLogger(nil, false), source, "", sources[source])
+			}
+
+			flag, ok := flags.Get(context.Background(), "hello")
+			require.True(t, ok)
+			require.Equal(t, "B", flag.Source)
+			require.Equal(t, "on", flag.DefaultVariant)
+			require.Equal(t, want, flag.Metadata)
+		})
+	}
+
+	t.Run("metadata removed from a source", func(t *testing.T) {
+		t.Parallel()
+		flags := &Flags{Flags: map[string]model.Flag{}, FlagSources: []string{"A", "B"}}
+		flags.Merge(logger.NewLogger(nil, false), "B", "", high)
+		flags.Merge(logger.NewLogger(nil, false), "A", "", low)
+
+		notifs, _ := flags.Merge(logger.NewLogger(nil, false), "A", "", map[string]model.Flag{})
+		require.Equal(t, map[string]interface{}{"hello": map[string]interface{}{"type": "update", "source": "A"}}, notifs)
+
+		flag, _ := flags.Get(context.Background(), "hello")
+		require.Equal(t, high["hello"].Metadata, flag.Metadata)
+	})
+
+	t.Run("metadata deleted by a delete sync", func(t *testing.T) {
+		t.Parallel()
+		flags := &Flags{Flags: map[string]model.Flag{}, FlagSources: []string{"A", "B"}}
+		flags.Merge(logger.NewLogger(nil, false), "A", "", low)
+		flags.DeleteFlags(logger.NewLogger(nil, false), "A", map[string]model.Flag{"hello": {}})
+
+		flags.Merge(logger.NewLogger(nil, false), "B", "", map[string]model.Flag{"hello": {DefaultVariant: "on"}})
+		flag, ok := flags.Get(context.Background(), "hello")
+		require.True(t, ok)
+		require.Empty(t, flag.Metadata)
+	})
+
+	t.Run("metadata of a lower priority source deleted by a delete sync", func(t *testing.T) {
+		t.Parallel()
+		flags := &Flags{Flags: map[string]model.Flag{}, FlagSources: []string{"A", "B"}}
+		flags.Merge(logger.NewLogger(nil, false), "B", "", high)
+		flags.Merge(logger.NewLogger(nil, false), "A", "", low)
+
+		notifs := flags.DeleteFlags(logger.NewLogger(nil, false), "A", map[string]model.Flag{})
+		require.Equal(t, map[string]interface{}{"hello": map[string]interface{}{"type": "update", "source": "A"}}, notifs)
+
+		flags.Merge(logger.NewLogger(nil, false), "B", "", high)
+		flag, _ := flags.Get(context.Background(), "hello")
+		require.Equal(t, high["hello"].Metadata, flag.Metadata)
+	})
+
+	t.Run("metadata changed by add and update syncs", func(t *testing.T) {
+		t.Parallel()
+		flags := &Flags{Flags: map[string]model.Flag{}, FlagSources: []string{"A", "B"}}
+		flags.Add(logger.NewLogger(nil, false), "B", "", high)
+		flags.Add(logger.NewLogger(nil, false), "A", "", low)
+		flag, _ := flags.Get(context.Background(), "hello")
+		require.Equal(t, want, flag.Metadata)
+
+		flags.Update(logger.NewLogger(nil, false), "A", "", map[string]model.Flag{
+			"hello": {DefaultVariant: "off", Metadata: map[string]interface{}{"owner": "team-c"}},
+		})
+		flag, _ = flags.Get(context.Background(), "hello")
+		require.Equal(t, map[string]interface{}{"owner": "team-c", "version": "2", "env": "prod"}, flag.Metadata)
+	})
+}
+
+func TestFlags_Version(t *testing.T) {
+	t.Parallel()
+	log := logger.NewLogger(nil, false)
+	flags := NewFlags()
+	flags.FlagSources = []string{"A"}
+	config := map[string]model.Flag{
+		"hello": {State: "ENABLED", DefaultVariant: "on", Variants: map[string]any{"on": true, "off": false}},
+	}
+
+	empty := flags.Version()
+	require.NotEmpty(t, empty)
+
+	flags.Merge(log, "A", "", config)
+	applied := flags.Version()
+	require.NotEqual(t, empty, applied)
+
+	// applying an unchanged configuration keeps the version
+	flags.Merge(log, "A", "", config)
+	require.Equal(t, applied, flags.Version())
+
+	changed := map[string]model.Flag{
+		"hello": {State: "ENABLED", DefaultVariant: "off", Variants: map[string]any{"on": true, "off": false}},
+	}
+	flags.Merge(log, "A", "", changed)
+	require.NotEqual(t, applied, flags.Version())
+
+	// versions are derived from the content, so that restoring a configuration restores its version
+	flags.Merge(log, "A", "", config)
+	require.Equal(t, applied, flags.Version())
+
+	flags.Merge(log, "A", "", map[string]model.Flag{})
+	require.Equal(t, empty, flags.Version())
+}
+
+func TestFlags_Lookup(t *testing.T) {
+	t.Parallel()
+	log := logger.NewLogger(nil, false)
+	flag := func(flagSet string, variant string) model.Flag {
+		return model.Flag{
+			State:          "ENABLED",
+			DefaultVariant: variant,
+			Variants:       map[string]any{"base": "base", "tenant": "tenant"},
+			Metadata:       map[string]interface{}{FlagSetIDMetadataKey: flagSet},
+		}
+	}
+
+	merge := func(flags *Flags) {
+		flags.Merge(log, "base", "", map[string]model.Flag{
+			"banner":   flag("base", "base"),
+			"checkout": flag("base", "base"),
+		})
+		flags.Merge(log, "tenant", "", map[string]model.Flag{
+			"banner": flag("tenant-a", "tenant"),
+			"search": flag("tenant-b", "tenant"),
+		})
+	}
+
+	// without a chain, the merged flags answer
+	flags := NewFlags()
+	flags.FlagSources = []string{"base", "tenant"}
+	merge(flags)
+	found, flagSet, ok := flags.Lookup(context.Background(), "banner")
+	require.True(t, ok)
+	require.Empty(t, flagSet)
+	require.Equal(t, "tenant", found.DefaultVariant)
+
+	flags = NewFlags()
+	flags.FlagSources = []string{"base", "tenant"}
+	flags.FlagSetFallback = []string{"tenant-a", "base"}
+	merge(flags)
+
+	found, flagSet, ok = flags.Lookup(context.Background(), "banner")
+	require.True(t, ok)
+	require.Equal(t, "tenant-a", flagSet)
+	require.Equal(t, "tenant", found.DefaultVariant)
+	require.Equal(t, "tenant", found.Source)
+
+	found, flagSet, ok = flags.Lookup(context.Background(), "checkout")
+	require.True(t, ok)
+	require.Equal(t, "base", flagSet)
+	require.Equal(t, "base", found.DefaultVariant)
+
+	// flags of flag sets outside the chain are not found
+	_, _, ok = flags.Lookup(context.Background(), "search")
+	require.False(t, ok)
+
+	// flags overridden in the merged flags still answer along the chain
+	flags.FlagSetFallback = []string{"base"}
+	found, flagSet, ok = flags.Lookup(context.Background(), "banner")
+	require.True(t, ok)
+	require.Equal(t, "base", flagSet)
+	require.Equal(t, "base", found.Source)
+
+	// deleted flags no longer answer
+	flags.DeleteFlags(log, "base", map[string]model.Flag{"banner": {}})
+	_, _, ok = flags.Lookup(context.Background(), "banner")
+	require.False(t, ok)
+	flags.Merge(log, "base", "", map[string]model.Flag{})
+	_, _, ok = flags.Lookup(context.Background(), "checkout")
+	require.False(t, ok)
+}
+
+func TestFlags_Alias(t *testing.T) {
+	t.Parallel()
+	log := logger.NewLogger(nil, false)
+	flag := func(aliases string) model.Flag {
+		return model.Flag{
+			State:          "ENABLED",
+			DefaultVariant: "on",
+			Variants:       map[string]any{"on": true},
+			Metadata:       map[string]interface{}{AliasesMetadataKey: aliases},
+		}
+	}
+
+	flags := NewFlags()
+	flags.Merge(log, "a", "", map[string]model.Flag{
+		"new-banner": flag("banner, old-banner"),
+		"search":     flag(""),
+	})
+	flags.Merge(log, "b", "", map[string]model.Flag{
+		"b-checkout": flag("checkout"),
+		"a-checkout": flag("checkout"),
+	})
+
+	key, ok := flags.Alias(context.Background(), "old-banner")
+	require.True(t, ok)
+	require.Equal(t, "new-banner", key)
+	key, ok = flags.Alias(context.Background(), "banner")
+	require.True(t, ok)
+	require.Equal(t, "new-banner", key)
+	// flags declaring the same alias resolve to the lexically first key
+	key, ok = flags.Alias(context.Background(), "checkout")
+	require.True(t, ok)
+	require.Equal(t, "a-checkout", key)
+	_, ok = flags.Alias(context.Background(), "search")
+	require.False(t, ok)
+
+	// aliases follow changes of the stored flags
+	flags.Merge(log, "a", "", map[string]model.Flag{"new-banner": flag("banner")})
+	_, ok = flags.Alias(context.Background(), "old-banner")
+	require.False(t, ok)
+}
+
+func TestAliases(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []string{"a", "b"}, Aliases(model.Flag{Metadata: map[string]interface{}{
+		AliasesMetadataKey: " a,,b ",
+	}}))
+	require.Empty(t, Aliases(model.Flag{Metadata: map[string]interface{}{AliasesMetadataKey: true}}))
+	require.Empty(t, Aliases(model.Flag{}))
+}
+
+func TestFlags_Snapshot(t *testing.T) {
+	t.Parallel()
+	log := logger.NewLogger(nil, false)
+	flags := NewFlags()
+	flags.FlagSources = []string{"a"}
+	flags.FlagSetFallback = []string{"base"}
+	flags.Merge(log, "a", "", map[string]model.Flag{
+		"banner": {DefaultVariant: "on", Metadata: map[string]interface{}{FlagSetIDMetadataKey: "base"}},
+	})
+	version := flags.Version()
+
+	snapshot := flags.Snapshot()
+	flags.Merge(log, "a", "", map[string]model.Flag{
+		"banner": {DefaultVariant: "off", Metadata: map[string]interface{}{FlagSetIDMetadataKey: "base"}},
+		"search": {DefaultVariant: "on", Metadata: map[string]interface{}{FlagSetIDMetadataKey: "base"}},
+	})
+
+	// the snapshot answers lookups as the store did at the time of the snapshot
+	found, flagSet, ok := snapshot.Lookup(context.Background(), "banner")
+	require.True(t, ok)
+	require.Equal(t, "base", flagSet)
+	require.Equal(t, "on", found.DefaultVariant)
+	_, _, ok = snapshot.Lookup(context.Background(), "search")
+	require.False(t, ok)
+	require.Equal(t, version, snapshot.Version())
+	require.NotEqual(t, version, flags.Version())
+}
+
+func TestFlags_Declarations(t *testing.T) {
+	t.Parallel()
+	log := logger.NewLogger(nil, false)
+	flags := NewFlags()
+	flags.FlagSources = []string{"a", "b"}
+	flags.Merge(log, "a", "", map[string]model.Flag{"banner": {DefaultVariant: "on"}})
+	version := flags.Version()
+
+	flags.SetDeclarations("b", Declarations{"$lookups": {"tiers": json.RawMessage(`"b"`)}}, true)
+	flags.SetDeclarations("a", Declarations{"$lookups": {
+		"tiers":   json.RawMessage(`"a"`),
+		"regions": json.RawMessage(`"a"`),
+	}}, true)
+	require.NotEqual(t, version, flags.Version())
+	snapshot := flags.Snapshot()
+
+	// names declared by several sources resolve to the source of the highest priority
+	require.Equal(t, Declarations{"$lookups": {
+		"tiers":   json.RawMessage(`"b"`),
+		"regions": json.RawMessage(`"a"`),
+	}}, flags.GetDeclarations(""))
+
+	flags.SetDeclarations("a", Declarations{"$enums": {"plans": json.RawMessage(`["free"]`)}}, false)
+	require.Equal(t, Declarations{
+		"$lookups": {"tiers": json.RawMessage(`"a"`), "regions": json.RawMessage(`"a"`)},
+		"$enums":   {"plans": json.RawMessage(`["free"]`)},
+	}, flags.GetDeclarations("a"))
+	require.Len(t, snapshot.GetDeclarations("a"), 1)
+
+	flags.DeleteFlags(log, "a", map[string]model.Flag{})
+	require.Empty(t, flags.GetDeclarations("a"))
+}
+
 func TestFlags_Add(t *testing.T) {
 	mockLogger := logger.NewLogger(nil, false)
 	mockSource := "source"
@@ -24,6 +24,7 @@ type Sync struct {
 	Cron        Cron
 	Logger      *logger.Logger
 	Interval    uint32
+	Metrics     *sync.SourceMetrics
 	ready       bool
 	lastUpdated time.Time
 }
@@ -50,19 +51,26 @@ func (hs *Sync) IsReady() bool {
 }
 
 func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	defer hs.Metrics.Watch()()
 	hs.Logger.Info(fmt.Sprintf("starting sync from %s/%s with interval %ds", hs.Bucket, hs.Object, hs.Interval))
 	_ = hs.Cron.AddFunc(fmt.Sprintf("*/%d * * * *", hs.Interval), func() {
+		hs.Metrics.Attempt(ctx)
 		err := hs.sync(ctx, dataSync, false)
 		if err != nil {
+			hs.Metrics.Failure(ctx)
 			hs.Logger.Warn(fmt.Sprintf("sync failed: %v", err))
+			return
 		}
+		hs.Metrics.Success()
 	})
 	// Initial fetch
 	hs.Logger.Debug(fmt.Sprintf("initial sync of the %s/%s", hs.Bucket, hs.Object))
 	err := hs.sync(ctx, dataSync, false)
 	if err != nil {
+		hs.Metrics.Failure(ctx)
 		return err
 	}
+	hs.Metrics.Success()
 
 	hs.ready = true
 	hs.Cron.Start()
@@ -104,6 +112,7 @@ func (hs *Sync) sync(ctx context.Context, dataSync chan<- sync.DataSync, skipChe
 	if !skipCheckingModTime {
 		hs.lastUpdated = updated
 	}
+	hs.Metrics.Retain(msg)
 	dataSync <- sync.DataSync{FlagData: msg, Source: hs.Bucket + hs.Object, Type: sync.ALL}
 	return nil
 }
@@ -0,0 +1,47 @@
+package builder
+
+import (
+	"context"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// lenientSync wraps a sync source configured to ignore unknown fields, marking its data syncs so that unknown fields
+// of their configurations are ignored instead of rejected
+type lenientSync struct {
+	sync.ISync
+}
+
+func (s *lenientSync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return s.forward(ctx, dataSync, s.ISync.Sync)
+}
+
+func (s *lenientSync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return s.forward(ctx, dataSync, s.ISync.ReSync)
+}
+
+// forward runs a sync operation of the wrapped source, passing its marked data syncs on until the operation returns
+func (s *lenientSync) forward(
+	ctx context.Context,
+	dataSync chan<- sync.DataSync,
+	operation func(context.Context, chan<- sync.DataSync) error,
+) error {
+	unmarked := make(chan sync.DataSync)
+	done := make(chan error, 1)
+	go func() {
+		done <- operation(ctx, unmarked)
+	}()
+
+	for {
+		select {
+		case data := <-unmarked:
+			data.IgnoreUnknownFields = true
+			select {
+			case dataSync <- data:
+			case <-ctx.Done():
+			}
+		case err := <-done:
+			return err
+		}
+	}
+}
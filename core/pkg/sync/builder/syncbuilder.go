@@ -1,6 +1,7 @@
 package builder
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,11 +11,14 @@ import (
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
 	blobSync "github.com/open-feature/flagd/core/pkg/sync/blob"
+	"github.com/open-feature/flagd/core/pkg/sync/circuitbreaker"
 	"github.com/open-feature/flagd/core/pkg/sync/file"
+	"github.com/open-feature/flagd/core/pkg/sync/filter"
 	"github.com/open-feature/flagd/core/pkg/sync/grpc"
 	"github.com/open-feature/flagd/core/pkg/sync/grpc/credentials"
 	httpSync "github.com/open-feature/flagd/core/pkg/sync/http"
 	"github.com/open-feature/flagd/core/pkg/sync/kubernetes"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/robfig/cron"
 	"go.uber.org/zap"
 	"gocloud.dev/blob"
@@ -33,6 +37,10 @@ const (
 	syncProviderGcs        = "gcs"
 	syncProviderAzblob     = "azblob"
 	syncProviderS3         = "s3"
+
+	// circuit breaker defaults of polling sync providers
+	defaultCircuitBreakerThreshold = 5
+	defaultCircuitBreakerCoolDown  = 60
 )
 
 var (
@@ -66,12 +74,33 @@ type ISyncBuilder interface {
 
 type SyncBuilder struct {
 	k8sClientBuilder IK8sClientBuilder
+	metrics          telemetry.IMetricsRecorder
+	// sources holds the metrics of the built sync sources, whose states are reported by the sync source gauges
+	sources []*sync.SourceMetrics
+}
+
+type SyncBuilderOption func(sb *SyncBuilder)
+
+// WithMetricsRecorder sets the recorder used by sync providers to report their telemetry
+func WithMetricsRecorder(recorder telemetry.IMetricsRecorder) SyncBuilderOption {
+	return func(sb *SyncBuilder) {
+		if recorder != nil {
+			sb.metrics = recorder
+		}
+	}
 }
 
-func NewSyncBuilder() *SyncBuilder {
-	return &SyncBuilder{
+func NewSyncBuilder(opts ...SyncBuilderOption) *SyncBuilder {
+	sb := &SyncBuilder{
 		k8sClientBuilder: &KubernetesClientBuilder{},
+		metrics:          &telemetry.NoopMetricsRecorder{},
 	}
+
+	for _, o := range opts {
+		o(sb)
+	}
+
+	return sb
 }
 
 func (sb *SyncBuilder) SyncFromURI(uri string, logger *logger.Logger) (sync.ISync, error) {
@@ -92,11 +121,65 @@ func (sb *SyncBuilder) SyncsFromConfig(sourceConfigs []sync.SourceConfig, logger
 		if err != nil {
 			return nil, fmt.Errorf("could not create sync provider: %w", err)
 		}
+		syncImpl, err = sb.withFilter(syncImpl, syncProvider, logger)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sync provider: %w", err)
+		}
+		if syncProvider.IgnoreUnknownFields {
+			syncImpl = &lenientSync{ISync: syncImpl}
+		}
 		syncImpls[i] = syncImpl
 	}
+
+	sources := sb.sources
+	metrics := telemetry.SyncMetrics(sb.metrics)
+	metrics.SyncSources(int64(len(syncImpls)), func() int64 {
+		return sync.CountActive(sources)
+	})
+	metrics.SyncSourcesUsage(func() []telemetry.SyncSourceUsage {
+		return sync.Usage(sources)
+	})
+	metrics.SyncStaleness(sb.Staleness)
 	return syncImpls, nil
 }
 
+// Staleness returns the duration since all sync sources built by the builder were lost, zero while any source is
+// active
+func (sb *SyncBuilder) Staleness() time.Duration {
+	return sync.Staleness(sb.sources, time.Now())
+}
+
+// newSourceMetrics creates the metrics of a sync source, tracking its state for the sync source gauges
+func (sb *SyncBuilder) newSourceMetrics(source string) *sync.SourceMetrics {
+	metrics := sync.NewSourceMetrics(sb.metrics, source)
+	sb.sources = append(sb.sources, metrics)
+	return metrics
+}
+
+// withFilter wraps a sync source scoping its flags, if the source configures a flag key filter
+func (sb *SyncBuilder) withFilter(
+	syncImpl sync.ISync, config sync.SourceConfig, logger *logger.Logger,
+) (sync.ISync, error) {
+	if len(config.IncludeFlags) == 0 && len(config.ExcludeFlags) == 0 {
+		return syncImpl, nil
+	}
+
+	flagFilter, err := filter.New(config.IncludeFlags, config.ExcludeFlags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid flag filter of %s: %w", config.URI, err)
+	}
+	return &filter.Sync{
+		ISync:  syncImpl,
+		Filter: flagFilter,
+		URI:    config.URI,
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", "filter"),
+		),
+		Metrics: sb.metrics,
+	}, nil
+}
+
 func (sb *SyncBuilder) syncFromConfig(sourceConfig sync.SourceConfig, logger *logger.Logger) (sync.ISync, error) {
 	switch sourceConfig.Provider {
 	case syncProviderFile:
@@ -115,7 +198,7 @@ func (sb *SyncBuilder) syncFromConfig(sourceConfig sync.SourceConfig, logger *lo
 		return sb.newHTTP(sourceConfig, logger), nil
 	case syncProviderGrpc:
 		logger.Debug(fmt.Sprintf("using grpc sync-provider for: %s", sourceConfig.URI))
-		return sb.newGRPC(sourceConfig, logger), nil
+		return sb.newGRPC(sourceConfig, logger)
 	case syncProviderGcs:
 		logger.Debug(fmt.Sprintf("using blob sync-provider with gcs driver for: %s", sourceConfig.URI))
 		return sb.newGcs(sourceConfig, logger), nil
@@ -148,7 +231,7 @@ func (sb *SyncBuilder) newFile(uri string, logger *logger.Logger) *file.Sync {
 
 // return a new file.Sync that uses fsnotify under the hood
 func (sb *SyncBuilder) newFsNotify(uri string, logger *logger.Logger) *file.Sync {
-	return file.NewFileSync(
+	fileSync := file.NewFileSync(
 		regFile.ReplaceAllString(uri, ""),
 		file.FSNOTIFY,
 		logger.WithFields(
@@ -156,11 +239,13 @@ func (sb *SyncBuilder) newFsNotify(uri string, logger *logger.Logger) *file.Sync
 			zap.String("sync", syncProviderFsNotify),
 		),
 	)
+	fileSync.Metrics = sb.newSourceMetrics(fileSync.URI)
+	return fileSync
 }
 
 // return a new file.Sync that uses os.Stat/fs.FileInfo under the hood
 func (sb *SyncBuilder) newFileInfo(uri string, logger *logger.Logger) *file.Sync {
-	return file.NewFileSync(
+	fileSync := file.NewFileSync(
 		regFile.ReplaceAllString(uri, ""),
 		file.FILEINFO,
 		logger.WithFields(
@@ -168,6 +253,8 @@ func (sb *SyncBuilder) newFileInfo(uri string, logger *logger.Logger) *file.Sync
 			zap.String("sync", syncProviderFileInfo),
 		),
 	)
+	fileSync.Metrics = sb.newSourceMetrics(fileSync.URI)
+	return fileSync
 }
 
 func (sb *SyncBuilder) newK8s(uri string, logger *logger.Logger) (*kubernetes.Sync, error) {
@@ -176,14 +263,16 @@ func (sb *SyncBuilder) newK8s(uri string, logger *logger.Logger) (*kubernetes.Sy
 		return nil, fmt.Errorf("error creating kubernetes clients: %w", err)
 	}
 
-	return kubernetes.NewK8sSync(
+	k8sSync := kubernetes.NewK8sSync(
 		logger.WithFields(
 			zap.String("component", "sync"),
 			zap.String("sync", "kubernetes"),
 		),
 		regCrd.ReplaceAllString(uri, ""),
 		dynamicClient,
-	), nil
+	)
+	k8sSync.Metrics = sb.newSourceMetrics(uri)
+	return k8sSync, nil
 }
 
 func (sb *SyncBuilder) newHTTP(config sync.SourceConfig, logger *logger.Logger) *httpSync.Sync {
@@ -193,23 +282,65 @@ func (sb *SyncBuilder) newHTTP(config sync.SourceConfig, logger *logger.Logger)
 		interval = config.Interval
 	}
 
+	syncLogger := logger.WithFields(
+		zap.String("component", "sync"),
+		zap.String("sync", "remote"),
+	)
+
 	return &httpSync.Sync{
 		URI: config.URI,
 		Client: &http.Client{
 			Timeout: time.Second * 10,
 		},
-		Logger: logger.WithFields(
-			zap.String("component", "sync"),
-			zap.String("sync", "remote"),
-		),
+		Logger:      syncLogger,
 		BearerToken: config.BearerToken,
 		AuthHeader:  config.AuthHeader,
 		Interval:    interval,
 		Cron:        cron.New(),
+		Breaker:     sb.newCircuitBreaker(config, syncLogger),
+		Metrics:     sb.newSourceMetrics(config.URI),
 	}
 }
 
-func (sb *SyncBuilder) newGRPC(config sync.SourceConfig, logger *logger.Logger) *grpc.Sync {
+// newCircuitBreaker derives the circuit breaker of a polling sync provider, reporting its state transitions
+func (sb *SyncBuilder) newCircuitBreaker(
+	config sync.SourceConfig, logger *logger.Logger,
+) *circuitbreaker.CircuitBreaker {
+	threshold := defaultCircuitBreakerThreshold
+	if config.CircuitBreakerThreshold != 0 {
+		threshold = config.CircuitBreakerThreshold
+	}
+
+	var coolDown uint32 = defaultCircuitBreakerCoolDown
+	if config.CircuitBreakerCoolDown != 0 {
+		coolDown = config.CircuitBreakerCoolDown
+	}
+
+	metrics := telemetry.SyncMetrics(sb.metrics)
+	metrics.SyncCircuitBreakerState(context.Background(), config.URI, int64(circuitbreaker.Closed))
+
+	return circuitbreaker.New(threshold, time.Duration(coolDown)*time.Second,
+		circuitbreaker.WithStateChangeHandler(func(state circuitbreaker.State) {
+			switch state {
+			case circuitbreaker.Open:
+				logger.Warn(fmt.Sprintf("circuit breaker opened for %s, pausing fetches for %d seconds "+
+					"and serving the last known configuration", config.URI, coolDown))
+			case circuitbreaker.HalfOpen:
+				logger.Info(fmt.Sprintf("circuit breaker half-open for %s, probing the source", config.URI))
+			case circuitbreaker.Closed:
+				logger.Info(fmt.Sprintf("circuit breaker closed for %s, source recovered", config.URI))
+			}
+			metrics.SyncCircuitBreakerState(context.Background(), config.URI, int64(state))
+		}),
+	)
+}
+
+func (sb *SyncBuilder) newGRPC(config sync.SourceConfig, logger *logger.Logger) (*grpc.Sync, error) {
+	tokenProvider, err := credentials.NewTokenProvider(config.TokenCommand, config.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid grpc sync source %s: %w", config.URI, err)
+	}
+
 	return &grpc.Sync{
 		URI: config.URI,
 		Logger: logger.WithFields(
@@ -222,7 +353,9 @@ func (sb *SyncBuilder) newGRPC(config sync.SourceConfig, logger *logger.Logger)
 		Secure:            config.TLS,
 		Selector:          config.Selector,
 		MaxMsgSize:        config.MaxMsgSize,
-	}
+		TokenProvider:     tokenProvider,
+		Metrics:           sb.newSourceMetrics(config.URI),
+	}, nil
 }
 
 func (sb *SyncBuilder) newGcs(config sync.SourceConfig, logger *logger.Logger) *blobSync.Sync {
@@ -250,6 +383,7 @@ func (sb *SyncBuilder) newGcs(config sync.SourceConfig, logger *logger.Logger) *
 		),
 		Interval: interval,
 		Cron:     cron.New(),
+		Metrics:  sb.newSourceMetrics(config.URI),
 	}
 }
 
@@ -287,6 +421,7 @@ func (sb *SyncBuilder) newAzblob(config sync.SourceConfig, logger *logger.Logger
 		),
 		Interval: interval,
 		Cron:     cron.New(),
+		Metrics:  sb.newSourceMetrics(config.URI),
 	}, nil
 }
 
@@ -315,6 +450,7 @@ func (sb *SyncBuilder) newS3(config sync.SourceConfig, logger *logger.Logger) *b
 		),
 		Interval: interval,
 		Cron:     cron.New(),
+		Metrics:  sb.newSourceMetrics(config.URI),
 	}
 }
 
@@ -1,6 +1,7 @@
 package builder
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -9,9 +10,11 @@ import (
 	"github.com/open-feature/flagd/core/pkg/sync/blob"
 	buildermock "github.com/open-feature/flagd/core/pkg/sync/builder/mock"
 	"github.com/open-feature/flagd/core/pkg/sync/file"
+	"github.com/open-feature/flagd/core/pkg/sync/filter"
 	"github.com/open-feature/flagd/core/pkg/sync/grpc"
 	"github.com/open-feature/flagd/core/pkg/sync/http"
 	"github.com/open-feature/flagd/core/pkg/sync/kubernetes"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
@@ -194,6 +197,39 @@ func Test_SyncsFromFromConfig(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "grpc-with-token-file",
+			args: args{
+				logger: lg,
+				sources: []sync.SourceConfig{
+					{
+						URI:       "grpc://host:port",
+						Provider:  syncProviderGrpc,
+						TokenFile: "/var/run/secrets/token",
+					},
+				},
+			},
+			wantSyncs: []sync.ISync{
+				&grpc.Sync{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "grpc-with-token-command-and-file",
+			args: args{
+				logger: lg,
+				sources: []sync.SourceConfig{
+					{
+						URI:          "grpc://host:port",
+						Provider:     syncProviderGrpc,
+						TokenCommand: []string{"token-agent", "print"},
+						TokenFile:    "/var/run/secrets/token",
+					},
+				},
+			},
+			wantSyncs: nil,
+			wantErr:   true,
+		},
 		{
 			name: "combined",
 			injectFunc: func(builder *SyncBuilder) {
@@ -283,6 +319,94 @@ func Test_SyncsFromFromConfig(t *testing.T) {
 	}
 }
 
+// sourcesRecorder captures the reported sync sources
+type sourcesRecorder struct {
+	telemetry.NoopMetricsRecorder
+	configured int64
+	active     func() int64
+}
+
+func (r *sourcesRecorder) SyncSources(configured int64, active func() int64) {
+	r.configured = configured
+	r.active = active
+}
+
+func Test_SyncsFromConfig_SyncSources(t *testing.T) {
+	recorder := &sourcesRecorder{}
+	sb := NewSyncBuilder(WithMetricsRecorder(recorder))
+
+	syncs, err := sb.SyncsFromConfig([]sync.SourceConfig{
+		{URI: "https://host:port", Provider: syncProviderHTTP},
+		{URI: "/tmp/flags.json", Provider: syncProviderFile},
+	}, logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(2), recorder.configured)
+	require.Equal(t, int64(0), recorder.active())
+
+	// the active count follows the fetch health of the sources
+	syncs[0].(*http.Sync).Metrics.Success()
+	require.Equal(t, int64(1), recorder.active())
+	syncs[1].(*file.Sync).Metrics.Success()
+	require.Equal(t, int64(2), recorder.active())
+	syncs[0].(*http.Sync).Metrics.Failure(context.Background())
+	require.Equal(t, int64(1), recorder.active())
+}
+
+func Test_SyncsFromConfig_Filter(t *testing.T) {
+	sb := NewSyncBuilder()
+
+	syncs, err := sb.SyncsFromConfig([]sync.SourceConfig{
+		{URI: "https://host:port", Provider: syncProviderHTTP, IncludeFlags: []string{"team-a."}},
+		{URI: "/tmp/flags.json", Provider: syncProviderFile},
+	}, logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	filtered, ok := syncs[0].(*filter.Sync)
+	require.True(t, ok, "sources with flag key filters are wrapped")
+	require.IsType(t, &http.Sync{}, filtered.ISync)
+	require.IsType(t, &file.Sync{}, syncs[1])
+
+	_, err = sb.SyncsFromConfig([]sync.SourceConfig{
+		{URI: "https://host:port", Provider: syncProviderHTTP, ExcludeFlags: []string{"/[/"}},
+	}, logger.NewLogger(nil, false))
+	require.Error(t, err)
+}
+
+func Test_SyncsFromConfig_IgnoreUnknownFields(t *testing.T) {
+	sb := NewSyncBuilder()
+
+	syncs, err := sb.SyncsFromConfig([]sync.SourceConfig{
+		{URI: "https://host:port", Provider: syncProviderHTTP, IgnoreUnknownFields: true},
+		{URI: "/tmp/flags.json", Provider: syncProviderFile},
+	}, logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	lenient, ok := syncs[0].(*lenientSync)
+	require.True(t, ok, "sources ignoring unknown fields are wrapped")
+	require.IsType(t, &http.Sync{}, lenient.ISync)
+	require.IsType(t, &file.Sync{}, syncs[1])
+}
+
+func Test_lenientSync(t *testing.T) {
+	lenient := &lenientSync{ISync: &staticSync{data: sync.DataSync{FlagData: "{}", Source: "static"}}}
+
+	dataSync := make(chan sync.DataSync, 1)
+	require.NoError(t, lenient.Sync(context.Background(), dataSync))
+	require.Equal(t, sync.DataSync{FlagData: "{}", Source: "static", IgnoreUnknownFields: true}, <-dataSync)
+}
+
+// staticSync emits a single data sync
+type staticSync struct {
+	sync.ISync
+	data sync.DataSync
+}
+
+func (s *staticSync) Sync(_ context.Context, dataSync chan<- sync.DataSync) error {
+	dataSync <- s.data
+	return nil
+}
+
 func Test_GcsConfig(t *testing.T) {
 	lg := logger.NewLogger(nil, false)
 	defaultInterval := uint32(5)
@@ -4,10 +4,49 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
 
 	"github.com/open-feature/flagd/core/pkg/sync"
 )
 
+// regEnvReference matches the ${NAME} references to environment variables of sync URIs
+var regEnvReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces the ${NAME} references of a sync URI by the values of the environment variables. It fails if
+// any referenced variable is unset, variables set to an empty value are expanded to the empty string.
+func expandEnv(uri string) (string, error) {
+	var unset []string
+	expanded := regEnvReference.ReplaceAllStringFunc(uri, func(reference string) string {
+		name := regEnvReference.FindStringSubmatch(reference)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			unset = append(unset, name)
+		}
+		return value
+	})
+	if len(unset) > 0 {
+		return "", fmt.Errorf("sync uri %s references unset environment variables: %s", uri, strings.Join(unset, ", "))
+	}
+	return expanded, nil
+}
+
+// ExpandSourceURIs replaces the ${NAME} references to environment variables of the URIs of the given sources, so that
+// a single source configuration can be shared across environments
+func ExpandSourceURIs(sources []sync.SourceConfig) ([]sync.SourceConfig, error) {
+	expanded := make([]sync.SourceConfig, 0, len(sources))
+	for _, source := range sources {
+		uri, err := expandEnv(source.URI)
+		if err != nil {
+			return nil, err
+		}
+		source.URI = uri
+		expanded = append(expanded, source)
+	}
+	return expanded, nil
+}
+
 // ParseSources parse a json formatted SourceConfig array string and performs validations on the content
 func ParseSources(sourcesFlag string) ([]sync.SourceConfig, error) {
 	syncProvidersParsed := []sync.SourceConfig{}
@@ -32,11 +71,15 @@ func ParseSources(sourcesFlag string) ([]sync.SourceConfig, error) {
 }
 
 // ParseSyncProviderURIs uri flag based sync sources to SourceConfig array. Replaces uri prefixes where necessary to
-// derive SourceConfig
+// derive SourceConfig. References to environment variables are expanded beforehand, see ExpandSourceURIs.
 func ParseSyncProviderURIs(uris []string) ([]sync.SourceConfig, error) {
 	syncProvidersParsed := []sync.SourceConfig{}
 
 	for _, uri := range uris {
+		uri, err := expandEnv(uri)
+		if err != nil {
+			return syncProvidersParsed, err
+		}
 		switch uriB := []byte(uri); {
 		case regFile.Match(uriB):
 			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
@@ -86,7 +129,7 @@ func ParseSyncProviderURIs(uris []string) ([]sync.SourceConfig, error) {
 			})
 		default:
 			return syncProvidersParsed, fmt.Errorf("invalid sync uri argument: %s, must start with 'file:', "+
-				"'http(s)://', 'grpc(s)://', 'gs://', 'azblob://' or 'core.openfeature.dev'", uri)
+				"'http(s)://', 'grpc(s)://', 'unix://', 'gs://', 'azblob://' or 'core.openfeature.dev'", uri)
 		}
 	}
 	return syncProvidersParsed, nil
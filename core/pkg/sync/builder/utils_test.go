@@ -2,6 +2,7 @@ package builder
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/open-feature/flagd/core/pkg/sync"
@@ -239,6 +240,18 @@ func TestParseSyncProviderURIs(t *testing.T) {
 				},
 			},
 		},
+		"unix-socket": {
+			in: []string{
+				"unix:///var/run/flagd/sync.sock",
+			},
+			expectErr: false,
+			out: []sync.SourceConfig{
+				{
+					URI:      "unix:///var/run/flagd/sync.sock",
+					Provider: "grpc",
+				},
+			},
+		},
 		"empty": {
 			in:        []string{},
 			expectErr: false,
@@ -267,3 +280,50 @@ func TestParseSyncProviderURIs(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSyncProviderURIs_Env(t *testing.T) {
+	t.Setenv("FLAGD_TEST_SYNC_HOST", "sync.prod")
+	t.Setenv("FLAGD_TEST_EMPTY", "")
+
+	out, err := ParseSyncProviderURIs([]string{
+		"grpc://${FLAGD_TEST_SYNC_HOST}:8015",
+		"file:/flags${FLAGD_TEST_EMPTY}/$HOME.json",
+	})
+	if err != nil {
+		t.Fatalf("did not expect error: %s", err.Error())
+	}
+	expected := []sync.SourceConfig{
+		{URI: "sync.prod:8015", Provider: syncProviderGrpc},
+		// only braced references are expanded
+		{URI: "/flags/$HOME.json", Provider: syncProviderFile},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected output, expected %v, got %v", expected, out)
+	}
+
+	_, err = ParseSyncProviderURIs([]string{"grpc://${FLAGD_TEST_UNSET_HOST}:${FLAGD_TEST_UNSET_PORT}"})
+	if err == nil || !strings.Contains(err.Error(), "FLAGD_TEST_UNSET_HOST, FLAGD_TEST_UNSET_PORT") {
+		t.Errorf("expected error naming the unset variables, got %v", err)
+	}
+}
+
+func TestExpandSourceURIs(t *testing.T) {
+	t.Setenv("FLAGD_TEST_BUCKET", "flags-staging")
+
+	out, err := ExpandSourceURIs([]sync.SourceConfig{
+		{URI: "gs://${FLAGD_TEST_BUCKET}/flags.json", Provider: syncProviderGcs, Interval: 10},
+	})
+	if err != nil {
+		t.Fatalf("did not expect error: %s", err.Error())
+	}
+	expected := []sync.SourceConfig{
+		{URI: "gs://flags-staging/flags.json", Provider: syncProviderGcs, Interval: 10},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("unexpected output, expected %v, got %v", expected, out)
+	}
+
+	if _, err := ExpandSourceURIs([]sync.SourceConfig{{URI: "gs://${FLAGD_TEST_UNSET_BUCKET}/flags.json"}}); err == nil {
+		t.Error("expected error, got none")
+	}
+}
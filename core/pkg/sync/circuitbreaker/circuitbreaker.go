@@ -0,0 +1,181 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State of a CircuitBreaker
+type State int64
+
+const (
+	// Closed - calls are allowed and failures are counted
+	Closed State = iota
+	// Open - calls are rejected until the cool-down elapsed
+	Open
+	// HalfOpen - a single trial call is allowed to probe whether the source recovered
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker guards calls to a flapping dependency. It opens after a number of consecutive failures, rejects
+// calls for a cool-down period and then half-opens to let a single trial call through. A successful trial closes the
+// breaker while a failed one re-opens it.
+// A nil CircuitBreaker always allows calls, which keeps the breaker optional for its users.
+type CircuitBreaker struct {
+	mx sync.Mutex
+
+	threshold     int
+	coolDown      time.Duration
+	onStateChange func(State)
+	now           func() time.Time
+
+	state    State
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+type Option func(cb *CircuitBreaker)
+
+// WithStateChangeHandler registers a callback invoked on every state transition. The callback runs while the breaker
+// is locked, hence it must not call back into the breaker.
+func WithStateChangeHandler(handler func(State)) Option {
+	return func(cb *CircuitBreaker) {
+		cb.onStateChange = handler
+	}
+}
+
+// WithClock overrides the time source of the breaker, mainly useful for testing
+func WithClock(now func() time.Time) Option {
+	return func(cb *CircuitBreaker) {
+		cb.now = now
+	}
+}
+
+// New creates a closed CircuitBreaker opening after threshold consecutive failures. A threshold lower than 1 disables
+// the breaker, in which case calls are always allowed.
+func New(threshold int, coolDown time.Duration, opts ...Option) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		threshold: threshold,
+		coolDown:  coolDown,
+		now:       time.Now,
+		state:     Closed,
+	}
+
+	for _, o := range opts {
+		o(cb)
+	}
+
+	return cb
+}
+
+// Allow reports whether a call may be attempted. Once the cool-down of an open breaker elapsed, the breaker moves to
+// half-open and allows exactly one trial call until its outcome is reported through Success or Failure.
+func (cb *CircuitBreaker) Allow() bool {
+	if cb == nil {
+		return true
+	}
+
+	cb.mx.Lock()
+	defer cb.mx.Unlock()
+
+	switch cb.state {
+	case Open:
+		if cb.now().Sub(cb.openedAt) < cb.coolDown {
+			return false
+		}
+		cb.transition(HalfOpen)
+		cb.trial = true
+		return true
+	case HalfOpen:
+		if cb.trial {
+			return false
+		}
+		cb.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success reports a successful call and closes the breaker
+func (cb *CircuitBreaker) Success() {
+	if cb == nil {
+		return
+	}
+
+	cb.mx.Lock()
+	defer cb.mx.Unlock()
+
+	cb.failures = 0
+	cb.trial = false
+	if cb.state != Closed {
+		cb.transition(Closed)
+	}
+}
+
+// Failure reports a failed call. The breaker opens when the threshold of consecutive failures is reached or when the
+// half-open trial call failed.
+func (cb *CircuitBreaker) Failure() {
+	if cb == nil {
+		return
+	}
+
+	cb.mx.Lock()
+	defer cb.mx.Unlock()
+
+	if cb.threshold < 1 {
+		return
+	}
+
+	cb.failures++
+	cb.trial = false
+
+	switch cb.state {
+	case HalfOpen:
+		cb.open()
+	case Closed:
+		if cb.failures >= cb.threshold {
+			cb.open()
+		}
+	case Open:
+		// already open, nothing to do
+	}
+}
+
+// State returns the current state of the breaker
+func (cb *CircuitBreaker) State() State {
+	if cb == nil {
+		return Closed
+	}
+
+	cb.mx.Lock()
+	defer cb.mx.Unlock()
+
+	return cb.state
+}
+
+func (cb *CircuitBreaker) open() {
+	cb.openedAt = cb.now()
+	cb.transition(Open)
+}
+
+func (cb *CircuitBreaker) transition(to State) {
+	cb.state = to
+	if cb.onStateChange != nil {
+		cb.onStateChange(to)
+	}
+}
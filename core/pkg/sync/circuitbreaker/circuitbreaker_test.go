@@ -0,0 +1,120 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var transitions []State
+	cb := New(3, time.Minute, WithClock(clock.Now), WithStateChangeHandler(func(s State) {
+		transitions = append(transitions, s)
+	}))
+
+	for i := 0; i < 2; i++ {
+		require.True(t, cb.Allow())
+		cb.Failure()
+		require.Equal(t, Closed, cb.State())
+	}
+
+	require.True(t, cb.Allow())
+	cb.Failure()
+	require.Equal(t, Open, cb.State())
+	require.False(t, cb.Allow(), "open breaker must reject calls")
+	require.Equal(t, []State{Open}, transitions)
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	cb := New(2, time.Minute)
+
+	cb.Failure()
+	cb.Success()
+	cb.Failure()
+
+	require.Equal(t, Closed, cb.State(), "failures must be consecutive to open the breaker")
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	tests := map[string]struct {
+		trialSucceeds bool
+		expectedState State
+	}{
+		"successful trial closes the breaker": {
+			trialSucceeds: true,
+			expectedState: Closed,
+		},
+		"failed trial re-opens the breaker": {
+			trialSucceeds: false,
+			expectedState: Open,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			cb := New(1, time.Minute, WithClock(clock.Now))
+
+			cb.Failure()
+			require.Equal(t, Open, cb.State())
+
+			clock.Advance(30 * time.Second)
+			require.False(t, cb.Allow(), "breaker must stay open during the cool-down")
+
+			clock.Advance(30 * time.Second)
+			require.True(t, cb.Allow(), "breaker must allow a trial once the cool-down elapsed")
+			require.Equal(t, HalfOpen, cb.State())
+			require.False(t, cb.Allow(), "only a single trial call is allowed while half-open")
+
+			if tt.trialSucceeds {
+				cb.Success()
+			} else {
+				cb.Failure()
+			}
+
+			require.Equal(t, tt.expectedState, cb.State())
+		})
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	cb := New(0, time.Minute)
+
+	for i := 0; i < 10; i++ {
+		cb.Failure()
+	}
+
+	require.True(t, cb.Allow())
+	require.Equal(t, Closed, cb.State())
+}
+
+func TestCircuitBreaker_Nil(t *testing.T) {
+	var cb *CircuitBreaker
+
+	cb.Failure()
+	cb.Success()
+
+	require.True(t, cb.Allow())
+	require.Equal(t, Closed, cb.State())
+}
+
+func TestState_String(t *testing.T) {
+	require.Equal(t, "closed", Closed.String())
+	require.Equal(t, "open", Open.String())
+	require.Equal(t, "half-open", HalfOpen.String())
+	require.Equal(t, "unknown", State(42).String())
+}
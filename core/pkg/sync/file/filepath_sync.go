@@ -37,6 +37,8 @@ type Sync struct {
 	watcher   Watcher
 	ready     bool
 	Mux       *msync.RWMutex
+	// Metrics reports the read health of the file, optional
+	Metrics *sync.SourceMetrics
 }
 
 func NewFileSync(uri string, watchType string, logger *logger.Logger) *Sync {
@@ -93,6 +95,7 @@ func (fs *Sync) setReady(val bool) {
 
 //nolint:funlen
 func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	defer fs.Metrics.Watch()()
 	defer fs.watcher.Close()
 	fs.sendDataSync(ctx, sync.ALL, dataSync)
 	fs.setReady(true)
@@ -152,14 +155,19 @@ func (fs *Sync) sendDataSync(ctx context.Context, syncType sync.Type, dataSync c
 
 	if syncType == sync.DELETE {
 		// Skip fetching and emit default state to avoid EOF errors
+		fs.Metrics.Retain(defaultState)
 		dataSync <- sync.DataSync{FlagData: defaultState, Source: fs.URI, Type: syncType}
 		return
 	}
 
 	msg := defaultState
+	fs.Metrics.Attempt(ctx)
 	m, err := fs.fetch(ctx)
 	if err != nil {
 		fs.Logger.Error(fmt.Sprintf("Error fetching %s: %s", fs.URI, err.Error()))
+		fs.Metrics.Failure(ctx)
+	} else {
+		fs.Metrics.Success()
 	}
 	if m == "" {
 		fs.Logger.Warn(fmt.Sprintf("file %s is empty", fs.URI))
@@ -167,6 +175,7 @@ func (fs *Sync) sendDataSync(ctx context.Context, syncType sync.Type, dataSync c
 		msg = m
 	}
 
+	fs.Metrics.Retain(msg)
 	dataSync <- sync.DataSync{FlagData: msg, Source: fs.URI, Type: syncType}
 }
 
@@ -0,0 +1,165 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+const flagsKey = "flags"
+
+// Filter scopes the flags contributed by a sync source by their keys. Patterns are prefixes of flag keys, unless they
+// are enclosed in slashes, e.g. "/^team-a\./", in which case they are regular expressions.
+type Filter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// New compiles the include and exclude patterns of a filter. A flag is kept if it matches any include pattern, or if
+// there is none, and matches no exclude pattern.
+func New(include []string, exclude []string) (*Filter, error) {
+	includeRegs, err := compile(include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	excludeRegs, err := compile(exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	return &Filter{include: includeRegs, exclude: excludeRegs}, nil
+}
+
+func compile(patterns []string) ([]*regexp.Regexp, error) {
+	regs := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		expr := "^" + regexp.QuoteMeta(pattern)
+		if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			expr = pattern[1 : len(pattern)-1]
+		}
+		reg, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pattern, err)
+		}
+		regs = append(regs, reg)
+	}
+	return regs, nil
+}
+
+// Keep reports whether the flag with the given key passes the filter
+func (f *Filter) Keep(key string) bool {
+	if len(f.include) > 0 && !matchAny(f.include, key) {
+		return false
+	}
+	return !matchAny(f.exclude, key)
+}
+
+func matchAny(regs []*regexp.Regexp, key string) bool {
+	for _, reg := range regs {
+		if reg.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply removes the flags not passing the filter from a flag configuration, returning the filtered configuration and
+// the sorted keys of the removed flags. Configurations which are not valid JSON are returned unchanged, so that they
+// are rejected when applied.
+func (f *Filter) Apply(flagData string) (string, []string) {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(flagData), &config); err != nil {
+		return flagData, nil
+	}
+	var flags map[string]json.RawMessage
+	if err := json.Unmarshal(config[flagsKey], &flags); err != nil {
+		return flagData, nil
+	}
+
+	var removed []string
+	for key := range flags {
+		if !f.Keep(key) {
+			delete(flags, key)
+			removed = append(removed, key)
+		}
+	}
+	if len(removed) == 0 {
+		return flagData, nil
+	}
+	sort.Strings(removed)
+
+	filteredFlags, err := json.Marshal(flags)
+	if err != nil {
+		return flagData, nil
+	}
+	config[flagsKey] = filteredFlags
+	filtered, err := json.Marshal(config)
+	if err != nil {
+		return flagData, nil
+	}
+	return string(filtered), removed
+}
+
+// Sync wraps a sync source, filtering the flags of its data syncs after they are fetched and before they are merged
+// into the store
+type Sync struct {
+	sync.ISync
+	Filter  *Filter
+	URI     string
+	Logger  *logger.Logger
+	Metrics telemetry.IMetricsRecorder
+}
+
+func (s *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return s.forward(ctx, dataSync, s.ISync.Sync)
+}
+
+func (s *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return s.forward(ctx, dataSync, s.ISync.ReSync)
+}
+
+// forward runs a sync operation of the wrapped source, passing its filtered data syncs on until the operation returns
+func (s *Sync) forward(
+	ctx context.Context,
+	dataSync chan<- sync.DataSync,
+	operation func(context.Context, chan<- sync.DataSync) error,
+) error {
+	unfiltered := make(chan sync.DataSync)
+	done := make(chan error, 1)
+	go func() {
+		done <- operation(ctx, unfiltered)
+	}()
+
+	for {
+		select {
+		case data := <-unfiltered:
+			select {
+			case dataSync <- s.apply(ctx, data):
+			case <-ctx.Done():
+			}
+		case err := <-done:
+			return err
+		}
+	}
+}
+
+func (s *Sync) apply(ctx context.Context, data sync.DataSync) sync.DataSync {
+	filtered, removed := s.Filter.Apply(data.FlagData)
+	if len(removed) == 0 {
+		return data
+	}
+
+	s.Logger.Debug(fmt.Sprintf("filtered out %d flags of source %s: %s", len(removed), s.URI,
+		strings.Join(removed, ", ")))
+	if s.Metrics != nil {
+		telemetry.SyncMetrics(s.Metrics).SyncFlagsFiltered(ctx, s.URI, int64(len(removed)))
+	}
+	data.FlagData = filtered
+	return data
+}
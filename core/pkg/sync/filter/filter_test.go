@@ -0,0 +1,174 @@
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_Keep(t *testing.T) {
+	tests := map[string]struct {
+		include []string
+		exclude []string
+		kept    []string
+		removed []string
+	}{
+		"no patterns keep all flags": {
+			kept: []string{"team-a.banner", "team-b.banner"},
+		},
+		"include prefix": {
+			include: []string{"team-a."},
+			kept:    []string{"team-a.banner"},
+			removed: []string{"team-b.banner", "banner.team-a."},
+		},
+		"include regular expression": {
+			include: []string{"/-(beta|alpha)$/"},
+			kept:    []string{"checkout-beta", "search-alpha"},
+			removed: []string{"checkout"},
+		},
+		"exclude takes precedence over include": {
+			include: []string{"team-a."},
+			exclude: []string{"team-a.internal"},
+			kept:    []string{"team-a.banner"},
+			removed: []string{"team-a.internal-tool"},
+		},
+		"prefixes are literal": {
+			include: []string{"a.b"},
+			kept:    []string{"a.b-flag"},
+			removed: []string{"axb-flag"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := New(tt.include, tt.exclude)
+			require.NoError(t, err)
+			for _, key := range tt.kept {
+				assert.True(t, f.Keep(key), key)
+			}
+			for _, key := range tt.removed {
+				assert.False(t, f.Keep(key), key)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	_, err := New([]string{"/[/"}, nil)
+	require.Error(t, err)
+	_, err = New(nil, []string{"/(/"})
+	require.Error(t, err)
+}
+
+func TestFilter_Apply(t *testing.T) {
+	f, err := New([]string{"team-a."}, nil)
+	require.NoError(t, err)
+
+	filtered, removed := f.Apply(`{
+		"$schema": "https://flagd.dev/schema/v0/flags.json",
+		"metadata": {"version": "1"},
+		"flags": {
+			"team-a.banner": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"},
+			"team-b.banner": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"},
+			"banner": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}
+		}
+	}`)
+	assert.Equal(t, []string{"banner", "team-b.banner"}, removed)
+	assert.JSONEq(t, `{
+		"$schema": "https://flagd.dev/schema/v0/flags.json",
+		"metadata": {"version": "1"},
+		"flags": {
+			"team-a.banner": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}
+		}
+	}`, filtered)
+
+	// unparsable configurations are passed on to be rejected when applied
+	filtered, removed = f.Apply(`{"flags": [`)
+	assert.Equal(t, `{"flags": [`, filtered)
+	assert.Empty(t, removed)
+}
+
+type fakeSync struct {
+	sync.ISync
+	data sync.DataSync
+}
+
+func (s *fakeSync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	dataSync <- s.data
+	<-ctx.Done()
+	return nil
+}
+
+func (s *fakeSync) ReSync(_ context.Context, dataSync chan<- sync.DataSync) error {
+	dataSync <- s.data
+	return nil
+}
+
+type filterRecorder struct {
+	telemetry.NoopMetricsRecorder
+	source   string
+	filtered int64
+}
+
+func (r *filterRecorder) SyncFlagsFiltered(_ context.Context, source string, count int64) {
+	r.source = source
+	r.filtered += count
+}
+
+func TestSync(t *testing.T) {
+	f, err := New(nil, []string{"internal-"})
+	require.NoError(t, err)
+
+	recorder := &filterRecorder{}
+	s := &Sync{
+		ISync: &fakeSync{data: sync.DataSync{
+			FlagData: `{"flags": {"internal-tool": {}, "banner": {}}}`,
+			Source:   "source",
+			Type:     sync.ALL,
+		}},
+		Filter:  f,
+		URI:     "source",
+		Logger:  logger.NewLogger(nil, false),
+		Metrics: recorder,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dataSync := make(chan sync.DataSync)
+	done := make(chan error)
+	go func() {
+		done <- s.Sync(ctx, dataSync)
+	}()
+
+	select {
+	case data := <-dataSync:
+		assert.JSONEq(t, `{"flags": {"banner": {}}}`, data.FlagData)
+		assert.Equal(t, "source", data.Source)
+		assert.Equal(t, sync.ALL, data.Type)
+	case <-time.After(time.Second):
+		t.Fatal("no data sync received")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("sync did not end")
+	}
+
+	// resyncs are filtered likewise
+	go func() {
+		done <- s.ReSync(context.Background(), dataSync)
+	}()
+	data := <-dataSync
+	assert.JSONEq(t, `{"flags": {"banner": {}}}`, data.FlagData)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, "source", recorder.source)
+	assert.Equal(t, int64(2), recorder.filtered)
+}
@@ -0,0 +1,109 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// tokenCommandTimeout bounds a single run of a token command, so that a hanging token agent doesn't block the sync
+const tokenCommandTimeout = 10 * time.Second
+
+// TokenProvider fetches the token attached to an RPC of the grpc sync
+type TokenProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// CommandTokenProvider runs a command for each token, e.g. the CLI of a local token agent, and uses its trimmed
+// standard output as token. The first element is the executable, further elements are its arguments.
+type CommandTokenProvider struct {
+	Command []string
+}
+
+func (p *CommandTokenProvider) Token(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	//nolint:gosec // the command is taken from the sync configuration of the operator
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running token command %s: %w: %s", p.Command[0], err,
+			strings.TrimSpace(stderr.String()))
+	}
+	return nonEmptyToken(out, "token command "+p.Command[0])
+}
+
+// FileTokenProvider reads the token from a file for each token, so that a token rotated by a sidecar is picked up
+// by the next RPC
+type FileTokenProvider struct {
+	Path string
+}
+
+func (p *FileTokenProvider) Token(_ context.Context) (string, error) {
+	out, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read token file %s: %w", p.Path, err)
+	}
+	return nonEmptyToken(out, "token file "+p.Path)
+}
+
+// NewTokenProvider derives the token provider of a grpc sync source from its token command or token file, which are
+// mutually exclusive. Without either, the source doesn't attach a token and no provider is returned.
+func NewTokenProvider(command []string, file string) (TokenProvider, error) {
+	switch {
+	case len(command) > 0 && file != "":
+		return nil, errors.New("tokenCommand and tokenFile are mutually exclusive")
+	case len(command) > 0:
+		return &CommandTokenProvider{Command: command}, nil
+	case file != "":
+		return &FileTokenProvider{Path: file}, nil
+	default:
+		var provider TokenProvider
+		return provider, nil
+	}
+}
+
+// TokenCredentials attaches a token fetched from the TokenProvider to each RPC as bearer token of the authorization
+// metadata. Fetch failures fail the RPC with codes.Unauthenticated, so that the sync retries with its back off.
+type TokenCredentials struct {
+	Provider TokenProvider
+	// Secure requires a secure connection to send the token on, tokens of insecure connections are sent in plain
+	Secure bool
+}
+
+// NewTokenCredentials returns the credentials attaching the tokens of the provider to each RPC
+func NewTokenCredentials(provider TokenProvider, secure bool) credentials.PerRPCCredentials {
+	return &TokenCredentials{Provider: provider, Secure: secure}
+}
+
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := c.Provider.Token(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, fmt.Sprintf("error fetching token: %v", err))
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return c.Secure
+}
+
+func nonEmptyToken(out []byte, origin string) (string, error) {
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("%s returned an empty token", origin)
+	}
+	return token, nil
+}
@@ -0,0 +1,75 @@
+package credentials
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCommandTokenProvider(t *testing.T) {
+	token, err := (&CommandTokenProvider{Command: []string{"echo", "secret"}}).Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "secret", token, "the output is trimmed")
+
+	_, err = (&CommandTokenProvider{Command: []string{"sh", "-c", "echo agent down >&2; exit 1"}}).
+		Token(context.Background())
+	require.ErrorContains(t, err, "agent down")
+
+	_, err = (&CommandTokenProvider{Command: []string{"true"}}).Token(context.Background())
+	require.EqualError(t, err, "token command true returned an empty token")
+}
+
+func TestFileTokenProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	provider := &FileTokenProvider{Path: path}
+
+	_, err := provider.Token(context.Background())
+	require.Error(t, err, "a missing token file fails the fetch")
+
+	require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+	token, err := provider.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "first", token)
+
+	require.NoError(t, os.WriteFile(path, []byte("second"), 0o600))
+	token, err = provider.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "second", token, "a rotated token is re-read")
+}
+
+func TestNewTokenProvider(t *testing.T) {
+	provider, err := NewTokenProvider(nil, "")
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	provider, err = NewTokenProvider([]string{"token-agent"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, &CommandTokenProvider{Command: []string{"token-agent"}}, provider)
+
+	provider, err = NewTokenProvider(nil, "/var/run/token")
+	require.NoError(t, err)
+	assert.Equal(t, &FileTokenProvider{Path: "/var/run/token"}, provider)
+
+	_, err = NewTokenProvider([]string{"token-agent"}, "/var/run/token")
+	require.Error(t, err)
+}
+
+func TestTokenCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	creds := NewTokenCredentials(&FileTokenProvider{Path: path}, true)
+	assert.True(t, creds.RequireTransportSecurity())
+
+	_, err := creds.GetRequestMetadata(context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	require.NoError(t, os.WriteFile(path, []byte("secret"), 0o600))
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer secret"}, md)
+}
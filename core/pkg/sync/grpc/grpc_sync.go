@@ -2,8 +2,13 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"math"
+	"net"
+	"os"
+	"strings"
 	msync "sync"
 	"time"
 
@@ -14,6 +19,8 @@ import (
 	grpccredential "github.com/open-feature/flagd/core/pkg/sync/grpc/credentials"
 	_ "github.com/open-feature/flagd/core/pkg/sync/grpc/nameresolvers" // initialize custom resolvers e.g. envoy.Init()
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -21,7 +28,12 @@ const (
 	// URLs for REST APIs (i.e - HTTP) from GRPC endpoints.
 	Prefix          = "grpc://"
 	PrefixSecure    = "grpcs://"
-	SupportedScheme = "(envoy|dns|uds|xds)"
+	SupportedScheme = "(envoy|dns|uds|xds|unix)"
+	// PrefixUnix is the prefix of targets connecting over a Unix domain socket, e.g. unix:///var/run/flagd/sync.sock
+	PrefixUnix = "unix:"
+
+	// unixProbeTimeout bounds the connection probe of Unix domain socket targets
+	unixProbeTimeout = time.Second
 
 	// Connection retry constants
 	// Back off period is calculated with backOffBase ^ #retry-iteration. However, when #retry-iteration count reach
@@ -51,12 +63,23 @@ type Sync struct {
 	Selector          string
 	URI               string
 	MaxMsgSize        int
+	// TokenProvider fetches a fresh token attached to each RPC, which is optional
+	TokenProvider grpccredential.TokenProvider
+	// Metrics reports connection retries and failures of the source
+	Metrics *sync.SourceMetrics
 
 	client FlagSyncServiceClient
 	ready  bool
 }
 
 func (g *Sync) Init(_ context.Context) error {
+	if path, ok := unixSocketPath(g.URI); ok {
+		if err := probeUnixSocket(path); err != nil {
+			g.Logger.Error(err.Error())
+			return err
+		}
+	}
+
 	tCredentials, err := g.CredentialBuilder.Build(g.Secure, g.CertPath)
 	if err != nil {
 		err := fmt.Errorf("error building transport credentials: %w", err)
@@ -65,17 +88,21 @@ func (g *Sync) Init(_ context.Context) error {
 	}
 
 	// Derive reusable client connection
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(tCredentials)}
 	// Set MaxMsgSize if passed
-	var rpcCon *grpc.ClientConn
-
 	if g.MaxMsgSize > 0 {
 		g.Logger.Info(fmt.Sprintf("setting max receive message size %d bytes default 4MB", g.MaxMsgSize))
-		dialOptions := grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(g.MaxMsgSize))
-		rpcCon, err = grpc.NewClient(g.URI, grpc.WithTransportCredentials(tCredentials), dialOptions)
-	} else {
-		rpcCon, err = grpc.NewClient(g.URI, grpc.WithTransportCredentials(tCredentials))
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(g.MaxMsgSize)))
+	}
+	if g.TokenProvider != nil {
+		if !g.Secure {
+			g.Logger.Warn(fmt.Sprintf("sending the tokens of grpc target %s over an insecure connection", g.URI))
+		}
+		dialOptions = append(dialOptions,
+			grpc.WithPerRPCCredentials(grpccredential.NewTokenCredentials(g.TokenProvider, g.Secure)))
 	}
 
+	rpcCon, err := grpc.NewClient(g.URI, dialOptions...)
 	if err != nil {
 		err := fmt.Errorf("error initiating grpc client connection: %w", err)
 		g.Logger.Error(err.Error())
@@ -90,11 +117,17 @@ func (g *Sync) Init(_ context.Context) error {
 
 func (g *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	res, err := g.client.FetchAllFlags(ctx, &v1.FetchAllFlagsRequest{ProviderId: g.ProviderID, Selector: g.Selector})
+	if g.isTokenFailure(err) {
+		// the stream keeps the flags in sync, so that skipping the resync doesn't stop the source
+		g.Logger.Warn(fmt.Sprintf("skipping resync of grpc target %s: %s", g.URI, err.Error()))
+		return nil
+	}
 	if err != nil {
 		err = fmt.Errorf("error fetching all flags: %w", err)
 		g.Logger.Error(err.Error())
 		return err
 	}
+	g.Metrics.Retain(res.GetFlagConfiguration())
 	dataSync <- sync.DataSync{
 		FlagData: res.GetFlagConfiguration(),
 		Source:   g.URI,
@@ -108,20 +141,30 @@ func (g *Sync) IsReady() bool {
 }
 
 func (g *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	defer g.Metrics.Watch()()
 	// Initialize SyncFlags client. This fails if server connection establishment fails (ex:- grpc server offline)
 	syncClient, err := g.client.SyncFlags(ctx, &v1.SyncFlagsRequest{ProviderId: g.ProviderID, Selector: g.Selector})
-	if err != nil {
+	switch {
+	case g.isTokenFailure(err):
+		// a token agent which isn't ready yet is retried like an unavailable server
+		g.Metrics.Failure(ctx)
+		g.Logger.Warn(fmt.Sprintf("unable to sync flags: %s", err.Error()))
+	case err != nil:
+		g.Metrics.Failure(ctx)
 		return fmt.Errorf("unable to sync flags: %w", err)
-	}
+	default:
+		g.Metrics.Success()
 
-	// Initial stream listening. Error will be logged and continue and retry connection establishment
-	err = g.handleFlagSync(syncClient, dataSync)
-	if err == nil {
-		// This should not happen as handleFlagSync expects to return with an error
-		return nil
-	}
+		// Initial stream listening. Error will be logged and continue and retry connection establishment
+		err = g.handleFlagSync(syncClient, dataSync)
+		if err == nil {
+			// This should not happen as handleFlagSync expects to return with an error
+			return nil
+		}
 
-	g.Logger.Warn(fmt.Sprintf("error with stream listener: %s", err.Error()))
+		g.Metrics.Failure(ctx)
+		g.Logger.Warn(fmt.Sprintf("error with stream listener: %s", err.Error()))
+	}
 
 	// retry connection establishment
 	for {
@@ -133,6 +176,7 @@ func (g *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 
 		err = g.handleFlagSync(syncClient, dataSync)
 		if err != nil {
+			g.Metrics.Failure(ctx)
 			g.Logger.Warn(fmt.Sprintf("error with stream listener: %s", err.Error()))
 			continue
 		}
@@ -167,18 +211,63 @@ func (g *Sync) connectWithRetry(
 		}
 
 		g.Logger.Warn(fmt.Sprintf("connection re-establishment attempt in-progress for grpc target: %s", g.URI))
+		g.Metrics.Attempt(ctx)
 
 		syncClient, err := g.client.SyncFlags(ctx, &v1.SyncFlagsRequest{ProviderId: g.ProviderID, Selector: g.Selector})
 		if err != nil {
+			g.Metrics.Failure(ctx)
 			g.Logger.Debug(fmt.Sprintf("error opening service client: %s", err.Error()))
 			continue
 		}
+		g.Metrics.Success()
 
 		g.Logger.Info(fmt.Sprintf("connection re-established with grpc target: %s", g.URI))
 		return syncClient, true
 	}
 }
 
+// isTokenFailure reports whether an RPC failed as the token of the TokenProvider couldn't be fetched or was rejected.
+// Both are retried rather than failing the sync, as the next attempt fetches a fresh token.
+func (g *Sync) isTokenFailure(err error) bool {
+	return err != nil && g.TokenProvider != nil && status.Code(err) == codes.Unauthenticated
+}
+
+// unixSocketPath returns the socket path of a Unix domain socket target, i.e. unix:path or unix:///absolute/path
+func unixSocketPath(target string) (string, bool) {
+	if !strings.HasPrefix(target, PrefixUnix) {
+		return "", false
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(target, PrefixUnix), "//"), true
+}
+
+// probeUnixSocket verifies that the socket of a Unix domain socket target can be used. A socket which doesn't exist
+// yet or isn't accepting connections is not an error, as the server may start after flagd, so that the connection is
+// established by the regular retries. A path which is not a socket, or a socket flagd lacks the permission to connect
+// to, is a misconfiguration which retries can't resolve.
+func probeUnixSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error accessing unix socket %s: %w", path, err)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("grpc target %s is not a unix socket", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, unixProbeTimeout)
+	if err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("permission denied connecting to unix socket %s, flagd requires write permission "+
+				"on the socket: %w", path, err)
+		}
+		return nil
+	}
+	_ = conn.Close()
+	return nil
+}
+
 // handleFlagSync wraps the stream listening and push updates through dataSync channel
 func (g *Sync) handleFlagSync(stream syncv1grpc.FlagSyncService_SyncFlagsClient, dataSync chan<- sync.DataSync) error {
 	once.Do(func() {
@@ -191,6 +280,7 @@ func (g *Sync) handleFlagSync(stream syncv1grpc.FlagSyncService_SyncFlagsClient,
 			return fmt.Errorf("error receiving payload from stream: %w", err)
 		}
 
+		g.Metrics.Retain(data.FlagConfiguration)
 		dataSync <- sync.DataSync{
 			FlagData: data.FlagConfiguration,
 			Source:   g.URI,
@@ -6,8 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,6 +20,7 @@ import (
 	v1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/flagd/sync/v1"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	grpccredential "github.com/open-feature/flagd/core/pkg/sync/grpc/credentials"
 	credendialsmock "github.com/open-feature/flagd/core/pkg/sync/grpc/credentials/mock"
 	grpcmock "github.com/open-feature/flagd/core/pkg/sync/grpc/mock"
 	"github.com/stretchr/testify/require"
@@ -25,6 +31,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/test/bufconn"
 )
 
@@ -548,6 +555,139 @@ func Test_SyncRetry(t *testing.T) {
 	}
 }
 
+func Test_UnixSocketTarget(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "sync.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	syncv1grpc.RegisterFlagSyncServiceServer(server, &bufferedServer{
+		fetchAllFlagsResponse: &v1.FetchAllFlagsResponse{FlagConfiguration: "success"},
+	})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	mockCtrl := gomock.NewController(t)
+	mockCredentialBulder := credendialsmock.NewMockBuilder(mockCtrl)
+	mockCredentialBulder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(insecure.NewCredentials(), nil)
+
+	grpcSync := Sync{
+		URI:               "unix://" + socketPath,
+		Logger:            logger.NewLogger(nil, false),
+		CredentialBuilder: mockCredentialBulder,
+	}
+	require.NoError(t, grpcSync.Init(context.Background()))
+
+	dataSync := make(chan sync.DataSync, 1)
+	require.NoError(t, grpcSync.ReSync(context.Background(), dataSync))
+	require.Equal(t, "success", (<-dataSync).FlagData)
+}
+
+func Test_ProbeUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+
+	// the server may not be listening yet, which is left to the connection retries
+	require.NoError(t, probeUnixSocket(filepath.Join(dir, "missing.sock")))
+
+	regularFile := filepath.Join(dir, "flags.json")
+	require.NoError(t, os.WriteFile(regularFile, []byte("{}"), 0o600))
+	require.Error(t, probeUnixSocket(regularFile))
+
+	socketPath := filepath.Join(dir, "sync.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	defer listener.Close()
+	require.NoError(t, probeUnixSocket(socketPath))
+
+	if os.Geteuid() == 0 {
+		t.Skip("socket permissions are not enforced for root")
+	}
+	require.NoError(t, os.Chmod(socketPath, 0o000))
+	require.ErrorIs(t, probeUnixSocket(socketPath), fs.ErrPermission)
+}
+
+func Test_UnixSocketPath(t *testing.T) {
+	tests := map[string]struct {
+		target string
+		path   string
+		unix   bool
+	}{
+		"absolute path":   {target: "unix:///var/run/flagd/sync.sock", path: "/var/run/flagd/sync.sock", unix: true},
+		"relative path":   {target: "unix:sync.sock", path: "sync.sock", unix: true},
+		"tcp target":      {target: "localhost:8015"},
+		"custom resolver": {target: "envoy://localhost:9211/test.service"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, ok := unixSocketPath(tt.target)
+			require.Equal(t, tt.unix, ok)
+			require.Equal(t, tt.path, path)
+		})
+	}
+}
+
+func Test_TokenProvider(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "sync.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	syncv1grpc.RegisterFlagSyncServiceServer(server, &tokenServer{})
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	newSync := func(provider grpccredential.TokenProvider) *Sync {
+		mockCtrl := gomock.NewController(t)
+		mockCredentialBulder := credendialsmock.NewMockBuilder(mockCtrl)
+		mockCredentialBulder.EXPECT().Build(gomock.Any(), gomock.Any()).Return(insecure.NewCredentials(), nil)
+		grpcSync := &Sync{
+			URI:               "unix://" + socketPath,
+			Logger:            logger.NewLogger(nil, false),
+			CredentialBuilder: mockCredentialBulder,
+			TokenProvider:     provider,
+		}
+		require.NoError(t, grpcSync.Init(context.Background()))
+		return grpcSync
+	}
+
+	t.Run("resync", func(t *testing.T) {
+		grpcSync := newSync(&rotatingTokenProvider{})
+		dataSync := make(chan sync.DataSync, 1)
+
+		require.NoError(t, grpcSync.ReSync(context.Background(), dataSync), "token failures skip the resync")
+		require.Empty(t, dataSync)
+
+		require.NoError(t, grpcSync.ReSync(context.Background(), dataSync))
+		require.Equal(t, "Bearer token-2", (<-dataSync).FlagData, "each RPC fetches a fresh token")
+	})
+
+	t.Run("sync retries token failures", func(t *testing.T) {
+		grpcSync := newSync(&rotatingTokenProvider{})
+		dataSync := make(chan sync.DataSync, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan error, 1)
+		go func() {
+			done <- grpcSync.Sync(ctx, dataSync)
+		}()
+
+		select {
+		case data := <-dataSync:
+			require.Equal(t, "Bearer token-2", data.FlagData)
+		case err := <-done:
+			t.Fatalf("sync returned before retrying the token failure: %v", err)
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout waiting for the retried sync")
+		}
+	})
+}
+
 // Mock implementations
 
 // serve serves a bufferedServer. This is a blocking call
@@ -594,3 +734,35 @@ func (b *bufferedServer) FetchAllFlags(_ context.Context, _ *v1.FetchAllFlagsReq
 func (b *bufferedServer) GetMetadata(_ context.Context, _ *v1.GetMetadataRequest) (*v1.GetMetadataResponse, error) {
 	return &v1.GetMetadataResponse{}, nil
 }
+
+// tokenServer answers with the authorization metadata of the requests as flag configuration
+type tokenServer struct {
+	bufferedServer
+}
+
+func (s *tokenServer) SyncFlags(_ *v1.SyncFlagsRequest, stream syncv1grpc.FlagSyncService_SyncFlagsServer) error {
+	return stream.Send(&v1.SyncFlagsResponse{FlagConfiguration: authorization(stream.Context())})
+}
+
+func (s *tokenServer) FetchAllFlags(ctx context.Context, _ *v1.FetchAllFlagsRequest) (*v1.FetchAllFlagsResponse, error) {
+	return &v1.FetchAllFlagsResponse{FlagConfiguration: authorization(ctx)}, nil
+}
+
+func authorization(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return strings.Join(md.Get("authorization"), ",")
+}
+
+// rotatingTokenProvider fails to fetch the first token, like a token agent which isn't ready yet, and returns a new
+// token for each further fetch
+type rotatingTokenProvider struct {
+	fetches atomic.Int32
+}
+
+func (p *rotatingTokenProvider) Token(_ context.Context) (string, error) {
+	fetch := p.fetches.Add(1)
+	if fetch == 1 {
+		return "", errors.New("token agent not ready")
+	}
+	return fmt.Sprintf("token-%d", fetch), nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/circuitbreaker"
 	"github.com/open-feature/flagd/core/pkg/utils"
 	"golang.org/x/crypto/sha3" //nolint:gosec
 )
@@ -26,7 +27,11 @@ type Sync struct {
 	BearerToken string
 	AuthHeader  string
 	Interval    uint32
-	ready       bool
+	// Breaker pauses polling of a failing source. The last known configuration is kept in the store while open
+	Breaker *circuitbreaker.CircuitBreaker
+	// Metrics reports fetch retries and failures of the source
+	Metrics *sync.SourceMetrics
+	ready   bool
 }
 
 // Client defines the behaviour required of a http client
@@ -46,6 +51,7 @@ func (hs *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error
 	if err != nil {
 		return err
 	}
+	hs.Metrics.Retain(msg)
 	dataSync <- sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL}
 	return nil
 }
@@ -62,20 +68,30 @@ func (hs *Sync) IsReady() bool {
 }
 
 func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	defer hs.Metrics.Watch()()
 	// Initial fetch
 	fetch, err := hs.Fetch(ctx)
 	if err != nil {
+		hs.Metrics.Failure(ctx)
 		return err
 	}
+	hs.Metrics.Success()
 
 	// Set ready state
 	hs.ready = true
 
 	hs.Logger.Debug(fmt.Sprintf("polling %s every %d seconds", hs.URI, hs.Interval))
 	_ = hs.Cron.AddFunc(fmt.Sprintf("*/%d * * * *", hs.Interval), func() {
+		if !hs.Breaker.Allow() {
+			hs.Logger.Debug(fmt.Sprintf("circuit breaker is open, skipping fetch from %s", hs.URI))
+			return
+		}
+
+		hs.Metrics.Attempt(ctx)
 		hs.Logger.Debug(fmt.Sprintf("fetching configuration from %s", hs.URI))
 		body, err := hs.fetchBodyFromURL(ctx, hs.URI)
 		if err != nil {
+			hs.fetchFailed(ctx)
 			hs.Logger.Error(err.Error())
 			return
 		}
@@ -87,29 +103,37 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 				hs.Logger.Debug("new configuration created")
 				msg, err := hs.Fetch(ctx)
 				if err != nil {
+					hs.fetchFailed(ctx)
 					hs.Logger.Error(fmt.Sprintf("error fetching: %s", err.Error()))
-				} else {
-					dataSync <- sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL}
+					return
 				}
+				hs.Metrics.Retain(msg)
+				dataSync <- sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL}
 			} else {
 				currentSHA := hs.generateSha([]byte(body))
 				if hs.LastBodySHA != currentSHA {
 					hs.Logger.Debug("configuration modified")
 					msg, err := hs.Fetch(ctx)
 					if err != nil {
+						hs.fetchFailed(ctx)
 						hs.Logger.Error(fmt.Sprintf("error fetching: %s", err.Error()))
-					} else {
-						dataSync <- sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL}
+						return
 					}
+					hs.Metrics.Retain(msg)
+					dataSync <- sync.DataSync{FlagData: msg, Source: hs.URI, Type: sync.ALL}
 				}
 
 				hs.LastBodySHA = currentSHA
 			}
 		}
+
+		hs.Breaker.Success()
+		hs.Metrics.Success()
 	})
 
 	hs.Cron.Start()
 
+	hs.Metrics.Retain(fetch)
 	dataSync <- sync.DataSync{FlagData: fetch, Source: hs.URI, Type: sync.ALL}
 
 	<-ctx.Done()
@@ -118,6 +142,12 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	return nil
 }
 
+// fetchFailed reports a failed fetch to the circuit breaker and the metrics of the source
+func (hs *Sync) fetchFailed(ctx context.Context) {
+	hs.Breaker.Failure()
+	hs.Metrics.Failure(ctx)
+}
+
 func (hs *Sync) fetchBodyFromURL(ctx context.Context, url string) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, bytes.NewBuffer(nil))
 	if err != nil {
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/circuitbreaker"
 	syncmock "github.com/open-feature/flagd/core/pkg/sync/http/mock"
 	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
 	"go.uber.org/mock/gomock"
@@ -103,6 +105,59 @@ func TestExtensionWithQSSync(t *testing.T) {
 	}
 }
 
+func TestSyncCircuitBreaker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockCron := synctesting.NewMockCron(ctrl)
+	mockCron.EXPECT().AddFunc(gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, _ func()) error {
+		return nil
+	})
+	mockCron.EXPECT().Start().Times(1)
+
+	mockClient := syncmock.NewMockClient(ctrl)
+	responseBody := "test response"
+	// initial fetch succeeds
+	mockClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		Header:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(responseBody)),
+		StatusCode: http.StatusOK,
+	}, nil)
+	// polls fail until the breaker opens, no further calls are expected afterwards
+	mockClient.EXPECT().Do(gomock.Any()).Return(nil, errors.New("connection refused")).Times(2)
+
+	breaker := circuitbreaker.New(2, time.Hour)
+	httpSync := Sync{
+		URI:     "http://localhost/flags",
+		Client:  mockClient,
+		Cron:    mockCron,
+		Logger:  logger.NewLogger(nil, false),
+		Breaker: breaker,
+	}
+
+	ctx := context.Background()
+	dataSyncChan := make(chan sync.DataSync)
+
+	go func() {
+		err := httpSync.Sync(ctx, dataSyncChan)
+		if err != nil {
+			log.Fatalf("Error start sync: %s", err.Error())
+			return
+		}
+	}()
+
+	data := <-dataSyncChan
+	if data.FlagData != responseBody {
+		t.Errorf("expected content: %s, but received content: %s", responseBody, data.FlagData)
+	}
+
+	for i := 0; i < 4; i++ {
+		mockCron.Tick()
+	}
+
+	if breaker.State() != circuitbreaker.Open {
+		t.Errorf("expected circuit breaker to be open, got %s", breaker.State())
+	}
+}
+
 func TestHTTPSync_Fetch(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
@@ -59,6 +59,8 @@ type DataSync struct {
 	Source   string
 	Selector string
 	Type
+	// IgnoreUnknownFields accepts unknown top-level and flag-level fields of the configuration instead of rejecting it
+	IgnoreUnknownFields bool
 }
 
 // SourceConfig is configuration option for flagd. This maps to startup parameter sources
@@ -74,4 +76,21 @@ type SourceConfig struct {
 	Selector    string `json:"selector,omitempty"`
 	Interval    uint32 `json:"interval,omitempty"`
 	MaxMsgSize  int    `json:"maxMsgSize,omitempty"`
+
+	// TokenCommand and TokenFile fetch the token attached to each RPC of a grpc sync, either by running the command
+	// or by re-reading the file, e.g. of a sidecar rotating short-lived tokens
+	TokenCommand []string `json:"tokenCommand,omitempty"`
+	TokenFile    string   `json:"tokenFile,omitempty"`
+
+	CircuitBreakerThreshold int    `json:"circuitBreakerThreshold,omitempty"`
+	CircuitBreakerCoolDown  uint32 `json:"circuitBreakerCoolDown,omitempty"`
+
+	// IncludeFlags and ExcludeFlags scope the flags contributed by the source by their keys, patterns are prefixes
+	// or regular expressions enclosed in slashes
+	IncludeFlags []string `json:"includeFlags,omitempty"`
+	ExcludeFlags []string `json:"excludeFlags,omitempty"`
+
+	// IgnoreUnknownFields accepts configurations of the source with unknown top-level and flag-level fields, which
+	// are logged once and ignored, instead of rejecting them
+	IgnoreUnknownFields bool `json:"ignoreUnknownFields,omitempty"`
 }
@@ -31,6 +31,8 @@ type SyncOption func(s *Sync)
 
 type Sync struct {
 	URI string
+	// Metrics reports fetch retries and failures of the source
+	Metrics *sync.SourceMetrics
 
 	ready         bool
 	namespace     string
@@ -57,6 +59,7 @@ func (k *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error
 	if err != nil {
 		return fmt.Errorf("unable to fetch flag configuration: %w", err)
 	}
+	k.Metrics.Retain(fetch)
 	dataSync <- sync.DataSync{FlagData: fetch, Source: k.URI, Type: sync.ALL}
 	return nil
 }
@@ -87,16 +90,18 @@ func (k *Sync) IsReady() bool {
 }
 
 func (k *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	defer k.Metrics.Watch()()
 	k.logger.Info(fmt.Sprintf("starting kubernetes sync notifier for resource: %s", k.URI))
 
 	// Initial fetch
-	fetch, err := k.fetch(ctx)
+	fetch, err := k.fetchWithMetrics(ctx)
 	if err != nil {
 		err = fmt.Errorf("error with the initial fetch: %w", err)
 		k.logger.Error(err.Error())
 		return err
 	}
 
+	k.Metrics.Retain(fetch)
 	dataSync <- sync.DataSync{FlagData: fetch, Source: k.URI, Type: sync.ALL}
 
 	notifies := make(chan INotify)
@@ -107,6 +112,7 @@ func (k *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		defer k.Metrics.Watch()()
 		k.notify(ctx, notifies)
 	}()
 
@@ -114,6 +120,7 @@ func (k *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		defer k.Metrics.Watch()()
 		k.watcher(ctx, notifies, dataSync)
 	}()
 
@@ -130,21 +137,23 @@ func (k *Sync) watcher(ctx context.Context, notifies chan INotify, dataSync chan
 			switch w.GetEvent().EventType {
 			case DefaultEventTypeCreate:
 				k.logger.Debug("new configuration created")
-				msg, err := k.fetch(ctx)
+				msg, err := k.fetchWithMetrics(ctx)
 				if err != nil {
 					k.logger.Error(fmt.Sprintf("error fetching after create notification: %s", err.Error()))
 					continue
 				}
 
+				k.Metrics.Retain(msg)
 				dataSync <- sync.DataSync{FlagData: msg, Source: k.URI, Type: sync.ALL}
 			case DefaultEventTypeModify:
 				k.logger.Debug("Configuration modified")
-				msg, err := k.fetch(ctx)
+				msg, err := k.fetchWithMetrics(ctx)
 				if err != nil {
 					k.logger.Error(fmt.Sprintf("error fetching after update notification: %s", err.Error()))
 					continue
 				}
 
+				k.Metrics.Retain(msg)
 				dataSync <- sync.DataSync{FlagData: msg, Source: k.URI, Type: sync.ALL}
 			case DefaultEventTypeDelete:
 				k.logger.Debug("configuration deleted")
@@ -156,6 +165,18 @@ func (k *Sync) watcher(ctx context.Context, notifies chan INotify, dataSync chan
 	}
 }
 
+// fetchWithMetrics wraps fetch, reporting retries and failures to the metrics of the source
+func (k *Sync) fetchWithMetrics(ctx context.Context) (string, error) {
+	k.Metrics.Attempt(ctx)
+	msg, err := k.fetch(ctx)
+	if err != nil {
+		k.Metrics.Failure(ctx)
+		return "", err
+	}
+	k.Metrics.Success()
+	return msg, nil
+}
+
 // fetch attempts to retrieve the latest feature flag configurations
 func (k *Sync) fetch(ctx context.Context) (string, error) {
 	// first check the store - avoid overloading API
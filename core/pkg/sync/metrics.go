@@ -0,0 +1,132 @@
+package sync
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+// SourceMetrics reports the fetch health and the resource usage of a sync source. Any fetch or connection attempt
+// following a failure is reported as a retry. A source is active while its last fetch or connection attempt
+// succeeded. A nil SourceMetrics discards all reports, which keeps the instrumentation optional for sync providers.
+type SourceMetrics struct {
+	recorder   telemetry.ISyncMetricsRecorder
+	source     string
+	failing    atomic.Bool
+	active     atomic.Bool
+	goroutines atomic.Int64
+	retained   atomic.Int64
+	// lost is the time in unix nanoseconds the source became inactive, zero while it is active or before its first
+	// failed attempt
+	lost atomic.Int64
+}
+
+func NewSourceMetrics(recorder telemetry.IMetricsRecorder, source string) *SourceMetrics {
+	return &SourceMetrics{
+		recorder: telemetry.SyncMetrics(recorder),
+		source:   source,
+	}
+}
+
+// Attempt reports a fetch or connection attempt, which is recorded as a retry if the previous attempt failed
+func (m *SourceMetrics) Attempt(ctx context.Context) {
+	if m == nil || !m.failing.Load() {
+		return
+	}
+	m.recorder.SyncRetry(ctx, m.source)
+}
+
+// Failure reports a failed fetch or connection attempt
+func (m *SourceMetrics) Failure(ctx context.Context) {
+	if m == nil {
+		return
+	}
+	m.failing.Store(true)
+	m.active.Store(false)
+	m.lost.CompareAndSwap(0, time.Now().UnixNano())
+	m.recorder.SyncFailure(ctx, m.source, telemetry.SyncFetchFailure)
+}
+
+// Success reports a successful fetch or connection attempt
+func (m *SourceMetrics) Success() {
+	if m == nil {
+		return
+	}
+	m.failing.Store(false)
+	m.active.Store(true)
+	m.lost.Store(0)
+}
+
+// Active reports whether the last fetch or connection attempt of the source succeeded
+func (m *SourceMetrics) Active() bool {
+	return m != nil && m.active.Load()
+}
+
+// Watch reports a goroutine watching the source until the returned function is called, e.g.
+//
+//	defer metrics.Watch()()
+func (m *SourceMetrics) Watch() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.goroutines.Add(1)
+	return func() {
+		m.goroutines.Add(-1)
+	}
+}
+
+// Retain reports the flag configuration last received from the source, which is retained until the next one
+func (m *SourceMetrics) Retain(flagData string) {
+	if m == nil {
+		return
+	}
+	m.retained.Store(int64(len(flagData)))
+}
+
+// Usage returns the goroutines and the retained configuration bytes of the sources
+func Usage(sources []*SourceMetrics) []telemetry.SyncSourceUsage {
+	usage := make([]telemetry.SyncSourceUsage, 0, len(sources))
+	for _, source := range sources {
+		if source == nil {
+			continue
+		}
+		usage = append(usage, telemetry.SyncSourceUsage{
+			Source:        source.source,
+			Goroutines:    source.goroutines.Load(),
+			RetainedBytes: source.retained.Load(),
+		})
+	}
+	return usage
+}
+
+// CountActive returns the number of active sources
+func CountActive(sources []*SourceMetrics) int64 {
+	var active int64
+	for _, source := range sources {
+		if source.Active() {
+			active++
+		}
+	}
+	return active
+}
+
+// Staleness returns the duration since the last of the sources became inactive, zero while any source is active or if
+// no source failed yet, e.g. during startup
+func Staleness(sources []*SourceMetrics, now time.Time) time.Duration {
+	var lost int64
+	for _, source := range sources {
+		if source == nil {
+			continue
+		}
+		if source.Active() {
+			return 0
+		}
+		lost = max(lost, source.lost.Load())
+	}
+	if lost == 0 {
+		return 0
+	}
+	return now.Sub(time.Unix(0, lost))
+}
@@ -0,0 +1,119 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+)
+
+type syncHealthRecorder struct {
+	telemetry.NoopMetricsRecorder
+	retries  map[string]int
+	failures map[string]int
+}
+
+func (r *syncHealthRecorder) SyncRetry(_ context.Context, source string) {
+	r.retries[source]++
+}
+
+func (r *syncHealthRecorder) SyncFailure(_ context.Context, source, failureType string) {
+	r.failures[source+"/"+failureType]++
+}
+
+func TestSourceMetrics(t *testing.T) {
+	recorder := &syncHealthRecorder{retries: map[string]int{}, failures: map[string]int{}}
+	metrics := NewSourceMetrics(recorder, "source")
+	ctx := context.Background()
+
+	// attempts of a healthy source are not retries
+	metrics.Attempt(ctx)
+	metrics.Success()
+	assert.Equal(t, 0, recorder.retries["source"])
+
+	metrics.Attempt(ctx)
+	metrics.Failure(ctx)
+	metrics.Attempt(ctx)
+	metrics.Failure(ctx)
+	metrics.Attempt(ctx)
+	metrics.Success()
+	assert.Equal(t, 2, recorder.retries["source"])
+	assert.Equal(t, 2, recorder.failures["source/"+telemetry.SyncFetchFailure])
+
+	// recovery resets the retry tracking
+	metrics.Attempt(ctx)
+	assert.Equal(t, 2, recorder.retries["source"])
+}
+
+func TestSourceMetrics_Active(t *testing.T) {
+	recorder := &syncHealthRecorder{retries: map[string]int{}, failures: map[string]int{}}
+	sourceA := NewSourceMetrics(recorder, "sourceA")
+	sourceB := NewSourceMetrics(recorder, "sourceB")
+	sources := []*SourceMetrics{sourceA, sourceB, nil}
+
+	// sources are inactive until their first successful attempt
+	assert.Equal(t, int64(0), CountActive(sources))
+
+	sourceA.Success()
+	sourceB.Success()
+	assert.Equal(t, int64(2), CountActive(sources))
+
+	sourceB.Failure(context.Background())
+	assert.False(t, sourceB.Active())
+	assert.Equal(t, int64(1), CountActive(sources))
+}
+
+func TestSourceMetrics_Nil(t *testing.T) {
+	var metrics *SourceMetrics
+	metrics.Attempt(context.Background())
+	metrics.Failure(context.Background())
+	metrics.Success()
+	assert.False(t, metrics.Active())
+}
+
+func TestSourceMetrics_Usage(t *testing.T) {
+	sourceA := NewSourceMetrics(&telemetry.NoopMetricsRecorder{}, "sourceA")
+	sourceB := NewSourceMetrics(&telemetry.NoopMetricsRecorder{}, "sourceB")
+	var unset *SourceMetrics
+
+	doneA := sourceA.Watch()
+	sourceA.Watch()
+	sourceB.Watch()()
+	unset.Watch()()
+	sourceA.Retain(`{"flags": {}}`)
+	sourceA.Retain("{}")
+	unset.Retain("{}")
+
+	assert.Equal(t, []telemetry.SyncSourceUsage{
+		{Source: "sourceA", Goroutines: 2, RetainedBytes: 2},
+		{Source: "sourceB"},
+	}, Usage([]*SourceMetrics{sourceA, sourceB, unset}))
+
+	doneA()
+	assert.Equal(t, int64(1), Usage([]*SourceMetrics{sourceA})[0].Goroutines)
+}
+
+func TestSourceMetrics_Staleness(t *testing.T) {
+	sourceA := NewSourceMetrics(&telemetry.NoopMetricsRecorder{}, "sourceA")
+	sourceB := NewSourceMetrics(&telemetry.NoopMetricsRecorder{}, "sourceB")
+	sources := []*SourceMetrics{sourceA, sourceB, nil}
+
+	// sources which didn't fail yet aren't stale
+	assert.Equal(t, time.Duration(0), Staleness(sources, time.Now()))
+
+	sourceA.Success()
+	sourceB.Failure(context.Background())
+	assert.Equal(t, time.Duration(0), Staleness(sources, time.Now()), "a source is active")
+
+	sourceA.Failure(context.Background())
+	lostA := time.Unix(0, sourceA.lost.Load())
+	sourceA.Failure(context.Background())
+	assert.Equal(t, lostA, time.Unix(0, sourceA.lost.Load()), "only the first failure marks a source as lost")
+	assert.Equal(t, time.Minute, Staleness(sources, lostA.Add(time.Minute)),
+		"the staleness starts once the last source was lost")
+
+	sourceB.Success()
+	assert.Equal(t, time.Duration(0), Staleness(sources, time.Now()), "a source recovered")
+}
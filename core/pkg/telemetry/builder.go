@@ -17,8 +17,10 @@ import (
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.18.0"
@@ -30,7 +32,17 @@ import (
 
 const (
 	metricsExporterOtel = "otel"
-	exportInterval      = 2 * time.Second
+	// metricsExporterStdout prints the metrics as indented JSON on each export, for local debugging without a
+	// metrics backend
+	metricsExporterStdout = "stdout"
+
+	// DefaultExportInterval is the interval of pushing metrics to the OTEL collector
+	DefaultExportInterval = 2 * time.Second
+
+	// TemporalityCumulative reports the metrics aggregated since the start of flagd
+	TemporalityCumulative = "cumulative"
+	// TemporalityDelta reports the counters and histograms aggregated since the last export
+	TemporalityDelta = "delta"
 )
 
 type CollectorConfig struct {
@@ -55,10 +67,17 @@ func RegisterErrorHandling(log *logger.Logger) {
 
 // BuildMetricsRecorder is a helper to build telemetry.MetricsRecorder based on configurations
 func BuildMetricsRecorder(
-	ctx context.Context, svcName string, svcVersion string, config Config,
+	ctx context.Context, svcName string, svcVersion string, config Config, opts ...RecorderOption,
 ) (IMetricsRecorder, error) {
+	options := newRecorderOptions(svcName, opts...)
+	if err := validateReaderOptions(config, options); err != nil {
+		return nil, err
+	}
+
 	// Build metric reader based on configurations
-	mReader, err := buildMetricReader(ctx, config)
+	registration := &prometheusRegistration{Registerer: options.prometheusRegisterer}
+	options.prometheusRegisterer = registration
+	mReader, err := buildMetricReader(ctx, config, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to setup metric reader: %w", err)
 	}
@@ -69,7 +88,21 @@ func BuildMetricsRecorder(
 		return nil, fmt.Errorf("failed to setup resource identifier: %w", err)
 	}
 
-	return NewOTelRecorder(mReader, rsc, svcName), nil
+	recorder, err := newOTelRecorder(mReader, rsc, svcName, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the metric instruments: %w", err)
+	}
+	if err := registration.checkConflicts(options.prometheusGatherer); err != nil {
+		return nil, fmt.Errorf("failed to setup metric reader: %w", err)
+	}
+	return recorder, nil
+}
+
+// SupportsNativeHistograms reports whether the metrics exporter of the configuration exports native histograms. The
+// otel exporter pushes them as OTLP exponential histograms, while the Prometheus exporter only exposes histograms with
+// explicit buckets, hence BuildMetricsRecorder refuses native histograms for it.
+func SupportsNativeHistograms(config Config) bool {
+	return config.MetricsExporter == metricsExporterOtel
 }
 
 // BuildTraceProvider build and register the trace provider and propagator for the caller runtime. This method
@@ -161,16 +194,56 @@ func buildTransportCredentials(_ context.Context, cfg CollectorConfig) (credenti
 	return creds, nil
 }
 
+// validateReaderOptions checks the export options against the metrics exporter. Delta temporality is only supported by
+// the otel and stdout exporters, as Prometheus expects cumulative metrics, and native histograms only by the otel
+// exporter.
+func validateReaderOptions(cfg Config, options recorderOptions) error {
+	if options.exportInterval < 0 {
+		return fmt.Errorf("invalid metrics export interval %s: must be positive", options.exportInterval)
+	}
+	if options.nativeHistograms && !SupportsNativeHistograms(cfg) {
+		return fmt.Errorf("native histograms require the %s metrics exporter, the other exporters only export "+
+			"histograms with explicit buckets", metricsExporterOtel)
+	}
+	switch options.temporality {
+	case TemporalityCumulative:
+	case TemporalityDelta:
+		if cfg.MetricsExporter != metricsExporterOtel && cfg.MetricsExporter != metricsExporterStdout {
+			return fmt.Errorf("%s temporality requires the %s or %s metrics exporter, Prometheus metrics are %s",
+				TemporalityDelta, metricsExporterOtel, metricsExporterStdout, TemporalityCumulative)
+		}
+	default:
+		return fmt.Errorf("unsupported metrics temporality %s, supported are %s and %s",
+			options.temporality, TemporalityCumulative, TemporalityDelta)
+	}
+	return nil
+}
+
+// deltaTemporality selects delta temporality for counters and histograms. Up-down counters stay cumulative, as their
+// deltas don't add up to a meaningful value in most backends.
+func deltaTemporality(kind metric.InstrumentKind) metricdata.Temporality {
+	switch kind {
+	case metric.InstrumentKindCounter, metric.InstrumentKindObservableCounter, metric.InstrumentKindHistogram:
+		return metricdata.DeltaTemporality
+	default:
+		return metricdata.CumulativeTemporality
+	}
+}
+
 // buildMetricReader builds a metric reader based on provided configurations
-func buildMetricReader(ctx context.Context, cfg Config) (metric.Reader, error) {
+func buildMetricReader(ctx context.Context, cfg Config, options recorderOptions) (metric.Reader, error) {
 	if cfg.MetricsExporter == "" {
-		return buildDefaultMetricReader()
+		return buildDefaultMetricReader(options)
+	}
+
+	if cfg.MetricsExporter == metricsExporterStdout {
+		return buildStdoutMetricReader(options)
 	}
 
 	// Handle metric reader override
 	if cfg.MetricsExporter != metricsExporterOtel {
-		return nil, fmt.Errorf("provided metrics operator %s is not supported. currently only support %s",
-			cfg.MetricsExporter, metricsExporterOtel)
+		return nil, fmt.Errorf("provided metrics operator %s is not supported. currently only support %s and %s",
+			cfg.MetricsExporter, metricsExporterOtel, metricsExporterStdout)
 	}
 
 	// Otel override require target configuration
@@ -191,12 +264,32 @@ func buildMetricReader(ctx context.Context, cfg Config) (metric.Reader, error) {
 	}
 
 	// Otel metric exporter
-	otelExporter, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithGRPCConn(conn))
+	exporterOptions := []otlpmetricgrpc.Option{otlpmetricgrpc.WithGRPCConn(conn)}
+	if options.temporality == TemporalityDelta {
+		exporterOptions = append(exporterOptions, otlpmetricgrpc.WithTemporalitySelector(deltaTemporality))
+	}
+	otelExporter, err := otlpmetricgrpc.New(ctx, exporterOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("error creating otel metric exporter: %w", err)
 	}
 
-	return metric.NewPeriodicReader(otelExporter, metric.WithInterval(exportInterval)), nil
+	return metric.NewPeriodicReader(otelExporter, metric.WithInterval(options.exportInterval)), nil
+}
+
+// buildStdoutMetricReader builds a periodic reader printing the metrics to the writer of the options
+func buildStdoutMetricReader(options recorderOptions) (metric.Reader, error) {
+	exporterOptions := []stdoutmetric.Option{
+		stdoutmetric.WithWriter(options.stdoutWriter),
+		stdoutmetric.WithPrettyPrint(),
+	}
+	if options.temporality == TemporalityDelta {
+		exporterOptions = append(exporterOptions, stdoutmetric.WithTemporalitySelector(deltaTemporality))
+	}
+	exporter, err := stdoutmetric.New(exporterOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating stdout metric exporter: %w", err)
+	}
+	return metric.NewPeriodicReader(exporter, metric.WithInterval(options.exportInterval)), nil
 }
 
 // buildOtlpExporter is a helper to build grpc backed otlp trace exporter
@@ -220,16 +313,19 @@ func buildOtlpExporter(ctx context.Context, cfg CollectorConfig) (*otlptrace.Exp
 	return exporter, nil
 }
 
-// buildDefaultMetricReader provides the default metric reader
-func buildDefaultMetricReader() (metric.Reader, error) {
-	p, err := prometheus.New()
+// buildDefaultMetricReader provides the default metric reader, exposing the metrics through the Prometheus registerer
+// of the options
+func buildDefaultMetricReader(options recorderOptions) (metric.Reader, error) {
+	p, err := prometheus.New(prometheus.WithRegisterer(options.prometheusRegisterer))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create default metric reader: %w", err)
 	}
 	return p, nil
 }
 
-// buildResourceFor builds a resource identifier with set of resources and service key as attributes
+// buildResourceFor builds a resource identifier with set of resources and service key as attributes. Attributes of the
+// OTEL_RESOURCE_ATTRIBUTES environment variable, e.g. injected by the platform, are merged into the resource, but the
+// service key attributes take precedence.
 func buildResourceFor(ctx context.Context, serviceName string, serviceVersion string) (*resource.Resource, error) {
 	r, err := resource.New(
 		ctx,
@@ -237,6 +333,8 @@ func buildResourceFor(ctx context.Context, serviceName string, serviceVersion st
 		resource.WithHost(),
 		resource.WithProcessRuntimeVersion(),
 		resource.WithTelemetrySDK(),
+		// detected before the service attributes, as later attributes win on conflict
+		resource.WithFromEnv(),
 		resource.WithAttributes(
 			semconv.ServiceNameKey.String(serviceName),
 			semconv.ServiceVersionKey.String(serviceVersion)),
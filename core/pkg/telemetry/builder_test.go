@@ -1,12 +1,15 @@
 package telemetry
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"testing"
 	"time"
 
 	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric"
@@ -60,6 +63,13 @@ func TestBuildMetricReader(t *testing.T) {
 			},
 			error: true,
 		},
+		{
+			name: "Stdout metric exporter requires no collector",
+			cfg: Config{
+				MetricsExporter: metricsExporterStdout,
+			},
+			error: false,
+		},
 		{
 			name: "Metric exporter overriding with valid configurations",
 			cfg: Config{
@@ -73,7 +83,7 @@ func TestBuildMetricReader(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		reader, err := buildMetricReader(gCtx, test.cfg)
+		reader, err := buildMetricReader(gCtx, test.cfg, newRecorderOptions("service"))
 
 		if test.error {
 			require.NotNil(t, err, "test %s expected non-nil error", test.name)
@@ -85,6 +95,132 @@ func TestBuildMetricReader(t *testing.T) {
 	}
 }
 
+func TestBuildMetricsRecorder_ReaderOptions(t *testing.T) {
+	otelConfig := Config{
+		MetricsExporter: metricsExporterOtel,
+		CollectorConfig: CollectorConfig{
+			Target: "localhost:8080",
+		},
+	}
+
+	tests := []struct {
+		name  string
+		cfg   Config
+		opts  []RecorderOption
+		error bool
+	}{
+		{
+			name: "delta temporality with otel exporter",
+			cfg:  otelConfig,
+			opts: []RecorderOption{WithTemporality(TemporalityDelta), WithExportInterval(10 * time.Second)},
+		},
+		{
+			name:  "delta temporality with prometheus exporter",
+			cfg:   Config{},
+			opts:  []RecorderOption{WithTemporality(TemporalityDelta)},
+			error: true,
+		},
+		{
+			name: "delta temporality with stdout exporter",
+			cfg:  Config{MetricsExporter: metricsExporterStdout},
+			opts: []RecorderOption{WithTemporality(TemporalityDelta), WithStdoutWriter(io.Discard)},
+		},
+		{
+			name: "cumulative temporality with prometheus exporter",
+			cfg:  Config{},
+			opts: []RecorderOption{WithTemporality(TemporalityCumulative)},
+		},
+		{
+			name:  "unsupported temporality",
+			cfg:   otelConfig,
+			opts:  []RecorderOption{WithTemporality("sometimes")},
+			error: true,
+		},
+		{
+			name:  "native histograms with prometheus exporter",
+			cfg:   Config{},
+			opts:  []RecorderOption{WithNativeHistograms(true)},
+			error: true,
+		},
+		{
+			name: "native histograms with otel exporter",
+			cfg:  otelConfig,
+			opts: []RecorderOption{WithNativeHistograms(true)},
+		},
+		{
+			name:  "negative export interval",
+			cfg:   otelConfig,
+			opts:  []RecorderOption{WithExportInterval(-time.Second)},
+			error: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// each recorder exposes the same series, hence each needs its own registry
+			opts := append(test.opts, withPrometheusRegistry(prometheus.NewRegistry()))
+			_, err := BuildMetricsRecorder(context.Background(), "service", "0.0.1", test.cfg, opts...)
+			if test.error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestStdoutMetricReader(t *testing.T) {
+	var out bytes.Buffer
+	reader, err := buildMetricReader(context.Background(), Config{MetricsExporter: metricsExporterStdout},
+		newRecorderOptions("service", WithStdoutWriter(&out)))
+	require.NoError(t, err)
+
+	rec := NewOTelRecorder(reader, resource.NewWithAttributes("testSchema"), "service")
+	rec.RecordEvaluation(context.TODO(), nil, "STATIC", "on", "flag", APISurfaceGRPC)
+
+	// shutting down exports the pending metrics
+	require.NoError(t, reader.Shutdown(context.Background()))
+	require.Contains(t, out.String(), `"Name": "`+impressionMetric+`"`)
+	require.Contains(t, out.String(), "\n\t", "expected indented output")
+}
+
+func TestBuildMetricsRecorderPrometheusConflict(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	withRegistry := withPrometheusRegistry(registry)
+
+	_, err := BuildMetricsRecorder(context.Background(), "service", "0.0.1", Config{}, withRegistry)
+	require.NoError(t, err)
+
+	// a second exporter of the same service exposes the same series
+	recorder, err := BuildMetricsRecorder(context.Background(), "service", "0.0.1", Config{}, withRegistry)
+	require.Nil(t, recorder)
+	require.ErrorContains(t, err, "the metrics of the Prometheus exporter conflict with registered metrics")
+	require.ErrorContains(t, err, "target_info", "expected the conflicting metric")
+
+	// the conflicting collector is unregistered, so that the metrics of the first exporter are still served
+	_, err = registry.Gather()
+	require.NoError(t, err)
+}
+
+func withPrometheusRegistry(registry *prometheus.Registry) RecorderOption {
+	return func(o *recorderOptions) {
+		o.prometheusRegisterer = registry
+		o.prometheusGatherer = registry
+	}
+}
+
+func TestSupportsNativeHistograms(t *testing.T) {
+	require.True(t, SupportsNativeHistograms(Config{MetricsExporter: metricsExporterOtel}))
+	require.False(t, SupportsNativeHistograms(Config{}))
+}
+
+func TestDeltaTemporality(t *testing.T) {
+	require.Equal(t, metricdata.DeltaTemporality, deltaTemporality(metric.InstrumentKindCounter))
+	require.Equal(t, metricdata.DeltaTemporality, deltaTemporality(metric.InstrumentKindHistogram))
+	require.Equal(t, metricdata.CumulativeTemporality, deltaTemporality(metric.InstrumentKindUpDownCounter))
+	require.Equal(t, metricdata.CumulativeTemporality, deltaTemporality(metric.InstrumentKindObservableGauge))
+}
+
 func TestBuildSpanProcessor(t *testing.T) {
 	gCtx := context.TODO()
 
@@ -172,6 +308,19 @@ func TestBuildResourceFor(t *testing.T) {
 	}, "expected resource to contain service version")
 }
 
+func TestBuildResourceForEnvironment(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "k8s.pod.name=flagd-0,k8s.namespace.name=platform,service.name=envSvc")
+
+	resource, err := buildResourceFor(context.Background(), "testSvc", "0.0.1")
+	require.Nil(t, err, "expected no error, but got: %v", err)
+
+	attributes := resource.Attributes()
+	require.Contains(t, attributes, attribute.String("k8s.pod.name", "flagd-0"))
+	require.Contains(t, attributes, attribute.String("k8s.namespace.name", "platform"))
+	require.Contains(t, attributes, attribute.String(string(semconv.ServiceNameKey), "testSvc"),
+		"expected the service name to take precedence over the environment")
+}
+
 func TestErrorIntercepted(t *testing.T) {
 	// register the OTel error handling
 	observedZapCore, observedLogs := observer.New(zap.DebugLevel)
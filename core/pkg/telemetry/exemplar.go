@@ -0,0 +1,102 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	msdk "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exemplarFilterEnv is the environment variable selecting the exemplar filter of the OpenTelemetry SDK
+const exemplarFilterEnv = "OTEL_METRICS_EXEMPLAR_FILTER"
+
+// ExemplarsEnabled reports whether measurements are offered as exemplars, which is the case unless the exemplar
+// filter is turned off through the OTEL_METRICS_EXEMPLAR_FILTER environment variable
+func ExemplarsEnabled() bool {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(exemplarFilterEnv))) != "always_off"
+}
+
+// slowestExemplars selects a reservoir keeping the slowest measurement of each bucket within an interval, regardless of
+// the aggregation of the histogram
+func slowestExemplars(bounds []float64, interval time.Duration) msdk.ExemplarReservoirProviderSelector {
+	sorted := slices.Clone(bounds)
+	slices.Sort(sorted)
+	return func(msdk.Aggregation) exemplar.ReservoirProvider {
+		return func(attribute.Set) exemplar.Reservoir {
+			return newSlowestReservoir(sorted, interval)
+		}
+	}
+}
+
+// slowestReservoir is an exemplar.Reservoir keeping the highest measurement of each bucket. A kept measurement is only
+// replaced by a higher one until it is older than the interval, so that outliers outlast the churn of high request
+// rates while the exemplars still follow the recent measurements.
+type slowestReservoir struct {
+	mx       sync.Mutex
+	bounds   []float64
+	interval time.Duration
+	kept     []keptExemplar
+}
+
+type keptExemplar struct {
+	exemplar.Exemplar
+	value float64
+	valid bool
+}
+
+func newSlowestReservoir(bounds []float64, interval time.Duration) *slowestReservoir {
+	return &slowestReservoir{
+		bounds:   bounds,
+		interval: interval,
+		kept:     make([]keptExemplar, len(bounds)+1),
+	}
+}
+
+func (r *slowestReservoir) Offer(ctx context.Context, t time.Time, v exemplar.Value, attrs []attribute.KeyValue) {
+	var value float64
+	switch v.Type() {
+	case exemplar.Int64ValueType:
+		value = float64(v.Int64())
+	case exemplar.Float64ValueType:
+		value = v.Float64()
+	default:
+		return
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	kept := &r.kept[sort.SearchFloat64s(r.bounds, value)]
+	if kept.valid && value <= kept.value && t.Sub(kept.Time) < r.interval {
+		return
+	}
+
+	*kept = keptExemplar{
+		Exemplar: exemplar.Exemplar{FilteredAttributes: attrs, Time: t, Value: v},
+		value:    value,
+		valid:    true,
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		traceID, spanID := spanContext.TraceID(), spanContext.SpanID()
+		kept.TraceID = traceID[:]
+		kept.SpanID = spanID[:]
+	}
+}
+
+func (r *slowestReservoir) Collect(dest *[]exemplar.Exemplar) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	*dest = (*dest)[:0]
+	for _, kept := range r.kept {
+		if kept.valid {
+			*dest = append(*dest, kept.Exemplar)
+		}
+	}
+}
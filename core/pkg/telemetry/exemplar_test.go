@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSlowestReservoir(t *testing.T) {
+	reservoir := newSlowestReservoir([]float64{0.1, 1}, time.Minute)
+	collect := func() []float64 {
+		var exemplars []exemplar.Exemplar
+		reservoir.Collect(&exemplars)
+		values := []float64{}
+		for _, e := range exemplars {
+			values = append(values, e.Value.Float64())
+		}
+		return values
+	}
+	start := time.Now()
+
+	reservoir.Offer(context.Background(), start, exemplar.NewValue(0.5), nil)
+	reservoir.Offer(context.Background(), start, exemplar.NewValue(0.8), nil)
+	// faster requests within the interval don't replace the slowest one of the bucket
+	reservoir.Offer(context.Background(), start.Add(time.Second), exemplar.NewValue(0.3), nil)
+	reservoir.Offer(context.Background(), start, exemplar.NewValue(0.05), nil)
+	require.Equal(t, []float64{0.05, 0.8}, collect())
+
+	// once the slowest request is older than the interval, it is replaced by the next one
+	reservoir.Offer(context.Background(), start.Add(time.Minute), exemplar.NewValue(0.3), nil)
+	require.Equal(t, []float64{0.05, 0.3}, collect())
+
+	// the span of the request is kept along with its measurement
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+	reservoir.Offer(ctx, start, exemplar.NewValue(2.0), nil)
+	var exemplars []exemplar.Exemplar
+	reservoir.Collect(&exemplars)
+	require.Len(t, exemplars, 3)
+	traceID, spanID := spanContext.TraceID(), spanContext.SpanID()
+	require.Equal(t, traceID[:], exemplars[2].TraceID)
+	require.Equal(t, spanID[:], exemplars[2].SpanID)
+}
+
+func TestExemplarsEnabled(t *testing.T) {
+	t.Setenv(exemplarFilterEnv, "")
+	require.True(t, ExemplarsEnabled())
+	t.Setenv(exemplarFilterEnv, "trace_based")
+	require.True(t, ExemplarsEnabled())
+	t.Setenv(exemplarFilterEnv, "always_off")
+	require.False(t, ExemplarsEnabled())
+}
@@ -0,0 +1,88 @@
+package telemetry
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// MetricsFormatText exposes the Prometheus metrics in the text format only, which doesn't carry exemplars
+	MetricsFormatText = "text"
+	// MetricsFormatOpenMetrics additionally exposes the Prometheus metrics in the OpenMetrics format to scrapers
+	// accepting it, which carries the exemplars of counters and histograms
+	MetricsFormatOpenMetrics = "openmetrics"
+)
+
+// ValidateMetricsFormat returns an error if the format is neither MetricsFormatText nor MetricsFormatOpenMetrics
+func ValidateMetricsFormat(format string) error {
+	if format != MetricsFormatText && format != MetricsFormatOpenMetrics {
+		return fmt.Errorf("unsupported metrics format '%s', must be '%s' or '%s'",
+			format, MetricsFormatText, MetricsFormatOpenMetrics)
+	}
+	return nil
+}
+
+// ExposesExemplars reports whether the exemplars of the metrics exporter of the configuration reach their consumer.
+// The otel exporter pushes them along with the metrics, while the Prometheus exporter drops them unless the metrics
+// are exposed in the OpenMetrics format.
+func ExposesExemplars(config Config, format string) bool {
+	return config.MetricsExporter == metricsExporterOtel || format == MetricsFormatOpenMetrics
+}
+
+// MetricsHandler serves the metrics of the default Prometheus registry in the given format
+func MetricsHandler(format string) http.Handler {
+	return metricsHandler(prometheus.DefaultRegisterer, prometheus.DefaultGatherer, format)
+}
+
+func metricsHandler(registerer prometheus.Registerer, gatherer prometheus.Gatherer, format string) http.Handler {
+	return promhttp.InstrumentMetricHandler(registerer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: format == MetricsFormatOpenMetrics,
+	}))
+}
+
+// prometheusRegistration registers the collector of the Prometheus exporter, so that it can be detached from the
+// registry again if its metrics conflict with the metrics of other collectors
+type prometheusRegistration struct {
+	prometheus.Registerer
+	collector *detachableCollector
+}
+
+func (r *prometheusRegistration) Register(collector prometheus.Collector) error {
+	detachable := &detachableCollector{Collector: collector}
+	if err := r.Registerer.Register(detachable); err != nil {
+		return fmt.Errorf("error registering the Prometheus collector: %w", err)
+	}
+	r.collector = detachable
+	return nil
+}
+
+// checkConflicts gathers the metrics of the registry once, detaching the collector if its metrics conflict with the
+// metrics of other collectors, e.g. of a second exporter. As the collector of the exporter doesn't describe its metrics
+// upfront, such conflicts aren't detected on registration, but would fail each scrape of the registry.
+func (r *prometheusRegistration) checkConflicts(gatherer prometheus.Gatherer) error {
+	if r.collector == nil {
+		return nil
+	}
+	if _, err := gatherer.Gather(); err != nil {
+		r.collector.detached.Store(true)
+		return fmt.Errorf("the metrics of the Prometheus exporter conflict with registered metrics: %w", err)
+	}
+	return nil
+}
+
+// detachableCollector collects the metrics of a collector until it is detached. Unlike unregistering, detaching also
+// works for collectors which don't describe their metrics.
+type detachableCollector struct {
+	prometheus.Collector
+	detached atomic.Bool
+}
+
+func (c *detachableCollector) Collect(ch chan<- prometheus.Metric) {
+	if !c.detached.Load() {
+		c.Collector.Collect(ch)
+	}
+}
@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateMetricsFormat(t *testing.T) {
+	require.NoError(t, ValidateMetricsFormat(MetricsFormatText))
+	require.NoError(t, ValidateMetricsFormat(MetricsFormatOpenMetrics))
+	require.EqualError(t, ValidateMetricsFormat("json"),
+		"unsupported metrics format 'json', must be 'text' or 'openmetrics'")
+}
+
+func TestExposesExemplars(t *testing.T) {
+	require.True(t, ExposesExemplars(Config{MetricsExporter: metricsExporterOtel}, MetricsFormatText))
+	require.True(t, ExposesExemplars(Config{}, MetricsFormatOpenMetrics))
+	require.False(t, ExposesExemplars(Config{}, MetricsFormatText))
+}
+
+func TestMetricsHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "requests_total"})
+	registry.MustRegister(counter)
+	counter.(prometheus.ExemplarAdder).AddWithExemplar(1, prometheus.Labels{"trace_id": "abc"})
+
+	tests := map[string]struct {
+		format      string
+		contentType string
+		exemplar    bool
+	}{
+		"text": {
+			format:      MetricsFormatText,
+			contentType: "text/plain",
+		},
+		"openmetrics": {
+			format:      MetricsFormatOpenMetrics,
+			contentType: "application/openmetrics-text",
+			exemplar:    true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			request.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+			recorder := httptest.NewRecorder()
+
+			metricsHandler(prometheus.NewRegistry(), registry, tt.format).ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			assert.Contains(t, recorder.Header().Get("Content-Type"), tt.contentType)
+			if tt.exemplar {
+				assert.Contains(t, recorder.Body.String(), `trace_id="abc"`)
+			} else {
+				assert.NotContains(t, recorder.Body.String(), `trace_id="abc"`)
+			}
+		})
+	}
+}
@@ -2,11 +2,18 @@ package telemetry
 
 import (
 	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 	"go.opentelemetry.io/otel/sdk/instrumentation"
 	msdk "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -18,22 +25,331 @@ const (
 
 	FeatureFlagReasonKey = attribute.Key("feature_flag.reason")
 	ExceptionTypeKey     = attribute.Key("ExceptionTypeKeyName")
+	SyncSourceKey        = attribute.Key("feature_flag.source")
+	StreamTypeKey        = attribute.Key("flagd.stream.type")
+	SyncFailureTypeKey   = attribute.Key("flagd.sync.failure.type")
+	RequestedTypeKey     = attribute.Key("flagd.type.requested")
+	ActualTypeKey        = attribute.Key("flagd.type.actual")
+	AliasKey             = attribute.Key("flagd.alias")
+	OFREPRequestTypeKey  = attribute.Key("flagd.ofrep.type")
+	OFREPStatusKey       = attribute.Key("flagd.ofrep.status")
+	ConfigWarningKey     = attribute.Key("flagd.config.warning")
+	APISurfaceKey        = attribute.Key("flagd.api.surface")
+	ContextKeyKey        = attribute.Key("flagd.context.key")
+	CacheResultKey       = attribute.Key("flagd.cache.result")
+	VariantRefKindKey    = attribute.Key("flagd.variant_reference.kind")
+	EventTypeKey         = attribute.Key("flagd.event.type")
+	EventOutcomeKey      = attribute.Key("flagd.event.outcome")
+
+	// SyncFetchFailure is a failed fetch or connection attempt of a sync source
+	SyncFetchFailure = "fetch"
+	// SyncParseFailure is a flag configuration of a sync source which could not be parsed or validated
+	SyncParseFailure = "parse"
+	// SyncFlagLimitFailure is a flag configuration of a sync source which was rejected, as it defines more flags than
+	// the configured maximum
+	SyncFlagLimitFailure = "flag_limit"
+	// SyncEmptyConfigFailure is a flag configuration of a sync source which was rejected, as it defines no flags
+	SyncEmptyConfigFailure = "empty_config"
+	// SyncVariantRefFailure is a flag configuration of a sync source which was rejected, as a flag references a
+	// variant it doesn't define
+	SyncVariantRefFailure = "variant_reference"
+	// SyncVersionRegressionFailure is a flag configuration of a sync source which was rejected, as its monotonic
+	// version is older than the version of the applied configuration of the source
+	SyncVersionRegressionFailure = "version_regression"
+
+	// VariantRefDefault, VariantRefRegionDefault and VariantRefTargeting are the kinds of references to variants a
+	// flag doesn't define, either its default variant, one of its region defaults or a variant its targeting resolves
+	// to
+	VariantRefDefault       = "default_variant"
+	VariantRefRegionDefault = "region_default"
+	VariantRefTargeting     = "targeting"
+
+	// ConfigDeprecatedOperator, ConfigDuplicateFlag, ConfigEmptyTargeting and ConfigUnreachableVariant are the
+	// categories of non-fatal issues of flag configurations, reported when the configuration is applied
+	ConfigDeprecatedOperator = "deprecated_operator"
+	ConfigDuplicateFlag      = "duplicate_flag"
+	ConfigEmptyTargeting     = "empty_targeting"
+	ConfigUnreachableVariant = "unreachable_variant"
+
+	// APISurfaceGRPC, APISurfaceOFREP, APISurfaceREST and APISurfaceInProcess are the API surfaces evaluations are
+	// requested through, any other surface is recorded as APISurfaceInProcess
+	APISurfaceGRPC      = "grpc"
+	APISurfaceOFREP     = "ofrep"
+	APISurfaceREST      = "rest"
+	APISurfaceInProcess = "inprocess"
+
+	// OFREPSingleRequest, OFREPBulkRequest and OFREPBatchRequest are the types of OFREP evaluation requests, which are
+	// either answered successfully (OFREPStatusOK) or with an error (OFREPStatusError)
+	OFREPSingleRequest = "single"
+	OFREPBulkRequest   = "bulk"
+	OFREPBatchRequest  = "batch"
+	OFREPStatusOK      = "ok"
+	OFREPStatusError   = "error"
+
+	// EvaluationCacheHit and EvaluationCacheMiss are the results of evaluation cache lookups
+	EvaluationCacheHit  = "hit"
+	EvaluationCacheMiss = "miss"
+
+	// EventCoalesced and EventLost are the outcomes of change notifications overflowing the buffer of an event stream,
+	// which are either coalesced into a later notification of the stream or lost
+	EventCoalesced = "coalesced"
+	EventLost      = "lost"
+
+	// TypeBoolean, TypeString, TypeInteger, TypeFloat and TypeObject are the value types of flag evaluations, values of
+	// any other type, e.g. arrays, are TypeUnknown
+	TypeBoolean = "boolean"
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeFloat   = "float"
+	TypeObject  = "object"
+	TypeUnknown = "unknown"
 
 	httpRequestDurationMetric = "http.server.duration"
 	httpResponseSizeMetric    = "http.server.response.size"
 	httpActiveRequestsMetric  = "http.server.active_requests"
+	rpcDurationMetric         = "rpc.server.duration"
 	impressionMetric          = "feature_flag." + ProviderName + ".impression"
 	reasonMetric              = "feature_flag." + ProviderName + ".evaluation.reason"
+	reasonMixMetric           = ProviderName + ".reason"
+	syncBreakerStateMetric    = ProviderName + ".sync.circuit_breaker.state"
+	variantServedMetric       = ProviderName + ".variant.served"
+	configStalenessMetric     = ProviderName + ".config.staleness"
+	configWarningsMetric      = ProviderName + ".config.warnings"
+	configParseDurationMetric = ProviderName + ".config.parse.duration"
+	evaluationPanicMetric     = ProviderName + ".evaluation.panic"
+	evaluationTimeoutMetric   = ProviderName + ".evaluation.timeout"
+	operatorsExecutedMetric   = ProviderName + ".operators.executed"
+	contextSizeMetric         = ProviderName + ".evaluation.context.size"
+	typeMismatchMetric        = ProviderName + ".type_mismatch"
+	aliasHitMetric            = ProviderName + ".alias.hit"
+	missingContextKeyMetric   = ProviderName + ".targeting.missing_context_key"
+	evaluationCacheMetric     = ProviderName + ".evaluation.cache"
+	ofrepRequestsMetric       = ProviderName + ".ofrep.requests"
+	syncRetriesMetric         = ProviderName + ".sync.retries"
+	syncFailuresMetric        = ProviderName + ".sync.failures"
+	emptyConfigAppliedMetric  = ProviderName + ".empty_config.applied"
+	variantRefErrorsMetric    = ProviderName + ".variant_reference.errors"
+	versionRegressionMetric   = ProviderName + ".config.version.regression"
+	syncFlagsFilteredMetric   = ProviderName + ".sync.flags.filtered"
+	webhookFailuresMetric     = ProviderName + ".webhook.delivery.failures"
+	fractionalBucketMetric    = ProviderName + ".fractional.bucket"
+	openStreamsMetric         = ProviderName + ".streams.open"
+	rejectedStreamsMetric     = ProviderName + ".streams.rejected"
+	eventOverflowMetric       = ProviderName + ".events.overflow"
+	syncSourcesTotalMetric    = ProviderName + ".sync.sources.total"
+	syncSourcesActiveMetric   = ProviderName + ".sync.sources.active"
+	changeSubscribersMetric   = ProviderName + ".change.subscribers"
+	openConnectionsMetric     = ProviderName + ".connections.open"
+	syncGoroutinesMetric      = ProviderName + ".sync.goroutines"
+	syncRetainedBytesMetric   = ProviderName + ".sync.retained.bytes"
+	syncStalenessMetric       = ProviderName + ".sync.staleness"
+
+	// FractionalWeightKey holds the configured percentage of the bucket served by a fractional evaluation
+	FractionalWeightKey = attribute.Key("flagd.fractional.weight")
+
+	// maxServedVariants bounds the cardinality of the variant dimension of the served variants metric, variants seen
+	// after this limit has been reached are recorded in the otherVariant bucket
+	maxServedVariants = 20
+	otherVariant      = "other"
+
+	// unknownReason is recorded by the reason mix metric for reasons other than the evaluation reasons of flagd
+	unknownReason = "UNKNOWN"
+
+	// maxTimedOutFlags bounds the cardinality of the flag key dimension of the evaluation timeout metric, flags seen
+	// after this limit has been reached are recorded in the otherFlag bucket
+	maxTimedOutFlags = 100
+	otherFlag        = "other"
+
+	// maxOperatorFlags bounds the cardinality of the flag key dimension of the operators executed metric, flags seen
+	// after this limit has been reached are recorded in the otherFlag bucket
+	maxOperatorFlags = 100
+
+	// nativeHistogramMaxSize and nativeHistogramMaxScale bound the buckets of native histograms, matching the
+	// defaults of the OpenTelemetry SDK
+	nativeHistogramMaxSize  = 160
+	nativeHistogramMaxScale = 20
+
+	// the explicit buckets of the response size histogram grow by a factor of ten from 100 bytes up to the top
+	// boundary, which defaults to 1 GB
+	responseSizeMinBucket     = 100
+	responseSizeBucketFactor  = 10
+	DefaultResponseSizeBucket = 1e9
 )
 
+// operatorsExecutedBuckets are the explicit buckets of the operators executed histogram
+var operatorsExecutedBuckets = []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000}
+
+// evaluationReasons bound the reason dimension of the reason mix metric to the evaluation reasons of flagd
+var evaluationReasons = map[string]bool{
+	"STATIC":          true,
+	"DEFAULT":         true,
+	"TARGETING_MATCH": true,
+	"SPLIT":           true,
+	"DISABLED":        true,
+	"ERROR":           true,
+	"REGION_DEFAULT":  true,
+	unknownReason:     true,
+}
+
+// contextSizeBuckets are the explicit buckets of the evaluation context size histogram in bytes, from small contexts
+// of a few attributes up to contexts of a megabyte
+var contextSizeBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+// metricNames are the names of the metrics of the MetricsRecorder, which may be disabled by WithDisabledMetrics
+var metricNames = map[string]bool{
+	httpRequestDurationMetric: true,
+	httpResponseSizeMetric:    true,
+	httpActiveRequestsMetric:  true,
+	rpcDurationMetric:         true,
+	impressionMetric:          true,
+	reasonMetric:              true,
+	reasonMixMetric:           true,
+	syncBreakerStateMetric:    true,
+	variantServedMetric:       true,
+	configStalenessMetric:     true,
+	configWarningsMetric:      true,
+	configParseDurationMetric: true,
+	evaluationPanicMetric:     true,
+	evaluationTimeoutMetric:   true,
+	operatorsExecutedMetric:   true,
+	contextSizeMetric:         true,
+	typeMismatchMetric:        true,
+	aliasHitMetric:            true,
+	missingContextKeyMetric:   true,
+	evaluationCacheMetric:     true,
+	ofrepRequestsMetric:       true,
+	syncRetriesMetric:         true,
+	syncFailuresMetric:        true,
+	emptyConfigAppliedMetric:  true,
+	variantRefErrorsMetric:    true,
+	versionRegressionMetric:   true,
+	syncFlagsFilteredMetric:   true,
+	webhookFailuresMetric:     true,
+	fractionalBucketMetric:    true,
+	openStreamsMetric:         true,
+	rejectedStreamsMetric:     true,
+	eventOverflowMetric:       true,
+	syncSourcesTotalMetric:    true,
+	syncSourcesActiveMetric:   true,
+	changeSubscribersMetric:   true,
+	openConnectionsMetric:     true,
+	syncGoroutinesMetric:      true,
+	syncRetainedBytesMetric:   true,
+	syncStalenessMetric:       true,
+}
+
 type IMetricsRecorder interface {
 	HTTPAttributes(svcName, url, method, code string) []attribute.KeyValue
 	HTTPRequestDuration(ctx context.Context, duration time.Duration, attrs []attribute.KeyValue)
 	HTTPResponseSize(ctx context.Context, sizeBytes int64, attrs []attribute.KeyValue)
 	InFlightRequestStart(ctx context.Context, attrs []attribute.KeyValue)
 	InFlightRequestEnd(ctx context.Context, attrs []attribute.KeyValue)
-	RecordEvaluation(ctx context.Context, err error, reason, variant, key string)
-	Impressions(ctx context.Context, reason, variant, key string)
+	RPCDuration(ctx context.Context, service, method string, code int, duration time.Duration)
+	RecordEvaluation(ctx context.Context, err error, reason, variant, key, surface string)
+	Impressions(ctx context.Context, reason, variant, key, surface string)
+}
+
+// ISyncMetricsRecorder is implemented by recorders of the metrics of sync sources, see SyncMetrics
+type ISyncMetricsRecorder interface {
+	SyncCircuitBreakerState(ctx context.Context, source string, state int64)
+	SyncRetry(ctx context.Context, source string)
+	SyncFailure(ctx context.Context, source, failureType string)
+	SyncFlagsFiltered(ctx context.Context, source string, count int64)
+	SyncSources(configured int64, active func() int64)
+	SyncSourcesUsage(usage func() []SyncSourceUsage)
+	SyncStaleness(staleness func() time.Duration)
+}
+
+// IConfigMetricsRecorder is implemented by recorders of the metrics of applied flag configurations, see ConfigMetrics
+type IConfigMetricsRecorder interface {
+	ConfigStaleness(ctx context.Context, source string, staleness time.Duration)
+	ConfigWarning(ctx context.Context, source, category string)
+	ConfigParseDuration(ctx context.Context, source string, duration time.Duration)
+	EmptyConfigApplied(ctx context.Context, source string)
+	VariantReferenceError(ctx context.Context, source, kind string)
+	ConfigVersionRegression(ctx context.Context, source string)
+}
+
+// IEvaluationMetricsRecorder is implemented by recorders of the metrics of flag evaluations, see EvaluationMetrics
+type IEvaluationMetricsRecorder interface {
+	EvaluationPanic(ctx context.Context, key string)
+	EvaluationTimeout(ctx context.Context, key string)
+	OperatorsExecuted(ctx context.Context, key string, operators int64)
+	EvaluationContextSize(ctx context.Context, surface string, size int64)
+	TypeMismatch(ctx context.Context, requestedType, actualType string)
+	AliasHit(ctx context.Context, alias, key string)
+	MissingContextKey(ctx context.Context, contextKey string)
+	FractionalBucket(ctx context.Context, key, variant string, percentage float64)
+}
+
+// ICacheMetricsRecorder is implemented by recorders of the metrics of the evaluation cache, see CacheMetrics
+type ICacheMetricsRecorder interface {
+	EvaluationCacheLookup(ctx context.Context, key, result string)
+}
+
+// IServiceMetricsRecorder is implemented by recorders of the metrics of the services serving evaluations, streams
+// and change events, see ServiceMetrics
+type IServiceMetricsRecorder interface {
+	OFREPRequest(ctx context.Context, requestType, status string)
+	StreamOpened(ctx context.Context, streamType string)
+	StreamClosed(ctx context.Context, streamType string)
+	StreamRejected(ctx context.Context, streamType string)
+	ChangeSubscribers(subscribers func() int64)
+	OpenConnections(connections func() int64)
+	WebhookDeliveryFailure(ctx context.Context)
+	EventOverflow(ctx context.Context, eventType, outcome string)
+}
+
+// SyncMetrics returns the sync metrics recorder of the recorder, or a noop recorder if it doesn't record them
+func SyncMetrics(recorder IMetricsRecorder) ISyncMetricsRecorder {
+	if r, ok := recorder.(ISyncMetricsRecorder); ok {
+		return r
+	}
+	return NoopMetricsRecorder{}
+}
+
+// ConfigMetrics returns the configuration metrics recorder of the recorder, or a noop recorder if it doesn't record
+// them
+func ConfigMetrics(recorder IMetricsRecorder) IConfigMetricsRecorder {
+	if r, ok := recorder.(IConfigMetricsRecorder); ok {
+		return r
+	}
+	return NoopMetricsRecorder{}
+}
+
+// EvaluationMetrics returns the evaluation metrics recorder of the recorder, or a noop recorder if it doesn't record
+// them
+func EvaluationMetrics(recorder IMetricsRecorder) IEvaluationMetricsRecorder {
+	if r, ok := recorder.(IEvaluationMetricsRecorder); ok {
+		return r
+	}
+	return NoopMetricsRecorder{}
+}
+
+// CacheMetrics returns the evaluation cache metrics recorder of the recorder, or a noop recorder if it doesn't record
+// them
+func CacheMetrics(recorder IMetricsRecorder) ICacheMetricsRecorder {
+	if r, ok := recorder.(ICacheMetricsRecorder); ok {
+		return r
+	}
+	return NoopMetricsRecorder{}
+}
+
+// ServiceMetrics returns the service metrics recorder of the recorder, or a noop recorder if it doesn't record them
+func ServiceMetrics(recorder IMetricsRecorder) IServiceMetricsRecorder {
+	if r, ok := recorder.(IServiceMetricsRecorder); ok {
+		return r
+	}
+	return NoopMetricsRecorder{}
+}
+
+// SyncSourceUsage is the resource usage of a sync source, as observed by the sync source usage gauges
+type SyncSourceUsage struct {
+	Source string
+	// Goroutines is the number of goroutines watching the source
+	Goroutines int64
+	// RetainedBytes is the size of the configuration last received from the source
+	RetainedBytes int64
 }
 
 type NoopMetricsRecorder struct{}
@@ -54,18 +370,191 @@ func (NoopMetricsRecorder) InFlightRequestStart(_ context.Context, _ []attribute
 func (NoopMetricsRecorder) InFlightRequestEnd(_ context.Context, _ []attribute.KeyValue) {
 }
 
-func (NoopMetricsRecorder) RecordEvaluation(_ context.Context, _ error, _, _, _ string) {
+func (NoopMetricsRecorder) RPCDuration(_ context.Context, _, _ string, _ int, _ time.Duration) {
+}
+
+func (NoopMetricsRecorder) RecordEvaluation(_ context.Context, _ error, _, _, _, _ string) {
+}
+
+func (NoopMetricsRecorder) Impressions(_ context.Context, _, _, _, _ string) {
+}
+
+func (NoopMetricsRecorder) SyncCircuitBreakerState(_ context.Context, _ string, _ int64) {
+}
+
+func (NoopMetricsRecorder) ConfigStaleness(_ context.Context, _ string, _ time.Duration) {
+}
+
+func (NoopMetricsRecorder) ConfigWarning(_ context.Context, _, _ string) {
+}
+
+func (NoopMetricsRecorder) ConfigParseDuration(_ context.Context, _ string, _ time.Duration) {
+}
+
+func (NoopMetricsRecorder) EvaluationPanic(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) EvaluationTimeout(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) OperatorsExecuted(_ context.Context, _ string, _ int64) {
+}
+
+func (NoopMetricsRecorder) EvaluationContextSize(_ context.Context, _ string, _ int64) {
+}
+
+func (NoopMetricsRecorder) TypeMismatch(_ context.Context, _, _ string) {
+}
+
+func (NoopMetricsRecorder) AliasHit(_ context.Context, _, _ string) {
+}
+
+func (NoopMetricsRecorder) MissingContextKey(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) EvaluationCacheLookup(_ context.Context, _, _ string) {
+}
+
+func (NoopMetricsRecorder) OFREPRequest(_ context.Context, _, _ string) {
+}
+
+func (NoopMetricsRecorder) SyncRetry(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) SyncFailure(_ context.Context, _, _ string) {
+}
+
+func (NoopMetricsRecorder) EmptyConfigApplied(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) VariantReferenceError(_ context.Context, _, _ string) {
+}
+
+func (NoopMetricsRecorder) ConfigVersionRegression(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) SyncFlagsFiltered(_ context.Context, _ string, _ int64) {
+}
+
+func (NoopMetricsRecorder) WebhookDeliveryFailure(_ context.Context) {
+}
+
+func (NoopMetricsRecorder) FractionalBucket(_ context.Context, _, _ string, _ float64) {
+}
+
+func (NoopMetricsRecorder) StreamOpened(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) StreamClosed(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) StreamRejected(_ context.Context, _ string) {
+}
+
+func (NoopMetricsRecorder) EventOverflow(_ context.Context, _, _ string) {
+}
+
+func (NoopMetricsRecorder) SyncSources(_ int64, _ func() int64) {
+}
+
+func (NoopMetricsRecorder) ChangeSubscribers(_ func() int64) {
+}
+
+func (NoopMetricsRecorder) OpenConnections(_ func() int64) {
+}
+
+func (NoopMetricsRecorder) SyncSourcesUsage(_ func() []SyncSourceUsage) {
 }
 
-func (NoopMetricsRecorder) Impressions(_ context.Context, _, _, _ string) {
+func (NoopMetricsRecorder) SyncStaleness(_ func() time.Duration) {
 }
 
 type MetricsRecorder struct {
 	httpRequestDurHistogram   metric.Float64Histogram
 	httpResponseSizeHistogram metric.Float64Histogram
 	httpRequestsInflight      metric.Int64UpDownCounter
+	rpcDurHistogram           metric.Float64Histogram
 	impressions               metric.Int64Counter
 	reasons                   metric.Int64Counter
+	reasonMix                 metric.Int64Counter
+	syncBreakerState          metric.Int64Gauge
+	variantsServed            metric.Int64Counter
+	servedVariants            *boundedSet
+	configStaleness           metric.Float64Gauge
+	configWarnings            metric.Int64Counter
+	configParseDuration       metric.Float64Histogram
+	evaluationPanics          metric.Int64Counter
+	evaluationTimeouts        metric.Int64Counter
+	timedOutFlags             *boundedSet
+	operatorsExecuted         metric.Int64Histogram
+	contextSizes              metric.Int64Histogram
+	operatorFlags             *boundedSet
+	typeMismatches            metric.Int64Counter
+	aliasHits                 metric.Int64Counter
+	missingContextKeys        metric.Int64Counter
+	evaluationCacheLookups    metric.Int64Counter
+	ofrepRequests             metric.Int64Counter
+	syncRetries               metric.Int64Counter
+	syncFailures              metric.Int64Counter
+	emptyConfigsApplied       metric.Int64Counter
+	versionRegressions        metric.Int64Counter
+	variantRefErrors          metric.Int64Counter
+	syncFlagsFiltered         metric.Int64Counter
+	webhookFailures           metric.Int64Counter
+	fractionalBuckets         metric.Int64Counter
+	openStreams               metric.Int64UpDownCounter
+	rejectedStreams           metric.Int64Counter
+	eventOverflows            metric.Int64Counter
+	// syncSources holds the state observed by the sync source gauges, set once the sources are built
+	syncSources *atomic.Pointer[syncSourcesState]
+	// changeSubscribers counts the subscriptions observed by the change subscribers gauge, set once the service is built
+	changeSubscribers *atomic.Pointer[func() int64]
+	// openConnections counts the connections observed by the open connections gauge, set once the server listens
+	openConnections *atomic.Pointer[func() int64]
+	// syncSourcesUsage returns the usage observed by the sync source usage gauges, set once the sources are built
+	syncSourcesUsage *atomic.Pointer[func() []SyncSourceUsage]
+	// syncStaleness returns the staleness observed by the sync staleness gauge, set once the sources are built
+	syncStaleness *atomic.Pointer[func() time.Duration]
+}
+
+type syncSourcesState struct {
+	configured int64
+	active     func() int64
+}
+
+// boundedSet tracks up to limit distinct values, it is used to cap the cardinality of metric attributes
+type boundedSet struct {
+	mx     sync.RWMutex
+	limit  int
+	values map[string]struct{}
+}
+
+func newBoundedSet(limit int) *boundedSet {
+	return &boundedSet{
+		limit:  limit,
+		values: map[string]struct{}{},
+	}
+}
+
+// admit reports whether the value is tracked, adding it to the set if the limit has not been reached yet
+func (b *boundedSet) admit(value string) bool {
+	b.mx.RLock()
+	_, ok := b.values[value]
+	b.mx.RUnlock()
+	if ok {
+		return true
+	}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if _, ok := b.values[value]; ok {
+		return true
+	}
+	if len(b.values) >= b.limit {
+		return false
+	}
+	b.values[value] = struct{}{}
+	return true
 }
 
 func (r MetricsRecorder) HTTPAttributes(svcName, url, method, code string) []attribute.KeyValue {
@@ -93,23 +582,57 @@ func (r MetricsRecorder) InFlightRequestEnd(ctx context.Context, attrs []attribu
 	r.httpRequestsInflight.Add(ctx, -1, metric.WithAttributes(attrs...))
 }
 
-func (r MetricsRecorder) RecordEvaluation(ctx context.Context, err error, reason, variant, key string) {
+// RPCDuration records the duration of an RPC of the given service and method, along with its gRPC status code
+func (r MetricsRecorder) RPCDuration(ctx context.Context, service, method string, code int, duration time.Duration) {
+	r.rpcDurHistogram.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		semconv.RPCServiceKey.String(service),
+		semconv.RPCMethodKey.String(method),
+		semconv.RPCGRPCStatusCodeKey.Int(code),
+	))
+}
+
+// RecordEvaluation records the impression and reason of an evaluation requested through the given API surface, e.g.
+// APISurfaceOFREP
+func (r MetricsRecorder) RecordEvaluation(ctx context.Context, err error, reason, variant, key, surface string) {
 	if err == nil {
-		r.Impressions(ctx, reason, variant, key)
+		r.Impressions(ctx, reason, variant, key, surface)
+		r.VariantServed(ctx, variant)
+	}
+	r.Reasons(ctx, key, reason, err, surface)
+	r.ReasonMix(ctx, reason)
+}
+
+// ReasonMix records the reason of an evaluation, labeled only by the reason to chart the fleet-wide reason mix without
+// the cardinality of flag keys, API surfaces and errors. Reasons flagd doesn't know are recorded as "UNKNOWN".
+func (r MetricsRecorder) ReasonMix(ctx context.Context, reason string) {
+	if !evaluationReasons[reason] {
+		reason = unknownReason
 	}
-	r.Reasons(ctx, key, reason, err)
+	r.reasonMix.Add(ctx, 1, metric.WithAttributes(FeatureFlagReason(reason)))
 }
 
-func (r MetricsRecorder) Impressions(ctx context.Context, reason, variant, key string) {
+// VariantServed records a served variant, labeled only by the variant name to chart the fleet-wide variant mix
+// without the cardinality of flag keys. Once maxServedVariants distinct variant names were recorded, further
+// variants are recorded as "other".
+func (r MetricsRecorder) VariantServed(ctx context.Context, variant string) {
+	if !r.servedVariants.admit(variant) {
+		variant = otherVariant
+	}
+	r.variantsServed.Add(ctx, 1, metric.WithAttributes(semconv.FeatureFlagVariant(variant)))
+}
+
+func (r MetricsRecorder) Impressions(ctx context.Context, reason, variant, key, surface string) {
 	r.impressions.Add(ctx,
 		1,
-		metric.WithAttributes(append(SemConvFeatureFlagAttributes(key, variant), FeatureFlagReason(reason))...))
+		metric.WithAttributes(append(SemConvFeatureFlagAttributes(key, variant),
+			FeatureFlagReason(reason), APISurface(surface))...))
 }
 
-func (r MetricsRecorder) Reasons(ctx context.Context, key string, reason string, err error) {
+func (r MetricsRecorder) Reasons(ctx context.Context, key string, reason string, err error, surface string) {
 	attrs := []attribute.KeyValue{
 		semconv.FeatureFlagProviderName(ProviderName),
 		FeatureFlagReason(reason),
+		APISurface(surface),
 	}
 	if err == nil {
 		// record flag key only if evaluation is successful
@@ -121,21 +644,220 @@ func (r MetricsRecorder) Reasons(ctx context.Context, key string, reason string,
 	r.reasons.Add(ctx, 1, metric.WithAttributes(attrs...))
 }
 
-func getDurationView(svcName, viewName string, bucket []float64) msdk.View {
+// SyncCircuitBreakerState records the circuit breaker state of a sync source
+func (r MetricsRecorder) SyncCircuitBreakerState(ctx context.Context, source string, state int64) {
+	r.syncBreakerState.Record(ctx, state, metric.WithAttributes(SyncSource(source)))
+}
+
+// ConfigStaleness records the age of a flag configuration of a source at the time it was applied
+func (r MetricsRecorder) ConfigStaleness(ctx context.Context, source string, staleness time.Duration) {
+	r.configStaleness.Record(ctx, staleness.Seconds(), metric.WithAttributes(SyncSource(source)))
+}
+
+// ConfigWarning records a non-fatal issue of a flag configuration of a source, e.g. a deprecated operator
+// (ConfigDeprecatedOperator)
+func (r MetricsRecorder) ConfigWarning(ctx context.Context, source, category string) {
+	r.configWarnings.Add(ctx, 1, metric.WithAttributes(SyncSource(source), ConfigWarningKey.String(category)))
+}
+
+// ConfigParseDuration records the duration of parsing and validating a flag configuration of a source, whether it
+// was valid or not. It excludes applying the flags of a valid configuration to the store.
+func (r MetricsRecorder) ConfigParseDuration(ctx context.Context, source string, duration time.Duration) {
+	r.configParseDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(SyncSource(source)))
+}
+
+// EvaluationPanic records a panic recovered during the evaluation of a flag
+func (r MetricsRecorder) EvaluationPanic(ctx context.Context, key string) {
+	r.evaluationPanics.Add(ctx, 1, metric.WithAttributes(semconv.FeatureFlagKey(key)))
+}
+
+// EvaluationTimeout records an evaluation of a flag cancelled by its deadline
+func (r MetricsRecorder) EvaluationTimeout(ctx context.Context, key string) {
+	if !r.timedOutFlags.admit(key) {
+		key = otherFlag
+	}
+	r.evaluationTimeouts.Add(ctx, 1, metric.WithAttributes(semconv.FeatureFlagKey(key)))
+}
+
+// OperatorsExecuted records the number of operators executed by an evaluation of the targeting of a flag
+func (r MetricsRecorder) OperatorsExecuted(ctx context.Context, key string, operators int64) {
+	if !r.operatorFlags.admit(key) {
+		key = otherFlag
+	}
+	r.operatorsExecuted.Record(ctx, operators, metric.WithAttributes(semconv.FeatureFlagKey(key)))
+}
+
+// EvaluationContextSize records the size in bytes of an evaluation context accepted by the given API surface
+func (r MetricsRecorder) EvaluationContextSize(ctx context.Context, surface string, size int64) {
+	r.contextSizes.Record(ctx, size, metric.WithAttributes(APISurface(surface)))
+}
+
+// AliasHit records an evaluation of a flag requested by one of its aliases
+func (r MetricsRecorder) AliasHit(ctx context.Context, alias, key string) {
+	r.aliasHits.Add(ctx, 1, metric.WithAttributes(AliasKey.String(alias), semconv.FeatureFlagKey(key)))
+}
+
+// MissingContextKey records an evaluation of a targeting rule referencing a context key absent from the evaluation
+// context. The context keys are bounded by the keys the evaluator is configured to track.
+func (r MetricsRecorder) MissingContextKey(ctx context.Context, contextKey string) {
+	r.missingContextKeys.Add(ctx, 1, metric.WithAttributes(ContextKeyKey.String(contextKey)))
+}
+
+// EvaluationCacheLookup records a lookup of the evaluation cache for a flag opted into caching, which either served
+// a cached result (EvaluationCacheHit) or evaluated the flag (EvaluationCacheMiss)
+func (r MetricsRecorder) EvaluationCacheLookup(ctx context.Context, key, result string) {
+	r.evaluationCacheLookups.Add(ctx, 1, metric.WithAttributes(semconv.FeatureFlagKey(key),
+		CacheResultKey.String(result)))
+}
+
+// OFREPRequest records an OFREP evaluation request, either of a single flag (OFREPSingleRequest), of all flags
+// (OFREPBulkRequest) or of a single flag against multiple contexts (OFREPBatchRequest), along with its status
+func (r MetricsRecorder) OFREPRequest(ctx context.Context, requestType, status string) {
+	r.ofrepRequests.Add(ctx, 1, metric.WithAttributes(OFREPRequestTypeKey.String(requestType),
+		OFREPStatusKey.String(status)))
+}
+
+// TypeMismatch records an evaluation requesting a flag as a type other than the type of its variant
+func (r MetricsRecorder) TypeMismatch(ctx context.Context, requestedType, actualType string) {
+	r.typeMismatches.Add(ctx, 1, metric.WithAttributes(RequestedTypeKey.String(requestedType),
+		ActualTypeKey.String(actualType)))
+}
+
+// SyncRetry records a fetch or connection attempt of a sync source following a failed attempt
+func (r MetricsRecorder) SyncRetry(ctx context.Context, source string) {
+	r.syncRetries.Add(ctx, 1, metric.WithAttributes(SyncSource(source)))
+}
+
+// SyncFailure records a failure of a sync source, either a failed fetch or connection attempt (SyncFetchFailure) or a
+// flag configuration which could not be applied (SyncParseFailure, SyncFlagLimitFailure, SyncEmptyConfigFailure,
+// SyncVariantRefFailure, SyncVersionRegressionFailure)
+func (r MetricsRecorder) SyncFailure(ctx context.Context, source, failureType string) {
+	r.syncFailures.Add(ctx, 1, metric.WithAttributes(SyncSource(source), SyncFailureTypeKey.String(failureType)))
+}
+
+// EmptyConfigApplied records a flag configuration of a sync source defining no flags which replaced the flags of the
+// source, as empty configurations are allowed
+func (r MetricsRecorder) EmptyConfigApplied(ctx context.Context, source string) {
+	r.emptyConfigsApplied.Add(ctx, 1, metric.WithAttributes(SyncSource(source)))
+}
+
+// VariantReferenceError records a flag of a flag configuration of a source referencing a variant it doesn't define,
+// either as default variant (VariantRefDefault) or as result of its targeting (VariantRefTargeting)
+func (r MetricsRecorder) VariantReferenceError(ctx context.Context, source, kind string) {
+	r.variantRefErrors.Add(ctx, 1, metric.WithAttributes(SyncSource(source), VariantRefKindKey.String(kind)))
+}
+
+// ConfigVersionRegression records a flag configuration of a sync source whose monotonic version is older than the
+// version of the applied configuration of the source, whether it was rejected or applied
+func (r MetricsRecorder) ConfigVersionRegression(ctx context.Context, source string) {
+	r.versionRegressions.Add(ctx, 1, metric.WithAttributes(SyncSource(source)))
+}
+
+// SyncFlagsFiltered records flags of a sync source which were filtered out by the flag key filter of the source
+func (r MetricsRecorder) SyncFlagsFiltered(ctx context.Context, source string, count int64) {
+	r.syncFlagsFiltered.Add(ctx, count, metric.WithAttributes(SyncSource(source)))
+}
+
+// WebhookDeliveryFailure records a change event which could not be delivered to the webhook
+func (r MetricsRecorder) WebhookDeliveryFailure(ctx context.Context) {
+	r.webhookFailures.Add(ctx, 1)
+}
+
+// FractionalBucket records the bucket served by a fractional evaluation along with its configured percentage, so that
+// the observed distribution of a flag can be compared with its configured weights
+func (r MetricsRecorder) FractionalBucket(ctx context.Context, key, variant string, percentage float64) {
+	r.fractionalBuckets.Add(ctx, 1, metric.WithAttributes(
+		append(SemConvFeatureFlagAttributes(key, variant), FractionalWeightKey.Float64(percentage))...))
+}
+
+// StreamOpened records a stream of the given type, e.g. a sync or event stream, being opened
+func (r MetricsRecorder) StreamOpened(ctx context.Context, streamType string) {
+	r.openStreams.Add(ctx, 1, metric.WithAttributes(StreamType(streamType)))
+}
+
+// StreamClosed records a stream of the given type being closed
+func (r MetricsRecorder) StreamClosed(ctx context.Context, streamType string) {
+	r.openStreams.Add(ctx, -1, metric.WithAttributes(StreamType(streamType)))
+}
+
+// StreamRejected records a stream of the given type which was rejected, as the limit of concurrent streams was reached
+func (r MetricsRecorder) StreamRejected(ctx context.Context, streamType string) {
+	r.rejectedStreams.Add(ctx, 1, metric.WithAttributes(StreamType(streamType)))
+}
+
+// EventOverflow records a notification of the given type which overflowed the buffer of an event stream, either
+// coalesced into a later notification (EventCoalesced) or lost (EventLost)
+func (r MetricsRecorder) EventOverflow(ctx context.Context, eventType, outcome string) {
+	r.eventOverflows.Add(ctx, 1, metric.WithAttributes(EventTypeKey.String(eventType), EventOutcomeKey.String(outcome)))
+}
+
+// SyncSources reports the number of configured sync sources and the number of active sources through observable
+// gauges. The active function is called on each collection.
+func (r MetricsRecorder) SyncSources(configured int64, active func() int64) {
+	r.syncSources.Store(&syncSourcesState{configured: configured, active: active})
+}
+
+// ChangeSubscribers reports the number of active change event subscriptions through an observable gauge. The
+// subscribers function is called on each collection.
+func (r MetricsRecorder) ChangeSubscribers(subscribers func() int64) {
+	r.changeSubscribers.Store(&subscribers)
+}
+
+// OpenConnections reports the number of connections accepted by the flag evaluation server, which are not closed yet,
+// through an observable gauge. The connections function is called on each collection.
+func (r MetricsRecorder) OpenConnections(connections func() int64) {
+	r.openConnections.Store(&connections)
+}
+
+// SyncSourcesUsage reports the goroutines and the retained configuration bytes of each sync source through observable
+// gauges. The usage function is called on each collection.
+func (r MetricsRecorder) SyncSourcesUsage(usage func() []SyncSourceUsage) {
+	r.syncSourcesUsage.Store(&usage)
+}
+
+// SyncStaleness reports the duration since all sync sources were lost, zero while a source is active, through an
+// observable gauge. The staleness function is called on each collection.
+func (r MetricsRecorder) SyncStaleness(staleness func() time.Duration) {
+	r.syncStaleness.Store(&staleness)
+}
+
+// getDurationView configures the aggregation of a histogram, either as native (base-2 exponential) histogram or with
+// the given explicit bucket boundaries, and its exemplar reservoir, the default reservoir is used if it is nil
+func getDurationView(
+	scopeName, instrumentName string, bucket []float64, native bool, reservoir msdk.ExemplarReservoirProviderSelector,
+) msdk.View {
+	var aggregation msdk.Aggregation = msdk.AggregationExplicitBucketHistogram{
+		Boundaries: bucket,
+	}
+	if native {
+		aggregation = msdk.AggregationBase2ExponentialHistogram{
+			MaxSize:  nativeHistogramMaxSize,
+			MaxScale: nativeHistogramMaxScale,
+		}
+	}
+
 	return msdk.NewView(
 		msdk.Instrument{
 			// we change aggregation only for instruments with this name and scope
-			Name: viewName,
+			Name: instrumentName,
 			Scope: instrumentation.Scope{
-				Name: svcName,
+				Name: scopeName,
 			},
 		},
-		msdk.Stream{Aggregation: msdk.AggregationExplicitBucketHistogram{
-			Boundaries: bucket,
-		}},
+		msdk.Stream{Aggregation: aggregation, ExemplarReservoirProviderSelector: reservoir},
 	)
 }
 
+// responseSizeBuckets returns the explicit buckets of the response size histogram, growing by a factor of ten from
+// 100 bytes and ending with the given top boundary
+func responseSizeBuckets(top float64) []float64 {
+	var buckets []float64
+	for bucket := float64(responseSizeMinBucket); bucket < top; bucket *= responseSizeBucketFactor {
+		buckets = append(buckets, bucket)
+	}
+	return append(buckets, top)
+}
+
 func FeatureFlagReason(val string) attribute.KeyValue {
 	return FeatureFlagReasonKey.String(val)
 }
@@ -144,54 +866,574 @@ func ExceptionType(val string) attribute.KeyValue {
 	return ExceptionTypeKey.String(val)
 }
 
+// APISurface returns the API surface attribute of an evaluation, bounded to the known API surfaces
+func APISurface(val string) attribute.KeyValue {
+	switch val {
+	case APISurfaceGRPC, APISurfaceOFREP, APISurfaceREST:
+		return APISurfaceKey.String(val)
+	default:
+		return APISurfaceKey.String(APISurfaceInProcess)
+	}
+}
+
+func SyncSource(val string) attribute.KeyValue {
+	return SyncSourceKey.String(val)
+}
+
+func StreamType(val string) attribute.KeyValue {
+	return StreamTypeKey.String(val)
+}
+
+// RecorderOption configures the MetricsRecorder created by NewOTelRecorder
+type RecorderOption func(o *recorderOptions)
+
+type recorderOptions struct {
+	scopeName      string
+	scopeVersion   string
+	exportInterval time.Duration
+	temporality    string
+	// nativeHistograms records the duration and size histograms as native histograms instead of explicit buckets
+	nativeHistograms bool
+	// slowestExemplarsInterval keeps the slowest request of each bucket of the request duration histogram as exemplar
+	// within the interval, zero keeps the default reservoir
+	slowestExemplarsInterval time.Duration
+	// responseSizeMaxBucket is the top boundary of the explicit buckets of the response size histogram in bytes
+	responseSizeMaxBucket float64
+	// disabledMetrics are the names of the metrics which are neither recorded nor exported
+	disabledMetrics map[string]bool
+	// stdoutWriter receives the metrics printed by the stdout exporter
+	stdoutWriter io.Writer
+	// the Prometheus exporter registers its collector with the registerer, whose metrics are gathered by the gatherer
+	prometheusRegisterer prometheus.Registerer
+	prometheusGatherer   prometheus.Gatherer
+}
+
+func newRecorderOptions(serviceName string, opts ...RecorderOption) recorderOptions {
+	options := recorderOptions{
+		scopeName:      serviceName,
+		exportInterval: DefaultExportInterval,
+		temporality:    TemporalityCumulative,
+		// the default yields the 8 buckets from 100 B to 1 GB
+		responseSizeMaxBucket: DefaultResponseSizeBucket,
+		stdoutWriter:          os.Stdout,
+		prometheusRegisterer:  prometheus.DefaultRegisterer,
+		prometheusGatherer:    prometheus.DefaultGatherer,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+	return options
+}
+
+// WithScopeName overrides the instrumentation scope name of the recorded metrics, which defaults to the service name.
+// This allows multiple components within one process to report metrics under separate scopes.
+func WithScopeName(name string) RecorderOption {
+	return func(o *recorderOptions) {
+		if name != "" {
+			o.scopeName = name
+		}
+	}
+}
+
+// WithScopeVersion sets the instrumentation scope version of the recorded metrics
+func WithScopeVersion(version string) RecorderOption {
+	return func(o *recorderOptions) {
+		o.scopeVersion = version
+	}
+}
+
+// WithExportInterval sets the interval of the periodic reader pushing metrics to the OTEL collector, which defaults to
+// two seconds. It applies to the readers built by BuildMetricsRecorder and has no effect on pulled Prometheus metrics.
+func WithExportInterval(interval time.Duration) RecorderOption {
+	return func(o *recorderOptions) {
+		if interval != 0 {
+			o.exportInterval = interval
+		}
+	}
+}
+
+// WithTemporality selects the aggregation temporality of the metrics pushed to the OTEL collector or printed to stdout,
+// either TemporalityCumulative (default) or TemporalityDelta. It applies to the readers built by BuildMetricsRecorder.
+func WithTemporality(temporality string) RecorderOption {
+	return func(o *recorderOptions) {
+		if temporality != "" {
+			o.temporality = temporality
+		}
+	}
+}
+
+// WithStdoutWriter sets the writer the stdout metrics exporter prints the metrics to on each export, which defaults to
+// os.Stdout. It applies to the readers built by BuildMetricsRecorder with the stdout metrics exporter.
+func WithStdoutWriter(w io.Writer) RecorderOption {
+	return func(o *recorderOptions) {
+		if w != nil {
+			o.stdoutWriter = w
+		}
+	}
+}
+
+// WithNativeHistograms records the request duration and response size histograms as native (base-2 exponential)
+// histograms, which keep their resolution at a fixed cost of series. Otherwise, they use explicit buckets.
+func WithNativeHistograms(enabled bool) RecorderOption {
+	return func(o *recorderOptions) {
+		o.nativeHistograms = enabled
+	}
+}
+
+// WithSlowestExemplars keeps the slowest request of each bucket of the request duration histogram as exemplar, until
+// it is older than the interval, instead of the most recent request. This keeps the slow outliers at high request
+// rates. It has no effect if exemplars are disabled or the interval is zero.
+func WithSlowestExemplars(interval time.Duration) RecorderOption {
+	return func(o *recorderOptions) {
+		o.slowestExemplarsInterval = interval
+	}
+}
+
+// WithResponseSizeMaxBucket sets the top boundary in bytes of the explicit buckets of the response size histogram,
+// which defaults to DefaultResponseSizeBucket. The buckets grow by a factor of ten from 100 bytes up to the boundary, so
+// that a higher boundary distinguishes large responses, e.g. of object flags, instead of counting them in the +Inf
+// bucket. It has no effect on native histograms or if the boundary isn't positive.
+func WithResponseSizeMaxBucket(bytes float64) RecorderOption {
+	return func(o *recorderOptions) {
+		if bytes > 0 {
+			o.responseSizeMaxBucket = bytes
+		}
+	}
+}
+
+// WithDisabledMetrics disables the metrics of the given names, e.g. "feature_flag.flagd.evaluation.reason". Disabled
+// metrics are neither recorded nor exported, while recording them through the MetricsRecorder stays safe.
+func WithDisabledMetrics(names ...string) RecorderOption {
+	return func(o *recorderOptions) {
+		if o.disabledMetrics == nil {
+			o.disabledMetrics = map[string]bool{}
+		}
+		for _, name := range names {
+			o.disabledMetrics[name] = true
+		}
+	}
+}
+
+// IsMetricName reports whether the name is the name of a metric of the MetricsRecorder
+func IsMetricName(name string) bool {
+	return metricNames[name]
+}
+
 // NewOTelRecorder creates a MetricsRecorder based on the provided metric.Reader. Note that, metric.NewMeterProvider is
 // created here but not registered globally as this is the only place we derive a metric.Meter. Consider global provider
-// registration if we need more meters
-func NewOTelRecorder(exporter msdk.Reader, resource *resource.Resource, serviceName string) *MetricsRecorder {
+// registration if we need more meters. Errors creating the instruments are reported to the OpenTelemetry error
+// handler, see RegisterErrorHandling.
+func NewOTelRecorder(
+	exporter msdk.Reader, resource *resource.Resource, serviceName string, opts ...RecorderOption,
+) *MetricsRecorder {
+	recorder, err := newOTelRecorder(exporter, resource, serviceName, opts...)
+	if err != nil {
+		otel.Handle(err)
+	}
+	return recorder
+}
+
+// newOTelRecorder creates the MetricsRecorder of NewOTelRecorder, along with the joined errors creating its
+// instruments
+// nolint: funlen
+func newOTelRecorder(
+	exporter msdk.Reader, resource *resource.Resource, serviceName string, opts ...RecorderOption,
+) (*MetricsRecorder, error) {
+	options := newRecorderOptions(serviceName, opts...)
+
+	var durationExemplars msdk.ExemplarReservoirProviderSelector
+	if options.slowestExemplarsInterval > 0 && ExemplarsEnabled() {
+		durationExemplars = slowestExemplars(prometheus.DefBuckets, options.slowestExemplarsInterval)
+	}
+
 	// create a metric provider with custom bucket size for histograms
 	provider := msdk.NewMeterProvider(
 		msdk.WithReader(exporter),
 		// for the request duration metric we use the default bucket size which are tailored for response time in seconds
-		msdk.WithView(getDurationView(httpRequestDurationMetric, serviceName, prometheus.DefBuckets)),
-		// for response size we want 8 exponential bucket starting from 100 Bytes
-		msdk.WithView(getDurationView(httpResponseSizeMetric, serviceName, prometheus.ExponentialBuckets(100, 10, 8))),
+		msdk.WithView(getDurationView(options.scopeName, httpRequestDurationMetric, prometheus.DefBuckets,
+			options.nativeHistograms, durationExemplars)),
+		// RPCs are measured with the same buckets as HTTP requests
+		msdk.WithView(getDurationView(options.scopeName, rpcDurationMetric, prometheus.DefBuckets,
+			options.nativeHistograms, nil)),
+		// parsing and validating configurations takes milliseconds up to seconds for large configurations
+		msdk.WithView(getDurationView(options.scopeName, configParseDurationMetric, prometheus.DefBuckets,
+			options.nativeHistograms, nil)),
+		// targeting rules execute a handful of operators, pathological ones up to thousands
+		msdk.WithView(getDurationView(options.scopeName, operatorsExecutedMetric, operatorsExecutedBuckets,
+			options.nativeHistograms, nil)),
+		// evaluation contexts range from a few attributes to megabytes shipped by misbehaving clients
+		msdk.WithView(getDurationView(options.scopeName, contextSizeMetric, contextSizeBuckets,
+			options.nativeHistograms, nil)),
+		// for response size we want exponential buckets starting from 100 Bytes
+		msdk.WithView(getDurationView(options.scopeName, httpResponseSizeMetric,
+			responseSizeBuckets(options.responseSizeMaxBucket), options.nativeHistograms, nil)),
 		// set entity producing telemetry
 		msdk.WithResource(resource),
 	)
 
-	meter := provider.Meter(serviceName)
+	meter := provider.Meter(options.scopeName, metric.WithInstrumentationVersion(options.scopeVersion))
+	// disabled metrics are built by a noop meter, so that they are neither exported nor recorded
+	noopMeter := noop.NewMeterProvider().Meter(options.scopeName)
+	instruments := func(name string) metric.Meter {
+		if options.disabledMetrics[name] {
+			return noopMeter
+		}
+		return meter
+	}
 
-	// we can ignore errors from OpenTelemetry since they could occur if we select the wrong aggregator
-	hduration, _ := meter.Float64Histogram(
+	// errors creating an instrument are collected, the instrument returned along with the error is still safe to use
+	var errs []error
+	hduration, err := instruments(httpRequestDurationMetric).Float64Histogram(
 		httpRequestDurationMetric,
 		metric.WithDescription("Measures the duration of inbound HTTP requests."),
 		metric.WithUnit("s"),
 	)
-	hsize, _ := meter.Float64Histogram(
+	errs = append(errs, err)
+	hsize, err := instruments(httpResponseSizeMetric).Float64Histogram(
 		httpResponseSizeMetric,
 		metric.WithDescription("Measures the size of HTTP request messages (compressed)."),
 		metric.WithUnit("By"),
 	)
-	reqCounter, _ := meter.Int64UpDownCounter(
+	errs = append(errs, err)
+	reqCounter, err := instruments(httpActiveRequestsMetric).Int64UpDownCounter(
 		httpActiveRequestsMetric,
 		metric.WithDescription("Measures the number of concurrent HTTP requests that are currently in-flight."),
 		metric.WithUnit("{request}"),
 	)
-	impressions, _ := meter.Int64Counter(
+	errs = append(errs, err)
+	rpcDuration, err := instruments(rpcDurationMetric).Float64Histogram(
+		rpcDurationMetric,
+		metric.WithDescription("Measures the duration of inbound RPCs."),
+		metric.WithUnit("s"),
+	)
+	errs = append(errs, err)
+	impressions, err := instruments(impressionMetric).Int64Counter(
 		impressionMetric,
 		metric.WithDescription("Measures the number of evaluations for a given flag."),
 		metric.WithUnit("{impression}"),
 	)
-	reasons, _ := meter.Int64Counter(
+	errs = append(errs, err)
+	reasons, err := instruments(reasonMetric).Int64Counter(
 		reasonMetric,
 		metric.WithDescription("Measures the number of evaluations for a given reason."),
 		metric.WithUnit("{reason}"),
 	)
+	errs = append(errs, err)
+	reasonMix, err := instruments(reasonMixMetric).Int64Counter(
+		reasonMixMetric,
+		metric.WithDescription("Measures the number of evaluations for a given reason across all flags."),
+		metric.WithUnit("{evaluation}"),
+	)
+	errs = append(errs, err)
+	syncBreakerState, err := instruments(syncBreakerStateMetric).Int64Gauge(
+		syncBreakerStateMetric,
+		metric.WithDescription("Reports the circuit breaker state of a sync source (0 closed, 1 open, 2 half-open)."),
+		metric.WithUnit("{state}"),
+	)
+	errs = append(errs, err)
+	variantsServed, err := instruments(variantServedMetric).Int64Counter(
+		variantServedMetric,
+		metric.WithDescription("Measures the number of successful evaluations for a given variant across all flags."),
+		metric.WithUnit("{evaluation}"),
+	)
+	errs = append(errs, err)
+	configStaleness, err := instruments(configStalenessMetric).Float64Gauge(
+		configStalenessMetric,
+		metric.WithDescription("Measures the age of a flag configuration, based on its last modification timestamp, "+
+			"at the time it was applied."),
+		metric.WithUnit("s"),
+	)
+	errs = append(errs, err)
+	configWarnings, err := instruments(configWarningsMetric).Int64Counter(
+		configWarningsMetric,
+		metric.WithDescription("Measures the number of non-fatal issues of flag configurations, e.g. deprecated "+
+			"operators, found when the configurations were applied."),
+		metric.WithUnit("{warning}"),
+	)
+	errs = append(errs, err)
+	configParseDuration, err := instruments(configParseDurationMetric).Float64Histogram(
+		configParseDurationMetric,
+		metric.WithDescription("Measures the duration of parsing and validating the flag configurations of sync "+
+			"sources."),
+		metric.WithUnit("s"),
+	)
+	errs = append(errs, err)
+	evaluationPanics, err := instruments(evaluationPanicMetric).Int64Counter(
+		evaluationPanicMetric,
+		metric.WithDescription("Measures the number of panics recovered during flag evaluations."),
+		metric.WithUnit("{panic}"),
+	)
+	errs = append(errs, err)
+	evaluationTimeouts, err := instruments(evaluationTimeoutMetric).Int64Counter(
+		evaluationTimeoutMetric,
+		metric.WithDescription("Measures the number of flag evaluations cancelled by their deadline."),
+		metric.WithUnit("{evaluation}"),
+	)
+	errs = append(errs, err)
+	operatorsExecuted, err := instruments(operatorsExecutedMetric).Int64Histogram(
+		operatorsExecutedMetric,
+		metric.WithDescription("Measures the number of operators executed per evaluation of the targeting rules of a "+
+			"flag."),
+		metric.WithUnit("{operator}"),
+	)
+	errs = append(errs, err)
+	contextSizes, err := instruments(contextSizeMetric).Int64Histogram(
+		contextSizeMetric,
+		metric.WithDescription("Measures the size of the evaluation contexts accepted for evaluation."),
+		metric.WithUnit("By"),
+	)
+	errs = append(errs, err)
+	typeMismatches, err := instruments(typeMismatchMetric).Int64Counter(
+		typeMismatchMetric,
+		metric.WithDescription("Measures the number of evaluations requesting a flag as a type other than the type of "+
+			"its variant."),
+		metric.WithUnit("{evaluation}"),
+	)
+	errs = append(errs, err)
+	aliasHits, err := instruments(aliasHitMetric).Int64Counter(
+		aliasHitMetric,
+		metric.WithDescription("Measures the number of evaluations requesting a flag by one of its aliases."),
+		metric.WithUnit("{evaluation}"),
+	)
+	errs = append(errs, err)
+	missingContextKeys, err := instruments(missingContextKeyMetric).Int64Counter(
+		missingContextKeyMetric,
+		metric.WithDescription("Measures the number of targeting evaluations referencing a context key absent from the "+
+			"evaluation context."),
+		metric.WithUnit("{evaluation}"),
+	)
+	errs = append(errs, err)
+	evaluationCacheLookups, err := instruments(evaluationCacheMetric).Int64Counter(
+		evaluationCacheMetric,
+		metric.WithDescription("Measures the number of evaluation cache lookups of flags opted into caching by "+
+			"result."),
+		metric.WithUnit("{lookup}"),
+	)
+	errs = append(errs, err)
+	ofrepRequests, err := instruments(ofrepRequestsMetric).Int64Counter(
+		ofrepRequestsMetric,
+		metric.WithDescription("Measures the number of OFREP evaluation requests by request type and status."),
+		metric.WithUnit("{request}"),
+	)
+	errs = append(errs, err)
+	syncRetries, err := instruments(syncRetriesMetric).Int64Counter(
+		syncRetriesMetric,
+		metric.WithDescription("Measures the number of fetch or connection attempts of a sync source following a failure."),
+		metric.WithUnit("{retry}"),
+	)
+	errs = append(errs, err)
+	syncFailures, err := instruments(syncFailuresMetric).Int64Counter(
+		syncFailuresMetric,
+		metric.WithDescription("Measures the number of failed fetch or connection attempts of a sync source, and of "+
+			"flag configurations of a sync source which could not be parsed."),
+		metric.WithUnit("{failure}"),
+	)
+	errs = append(errs, err)
+	emptyConfigsApplied, err := instruments(emptyConfigAppliedMetric).Int64Counter(
+		emptyConfigAppliedMetric,
+		metric.WithDescription("Measures the number of applied flag configurations of a sync source defining no flags."),
+		metric.WithUnit("{configuration}"),
+	)
+	errs = append(errs, err)
+	versionRegressions, err := instruments(versionRegressionMetric).Int64Counter(
+		versionRegressionMetric,
+		metric.WithDescription("Measures the number of flag configurations of a sync source with an older version "+
+			"than the applied configuration of the source."),
+		metric.WithUnit("{configuration}"),
+	)
+	errs = append(errs, err)
+	variantRefErrors, err := instruments(variantRefErrorsMetric).Int64Counter(
+		variantRefErrorsMetric,
+		metric.WithDescription("Measures the number of references of flags to variants they don't define, found "+
+			"when the flag configurations were loaded."),
+		metric.WithUnit("{error}"),
+	)
+	errs = append(errs, err)
+	syncFlagsFiltered, err := instruments(syncFlagsFilteredMetric).Int64Counter(
+		syncFlagsFilteredMetric,
+		metric.WithDescription("Measures the number of flags of a sync source filtered out by its flag key filter."),
+		metric.WithUnit("{flag}"),
+	)
+	errs = append(errs, err)
+	webhookFailures, err := instruments(webhookFailuresMetric).Int64Counter(
+		webhookFailuresMetric,
+		metric.WithDescription("Measures the number of flag change events which could not be delivered to the webhook."),
+		metric.WithUnit("{event}"),
+	)
+	errs = append(errs, err)
+	fractionalBuckets, err := instruments(fractionalBucketMetric).Int64Counter(
+		fractionalBucketMetric,
+		metric.WithDescription("Measures the number of fractional evaluations for a given flag and bucket."),
+		metric.WithUnit("{evaluation}"),
+	)
+	errs = append(errs, err)
+	openStreams, err := instruments(openStreamsMetric).Int64UpDownCounter(
+		openStreamsMetric,
+		metric.WithDescription("Measures the number of currently open sync and event streams."),
+		metric.WithUnit("{stream}"),
+	)
+	errs = append(errs, err)
+	rejectedStreams, err := instruments(rejectedStreamsMetric).Int64Counter(
+		rejectedStreamsMetric,
+		metric.WithDescription("Measures the number of streams rejected as the limit of concurrent streams was reached."),
+		metric.WithUnit("{stream}"),
+	)
+	errs = append(errs, err)
+	eventOverflows, err := instruments(eventOverflowMetric).Int64Counter(
+		eventOverflowMetric,
+		metric.WithDescription("Measures the number of change notifications which overflowed the buffer of an event "+
+			"stream, either coalesced into a later notification or lost."),
+		metric.WithUnit("{notification}"),
+	)
+	errs = append(errs, err)
+	syncSources := &atomic.Pointer[syncSourcesState]{}
+	syncSourcesTotal, err := instruments(syncSourcesTotalMetric).Int64ObservableGauge(
+		syncSourcesTotalMetric,
+		metric.WithDescription("Reports the number of configured sync sources."),
+		metric.WithUnit("{source}"),
+	)
+	errs = append(errs, err)
+	syncSourcesActive, err := instruments(syncSourcesActiveMetric).Int64ObservableGauge(
+		syncSourcesActiveMetric,
+		metric.WithDescription("Reports the number of sync sources whose last fetch or connection attempt succeeded."),
+		metric.WithUnit("{source}"),
+	)
+	errs = append(errs, err)
+	// the callbacks are registered per gauge, as the gauges of disabled metrics belong to the noop meter
+	_, err = instruments(syncSourcesTotalMetric).RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		if state := syncSources.Load(); state != nil {
+			o.ObserveInt64(syncSourcesTotal, state.configured)
+		}
+		return nil
+	}, syncSourcesTotal)
+	errs = append(errs, err)
+	_, err = instruments(syncSourcesActiveMetric).RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		if state := syncSources.Load(); state != nil {
+			o.ObserveInt64(syncSourcesActive, state.active())
+		}
+		return nil
+	}, syncSourcesActive)
+	errs = append(errs, err)
+	changeSubscribers := &atomic.Pointer[func() int64]{}
+	changeSubscribersGauge, err := instruments(changeSubscribersMetric).Int64ObservableGauge(
+		changeSubscribersMetric,
+		metric.WithDescription("Reports the number of active flag change event subscriptions."),
+		metric.WithUnit("{subscription}"),
+	)
+	errs = append(errs, err)
+	_, err = instruments(changeSubscribersMetric).RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		subscribers := changeSubscribers.Load()
+		if subscribers == nil {
+			return nil
+		}
+		o.ObserveInt64(changeSubscribersGauge, (*subscribers)())
+		return nil
+	}, changeSubscribersGauge)
+	errs = append(errs, err)
+	openConnections := &atomic.Pointer[func() int64]{}
+	openConnectionsGauge, err := instruments(openConnectionsMetric).Int64ObservableGauge(
+		openConnectionsMetric,
+		metric.WithDescription("Reports the number of open connections accepted by the flag evaluation server."),
+		metric.WithUnit("{connection}"),
+	)
+	errs = append(errs, err)
+	_, err = instruments(openConnectionsMetric).RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		connections := openConnections.Load()
+		if connections == nil {
+			return nil
+		}
+		o.ObserveInt64(openConnectionsGauge, (*connections)())
+		return nil
+	}, openConnectionsGauge)
+	errs = append(errs, err)
+	syncSourcesUsage := &atomic.Pointer[func() []SyncSourceUsage]{}
+	syncGoroutines, err := instruments(syncGoroutinesMetric).Int64ObservableGauge(
+		syncGoroutinesMetric,
+		metric.WithDescription("Reports the number of goroutines watching a sync source."),
+		metric.WithUnit("{goroutine}"),
+	)
+	errs = append(errs, err)
+	syncRetainedBytes, err := instruments(syncRetainedBytesMetric).Int64ObservableGauge(
+		syncRetainedBytesMetric,
+		metric.WithDescription("Reports the size of the flag configuration last received from a sync source."),
+		metric.WithUnit("By"),
+	)
+	errs = append(errs, err)
+	_, err = instruments(syncGoroutinesMetric).RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		if usage := syncSourcesUsage.Load(); usage != nil {
+			for _, source := range (*usage)() {
+				o.ObserveInt64(syncGoroutines, source.Goroutines, metric.WithAttributes(SyncSource(source.Source)))
+			}
+		}
+		return nil
+	}, syncGoroutines)
+	errs = append(errs, err)
+	_, err = instruments(syncRetainedBytesMetric).RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		if usage := syncSourcesUsage.Load(); usage != nil {
+			for _, source := range (*usage)() {
+				o.ObserveInt64(syncRetainedBytes, source.RetainedBytes, metric.WithAttributes(SyncSource(source.Source)))
+			}
+		}
+		return nil
+	}, syncRetainedBytes)
+	errs = append(errs, err)
+	syncStaleness := &atomic.Pointer[func() time.Duration]{}
+	syncStalenessGauge, err := instruments(syncStalenessMetric).Float64ObservableGauge(
+		syncStalenessMetric,
+		metric.WithDescription("Reports the duration since all sync sources were lost, while the last valid flag "+
+			"configuration is served."),
+		metric.WithUnit("s"),
+	)
+	errs = append(errs, err)
+	_, err = instruments(syncStalenessMetric).RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		staleness := syncStaleness.Load()
+		if staleness == nil {
+			return nil
+		}
+		o.ObserveFloat64(syncStalenessGauge, (*staleness)().Seconds())
+		return nil
+	}, syncStalenessGauge)
+	errs = append(errs, err)
 	return &MetricsRecorder{
 		httpRequestDurHistogram:   hduration,
 		httpResponseSizeHistogram: hsize,
 		httpRequestsInflight:      reqCounter,
+		rpcDurHistogram:           rpcDuration,
 		impressions:               impressions,
 		reasons:                   reasons,
-	}
+		reasonMix:                 reasonMix,
+		syncBreakerState:          syncBreakerState,
+		variantsServed:            variantsServed,
+		servedVariants:            newBoundedSet(maxServedVariants),
+		configStaleness:           configStaleness,
+		configWarnings:            configWarnings,
+		configParseDuration:       configParseDuration,
+		evaluationPanics:          evaluationPanics,
+		evaluationTimeouts:        evaluationTimeouts,
+		timedOutFlags:             newBoundedSet(maxTimedOutFlags),
+		operatorsExecuted:         operatorsExecuted,
+		operatorFlags:             newBoundedSet(maxOperatorFlags),
+		contextSizes:              contextSizes,
+		typeMismatches:            typeMismatches,
+		aliasHits:                 aliasHits,
+		missingContextKeys:        missingContextKeys,
+		evaluationCacheLookups:    evaluationCacheLookups,
+		ofrepRequests:             ofrepRequests,
+		syncRetries:               syncRetries,
+		syncFailures:              syncFailures,
+		emptyConfigsApplied:       emptyConfigsApplied,
+		versionRegressions:        versionRegressions,
+		variantRefErrors:          variantRefErrors,
+		syncFlagsFiltered:         syncFlagsFiltered,
+		webhookFailures:           webhookFailures,
+		fractionalBuckets:         fractionalBuckets,
+		openStreams:               openStreams,
+		rejectedStreams:           rejectedStreams,
+		eventOverflows:            eventOverflows,
+		syncSources:               syncSources,
+		changeSubscribers:         changeSubscribers,
+		openConnections:           openConnections,
+		syncSourcesUsage:          syncSourcesUsage,
+		syncStaleness:             syncStaleness,
+	}, errors.Join(errs...)
 }
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.13.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const svcName = "mySvc"
@@ -115,6 +118,16 @@ func TestMetrics(t *testing.T) {
 			},
 			metricsLen: 1,
 		},
+		{
+			name: "RPCDuration",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.RPCDuration(context.TODO(), "flagd.evaluation.v1.Service", "ResolveBoolean", 0, time.Second)
+				rec.RPCDuration(context.TODO(), "flagd.evaluation.v1.Service", "ResolveString", 5, time.Second)
+			},
+			metricsLen: 1,
+		},
 		{
 			name: "HTTPResponseSize",
 			metricFunc: func(exp metric.Reader) {
@@ -145,7 +158,7 @@ func TestMetrics(t *testing.T) {
 				rs := resource.NewWithAttributes("testSchema")
 				rec := NewOTelRecorder(exp, rs, svcName)
 				for i := 0; i < n; i++ {
-					rec.Impressions(context.TODO(), "reason", "variant", "key")
+					rec.Impressions(context.TODO(), "reason", "variant", "key", APISurfaceGRPC)
 				}
 			},
 			metricsLen: 1,
@@ -156,10 +169,10 @@ func TestMetrics(t *testing.T) {
 				rs := resource.NewWithAttributes("testSchema")
 				rec := NewOTelRecorder(exp, rs, svcName)
 				for i := 0; i < n; i++ {
-					rec.Reasons(context.TODO(), "keyA", "reason", nil)
+					rec.Reasons(context.TODO(), "keyA", "reason", nil, APISurfaceGRPC)
 				}
 				for i := 0; i < n; i++ {
-					rec.Reasons(context.TODO(), "keyB", "error", fmt.Errorf("err not found"))
+					rec.Reasons(context.TODO(), "keyB", "error", fmt.Errorf("err not found"), APISurfaceOFREP)
 				}
 			},
 			metricsLen: 1,
@@ -170,17 +183,290 @@ func TestMetrics(t *testing.T) {
 				rs := resource.NewWithAttributes("testSchema")
 				rec := NewOTelRecorder(exp, rs, svcName)
 				for i := 0; i < n; i++ {
-					rec.RecordEvaluation(context.TODO(), nil, "reason", "variant", "key")
+					rec.RecordEvaluation(context.TODO(), nil, "reason", "variant", "key", APISurfaceREST)
+				}
+				for i := 0; i < n; i++ {
+					rec.RecordEvaluation(context.TODO(), fmt.Errorf("general"), "error", "variant", "key", APISurfaceGRPC)
 				}
 				for i := 0; i < n; i++ {
-					rec.RecordEvaluation(context.TODO(), fmt.Errorf("general"), "error", "variant", "key")
+					rec.RecordEvaluation(context.TODO(), fmt.Errorf("not found"), "error", "variant", "key", APISurfaceGRPC)
 				}
+			},
+			metricsLen: 4,
+		},
+		{
+			name: "ConfigStaleness",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.ConfigStaleness(context.TODO(), "sourceA", time.Minute)
+				rec.ConfigStaleness(context.TODO(), "sourceB", time.Hour)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "ConfigParseDuration",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.ConfigParseDuration(context.TODO(), "sourceA", time.Millisecond)
+				rec.ConfigParseDuration(context.TODO(), "sourceB", time.Second)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "EvaluationPanic",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.EvaluationPanic(context.TODO(), "flagA")
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "EvaluationTimeout",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.EvaluationTimeout(context.TODO(), "flagA")
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "OperatorsExecuted",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.OperatorsExecuted(context.TODO(), "flagA", 12)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "EvaluationContextSize",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.EvaluationContextSize(context.TODO(), APISurfaceOFREP, 512)
+				rec.EvaluationContextSize(context.TODO(), APISurfaceGRPC, 2048)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "TypeMismatch",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.TypeMismatch(context.TODO(), TypeString, TypeBoolean)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "MissingContextKey",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.MissingContextKey(context.TODO(), "email")
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "EvaluationCacheLookup",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.EvaluationCacheLookup(context.TODO(), "myFlag", EvaluationCacheHit)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "AliasHit",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.AliasHit(context.TODO(), "old-key", "new-key")
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "OFREPRequest",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.OFREPRequest(context.TODO(), OFREPSingleRequest, OFREPStatusOK)
+				rec.OFREPRequest(context.TODO(), OFREPBulkRequest, OFREPStatusError)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "SyncRetry",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.SyncRetry(context.TODO(), "sourceA")
+				rec.SyncRetry(context.TODO(), "sourceB")
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "ConfigWarning",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.ConfigWarning(context.TODO(), "sourceA", ConfigDeprecatedOperator)
+				rec.ConfigWarning(context.TODO(), "sourceA", ConfigUnreachableVariant)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "SyncFailure",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.SyncFailure(context.TODO(), "sourceA", SyncFetchFailure)
+				rec.SyncFailure(context.TODO(), "sourceA", SyncParseFailure)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "EmptyConfigApplied",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.EmptyConfigApplied(context.TODO(), "sourceA")
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "ConfigVersionRegression",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.ConfigVersionRegression(context.TODO(), "sourceA")
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "VariantReferenceError",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.VariantReferenceError(context.TODO(), "sourceA", VariantRefDefault)
+				rec.VariantReferenceError(context.TODO(), "sourceA", VariantRefTargeting)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "SyncFlagsFiltered",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.SyncFlagsFiltered(context.TODO(), "sourceA", 2)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "WebhookDeliveryFailure",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.WebhookDeliveryFailure(context.TODO())
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "FractionalBucket",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
 				for i := 0; i < n; i++ {
-					rec.RecordEvaluation(context.TODO(), fmt.Errorf("not found"), "error", "variant", "key")
+					rec.FractionalBucket(context.TODO(), "flagA", "red", 25)
+					rec.FractionalBucket(context.TODO(), "flagA", "blue", 75)
 				}
 			},
+			metricsLen: 1,
+		},
+		{
+			name: "Streams",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.StreamOpened(context.TODO(), "sync")
+				rec.StreamOpened(context.TODO(), "event")
+				rec.StreamClosed(context.TODO(), "sync")
+				rec.StreamRejected(context.TODO(), "event")
+			},
+			metricsLen: 2,
+		},
+		{
+			name: "EventOverflow",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.EventOverflow(context.TODO(), "configuration_change", EventCoalesced)
+				rec.EventOverflow(context.TODO(), "provider_shutdown", EventLost)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "SyncCircuitBreakerState",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.SyncCircuitBreakerState(context.TODO(), "sourceA", 1)
+				rec.SyncCircuitBreakerState(context.TODO(), "sourceB", 0)
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "SyncSources",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.SyncSources(3, func() int64 { return 2 })
+			},
 			metricsLen: 2,
 		},
+		{
+			name: "ChangeSubscribers",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.ChangeSubscribers(func() int64 { return 4 })
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "OpenConnections",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.OpenConnections(func() int64 { return 7 })
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "SyncSourcesUsage",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.SyncSourcesUsage(func() []SyncSourceUsage {
+					return []SyncSourceUsage{
+						{Source: "sourceA", Goroutines: 3, RetainedBytes: 1024},
+						{Source: "sourceB", Goroutines: 1, RetainedBytes: 64},
+					}
+				})
+			},
+			metricsLen: 2,
+		},
+		{
+			name: "SyncStaleness",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				rec.SyncStaleness(func() time.Duration { return time.Minute })
+			},
+			metricsLen: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -205,6 +491,259 @@ func TestMetrics(t *testing.T) {
 	}
 }
 
+func TestVariantServed(t *testing.T) {
+	exp := metric.NewManualReader()
+	rs := resource.NewWithAttributes("testSchema")
+	rec := NewOTelRecorder(exp, rs, svcName)
+
+	for i := 0; i < maxServedVariants; i++ {
+		rec.VariantServed(context.TODO(), fmt.Sprintf("variant-%d", i))
+	}
+	// variants beyond the limit end up in the other bucket, while already known variants are still recorded as is
+	rec.VariantServed(context.TODO(), "late-variant")
+	rec.VariantServed(context.TODO(), "another-late-variant")
+	rec.VariantServed(context.TODO(), "variant-0")
+
+	var data metricdata.ResourceMetrics
+	require.Nil(t, exp.Collect(context.TODO(), &data))
+	require.Len(t, data.ScopeMetrics, 1)
+	require.Len(t, data.ScopeMetrics[0].Metrics, 1)
+
+	served := data.ScopeMetrics[0].Metrics[0]
+	require.Equal(t, variantServedMetric, served.Name)
+	sum, ok := served.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+
+	counts := map[string]int64{}
+	for _, dp := range sum.DataPoints {
+		variant, _ := dp.Attributes.Value(attribute.Key("feature_flag.variant"))
+		counts[variant.AsString()] = dp.Value
+	}
+	require.Len(t, counts, maxServedVariants+1)
+	require.Equal(t, int64(2), counts[otherVariant])
+	require.Equal(t, int64(2), counts["variant-0"])
+}
+
+func TestDisabledMetrics(t *testing.T) {
+	exp := metric.NewManualReader()
+	rs := resource.NewWithAttributes("testSchema")
+	rec := NewOTelRecorder(exp, rs, svcName, WithDisabledMetrics(reasonMetric, syncSourcesTotalMetric))
+
+	rec.RecordEvaluation(context.TODO(), nil, "STATIC", "on", "flag", APISurfaceGRPC)
+	rec.SyncSources(3, func() int64 { return 2 })
+
+	var data metricdata.ResourceMetrics
+	require.Nil(t, exp.Collect(context.TODO(), &data))
+	require.Len(t, data.ScopeMetrics, 1)
+	names := map[string]bool{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		names[m.Name] = true
+	}
+	require.Equal(t, map[string]bool{
+		impressionMetric: true, reasonMixMetric: true, variantServedMetric: true, syncSourcesActiveMetric: true,
+	}, names)
+}
+
+func TestReasonMix(t *testing.T) {
+	exp := metric.NewManualReader()
+	rs := resource.NewWithAttributes("testSchema")
+	rec := NewOTelRecorder(exp, rs, svcName)
+
+	rec.RecordEvaluation(context.TODO(), nil, "STATIC", "on", "flagA", APISurfaceGRPC)
+	rec.RecordEvaluation(context.TODO(), nil, "TARGETING_MATCH", "on", "flagB", APISurfaceOFREP)
+	rec.RecordEvaluation(context.TODO(), nil, "TARGETING_MATCH", "off", "flagC", APISurfaceREST)
+	rec.RecordEvaluation(context.TODO(), fmt.Errorf("general"), "ERROR", "", "flagD", APISurfaceGRPC)
+	rec.RecordEvaluation(context.TODO(), nil, "CUSTOM", "on", "flagE", APISurfaceGRPC)
+
+	var data metricdata.ResourceMetrics
+	require.Nil(t, exp.Collect(context.TODO(), &data))
+	require.Len(t, data.ScopeMetrics, 1)
+
+	counts := map[string]int64{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		if m.Name != reasonMixMetric {
+			continue
+		}
+		sum, ok := m.Data.(metricdata.Sum[int64])
+		require.True(t, ok)
+		for _, dp := range sum.DataPoints {
+			require.Equal(t, 1, dp.Attributes.Len(), "expected the reason as the only label")
+			reason, _ := dp.Attributes.Value(FeatureFlagReasonKey)
+			counts[reason.AsString()] = dp.Value
+		}
+	}
+	require.Equal(t, map[string]int64{"STATIC": 1, "TARGETING_MATCH": 2, "ERROR": 1, unknownReason: 1}, counts)
+}
+
+func TestIsMetricName(t *testing.T) {
+	require.True(t, IsMetricName("feature_flag.flagd.evaluation.reason"))
+	require.True(t, IsMetricName(changeSubscribersMetric))
+	require.False(t, IsMetricName("flagd_change_subscribers"))
+}
+
+func TestAPISurface(t *testing.T) {
+	for _, surface := range []string{APISurfaceGRPC, APISurfaceOFREP, APISurfaceREST, APISurfaceInProcess} {
+		require.Equal(t, APISurfaceKey.String(surface), APISurface(surface))
+	}
+	// unknown surfaces don't extend the cardinality of the label
+	require.Equal(t, APISurfaceKey.String(APISurfaceInProcess), APISurface("graphql"))
+}
+
+func TestRecorderScope(t *testing.T) {
+	tests := map[string]struct {
+		opts            []RecorderOption
+		expectedName    string
+		expectedVersion string
+	}{
+		"defaults to service name": {
+			expectedName: svcName,
+		},
+		"custom scope name and version": {
+			opts:            []RecorderOption{WithScopeName("flagd-proxy"), WithScopeVersion("v1.2.3")},
+			expectedName:    "flagd-proxy",
+			expectedVersion: "v1.2.3",
+		},
+		"empty scope name is ignored": {
+			opts:         []RecorderOption{WithScopeName("")},
+			expectedName: svcName,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			exp := metric.NewManualReader()
+			rs := resource.NewWithAttributes("testSchema")
+			rec := NewOTelRecorder(exp, rs, svcName, tt.opts...)
+			rec.HTTPRequestDuration(context.TODO(), time.Second, nil)
+			rec.HTTPResponseSize(context.TODO(), 100, nil)
+
+			var data metricdata.ResourceMetrics
+			require.Nil(t, exp.Collect(context.TODO(), &data))
+			require.Len(t, data.ScopeMetrics, 1)
+			scopeMetrics := data.ScopeMetrics[0]
+			require.Equal(t, tt.expectedName, scopeMetrics.Scope.Name)
+			require.Equal(t, tt.expectedVersion, scopeMetrics.Scope.Version)
+
+			// custom buckets must apply to the instruments of the configured scope
+			bounds := map[string][]float64{}
+			for _, m := range scopeMetrics.Metrics {
+				histogram, ok := m.Data.(metricdata.Histogram[float64])
+				require.True(t, ok)
+				require.Len(t, histogram.DataPoints, 1)
+				bounds[m.Name] = histogram.DataPoints[0].Bounds
+			}
+			require.Equal(t, prometheus.DefBuckets, bounds[httpRequestDurationMetric])
+			require.Equal(t, prometheus.ExponentialBuckets(100, 10, 8), bounds[httpResponseSizeMetric])
+		})
+	}
+}
+
+func TestNativeHistograms(t *testing.T) {
+	exp := metric.NewManualReader()
+	rs := resource.NewWithAttributes("testSchema")
+	rec := NewOTelRecorder(exp, rs, svcName, WithNativeHistograms(true))
+	rec.HTTPRequestDuration(context.TODO(), time.Second, nil)
+	rec.HTTPResponseSize(context.TODO(), 100, nil)
+
+	var data metricdata.ResourceMetrics
+	require.Nil(t, exp.Collect(context.TODO(), &data))
+	require.Len(t, data.ScopeMetrics, 1)
+	require.Len(t, data.ScopeMetrics[0].Metrics, 2)
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		histogram, ok := m.Data.(metricdata.ExponentialHistogram[float64])
+		require.True(t, ok, "%s must be a native histogram", m.Name)
+		require.Len(t, histogram.DataPoints, 1)
+		require.Equal(t, uint64(1), histogram.DataPoints[0].Count)
+	}
+}
+
+func TestResponseSizeBuckets(t *testing.T) {
+	tests := map[string]struct {
+		opts     []RecorderOption
+		expected []float64
+	}{
+		"default": {
+			expected: prometheus.ExponentialBuckets(100, 10, 8),
+		},
+		"extended top boundary": {
+			opts:     []RecorderOption{WithResponseSizeMaxBucket(5e10)},
+			expected: []float64{100, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 5e10},
+		},
+		"lowered top boundary": {
+			opts:     []RecorderOption{WithResponseSizeMaxBucket(1 << 20)},
+			expected: []float64{100, 1e3, 1e4, 1e5, 1e6, 1 << 20},
+		},
+		"boundary must be positive": {
+			opts:     []RecorderOption{WithResponseSizeMaxBucket(0)},
+			expected: prometheus.ExponentialBuckets(100, 10, 8),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			exp := metric.NewManualReader()
+			rs := resource.NewWithAttributes("testSchema")
+			rec := NewOTelRecorder(exp, rs, svcName, tt.opts...)
+			rec.HTTPResponseSize(context.TODO(), 2e9, nil)
+
+			var data metricdata.ResourceMetrics
+			require.Nil(t, exp.Collect(context.TODO(), &data))
+			require.Len(t, data.ScopeMetrics, 1)
+			histogram, ok := data.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+			require.True(t, ok)
+			require.Len(t, histogram.DataPoints, 1)
+			require.Equal(t, tt.expected, histogram.DataPoints[0].Bounds)
+		})
+	}
+}
+
+func TestSlowestExemplars(t *testing.T) {
+	exp := metric.NewManualReader()
+	rs := resource.NewWithAttributes("testSchema")
+	rec := NewOTelRecorder(exp, rs, svcName, WithSlowestExemplars(time.Minute))
+	ctx := trace.ContextWithSpanContext(context.TODO(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	// both requests fall into the same bucket, the slower one is kept although it was recorded first
+	rec.HTTPRequestDuration(ctx, 8*time.Second, nil)
+	rec.HTTPRequestDuration(ctx, 6*time.Second, nil)
+
+	var data metricdata.ResourceMetrics
+	require.Nil(t, exp.Collect(context.TODO(), &data))
+	require.Len(t, data.ScopeMetrics, 1)
+	histogram, ok := data.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	require.Len(t, histogram.DataPoints, 1)
+	require.Len(t, histogram.DataPoints[0].Exemplars, 1)
+	require.Equal(t, float64(8), histogram.DataPoints[0].Exemplars[0].Value)
+}
+
+func TestOptInMetrics(t *testing.T) {
+	exp := metric.NewManualReader()
+	rec := NewOTelRecorder(exp, resource.NewWithAttributes("testSchema"), svcName)
+	require.Same(t, rec, SyncMetrics(rec))
+	require.Same(t, rec, ConfigMetrics(rec))
+	require.Same(t, rec, EvaluationMetrics(rec))
+	require.Same(t, rec, CacheMetrics(rec))
+	require.Same(t, rec, ServiceMetrics(rec))
+
+	// recorders of the core metrics only, as well as nil recorders, record none of the opt-in metrics
+	for _, recorder := range []IMetricsRecorder{coreRecorder{rec}, nil} {
+		require.Equal(t, NoopMetricsRecorder{}, SyncMetrics(recorder))
+		require.Equal(t, NoopMetricsRecorder{}, ConfigMetrics(recorder))
+		require.Equal(t, NoopMetricsRecorder{}, EvaluationMetrics(recorder))
+		require.Equal(t, NoopMetricsRecorder{}, CacheMetrics(recorder))
+		require.Equal(t, NoopMetricsRecorder{}, ServiceMetrics(recorder))
+	}
+}
+
+// coreRecorder hides the opt-in metrics of the embedded recorder
+type coreRecorder struct {
+	IMetricsRecorder
+}
+
 // some really simple tests just to make sure all methods are actually implemented and nothing panics
 func TestNoopMetricsRecorder_HTTPAttributes(t *testing.T) {
 	no := NoopMetricsRecorder{}
@@ -217,6 +756,11 @@ func TestNoopMetricsRecorder_HTTPRequestDuration(_ *testing.T) {
 	no.HTTPRequestDuration(context.TODO(), 0, nil)
 }
 
+func TestNoopMetricsRecorder_RPCDuration(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.RPCDuration(context.TODO(), "", "", 0, 0)
+}
+
 func TestNoopMetricsRecorder_InFlightRequestStart(_ *testing.T) {
 	no := NoopMetricsRecorder{}
 	no.InFlightRequestStart(context.TODO(), nil)
@@ -229,10 +773,152 @@ func TestNoopMetricsRecorder_InFlightRequestEnd(_ *testing.T) {
 
 func TestNoopMetricsRecorder_RecordEvaluation(_ *testing.T) {
 	no := NoopMetricsRecorder{}
-	no.RecordEvaluation(context.TODO(), nil, "", "", "")
+	no.RecordEvaluation(context.TODO(), nil, "", "", "", "")
 }
 
 func TestNoopMetricsRecorder_Impressions(_ *testing.T) {
 	no := NoopMetricsRecorder{}
-	no.Impressions(context.TODO(), "", "", "")
+	no.Impressions(context.TODO(), "", "", "", "")
+}
+
+func TestNoopMetricsRecorder_SyncCircuitBreakerState(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.SyncCircuitBreakerState(context.TODO(), "", 0)
+}
+
+func TestNoopMetricsRecorder_ConfigStaleness(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.ConfigStaleness(context.TODO(), "", 0)
+}
+
+func TestNoopMetricsRecorder_ConfigParseDuration(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.ConfigParseDuration(context.TODO(), "", 0)
+}
+
+func TestNoopMetricsRecorder_EvaluationPanic(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.EvaluationPanic(context.TODO(), "")
+}
+
+func TestNoopMetricsRecorder_EvaluationTimeout(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.EvaluationTimeout(context.TODO(), "")
+}
+
+func TestNoopMetricsRecorder_OperatorsExecuted(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.OperatorsExecuted(context.TODO(), "", 0)
+}
+
+func TestNoopMetricsRecorder_EvaluationContextSize(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.EvaluationContextSize(context.TODO(), "", 0)
+}
+
+func TestNoopMetricsRecorder_TypeMismatch(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.TypeMismatch(context.TODO(), "", "")
+}
+
+func TestNoopMetricsRecorder_MissingContextKey(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.MissingContextKey(context.TODO(), "")
+}
+
+func TestNoopMetricsRecorder_EvaluationCacheLookup(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.EvaluationCacheLookup(context.TODO(), "", "")
+}
+
+func TestNoopMetricsRecorder_AliasHit(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.AliasHit(context.TODO(), "", "")
+}
+
+func TestNoopMetricsRecorder_OFREPRequest(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.OFREPRequest(context.TODO(), "", "")
+}
+
+func TestNoopMetricsRecorder_SyncSources(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.SyncSources(0, func() int64 { return 0 })
+}
+
+func TestNoopMetricsRecorder_ChangeSubscribers(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.ChangeSubscribers(func() int64 { return 0 })
+}
+
+func TestNoopMetricsRecorder_OpenConnections(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.OpenConnections(func() int64 { return 0 })
+}
+
+func TestNoopMetricsRecorder_SyncSourcesUsage(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.SyncSourcesUsage(func() []SyncSourceUsage { return nil })
+}
+
+func TestNoopMetricsRecorder_SyncStaleness(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.SyncStaleness(func() time.Duration { return 0 })
+}
+
+func TestNoopMetricsRecorder_SyncRetry(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.SyncRetry(context.TODO(), "")
+}
+
+func TestNoopMetricsRecorder_ConfigWarning(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.ConfigWarning(context.TODO(), "", "")
+}
+
+func TestNoopMetricsRecorder_SyncFailure(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.SyncFailure(context.TODO(), "", "")
+}
+
+func TestNoopMetricsRecorder_EmptyConfigApplied(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.EmptyConfigApplied(context.TODO(), "")
+}
+
+func TestNoopMetricsRecorder_ConfigVersionRegression(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.ConfigVersionRegression(context.TODO(), "")
+}
+
+func TestNoopMetricsRecorder_VariantReferenceError(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.VariantReferenceError(context.TODO(), "", "")
+}
+
+func TestNoopMetricsRecorder_SyncFlagsFiltered(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.SyncFlagsFiltered(context.TODO(), "", 0)
+}
+
+func TestNoopMetricsRecorder_WebhookDeliveryFailure(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.WebhookDeliveryFailure(context.TODO())
+}
+
+func TestNoopMetricsRecorder_FractionalBucket(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.FractionalBucket(context.TODO(), "", "", 0)
+}
+
+func TestNoopMetricsRecorder_Streams(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.StreamOpened(context.TODO(), "")
+	no.StreamClosed(context.TODO(), "")
+	no.StreamRejected(context.TODO(), "")
+}
+
+func TestNoopMetricsRecorder_EventOverflow(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.EventOverflow(context.TODO(), "", "")
 }
@@ -6,10 +6,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/sync"
 	syncbuilder "github.com/open-feature/flagd/core/pkg/sync/builder"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/open-feature/flagd/flagd/pkg/runtime"
+	"github.com/open-feature/flagd/flagd/pkg/service/flag-evaluation/ofrep"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/auth"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/configversion"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -17,24 +23,88 @@ import (
 )
 
 const (
-	corsFlagName               = "cors-origin"
-	logFormatFlagName          = "log-format"
-	managementPortFlagName     = "management-port"
-	metricsExporter            = "metrics-exporter"
-	ofrepPortFlagName          = "ofrep-port"
-	otelCollectorURI           = "otel-collector-uri"
-	otelCertPathFlagName       = "otel-cert-path"
-	otelKeyPathFlagName        = "otel-key-path"
-	otelCAPathFlagName         = "otel-ca-path"
-	otelReloadIntervalFlagName = "otel-reload-interval"
-	portFlagName               = "port"
-	serverCertPathFlagName     = "server-cert-path"
-	serverKeyPathFlagName      = "server-key-path"
-	socketPathFlagName         = "socket-path"
-	sourcesFlagName            = "sources"
-	syncPortFlagName           = "sync-port"
-	uriFlagName                = "uri"
-	contextValueFlagName       = "context-value"
+	adminAuditLogFlagName       = "admin-audit-log"
+	adminTokenFlagName          = "admin-token"
+	allowEmptyConfigFlagName    = "allow-empty-config"
+	unknownVariantsFlagName     = "reject-unknown-variants"
+	captureRedactKeysFlagName   = "capture-redact-keys"
+	captureSamplesFlagName      = "capture-samples"
+	configHistoryFlagName       = "config-history"
+	configVersionHeaderFlagName = "config-version-header"
+	contextAllowKeysFlagName    = "context-allow-keys"
+	contextRedactAllFlagName    = "context-redact-all"
+	contextRedactKeysFlagName   = "context-redact-keys"
+	corsFlagName                = "cors-origin"
+	defaultOnErrorFlagName      = "default-on-targeting-error"
+	defaultTargetingKeyFlagName = "default-targeting-key"
+	evaluationCacheSizeFlagName = "evaluation-cache-size"
+	evaluationTimeoutFlagName   = "evaluation-timeout"
+	fallbackConfigFlagName      = "fallback-config"
+	flagDebugDurationFlagName   = "flag-debug-duration"
+	lastEvaluatedFlagName       = "last-evaluated-flags"
+	flagSetFallbackFlagName     = "flag-set-fallback"
+	geoIPDatabaseFlagName       = "geoip-database"
+	grpcCompressionFlagName     = "grpc-compression"
+	jsonNumbersFlagName         = "json-numbers"
+	jwtAudienceFlagName         = "jwt-audience"
+	jwtIssuerFlagName           = "jwt-issuer"
+	jwtJWKSURLFlagName          = "jwt-jwks-url"
+	jwtPublicKeyPathFlagName    = "jwt-public-key-path"
+	logFormatFlagName           = "log-format"
+	managementAddressFlagName   = "management-address"
+	managementCertPathFlagName  = "management-cert-path"
+	managementKeyPathFlagName   = "management-key-path"
+	managementPortFlagName      = "management-port"
+	maxAdminBodyFlagName        = "max-admin-body-bytes"
+	maxBulkBodyFlagName         = "max-bulk-body-bytes"
+	maxConnectionsFlagName      = "max-connections"
+	maxContextBytesFlagName     = "max-context-bytes"
+	maxEvalBodyFlagName         = "max-evaluation-body-bytes"
+	maxEventStreamsFlagName     = "max-event-streams"
+	maxFlagsFlagName            = "max-flags"
+	maxSyncStreamsFlagName      = "max-sync-streams"
+	maxTargetingDepthFlagName   = "max-targeting-depth"
+	metricsExporter             = "metrics-exporter"
+	metricsDisabledFlagName     = "metrics-disabled"
+	metricsExportIntervalName   = "metrics-export-interval"
+	metricsFormatFlagName       = "metrics-format"
+	metricsTemporalityName      = "metrics-temporality"
+	metricsNativeHistograms     = "metrics-native-histograms"
+	metricsResponseSizeBucket   = "metrics-response-size-max-bucket"
+	metricsSlowestExemplars     = "metrics-slowest-exemplars"
+	metricsMissingContextKeys   = "metrics-missing-context-keys"
+	metricsOperatorsExecuted    = "metrics-operators-executed"
+	ofrepBatchSizeFlagName      = "ofrep-max-batch-size"
+	ofrepPollingFlagName        = "ofrep-min-polling-interval"
+	ofrepPortFlagName           = "ofrep-port"
+	otelCollectorURI            = "otel-collector-uri"
+	otelCertPathFlagName        = "otel-cert-path"
+	otelKeyPathFlagName         = "otel-key-path"
+	otelCAPathFlagName          = "otel-ca-path"
+	otelReloadIntervalFlagName  = "otel-reload-interval"
+	peerContextFlagName         = "peer-context"
+	portFlagName                = "port"
+	readHeaderTimeoutFlagName   = "server-read-header-timeout"
+	readTimeoutFlagName         = "server-read-timeout"
+	regionContextKeyFlagName    = "region-context-key"
+	rejectDuplicatesFlagName    = "reject-duplicate-flag-keys"
+	rejectRegressionsFlagName   = "reject-version-regressions"
+	writeTimeoutFlagName        = "server-write-timeout"
+	idleTimeoutFlagName         = "server-idle-timeout"
+	serverCertPathFlagName      = "server-cert-path"
+	serverKeyPathFlagName       = "server-key-path"
+	socketPathFlagName          = "socket-path"
+	startupSelfTestFlagName     = "startup-self-test"
+	sourcesFlagName             = "sources"
+	strictTargetingFlagName     = "strict-targeting"
+	subsystemLogLevelFlagName   = "subsystem-log-level"
+	syncMaxStalenessFlagName    = "sync-max-staleness"
+	syncPortFlagName            = "sync-port"
+	syncStaleErrorsFlagName     = "sync-stale-errors"
+	webhookURLFlagName          = "webhook-url"
+	webhookSecretFlagName       = "webhook-secret"
+	uriFlagName                 = "uri"
+	contextValueFlagName        = "context-value"
 )
 
 func init() {
@@ -44,9 +114,19 @@ func init() {
 	viper.SetEnvPrefix("FLAGD")                            // port becomes FLAGD_PORT
 
 	flags.Int32P(managementPortFlagName, "m", 8014, "Port for management operations")
+	flags.String(managementAddressFlagName, "", "Host the management server, serving the metrics and probes, binds "+
+		"to. Empty binds to all interfaces")
+	flags.String(managementCertPathFlagName, "", "TLS certificate path of the management server, independent of the "+
+		"TLS of the evaluation server")
+	flags.String(managementKeyPathFlagName, "", "TLS key path of the management server, independent of the TLS of "+
+		"the evaluation server")
 	flags.Int32P(portFlagName, "p", 8013, "Port to listen on")
 	flags.Int32P(syncPortFlagName, "g", 8015, "gRPC Sync port")
 	flags.Int32P(ofrepPortFlagName, "r", 8016, "ofrep service port")
+	flags.Duration(ofrepPollingFlagName, 0, "Minimum interval between polls of the OFREP bulk evaluation, "+
+		"advertised to OFREP clients by the /ofrep/v1/configuration endpoint. Zero doesn't limit the polling")
+	flags.Int(ofrepBatchSizeFlagName, ofrep.DefaultMaxBatchSize, "Maximum number of contexts of an OFREP batch "+
+		"evaluation, larger batches are rejected. Zero doesn't limit the contexts")
 
 	flags.StringP(socketPathFlagName, "d", "", "Flagd socket path. "+
 		"With grpc the service will become available on this address. "+
@@ -62,15 +142,48 @@ func init() {
 			"Please note that if you are using filepath, flagd only supports files with `.yaml/.yml/.json` extension.",
 	)
 	flags.StringSliceP(corsFlagName, "C", []string{}, "CORS allowed origins, * will allow all origins")
+	flags.String(configVersionHeaderFlagName, configversion.DefaultHeaderName, "Response header returning the "+
+		"version of the applied flag configuration with each evaluation response, an empty value disables the header")
 	flags.StringP(
 		sourcesFlagName, "s", "", "JSON representation of an array of SourceConfig objects. This object contains "+
 			"2 required fields, uri (string) and provider (string). Documentation for this object: "+
 			"https://flagd.dev/reference/sync-configuration/#source-configuration",
 	)
+	flags.Duration(syncMaxStalenessFlagName, 24*time.Hour, "Duration the last valid flag configuration is served "+
+		"after all sync sources were lost, flagd reports not ready once it is exceeded so that load balancers route "+
+		"away. Zero serves the last valid configuration indefinitely")
+	flags.Bool(syncStaleErrorsFlagName, false, "Fail evaluations with an error while the flag configuration is "+
+		"stale, as all sync sources were lost for longer than --sync-max-staleness")
 	flags.StringP(logFormatFlagName, "z", "console", "Set the logging format, e.g. console or json")
+	flags.StringToString(subsystemLogLevelFlagName, map[string]string{}, "Override the log level of a subsystem, "+
+		"one of sync, evaluation or service, e.g. sync=warn,evaluation=debug")
 	flags.StringP(metricsExporter, "t", "", "Set the metrics exporter. Default(if unset) is Prometheus."+
 		" Can be override to otel - OpenTelemetry metric exporter. Overriding to otel require otelCollectorURI to"+
-		" be present")
+		" be present. Set to stdout to print the metrics as JSON on each export, e.g. for local debugging")
+	flags.Duration(metricsExportIntervalName, telemetry.DefaultExportInterval, "Interval of pushing metrics to the OpenTelemetry "+
+		"collector or printing them, if the otel or stdout metrics exporter is used")
+	flags.String(metricsTemporalityName, telemetry.TemporalityCumulative, "Aggregation temporality of the "+
+		"metrics pushed to the OpenTelemetry collector, cumulative or delta. Delta requires the otel or stdout "+
+		"metrics exporter and applies to counters and histograms")
+	flags.StringSlice(metricsDisabledFlagName, []string{}, "Names of the metrics which are neither recorded nor "+
+		"exported, e.g. feature_flag.flagd.evaluation.reason")
+	flags.String(metricsFormatFlagName, telemetry.MetricsFormatText, "Exposition format of the Prometheus metrics, "+
+		"either 'text' or 'openmetrics'. The text format drops exemplars, 'openmetrics' exposes them to scrapers "+
+		"accepting the OpenMetrics format")
+	flags.Bool(metricsNativeHistograms, false, "Record the request duration and response size histograms as "+
+		"native (exponential) histograms instead of explicit buckets. Requires the otel metrics exporter, flagd "+
+		"refuses to start with the Prometheus exporter, which only exposes explicit buckets")
+	flags.Float64(metricsResponseSizeBucket, telemetry.DefaultResponseSizeBucket, "Top boundary in bytes of the "+
+		"explicit buckets of the response size histogram, which grow by a factor of ten from 100 bytes. Raise it "+
+		"to distinguish large responses, e.g. of object flags, which are otherwise counted in the +Inf bucket")
+	flags.Duration(metricsSlowestExemplars, 0, "Keep the slowest request of each bucket of the request duration "+
+		"histogram as exemplar for the given interval, instead of the most recent request. Zero keeps the default "+
+		"exemplars, and the option has no effect if exemplars are disabled")
+	flags.StringSlice(metricsMissingContextKeys, []string{}, "Evaluation context keys counted by the missing "+
+		"context key metric whenever they are referenced by a targeting rule but absent from the evaluation "+
+		"context, nested keys are addressed by their dot separated path, e.g. user.email. Nothing is counted if unset")
+	flags.Bool(metricsOperatorsExecuted, false, "Record the number of operators executed per evaluation of "+
+		"targeting rules with the operators executed metric. Counting adds a small overhead to each executed operator")
 	flags.StringP(otelCollectorURI, "o", "", "Set the grpc URI of the OpenTelemetry collector "+
 		"for flagd runtime. If unset, the collector setup will be ignored and traces will not be exported.")
 	flags.StringP(otelCertPathFlagName, "D", "", "tls certificate path to use with OpenTelemetry collector")
@@ -78,25 +191,199 @@ func init() {
 	flags.StringP(otelCAPathFlagName, "A", "", "tls certificate authority path to use with OpenTelemetry collector")
 	flags.DurationP(otelReloadIntervalFlagName, "I", time.Hour, "how long between reloading the otel tls certificate "+
 		"from disk")
+	flags.String(adminTokenFlagName, "", "Bearer token required to access the admin endpoints of the "+
+		"management port, e.g. the dump of the current flag state. Admin endpoints are disabled if unset")
+	flags.String(adminAuditLogFlagName, "stderr", "Sink of the audit log recording every call of the admin "+
+		"endpoints as JSON entry, either stderr, stdout or the path of a file the entries are appended to")
+	flags.Int(captureSamplesFlagName, 0, "Number of recent evaluations captured for debugging, exposed on the "+
+		"admin endpoints. The samples contain the evaluation context of requests. Zero disables capturing")
+	flags.StringSlice(captureRedactKeysFlagName, []string{}, "Evaluation context keys redacted in captured "+
+		"evaluations, nested keys are addressed by their dot separated path, e.g. peer.ip")
+	flags.Duration(flagDebugDurationFlagName, 10*time.Minute, "Maximum duration the detailed logging of the "+
+		"evaluations of a flag, enabled on the admin endpoints, lasts before it expires. Zero disables flag debugging")
+	flags.Int(lastEvaluatedFlagName, 0, "Number of flags whose last evaluation time is tracked in memory, exposed "+
+		"on the admin endpoints to identify flags which aren't evaluated anymore. Zero disables tracking")
+	flags.Int(configHistoryFlagName, 0, "Number of recently applied flag configurations retained in memory, so "+
+		"that flags can be evaluated against prior configurations on the admin endpoints. Zero disables retention")
+	flags.StringSlice(contextRedactKeysFlagName, []string{}, "Evaluation context keys whose values are redacted "+
+		"wherever the evaluation context is logged or captured, nested keys are addressed by their dot separated "+
+		"path, e.g. peer.ip")
+	flags.Bool(contextRedactAllFlagName, false, "Redact all values of the evaluation context wherever it is logged "+
+		"or captured, except the values of the --context-allow-keys")
+	flags.StringSlice(contextAllowKeysFlagName, []string{}, "Evaluation context keys whose values are not "+
+		"redacted with --context-redact-all, nested keys are addressed by their dot separated path")
+	flags.Bool(jsonNumbersFlagName, false, "Decode numbers of flag configurations as JSON numbers instead of "+
+		"floating point numbers. This preserves integer values during evaluation, including integers that exceed "+
+		"the precision of a float64")
+	flags.String(jwtPublicKeyPathFlagName, "", "Path of a PEM encoded public key verifying the JWT bearer token "+
+		"of evaluation requests. If set, requests without a valid token are rejected and the token claims are merged "+
+		"into the evaluation context")
+	flags.String(jwtJWKSURLFlagName, "", "URL of a JSON Web Key Set verifying the JWT bearer token of evaluation "+
+		"requests, as an alternative to a public key")
+	flags.String(jwtIssuerFlagName, "", "Issuer required in the iss claim of JWT bearer tokens")
+	flags.String(jwtAudienceFlagName, "", "Audience required in the aud claim of JWT bearer tokens")
+	flags.Bool(peerContextFlagName, false, "Add the attributes of the peer of evaluation requests, e.g. its IP "+
+		"address, to the evaluation context under the peer key. Values sent by clients take precedence")
+	flags.String(geoIPDatabaseFlagName, "", "Path of a CSV file mapping networks to country codes, used to add "+
+		"the country of the peer to the evaluation context. Requires --peer-context")
+	flags.String(fallbackConfigFlagName, "", "File name of a fallback flag configuration compiled into flagd, "+
+		"e.g. example.flagd.json, which is served until a sync source delivers a configuration. The flags of the "+
+		"fallback configuration are marked with the fallbackConfig metadata")
+	flags.StringSlice(flagSetFallbackFlagName, []string{}, "Ordered chain of flag set IDs flags are looked up in, "+
+		"the first flag set defining a flag answers, e.g. tenant-a,base. Flags of flag sets outside the chain are "+
+		"not served. If unset, flags are served from the merged configuration of all sources")
+	flags.Int(evaluationCacheSizeFlagName, evaluator.DefaultEvaluationCacheSize, "Maximum number of evaluation "+
+		"results cached for flags opting into the evaluation cache with the evaluationCacheTTL metadata. Zero "+
+		"disables the cache")
+	flags.Duration(evaluationTimeoutFlagName, 0, "Maximum duration of a single flag evaluation, evaluations "+
+		"exceeding it result in an error and are counted by the flagd.evaluation.timeout metric. Zero doesn't limit "+
+		"evaluations")
+	flags.Int(maxTargetingDepthFlagName, evaluator.DefaultMaxTargetingDepth, "Maximum nesting depth of targeting "+
+		"rules, each object and array of a rule adds a level. Configurations with deeper rules are rejected. Zero "+
+		"doesn't limit the depth")
+	flags.Int(maxFlagsFlagName, 0, "Maximum number of flags of a flag configuration, configurations defining more "+
+		"flags are rejected and the last valid configuration of the source is kept. Zero doesn't limit the flags")
+	flags.Bool(strictTargetingFlagName, false, "Evaluate the comparisons of targeting rules without type coercion, "+
+		"so that operands of mismatching types are neither equal nor ordered. Flags may override this default with "+
+		"the strictTargeting metadata")
+	flags.String(startupSelfTestFlagName, "", "Check the default variant of each flag of a source once its first "+
+		"configuration is applied, the default variant must exist and match the type of the other variants. Failed "+
+		"checks are logged with 'warn' and stop flagd with 'fail'. Unset disables the self-test")
+	flags.Bool(defaultOnErrorFlagName, false, "Resolve flags whose targeting fails to their default variant, with an "+
+		"ERROR reason and the error code in the errorCode metadata. Flags may override this default with the "+
+		"defaultOnTargetingError metadata")
+	flags.String(defaultTargetingKeyFlagName, "", "JsonLogic expression synthesizing the targeting key of "+
+		"evaluation contexts without one, so that fractional assignments of such clients are stable, e.g. "+
+		`{"cat": [{"var": "peer.ip"}, "/", {"var": "sessionId"}]}`)
+	flags.String(grpcCompressionFlagName, "", "Compression of evaluation responses for clients advertising it, "+
+		"either 'gzip' or empty to send responses uncompressed. Compression reduces the size of large responses, "+
+		"e.g. of ResolveAll, at the cost of CPU on flagd and the clients")
+	flags.Bool(allowEmptyConfigFlagName, false, "Apply flag configurations defining no flags, which remove all flags "+
+		"of their source and are counted by the flagd.empty_config.applied metric. Otherwise, such configurations are "+
+		"rejected and the last valid configuration of the source is kept")
+	flags.String(unknownVariantsFlagName, "", "Reject flags referencing a default variant or a variant resolved by "+
+		"their targeting which they don't define when the configuration is loaded, either 'flag' to skip such flags "+
+		"or 'config' to reject the whole configuration. Unset rejects configurations with unknown default variants "+
+		"only. References are counted by the flagd.variant_reference.errors metric")
+	flags.String(regionContextKeyFlagName, evaluator.DefaultRegionContextKey, "Evaluation context key holding the "+
+		"region of a client, which selects the variant served from the regionDefaults metadata of flags instead of "+
+		"their default variant")
+	flags.Bool(rejectDuplicatesFlagName, false, "Reject flag configurations defining a flag key more than once, "+
+		"keeping the last valid configuration of the source. Otherwise, the last definition of the flag is used and "+
+		"a warning is logged and counted by the flagd.config.warnings metric")
+	flags.Bool(rejectRegressionsFlagName, false, "Reject flag configurations whose monotonicVersion metadata is "+
+		"older than the version of the applied configuration of the source, keeping the applied configuration. "+
+		"Otherwise, a warning is logged. Regressions are counted by the flagd.config.version.regression metric")
+	flags.String(webhookURLFlagName, "", "URL of a webhook receiving a POST request with the changed flag keys "+
+		"and the new flag state version on each applied flag configuration change")
+	flags.String(webhookSecretFlagName, "", "Secret used to sign the webhook requests with HMAC-SHA256, the "+
+		"signature is sent in the X-Flagd-Signature header")
+	flags.Duration(readHeaderTimeoutFlagName, service.DefaultReadHeaderTimeout, "Maximum duration for reading "+
+		"the request headers of the HTTP servers. A negative value disables the timeout")
+	flags.Duration(readTimeoutFlagName, service.DefaultReadTimeout, "Maximum duration for reading an entire "+
+		"request of the HTTP servers. Event streams are exempt. A negative value disables the timeout")
+	flags.Duration(writeTimeoutFlagName, service.DefaultWriteTimeout, "Maximum duration for writing a response "+
+		"of the HTTP servers. Event streams are exempt. A negative value disables the timeout")
+	flags.Duration(idleTimeoutFlagName, service.DefaultIdleTimeout, "Maximum duration to keep idle connections of "+
+		"the HTTP servers open. A negative value disables the timeout")
+	flags.Int(maxConnectionsFlagName, 0, "Maximum number of concurrent connections of the flag evaluation "+
+		"service, further connections wait until an accepted connection is closed. Zero doesn't limit the connections")
+	flags.Int(maxContextBytesFlagName, 0, "Maximum size in bytes of the evaluation context of a request, larger "+
+		"contexts are rejected before the evaluation. Zero doesn't limit the size")
+	flags.Int64(maxEvalBodyFlagName, 0, "Maximum size in bytes of the request body of single flag evaluations, "+
+		"larger requests are rejected with 413. Zero doesn't limit the size")
+	flags.Int64(maxBulkBodyFlagName, 0, "Maximum size in bytes of the request body of evaluations of all flags, "+
+		"i.e. ResolveAll and the OFREP bulk and batch evaluations, larger requests are rejected with 413. Zero "+
+		"doesn't limit the size")
+	flags.Int64(maxAdminBodyFlagName, 0, "Maximum size in bytes of the request body of the admin endpoints, "+
+		"larger requests are rejected with 413. Zero doesn't limit the size")
+	flags.Int(maxEventStreamsFlagName, 0, "Maximum number of concurrent event streams of the flag evaluation "+
+		"service, further streams are rejected with RESOURCE_EXHAUSTED. Zero doesn't limit the streams")
+	flags.Int(maxSyncStreamsFlagName, 0, "Maximum number of concurrent streams of the gRPC sync service, "+
+		"further streams are rejected with RESOURCE_EXHAUSTED. Zero doesn't limit the streams")
 	flags.StringToStringP(contextValueFlagName, "X", map[string]string{}, "add arbitrary key value pairs "+
 		"to the flag evaluation context")
 
+	_ = viper.BindPFlag(adminAuditLogFlagName, flags.Lookup(adminAuditLogFlagName))
+	_ = viper.BindPFlag(adminTokenFlagName, flags.Lookup(adminTokenFlagName))
+	_ = viper.BindPFlag(captureRedactKeysFlagName, flags.Lookup(captureRedactKeysFlagName))
+	_ = viper.BindPFlag(captureSamplesFlagName, flags.Lookup(captureSamplesFlagName))
+	_ = viper.BindPFlag(configHistoryFlagName, flags.Lookup(configHistoryFlagName))
+	_ = viper.BindPFlag(configVersionHeaderFlagName, flags.Lookup(configVersionHeaderFlagName))
+	_ = viper.BindPFlag(contextAllowKeysFlagName, flags.Lookup(contextAllowKeysFlagName))
+	_ = viper.BindPFlag(contextRedactAllFlagName, flags.Lookup(contextRedactAllFlagName))
+	_ = viper.BindPFlag(contextRedactKeysFlagName, flags.Lookup(contextRedactKeysFlagName))
 	_ = viper.BindPFlag(corsFlagName, flags.Lookup(corsFlagName))
+	_ = viper.BindPFlag(evaluationCacheSizeFlagName, flags.Lookup(evaluationCacheSizeFlagName))
+	_ = viper.BindPFlag(evaluationTimeoutFlagName, flags.Lookup(evaluationTimeoutFlagName))
+	_ = viper.BindPFlag(flagDebugDurationFlagName, flags.Lookup(flagDebugDurationFlagName))
+	_ = viper.BindPFlag(lastEvaluatedFlagName, flags.Lookup(lastEvaluatedFlagName))
+	_ = viper.BindPFlag(fallbackConfigFlagName, flags.Lookup(fallbackConfigFlagName))
+	_ = viper.BindPFlag(flagSetFallbackFlagName, flags.Lookup(flagSetFallbackFlagName))
+	_ = viper.BindPFlag(geoIPDatabaseFlagName, flags.Lookup(geoIPDatabaseFlagName))
+	_ = viper.BindPFlag(jsonNumbersFlagName, flags.Lookup(jsonNumbersFlagName))
+	_ = viper.BindPFlag(jwtAudienceFlagName, flags.Lookup(jwtAudienceFlagName))
+	_ = viper.BindPFlag(jwtIssuerFlagName, flags.Lookup(jwtIssuerFlagName))
+	_ = viper.BindPFlag(jwtJWKSURLFlagName, flags.Lookup(jwtJWKSURLFlagName))
+	_ = viper.BindPFlag(jwtPublicKeyPathFlagName, flags.Lookup(jwtPublicKeyPathFlagName))
+	_ = viper.BindPFlag(peerContextFlagName, flags.Lookup(peerContextFlagName))
+	_ = viper.BindPFlag(strictTargetingFlagName, flags.Lookup(strictTargetingFlagName))
+	_ = viper.BindPFlag(startupSelfTestFlagName, flags.Lookup(startupSelfTestFlagName))
+	_ = viper.BindPFlag(defaultOnErrorFlagName, flags.Lookup(defaultOnErrorFlagName))
+	_ = viper.BindPFlag(defaultTargetingKeyFlagName, flags.Lookup(defaultTargetingKeyFlagName))
+	_ = viper.BindPFlag(grpcCompressionFlagName, flags.Lookup(grpcCompressionFlagName))
+	_ = viper.BindPFlag(rejectDuplicatesFlagName, flags.Lookup(rejectDuplicatesFlagName))
+	_ = viper.BindPFlag(rejectRegressionsFlagName, flags.Lookup(rejectRegressionsFlagName))
+	_ = viper.BindPFlag(regionContextKeyFlagName, flags.Lookup(regionContextKeyFlagName))
+	_ = viper.BindPFlag(allowEmptyConfigFlagName, flags.Lookup(allowEmptyConfigFlagName))
+	_ = viper.BindPFlag(unknownVariantsFlagName, flags.Lookup(unknownVariantsFlagName))
+	_ = viper.BindPFlag(webhookURLFlagName, flags.Lookup(webhookURLFlagName))
+	_ = viper.BindPFlag(webhookSecretFlagName, flags.Lookup(webhookSecretFlagName))
 	_ = viper.BindPFlag(logFormatFlagName, flags.Lookup(logFormatFlagName))
+	_ = viper.BindPFlag(subsystemLogLevelFlagName, flags.Lookup(subsystemLogLevelFlagName))
+	_ = viper.BindPFlag(maxConnectionsFlagName, flags.Lookup(maxConnectionsFlagName))
+	_ = viper.BindPFlag(maxContextBytesFlagName, flags.Lookup(maxContextBytesFlagName))
+	_ = viper.BindPFlag(maxEvalBodyFlagName, flags.Lookup(maxEvalBodyFlagName))
+	_ = viper.BindPFlag(maxBulkBodyFlagName, flags.Lookup(maxBulkBodyFlagName))
+	_ = viper.BindPFlag(maxAdminBodyFlagName, flags.Lookup(maxAdminBodyFlagName))
+	_ = viper.BindPFlag(maxEventStreamsFlagName, flags.Lookup(maxEventStreamsFlagName))
+	_ = viper.BindPFlag(maxFlagsFlagName, flags.Lookup(maxFlagsFlagName))
+	_ = viper.BindPFlag(maxSyncStreamsFlagName, flags.Lookup(maxSyncStreamsFlagName))
+	_ = viper.BindPFlag(maxTargetingDepthFlagName, flags.Lookup(maxTargetingDepthFlagName))
 	_ = viper.BindPFlag(metricsExporter, flags.Lookup(metricsExporter))
+	_ = viper.BindPFlag(metricsFormatFlagName, flags.Lookup(metricsFormatFlagName))
+	_ = viper.BindPFlag(metricsDisabledFlagName, flags.Lookup(metricsDisabledFlagName))
+	_ = viper.BindPFlag(metricsExportIntervalName, flags.Lookup(metricsExportIntervalName))
+	_ = viper.BindPFlag(metricsTemporalityName, flags.Lookup(metricsTemporalityName))
+	_ = viper.BindPFlag(metricsNativeHistograms, flags.Lookup(metricsNativeHistograms))
+	_ = viper.BindPFlag(metricsResponseSizeBucket, flags.Lookup(metricsResponseSizeBucket))
+	_ = viper.BindPFlag(metricsSlowestExemplars, flags.Lookup(metricsSlowestExemplars))
+	_ = viper.BindPFlag(metricsMissingContextKeys, flags.Lookup(metricsMissingContextKeys))
+	_ = viper.BindPFlag(metricsOperatorsExecuted, flags.Lookup(metricsOperatorsExecuted))
 	_ = viper.BindPFlag(managementPortFlagName, flags.Lookup(managementPortFlagName))
+	_ = viper.BindPFlag(managementAddressFlagName, flags.Lookup(managementAddressFlagName))
+	_ = viper.BindPFlag(managementCertPathFlagName, flags.Lookup(managementCertPathFlagName))
+	_ = viper.BindPFlag(managementKeyPathFlagName, flags.Lookup(managementKeyPathFlagName))
 	_ = viper.BindPFlag(otelCollectorURI, flags.Lookup(otelCollectorURI))
 	_ = viper.BindPFlag(otelCertPathFlagName, flags.Lookup(otelCertPathFlagName))
 	_ = viper.BindPFlag(otelKeyPathFlagName, flags.Lookup(otelKeyPathFlagName))
 	_ = viper.BindPFlag(otelCAPathFlagName, flags.Lookup(otelCAPathFlagName))
 	_ = viper.BindPFlag(portFlagName, flags.Lookup(portFlagName))
+	_ = viper.BindPFlag(readHeaderTimeoutFlagName, flags.Lookup(readHeaderTimeoutFlagName))
+	_ = viper.BindPFlag(readTimeoutFlagName, flags.Lookup(readTimeoutFlagName))
+	_ = viper.BindPFlag(writeTimeoutFlagName, flags.Lookup(writeTimeoutFlagName))
+	_ = viper.BindPFlag(idleTimeoutFlagName, flags.Lookup(idleTimeoutFlagName))
 	_ = viper.BindPFlag(serverCertPathFlagName, flags.Lookup(serverCertPathFlagName))
 	_ = viper.BindPFlag(serverKeyPathFlagName, flags.Lookup(serverKeyPathFlagName))
 	_ = viper.BindPFlag(socketPathFlagName, flags.Lookup(socketPathFlagName))
 	_ = viper.BindPFlag(sourcesFlagName, flags.Lookup(sourcesFlagName))
 	_ = viper.BindPFlag(uriFlagName, flags.Lookup(uriFlagName))
 	_ = viper.BindPFlag(syncPortFlagName, flags.Lookup(syncPortFlagName))
+	_ = viper.BindPFlag(syncMaxStalenessFlagName, flags.Lookup(syncMaxStalenessFlagName))
+	_ = viper.BindPFlag(syncStaleErrorsFlagName, flags.Lookup(syncStaleErrorsFlagName))
 	_ = viper.BindPFlag(ofrepPortFlagName, flags.Lookup(ofrepPortFlagName))
+	_ = viper.BindPFlag(ofrepPollingFlagName, flags.Lookup(ofrepPollingFlagName))
+	_ = viper.BindPFlag(ofrepBatchSizeFlagName, flags.Lookup(ofrepBatchSizeFlagName))
 	_ = viper.BindPFlag(contextValueFlagName, flags.Lookup(contextValueFlagName))
 }
 
@@ -114,11 +401,17 @@ var startCmd = &cobra.Command{
 		} else {
 			level = zapcore.InfoLevel
 		}
-		l, err := logger.NewZapLogger(level, viper.GetString(logFormatFlagName))
+		subsystemLevels, err := logger.ParseSubsystemLevels(viper.GetStringMapString(subsystemLogLevelFlagName))
+		if err != nil {
+			log.Fatalf("invalid %s: %v", subsystemLogLevelFlagName, err)
+		}
+		// subsystems may log at lower levels than flagd
+		minLevel := logger.MinLevel(level, subsystemLevels)
+		l, err := logger.NewZapLogger(minLevel, viper.GetString(logFormatFlagName))
 		if err != nil {
 			log.Fatalf("can't initialize zap logger: %v", err)
 		}
-		logger := logger.NewLogger(l, Debug)
+		logger := logger.NewLogger(l, minLevel == zapcore.DebugLevel).WithSubsystemLevels(level, subsystemLevels)
 		rtLogger := logger.WithFields(zap.String("component", "start"))
 
 		rtLogger.Info(fmt.Sprintf("flagd version: %s (%s), built at: %s", Version, Commit, Date))
@@ -140,6 +433,10 @@ var startCmd = &cobra.Command{
 				log.Fatal(err)
 			}
 		}
+		syncProvidersFromConfig, err = syncbuilder.ExpandSourceURIs(syncProvidersFromConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
 		syncProviders = append(syncProviders, syncProvidersFromConfig...)
 
 		contextValuesToMap := make(map[string]any)
@@ -149,22 +446,89 @@ var startCmd = &cobra.Command{
 
 		// Build Runtime -----------------------------------------------------------
 		rt, err := runtime.FromConfig(logger, Version, runtime.Config{
-			CORS:               viper.GetStringSlice(corsFlagName),
-			MetricExporter:     viper.GetString(metricsExporter),
-			ManagementPort:     viper.GetUint16(managementPortFlagName),
-			OfrepServicePort:   viper.GetUint16(ofrepPortFlagName),
-			OtelCollectorURI:   viper.GetString(otelCollectorURI),
-			OtelCertPath:       viper.GetString(otelCertPathFlagName),
-			OtelKeyPath:        viper.GetString(otelKeyPathFlagName),
-			OtelReloadInterval: viper.GetDuration(otelReloadIntervalFlagName),
-			OtelCAPath:         viper.GetString(otelCAPathFlagName),
-			ServiceCertPath:    viper.GetString(serverCertPathFlagName),
-			ServiceKeyPath:     viper.GetString(serverKeyPathFlagName),
-			ServicePort:        viper.GetUint16(portFlagName),
-			ServiceSocketPath:  viper.GetString(socketPathFlagName),
-			SyncServicePort:    viper.GetUint16(syncPortFlagName),
-			SyncProviders:      syncProviders,
-			ContextValues:      contextValuesToMap,
+			AdminAuditLog:       viper.GetString(adminAuditLogFlagName),
+			AdminToken:          viper.GetString(adminTokenFlagName),
+			AllowEmptyConfig:    viper.GetBool(allowEmptyConfigFlagName),
+			CaptureRedactKeys:   viper.GetStringSlice(captureRedactKeysFlagName),
+			CaptureSamples:      viper.GetInt(captureSamplesFlagName),
+			ConfigHistory:       viper.GetInt(configHistoryFlagName),
+			ConfigVersionHeader: viper.GetString(configVersionHeaderFlagName),
+			ContextAllowKeys:    viper.GetStringSlice(contextAllowKeysFlagName),
+			ContextRedactAll:    viper.GetBool(contextRedactAllFlagName),
+			ContextRedactKeys:   viper.GetStringSlice(contextRedactKeysFlagName),
+			CORS:                viper.GetStringSlice(corsFlagName),
+			DefaultTargetingKey: viper.GetString(defaultTargetingKeyFlagName),
+			EvaluationCacheSize: viper.GetInt(evaluationCacheSizeFlagName),
+			EvaluationTimeout:   viper.GetDuration(evaluationTimeoutFlagName),
+			FallbackConfig:      viper.GetString(fallbackConfigFlagName),
+			FlagDebugDuration:   viper.GetDuration(flagDebugDurationFlagName),
+			FlagSetFallback:     viper.GetStringSlice(flagSetFallbackFlagName),
+			GeoIPDatabase:       viper.GetString(geoIPDatabaseFlagName),
+			GRPCCompression:     viper.GetString(grpcCompressionFlagName),
+			JSONNumbers:         viper.GetBool(jsonNumbersFlagName),
+			LastEvaluatedFlags:  viper.GetInt(lastEvaluatedFlagName),
+			JWT: auth.Configuration{
+				PublicKeyPath: viper.GetString(jwtPublicKeyPathFlagName),
+				JWKSURL:       viper.GetString(jwtJWKSURLFlagName),
+				Issuer:        viper.GetString(jwtIssuerFlagName),
+				Audience:      viper.GetString(jwtAudienceFlagName),
+			},
+			MaxConnections:          viper.GetInt(maxConnectionsFlagName),
+			MaxContextBytes:         viper.GetInt(maxContextBytesFlagName),
+			MaxEvaluationBodyBytes:  viper.GetInt64(maxEvalBodyFlagName),
+			MaxBulkBodyBytes:        viper.GetInt64(maxBulkBodyFlagName),
+			MaxAdminBodyBytes:       viper.GetInt64(maxAdminBodyFlagName),
+			MaxEventStreams:         viper.GetInt(maxEventStreamsFlagName),
+			MaxFlags:                viper.GetInt(maxFlagsFlagName),
+			MaxSyncStreams:          viper.GetInt(maxSyncStreamsFlagName),
+			MaxTargetingDepth:       viper.GetInt(maxTargetingDepthFlagName),
+			MetricExporter:          viper.GetString(metricsExporter),
+			MetricsDisabled:         viper.GetStringSlice(metricsDisabledFlagName),
+			MetricsExportInterval:   viper.GetDuration(metricsExportIntervalName),
+			MetricsFormat:           viper.GetString(metricsFormatFlagName),
+			MetricsTemporality:      viper.GetString(metricsTemporalityName),
+			MetricsNativeHistograms: viper.GetBool(metricsNativeHistograms),
+			MetricsOperators:        viper.GetBool(metricsOperatorsExecuted),
+			MetricsSlowestExemplars: viper.GetDuration(metricsSlowestExemplars),
+			MetricsResponseSizeMax:  viper.GetFloat64(metricsResponseSizeBucket),
+			MissingContextKeys:      viper.GetStringSlice(metricsMissingContextKeys),
+			ManagementPort:          viper.GetUint16(managementPortFlagName),
+			ManagementAddress:       viper.GetString(managementAddressFlagName),
+			ManagementCertPath:      viper.GetString(managementCertPathFlagName),
+			ManagementKeyPath:       viper.GetString(managementKeyPathFlagName),
+			OfrepServicePort:        viper.GetUint16(ofrepPortFlagName),
+			OfrepMinPollingInterval: viper.GetDuration(ofrepPollingFlagName),
+			OfrepMaxBatchSize:       viper.GetInt(ofrepBatchSizeFlagName),
+			OtelCollectorURI:        viper.GetString(otelCollectorURI),
+			OtelCertPath:            viper.GetString(otelCertPathFlagName),
+			OtelKeyPath:             viper.GetString(otelKeyPathFlagName),
+			OtelReloadInterval:      viper.GetDuration(otelReloadIntervalFlagName),
+			OtelCAPath:              viper.GetString(otelCAPathFlagName),
+			PeerContext:             viper.GetBool(peerContextFlagName),
+			RegionContextKey:        viper.GetString(regionContextKeyFlagName),
+			RejectDuplicates:        viper.GetBool(rejectDuplicatesFlagName),
+			RejectRegressions:       viper.GetBool(rejectRegressionsFlagName),
+			RejectUnknownVariants:   viper.GetString(unknownVariantsFlagName),
+			SelfTest:                viper.GetString(startupSelfTestFlagName),
+			ServiceCertPath:         viper.GetString(serverCertPathFlagName),
+			ServiceKeyPath:          viper.GetString(serverKeyPathFlagName),
+			ServicePort:             viper.GetUint16(portFlagName),
+			ServiceSocketPath:       viper.GetString(socketPathFlagName),
+			ServerTimeouts: service.ServerTimeouts{
+				ReadHeader: viper.GetDuration(readHeaderTimeoutFlagName),
+				Read:       viper.GetDuration(readTimeoutFlagName),
+				Write:      viper.GetDuration(writeTimeoutFlagName),
+				Idle:       viper.GetDuration(idleTimeoutFlagName),
+			},
+			StrictTargeting:  viper.GetBool(strictTargetingFlagName),
+			DefaultOnError:   viper.GetBool(defaultOnErrorFlagName),
+			SyncServicePort:  viper.GetUint16(syncPortFlagName),
+			SyncProviders:    syncProviders,
+			SyncMaxStaleness: viper.GetDuration(syncMaxStalenessFlagName),
+			SyncStaleErrors:  viper.GetBool(syncStaleErrorsFlagName),
+			ContextValues:    contextValuesToMap,
+			WebhookURL:       viper.GetString(webhookURLFlagName),
+			WebhookSecret:    viper.GetString(webhookSecretFlagName),
 		})
 		if err != nil {
 			rtLogger.Fatal(err.Error())
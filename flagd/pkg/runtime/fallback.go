@@ -0,0 +1,45 @@
+package runtime
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// fallbackConfigs are the fallback configurations compiled into flagd. Configurations added to the fallback directory
+// before building flagd, e.g. fallback/default.flagd.json, can be selected by their file name.
+//
+//go:embed fallback/*.json
+var fallbackConfigs embed.FS
+
+// fallbackConfigSource is the source of the flags of the fallback configuration
+func fallbackConfigSource(name string) string {
+	return "fallback://" + name
+}
+
+// fallbackConfig reads the fallback configuration compiled into flagd with the given file name
+func fallbackConfig(name string) (sync.DataSync, error) {
+	data, err := fs.ReadFile(fallbackConfigs, path.Join("fallback", name))
+	if err != nil {
+		names, _ := fs.Glob(fallbackConfigs, "fallback/*.json")
+		for i := range names {
+			names[i] = path.Base(names[i])
+		}
+		return sync.DataSync{}, fmt.Errorf("unknown fallback configuration %s, compiled configurations are %v: %w",
+			name, names, err)
+	}
+	return sync.DataSync{FlagData: string(data), Source: fallbackConfigSource(name), Type: sync.ALL}, nil
+}
+
+// removeFallbackConfig removes the flags of the fallback configuration once a sync source delivered a configuration,
+// so that the flags of the sync sources are served exclusively
+func (r *Runtime) removeFallbackConfig() (bool, bool, error) {
+	r.Logger.Info(fmt.Sprintf("a sync source delivered a configuration, no longer serving the fallback "+
+		"configuration %s", r.FallbackConfig.Source))
+	source := r.FallbackConfig.Source
+	r.FallbackConfig = nil
+	return r.apply(sync.DataSync{FlagData: `{"flags": {}}`, Source: source, Type: sync.DELETE})
+}
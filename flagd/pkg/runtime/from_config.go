@@ -2,7 +2,10 @@ package runtime
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/open-feature/flagd/core/pkg/evaluator"
@@ -15,33 +18,163 @@ import (
 	flageval "github.com/open-feature/flagd/flagd/pkg/service/flag-evaluation"
 	"github.com/open-feature/flagd/flagd/pkg/service/flag-evaluation/ofrep"
 	flagsync "github.com/open-feature/flagd/flagd/pkg/service/flag-sync"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/auth"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/peer"
+	"github.com/open-feature/flagd/flagd/pkg/service/webhook"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // from_config is a collection of structures and parsers responsible for deriving flagd runtime
 
 const svcName = "flagd"
 
+const (
+	// SelfTestWarn logs the failed checks of the startup self-test
+	SelfTestWarn = "warn"
+	// SelfTestFail stops flagd if checks of the startup self-test fail
+	SelfTestFail = "fail"
+)
+
 // Config is the configuration structure derived from startup arguments.
 type Config struct {
-	MetricExporter     string
-	ManagementPort     uint16
-	OfrepServicePort   uint16
-	OtelCollectorURI   string
-	OtelCertPath       string
-	OtelKeyPath        string
-	OtelCAPath         string
-	OtelReloadInterval time.Duration
-	ServiceCertPath    string
-	ServiceKeyPath     string
-	ServicePort        uint16
-	ServiceSocketPath  string
-	SyncServicePort    uint16
+	MetricExporter string
+	// MetricsExportInterval and MetricsTemporality configure the metrics pushed to the OTEL collector
+	MetricsExportInterval time.Duration
+	MetricsTemporality    string
+	// MetricsNativeHistograms records the duration and size histograms as native histograms
+	MetricsNativeHistograms bool
+	// MetricsSlowestExemplars keeps the slowest request of each duration bucket as exemplar within the interval
+	MetricsSlowestExemplars time.Duration
+	// MetricsDisabled are the names of the metrics which are neither recorded nor exported
+	MetricsDisabled []string
+	// MetricsFormat is the exposition format of the Prometheus metrics, either telemetry.MetricsFormatText or
+	// telemetry.MetricsFormatOpenMetrics
+	MetricsFormat string
+	// MetricsResponseSizeMax is the top bucket boundary of the response size histogram in bytes
+	MetricsResponseSizeMax float64
+	ManagementPort         uint16
+	ManagementAddress      string
+	ManagementCertPath     string
+	ManagementKeyPath      string
+	OfrepServicePort       uint16
+	// OfrepMaxBatchSize is the maximum number of contexts of an OFREP batch evaluation, zero doesn't limit the contexts
+	OfrepMaxBatchSize int
+	// OfrepMinPollingInterval is advertised to OFREP clients as the minimum interval between polls, zero doesn't limit
+	// the polling
+	OfrepMinPollingInterval time.Duration
+	OtelCollectorURI        string
+	OtelCertPath            string
+	OtelKeyPath             string
+	OtelCAPath              string
+	OtelReloadInterval      time.Duration
+	ServiceCertPath         string
+	ServiceKeyPath          string
+	ServicePort             uint16
+	ServiceSocketPath       string
+	SyncServicePort         uint16
+	ServerTimeouts          service.ServerTimeouts
+	// MaxEventStreams and MaxSyncStreams cap the number of concurrent streams, zero doesn't limit them
+	MaxEventStreams int
+	MaxSyncStreams  int
+	// MaxConnections caps the number of concurrent connections of the flag evaluation service, zero doesn't limit them
+	MaxConnections int
+	// MaxContextBytes rejects evaluation requests whose context exceeds the size in bytes, zero doesn't limit the size
+	MaxContextBytes int
+	// MaxEvaluationBodyBytes, MaxBulkBodyBytes and MaxAdminBodyBytes limit the size in bytes of the request bodies of
+	// the single flag evaluations, the bulk and batch evaluations and the admin endpoints, zero doesn't limit the size
+	MaxEvaluationBodyBytes int64
+	MaxBulkBodyBytes       int64
+	MaxAdminBodyBytes      int64
+	// GRPCCompression is the compression of evaluation responses, e.g. service.CompressionGzip, empty doesn't
+	// compress responses
+	GRPCCompression string
 
 	SyncProviders []sync.SourceConfig
-	CORS          []string
+	// SyncMaxStaleness is the duration the last valid flag configuration is served after all sync sources were lost,
+	// flagd isn't ready once it is exceeded. Zero serves the last valid configuration indefinitely. With
+	// SyncStaleErrors, evaluations of a stale configuration result in an error.
+	SyncMaxStaleness time.Duration
+	SyncStaleErrors  bool
+	CORS             []string
+	// ConfigVersionHeader is the response header returning the version of the applied flag configuration, empty
+	// disables the header
+	ConfigVersionHeader string
 
 	ContextValues map[string]any
+	// FallbackConfig is the file name of a fallback configuration compiled into flagd, which is served until a sync
+	// source delivers a configuration, empty disables the fallback configuration
+	FallbackConfig string
+	// FlagSetFallback is the ordered chain of flag set IDs flags are looked up in
+	FlagSetFallback []string
+	JSONNumbers     bool
+	StrictTargeting bool
+	// DefaultOnError resolves flags whose targeting fails to their default variant
+	DefaultOnError bool
+	// RejectDuplicates rejects flag configurations defining a flag key more than once
+	RejectDuplicates bool
+	// AllowEmptyConfig applies flag configurations defining no flags instead of rejecting them
+	AllowEmptyConfig bool
+	// RejectUnknownVariants rejects the flags referencing variants they don't define, either
+	// evaluator.VariantRefRejectFlag or evaluator.VariantRefRejectConfig. Empty rejects unknown default variants only.
+	RejectUnknownVariants string
+	// RejectRegressions rejects flag configurations with an older monotonic version than the applied configuration of
+	// their source instead of applying them with a warning
+	RejectRegressions bool
+	// RegionContextKey is the evaluation context key holding the region the region defaults of flags are selected by,
+	// empty selects evaluator.DefaultRegionContextKey
+	RegionContextKey string
+	// DefaultTargetingKey is the JsonLogic expression synthesizing the targeting key of evaluation contexts lacking
+	// one, empty if targeting keys aren't synthesized
+	DefaultTargetingKey string
+	// EvaluationCacheSize bounds the results cached for flags opted into the evaluation cache, zero disables the cache
+	EvaluationCacheSize int
+	// EvaluationTimeout is the deadline of a single evaluation, zero doesn't limit evaluations
+	EvaluationTimeout time.Duration
+	// MaxTargetingDepth is the maximum nesting depth of evaluated targeting rules, zero doesn't limit the depth
+	MaxTargetingDepth int
+	// MaxFlags is the maximum number of flags of a flag configuration, zero doesn't limit the number of flags
+	MaxFlags int
+	// SelfTest checks the default variants of the flags of each source once its first configuration is applied, either
+	// logging failed checks with SelfTestWarn or stopping flagd with SelfTestFail. Empty disables the self-test.
+	SelfTest string
+	// MissingContextKeys are the evaluation context keys whose absence from contexts evaluated by targeting rules
+	// referencing them is counted
+	MissingContextKeys []string
+	// MetricsOperators records the number of operators executed per evaluation of targeting rules
+	MetricsOperators bool
+	// ContextRedactKeys are the evaluation context keys redacted wherever the evaluation context is logged or
+	// captured. With ContextRedactAll, all keys except the ContextAllowKeys are redacted instead.
+	ContextRedactKeys []string
+	ContextRedactAll  bool
+	ContextAllowKeys  []string
+
+	AdminToken string
+	// AdminAuditLog is the sink of the audit log entries of admin endpoint calls, either "stderr", "stdout" or the path
+	// of a file the entries are appended to
+	AdminAuditLog string
+	// CaptureSamples is the number of recent evaluations captured for the admin endpoints, zero disables capturing.
+	// The values of the CaptureRedactKeys of the evaluation context are redacted.
+	CaptureSamples    int
+	CaptureRedactKeys []string
+	// ConfigHistory is the number of applied flag configurations retained for the admin endpoints, zero disables
+	// retention
+	ConfigHistory int
+	// FlagDebugDuration is the maximum duration the detailed logging of the evaluations of a flag lasts, enabled on
+	// the admin endpoints. Zero disables flag debugging.
+	FlagDebugDuration time.Duration
+	// LastEvaluatedFlags is the number of flags whose last evaluation time is tracked for the admin endpoints, zero
+	// disables tracking
+	LastEvaluatedFlags int
+	// JWT verification of evaluation requests, enabled if a public key or JWKS URL is set
+	JWT auth.Configuration
+	// PeerContext adds the attributes of the peer of evaluation requests to the evaluation context, including the
+	// country of the peer if a GeoIPDatabase is set
+	PeerContext   bool
+	GeoIPDatabase string
+
+	WebhookURL    string
+	WebhookSecret string
 }
 
 // FromConfig builds a runtime from startup configurations
@@ -68,17 +201,65 @@ func FromConfig(logger *logger.Logger, version string, config Config) (*Runtime,
 		logger.Error(fmt.Sprintf("error building trace provider: %v", err))
 	}
 
+	if config.MetricsNativeHistograms && !telemetry.SupportsNativeHistograms(telCfg) {
+		return nil, errors.New("error configuring metrics exposition: native histograms require the otel metrics " +
+			"exporter, the Prometheus exporter only exposes histograms with explicit buckets")
+	}
+
+	if config.MetricsSlowestExemplars > 0 && !telemetry.ExemplarsEnabled() {
+		logger.Warn("not keeping the slowest exemplars, as exemplars are disabled")
+	}
+
+	if config.MetricsFormat == "" {
+		config.MetricsFormat = telemetry.MetricsFormatText
+	}
+	if err := telemetry.ValidateMetricsFormat(config.MetricsFormat); err != nil {
+		return nil, fmt.Errorf("error configuring metrics exposition: %w", err)
+	}
+	// exemplars are recorded for sampled traces, and for the slowest requests if configured
+	recordsExemplars := config.OtelCollectorURI != "" || config.MetricsSlowestExemplars > 0
+	if recordsExemplars && telemetry.ExemplarsEnabled() && !telemetry.ExposesExemplars(telCfg, config.MetricsFormat) {
+		logger.Warn(fmt.Sprintf("exemplars are dropped by the %s format of the Prometheus metrics, select the %s "+
+			"format to expose them", config.MetricsFormat, telemetry.MetricsFormatOpenMetrics))
+	}
+
+	for _, name := range config.MetricsDisabled {
+		if !telemetry.IsMetricName(name) {
+			logger.Warn(fmt.Sprintf("not disabling the metric %s, as flagd has no metric of this name", name))
+		}
+	}
+
 	// build metrics recorder with startup configurations
-	recorder, err := telemetry.BuildMetricsRecorder(context.Background(), svcName, version, telCfg)
+	recorder, err := telemetry.BuildMetricsRecorder(context.Background(), svcName, version, telCfg,
+		telemetry.WithExportInterval(config.MetricsExportInterval),
+		telemetry.WithTemporality(config.MetricsTemporality),
+		telemetry.WithNativeHistograms(config.MetricsNativeHistograms),
+		telemetry.WithSlowestExemplars(config.MetricsSlowestExemplars),
+		telemetry.WithResponseSizeMaxBucket(config.MetricsResponseSizeMax),
+		telemetry.WithDisabledMetrics(config.MetricsDisabled...),
+	)
 	if err != nil {
-		// log the error but continue
-		logger.Error(fmt.Sprintf("error building metrics recorder: %v", err))
+		// log the error but continue without metrics, instead of handing a nil recorder to the components
+		logger.Error(fmt.Sprintf("error building metrics recorder, metrics are disabled: %v", err))
+		recorder = &telemetry.NoopMetricsRecorder{}
 	}
 
 	// build flag store, collect flag sources & fill sources details
 	s := store.NewFlags()
+	s.FlagSetFallback = config.FlagSetFallback
 	sources := []string{}
 
+	var fallback *sync.DataSync
+	if config.FallbackConfig != "" {
+		fallbackData, err := fallbackConfig(config.FallbackConfig)
+		if err != nil {
+			return nil, err
+		}
+		fallback = &fallbackData
+		// the fallback configuration has the lowest priority, so that the flags of any sync source override it
+		s.FlagSources = append(s.FlagSources, fallback.Source)
+	}
+
 	for _, provider := range config.SyncProviders {
 		s.FlagSources = append(s.FlagSources, provider.URI)
 		s.SourceMetadata[provider.URI] = store.SourceDetails{
@@ -89,20 +270,211 @@ func FromConfig(logger *logger.Logger, version string, config Config) (*Runtime,
 	}
 
 	// derive evaluator
-	jsonEvaluator := evaluator.NewJSON(logger, s)
+	contextRedactor := evaluator.RedactKeys(config.ContextRedactKeys...)
+	if config.ContextRedactAll {
+		contextRedactor = evaluator.RedactAllExcept(config.ContextAllowKeys...)
+	}
+	evaluatorOptions := []evaluator.JSONEvaluatorOption{
+		evaluator.WithMetricsRecorder(recorder),
+		evaluator.WithContextRedactor(contextRedactor),
+		evaluator.WithMaxTargetingDepth(config.MaxTargetingDepth),
+		evaluator.WithMaxFlags(config.MaxFlags),
+		evaluator.WithEvaluationCacheSize(config.EvaluationCacheSize),
+	}
+	if config.JSONNumbers {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithJSONNumbers())
+	}
+	if config.StrictTargeting {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithStrictTargeting())
+	}
+	if config.DefaultOnError {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithDefaultOnTargetingError())
+	}
+	if config.RejectDuplicates {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithRejectDuplicateFlagKeys())
+	}
+	if config.AllowEmptyConfig {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithAllowEmptyConfig())
+	}
+	if config.RejectRegressions {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithRejectVersionRegressions())
+	}
+	switch config.RejectUnknownVariants {
+	case "":
+	case evaluator.VariantRefRejectFlag, evaluator.VariantRefRejectConfig:
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithVariantRefRejection(config.RejectUnknownVariants))
+	default:
+		return nil, fmt.Errorf("error configuring unknown variant rejection: unsupported mode '%s', must be '%s' or "+
+			"'%s'", config.RejectUnknownVariants, evaluator.VariantRefRejectFlag, evaluator.VariantRefRejectConfig)
+	}
+	if config.DefaultTargetingKey != "" {
+		if !json.Valid([]byte(config.DefaultTargetingKey)) {
+			return nil, fmt.Errorf("error parsing the default targeting key expression: invalid JSON")
+		}
+		evaluatorOptions = append(evaluatorOptions,
+			evaluator.WithDefaultTargetingKey(json.RawMessage(config.DefaultTargetingKey)))
+	}
+	if config.RegionContextKey != "" {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithRegionContextKey(config.RegionContextKey))
+	}
+	if config.EvaluationTimeout > 0 {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithEvaluationTimeout(config.EvaluationTimeout))
+	}
+	if len(config.MissingContextKeys) > 0 {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithMissingContextKeys(config.MissingContextKeys...))
+	}
+	if config.MetricsOperators {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithOperatorMetrics())
+	}
+	if fallback != nil {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithFallbackConfigSource(fallback.Source))
+	}
+	// retention of applied configurations, if enabled
+	var history *evaluator.ConfigHistory
+	if config.ConfigHistory > 0 {
+		if config.AdminToken == "" {
+			logger.Warn("not retaining flag configurations, as the admin endpoints are disabled")
+		} else {
+			history = evaluator.NewConfigHistory(config.ConfigHistory)
+			evaluatorOptions = append(evaluatorOptions, evaluator.WithConfigHistory(history))
+		}
+	}
+	// debugging of flag evaluations, toggled on the admin endpoints
+	var debugger *evaluator.FlagDebugger
+	if config.FlagDebugDuration > 0 && config.AdminToken != "" {
+		debugger = evaluator.NewFlagDebugger(config.FlagDebugDuration)
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithFlagDebugger(debugger))
+	}
+	// tracking of the last evaluation of each flag, if enabled
+	var lastEvaluated *evaluator.LastEvaluated
+	if config.LastEvaluatedFlags > 0 {
+		if config.AdminToken == "" {
+			logger.Warn("not tracking the last evaluation of flags, as the admin endpoints are disabled")
+		} else {
+			lastEvaluated = evaluator.NewLastEvaluated(config.LastEvaluatedFlags)
+			evaluatorOptions = append(evaluatorOptions, evaluator.WithLastEvaluated(lastEvaluated))
+		}
+	}
+	jsonEvaluator := evaluator.NewJSON(logger.WithSubsystem("evaluation"), s, evaluatorOptions...)
+	var eval evaluator.IEvaluator = jsonEvaluator
+
+	// capturing of evaluation samples, if enabled
+	var samples *evaluator.SampleRecorder
+	if config.CaptureSamples > 0 {
+		if config.AdminToken == "" {
+			logger.Warn("not capturing evaluation samples, as the admin endpoints are disabled")
+		} else {
+			samples = evaluator.NewSampleRecorder(eval, config.CaptureSamples,
+				evaluator.ChainRedactors(contextRedactor, evaluator.RedactKeys(config.CaptureRedactKeys...)))
+			eval = samples
+		}
+	}
+
+	var selfTest func(ctx context.Context, source string) error
+	switch config.SelfTest {
+	case "":
+	case SelfTestWarn, SelfTestFail:
+		selfTest = jsonEvaluator.SelfTest
+	default:
+		return nil, fmt.Errorf("error configuring startup self-test: unsupported mode '%s', must be '%s' or '%s'",
+			config.SelfTest, SelfTestWarn, SelfTestFail)
+	}
 
 	// derive services
 
+	if config.GRPCCompression != "" && config.GRPCCompression != service.CompressionGzip {
+		return nil, fmt.Errorf("error configuring response compression: unsupported compression '%s', must be '%s'",
+			config.GRPCCompression, service.CompressionGzip)
+	}
+
+	if (config.ManagementCertPath == "") != (config.ManagementKeyPath == "") {
+		return nil, fmt.Errorf("error configuring management server tls: both a certificate and a key path are required")
+	}
+
+	// audit log of the admin endpoints, if enabled
+	var adminAudit *zap.Logger
+	if config.AdminToken != "" {
+		adminAudit, err = newAdminAuditLogger(config.AdminAuditLog)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring admin audit log: %w", err)
+		}
+	}
+
+	// JWT authentication of evaluation requests, if configured
+	var authentication func(http.Handler) http.Handler
+	if config.JWT.PublicKeyPath != "" || config.JWT.JWKSURL != "" {
+		authMiddleware, err := auth.New(logger.WithSubsystem("service", zap.String("component", "auth")), config.JWT)
+		if err != nil {
+			return nil, fmt.Errorf("error creating JWT authentication: %w", err)
+		}
+		authentication = authMiddleware.Handler
+	}
+
+	// peer attributes of evaluation requests, if enabled
+	var peerContext func(http.Handler) http.Handler
+	if config.PeerContext {
+		var locator peer.Locator
+		if config.GeoIPDatabase != "" {
+			db, err := peer.LoadDatabase(config.GeoIPDatabase)
+			if err != nil {
+				return nil, fmt.Errorf("error loading GeoIP database: %w", err)
+			}
+			locator = db
+		}
+		peerContext = peer.New(locator).Handler
+	} else if config.GeoIPDatabase != "" {
+		logger.Warn("ignoring the GeoIP database, as the peer context is disabled")
+	}
+
+	// build sync providers
+	syncLogger := logger.WithSubsystem("sync", zap.String("component", "sync"))
+	iSyncs, staleness, err := syncProvidersFromConfig(syncLogger, config.SyncProviders, recorder)
+	if err != nil {
+		return nil, err
+	}
+
+	// staleness of the served configuration once all sync sources were lost, if limited
+	var stale func() bool
+	if config.SyncMaxStaleness > 0 {
+		guard := &stalenessGuard{
+			logger:       syncLogger,
+			maxStaleness: config.SyncMaxStaleness,
+			staleness:    staleness,
+		}
+		stale = guard.isStale
+		if config.SyncStaleErrors {
+			eval = evaluator.NewStaleGuard(eval, stale)
+		}
+	} else if config.SyncStaleErrors {
+		logger.Warn("not failing evaluations of stale configurations, as the maximum staleness is disabled")
+	}
+
 	// connect service
 	connectService := flageval.NewConnectService(
-		logger.WithFields(zap.String("component", "service")),
-		jsonEvaluator,
+		logger.WithSubsystem("service", zap.String("component", "service")),
+		eval,
 		recorder)
 
+	bodyLimits := service.BodyLimits{
+		Evaluation: config.MaxEvaluationBodyBytes,
+		Bulk:       config.MaxBulkBodyBytes,
+		Admin:      config.MaxAdminBodyBytes,
+	}
+
 	// ofrep service
-	ofrepService, err := ofrep.NewOfrepService(jsonEvaluator, config.CORS, ofrep.SvcConfiguration{
-		Logger: logger.WithFields(zap.String("component", "OFREPService")),
-		Port:   config.OfrepServicePort,
+	ofrepService, err := ofrep.NewOfrepService(eval, config.CORS, ofrep.SvcConfiguration{
+		Logger:              logger.WithSubsystem("service", zap.String("component", "OFREPService")),
+		Port:                config.OfrepServicePort,
+		Timeouts:            config.ServerTimeouts,
+		Authentication:      authentication,
+		PeerContext:         peerContext,
+		ConfigVersionHeader: config.ConfigVersionHeader,
+		ConfigVersion:       s.Version,
+		Metrics:             recorder,
+		MinPollingInterval:  config.OfrepMinPollingInterval,
+		MaxBatchSize:        config.OfrepMaxBatchSize,
+		MaxContextBytes:     config.MaxContextBytes,
+		BodyLimits:          bodyLimits,
 	},
 		config.ContextValues,
 	)
@@ -112,23 +484,32 @@ func FromConfig(logger *logger.Logger, version string, config Config) (*Runtime,
 
 	// flag sync service
 	flagSyncService, err := flagsync.NewSyncService(flagsync.SvcConfigurations{
-		Logger:        logger.WithFields(zap.String("component", "FlagSyncService")),
+		Logger:        logger.WithSubsystem("service", zap.String("component", "FlagSyncService")),
 		Port:          config.SyncServicePort,
 		Sources:       sources,
 		Store:         s,
 		ContextValues: config.ContextValues,
 		KeyPath:       config.ServiceKeyPath,
 		CertPath:      config.ServiceCertPath,
+		MaxStreams:    config.MaxSyncStreams,
+		Metrics:       recorder,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error creating sync service: %w", err)
 	}
 
-	// build sync providers
-	syncLogger := logger.WithFields(zap.String("component", "sync"))
-	iSyncs, err := syncProvidersFromConfig(syncLogger, config.SyncProviders)
-	if err != nil {
-		return nil, err
+	// webhook notifier, if configured
+	var notifier webhook.INotifier
+	if config.WebhookURL != "" {
+		notifier, err = webhook.NewNotifier(webhook.Configuration{
+			Logger:  logger.WithFields(zap.String("component", "webhook")),
+			URL:     config.WebhookURL,
+			Secret:  config.WebhookSecret,
+			Metrics: recorder,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating webhook notifier: %w", err)
+		}
 	}
 
 	options, err := telemetry.BuildConnectOptions(telCfg)
@@ -139,32 +520,78 @@ func FromConfig(logger *logger.Logger, version string, config Config) (*Runtime,
 
 	return &Runtime{
 		Logger:       logger.WithFields(zap.String("component", "runtime")),
-		Evaluator:    jsonEvaluator,
+		Evaluator:    eval,
 		FlagSync:     flagSyncService,
 		OfrepService: ofrepService,
 		Service:      connectService,
 		ServiceConfig: service.Configuration{
-			Port:           config.ServicePort,
-			ManagementPort: config.ManagementPort,
-			ServiceName:    svcName,
-			KeyPath:        config.ServiceKeyPath,
-			CertPath:       config.ServiceCertPath,
-			SocketPath:     config.ServiceSocketPath,
-			CORS:           config.CORS,
-			Options:        options,
-			ContextValues:  config.ContextValues,
+			Port:                config.ServicePort,
+			ManagementPort:      config.ManagementPort,
+			ManagementAddress:   config.ManagementAddress,
+			ManagementCertPath:  config.ManagementCertPath,
+			ManagementKeyPath:   config.ManagementKeyPath,
+			ServiceName:         svcName,
+			KeyPath:             config.ServiceKeyPath,
+			CertPath:            config.ServiceCertPath,
+			SocketPath:          config.ServiceSocketPath,
+			CORS:                config.CORS,
+			Options:             options,
+			ContextValues:       config.ContextValues,
+			AdminToken:          config.AdminToken,
+			AdminAudit:          adminAudit,
+			Samples:             samples,
+			History:             history,
+			Debugger:            debugger,
+			LastEvaluated:       lastEvaluated,
+			Timeouts:            config.ServerTimeouts,
+			Authentication:      authentication,
+			PeerContext:         peerContext,
+			MaxStreams:          config.MaxEventStreams,
+			MaxConnections:      config.MaxConnections,
+			MaxContextBytes:     config.MaxContextBytes,
+			BodyLimits:          bodyLimits,
+			ConfigVersionHeader: config.ConfigVersionHeader,
+			ConfigVersion:       s.Version,
+			Compression:         config.GRPCCompression,
+			MetricsFormat:       config.MetricsFormat,
 		},
-		SyncImpl: iSyncs,
+		SyncImpl:       iSyncs,
+		Webhook:        notifier,
+		Metrics:        recorder,
+		SelfTest:       selfTest,
+		SelfTestFatal:  config.SelfTest == SelfTestFail,
+		Stale:          stale,
+		FallbackConfig: fallback,
 	}, nil
 }
 
-// syncProvidersFromConfig is a helper to build ISync implementations from SourceConfig
-func syncProvidersFromConfig(logger *logger.Logger, sources []sync.SourceConfig) ([]sync.ISync, error) {
-	builder := syncbuilder.NewSyncBuilder()
+// syncProvidersFromConfig is a helper to build ISync implementations from SourceConfig, along with the staleness of
+// the built sources
+func syncProvidersFromConfig(
+	logger *logger.Logger, sources []sync.SourceConfig, recorder telemetry.IMetricsRecorder,
+) ([]sync.ISync, func() time.Duration, error) {
+	builder := syncbuilder.NewSyncBuilder(syncbuilder.WithMetricsRecorder(recorder))
 	syncs, err := builder.SyncsFromConfig(sources, logger)
 	if err != nil {
-		return nil, fmt.Errorf("could not create sync sources from config: %w", err)
+		return nil, nil, fmt.Errorf("could not create sync sources from config: %w", err)
 	}
 
-	return syncs, nil
+	return syncs, builder.Staleness, nil
+}
+
+// newAdminAuditLogger builds the logger of the audit log entries of admin endpoint calls, writing JSON entries to the
+// given sink independent of the format and level of the service log
+func newAdminAuditLogger(sink string) (*zap.Logger, error) {
+	if sink == "" {
+		sink = "stderr"
+	}
+	writer, _, err := zap.Open(sink)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open sink %s: %w", sink, err)
+	}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), writer, zapcore.InfoLevel)
+	return zap.New(core).With(zap.String("component", "audit")), nil
 }
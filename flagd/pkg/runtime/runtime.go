@@ -2,19 +2,30 @@ package runtime
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	msync "sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/open-feature/flagd/flagd/pkg/service/flag-evaluation/ofrep"
 	flagsync "github.com/open-feature/flagd/flagd/pkg/service/flag-sync"
+	"github.com/open-feature/flagd/flagd/pkg/service/webhook"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -26,8 +37,22 @@ type Runtime struct {
 	Service       service.IFlagEvaluationService
 	ServiceConfig service.Configuration
 	SyncImpl      []sync.ISync
+	// Webhook is notified about applied flag changes, if configured
+	Webhook webhook.INotifier
+	Metrics telemetry.IMetricsRecorder
+	// SelfTest checks the flags of a source once its first configuration is applied, if set. Failed checks stop the
+	// runtime if SelfTestFatal is set, and are logged otherwise.
+	SelfTest      func(ctx context.Context, source string) error
+	SelfTestFatal bool
+	// Stale reports whether the served flag configuration is stale, as all sync sources were lost for longer than the
+	// maximum staleness. flagd isn't ready while its configuration is stale. Nil disables the staleness check.
+	Stale func() bool
+	// FallbackConfig is applied before the sync sources are started, if set, and removed once a sync source delivered
+	// a configuration
+	FallbackConfig *sync.DataSync
 
-	mu msync.Mutex
+	mu         msync.Mutex
+	selfTested map[string]bool
 }
 
 //nolint:funlen
@@ -53,7 +78,11 @@ func (r *Runtime) Start() error {
 				// resync events are triggered when a delete occurs during flag merges in the store
 				// resync events may trigger further resync events, however for a flag to be deleted from the store
 				// its source must match, preventing the opportunity for resync events to snowball
-				if resyncRequired := r.updateAndEmit(data); resyncRequired {
+				resyncRequired, err := r.updateAndEmit(data)
+				if err != nil {
+					return err
+				}
+				if resyncRequired {
 					for _, s := range r.SyncImpl {
 						p := s
 						g.Go(func() error {
@@ -70,6 +99,29 @@ func (r *Runtime) Start() error {
 			}
 		}
 	})
+	// with a fallback configuration, flagd keeps serving if sync sources fail
+	fallback := r.FallbackConfig != nil
+	if fallback {
+		r.Logger.Info(fmt.Sprintf("serving the fallback configuration %s until a sync source delivers a configuration",
+			r.FallbackConfig.Source))
+		// received by the watcher before any configuration of the sync sources
+		dataSync <- *r.FallbackConfig
+	}
+	// a reload signal fetches the full configurations of the sync sources again. The changes are applied to the store
+	// and notified to the open event streams, which are only ended by shutting down.
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	g.Go(func() error {
+		for {
+			select {
+			case <-reload:
+				r.reload(gCtx, dataSync)
+			case <-gCtx.Done():
+				return nil
+			}
+		}
+	})
 	// Init sync providers
 	for _, s := range r.SyncImpl {
 		if err := s.Init(gCtx); err != nil {
@@ -81,6 +133,11 @@ func (r *Runtime) Start() error {
 		p := s
 		g.Go(func() error {
 			if err := p.Sync(gCtx, dataSync); err != nil {
+				if fallback {
+					r.Logger.Error(fmt.Sprintf("sync provider returned error, serving the configurations "+
+						"received so far: %v", err))
+					return nil
+				}
 				return fmt.Errorf("sync provider returned error: %w", err)
 			}
 			return nil
@@ -120,12 +177,31 @@ func (r *Runtime) Start() error {
 		return nil
 	})
 
+	if r.Webhook != nil {
+		g.Go(func() error {
+			if err := r.Webhook.Start(gCtx); err != nil {
+				return fmt.Errorf("error from webhook notifier: %w", err)
+			}
+			return nil
+		})
+	}
+
 	if err := g.Wait(); err != nil {
 		return fmt.Errorf("errgroup closed with error: %w", err)
 	}
 	return nil
 }
 
+// reload resyncs all sync sources. Sources failing to resync keep serving their current configuration.
+func (r *Runtime) reload(ctx context.Context, dataSync chan<- sync.DataSync) {
+	r.Logger.Info("reloading the flag configurations of the sync sources")
+	for _, p := range r.SyncImpl {
+		if err := p.ReSync(ctx, dataSync); err != nil {
+			r.Logger.Error(fmt.Sprintf("error reloading sync source: %v", err))
+		}
+	}
+}
+
 func (r *Runtime) isReady() bool {
 	// if all providers can watch for flag changes, we are ready.
 	for _, p := range r.SyncImpl {
@@ -133,20 +209,88 @@ func (r *Runtime) isReady() bool {
 			return false
 		}
 	}
-	return true
+	// load balancers shall route away from instances serving a stale configuration
+	return r.Stale == nil || !r.Stale()
+}
+
+// stalenessGuard reports the served flag configuration as stale once all sync sources were lost for longer than the
+// maximum staleness, logging each change of the staleness
+type stalenessGuard struct {
+	logger       *logger.Logger
+	maxStaleness time.Duration
+	staleness    func() time.Duration
+	stale        atomic.Bool
+}
+
+func (g *stalenessGuard) isStale() bool {
+	staleness := g.staleness()
+	stale := staleness > g.maxStaleness
+	if g.stale.Swap(stale) != stale {
+		if stale {
+			g.logger.Error(fmt.Sprintf("all sync sources were lost for %s, exceeding the maximum staleness of %s, "+
+				"the flag configuration is stale", staleness.Round(time.Second), g.maxStaleness))
+		} else {
+			g.logger.Info("a sync source recovered, the flag configuration is no longer stale")
+		}
+	}
+	return stale
 }
 
-// updateAndEmit helps to update state, notify changes and trigger sync updates
-func (r *Runtime) updateAndEmit(payload sync.DataSync) bool {
+// updateAndEmit helps to update state, notify changes and trigger sync updates. An error is only returned if the
+// self-test of the first configuration of the source fails fatally.
+func (r *Runtime) updateAndEmit(payload sync.DataSync) (bool, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	notifications, resyncRequired, err := r.Evaluator.SetState(payload)
+	applied, resyncRequired, err := r.apply(payload)
+	if err != nil || !applied || r.FallbackConfig == nil || payload.Source == r.FallbackConfig.Source {
+		return resyncRequired, err
+	}
+	_, removalResync, err := r.removeFallbackConfig()
+	return resyncRequired || removalResync, err
+}
+
+// apply applies a configuration and notifies the changes, reporting whether the configuration was valid and applied.
+// Each sync is traced as a span holding the spans of applying the configuration and dispatching the change events.
+func (r *Runtime) apply(payload sync.DataSync) (bool, bool, error) {
+	ctx, span := otel.Tracer("flagdRuntime").Start(context.Background(), "applySync", trace.WithAttributes(
+		attribute.String("feature_flag.source", payload.Source),
+		attribute.String("feature_flag.sync_type", payload.Type.String()),
+	))
+	defer span.End()
+
+	notifications, resyncRequired, err := r.Evaluator.SetStateWithContext(ctx, payload)
+	span.SetAttributes(attribute.Bool("feature_flag.sync_applied", err == nil))
 	if err != nil {
-		r.Logger.Error(err.Error())
-		return false
+		span.SetStatus(codes.Error, "applySync error")
+		span.RecordError(err)
+		// the flags of the last valid configuration are kept, as with fetch failures of the sync source
+		r.Logger.Error(fmt.Sprintf("error applying the configuration of source %s, keeping the last valid "+
+			"configuration: %v", payload.Source, err))
+		if r.Metrics != nil {
+			failureType := telemetry.SyncParseFailure
+			switch {
+			case errors.Is(err, evaluator.ErrFlagLimitExceeded):
+				failureType = telemetry.SyncFlagLimitFailure
+			case errors.Is(err, evaluator.ErrEmptyConfig):
+				failureType = telemetry.SyncEmptyConfigFailure
+			case errors.Is(err, evaluator.ErrUnknownVariant):
+				failureType = telemetry.SyncVariantRefFailure
+			case errors.Is(err, evaluator.ErrVersionRegression):
+				failureType = telemetry.SyncVersionRegressionFailure
+			}
+			telemetry.SyncMetrics(r.Metrics).SyncFailure(context.Background(), payload.Source, failureType)
+		}
+		return false, false, nil
+	}
+
+	if err := r.selfTest(payload.Source); err != nil {
+		return true, false, err
 	}
 
+	_, dispatchSpan := otel.Tracer("flagdRuntime").Start(ctx, "dispatchEvents", trace.WithAttributes(
+		attribute.Int("feature_flag.change_count", len(notifications)),
+	))
 	r.Service.Notify(service.Notification{
 		Type: service.ConfigurationChange,
 		Data: map[string]interface{}{
@@ -156,5 +300,57 @@ func (r *Runtime) updateAndEmit(payload sync.DataSync) bool {
 
 	r.FlagSync.Emit(resyncRequired, payload.Source)
 
-	return resyncRequired
+	if r.Webhook != nil && len(notifications) > 0 {
+		r.Webhook.Notify(r.changeEvent(payload.Source, notifications))
+	}
+	dispatchSpan.End()
+
+	return true, resyncRequired, nil
+}
+
+// selfTest runs the self-test on the first applied configuration of a source, later configurations are validated
+// when they are applied
+func (r *Runtime) selfTest(source string) error {
+	if r.SelfTest == nil || r.selfTested[source] {
+		return nil
+	}
+	if r.selfTested == nil {
+		r.selfTested = map[string]bool{}
+	}
+	r.selfTested[source] = true
+
+	err := r.SelfTest(context.Background(), source)
+	if err == nil {
+		return nil
+	}
+	if r.SelfTestFatal {
+		return fmt.Errorf("startup self-test of source %s failed: %w", source, err)
+	}
+	r.Logger.Warn(fmt.Sprintf("startup self-test of source %s failed: %v", source, err))
+	return nil
+}
+
+// changeEvent derives the webhook event of an applied change. The version identifies the resulting flag state, so
+// that receivers can tell whether different flagd instances serve the same configuration.
+func (r *Runtime) changeEvent(source string, notifications map[string]interface{}) webhook.Event {
+	keys := make([]string, 0, len(notifications))
+	for key := range notifications {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var version string
+	if state, err := r.Evaluator.GetState(); err != nil {
+		r.Logger.Warn(fmt.Sprintf("unable to derive the flag state version of the change event: %v", err))
+	} else {
+		digest := sha256.Sum256([]byte(state))
+		version = hex.EncodeToString(digest[:])
+	}
+
+	return webhook.Event{
+		Type:    webhook.EventTypeConfigurationChange,
+		Source:  source,
+		Flags:   keys,
+		Version: version,
+	}
 }
@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/evaluator"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/correlation"
+	"go.uber.org/zap"
+)
+
+const (
+	adminStatePath    = "/admin/state"
+	adminSamplesPath  = "/admin/samples"
+	adminHistoryPath  = "/admin/history"
+	adminDebugPath    = "/admin/debug"
+	adminLastEvalPath = "/admin/last-evaluated"
+	bearerPrefix      = "Bearer "
+
+	// auditSuccess, auditDenied, auditRejected and auditFailed are the outcomes of audited admin endpoint calls, either
+	// served, denied as unauthorized, rejected as invalid or failed
+	auditSuccess  = "success"
+	auditDenied   = "denied"
+	auditRejected = "rejected"
+	auditFailed   = "failed"
+)
+
+// adminAuditHandler emits an audit log entry for every call of an admin endpoint, recording the caller, the called
+// endpoint and the outcome. The entries are written to the audit logger, independent of the request log.
+type adminAuditHandler struct {
+	audit *zap.Logger
+	token []byte
+	next  http.Handler
+}
+
+func newAdminAuditHandler(audit *zap.Logger, token string, next http.Handler) *adminAuditHandler {
+	return &adminAuditHandler{
+		audit: audit,
+		token: []byte(token),
+		next:  next,
+	}
+}
+
+func (h *adminAuditHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	recorder := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	h.next.ServeHTTP(recorder, r)
+
+	h.audit.Info("admin endpoint access",
+		zap.String("remoteAddress", r.RemoteAddr),
+		zap.String("forwardedFor", r.Header.Get("X-Forwarded-For")),
+		zap.String("userAgent", r.UserAgent()),
+		zap.Bool("authenticated", adminAuthorized(r, h.token)),
+		zap.String("method", r.Method),
+		zap.String("endpoint", r.URL.Path),
+		zap.Int("status", recorder.status),
+		zap.String("outcome", auditOutcome(recorder.status)),
+	)
+}
+
+// auditOutcome returns the outcome of an admin endpoint call by its response status
+func auditOutcome(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return auditSuccess
+	case status == http.StatusUnauthorized:
+		return auditDenied
+	case status < http.StatusInternalServerError:
+		return auditRejected
+	default:
+		return auditFailed
+	}
+}
+
+// auditResponseWriter records the response status of an audited admin endpoint call
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap exposes the wrapped ResponseWriter to http.ResponseController
+func (w *auditResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// adminHandler serves an admin endpoint by fn, rejecting requests of other than the given methods and requests lacking
+// the configured token as bearer token
+func adminHandler(token string, methods []string, fn http.HandlerFunc) http.Handler {
+	expected := []byte(token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(methods, r.Method) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !adminAuthorized(r, expected) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		fn(w, r)
+	})
+}
+
+// writeAdminJSON writes the payload of an admin endpoint response, the response names the payload in logged errors
+func writeAdminJSON(w http.ResponseWriter, log *logger.Logger, response string, payload any) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Error(fmt.Sprintf("error marshalling admin %s response: %v", response, err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		log.Warn(fmt.Sprintf("error while writing admin %s response: %v", response, err))
+	}
+}
+
+// adminStateHandler dumps the current state of the flag store for debugging. The state contains the effective flag
+// configuration merged from all sources, where each flag is attributed to the source it was taken from.
+// As the state exposes targeting rules, the handler requires the configured token to be provided as bearer token.
+type adminStateHandler struct {
+	http.Handler
+	logger *logger.Logger
+	eval   evaluator.IEvaluator
+}
+
+func newAdminStateHandler(logger *logger.Logger, eval evaluator.IEvaluator, token string) *adminStateHandler {
+	h := &adminStateHandler{
+		logger: logger,
+		eval:   eval,
+	}
+	h.Handler = adminHandler(token, []string{http.MethodGet}, h.serve)
+	return h
+}
+
+func (h *adminStateHandler) serve(w http.ResponseWriter, _ *http.Request) {
+	state, err := h.eval.GetState()
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("error retrieving flag state for admin endpoint: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeAdminJSON(w, h.logger, "state", json.RawMessage(state))
+}
+
+// adminSamplesHandler dumps the captured evaluations, oldest first. As the samples contain request data, the handler
+// requires the configured token to be provided as bearer token.
+type adminSamplesHandler struct {
+	http.Handler
+	logger  *logger.Logger
+	samples *evaluator.SampleRecorder
+}
+
+func newAdminSamplesHandler(
+	logger *logger.Logger, samples *evaluator.SampleRecorder, token string,
+) *adminSamplesHandler {
+	h := &adminSamplesHandler{
+		logger:  logger,
+		samples: samples,
+	}
+	h.Handler = adminHandler(token, []string{http.MethodGet}, h.serve)
+	return h
+}
+
+func (h *adminSamplesHandler) serve(w http.ResponseWriter, _ *http.Request) {
+	writeAdminJSON(w, h.logger, "samples", h.samples.Samples())
+}
+
+// adminHistoryHandler evaluates flags against the retained prior flag configurations for forensics. GET lists the
+// retained versions, POST evaluates a historical evaluation request and DELETE evicts all retained versions. As the
+// history exposes the results of targeting rules, the handler requires the configured token as bearer token.
+type adminHistoryHandler struct {
+	http.Handler
+	logger  *logger.Logger
+	history *evaluator.ConfigHistory
+}
+
+// historicalEvaluationRequest evaluates the flag of the given key, or all flags if no key is given, against the
+// retained configuration of the given version
+type historicalEvaluationRequest struct {
+	Version string         `json:"version"`
+	FlagKey string         `json:"flagKey,omitempty"`
+	Context map[string]any `json:"context,omitempty"`
+}
+
+type historicalEvaluation struct {
+	FlagKey  string                 `json:"flagKey"`
+	Value    any                    `json:"value"`
+	Variant  string                 `json:"variant"`
+	Reason   string                 `json:"reason"`
+	Error    string                 `json:"error,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+func newAdminHistoryHandler(
+	logger *logger.Logger, history *evaluator.ConfigHistory, token string,
+) *adminHistoryHandler {
+	h := &adminHistoryHandler{
+		logger:  logger,
+		history: history,
+	}
+	h.Handler = adminHandler(token, []string{http.MethodGet, http.MethodPost, http.MethodDelete}, h.serve)
+	return h
+}
+
+func (h *adminHistoryHandler) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodDelete:
+		h.history.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		var request historicalEvaluationRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Version == "" {
+			w.WriteHeader(service.BodyErrorStatus(err))
+			return
+		}
+		resolver, ok := h.history.Resolver(request.Version)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeAdminJSON(w, h.logger, "history", h.evaluate(r.Context(), resolver, request))
+	default:
+		writeAdminJSON(w, h.logger, "history", h.history.Versions())
+	}
+}
+
+// evaluate evaluates a historical evaluation request, the evaluation of a single flag results in a single evaluation
+func (h *adminHistoryHandler) evaluate(
+	ctx context.Context, resolver *evaluator.Resolver, request historicalEvaluationRequest,
+) any {
+	reqID := correlation.NewRequestID(ctx, h.logger)
+	defer h.logger.ClearFields(reqID)
+	if request.FlagKey != "" {
+		return historicalEvaluationFrom(resolver.ResolveAsAnyValue(ctx, reqID, request.FlagKey, request.Context))
+	}
+
+	values, err := resolver.ResolveAllValues(ctx, reqID, request.Context)
+	if err != nil {
+		h.logger.Warn(fmt.Sprintf("error evaluating flags against configuration %s: %v", request.Version, err))
+	}
+	evaluations := make([]historicalEvaluation, 0, len(values))
+	for _, value := range values {
+		evaluations = append(evaluations, historicalEvaluationFrom(value))
+	}
+	return evaluations
+}
+
+func historicalEvaluationFrom(value evaluator.AnyValue) historicalEvaluation {
+	evaluation := historicalEvaluation{
+		FlagKey:  value.FlagKey,
+		Value:    value.Value,
+		Variant:  value.Variant,
+		Reason:   value.Reason,
+		Metadata: value.Metadata,
+	}
+	if value.Error != nil {
+		evaluation.Error = value.Error.Error()
+	}
+	return evaluation
+}
+
+// adminDebugHandler toggles the detailed logging of the evaluations of a single flag. GET returns the debugged flag,
+// POST starts debugging a flag and DELETE stops debugging. As the logged evaluations contain request data, the handler
+// requires the configured token as bearer token.
+type adminDebugHandler struct {
+	http.Handler
+	logger   *logger.Logger
+	debugger *evaluator.FlagDebugger
+}
+
+// flagDebugRequest starts debugging the flag of the given key, for the given duration if set, e.g. "5m"
+type flagDebugRequest struct {
+	FlagKey  string `json:"flagKey"`
+	Duration string `json:"duration,omitempty"`
+}
+
+func newAdminDebugHandler(
+	logger *logger.Logger, debugger *evaluator.FlagDebugger, token string,
+) *adminDebugHandler {
+	h := &adminDebugHandler{
+		logger:   logger,
+		debugger: debugger,
+	}
+	h.Handler = adminHandler(token, []string{http.MethodGet, http.MethodPost, http.MethodDelete}, h.serve)
+	return h
+}
+
+func (h *adminDebugHandler) serve(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodDelete:
+		h.debugger.Stop()
+		h.logger.Info("stopped debugging flag evaluations")
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodPost:
+		var request flagDebugRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.FlagKey == "" {
+			w.WriteHeader(service.BodyErrorStatus(err))
+			return
+		}
+		var duration time.Duration
+		if request.Duration != "" {
+			var err error
+			if duration, err = time.ParseDuration(request.Duration); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		status := h.debugger.Debug(request.FlagKey, duration)
+		h.logger.Info(fmt.Sprintf("debugging evaluations of flag %s until %s", status.FlagKey,
+			status.Expires.Format(time.RFC3339)))
+		writeAdminJSON(w, h.logger, "debug", status)
+	default:
+		writeAdminJSON(w, h.logger, "debug", h.debugger.Status())
+	}
+}
+
+// adminLastEvaluatedHandler lists the time each flag was last evaluated at, so that flags which aren't evaluated
+// anymore can be identified for cleanup. The unusedFor query parameter, e.g. "720h", restricts the list to the flags
+// not evaluated within the duration. As the list reveals the usage of the flags, the handler requires the configured
+// token as bearer token.
+type adminLastEvaluatedHandler struct {
+	http.Handler
+	logger  *logger.Logger
+	tracker *evaluator.LastEvaluated
+	now     func() time.Time
+}
+
+// lastEvaluatedResponse lists the last evaluations of the flags, tracked since the given time
+type lastEvaluatedResponse struct {
+	Since time.Time                     `json:"since"`
+	Flags []evaluator.FlagLastEvaluated `json:"flags"`
+}
+
+func newAdminLastEvaluatedHandler(
+	logger *logger.Logger, tracker *evaluator.LastEvaluated, token string,
+) *adminLastEvaluatedHandler {
+	h := &adminLastEvaluatedHandler{
+		logger:  logger,
+		tracker: tracker,
+		now:     time.Now,
+	}
+	h.Handler = adminHandler(token, []string{http.MethodGet}, h.serve)
+	return h
+}
+
+func (h *adminLastEvaluatedHandler) serve(w http.ResponseWriter, r *http.Request) {
+	var unusedFor time.Duration
+	if raw := r.URL.Query().Get("unusedFor"); raw != "" {
+		var err error
+		if unusedFor, err = time.ParseDuration(raw); err != nil || unusedFor < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	flags, err := h.tracker.Flags(r.Context())
+	if err != nil {
+		h.logger.Error(fmt.Sprintf("error listing the last evaluated flags for admin endpoint: %v", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if unusedFor > 0 {
+		cutoff := h.now().Add(-unusedFor)
+		unused := flags[:0]
+		for _, flag := range flags {
+			if flag.LastEvaluated == nil || flag.LastEvaluated.Before(cutoff) {
+				unused = append(unused, flag)
+			}
+		}
+		flags = unused
+	}
+
+	writeAdminJSON(w, h.logger, "last evaluated", lastEvaluatedResponse{Since: h.tracker.Since(), Flags: flags})
+}
+
+func adminAuthorized(r *http.Request, token []byte) bool {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return false
+	}
+	provided := []byte(strings.TrimPrefix(header, bearerPrefix))
+	return subtle.ConstantTimeCompare(provided, token) == 1
+}
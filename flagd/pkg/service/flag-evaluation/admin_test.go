@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/evaluator"
+	mock "github.com/open-feature/flagd/core/pkg/evaluator/mock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAdminStateHandler(t *testing.T) {
+	const (
+		token = "secret"
+		state = `{"flags":{"myFlag":{"state":"ENABLED","source":"file:flags.json"}}}`
+	)
+
+	tests := map[string]struct {
+		method         string
+		authorization  string
+		getStateErr    error
+		expectGetState bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		"valid token": {
+			method:         http.MethodGet,
+			authorization:  "Bearer " + token,
+			expectGetState: true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   state,
+		},
+		"missing token": {
+			method:         http.MethodGet,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"invalid token": {
+			method:         http.MethodGet,
+			authorization:  "Bearer nope",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"token without bearer scheme": {
+			method:         http.MethodGet,
+			authorization:  token,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"unsupported method": {
+			method:         http.MethodPost,
+			authorization:  "Bearer " + token,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		"state error": {
+			method:         http.MethodGet,
+			authorization:  "Bearer " + token,
+			getStateErr:    errors.New("boom"),
+			expectGetState: true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			eval := mock.NewMockIEvaluator(ctrl)
+			if tt.expectGetState {
+				eval.EXPECT().GetState().Return(state, tt.getStateErr)
+			}
+
+			h := newAdminStateHandler(logger.NewLogger(nil, false), eval, token)
+
+			req := httptest.NewRequest(tt.method, adminStatePath, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				require.JSONEq(t, tt.expectedBody, rec.Body.String())
+				require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestAdminSamplesHandler(t *testing.T) {
+	const token = "secret"
+
+	ctrl := gomock.NewController(t)
+	eval := mock.NewMockIEvaluator(ctrl)
+	eval.EXPECT().ResolveBooleanValue(gomock.Any(), "1", "myFlag", gomock.Any()).
+		Return(true, "on", model.StaticReason, nil, nil)
+
+	samples := evaluator.NewSampleRecorder(eval, 10, evaluator.RedactKeys("email"))
+	_, _, _, _, err := samples.ResolveBooleanValue(context.TODO(), "1", "myFlag",
+		map[string]any{"email": "user@example.com", "tier": "gold"})
+	require.NoError(t, err)
+
+	h := newAdminSamplesHandler(logger.NewLogger(nil, false), samples, token)
+
+	req := httptest.NewRequest(http.MethodGet, adminSamplesPath, nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var got []evaluator.Sample
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	require.Len(t, got, 1)
+	require.Equal(t, "myFlag", got[0].FlagKey)
+	require.Equal(t, true, got[0].Value)
+	require.Equal(t, "on", got[0].Variant)
+	require.Equal(t, map[string]any{"email": evaluator.RedactedValue, "tier": "gold"}, got[0].Context)
+}
+
+func TestAdminHistoryHandler(t *testing.T) {
+	const token = "secret"
+
+	history := evaluator.NewConfigHistory(5)
+	flags := store.NewFlags()
+	eval := evaluator.NewJSON(logger.NewLogger(nil, false), flags, evaluator.WithConfigHistory(history))
+	_, _, err := eval.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: `{
+		"flags": {
+			"myFlag": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`})
+	require.NoError(t, err)
+	version := flags.Version()
+	_, _, err = eval.SetState(sync.DataSync{Source: "file", Type: sync.ALL, FlagData: `{
+		"flags": {
+			"myFlag": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off"}
+		}
+	}`})
+	require.NoError(t, err)
+
+	h := newAdminHistoryHandler(logger.NewLogger(nil, false), history, token)
+	serve := func(method string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, adminHistoryPath, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, adminHistoryPath, nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var versions []evaluator.ConfigVersion
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &versions))
+	require.Len(t, versions, 2)
+	require.Equal(t, version, versions[0].Version)
+
+	// a single flag evaluated against the prior configuration
+	rec = serve(http.MethodPost, `{"version": "`+version+`", "flagKey": "myFlag", "context": {"tier": "gold"}}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var single historicalEvaluation
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &single))
+	require.Equal(t, true, single.Value)
+	require.Equal(t, "on", single.Variant)
+	require.Equal(t, model.StaticReason, single.Reason)
+
+	// all flags evaluated against the prior configuration
+	rec = serve(http.MethodPost, `{"version": "`+version+`"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var all []historicalEvaluation
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &all))
+	require.Len(t, all, 1)
+	require.Equal(t, "myFlag", all[0].FlagKey)
+	require.Equal(t, true, all[0].Value)
+
+	require.Equal(t, http.StatusNotFound, serve(http.MethodPost, `{"version": "unknown"}`).Code)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPost, `{}`).Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPut, "").Code)
+
+	// evicted configurations are no longer available
+	require.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodPost, `{"version": "`+version+`"}`).Code)
+}
+
+func TestAdminDebugHandler(t *testing.T) {
+	const token = "secret"
+
+	debugger := evaluator.NewFlagDebugger(time.Hour)
+	h := newAdminDebugHandler(logger.NewLogger(nil, false), debugger, token)
+	serve := func(method string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, adminDebugPath, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, adminDebugPath, strings.NewReader(`{"flagKey": "myFlag"}`)))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	require.Empty(t, debugger.Status().FlagKey)
+
+	rec = serve(http.MethodPost, `{"flagKey": "myFlag", "duration": "5m"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status evaluator.FlagDebugStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.Equal(t, "myFlag", status.FlagKey)
+	require.WithinDuration(t, time.Now().Add(5*time.Minute), status.Expires, time.Minute)
+
+	rec = serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	require.Equal(t, "myFlag", status.FlagKey)
+
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPost, `{}`).Code)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPost, `{"flagKey": "myFlag", "duration": "soon"}`).Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPut, "").Code)
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "").Code)
+	require.Empty(t, debugger.Status().FlagKey)
+}
+
+func TestAdminLastEvaluatedHandler(t *testing.T) {
+	const token = "secret"
+
+	tracker := evaluator.NewLastEvaluated(10)
+	eval := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags(), evaluator.WithLastEvaluated(tracker))
+	_, _, err := eval.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"used": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"},
+			"unused": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`, Source: "testSource", Type: sync.ALL})
+	require.NoError(t, err)
+	_, _, _, _, err = eval.ResolveBooleanValue(context.Background(), "req", "used", nil)
+	require.NoError(t, err)
+
+	h := newAdminLastEvaluatedHandler(logger.NewLogger(nil, false), tracker, token)
+	serve := func(method string, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, adminLastEvalPath+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	flagKeys := func(rec *httptest.ResponseRecorder) []string {
+		var response lastEvaluatedResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Equal(t, tracker.Since(), response.Since)
+		keys := []string{}
+		for _, flag := range response.Flags {
+			keys = append(keys, flag.FlagKey)
+		}
+		return keys
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, adminLastEvalPath, nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []string{"unused", "used"}, flagKeys(rec))
+
+	rec = serve(http.MethodGet, "?unusedFor=1h")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []string{"unused"}, flagKeys(rec))
+
+	h.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	rec = serve(http.MethodGet, "?unusedFor=1h")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, []string{"unused", "used"}, flagKeys(rec))
+
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "?unusedFor=soon").Code)
+	require.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodPost, "").Code)
+}
+
+func TestAdminAuditHandler(t *testing.T) {
+	const token = "secret"
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	debugger := evaluator.NewFlagDebugger(time.Hour)
+	h := newAdminAuditHandler(zap.New(core), token,
+		newAdminDebugHandler(logger.NewLogger(nil, false), debugger, token))
+
+	serve := func(method string, authorization string, body string) {
+		req := httptest.NewRequest(method, adminDebugPath, strings.NewReader(body))
+		req.RemoteAddr = "192.0.2.1:4711"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serve(http.MethodPost, "Bearer "+token, `{"flagKey": "myFlag"}`)
+	serve(http.MethodPost, "Bearer guessed", `{"flagKey": "myFlag"}`)
+	serve(http.MethodPost, "Bearer "+token, `{}`)
+	serve(http.MethodGet, "", "")
+
+	entries := logs.FilterMessage("admin endpoint access").AllUntimed()
+	require.Len(t, entries, 4)
+
+	tests := []struct {
+		authenticated bool
+		status        int64
+		outcome       string
+	}{
+		{authenticated: true, status: http.StatusOK, outcome: auditSuccess},
+		{authenticated: false, status: http.StatusUnauthorized, outcome: auditDenied},
+		{authenticated: true, status: http.StatusBadRequest, outcome: auditRejected},
+		{authenticated: false, status: http.StatusUnauthorized, outcome: auditDenied},
+	}
+	for i, tt := range tests {
+		fields := entries[i].ContextMap()
+		require.Equal(t, "192.0.2.1:4711", fields["remoteAddress"])
+		require.Equal(t, adminDebugPath, fields["endpoint"])
+		require.Equal(t, tt.authenticated, fields["authenticated"])
+		require.Equal(t, tt.status, fields["status"])
+		require.Equal(t, tt.outcome, fields["outcome"])
+	}
+	require.Equal(t, http.MethodGet, entries[3].ContextMap()["method"])
+}
+
+func TestAuditOutcome(t *testing.T) {
+	require.Equal(t, auditSuccess, auditOutcome(http.StatusNoContent))
+	require.Equal(t, auditDenied, auditOutcome(http.StatusUnauthorized))
+	require.Equal(t, auditRejected, auditOutcome(http.StatusMethodNotAllowed))
+	require.Equal(t, auditFailed, auditOutcome(http.StatusInternalServerError))
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"compress/gzip"
+	"math"
+
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/service"
+)
+
+// compressMinBytes is the size below which compressed responses are sent uncompressed, as compressing small messages
+// costs more CPU than it saves bandwidth
+const compressMinBytes = 1024
+
+// compressionOptions returns the handler options compressing the evaluation responses of clients advertising the given
+// compression. Without compression, compressed requests are still accepted, but responses are sent uncompressed.
+func compressionOptions(compression string) connect.HandlerOption {
+	if compression != service.CompressionGzip {
+		return connect.WithCompressMinBytes(math.MaxInt)
+	}
+	return connect.WithHandlerOptions(
+		connect.WithCompression(service.CompressionGzip,
+			func() connect.Decompressor { return &gzip.Reader{} },
+			func() connect.Compressor { return gzip.NewWriter(nil) },
+		),
+		connect.WithCompressMinBytes(compressMinBytes),
+	)
+}
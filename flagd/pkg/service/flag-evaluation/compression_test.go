@@ -0,0 +1,64 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	evaluationV1 "buf.build/gen/go/open-feature/flagd/connectrpc/go/flagd/evaluation/v1/evaluationv1connect"
+	"github.com/open-feature/flagd/core/pkg/evaluator"
+	mock "github.com/open-feature/flagd/core/pkg/evaluator/mock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCompressionOptions(t *testing.T) {
+	values := make([]evaluator.AnyValue, 0, 100)
+	for i := range 100 {
+		values = append(values, evaluator.AnyValue{
+			Value: "value", Variant: "on", Reason: model.StaticReason, FlagKey: fmt.Sprintf("flag-%d", i),
+		})
+	}
+
+	tests := map[string]struct {
+		compression string
+		expected    string
+	}{
+		"gzip": {
+			compression: service.CompressionGzip,
+			expected:    "gzip",
+		},
+		"disabled": {},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			eval := mock.NewMockIEvaluator(gomock.NewController(t))
+			eval.EXPECT().ResolveAllValuesWithVersion(gomock.Any(), gomock.Any(), gomock.Any()).Return(values, "", nil)
+
+			svc := NewFlagEvaluationService(logger.NewLogger(nil, false), eval, &eventingConfiguration{},
+				&telemetry.NoopMetricsRecorder{}, nil)
+			_, handler := evaluationV1.NewServiceHandler(svc, compressionOptions(tt.compression))
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+evaluationV1.ServiceResolveAllProcedure,
+				strings.NewReader("{}"))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Encoding", "gzip")
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, tt.expected, res.Header.Get("Content-Encoding"))
+		})
+	}
+}
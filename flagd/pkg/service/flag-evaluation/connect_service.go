@@ -7,21 +7,24 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	evaluationV1 "buf.build/gen/go/open-feature/flagd/connectrpc/go/flagd/evaluation/v1/evaluationv1connect"
 	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/connectrpc/go/schema/v1/schemav1connect"
+	"connectrpc.com/connect"
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/open-feature/flagd/flagd/pkg/service/middleware"
+	configversionmw "github.com/open-feature/flagd/flagd/pkg/service/middleware/configversion"
+	correlationmw "github.com/open-feature/flagd/flagd/pkg/service/middleware/correlation"
 	corsmw "github.com/open-feature/flagd/flagd/pkg/service/middleware/cors"
 	h2cmw "github.com/open-feature/flagd/flagd/pkg/service/middleware/h2c"
 	metricsmw "github.com/open-feature/flagd/flagd/pkg/service/middleware/metrics"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
@@ -36,6 +39,10 @@ const (
 	ErrorPrefix = "FlagdError:"
 
 	flagdSchemaPrefix = "/flagd"
+
+	eventStreamSuffix = "/EventStream"
+	resolveAllSuffix  = "/ResolveAll"
+	healthWatchPath   = "/grpc.health.v1.Health/Watch"
 )
 
 // bufSwitchHandler combines the handlers of the old and new evaluation schema and combines them into one
@@ -58,7 +65,7 @@ type ConnectService struct {
 	logger                *logger.Logger
 	eval                  evaluator.IEvaluator
 	metrics               telemetry.IMetricsRecorder
-	eventingConfiguration IEvents
+	eventingConfiguration *eventingConfiguration
 
 	server        *http.Server
 	metricsServer *http.Server
@@ -73,18 +80,23 @@ type ConnectService struct {
 func NewConnectService(
 	logger *logger.Logger, evaluator evaluator.IEvaluator, mRecorder telemetry.IMetricsRecorder,
 ) *ConnectService {
-	cs := &ConnectService{
+	eventing := &eventingConfiguration{
 		logger:  logger,
-		eval:    evaluator,
-		metrics: &telemetry.NoopMetricsRecorder{},
-		eventingConfiguration: &eventingConfiguration{
-			subs: make(map[interface{}]chan service.Notification),
-			mu:   &sync.RWMutex{},
-		},
+		metrics: telemetry.ServiceMetrics(mRecorder),
+		subs:    make(map[any]subscription),
+		mu:      &sync.RWMutex{},
+		closed:  make(chan struct{}),
+	}
+	cs := &ConnectService{
+		logger:                logger,
+		eval:                  evaluator,
+		metrics:               &telemetry.NoopMetricsRecorder{},
+		eventingConfiguration: eventing,
 	}
 	if mRecorder != nil {
 		cs.metrics = mRecorder
 	}
+	telemetry.ServiceMetrics(cs.metrics).ChangeSubscribers(eventing.Subscribers)
 	return cs
 }
 
@@ -146,6 +158,8 @@ func (s *ConnectService) setupServer(svcConf service.Configuration) (net.Listene
 	if err != nil {
 		return nil, fmt.Errorf("error creating listener for flag evaluation service: %w", err)
 	}
+	limiter := service.NewConnectionLimiter(lis, svcConf.MaxConnections)
+	telemetry.ServiceMetrics(s.metrics).OpenConnections(limiter.Open)
 
 	// register handler for old flag evaluation schema
 	// can be removed as a part of https://github.com/open-feature/flagd/issues/1088
@@ -162,8 +176,18 @@ func (s *ConnectService) setupServer(svcConf service.Configuration) (net.Listene
 		protojson.MarshalOptions{EmitUnpopulated: true},
 		protojson.UnmarshalOptions{DiscardUnknown: true},
 	)
+	handlerOpts := append(append([]connect.HandlerOption{}, svcConf.Options...),
+		marshalOpts,
+		connect.WithInterceptors(newRPCMetricsInterceptor(s.metrics),
+			newContextSizeInterceptor(s.metrics, svcConf.MaxContextBytes), typedContextInterceptor{}),
+		compressionOptions(svcConf.Compression),
+	)
 
-	_, oldHandler := schemaConnectV1.NewServiceHandler(fes, append(svcConf.Options, marshalOpts)...)
+	// event streams of both schemas share the limit of concurrent streams
+	streams := service.NewStreamLimiter(service.EventStream, svcConf.MaxStreams, s.metrics)
+	fes.streams = streams
+
+	_, oldHandler := schemaConnectV1.NewServiceHandler(fes, handlerOpts...)
 
 	// register handler for new flag evaluation schema
 
@@ -173,8 +197,9 @@ func (s *ConnectService) setupServer(svcConf service.Configuration) (net.Listene
 		s.metrics,
 		svcConf.ContextValues,
 	)
+	newFes.streams = streams
 
-	_, newHandler := evaluationV1.NewServiceHandler(newFes, append(svcConf.Options, marshalOpts)...)
+	_, newHandler := evaluationV1.NewServiceHandler(newFes, handlerOpts...)
 
 	bs := bufSwitchHandler{
 		old: oldHandler,
@@ -182,13 +207,20 @@ func (s *ConnectService) setupServer(svcConf service.Configuration) (net.Listene
 	}
 
 	s.serverMtx.Lock()
-	s.server = &http.Server{
-		ReadHeaderTimeout: time.Second,
-		Handler:           bs,
-	}
+	s.server = service.NewHTTPServer("", exemptStreams(s.logger, limitBodies(bs, svcConf.BodyLimits),
+		func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, eventStreamSuffix)
+		}), svcConf.Timeouts)
 	s.serverMtx.Unlock()
 
 	// Add middlewares
+	if svcConf.PeerContext != nil {
+		s.server.Handler = svcConf.PeerContext(s.server.Handler)
+	}
+	if svcConf.Authentication != nil {
+		s.server.Handler = svcConf.Authentication(s.server.Handler)
+	}
+
 	metricsMiddleware := metricsmw.NewHTTPMetric(metricsmw.Config{
 		Service:        svcConf.ServiceName,
 		MetricRecorder: s.metrics,
@@ -198,7 +230,15 @@ func (s *ConnectService) setupServer(svcConf service.Configuration) (net.Listene
 
 	s.AddMiddleware(metricsMiddleware)
 
-	corsMiddleware := corsmw.New(svcConf.CORS)
+	s.AddMiddleware(correlationmw.New())
+
+	var exposedHeaders []string
+	if svcConf.ConfigVersionHeader != "" && svcConf.ConfigVersion != nil {
+		s.AddMiddleware(configversionmw.New(svcConf.ConfigVersionHeader, svcConf.ConfigVersion))
+		exposedHeaders = append(exposedHeaders, svcConf.ConfigVersionHeader)
+	}
+
+	corsMiddleware := corsmw.New(svcConf.CORS, exposedHeaders...)
 	s.AddMiddleware(corsMiddleware)
 
 	if svcConf.CertPath == "" || svcConf.KeyPath == "" {
@@ -206,7 +246,7 @@ func (s *ConnectService) setupServer(svcConf service.Configuration) (net.Listene
 		s.AddMiddleware(h2cMiddleware)
 	}
 
-	return lis, nil
+	return limiter, nil
 }
 
 func (s *ConnectService) AddMiddleware(mw middleware.IMiddleware) {
@@ -219,6 +259,8 @@ func (s *ConnectService) Shutdown() {
 		Type: service.Shutdown,
 		Data: map[string]interface{}{},
 	})
+	// configuration changes keep the event streams open, only shutting down ends them
+	s.eventingConfiguration.Close()
 }
 
 func (s *ConnectService) startServer(svcConf service.Configuration) error {
@@ -246,7 +288,8 @@ func (s *ConnectService) startServer(svcConf service.Configuration) error {
 }
 
 func (s *ConnectService) startMetricsServer(svcConf service.Configuration) error {
-	s.logger.Info(fmt.Sprintf("metrics and probes listening at %d", svcConf.ManagementPort))
+	address := net.JoinHostPort(svcConf.ManagementAddress, strconv.Itoa(int(svcConf.ManagementPort)))
+	s.logger.Info(fmt.Sprintf("metrics and probes listening at %s", address))
 
 	srv := grpc.NewServer()
 	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
@@ -262,7 +305,32 @@ func (s *ConnectService) startMetricsServer(svcConf service.Configuration) error
 			w.WriteHeader(http.StatusPreconditionFailed)
 		}
 	}))
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", telemetry.MetricsHandler(svcConf.MetricsFormat))
+	if svcConf.AdminToken != "" {
+		// every call of an admin endpoint is audited, falling back to the service log if no audit log is set
+		audit := svcConf.AdminAudit
+		if audit == nil {
+			audit = s.logger.Logger
+		}
+		handleAdmin := func(path string, handler http.Handler) {
+			mux.Handle(path, newAdminAuditHandler(audit, svcConf.AdminToken,
+				service.LimitBody(handler, svcConf.BodyLimits.Admin)))
+		}
+		handleAdmin(adminStatePath, newAdminStateHandler(s.logger, s.eval, svcConf.AdminToken))
+		if svcConf.Samples != nil {
+			handleAdmin(adminSamplesPath, newAdminSamplesHandler(s.logger, svcConf.Samples, svcConf.AdminToken))
+		}
+		if svcConf.History != nil {
+			handleAdmin(adminHistoryPath, newAdminHistoryHandler(s.logger, svcConf.History, svcConf.AdminToken))
+		}
+		if svcConf.Debugger != nil {
+			handleAdmin(adminDebugPath, newAdminDebugHandler(s.logger, svcConf.Debugger, svcConf.AdminToken))
+		}
+		if svcConf.LastEvaluated != nil {
+			handleAdmin(adminLastEvalPath, newAdminLastEvaluatedHandler(s.logger, svcConf.LastEvaluated,
+				svcConf.AdminToken))
+		}
+	}
 
 	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		// if this is 'application/grpc' and HTTP2, handle with gRPC, otherwise HTTP.
@@ -275,15 +343,54 @@ func (s *ConnectService) startMetricsServer(svcConf service.Configuration) error
 	})
 
 	s.metricsServerMtx.Lock()
-	s.metricsServer = &http.Server{
-		Addr:              fmt.Sprintf(":%d", svcConf.ManagementPort),
-		ReadHeaderTimeout: 3 * time.Second,
-		Handler:           h2c.NewHandler(handler, &http2.Server{}), // we need to use h2c to support plaintext HTTP2
-	}
+	s.metricsServer = service.NewHTTPServer(
+		address,
+		// we need to use h2c to support plaintext HTTP2
+		h2c.NewHandler(exemptStreams(s.logger, handler, func(r *http.Request) bool {
+			return r.URL.Path == healthWatchPath
+		}), &http2.Server{}),
+		svcConf.Timeouts,
+	)
 	s.metricsServerMtx.Unlock()
 
-	if err := s.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	var err error
+	if svcConf.ManagementCertPath != "" && svcConf.ManagementKeyPath != "" {
+		err = s.metricsServer.ListenAndServeTLS(svcConf.ManagementCertPath, svcConf.ManagementKeyPath)
+	} else {
+		err = s.metricsServer.ListenAndServe()
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return fmt.Errorf("error returned from metrics server: %w", err)
 	}
 	return nil
 }
+
+// limitBodies limits the request bodies of the evaluation services, ResolveAll requests being bulk requests
+func limitBodies(next http.Handler, limits service.BodyLimits) http.Handler {
+	evaluation := service.LimitBody(next, limits.Evaluation)
+	bulk := service.LimitBody(next, limits.Bulk)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, resolveAllSuffix) {
+			bulk.ServeHTTP(w, r)
+			return
+		}
+		evaluation.ServeHTTP(w, r)
+	})
+}
+
+// exemptStreams clears the read and write deadlines of long-lived streams, which would otherwise be closed once the
+// server timeouts expire. Streams end with the client disconnecting or the server shutting down instead.
+func exemptStreams(log *logger.Logger, next http.Handler, isStream func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isStream(r) {
+			rc := http.NewResponseController(w)
+			if err := rc.SetReadDeadline(time.Time{}); err != nil {
+				log.Debug(fmt.Sprintf("unable to clear the read deadline of stream %s: %v", r.URL.Path, err))
+			}
+			if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+				log.Debug(fmt.Sprintf("unable to clear the write deadline of stream %s: %v", r.URL.Path, err))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
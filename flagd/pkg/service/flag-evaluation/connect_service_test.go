@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -182,7 +186,7 @@ func TestConnectServiceNotify(t *testing.T) {
 
 	sChan := make(chan iservice.Notification, 1)
 	eventing := service.eventingConfiguration
-	eventing.Subscribe("key", sChan)
+	eventing.Subscribe("key", EventFilter{}, sChan)
 
 	// notification type
 	ofType := iservice.ConfigurationChange
@@ -220,7 +224,7 @@ func TestConnectServiceShutdown(t *testing.T) {
 
 	sChan := make(chan iservice.Notification, 1)
 	eventing := service.eventingConfiguration
-	eventing.Subscribe("key", sChan)
+	eventing.Subscribe("key", EventFilter{}, sChan)
 
 	// notification type
 	ofType := iservice.Shutdown
@@ -246,3 +250,136 @@ func TestConnectServiceShutdown(t *testing.T) {
 		t.Error("timeout while waiting for notifications")
 	}
 }
+
+func TestConnectService_EventStreamOutlivesTimeouts(t *testing.T) {
+	const socketPath = "/tmp/flagd-timeouts.sock"
+	_ = os.Remove(socketPath)
+
+	ctrl := gomock.NewController(t)
+	eval := mock.NewMockIEvaluator(ctrl)
+
+	svc := NewConnectService(logger.NewLogger(nil, false), eval, nil)
+	serveConf := iservice.Configuration{
+		ReadinessProbe: func() bool {
+			return true
+		},
+		SocketPath: socketPath,
+		Timeouts: iservice.ServerTimeouts{
+			Read:  100 * time.Millisecond,
+			Write: 100 * time.Millisecond,
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		err := svc.Serve(ctx, serveConf)
+		fmt.Println(err)
+	}()
+	conn, err := grpc.Dial(
+		fmt.Sprintf("unix://%s", socketPath),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+		grpc.WithTimeout(2*time.Second),
+	)
+	require.NoError(t, err)
+	client := schemaGrpcV1.NewServiceClient(conn)
+
+	stream, err := client.EventStream(ctx, &schemaV1.EventStreamRequest{})
+	require.NoError(t, err)
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, string(iservice.ProviderReady), res.Type)
+
+	// exceed the read and write timeouts before the next event
+	time.Sleep(300 * time.Millisecond)
+	svc.Notify(iservice.Notification{
+		Type: iservice.ConfigurationChange,
+		Data: map[string]interface{}{},
+	})
+
+	res, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, string(iservice.ConfigurationChange), res.Type)
+}
+
+func TestConnectService_ManagementTLS(t *testing.T) {
+	const socketPath = "/tmp/flagd-management.sock"
+	_ = os.Remove(socketPath)
+
+	// reserve a free port of the loopback interface for the management server
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := lis.Addr().(*net.TCPAddr).Port
+	require.NoError(t, lis.Close())
+
+	ctrl := gomock.NewController(t)
+	eval := mock.NewMockIEvaluator(ctrl)
+
+	svc := NewConnectService(logger.NewLogger(nil, false), eval, nil)
+	serveConf := iservice.Configuration{
+		ReadinessProbe: func() bool {
+			return true
+		},
+		SocketPath:         socketPath,
+		ManagementAddress:  "127.0.0.1",
+		ManagementPort:     uint16(port),
+		ManagementCertPath: "../flag-sync/test-cert/server-cert.pem",
+		ManagementKeyPath:  "../flag-sync/test-cert/server-key.pem",
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go func() {
+		err := svc.Serve(ctx, serveConf)
+		fmt.Println(err)
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			// #nosec G402 -- the test certificate is self-signed
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		Timeout: time.Second,
+	}
+	url := fmt.Sprintf("https://127.0.0.1:%d/healthz", port)
+	require.Eventually(t, func() bool {
+		res, err := client.Get(url)
+		if err != nil {
+			return false
+		}
+		defer res.Body.Close()
+		return res.StatusCode == http.StatusOK
+	}, 3*time.Second, 50*time.Millisecond)
+
+	// plaintext requests are rejected by the TLS server
+	res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/healthz", port))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestLimitBodies(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := limitBodies(ok, iservice.BodyLimits{Evaluation: 8, Bulk: 32})
+
+	tests := map[string]struct {
+		path   string
+		status int
+	}{
+		"evaluation":    {path: "/flagd.evaluation.v1.Service/ResolveBoolean", status: http.StatusRequestEntityTooLarge},
+		"old schema":    {path: "/schema.v1.Service/ResolveBoolean", status: http.StatusRequestEntityTooLarge},
+		"bulk":          {path: "/flagd.evaluation.v1.Service/ResolveAll", status: http.StatusOK},
+		"old bulk path": {path: "/schema.v1.Service/ResolveAll", status: http.StatusOK},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, tt.path,
+				strings.NewReader(`{"flagKey": "my-flag"}`)))
+			require.Equal(t, tt.status, recorder.Code)
+		})
+	}
+}
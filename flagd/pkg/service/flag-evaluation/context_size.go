@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"google.golang.org/protobuf/proto"
+)
+
+// contextSizeInterceptor rejects evaluation requests whose context exceeds the maximum size in bytes before they are
+// evaluated, and records the size of the accepted contexts. The size is the size of the context in the protobuf
+// encoding, regardless of the protocol of the request.
+type contextSizeInterceptor struct {
+	metrics telemetry.IMetricsRecorder
+	// maxBytes is the maximum size of an evaluation context in bytes, zero doesn't limit the size
+	maxBytes int
+}
+
+func newContextSizeInterceptor(metrics telemetry.IMetricsRecorder, maxBytes int) *contextSizeInterceptor {
+	return &contextSizeInterceptor{metrics: metrics, maxBytes: maxBytes}
+}
+
+func (i *contextSizeInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if msg, ok := req.Any().(contextRequest); ok && msg.GetContext() != nil {
+			size := proto.Size(msg.GetContext())
+			if err := service.CheckContextSize(size, i.maxBytes); err != nil {
+				return nil, connect.NewError(connect.CodeInvalidArgument, err)
+			}
+			telemetry.EvaluationMetrics(i.metrics).EvaluationContextSize(ctx, apiSurface(req.Peer()), int64(size))
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *contextSizeInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler leaves streams as they are, as event streams don't carry an evaluation context
+func (i *contextSizeInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	evaluationV1 "buf.build/gen/go/open-feature/flagd/connectrpc/go/flagd/evaluation/v1/evaluationv1connect"
+	evalV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/flagd/evaluation/v1"
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type contextSizeRecorder struct {
+	telemetry.NoopMetricsRecorder
+	sizes []int64
+}
+
+func (r *contextSizeRecorder) EvaluationContextSize(_ context.Context, _ string, size int64) {
+	r.sizes = append(r.sizes, size)
+}
+
+func TestContextSizeInterceptor(t *testing.T) {
+	recorder := &contextSizeRecorder{}
+	handler := &contextCapturingHandler{}
+	_, h := evaluationV1.NewServiceHandler(handler,
+		connect.WithInterceptors(newContextSizeInterceptor(recorder, 1024)))
+	server := httptest.NewServer(h)
+	defer server.Close()
+	client := evaluationV1.NewServiceClient(server.Client(), server.URL)
+
+	request := func(email string) error {
+		s, err := structpb.NewStruct(map[string]any{"email": email})
+		require.NoError(t, err)
+		_, err = client.ResolveString(context.Background(),
+			connect.NewRequest(&evalV1.ResolveStringRequest{FlagKey: "flag", Context: s}))
+		return err
+	}
+
+	require.NoError(t, request("ada@example.com"))
+	require.Len(t, recorder.sizes, 1)
+	assert.Positive(t, recorder.sizes[0])
+
+	err := request(strings.Repeat("a", 1024))
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+	assert.Contains(t, err.Error(), "exceed the maximum of 1024 bytes")
+	assert.Len(t, recorder.sizes, 1, "rejected contexts aren't recorded")
+}
@@ -1,37 +1,237 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"sync"
+	"time"
 
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/logger"
 	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+const (
+	// SelectorHeader restricts the change notifications of an event stream to flags of the given source
+	SelectorHeader = "Flagd-Selector"
+	// FlagKeyPrefixHeader restricts the change notifications of an event stream to flags with the given key prefix
+	FlagKeyPrefixHeader = "Flagd-Flag-Key-Prefix"
+
+	// eventBufferSize is the number of pending notifications per subscription, further notifications are coalesced
+	// with the pending ones until the subscriber catches up
+	eventBufferSize = 16
+	// keepAliveInterval is the interval of keep alive events sent to idle event streams
+	keepAliveInterval = 20 * time.Second
 )
 
 // IEvents is an interface for event subscriptions
 type IEvents interface {
-	Subscribe(id any, notifyChan chan iservice.Notification)
+	Subscribe(id any, filter EventFilter, notifyChan chan iservice.Notification)
 	Unsubscribe(id any)
 	EmitToAll(n iservice.Notification)
+	// Closed is closed once the event streams are to be ended, as the server shuts down
+	Closed() <-chan struct{}
+}
+
+// EventFilter restricts the flags included in configuration change notifications. Empty fields match all flags.
+type EventFilter struct {
+	Selector  string
+	KeyPrefix string
+}
+
+func (f EventFilter) empty() bool {
+	return f.Selector == "" && f.KeyPrefix == ""
+}
+
+// apply returns the notification restricted to the flags matched by the filter. Only configuration changes are
+// filtered, and false is returned if none of the changed flags match.
+func (f EventFilter) apply(n iservice.Notification) (iservice.Notification, bool) {
+	if f.empty() || n.Type != iservice.ConfigurationChange {
+		return n, true
+	}
+
+	changes, ok := n.Data["flags"].(map[string]interface{})
+	if !ok {
+		return n, true
+	}
+
+	matched := map[string]interface{}{}
+	for key, change := range changes {
+		if !strings.HasPrefix(key, f.KeyPrefix) {
+			continue
+		}
+		if f.Selector != "" {
+			details, ok := change.(map[string]interface{})
+			if !ok || details["source"] != f.Selector {
+				continue
+			}
+		}
+		matched[key] = change
+	}
+	if len(matched) == 0 {
+		return n, false
+	}
+
+	return iservice.Notification{
+		Type: n.Type,
+		Data: map[string]interface{}{
+			"flags": matched,
+		},
+	}, true
+}
+
+// eventFilterFromHeaders derives the filter of an event stream from its request headers
+func eventFilterFromHeaders(header http.Header) EventFilter {
+	return EventFilter{
+		Selector:  header.Get(SelectorHeader),
+		KeyPrefix: header.Get(FlagKeyPrefixHeader),
+	}
+}
+
+type subscription struct {
+	filter     EventFilter
+	notifyChan chan iservice.Notification
 }
 
 // eventingConfiguration is a wrapper for notification subscriptions
 type eventingConfiguration struct {
-	mu   *sync.RWMutex
-	subs map[any]chan iservice.Notification
+	logger  *logger.Logger
+	metrics telemetry.IServiceMetricsRecorder
+	mu      *sync.RWMutex
+	subs    map[any]subscription
+	// closed ends the event streams, configuration changes are sent to the open streams instead
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
-func (eventing *eventingConfiguration) Subscribe(id any, notifyChan chan iservice.Notification) {
+func (eventing *eventingConfiguration) Subscribe(id any, filter EventFilter, notifyChan chan iservice.Notification) {
 	eventing.mu.Lock()
 	defer eventing.mu.Unlock()
 
-	eventing.subs[id] = notifyChan
+	eventing.subs[id] = subscription{
+		filter:     filter,
+		notifyChan: notifyChan,
+	}
 }
 
+// EmitToAll sends the notification to all matching subscriptions. Sending never blocks, so that a slow or
+// disconnected subscriber can neither stall store updates nor its own unsubscription. The pending notifications of a
+// full subscription are coalesced with the notification instead, so that the latest notification isn't lost.
 func (eventing *eventingConfiguration) EmitToAll(n iservice.Notification) {
-	eventing.mu.RLock()
-	defer eventing.mu.RUnlock()
+	// notifications are emitted one at a time, so that coalescing the pending notifications doesn't reorder them
+	eventing.mu.Lock()
+	defer eventing.mu.Unlock()
 
-	for _, send := range eventing.subs {
-		send <- n
+	for _, sub := range eventing.subs {
+		filtered, ok := sub.filter.apply(n)
+		if !ok {
+			continue
+		}
+		if !trySend(sub.notifyChan, filtered) {
+			eventing.overflow(sub.notifyChan, filtered)
+		}
+	}
+}
+
+// overflow sends the notification to a full subscription, coalesced with the notifications pending for it. The
+// oldest of the coalesced notifications are lost if they still exceed the buffer of the subscription.
+func (eventing *eventingConfiguration) overflow(notifyChan chan iservice.Notification, n iservice.Notification) {
+	var pending []iservice.Notification
+	for len(notifyChan) > 0 {
+		select {
+		case p := <-notifyChan:
+			pending = append(pending, p)
+		default:
+			// the subscriber caught up meanwhile
+		}
+	}
+
+	coalesced, folded := coalesceNotifications(append(pending, n))
+	for _, f := range folded {
+		eventing.recordOverflow(f, telemetry.EventCoalesced)
+	}
+	var lost []iservice.Notification
+	if excess := len(coalesced) - cap(notifyChan); excess > 0 {
+		lost = append(lost, coalesced[:excess]...)
+		coalesced = coalesced[excess:]
+	}
+	for _, c := range coalesced {
+		if !trySend(notifyChan, c) {
+			lost = append(lost, c)
+		}
+	}
+	for _, l := range lost {
+		eventing.recordOverflow(l, telemetry.EventLost)
+	}
+
+	if eventing.logger != nil {
+		eventing.logger.Warn(fmt.Sprintf("event buffer of a subscription is full, coalesced %d and dropped %d of the "+
+			"%d pending events", len(folded), len(lost), len(pending)+1))
+	}
+}
+
+// trySend sends the notification if the subscription accepts it without blocking
+func trySend(notifyChan chan iservice.Notification, n iservice.Notification) bool {
+	select {
+	case notifyChan <- n:
+		return true
+	default:
+		return false
+	}
+}
+
+func (eventing *eventingConfiguration) recordOverflow(n iservice.Notification, outcome string) {
+	if eventing.metrics != nil {
+		eventing.metrics.EventOverflow(context.Background(), string(n.Type), outcome)
+	}
+}
+
+// coalesceNotifications coalesces consecutive notifications of the same type, returning the coalesced notifications
+// in order and the notifications folded into a later one. Configuration changes are merged, the later change of a flag
+// taking precedence, while other notifications are superseded by the later one.
+func coalesceNotifications(
+	notifications []iservice.Notification,
+) (coalesced []iservice.Notification, folded []iservice.Notification) {
+	for _, n := range notifications {
+		last := len(coalesced) - 1
+		if last < 0 || coalesced[last].Type != n.Type {
+			coalesced = append(coalesced, n)
+			continue
+		}
+		folded = append(folded, coalesced[last])
+		coalesced[last] = mergeNotifications(coalesced[last], n)
+	}
+	return coalesced, folded
+}
+
+// mergeNotifications merges the flags changed by two configuration changes, other notifications are superseded by
+// the later one
+func mergeNotifications(earlier, later iservice.Notification) iservice.Notification {
+	earlierFlags, ok := earlier.Data["flags"].(map[string]interface{})
+	if !ok || later.Type != iservice.ConfigurationChange {
+		return later
+	}
+	laterFlags, ok := later.Data["flags"].(map[string]interface{})
+	if !ok {
+		return later
+	}
+
+	flags := make(map[string]interface{}, len(earlierFlags)+len(laterFlags))
+	for key, change := range earlierFlags {
+		flags[key] = change
+	}
+	for key, change := range laterFlags {
+		flags[key] = change
+	}
+	return iservice.Notification{
+		Type: later.Type,
+		Data: map[string]interface{}{
+			"flags": flags,
+		},
 	}
 }
 
@@ -41,3 +241,76 @@ func (eventing *eventingConfiguration) Unsubscribe(id any) {
 
 	delete(eventing.subs, id)
 }
+
+// Closed returns a channel closed once the event streams are ended
+func (eventing *eventingConfiguration) Closed() <-chan struct{} {
+	return eventing.closed
+}
+
+// Close ends the event streams, after the notifications pending for them were sent
+func (eventing *eventingConfiguration) Close() {
+	eventing.closeOnce.Do(func() {
+		close(eventing.closed)
+	})
+}
+
+// Subscribers returns the number of active subscriptions
+func (eventing *eventingConfiguration) Subscribers() int64 {
+	eventing.mu.RLock()
+	defer eventing.mu.RUnlock()
+
+	return int64(len(eventing.subs))
+}
+
+// streamEvents subscribes to notifications matching the filter and sends them until the context is done or the event
+// streams are closed. Configuration changes, including reloads, are sent to the open stream, which is only ended by
+// a shutdown. The subscription is always removed before returning.
+func streamEvents(
+	ctx context.Context,
+	log *logger.Logger,
+	events IEvents,
+	streams *iservice.StreamLimiter,
+	id any,
+	filter EventFilter,
+	send func(n iservice.Notification) error,
+) error {
+	release, ok := streams.Acquire(ctx)
+	if !ok {
+		return connect.NewError(connect.CodeResourceExhausted, errors.New("too many concurrent event streams"))
+	}
+	defer release()
+
+	notifyChan := make(chan iservice.Notification, eventBufferSize)
+	events.Subscribe(id, filter, notifyChan)
+	defer events.Unsubscribe(id)
+
+	if err := send(iservice.Notification{Type: iservice.ProviderReady}); err != nil {
+		log.Error(err.Error())
+	}
+	for {
+		select {
+		case <-time.After(keepAliveInterval):
+			if err := send(iservice.Notification{Type: iservice.KeepAlive}); err != nil {
+				log.Error(err.Error())
+			}
+		case notification := <-notifyChan:
+			if err := send(notification); err != nil {
+				log.Error(err.Error())
+			}
+		case <-events.Closed():
+			// deliver the pending notifications, such as the shutdown event, before ending the stream
+			for {
+				select {
+				case notification := <-notifyChan:
+					if err := send(notification); err != nil {
+						log.Error(err.Error())
+					}
+				default:
+					return nil
+				}
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
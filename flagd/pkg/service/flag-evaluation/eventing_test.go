@@ -1,17 +1,24 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"sync"
 	"testing"
+	"time"
 
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/logger"
 	iservice "github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/stretchr/testify/require"
 )
 
 func TestSubscribe(t *testing.T) {
 	// given
 	eventing := &eventingConfiguration{
-		subs: make(map[interface{}]chan iservice.Notification),
+		subs: make(map[any]subscription),
 		mu:   &sync.RWMutex{},
 	}
 
@@ -22,18 +29,20 @@ func TestSubscribe(t *testing.T) {
 	chanB := make(chan iservice.Notification, 1)
 
 	// when
-	eventing.Subscribe(idA, chanA)
-	eventing.Subscribe(idB, chanB)
+	eventing.Subscribe(idA, EventFilter{}, chanA)
+	eventing.Subscribe(idB, EventFilter{KeyPrefix: "b"}, chanB)
 
 	// then
-	require.Equal(t, chanA, eventing.subs[idA], "incorrect subscription association")
-	require.Equal(t, chanB, eventing.subs[idB], "incorrect subscription association")
+	require.Equal(t, chanA, eventing.subs[idA].notifyChan, "incorrect subscription association")
+	require.Equal(t, chanB, eventing.subs[idB].notifyChan, "incorrect subscription association")
+	require.Equal(t, EventFilter{KeyPrefix: "b"}, eventing.subs[idB].filter, "incorrect subscription filter")
+	require.Equal(t, int64(2), eventing.Subscribers(), "incorrect subscription count")
 }
 
 func TestUnsubscribe(t *testing.T) {
 	// given
 	eventing := &eventingConfiguration{
-		subs: make(map[interface{}]chan iservice.Notification),
+		subs: make(map[any]subscription),
 		mu:   &sync.RWMutex{},
 	}
 
@@ -43,13 +52,249 @@ func TestUnsubscribe(t *testing.T) {
 	chanB := make(chan iservice.Notification, 1)
 
 	// when
-	eventing.Subscribe(idA, chanA)
-	eventing.Subscribe(idB, chanB)
+	eventing.Subscribe(idA, EventFilter{}, chanA)
+	eventing.Subscribe(idB, EventFilter{}, chanB)
 
 	eventing.Unsubscribe(idA)
 
 	// then
-	require.Empty(t, eventing.subs[idA],
-		"expected subscription cleared, but value present: %v", eventing.subs[idA])
-	require.Equal(t, chanB, eventing.subs[idB], "incorrect subscription association")
+	require.NotContains(t, eventing.subs, idA, "expected subscription cleared")
+	require.Equal(t, chanB, eventing.subs[idB].notifyChan, "incorrect subscription association")
+	require.Equal(t, int64(1), eventing.Subscribers(), "incorrect subscription count")
+}
+
+func TestEmitToAll_Filter(t *testing.T) {
+	change := iservice.Notification{
+		Type: iservice.ConfigurationChange,
+		Data: map[string]interface{}{
+			"flags": map[string]interface{}{
+				"checkout-banner": map[string]interface{}{"type": "write", "source": "file:a.json"},
+				"checkout-color":  map[string]interface{}{"type": "update", "source": "file:b.json"},
+				"search-ranking":  map[string]interface{}{"type": "delete", "source": "file:a.json"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		filter   EventFilter
+		expected []string
+	}{
+		"no filter": {
+			expected: []string{"checkout-banner", "checkout-color", "search-ranking"},
+		},
+		"key prefix": {
+			filter:   EventFilter{KeyPrefix: "checkout-"},
+			expected: []string{"checkout-banner", "checkout-color"},
+		},
+		"selector": {
+			filter:   EventFilter{Selector: "file:a.json"},
+			expected: []string{"checkout-banner", "search-ranking"},
+		},
+		"key prefix and selector": {
+			filter:   EventFilter{Selector: "file:a.json", KeyPrefix: "checkout-"},
+			expected: []string{"checkout-banner"},
+		},
+		"no match": {
+			filter: EventFilter{KeyPrefix: "billing-"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			eventing := &eventingConfiguration{
+				subs: make(map[any]subscription),
+				mu:   &sync.RWMutex{},
+			}
+			notifyChan := make(chan iservice.Notification, 1)
+			eventing.Subscribe("id", tt.filter, notifyChan)
+
+			eventing.EmitToAll(change)
+
+			if len(tt.expected) == 0 {
+				require.Empty(t, notifyChan, "expected no notification")
+				return
+			}
+			n := <-notifyChan
+			require.Equal(t, iservice.ConfigurationChange, n.Type)
+			flags, ok := n.Data["flags"].(map[string]interface{})
+			require.True(t, ok, "expected changed flags")
+			keys := make([]string, 0, len(flags))
+			for key := range flags {
+				keys = append(keys, key)
+			}
+			require.ElementsMatch(t, tt.expected, keys)
+		})
+	}
+
+	// the filtered notifications must not alter the shared notification
+	require.Len(t, change.Data["flags"], 3)
+}
+
+func TestEmitToAll_FilterIgnoresOtherEvents(t *testing.T) {
+	eventing := &eventingConfiguration{
+		subs: make(map[any]subscription),
+		mu:   &sync.RWMutex{},
+	}
+	notifyChan := make(chan iservice.Notification, 1)
+	eventing.Subscribe("id", EventFilter{KeyPrefix: "checkout-"}, notifyChan)
+
+	eventing.EmitToAll(iservice.Notification{Type: iservice.Shutdown, Data: map[string]interface{}{}})
+
+	n := <-notifyChan
+	require.Equal(t, iservice.Shutdown, n.Type)
+}
+
+func TestEmitToAll_DoesNotBlock(t *testing.T) {
+	eventing := &eventingConfiguration{
+		logger: logger.NewLogger(nil, false),
+		subs:   make(map[any]subscription),
+		mu:     &sync.RWMutex{},
+	}
+	// a subscriber which never reads its notifications
+	eventing.Subscribe("slow", EventFilter{}, make(chan iservice.Notification))
+
+	done := make(chan struct{})
+	go func() {
+		eventing.EmitToAll(iservice.Notification{Type: iservice.ConfigurationChange})
+		eventing.Unsubscribe("slow")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("emit blocked")
+	}
+}
+
+func TestEmitToAll_CoalescesFullBuffer(t *testing.T) {
+	recorder := &overflowRecorder{}
+	eventing := &eventingConfiguration{
+		logger:  logger.NewLogger(nil, false),
+		metrics: recorder,
+		subs:    make(map[any]subscription),
+		mu:      &sync.RWMutex{},
+	}
+	notifyChan := make(chan iservice.Notification, 2)
+	eventing.Subscribe("slow", EventFilter{}, notifyChan)
+	change := func(flags map[string]interface{}) iservice.Notification {
+		return iservice.Notification{Type: iservice.ConfigurationChange, Data: map[string]interface{}{"flags": flags}}
+	}
+
+	eventing.EmitToAll(change(map[string]interface{}{"a": "created"}))
+	eventing.EmitToAll(change(map[string]interface{}{"b": "created"}))
+	// the buffer is full, the pending changes are merged with the latest one
+	eventing.EmitToAll(change(map[string]interface{}{"a": "updated"}))
+	require.Len(t, notifyChan, 1)
+	require.Equal(t, change(map[string]interface{}{"a": "updated", "b": "created"}), <-notifyChan)
+	require.Equal(t, []string{"configuration_change/coalesced", "configuration_change/coalesced"}, recorder.records)
+
+	eventing.EmitToAll(change(map[string]interface{}{"c": "created"}))
+	eventing.EmitToAll(iservice.Notification{Type: iservice.Shutdown, Data: map[string]interface{}{}})
+	// notifications of different types aren't merged, the oldest exceeding the buffer is lost
+	eventing.EmitToAll(change(map[string]interface{}{"d": "created"}))
+	require.Equal(t, iservice.Shutdown, (<-notifyChan).Type)
+	require.Equal(t, change(map[string]interface{}{"d": "created"}), <-notifyChan)
+	require.Equal(t, "configuration_change/lost", recorder.records[len(recorder.records)-1])
+}
+
+type overflowRecorder struct {
+	telemetry.NoopMetricsRecorder
+	records []string
+}
+
+func (r *overflowRecorder) EventOverflow(_ context.Context, eventType, outcome string) {
+	r.records = append(r.records, eventType+"/"+outcome)
+}
+
+func TestStreamEvents(t *testing.T) {
+	eventing := &eventingConfiguration{
+		subs: make(map[any]subscription),
+		mu:   &sync.RWMutex{},
+	}
+	streams := iservice.NewStreamLimiter(iservice.EventStream, 1, nil)
+	sent := make(chan iservice.Notification, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- streamEvents(ctx, logger.NewLogger(nil, false), eventing, streams, "id", EventFilter{},
+			func(n iservice.Notification) error {
+				sent <- n
+				if n.Type == iservice.ConfigurationChange {
+					return errors.New("client gone")
+				}
+				return nil
+			})
+	}()
+
+	require.Equal(t, iservice.ProviderReady, (<-sent).Type)
+	eventing.EmitToAll(iservice.Notification{Type: iservice.ConfigurationChange})
+	require.Equal(t, iservice.ConfigurationChange, (<-sent).Type)
+
+	// streams exceeding the limit are rejected
+	err := streamEvents(context.Background(), logger.NewLogger(nil, false), eventing, streams, "other", EventFilter{},
+		func(_ iservice.Notification) error {
+			t.Fatal("rejected stream must not send events")
+			return nil
+		})
+	require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err))
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end")
+	}
+	require.Empty(t, eventing.subs, "expected subscription cleared")
+	require.Zero(t, streams.Open(), "expected stream released")
+}
+
+func TestStreamEventsOutlivesConfigurationChanges(t *testing.T) {
+	eventing := &eventingConfiguration{
+		subs:   make(map[any]subscription),
+		mu:     &sync.RWMutex{},
+		closed: make(chan struct{}),
+	}
+	streams := iservice.NewStreamLimiter(iservice.EventStream, 0, nil)
+	sent := make(chan iservice.Notification, eventBufferSize)
+
+	done := make(chan error)
+	go func() {
+		done <- streamEvents(context.Background(), logger.NewLogger(nil, false), eventing, streams, "id",
+			EventFilter{}, func(n iservice.Notification) error {
+				sent <- n
+				return nil
+			})
+	}()
+	require.Equal(t, iservice.ProviderReady, (<-sent).Type)
+
+	// reloads of the configuration are notified to the open stream
+	for i := 0; i < 3; i++ {
+		eventing.EmitToAll(iservice.Notification{Type: iservice.ConfigurationChange})
+		require.Equal(t, iservice.ConfigurationChange, (<-sent).Type)
+	}
+	require.Len(t, eventing.subs, 1, "expected the subscription kept")
+
+	eventing.EmitToAll(iservice.Notification{Type: iservice.Shutdown})
+	eventing.Close()
+	eventing.Close()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end on shutdown")
+	}
+	require.Equal(t, iservice.Shutdown, (<-sent).Type, "expected the shutdown event sent before ending")
+	require.Empty(t, eventing.subs, "expected subscription cleared")
+}
+
+func TestEventFilterFromHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set(SelectorHeader, "file:a.json")
+	header.Set(FlagKeyPrefixHeader, "checkout-")
+
+	require.Equal(t, EventFilter{Selector: "file:a.json", KeyPrefix: "checkout-"}, eventFilterFromHeaders(header))
+	require.Equal(t, EventFilter{}, eventFilterFromHeaders(http.Header{}))
 }
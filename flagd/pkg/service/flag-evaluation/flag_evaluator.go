@@ -2,8 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"time"
+	"net/http"
 
 	schemaV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/schema/v1"
 	"connectrpc.com/connect"
@@ -12,7 +13,9 @@ import (
 	"github.com/open-feature/flagd/core/pkg/model"
 	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/telemetry"
-	"github.com/rs/xid"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/auth"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/correlation"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/peer"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -21,6 +24,9 @@ import (
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// ValueTypeHeader restricts the flags of a bulk evaluation to the given value type, e.g. boolean
+const ValueTypeHeader = "Flagd-Value-Type"
+
 type resolverSignature[T constraints] func(context context.Context, reqID, flagKey string, ctx map[string]any) (
 	T, string, string, map[string]interface{}, error)
 
@@ -33,6 +39,7 @@ type OldFlagEvaluationService struct {
 	eventingConfiguration IEvents
 	flagEvalTracer        trace.Tracer
 	contextValues         map[string]any
+	streams               *service.StreamLimiter
 }
 
 // NewOldFlagEvaluationService creates a OldFlagEvaluationService with provided parameters
@@ -55,6 +62,7 @@ func NewOldFlagEvaluationService(
 	if metricsRecorder != nil {
 		svc.metrics = metricsRecorder
 	}
+	svc.streams = service.NewStreamLimiter(service.EventStream, 0, svc.metrics)
 
 	return svc
 }
@@ -64,7 +72,7 @@ func (s *OldFlagEvaluationService) ResolveAll(
 	ctx context.Context,
 	req *connect.Request[schemaV1.ResolveAllRequest],
 ) (*connect.Response[schemaV1.ResolveAllResponse], error) {
-	reqID := xid.New().String()
+	reqID := correlation.NewRequestID(ctx, s.logger)
 	defer s.logger.ClearFields(reqID)
 	sCtx, span := s.flagEvalTracer.Start(ctx, "resolveAll", trace.WithSpanKind(trace.SpanKindServer))
 	defer span.End()
@@ -72,16 +80,23 @@ func (s *OldFlagEvaluationService) ResolveAll(
 		Flags: make(map[string]*schemaV1.AnyFlag),
 	}
 
-	values, err := s.eval.ResolveAllValues(sCtx, reqID, mergeContexts(req.Msg.GetContext().AsMap(), s.contextValues))
+	evalCtx := service.MergeContexts(
+		peer.EvaluationContext(ctx), req.Msg.GetContext().AsMap(), auth.ClaimsFromContext(ctx), s.contextValues)
+	sCtx, err := withValueTypeFromHeaders(sCtx, req.Header())
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.eval.ResolveAllValues(sCtx, reqID, evalCtx)
 	if err != nil {
 		s.logger.WarnWithID(reqID, fmt.Sprintf("error resolving all flags: %v", err))
 		return nil, fmt.Errorf("error resolving flags. Tracking ID: %s", reqID)
 	}
 
 	span.SetAttributes(attribute.Int("feature_flag.count", len(values)))
+	surface := apiSurface(req.Peer())
 	for _, value := range values {
 		// register the impression and reason for each flag evaluated
-		s.metrics.RecordEvaluation(sCtx, value.Error, value.Reason, value.Variant, value.FlagKey)
+		s.metrics.RecordEvaluation(sCtx, value.Error, value.Reason, value.Variant, value.FlagKey, surface)
 
 		switch v := value.Value.(type) {
 		case bool:
@@ -108,6 +123,19 @@ func (s *OldFlagEvaluationService) ResolveAll(
 					DoubleValue: v,
 				},
 			}
+		case json.Number:
+			val, err := v.Float64()
+			if err != nil {
+				s.logger.ErrorWithID(reqID, fmt.Sprintf("number response construction: %v", err))
+				continue
+			}
+			res.Flags[value.FlagKey] = &schemaV1.AnyFlag{
+				Reason:  value.Reason,
+				Variant: value.Variant,
+				Value: &schemaV1.AnyFlag_DoubleValue{
+					DoubleValue: val,
+				},
+			}
 		case map[string]any:
 			val, err := structpb.NewStruct(v)
 			if err != nil {
@@ -132,23 +160,8 @@ func (s *OldFlagEvaluationService) EventStream(
 	req *connect.Request[schemaV1.EventStreamRequest],
 	stream *connect.ServerStream[schemaV1.EventStreamResponse],
 ) error {
-	requestNotificationChan := make(chan service.Notification, 1)
-	s.eventingConfiguration.Subscribe(req, requestNotificationChan)
-	defer s.eventingConfiguration.Unsubscribe(req)
-
-	requestNotificationChan <- service.Notification{
-		Type: service.ProviderReady,
-	}
-	for {
-		select {
-		case <-time.After(20 * time.Second):
-			err := stream.Send(&schemaV1.EventStreamResponse{
-				Type: string(service.KeepAlive),
-			})
-			if err != nil {
-				s.logger.Error(err.Error())
-			}
-		case notification := <-requestNotificationChan:
+	return streamEvents(ctx, s.logger, s.eventingConfiguration, s.streams, req, eventFilterFromHeaders(req.Header()),
+		func(notification service.Notification) error {
 			d, err := structpb.NewStruct(notification.Data)
 			if err != nil {
 				s.logger.Error(err.Error())
@@ -158,12 +171,10 @@ func (s *OldFlagEvaluationService) EventStream(
 				Data: d,
 			})
 			if err != nil {
-				s.logger.Error(err.Error())
+				return fmt.Errorf("error sending %s event: %w", notification.Type, err)
 			}
-		case <-ctx.Done():
 			return nil
-		}
-	}
+		})
 }
 
 //nolint:staticcheck
@@ -183,6 +194,7 @@ func (s *OldFlagEvaluationService) ResolveBoolean(
 		req.Msg.GetContext(),
 		&booleanResponse{schemaV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -210,6 +222,7 @@ func (s *OldFlagEvaluationService) ResolveString(
 		req.Msg.GetContext(),
 		&stringResponse{schemaV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -237,6 +250,7 @@ func (s *OldFlagEvaluationService) ResolveInt(
 		req.Msg.GetContext(),
 		&intResponse{schemaV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -264,6 +278,7 @@ func (s *OldFlagEvaluationService) ResolveFloat(
 		req.Msg.GetContext(),
 		&floatResponse{schemaV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -291,6 +306,7 @@ func (s *OldFlagEvaluationService) ResolveObject(
 		req.Msg.GetContext(),
 		&objectResponse{schemaV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -301,28 +317,16 @@ func (s *OldFlagEvaluationService) ResolveObject(
 	return res, err
 }
 
-// mergeContexts combines values from the request context with the values from the config --context-values flag.
-// Request context values have a higher priority.
-func mergeContexts(reqCtx, configFlagsCtx map[string]any) map[string]any {
-	merged := make(map[string]any)
-	for k, v := range reqCtx {
-		merged[k] = v
-	}
-	for k, v := range configFlagsCtx {
-		merged[k] = v
-	}
-	return merged
-}
-
 // resolve is a generic flag resolver
 func resolve[T constraints](ctx context.Context, logger *logger.Logger, resolver resolverSignature[T], flagKey string,
-	evaluationContext *structpb.Struct, resp response[T], metrics telemetry.IMetricsRecorder,
+	evaluationContext *structpb.Struct, resp response[T], metrics telemetry.IMetricsRecorder, surface string,
 	configContextValues map[string]any,
 ) error {
-	reqID := xid.New().String()
+	reqID := correlation.NewRequestID(ctx, logger)
 	defer logger.ClearFields(reqID)
 
-	mergedContext := mergeContexts(evaluationContext.AsMap(), configContextValues)
+	mergedContext := service.MergeContexts(
+		peer.EvaluationContext(ctx), evaluationContext.AsMap(), auth.ClaimsFromContext(ctx), configContextValues)
 	logger.WriteFields(
 		reqID,
 		zap.String("flag-key", flagKey),
@@ -338,7 +342,7 @@ func resolve[T constraints](ctx context.Context, logger *logger.Logger, resolver
 	}
 
 	if metrics != nil {
-		metrics.RecordEvaluation(ctx, evalErr, reason, variant, flagKey)
+		metrics.RecordEvaluation(ctx, evalErr, reason, variant, flagKey, surface)
 	}
 
 	spanFromContext := trace.SpanFromContext(ctx)
@@ -352,6 +356,27 @@ func resolve[T constraints](ctx context.Context, logger *logger.Logger, resolver
 	return evalErrFormatted
 }
 
+// withValueTypeFromHeaders scopes the bulk evaluation of a request to the value type of its request headers, if any
+func withValueTypeFromHeaders(ctx context.Context, header http.Header) (context.Context, error) {
+	valueType := header.Get(ValueTypeHeader)
+	if valueType == "" {
+		return ctx, nil
+	}
+	if !evaluator.IsValueType(valueType) {
+		return ctx, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("unknown value type '%s'", valueType))
+	}
+	return evaluator.WithValueType(ctx, valueType), nil
+}
+
+// apiSurface returns the API surface of an evaluation request, requests of the Connect protocol are plain HTTP
+// requests of the REST surface
+func apiSurface(peer connect.Peer) string {
+	if peer.Protocol == connect.ProtocolConnect {
+		return telemetry.APISurfaceREST
+	}
+	return telemetry.APISurfaceGRPC
+}
+
 func formatContextKeys(context map[string]any) []string {
 	res := []string{}
 	for k := range context {
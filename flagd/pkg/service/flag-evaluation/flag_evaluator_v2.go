@@ -2,8 +2,8 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"time"
 
 	evalV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/flagd/evaluation/v1"
 	"connectrpc.com/connect"
@@ -11,7 +11,9 @@ import (
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/telemetry"
-	"github.com/rs/xid"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/auth"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/correlation"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/peer"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -26,6 +28,7 @@ type FlagEvaluationService struct {
 	eventingConfiguration IEvents
 	flagEvalTracer        trace.Tracer
 	contextValues         map[string]any
+	streams               *service.StreamLimiter
 }
 
 // NewFlagEvaluationService creates a FlagEvaluationService with provided parameters
@@ -47,6 +50,7 @@ func NewFlagEvaluationService(log *logger.Logger,
 	if metricsRecorder != nil {
 		svc.metrics = metricsRecorder
 	}
+	svc.streams = service.NewStreamLimiter(service.EventStream, 0, svc.metrics)
 
 	return svc
 }
@@ -56,7 +60,7 @@ func (s *FlagEvaluationService) ResolveAll(
 	ctx context.Context,
 	req *connect.Request[evalV1.ResolveAllRequest],
 ) (*connect.Response[evalV1.ResolveAllResponse], error) {
-	reqID := xid.New().String()
+	reqID := correlation.NewRequestID(ctx, s.logger)
 	defer s.logger.ClearFields(reqID)
 
 	sCtx, span := s.flagEvalTracer.Start(ctx, "resolveAll", trace.WithSpanKind(trace.SpanKindServer))
@@ -66,16 +70,28 @@ func (s *FlagEvaluationService) ResolveAll(
 		Flags: make(map[string]*evalV1.AnyFlag),
 	}
 
-	values, err := s.eval.ResolveAllValues(sCtx, reqID, mergeContexts(req.Msg.GetContext().AsMap(), s.contextValues))
+	evalCtx := service.MergeContexts(
+		peer.EvaluationContext(ctx), req.Msg.GetContext().AsMap(), auth.ClaimsFromContext(ctx), s.contextValues)
+	sCtx, err := withValueTypeFromHeaders(sCtx, req.Header())
+	if err != nil {
+		return nil, err
+	}
+	values, version, err := s.eval.ResolveAllValuesWithVersion(sCtx, reqID, evalCtx)
 	if err != nil {
 		s.logger.WarnWithID(reqID, fmt.Sprintf("error resolving all flags: %v", err))
 		return nil, fmt.Errorf("error resolving flags. Tracking ID: %s", reqID)
 	}
+	if version != "" {
+		res.Metadata = &structpb.Struct{Fields: map[string]*structpb.Value{
+			evaluator.ConfigVersionMetadataKey: structpb.NewStringValue(version),
+		}}
+	}
 
 	span.SetAttributes(attribute.Int("feature_flag.count", len(values)))
+	surface := apiSurface(req.Peer())
 	for _, value := range values {
 		// register the impression and reason for each flag evaluated
-		s.metrics.RecordEvaluation(sCtx, value.Error, value.Reason, value.Variant, value.FlagKey)
+		s.metrics.RecordEvaluation(sCtx, value.Error, value.Reason, value.Variant, value.FlagKey, surface)
 		switch v := value.Value.(type) {
 		case bool:
 			res.Flags[value.FlagKey] = &evalV1.AnyFlag{
@@ -101,6 +117,19 @@ func (s *FlagEvaluationService) ResolveAll(
 					DoubleValue: v,
 				},
 			}
+		case json.Number:
+			val, err := v.Float64()
+			if err != nil {
+				s.logger.ErrorWithID(reqID, fmt.Sprintf("number response construction: %v", err))
+				continue
+			}
+			res.Flags[value.FlagKey] = &evalV1.AnyFlag{
+				Reason:  value.Reason,
+				Variant: value.Variant,
+				Value: &evalV1.AnyFlag_DoubleValue{
+					DoubleValue: val,
+				},
+			}
 		case map[string]any:
 			val, err := structpb.NewStruct(v)
 			if err != nil {
@@ -125,23 +154,8 @@ func (s *FlagEvaluationService) EventStream(
 	req *connect.Request[evalV1.EventStreamRequest],
 	stream *connect.ServerStream[evalV1.EventStreamResponse],
 ) error {
-	requestNotificationChan := make(chan service.Notification, 1)
-	s.eventingConfiguration.Subscribe(req, requestNotificationChan)
-	defer s.eventingConfiguration.Unsubscribe(req)
-
-	requestNotificationChan <- service.Notification{
-		Type: service.ProviderReady,
-	}
-	for {
-		select {
-		case <-time.After(20 * time.Second):
-			err := stream.Send(&evalV1.EventStreamResponse{
-				Type: string(service.KeepAlive),
-			})
-			if err != nil {
-				s.logger.Error(err.Error())
-			}
-		case notification := <-requestNotificationChan:
+	return streamEvents(ctx, s.logger, s.eventingConfiguration, s.streams, req, eventFilterFromHeaders(req.Header()),
+		func(notification service.Notification) error {
 			d, err := structpb.NewStruct(notification.Data)
 			if err != nil {
 				s.logger.Error(err.Error())
@@ -151,12 +165,10 @@ func (s *FlagEvaluationService) EventStream(
 				Data: d,
 			})
 			if err != nil {
-				s.logger.Error(err.Error())
+				return fmt.Errorf("error sending %s event: %w", notification.Type, err)
 			}
-		case <-ctx.Done():
 			return nil
-		}
-	}
+		})
 }
 
 func (s *FlagEvaluationService) ResolveBoolean(
@@ -175,6 +187,7 @@ func (s *FlagEvaluationService) ResolveBoolean(
 		req.Msg.GetContext(),
 		&booleanResponse{evalV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -201,6 +214,7 @@ func (s *FlagEvaluationService) ResolveString(
 		req.Msg.GetContext(),
 		&stringResponse{evalV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -227,6 +241,7 @@ func (s *FlagEvaluationService) ResolveInt(
 		req.Msg.GetContext(),
 		&intResponse{evalV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -253,6 +268,7 @@ func (s *FlagEvaluationService) ResolveFloat(
 		req.Msg.GetContext(),
 		&floatResponse{evalV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -279,6 +295,7 @@ func (s *FlagEvaluationService) ResolveObject(
 		req.Msg.GetContext(),
 		&objectResponse{evalV1Resp: res},
 		s.metrics,
+		apiSurface(req.Peer()),
 		s.contextValues,
 	)
 	if err != nil {
@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"errors"
-	"reflect"
 	"testing"
 
 	evalV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/flagd/evaluation/v1"
@@ -12,6 +11,8 @@ import (
 	mock "github.com/open-feature/flagd/core/pkg/evaluator/mock"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.uber.org/mock/gomock"
@@ -89,8 +90,8 @@ func TestConnectServiceV2_ResolveAll(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			// given
 			eval := mock.NewMockIEvaluator(ctrl)
-			eval.EXPECT().ResolveAllValues(gomock.Any(), gomock.Any(), gomock.Any()).Return(
-				tt.evalRes, tt.evalErr,
+			eval.EXPECT().ResolveAllValuesWithVersion(gomock.Any(), gomock.Any(), gomock.Any()).Return(
+				tt.evalRes, "", tt.evalErr,
 			).AnyTimes()
 
 			metrics, exp := getMetricReader()
@@ -130,6 +131,74 @@ func TestConnectServiceV2_ResolveAll(t *testing.T) {
 	}
 }
 
+func TestConnectServiceV2_ResolveAllValueType(t *testing.T) {
+	eval := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := eval.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"enabled": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"},
+			"color": {"state": "ENABLED", "variants": {"red": "red"}, "defaultVariant": "red"}
+		}
+	}`})
+	require.NoError(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), eval, &eventingConfiguration{}, nil, nil)
+
+	tests := map[string]struct {
+		valueType string
+		wantKeys  []string
+		wantCode  connect.Code
+	}{
+		"scoped to booleans": {
+			valueType: "boolean",
+			wantKeys:  []string{"enabled"},
+		},
+		"unscoped": {
+			wantKeys: []string{"color", "enabled"},
+		},
+		"unknown value type": {
+			valueType: "number",
+			wantCode:  connect.CodeInvalidArgument,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := connect.NewRequest(&evalV1.ResolveAllRequest{})
+			if tt.valueType != "" {
+				req.Header().Set(ValueTypeHeader, tt.valueType)
+			}
+
+			got, err := s.ResolveAll(context.Background(), req)
+			if tt.wantCode != 0 {
+				require.Equal(t, tt.wantCode, connect.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			keys := make([]string, 0, len(got.Msg.Flags))
+			for key := range got.Msg.Flags {
+				keys = append(keys, key)
+			}
+			require.ElementsMatch(t, tt.wantKeys, keys)
+		})
+	}
+}
+
+func TestConnectServiceV2_ResolveAllConfigVersion(t *testing.T) {
+	flags := store.NewFlags()
+	eval := evaluator.NewJSON(logger.NewLogger(nil, false), flags)
+	_, _, err := eval.SetState(sync.DataSync{FlagData: `{
+		"flags": {
+			"enabled": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"}
+		}
+	}`})
+	require.NoError(t, err)
+	s := NewFlagEvaluationService(logger.NewLogger(nil, false), eval, &eventingConfiguration{}, nil, nil)
+
+	got, err := s.ResolveAll(context.Background(), connect.NewRequest(&evalV1.ResolveAllRequest{}))
+	require.NoError(t, err)
+	require.Equal(t, flags.Version(),
+		got.Msg.GetMetadata().GetFields()[evaluator.ConfigVersionMetadataKey].GetStringValue())
+}
+
 type resolveBooleanArgsV2 struct {
 	evalFields   resolveBooleanEvalFieldsV2
 	functionArgs resolveBooleanFunctionArgsV2
@@ -966,35 +1035,3 @@ func TestFlag_EvaluationV2_ErrorCodes(t *testing.T) {
 		}
 	}
 }
-
-func Test_mergeContexts(t *testing.T) {
-	type args struct {
-		clientContext, configContext map[string]any
-	}
-
-	tests := []struct {
-		name string
-		args args
-		want map[string]any
-	}{
-		{
-			name: "merge contexts",
-			args: args{
-				clientContext: map[string]any{"k1": "v1", "k2": "v2"},
-				configContext: map[string]any{"k2": "v22", "k3": "v3"},
-			},
-			// static context should "win"
-			want: map[string]any{"k1": "v1", "k2": "v22", "k3": "v3"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := mergeContexts(tt.args.clientContext, tt.args.configContext)
-
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("\ngot:  %+v\nwant: %+v", got, tt.want)
-			}
-		})
-	}
-}
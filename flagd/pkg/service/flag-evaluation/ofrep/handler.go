@@ -2,46 +2,83 @@ package ofrep
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/service/ofrep"
-	"github.com/rs/xid"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/auth"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/correlation"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/peer"
 )
 
 const (
 	key              = "key"
 	singleEvaluation = "/ofrep/v1/evaluate/flags/{key}"
 	bulkEvaluation   = "/ofrep/v1/evaluate/{path:flags\\/|flags}"
+	configuration    = "/ofrep/v1/configuration"
+	// batchEvaluation evaluates a single flag against multiple contexts, an extension of OFREP
+	batchEvaluation = "/ofrep/v1/evaluate/flags/{key}/batch"
+	// valueTypeParam restricts the flags of a bulk evaluation to the given value type, e.g. boolean
+	valueTypeParam = "type"
 )
 
 type handler struct {
 	Logger        *logger.Logger
 	evaluator     evaluator.IEvaluator
 	contextValues map[string]any
+	metrics       telemetry.IMetricsRecorder
+	configuration ofrep.ConfigurationResponse
+	// maxBatchSize is the maximum number of contexts of a batch evaluation, zero doesn't limit the contexts
+	maxBatchSize int
+	// maxContextBytes is the maximum size of an evaluation context in bytes, zero doesn't limit the size
+	maxContextBytes int
 }
 
-func NewOfrepHandler(logger *logger.Logger, evaluator evaluator.IEvaluator, contextValues map[string]any) http.Handler {
+// NewOfrepHandler returns the handler of the OFREP endpoints, configured by the evaluation settings of the service
+// configuration. The server settings of the configuration, e.g. the port, don't apply to the handler.
+func NewOfrepHandler(evaluator evaluator.IEvaluator, cfg SvcConfiguration, contextValues map[string]any) http.Handler {
 	h := handler{
-		Logger:        logger,
-		evaluator:     evaluator,
-		contextValues: contextValues,
+		Logger:          cfg.Logger,
+		evaluator:       evaluator,
+		contextValues:   contextValues,
+		metrics:         &telemetry.NoopMetricsRecorder{},
+		configuration:   ofrep.ConfigurationResponseFrom(cfg.MinPollingInterval),
+		maxBatchSize:    cfg.MaxBatchSize,
+		maxContextBytes: cfg.MaxContextBytes,
+	}
+	if cfg.Metrics != nil {
+		h.metrics = cfg.Metrics
 	}
 
 	router := mux.NewRouter()
-	router.HandleFunc(singleEvaluation, h.HandleFlagEvaluation).Methods("POST")
-	router.HandleFunc(bulkEvaluation, h.HandleBulkEvaluation).Methods("POST")
-	return router
+	router.Handle(singleEvaluation, service.LimitBody(http.HandlerFunc(h.HandleFlagEvaluation),
+		cfg.BodyLimits.Evaluation)).Methods("POST", "GET")
+	router.Handle(bulkEvaluation, service.LimitBody(http.HandlerFunc(h.HandleBulkEvaluation),
+		cfg.BodyLimits.Bulk)).Methods("POST")
+	router.Handle(batchEvaluation, service.LimitBody(http.HandlerFunc(h.HandleBatchEvaluation),
+		cfg.BodyLimits.Bulk)).Methods("POST")
+	router.HandleFunc(configuration, h.HandleConfiguration).Methods("GET")
+	return correlation.New().Handler(router)
 }
 
 func (h *handler) HandleFlagEvaluation(w http.ResponseWriter, r *http.Request) {
-	requestID := xid.New().String()
+	requestID := correlation.NewRequestID(r.Context(), h.Logger)
 	defer h.Logger.ClearFields(requestID)
 
+	status := telemetry.OFREPStatusError
+	defer func() {
+		telemetry.ServiceMetrics(h.metrics).OFREPRequest(r.Context(), telemetry.OFREPSingleRequest, status)
+	}()
+
 	// obtain flag key
 	vars := mux.Vars(r)
 	if vars == nil {
@@ -52,34 +89,103 @@ func (h *handler) HandleFlagEvaluation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	flagKey := vars[key]
-	request, err := extractOfrepRequest(r)
-	if err != nil {
+	var request ofrep.Request
+	var err error
+	if r.Method == http.MethodGet {
+		// lightweight clients pass a flat context as query parameters instead of a request body
+		request, err = queryOfrepRequest(r.URL.Query())
+		if err != nil {
+			h.writeJSONToResponse(http.StatusBadRequest, ofrep.EvaluationError{
+				Key:          flagKey,
+				ErrorCode:    model.InvalidContextCode,
+				ErrorDetails: err.Error(),
+			}, w)
+			return
+		}
+	} else if request, err = h.extractOfrepRequest(r); errors.Is(err, service.ErrContextTooLarge) {
+		h.writeJSONToResponse(http.StatusBadRequest, ofrep.EvaluationError{
+			Key:          flagKey,
+			ErrorCode:    model.InvalidContextCode,
+			ErrorDetails: err.Error(),
+		}, w)
+		return
+	} else if service.IsBodyTooLarge(err) {
+		h.writeJSONToResponse(http.StatusRequestEntityTooLarge, ofrep.EvaluationError{
+			Key:          flagKey,
+			ErrorCode:    model.GeneralErrorCode,
+			ErrorDetails: err.Error(),
+		}, w)
+		return
+	} else if err != nil {
 		h.writeJSONToResponse(http.StatusBadRequest, ofrep.ContextErrorResponseFrom(flagKey), w)
 		return
+	} else if request, err = typedOfrepRequest(r.Header, request); err != nil {
+		h.writeJSONToResponse(http.StatusBadRequest, ofrep.EvaluationError{
+			Key:          flagKey,
+			ErrorCode:    model.InvalidContextCode,
+			ErrorDetails: err.Error(),
+		}, w)
+		return
 	}
 
-	context := flagdContext(h.Logger, requestID, request, h.contextValues)
+	context := flagdContext(h.Logger, requestID, request,
+		peer.EvaluationContext(r.Context()), auth.ClaimsFromContext(r.Context()), h.contextValues)
 	evaluation := h.evaluator.ResolveAsAnyValue(r.Context(), requestID, flagKey, context)
+	h.metrics.RecordEvaluation(r.Context(), evaluation.Error, evaluation.Reason, evaluation.Variant, flagKey,
+		telemetry.APISurfaceOFREP)
 	if evaluation.Error != nil {
 		status, evaluationError := ofrep.EvaluationErrorResponseFrom(evaluation)
 		h.writeJSONToResponse(status, evaluationError, w)
 	} else {
+		status = telemetry.OFREPStatusOK
 		h.writeJSONToResponse(http.StatusOK, ofrep.SuccessResponseFrom(evaluation), w)
 	}
 }
 
 func (h *handler) HandleBulkEvaluation(w http.ResponseWriter, r *http.Request) {
-	requestID := xid.New().String()
+	requestID := correlation.NewRequestID(r.Context(), h.Logger)
 	defer h.Logger.ClearFields(requestID)
 
-	request, err := extractOfrepRequest(r)
+	status := telemetry.OFREPStatusError
+	defer func() {
+		telemetry.ServiceMetrics(h.metrics).OFREPRequest(r.Context(), telemetry.OFREPBulkRequest, status)
+	}()
+
+	request, err := h.extractOfrepRequest(r)
+	if errors.Is(err, service.ErrContextTooLarge) {
+		h.writeJSONToResponse(http.StatusBadRequest,
+			ofrep.BulkEvaluationContextErrorFrom(model.InvalidContextCode, err.Error()), w)
+		return
+	}
+	if service.IsBodyTooLarge(err) {
+		h.writeJSONToResponse(http.StatusRequestEntityTooLarge,
+			ofrep.BulkEvaluationContextErrorFrom(model.GeneralErrorCode, err.Error()), w)
+		return
+	}
 	if err != nil {
 		h.writeJSONToResponse(http.StatusBadRequest, ofrep.BulkEvaluationContextError(), w)
 		return
 	}
+	if request, err = typedOfrepRequest(r.Header, request); err != nil {
+		h.writeJSONToResponse(http.StatusBadRequest,
+			ofrep.BulkEvaluationContextErrorFrom(model.InvalidContextCode, err.Error()), w)
+		return
+	}
 
-	context := flagdContext(h.Logger, requestID, request, h.contextValues)
-	evaluations, err := h.evaluator.ResolveAllValues(r.Context(), requestID, context)
+	ctx := r.Context()
+	if valueType := r.URL.Query().Get(valueTypeParam); valueType != "" {
+		if !evaluator.IsValueType(valueType) {
+			res := ofrep.BulkEvaluationContextErrorFrom(model.GeneralErrorCode,
+				fmt.Sprintf("unknown value type '%s'", valueType))
+			h.writeJSONToResponse(http.StatusBadRequest, res, w)
+			return
+		}
+		ctx = evaluator.WithValueType(ctx, valueType)
+	}
+
+	context := flagdContext(h.Logger, requestID, request,
+		peer.EvaluationContext(r.Context()), auth.ClaimsFromContext(r.Context()), h.contextValues)
+	evaluations, version, err := h.evaluator.ResolveAllValuesWithVersion(ctx, requestID, context)
 	if err != nil {
 		h.Logger.WarnWithID(requestID, fmt.Sprintf("error from resolver: %v", err))
 
@@ -87,8 +193,110 @@ func (h *handler) HandleBulkEvaluation(w http.ResponseWriter, r *http.Request) {
 			fmt.Sprintf("Bulk evaluation failed. Tracking ID: %s", requestID))
 		h.writeJSONToResponse(http.StatusInternalServerError, res, w)
 	} else {
-		h.writeJSONToResponse(http.StatusOK, ofrep.BulkEvaluationResponseFrom(evaluations), w)
+		for _, evaluation := range evaluations {
+			h.metrics.RecordEvaluation(r.Context(), evaluation.Error, evaluation.Reason, evaluation.Variant,
+				evaluation.FlagKey, telemetry.APISurfaceOFREP)
+		}
+		status = telemetry.OFREPStatusOK
+		h.writeJSONToResponse(http.StatusOK, ofrep.BulkEvaluationResponseFrom(evaluations, version), w)
+	}
+}
+
+// HandleBatchEvaluation evaluates a single flag against each context of the request, answering with the results in the
+// order of the contexts. Invalid contexts and failed evaluations are reported as the result of their context.
+func (h *handler) HandleBatchEvaluation(w http.ResponseWriter, r *http.Request) {
+	requestID := correlation.NewRequestID(r.Context(), h.Logger)
+	defer h.Logger.ClearFields(requestID)
+
+	status := telemetry.OFREPStatusError
+	defer func() {
+		telemetry.ServiceMetrics(h.metrics).OFREPRequest(r.Context(), telemetry.OFREPBatchRequest, status)
+	}()
+
+	flagKey := mux.Vars(r)[key]
+	request := ofrep.BatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); service.IsBodyTooLarge(err) {
+		h.writeJSONToResponse(http.StatusRequestEntityTooLarge, ofrep.EvaluationError{
+			Key:          flagKey,
+			ErrorCode:    model.GeneralErrorCode,
+			ErrorDetails: err.Error(),
+		}, w)
+		return
+	} else if err != nil {
+		h.writeJSONToResponse(http.StatusBadRequest, ofrep.ContextErrorResponseFrom(flagKey), w)
+		return
+	}
+	if h.maxBatchSize > 0 && len(request.Contexts) > h.maxBatchSize {
+		h.writeJSONToResponse(http.StatusBadRequest, ofrep.EvaluationError{
+			Key:       flagKey,
+			ErrorCode: model.GeneralErrorCode,
+			ErrorDetails: fmt.Sprintf("batch of %d contexts exceeds the maximum of %d contexts",
+				len(request.Contexts), h.maxBatchSize),
+		}, w)
+		return
+	}
+	if _, err := service.IsTypedContext(r.Header); err != nil {
+		h.writeJSONToResponse(http.StatusBadRequest, ofrep.EvaluationError{
+			Key:          flagKey,
+			ErrorCode:    model.InvalidContextCode,
+			ErrorDetails: err.Error(),
+		}, w)
+		return
+	}
+
+	results := make([]interface{}, len(request.Contexts))
+	contexts := make([]map[string]any, 0, len(request.Contexts))
+	indexes := make([]int, 0, len(request.Contexts))
+	for i, evalCtx := range request.Contexts {
+		context, err := h.batchContext(r, requestID, evalCtx)
+		if err != nil {
+			results[i] = ofrep.EvaluationError{
+				Key:          flagKey,
+				ErrorCode:    model.InvalidContextCode,
+				ErrorDetails: fmt.Sprintf("context %d: %v", i, err),
+			}
+			continue
+		}
+		contexts = append(contexts, context)
+		indexes = append(indexes, i)
+	}
+
+	if len(contexts) > 0 {
+		evaluations := h.evaluator.ResolveBatchValues(r.Context(), requestID, flagKey, contexts)
+		for i, evaluation := range evaluations {
+			h.metrics.RecordEvaluation(r.Context(), evaluation.Error, evaluation.Reason, evaluation.Variant, flagKey,
+				telemetry.APISurfaceOFREP)
+			results[indexes[i]] = ofrep.EvaluationResponseFrom(evaluation)
+		}
 	}
+	status = telemetry.OFREPStatusOK
+	h.writeJSONToResponse(http.StatusOK, ofrep.BatchEvaluationResponse{Results: results}, w)
+}
+
+// batchContext returns the evaluation context of a single context of a batch evaluation, which must be an object
+// within the maximum context size
+func (h *handler) batchContext(r *http.Request, requestID string, raw json.RawMessage) (map[string]any, error) {
+	evalCtx, err := h.decodeContext(r, raw)
+	if err != nil {
+		return nil, err
+	}
+	if evalCtx == nil {
+		evalCtx = map[string]any{}
+	}
+	if _, ok := evalCtx.(map[string]any); !ok {
+		return nil, errors.New("the context is not an object")
+	}
+	request, err := typedOfrepRequest(r.Header, ofrep.Request{Context: evalCtx})
+	if err != nil {
+		return nil, err
+	}
+	return flagdContext(h.Logger, requestID, request,
+		peer.EvaluationContext(r.Context()), auth.ClaimsFromContext(r.Context()), h.contextValues), nil
+}
+
+// HandleConfiguration returns the capabilities of flagd, letting OFREP clients configure their caching and polling
+func (h *handler) HandleConfiguration(w http.ResponseWriter, _ *http.Request) {
+	h.writeJSONToResponse(http.StatusOK, h.configuration, w)
 }
 
 func (h *handler) writeJSONToResponse(status int, payload interface{}, w http.ResponseWriter) {
@@ -109,31 +317,93 @@ func (h *handler) writeJSONToResponse(status int, payload interface{}, w http.Re
 	}
 }
 
-func extractOfrepRequest(req *http.Request) (ofrep.Request, error) {
-	request := ofrep.Request{}
-	err := json.NewDecoder(req.Body).Decode(&request)
+// rawOfrepRequest is an OFREP request whose context is only decoded once its size is checked
+type rawOfrepRequest struct {
+	Context json.RawMessage `json:"context"`
+}
+
+func (h *handler) extractOfrepRequest(req *http.Request) (ofrep.Request, error) {
+	raw := rawOfrepRequest{}
+	err := json.NewDecoder(req.Body).Decode(&raw)
 	if err != nil && err.Error() != "EOF" {
-		return request, fmt.Errorf("decode error: %w", err)
+		return ofrep.Request{}, fmt.Errorf("decode error: %w", err)
+	}
+
+	context, err := h.decodeContext(req, raw.Context)
+	return ofrep.Request{Context: context}, err
+}
+
+// decodeContext decodes the raw JSON of an evaluation context, rejecting contexts exceeding the maximum context size
+// before decoding them and recording the size of the accepted contexts
+func (h *handler) decodeContext(req *http.Request, raw json.RawMessage) (interface{}, error) {
+	var context interface{}
+	if len(raw) == 0 {
+		return context, nil
+	}
+	if err := service.CheckContextSize(len(raw), h.maxContextBytes); err != nil {
+		return nil, fmt.Errorf("invalid context: %w", err)
+	}
+	telemetry.EvaluationMetrics(h.metrics).EvaluationContextSize(req.Context(), telemetry.APISurfaceOFREP,
+		int64(len(raw)))
+
+	if err := json.Unmarshal(raw, &context); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
 	}
+	return context, nil
+}
 
+// typedOfrepRequest decodes the context of a request body opting into the typed context format with the
+// service.ContextFormatHeader. Contexts passed as query parameters are always plain.
+func typedOfrepRequest(header http.Header, request ofrep.Request) (ofrep.Request, error) {
+	typed, err := service.IsTypedContext(header)
+	if err != nil {
+		return request, fmt.Errorf("invalid request header: %w", err)
+	}
+	if !typed {
+		return request, nil
+	}
+	context, ok := request.Context.(map[string]any)
+	if !ok {
+		return request, nil
+	}
+	decoded, err := service.DecodeTypedContext(context)
+	if err != nil {
+		return request, fmt.Errorf("invalid typed context: %w", err)
+	}
+	request.Context = decoded
 	return request, nil
 }
 
-func flagdContext(
-	log *logger.Logger, requestID string, request ofrep.Request, staticContextValues map[string]any,
-) map[string]any {
-	context := make(map[string]any)
-	if res, ok := request.Context.(map[string]any); ok {
-		for k, v := range res {
-			context[k] = v
+// queryOfrepRequest parses the query parameters of a request into a flat evaluation context of string values, as query
+// parameters carry no types. Parameters given more than once and parameters addressing nested attributes, e.g.
+// user.email or user[email], are rejected.
+func queryOfrepRequest(query url.Values) (ofrep.Request, error) {
+	context := make(map[string]any, len(query))
+	for name, values := range query {
+		if name == "" {
+			return ofrep.Request{}, errors.New("context parameters must be named")
 		}
-	} else {
-		log.WarnWithID(requestID, "provided context does not comply with flagd, continuing ignoring the context")
+		if strings.ContainsAny(name, ".[]") {
+			return ofrep.Request{}, fmt.Errorf("nested context parameter '%s' is not supported", name)
+		}
+		if len(values) != 1 {
+			return ofrep.Request{}, fmt.Errorf("context parameter '%s' is given more than once", name)
+		}
+		context[name] = values[0]
 	}
+	return ofrep.Request{Context: context}, nil
+}
 
-	for k, v := range staticContextValues {
-		context[k] = v
+// flagdContext merges the attributes of the peer, the evaluation context of the request, the verified claims of the
+// request and the static context values, see service.MergeContexts
+func flagdContext(
+	log *logger.Logger, requestID string, request ofrep.Request, peerContext map[string]any, claims map[string]any,
+	staticContextValues map[string]any,
+) map[string]any {
+	clientContext, ok := request.Context.(map[string]any)
+	if !ok {
+		log.WarnWithID(requestID, "provided context does not comply with flagd, continuing ignoring the context")
 	}
 
-	return context
+	return service.MergeContexts(peerContext, clientContext, claims, staticContextValues)
 }
@@ -2,25 +2,52 @@ package ofrep
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	mock "github.com/open-feature/flagd/core/pkg/evaluator/mock"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/service/ofrep"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"go.uber.org/mock/gomock"
 )
 
 var flagKey = "key"
 
+type requestRecorder struct {
+	telemetry.NoopMetricsRecorder
+	requests    []string
+	evaluations []string
+}
+
+func (r *requestRecorder) OFREPRequest(_ context.Context, requestType, status string) {
+	r.requests = append(r.requests, requestType+"/"+status)
+}
+
+func (r *requestRecorder) RecordEvaluation(_ context.Context, _ error, _, _, _, surface string) {
+	r.evaluations = append(r.evaluations, surface)
+}
+
+// expectedRequest returns the recorded request of a response with the given status code
+func expectedRequest(requestType string, code int) string {
+	if code == http.StatusOK {
+		return requestType + "/" + telemetry.OFREPStatusOK
+	}
+	return requestType + "/" + telemetry.OFREPStatusError
+}
+
 var successValue = evaluator.AnyValue{
 	Value:    true,
 	Variant:  "true",
@@ -51,6 +78,8 @@ func Test_handler_HandleFlagEvaluation(t *testing.T) {
 		method          string
 		path            string
 		input           *bytes.Reader
+		format          string
+		maxContextBytes int
 		mockAnyResponse *evaluator.AnyValue
 
 		expectedStatus       int
@@ -109,6 +138,51 @@ func Test_handler_HandleFlagEvaluation(t *testing.T) {
 			expectedStatus:       http.StatusBadRequest,
 			expectedResponseType: ofrep.EvaluationError{},
 		},
+		{
+			name:                 "context exceeding the maximum size",
+			method:               http.MethodPost,
+			path:                 "/ofrep/v1/evaluate/flags/" + flagKey,
+			input:                bytes.NewReader([]byte(`{"context": {"email": "a@example.com"}}`)),
+			maxContextBytes:      16,
+			expectedStatus:       http.StatusBadRequest,
+			expectedResponseType: ofrep.EvaluationError{},
+		},
+		{
+			name:                 "typed context and success",
+			method:               http.MethodPost,
+			path:                 "/ofrep/v1/evaluate/flags/" + flagKey,
+			input:                bytes.NewReader([]byte(`{"context": {"phone": {"type": "string", "value": 5550100}}}`)),
+			format:               service.TypedContextFormat,
+			mockAnyResponse:      &successValue,
+			expectedStatus:       http.StatusOK,
+			expectedResponseType: ofrep.EvaluationSuccess{},
+		},
+		{
+			name:                 "invalid typed context",
+			method:               http.MethodPost,
+			path:                 "/ofrep/v1/evaluate/flags/" + flagKey,
+			input:                bytes.NewReader([]byte(`{"context": {"phone": 5550100}}`)),
+			format:               service.TypedContextFormat,
+			expectedStatus:       http.StatusBadRequest,
+			expectedResponseType: ofrep.EvaluationError{},
+		},
+		{
+			name:                 "query context and success",
+			method:               http.MethodGet,
+			path:                 "/ofrep/v1/evaluate/flags/" + flagKey + "?email=a@example.com&beta=true",
+			input:                bytes.NewReader([]byte{}),
+			mockAnyResponse:      &successValue,
+			expectedStatus:       http.StatusOK,
+			expectedResponseType: ofrep.EvaluationSuccess{},
+		},
+		{
+			name:                 "nested query context",
+			method:               http.MethodGet,
+			path:                 "/ofrep/v1/evaluate/flags/" + flagKey + "?user.email=a@example.com",
+			input:                bytes.NewReader([]byte{}),
+			expectedStatus:       http.StatusBadRequest,
+			expectedResponseType: ofrep.EvaluationError{},
+		},
 	}
 
 	for _, test := range tests {
@@ -120,12 +194,16 @@ func Test_handler_HandleFlagEvaluation(t *testing.T) {
 					Return(*test.mockAnyResponse)
 			}
 
-			h := handler{Logger: log, evaluator: eval}
+			metrics := &requestRecorder{}
+			h := handler{Logger: log, evaluator: eval, metrics: metrics, maxContextBytes: test.maxContextBytes}
 
 			request, err := http.NewRequest(test.method, test.path, test.input)
 			if err != nil {
 				t.Fatalf("error setting up request: %v", err)
 			}
+			if test.format != "" {
+				request.Header.Set(service.ContextFormatHeader, test.format)
+			}
 
 			recorder := httptest.NewRecorder()
 
@@ -137,6 +215,19 @@ func Test_handler_HandleFlagEvaluation(t *testing.T) {
 				t.Errorf("expected status code %d, but got %d", test.expectedStatus, recorder.Code)
 			}
 
+			expected := []string{expectedRequest(telemetry.OFREPSingleRequest, test.expectedStatus)}
+			if !reflect.DeepEqual(expected, metrics.requests) {
+				t.Errorf("expected recorded requests %v, but got %v", expected, metrics.requests)
+			}
+
+			var expectedEvaluations []string
+			if test.mockAnyResponse != nil {
+				expectedEvaluations = []string{telemetry.APISurfaceOFREP}
+			}
+			if !reflect.DeepEqual(expectedEvaluations, metrics.evaluations) {
+				t.Errorf("expected recorded evaluations %v, but got %v", expectedEvaluations, metrics.evaluations)
+			}
+
 			output := test.expectedResponseType
 			err = json.NewDecoder(recorder.Result().Body).Decode(&output)
 			if err != nil {
@@ -146,6 +237,76 @@ func Test_handler_HandleFlagEvaluation(t *testing.T) {
 	}
 }
 
+func Test_queryOfrepRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected map[string]any
+		wantErr  bool
+	}{
+		{
+			name:     "empty query",
+			query:    "",
+			expected: map[string]any{},
+		},
+		{
+			name:  "string values",
+			query: "targetingKey=123&email=a@example.com&beta=true&age=42&zip=007&empty=",
+			expected: map[string]any{
+				"targetingKey": "123",
+				"email":        "a@example.com",
+				"beta":         "true",
+				"age":          "42",
+				"zip":          "007",
+				"empty":        "",
+			},
+		},
+		{
+			name:    "nested attribute by path",
+			query:   "user.email=a@example.com",
+			wantErr: true,
+		},
+		{
+			name:    "nested attribute by brackets",
+			query:   "user[email]=a@example.com",
+			wantErr: true,
+		},
+		{
+			name:    "repeated parameter",
+			query:   "tag=a&tag=b",
+			wantErr: true,
+		},
+		{
+			name:    "unnamed parameter",
+			query:   "=a",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := url.ParseQuery(test.query)
+			if err != nil {
+				t.Fatalf("error parsing query: %v", err)
+			}
+
+			request, err := queryOfrepRequest(query)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, but got context %v", request.Context)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(test.expected, request.Context) {
+				t.Errorf("expected context %v, but got %v", test.expected, request.Context)
+			}
+		})
+	}
+}
+
 func Test_handler_HandleBulkEvaluation(t *testing.T) {
 	log := logger.NewLogger(nil, false)
 
@@ -153,6 +314,7 @@ func Test_handler_HandleBulkEvaluation(t *testing.T) {
 		name string
 
 		method          string
+		query           string
 		input           *bytes.Reader
 		mockAnyResponse []evaluator.AnyValue
 		mockAnyError    error
@@ -201,17 +363,33 @@ func Test_handler_HandleBulkEvaluation(t *testing.T) {
 			input:          bytes.NewReader([]byte("{some invalid context}")),
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:            "scoped to value type",
+			method:          http.MethodPost,
+			query:           "?type=boolean",
+			input:           bytes.NewReader([]byte{}),
+			mockAnyResponse: []evaluator.AnyValue{successValue},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:           "unknown value type",
+			method:         http.MethodPost,
+			query:          "?type=number",
+			input:          bytes.NewReader([]byte{}),
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			eval := mock.NewMockIEvaluator(gomock.NewController(t))
-			eval.EXPECT().ResolveAllValues(gomock.Any(), gomock.Any(), gomock.Any()).
-				Return(test.mockAnyResponse, test.mockAnyError).MinTimes(0)
+			eval.EXPECT().ResolveAllValuesWithVersion(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(test.mockAnyResponse, "", test.mockAnyError).MinTimes(0)
 
-			h := handler{Logger: log, evaluator: eval}
+			metrics := &requestRecorder{}
+			h := handler{Logger: log, evaluator: eval, metrics: metrics}
 
-			request, err := http.NewRequest(test.method, "/ofrep/v1/evaluate/flags", test.input)
+			request, err := http.NewRequest(test.method, "/ofrep/v1/evaluate/flags"+test.query, test.input)
 			if err != nil {
 				t.Fatalf("error setting up request: %v", err)
 			}
@@ -225,6 +403,201 @@ func Test_handler_HandleBulkEvaluation(t *testing.T) {
 			if test.expectedStatus != recorder.Code {
 				t.Errorf("expected status code %d, but got %d", test.expectedStatus, recorder.Code)
 			}
+
+			expected := []string{expectedRequest(telemetry.OFREPBulkRequest, test.expectedStatus)}
+			if !reflect.DeepEqual(expected, metrics.requests) {
+				t.Errorf("expected recorded requests %v, but got %v", expected, metrics.requests)
+			}
+		})
+	}
+}
+
+func Test_handler_HandleBatchEvaluation(t *testing.T) {
+	log := logger.NewLogger(nil, false)
+
+	tests := []struct {
+		name            string
+		input           string
+		expectedResults []string
+		expectedStatus  int
+	}{
+		{
+			name:            "results in the order of the contexts",
+			input:           `{"contexts": [{"user": "a"}, {"user": "b"}]}`,
+			expectedResults: []string{"true", model.FlagNotFoundErrorCode},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "invalid contexts are reported inline",
+			input:           `{"contexts": [{"user": "a"}, "b", {"user": "c"}]}`,
+			expectedResults: []string{"true", model.InvalidContextCode, model.FlagNotFoundErrorCode},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "contexts exceeding the maximum size are reported inline",
+			input:           `{"contexts": [{"user": "a"}, {"user": "too large"}, {"user": "c"}]}`,
+			expectedResults: []string{"true", model.InvalidContextCode, model.FlagNotFoundErrorCode},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:            "empty batch",
+			input:           `{"contexts": []}`,
+			expectedResults: []string{},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name:           "batch exceeding the maximum size",
+			input:          `{"contexts": [{}, {}, {}, {}]}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid request",
+			input:          `{"contexts": {}}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			eval := mock.NewMockIEvaluator(gomock.NewController(t))
+			eval.EXPECT().
+				ResolveBatchValues(gomock.Any(), gomock.Any(), flagKey, gomock.Any()).
+				Return([]evaluator.AnyValue{successValue, flagNotFoundValue}).
+				MaxTimes(1)
+
+			metrics := &requestRecorder{}
+			h := handler{Logger: log, evaluator: eval, metrics: metrics, maxBatchSize: 3, maxContextBytes: 16}
+
+			request, err := http.NewRequest(http.MethodPost, "/ofrep/v1/evaluate/flags/"+flagKey+"/batch",
+				bytes.NewReader([]byte(test.input)))
+			if err != nil {
+				t.Fatalf("error setting up request: %v", err)
+			}
+			recorder := httptest.NewRecorder()
+
+			router := mux.NewRouter()
+			router.HandleFunc(batchEvaluation, h.HandleBatchEvaluation)
+			router.ServeHTTP(recorder, request)
+
+			if test.expectedStatus != recorder.Code {
+				t.Fatalf("expected status code %d, but got %d", test.expectedStatus, recorder.Code)
+			}
+			expected := []string{expectedRequest(telemetry.OFREPBatchRequest, test.expectedStatus)}
+			if !reflect.DeepEqual(expected, metrics.requests) {
+				t.Errorf("expected recorded requests %v, but got %v", expected, metrics.requests)
+			}
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Results []struct {
+					Variant   string `json:"variant"`
+					ErrorCode string `json:"errorCode"`
+				} `json:"results"`
+			}
+			if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+				t.Fatalf("error parsing response: %v", err)
+			}
+			results := []string{}
+			for _, result := range response.Results {
+				results = append(results, result.Variant+result.ErrorCode)
+			}
+			if !reflect.DeepEqual(test.expectedResults, results) {
+				t.Errorf("expected results %v, but got %v", test.expectedResults, results)
+			}
+		})
+	}
+}
+
+func Test_handler_HandleConfiguration(t *testing.T) {
+	log := logger.NewLogger(nil, false)
+	metrics := &requestRecorder{}
+	h := NewOfrepHandler(mock.NewMockIEvaluator(gomock.NewController(t)), SvcConfiguration{
+		Logger: log, Metrics: metrics, MinPollingInterval: 30 * time.Second, MaxBatchSize: DefaultMaxBatchSize,
+	}, nil)
+
+	request, err := http.NewRequest(http.MethodGet, "/ofrep/v1/configuration", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, but got %d", http.StatusOK, recorder.Code)
+	}
+
+	var rsp ofrep.ConfigurationResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &rsp); err != nil {
+		t.Fatalf("error unmarshalling body: %v", err)
+	}
+	if !reflect.DeepEqual(ofrep.ConfigurationResponseFrom(30*time.Second), rsp) {
+		t.Errorf("expected the capabilities of flagd, but got %v", rsp)
+	}
+
+	if len(metrics.requests) != 0 {
+		t.Errorf("expected configuration requests not to be recorded as evaluations, but got %v", metrics.requests)
+	}
+}
+
+func Test_handler_BodyLimits(t *testing.T) {
+	log := logger.NewLogger(nil, false)
+	eval := mock.NewMockIEvaluator(gomock.NewController(t))
+	eval.EXPECT().ResolveAllValuesWithVersion(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]evaluator.AnyValue{}, "", nil)
+	h := NewOfrepHandler(eval, SvcConfiguration{
+		Logger: log, MaxBatchSize: DefaultMaxBatchSize, BodyLimits: service.BodyLimits{Evaluation: 16, Bulk: 64},
+	}, nil)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		chunked        bool
+		expectedStatus int
+	}{
+		{
+			name:           "single evaluation exceeding the limit",
+			path:           "/ofrep/v1/evaluate/flags/" + flagKey,
+			body:           `{"context": {"email": "a@example.com"}}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "single evaluation exceeding the limit without content length",
+			path:           "/ofrep/v1/evaluate/flags/" + flagKey,
+			body:           `{"context": {"email": "a@example.com"}}`,
+			chunked:        true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "batch evaluation exceeding the limit without content length",
+			path:           "/ofrep/v1/evaluate/flags/" + flagKey + "/batch",
+			body:           `{"contexts": [{"email": "a@example.com"}, {"email": "b@example.com"}]}`,
+			chunked:        true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "bulk evaluation within the limit",
+			path:           "/ofrep/v1/evaluate/flags",
+			body:           `{"context": {"email": "a@example.com"}}`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body io.Reader = bytes.NewBufferString(test.body)
+			if test.chunked {
+				// hides the length of the body from the request
+				body = io.MultiReader(body)
+			}
+			recorder := httptest.NewRecorder()
+			h.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, test.path, body))
+
+			if test.expectedStatus != recorder.Code {
+				t.Errorf("expected status code %d, but got %d", test.expectedStatus, recorder.Code)
+			}
 		})
 	}
 }
@@ -9,6 +9,10 @@ import (
 
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/configversion"
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/correlation"
 	"github.com/rs/cors"
 	"golang.org/x/sync/errgroup"
 )
@@ -18,9 +22,31 @@ type IOfrepService interface {
 	Start(context.Context) error
 }
 
+// DefaultMaxBatchSize is the default maximum number of contexts of a batch evaluation
+const DefaultMaxBatchSize = 1000
+
 type SvcConfiguration struct {
-	Logger *logger.Logger
-	Port   uint16
+	Logger   *logger.Logger
+	Port     uint16
+	Timeouts service.ServerTimeouts
+	// Authentication wraps the OFREP handler, rejecting unauthenticated requests
+	Authentication func(http.Handler) http.Handler
+	// PeerContext wraps the OFREP handler, attaching the attributes of the peer to the request context
+	PeerContext func(http.Handler) http.Handler
+	// ConfigVersionHeader names the response header returning the ConfigVersion, empty if the version is not returned
+	ConfigVersionHeader string
+	ConfigVersion       func() string
+	// Metrics records the OFREP requests, no metrics are recorded if unset
+	Metrics telemetry.IMetricsRecorder
+	// MinPollingInterval is the minimum interval between polls of the bulk evaluation advertised by the configuration
+	// endpoint, zero doesn't limit the polling
+	MinPollingInterval time.Duration
+	// MaxBatchSize is the maximum number of contexts of a batch evaluation, zero doesn't limit the contexts
+	MaxBatchSize int
+	// MaxContextBytes rejects evaluation requests whose context exceeds the size in bytes, zero doesn't limit the size
+	MaxContextBytes int
+	// BodyLimits limit the request bodies of the single flag evaluations and of the bulk and batch evaluations
+	BodyLimits service.BodyLimits
 }
 
 type Service struct {
@@ -32,22 +58,29 @@ type Service struct {
 func NewOfrepService(
 	evaluator evaluator.IEvaluator, origins []string, cfg SvcConfiguration, contextValues map[string]any,
 ) (*Service, error) {
+	exposedHeaders := []string{correlation.HeaderName}
+	h := NewOfrepHandler(evaluator, cfg, contextValues)
+	if cfg.ConfigVersionHeader != "" && cfg.ConfigVersion != nil {
+		h = configversion.New(cfg.ConfigVersionHeader, cfg.ConfigVersion).Handler(h)
+		exposedHeaders = append(exposedHeaders, cfg.ConfigVersionHeader)
+	}
 	corsMW := cors.New(cors.Options{
 		AllowedOrigins: origins,
-		AllowedMethods: []string{http.MethodPost},
+		AllowedMethods: []string{http.MethodPost, http.MethodGet},
+		ExposedHeaders: exposedHeaders,
 	})
-	h := corsMW.Handler(NewOfrepHandler(cfg.Logger, evaluator, contextValues))
-
-	server := http.Server{
-		Addr:              fmt.Sprintf(":%d", cfg.Port),
-		Handler:           h,
-		ReadHeaderTimeout: 3 * time.Second,
+	if cfg.PeerContext != nil {
+		h = cfg.PeerContext(h)
+	}
+	if cfg.Authentication != nil {
+		h = cfg.Authentication(h)
 	}
+	h = corsMW.Handler(h)
 
 	return &Service{
 		logger: cfg.Logger,
 		port:   cfg.Port,
-		server: &server,
+		server: service.NewHTTPServer(fmt.Sprintf(":%d", cfg.Port), h, cfg.Timeouts),
 	}, nil
 }
 
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	evaluationV1 "buf.build/gen/go/open-feature/flagd/connectrpc/go/flagd/evaluation/v1/evaluationv1connect"
+	schemaConnectV1 "buf.build/gen/go/open-feature/flagd/connectrpc/go/schema/v1/schemav1connect"
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+// otherRPC is the service and method label of procedures which are not RPCs of the evaluation services
+const otherRPC = "other"
+
+// rpcProcedures are the procedures labelled by the RPC metrics, which bounds the cardinality of the method label
+var rpcProcedures = map[string]struct{}{
+	evaluationV1.ServiceResolveAllProcedure:        {},
+	evaluationV1.ServiceResolveBooleanProcedure:    {},
+	evaluationV1.ServiceResolveStringProcedure:     {},
+	evaluationV1.ServiceResolveFloatProcedure:      {},
+	evaluationV1.ServiceResolveIntProcedure:        {},
+	evaluationV1.ServiceResolveObjectProcedure:     {},
+	evaluationV1.ServiceEventStreamProcedure:       {},
+	schemaConnectV1.ServiceResolveAllProcedure:     {},
+	schemaConnectV1.ServiceResolveBooleanProcedure: {},
+	schemaConnectV1.ServiceResolveStringProcedure:  {},
+	schemaConnectV1.ServiceResolveFloatProcedure:   {},
+	schemaConnectV1.ServiceResolveIntProcedure:     {},
+	schemaConnectV1.ServiceResolveObjectProcedure:  {},
+	schemaConnectV1.ServiceEventStreamProcedure:    {},
+}
+
+// rpcMetricsInterceptor records the duration and gRPC status code of the RPCs served by the evaluation services
+type rpcMetricsInterceptor struct {
+	metrics telemetry.IMetricsRecorder
+}
+
+func newRPCMetricsInterceptor(metrics telemetry.IMetricsRecorder) *rpcMetricsInterceptor {
+	return &rpcMetricsInterceptor{metrics: metrics}
+}
+
+func (i *rpcMetricsInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		start := time.Now()
+		res, err := next(ctx, req)
+		i.record(ctx, req.Spec().Procedure, start, err)
+		return res, err
+	}
+}
+
+func (i *rpcMetricsInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler records event streams once they are closed, hence their duration is the lifetime of the stream
+func (i *rpcMetricsInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		start := time.Now()
+		err := next(ctx, conn)
+		i.record(ctx, conn.Spec().Procedure, start, err)
+		return err
+	}
+}
+
+func (i *rpcMetricsInterceptor) record(ctx context.Context, procedure string, start time.Time, err error) {
+	service, method := rpcLabels(procedure)
+	// connect codes are the gRPC status codes, with the exception of OK which connect represents by a nil error
+	code := 0
+	if err != nil {
+		code = int(connect.CodeOf(err))
+	}
+	i.metrics.RPCDuration(ctx, service, method, code, time.Since(start))
+}
+
+// rpcLabels splits a procedure of the evaluation services into its service and method
+func rpcLabels(procedure string) (string, string) {
+	if _, ok := rpcProcedures[procedure]; !ok {
+		return otherRPC, otherRPC
+	}
+	service, method, _ := strings.Cut(strings.TrimPrefix(procedure, "/"), "/")
+	return service, method
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	evaluationV1 "buf.build/gen/go/open-feature/flagd/connectrpc/go/flagd/evaluation/v1/evaluationv1connect"
+	evalV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/flagd/evaluation/v1"
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+type rpcRecord struct {
+	service string
+	method  string
+	code    int
+}
+
+type rpcRecorder struct {
+	telemetry.NoopMetricsRecorder
+	records []rpcRecord
+}
+
+func (r *rpcRecorder) RPCDuration(_ context.Context, service, method string, code int, _ time.Duration) {
+	r.records = append(r.records, rpcRecord{service: service, method: method, code: code})
+}
+
+type resolveBooleanHandler struct {
+	evaluationV1.UnimplementedServiceHandler
+}
+
+func (resolveBooleanHandler) ResolveBoolean(
+	_ context.Context, req *connect.Request[evalV1.ResolveBooleanRequest],
+) (*connect.Response[evalV1.ResolveBooleanResponse], error) {
+	if req.Msg.FlagKey == "missing" {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("flag not found"))
+	}
+	return connect.NewResponse(&evalV1.ResolveBooleanResponse{Value: true}), nil
+}
+
+func TestRPCMetricsInterceptor(t *testing.T) {
+	recorder := &rpcRecorder{}
+	_, handler := evaluationV1.NewServiceHandler(resolveBooleanHandler{},
+		connect.WithInterceptors(newRPCMetricsInterceptor(recorder)))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := evaluationV1.NewServiceClient(server.Client(), server.URL)
+	_, err := client.ResolveBoolean(context.Background(),
+		connect.NewRequest(&evalV1.ResolveBooleanRequest{FlagKey: "myFlag"}))
+	require.NoError(t, err)
+	_, err = client.ResolveBoolean(context.Background(),
+		connect.NewRequest(&evalV1.ResolveBooleanRequest{FlagKey: "missing"}))
+	require.Equal(t, connect.CodeNotFound, connect.CodeOf(err))
+	_, err = client.ResolveString(context.Background(),
+		connect.NewRequest(&evalV1.ResolveStringRequest{FlagKey: "myFlag"}))
+	require.Equal(t, connect.CodeUnimplemented, connect.CodeOf(err))
+
+	require.Equal(t, []rpcRecord{
+		{service: evaluationV1.ServiceName, method: "ResolveBoolean", code: 0},
+		{service: evaluationV1.ServiceName, method: "ResolveBoolean", code: int(connect.CodeNotFound)},
+		{service: evaluationV1.ServiceName, method: "ResolveString", code: int(connect.CodeUnimplemented)},
+	}, recorder.records)
+}
+
+func TestRPCLabels(t *testing.T) {
+	service, method := rpcLabels("/schema.v1.Service/ResolveObject")
+	require.Equal(t, "schema.v1.Service", service)
+	require.Equal(t, "ResolveObject", method)
+
+	service, method = rpcLabels("/schema.v1.Service/Unknown")
+	require.Equal(t, otherRPC, service)
+	require.Equal(t, otherRPC, method)
+}
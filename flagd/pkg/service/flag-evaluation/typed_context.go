@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// contextRequest is a request of the evaluation services carrying an evaluation context
+type contextRequest interface {
+	GetContext() *structpb.Struct
+}
+
+// typedContextInterceptor decodes the evaluation contexts of requests opting into the typed context format with the
+// service.ContextFormatHeader, so that the services evaluate plain contexts regardless of the format of the request
+type typedContextInterceptor struct{}
+
+func (typedContextInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		typed, err := service.IsTypedContext(req.Header())
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		if typed {
+			if err := decodeTypedRequestContext(req.Any()); err != nil {
+				return nil, connect.NewError(connect.CodeInvalidArgument, err)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+func (typedContextInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler leaves streams as they are, as event streams don't carry an evaluation context
+func (typedContextInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// decodeTypedRequestContext replaces the typed evaluation context of a request by the decoded plain context
+func decodeTypedRequestContext(msg any) error {
+	req, ok := msg.(contextRequest)
+	if !ok || req.GetContext() == nil {
+		return nil
+	}
+
+	decoded, err := service.DecodeTypedContext(req.GetContext().AsMap())
+	if err != nil {
+		return fmt.Errorf("invalid typed context: %w", err)
+	}
+	plain, err := structpb.NewStruct(decoded)
+	if err != nil {
+		return fmt.Errorf("invalid typed context: %w", err)
+	}
+	req.GetContext().Fields = plain.GetFields()
+	return nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	evaluationV1 "buf.build/gen/go/open-feature/flagd/connectrpc/go/flagd/evaluation/v1/evaluationv1connect"
+	evalV1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/flagd/evaluation/v1"
+	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// contextCapturingHandler captures the evaluation context of the requests it serves
+type contextCapturingHandler struct {
+	evaluationV1.UnimplementedServiceHandler
+	context map[string]any
+}
+
+func (h *contextCapturingHandler) ResolveString(
+	_ context.Context, req *connect.Request[evalV1.ResolveStringRequest],
+) (*connect.Response[evalV1.ResolveStringResponse], error) {
+	h.context = req.Msg.GetContext().AsMap()
+	return connect.NewResponse(&evalV1.ResolveStringResponse{Value: "ok"}), nil
+}
+
+func TestTypedContextInterceptor(t *testing.T) {
+	handler := &contextCapturingHandler{}
+	_, h := evaluationV1.NewServiceHandler(handler, connect.WithInterceptors(typedContextInterceptor{}))
+	server := httptest.NewServer(h)
+	defer server.Close()
+	client := evaluationV1.NewServiceClient(server.Client(), server.URL)
+
+	request := func(format string, evalCtx map[string]any) error {
+		s, err := structpb.NewStruct(evalCtx)
+		require.NoError(t, err)
+		req := connect.NewRequest(&evalV1.ResolveStringRequest{FlagKey: "flag", Context: s})
+		if format != "" {
+			req.Header().Set(service.ContextFormatHeader, format)
+		}
+		_, err = client.ResolveString(context.Background(), req)
+		return err
+	}
+
+	require.NoError(t, request("", map[string]any{"phone": 5550100}))
+	assert.Equal(t, map[string]any{"phone": 5550100.0}, handler.context, "plain contexts are kept as they are")
+
+	require.NoError(t, request(service.TypedContextFormat, map[string]any{
+		"phone": map[string]any{"type": "string", "value": 5550100},
+		"tier":  map[string]any{"type": "integer", "value": "3"},
+	}))
+	assert.Equal(t, map[string]any{"phone": "5550100", "tier": 3.0}, handler.context)
+
+	err := request(service.TypedContextFormat, map[string]any{"phone": 5550100})
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err), "plain attributes are invalid typed contexts")
+
+	err = request("xml", map[string]any{})
+	assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err), "unknown formats are rejected")
+}
@@ -7,6 +7,9 @@ import (
 	"buf.build/gen/go/open-feature/flagd/grpc/go/flagd/sync/v1/syncv1grpc"
 	syncv1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/flagd/sync/v1"
 	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
@@ -15,6 +18,7 @@ type syncHandler struct {
 	mux           *Multiplexer
 	log           *logger.Logger
 	contextValues map[string]any
+	streams       *service.StreamLimiter
 }
 
 func (s syncHandler) SyncFlags(req *syncv1.SyncFlagsRequest, server syncv1grpc.FlagSyncService_SyncFlagsServer) error {
@@ -23,10 +27,18 @@ func (s syncHandler) SyncFlags(req *syncv1.SyncFlagsRequest, server syncv1grpc.F
 
 	ctx := server.Context()
 
+	release, ok := s.streams.Acquire(ctx)
+	if !ok {
+		return status.Error(codes.ResourceExhausted, "too many concurrent sync streams")
+	}
+	defer release()
+
 	err := s.mux.Register(ctx, selector, muxPayload)
 	if err != nil {
 		return err
 	}
+	// unregister on every exit, the multiplexer would otherwise keep publishing to the abandoned channel
+	defer s.mux.Unregister(ctx, selector)
 
 	for {
 		select {
@@ -37,7 +49,6 @@ func (s syncHandler) SyncFlags(req *syncv1.SyncFlagsRequest, server syncv1grpc.F
 				return fmt.Errorf("error sending stream response: %w", err)
 			}
 		case <-ctx.Done():
-			s.mux.Unregister(ctx, selector)
 			s.log.Debug("context complete and exiting stream request")
 			return nil
 		}
@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	syncv1 "buf.build/gen/go/open-feature/flagd/protocolbuffers/go/flagd/sync/v1"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// syncStream is a server stream of the SyncFlags call, sending fails once sendErr is set
+type syncStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sent    chan *syncv1.SyncFlagsResponse
+	sendErr error
+}
+
+func (s *syncStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *syncStream) Send(response *syncv1.SyncFlagsResponse) error {
+	s.sent <- response
+	return s.sendErr
+}
+
+func TestSyncFlags_MaxStreams(t *testing.T) {
+	mux, err := NewMux(getSimpleFlagStore())
+	require.NoError(t, err)
+
+	handler := syncHandler{
+		mux:     mux,
+		log:     logger.NewLogger(nil, false),
+		streams: service.NewStreamLimiter(service.SyncStream, 1, nil),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := &syncStream{ctx: ctx, sent: make(chan *syncv1.SyncFlagsResponse, 1)}
+	done := make(chan error)
+	go func() {
+		done <- handler.SyncFlags(&syncv1.SyncFlagsRequest{}, first)
+	}()
+	<-first.sent
+
+	// streams exceeding the limit are rejected
+	err = handler.SyncFlags(&syncv1.SyncFlagsRequest{}, &syncStream{ctx: context.Background()})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end")
+	}
+	require.Zero(t, handler.streams.Open())
+
+	// streams failing to send are released and unregistered as well
+	failing := &syncStream{
+		ctx:     context.Background(),
+		sent:    make(chan *syncv1.SyncFlagsResponse, 1),
+		sendErr: errors.New("client gone"),
+	}
+	require.Error(t, handler.SyncFlags(&syncv1.SyncFlagsRequest{}, failing))
+	require.Zero(t, handler.streams.Open())
+	require.Empty(t, mux.subs)
+}
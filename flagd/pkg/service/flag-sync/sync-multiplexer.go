@@ -177,7 +177,7 @@ func (r *Multiplexer) reFill() error {
 		return fmt.Errorf("error retrieving flags from the store: %w", err)
 	}
 
-	bytes, err := json.Marshal(map[string]interface{}{"flags": all})
+	bytes, err := json.Marshal(servedConfig(all, r.store.GetDeclarations("")))
 	if err != nil {
 		return fmt.Errorf("error marshalling: %w", err)
 	}
@@ -199,7 +199,7 @@ func (r *Multiplexer) reFill() error {
 
 	// for all flags, sort them into their correct selector
 	for source, flags := range collector {
-		bytes, err := json.Marshal(map[string]interface{}{"flags": flags})
+		bytes, err := json.Marshal(servedConfig(flags, r.store.GetDeclarations(source)))
 		if err != nil {
 			return fmt.Errorf("unable to marshal flags: %w", err)
 		}
@@ -209,3 +209,13 @@ func (r *Multiplexer) reFill() error {
 
 	return nil
 }
+
+// servedConfig returns the configuration serving the given flags along with the declarations their targeting
+// references, e.g. the lookup tables, so that the served configuration can be applied as is
+func servedConfig(flags map[string]model.Flag, declarations store.Declarations) map[string]interface{} {
+	config := map[string]interface{}{"flags": flags}
+	for kind, named := range declarations {
+		config[kind] = named
+	}
+	return config
+}
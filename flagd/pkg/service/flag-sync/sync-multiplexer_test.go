@@ -2,11 +2,13 @@ package sync
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -200,3 +202,31 @@ func TestGetAllFlags(t *testing.T) {
 
 	assert.Equal(t, flags, emptyConfigString)
 }
+
+func TestGetAllFlagsWithDeclarations(t *testing.T) {
+	flagStore, sources := getSimpleFlagStore()
+	flagStore.SetDeclarations("A", store.Declarations{
+		"$lookups": {"tiers": json.RawMessage(`{"entries": {"DE": "gold"}}`)},
+	}, true)
+	mux, err := NewMux(flagStore, sources)
+	if err != nil {
+		t.Fatal("error during flag extraction")
+		return
+	}
+
+	for _, source := range []string{"", "A"} {
+		flags, err := mux.GetAllFlags(source)
+		if err != nil {
+			t.Fatal("error when retrieving all flags")
+			return
+		}
+		assert.Contains(t, flags, `"$lookups":{"tiers":{"entries":{"DE":"gold"}}}`)
+	}
+
+	flags, err := mux.GetAllFlags("B")
+	if err != nil {
+		t.Fatal("error when retrieving all flags")
+		return
+	}
+	assert.NotContains(t, flags, "$lookups")
+}
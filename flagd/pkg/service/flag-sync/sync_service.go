@@ -10,7 +10,9 @@ import (
 
 	"buf.build/gen/go/open-feature/flagd/grpc/go/flagd/sync/v1/syncv1grpc"
 	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -32,6 +34,9 @@ type SvcConfigurations struct {
 	ContextValues map[string]any
 	CertPath      string
 	KeyPath       string
+	// MaxStreams caps the number of concurrent sync streams, zero doesn't limit them
+	MaxStreams int
+	Metrics    telemetry.IMetricsRecorder
 }
 
 type Service struct {
@@ -82,6 +87,7 @@ func NewSyncService(cfg SvcConfigurations) (*Service, error) {
 		mux:           mux,
 		log:           l,
 		contextValues: cfg.ContextValues,
+		streams:       service.NewStreamLimiter(service.SyncStream, cfg.MaxStreams, cfg.Metrics),
 	})
 
 	l.Info(fmt.Sprintf("starting flag sync service on port %d", cfg.Port))
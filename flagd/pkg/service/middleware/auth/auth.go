@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+const bearerPrefix = "Bearer "
+
+// validMethods excludes symmetric and unsigned tokens, flagd only holds public keys
+var validMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+type contextKey struct{}
+
+// Configuration of the JWT verification. Exactly one of PublicKeyPath and JWKSURL must be set.
+type Configuration struct {
+	// PublicKeyPath is the path of a PEM encoded RSA, ECDSA or Ed25519 public key
+	PublicKeyPath string
+	// JWKSURL is the URL of a JSON Web Key Set, keys are selected by the key ID of the token
+	JWKSURL string
+	// Issuer, if set, must match the iss claim of the token
+	Issuer string
+	// Audience, if set, must be contained in the aud claim of the token
+	Audience string
+	// Client used to fetch the JWKS, defaults to a client with a timeout of 10 seconds
+	Client *http.Client
+}
+
+// keySource resolves the key verifying a token with the given key ID
+type keySource interface {
+	key(kid string) (any, error)
+}
+
+// Middleware rejects requests without a valid JWT bearer token. The claims of valid tokens are attached to the
+// request context, so that they can be merged into the evaluation context. Unlike attributes sent by clients, the
+// claims can't be altered without invalidating the signature.
+type Middleware struct {
+	logger *logger.Logger
+	keys   keySource
+	parser *jwt.Parser
+}
+
+func New(log *logger.Logger, cfg Configuration) (*Middleware, error) {
+	var keys keySource
+	switch {
+	case cfg.PublicKeyPath != "" && cfg.JWKSURL != "":
+		return nil, errors.New("only one of a public key and a JWKS URL can be configured")
+	case cfg.PublicKeyPath != "":
+		key, err := loadPublicKey(cfg.PublicKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		keys = staticKey{publicKey: key}
+	case cfg.JWKSURL != "":
+		set, err := newJWKS(log, cfg.JWKSURL, cfg.Client)
+		if err != nil {
+			return nil, err
+		}
+		keys = set
+	default:
+		return nil, errors.New("either a public key or a JWKS URL must be configured")
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(validMethods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Middleware{
+		logger: log,
+		keys:   keys,
+		parser: jwt.NewParser(opts...),
+	}, nil
+}
+
+func (m *Middleware) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := m.verify(r.Header.Get("Authorization"))
+		if err != nil {
+			m.logger.Debug(fmt.Sprintf("rejecting request to %s: %v", r.URL.Path, err))
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(w, r.WithContext(WithClaims(r.Context(), claims)))
+	})
+}
+
+func (m *Middleware) verify(authorization string) (map[string]any, error) {
+	raw, ok := strings.CutPrefix(authorization, bearerPrefix)
+	if !ok || raw == "" {
+		return nil, errors.New("missing bearer token")
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := m.parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return m.keys.key(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	return claims, nil
+}
+
+// WithClaims returns a copy of ctx carrying the verified claims of the request
+func WithClaims(ctx context.Context, claims map[string]any) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext returns the verified claims attached to ctx, or nil if the request wasn't authenticated
+func ClaimsFromContext(ctx context.Context) map[string]any {
+	claims, _ := ctx.Value(contextKey{}).(map[string]any)
+	return claims
+}
+
+type staticKey struct {
+	publicKey any
+}
+
+func (k staticKey) key(_ string) (any, error) {
+	return k.publicKey, nil
+}
+
+func loadPublicKey(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read public key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s is not PEM encoded", path)
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse public key %s: %w", path, err)
+		}
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse public key %s: %w", path, err)
+	}
+	return key, nil
+}
@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func writePublicKey(t *testing.T, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+// serve returns the status code and the claims seen by the handler behind the middleware
+func serve(t *testing.T, mw *Middleware, authorization string) (int, map[string]any) {
+	t.Helper()
+	var claims map[string]any
+	handler := mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = ClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/flagd.evaluation.v1.Service/ResolveBoolean", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code, claims
+}
+
+func TestMiddleware_PublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	mw, err := New(logger.NewLogger(nil, false), Configuration{
+		PublicKeyPath: writePublicKey(t, &key.PublicKey),
+		Issuer:        "https://issuer.example.com",
+		Audience:      "flagd",
+	})
+	require.NoError(t, err)
+
+	valid := jwt.MapClaims{
+		"iss":  "https://issuer.example.com",
+		"aud":  "flagd",
+		"sub":  "user-1",
+		"plan": "enterprise",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+	with := func(key string, value any) jwt.MapClaims {
+		claims := jwt.MapClaims{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := map[string]struct {
+		authorization string
+		expectedCode  int
+	}{
+		"valid token": {
+			authorization: "Bearer " + sign(t, jwt.SigningMethodRS256, key, "", valid),
+			expectedCode:  http.StatusOK,
+		},
+		"missing token": {
+			expectedCode: http.StatusUnauthorized,
+		},
+		"not a bearer token": {
+			authorization: "Basic dXNlcjpwYXNz",
+			expectedCode:  http.StatusUnauthorized,
+		},
+		"malformed token": {
+			authorization: "Bearer not-a-token",
+			expectedCode:  http.StatusUnauthorized,
+		},
+		"invalid signature": {
+			authorization: "Bearer " + sign(t, jwt.SigningMethodRS256, otherKey, "", valid),
+			expectedCode:  http.StatusUnauthorized,
+		},
+		"symmetric signature": {
+			authorization: "Bearer " + sign(t, jwt.SigningMethodHS256, []byte("secret"), "", valid),
+			expectedCode:  http.StatusUnauthorized,
+		},
+		"unsigned token": {
+			authorization: "Bearer " + sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", valid),
+			expectedCode:  http.StatusUnauthorized,
+		},
+		"expired token": {
+			authorization: "Bearer " + sign(t, jwt.SigningMethodRS256, key, "",
+				with("exp", time.Now().Add(-time.Minute).Unix())),
+			expectedCode: http.StatusUnauthorized,
+		},
+		"token without expiry": {
+			authorization: "Bearer " + sign(t, jwt.SigningMethodRS256, key, "", with("exp", nil)),
+			expectedCode:  http.StatusUnauthorized,
+		},
+		"wrong issuer": {
+			authorization: "Bearer " + sign(t, jwt.SigningMethodRS256, key, "", with("iss", "https://other.example.com")),
+			expectedCode:  http.StatusUnauthorized,
+		},
+		"wrong audience": {
+			authorization: "Bearer " + sign(t, jwt.SigningMethodRS256, key, "", with("aud", "other")),
+			expectedCode:  http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, claims := serve(t, mw, tt.authorization)
+			require.Equal(t, tt.expectedCode, code)
+			if tt.expectedCode == http.StatusOK {
+				require.Equal(t, "user-1", claims["sub"])
+				require.Equal(t, "enterprise", claims["plan"])
+			}
+		})
+	}
+}
+
+func TestMiddleware_JWKS(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPublic, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{
+				{
+					"kty": "EC", "kid": "ec", "use": "sig", "crv": "P-256",
+					"x": encode(ecKey.X.Bytes()), "y": encode(ecKey.Y.Bytes()),
+				},
+				{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": encode(edPublic)},
+				{
+					"kty": "RSA", "kid": "rsa",
+					"n": encode(rsaKey.N.Bytes()), "e": encode([]byte{1, 0, 1}),
+				},
+				{"kty": "RSA", "kid": "enc", "use": "enc", "n": encode(rsaKey.N.Bytes()), "e": "AQAB"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	mw, err := New(logger.NewLogger(nil, false), Configuration{JWKSURL: server.URL})
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := map[string]struct {
+		token        string
+		expectedCode int
+	}{
+		"ecdsa key": {
+			token:        sign(t, jwt.SigningMethodES256, ecKey, "ec", claims),
+			expectedCode: http.StatusOK,
+		},
+		"ed25519 key": {
+			token:        sign(t, jwt.SigningMethodEdDSA, edKey, "ed", claims),
+			expectedCode: http.StatusOK,
+		},
+		"rsa key": {
+			token:        sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claims),
+			expectedCode: http.StatusOK,
+		},
+		"mismatching key id": {
+			token:        sign(t, jwt.SigningMethodES256, ecKey, "ed", claims),
+			expectedCode: http.StatusUnauthorized,
+		},
+		"encryption key": {
+			token:        sign(t, jwt.SigningMethodRS256, rsaKey, "enc", claims),
+			expectedCode: http.StatusUnauthorized,
+		},
+		"unknown key id": {
+			token:        sign(t, jwt.SigningMethodES256, ecKey, "unknown", claims),
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, _ := serve(t, mw, "Bearer "+tt.token)
+			require.Equal(t, tt.expectedCode, code)
+		})
+	}
+
+	// unknown key ids don't trigger a refresh within the minimum refresh interval
+	require.Equal(t, 1, fetches)
+}
+
+func TestJWKS_RefreshDoesNotBlockLookups(t *testing.T) {
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if fetches.Add(1) > 1 {
+			entered <- struct{}{}
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]any{
+				{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(edPublic)},
+			},
+		})
+	}))
+	defer server.Close()
+
+	set, err := newJWKS(logger.NewLogger(nil, false), server.URL, nil)
+	require.NoError(t, err)
+	_, err = set.key("ed")
+	require.NoError(t, err)
+
+	// an unknown key id refreshes the key set once the minimum refresh interval passed
+	set.mu.Lock()
+	set.fetched = time.Now().Add(-2 * jwksMinRefreshInterval)
+	set.mu.Unlock()
+	refreshed := make(chan error)
+	for range 2 {
+		go func() {
+			_, err := set.key("unknown")
+			refreshed <- err
+		}()
+	}
+	<-entered
+
+	// known keys are looked up while the key set is being fetched
+	_, err = set.key("ed")
+	require.NoError(t, err)
+
+	close(release)
+	require.Error(t, <-refreshed)
+	require.Error(t, <-refreshed)
+	require.Equal(t, int32(2), fetches.Load())
+}
+
+func TestNew_InvalidConfiguration(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPath := writePublicKey(t, &key.PublicKey)
+
+	invalidKeyPath := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidKeyPath, []byte("not a key"), 0o600))
+
+	tests := map[string]Configuration{
+		"no key source":       {},
+		"both key sources":    {PublicKeyPath: keyPath, JWKSURL: "https://issuer.example.com/jwks.json"},
+		"missing public key":  {PublicKeyPath: filepath.Join(t.TempDir(), "missing.pem")},
+		"invalid public key":  {PublicKeyPath: invalidKeyPath},
+		"invalid JWKS scheme": {JWKSURL: "file:///jwks.json"},
+		"relative JWKS url":   {JWKSURL: "jwks.json"},
+		"malformed JWKS url":  {JWKSURL: "://"},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(logger.NewLogger(nil, false), cfg)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultJWKSTimeout = 10 * time.Second
+	// jwksMinRefreshInterval bounds the refreshes triggered by tokens with unknown key IDs
+	jwksMinRefreshInterval = time.Minute
+	// jwksMaxAge is the age after which the key set is refreshed, so that revoked keys are dropped
+	jwksMaxAge = time.Hour
+)
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwks is a lazily fetched JSON Web Key Set, refreshed once it is outdated or a token references an unknown key. The
+// key set is fetched outside of the lock and swapped in, so that lookups aren't blocked by refreshes.
+type jwks struct {
+	logger *logger.Logger
+	url    string
+	client *http.Client
+
+	mu      sync.RWMutex
+	keys    map[string]any
+	fetched time.Time
+	// refreshes joins the concurrent refreshes of the key set into a single fetch
+	refreshes singleflight.Group
+}
+
+func newJWKS(log *logger.Logger, rawURL string, client *http.Client) (*jwks, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid JWKS url %s: scheme must be http or https", rawURL)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultJWKSTimeout}
+	}
+
+	return &jwks{
+		logger: log,
+		url:    rawURL,
+		client: client,
+		keys:   map[string]any{},
+	}, nil
+}
+
+func (j *jwks) key(kid string) (any, error) {
+	key, ok, fetched := j.lookup(kid)
+	if ok && time.Since(fetched) < jwksMaxAge {
+		return key, nil
+	}
+	if time.Since(fetched) >= jwksMinRefreshInterval {
+		_, _, _ = j.refreshes.Do("", func() (any, error) {
+			j.refreshOutdated()
+			return nil, nil
+		})
+	}
+
+	key, ok, _ = j.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("no key matching the key id '%s'", kid)
+	}
+	return key, nil
+}
+
+// lookup selects the key by its ID and returns the time the key set was fetched. Tokens without key ID are accepted if
+// the set holds a single key.
+func (j *jwks) lookup(kid string) (any, bool, time.Time) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true, j.fetched
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok, j.fetched
+}
+
+// refreshOutdated refreshes the key set unless it was fetched within the minimum refresh interval, e.g. by a refresh
+// completed while the caller waited for the lock
+func (j *jwks) refreshOutdated() {
+	j.mu.RLock()
+	fetched := j.fetched
+	j.mu.RUnlock()
+	if time.Since(fetched) < jwksMinRefreshInterval {
+		return
+	}
+
+	keys, err := j.fetch()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// failed fetches are bounded by the minimum refresh interval as well
+	j.fetched = time.Now()
+	if err != nil {
+		// keep serving the previous keys while the endpoint is unavailable
+		j.logger.Warn(fmt.Sprintf("unable to refresh the JWKS from %s: %v", j.url, err))
+		return
+	}
+	j.keys = keys
+}
+
+// fetch fetches the key set, returning its signature keys by their ID
+func (j *jwks) fetch() (map[string]any, error) {
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching key set: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			j.logger.Debug(fmt.Sprintf("error closing the response body: %s", err.Error()))
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("error decoding key set: %w", err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			j.logger.Warn(fmt.Sprintf("ignoring key '%s' of the JWKS: %v", jwk.Kid, err))
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve '%s'", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid public key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
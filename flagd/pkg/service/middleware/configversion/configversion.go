@@ -0,0 +1,29 @@
+package configversion
+
+import (
+	"net/http"
+)
+
+// DefaultHeaderName is the default response header carrying the version of the applied flag configuration
+const DefaultHeaderName = "Flagd-Config-Version"
+
+// Middleware returns the version of the applied flag configuration with each response, as header of HTTP responses
+// and as metadata of gRPC responses. Clients caching evaluation results can invalidate them once the version changes.
+type Middleware struct {
+	header  string
+	version func() string
+}
+
+// New creates a Middleware returning the version provided by the version function through the given header
+func New(header string, version func() string) *Middleware {
+	return &Middleware{header: header, version: version}
+}
+
+func (m Middleware) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if version := m.version(); version != "" {
+			w.Header().Set(m.header, version)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
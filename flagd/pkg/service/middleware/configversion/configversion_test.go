@@ -0,0 +1,34 @@
+package configversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	version := "v1"
+	handler := New(DefaultHeaderName, func() string {
+		return version
+	}).Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, "v1", recorder.Header().Get(DefaultHeaderName))
+
+	// each response carries the version at the time of the request
+	version = "v2"
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, "v2", recorder.Header().Get(DefaultHeaderName))
+
+	// the header is omitted if no version is known
+	version = ""
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.NotContains(t, recorder.Header(), DefaultHeaderName)
+}
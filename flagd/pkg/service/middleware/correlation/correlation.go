@@ -0,0 +1,90 @@
+package correlation
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/rs/xid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// HeaderName is the header used to accept a correlation ID from clients and to return it with the response
+const HeaderName = "X-Request-Id"
+
+// FieldName is the log field holding the correlation ID of request scoped log lines
+const FieldName = "correlationID"
+
+// maxIDLength bounds client provided correlation IDs, longer values are replaced by a generated ID
+const maxIDLength = 128
+
+type contextKey struct{}
+
+// Middleware attaches a correlation ID to each request, which is then logged with all log lines written while serving
+// the request, see NewRequestID. The ID is derived from the incoming trace context if a trace is active, so that logs and
+// traces line up. Otherwise, the ID provided by the client through the HeaderName header is used, falling back to a
+// generated ID. The resulting ID is returned to the client through the HeaderName response header.
+type Middleware struct{}
+
+func New() *Middleware {
+	return &Middleware{}
+}
+
+func (m Middleware) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		id := resolveID(ctx, r.Header.Get(HeaderName))
+
+		w.Header().Set(HeaderName, id)
+		handler.ServeHTTP(w, r.WithContext(WithID(r.Context(), id)))
+	})
+}
+
+// WithID returns a copy of ctx carrying the given correlation ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the correlation ID attached to ctx. If no ID was attached, the ID is derived from the active
+// trace, if any, or generated.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(contextKey{}).(string); ok && id != "" {
+		return id
+	}
+	return resolveID(ctx, "")
+}
+
+// NewRequestID returns a unique ID of the request scoped log fields of a request and writes the correlation ID of ctx as
+// FieldName field. The correlation ID isn't used as key of the fields itself, as it is chosen by clients or shared by
+// the requests of a trace, so that concurrent requests would overwrite and clear the fields of each other.
+func NewRequestID(ctx context.Context, log *logger.Logger) string {
+	reqID := xid.New().String()
+	log.WriteFields(reqID, zap.String(FieldName, FromContext(ctx)))
+	return reqID
+}
+
+func resolveID(ctx context.Context, provided string) string {
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		return spanCtx.TraceID().String()
+	}
+	if isValidID(provided) {
+		return provided
+	}
+	return xid.New().String()
+}
+
+// isValidID guards against empty, oversized or non-printable client provided IDs ending up in logs and headers
+func isValidID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
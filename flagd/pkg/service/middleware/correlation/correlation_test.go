@@ -0,0 +1,121 @@
+package correlation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+func TestMiddleware(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	tests := map[string]struct {
+		headers  map[string]string
+		expected string
+	}{
+		"trace context takes precedence": {
+			headers: map[string]string{
+				"traceparent": "00-" + traceID + "-00f067aa0ba902b7-01",
+				HeaderName:    "client-id",
+			},
+			expected: traceID,
+		},
+		"client provided id": {
+			headers: map[string]string{
+				HeaderName: "client-id",
+			},
+			expected: "client-id",
+		},
+		"oversized client provided id is replaced": {
+			headers: map[string]string{
+				HeaderName: strings.Repeat("a", maxIDLength+1),
+			},
+		},
+		"client provided id with whitespace is replaced": {
+			headers: map[string]string{
+				HeaderName: "client id",
+			},
+		},
+		"generated id": {},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var seen string
+			mw := New()
+			ts := httptest.NewServer(mw.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = FromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})))
+			defer ts.Close()
+
+			req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+			require.Nil(t, err)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.Nil(t, err)
+			defer resp.Body.Close()
+
+			require.NotEmpty(t, seen)
+			require.Equal(t, seen, resp.Header.Get(HeaderName), "response must carry the id used for logging")
+			if tt.expected != "" {
+				require.Equal(t, tt.expected, seen)
+			} else {
+				require.NotEqual(t, tt.headers[HeaderName], seen)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	require.Equal(t, "my-id", FromContext(WithID(context.Background(), "my-id")))
+
+	tid, err := trace.TraceIDFromHex(traceID)
+	require.Nil(t, err)
+	spanCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: tid,
+		SpanID:  trace.SpanID{1},
+	}))
+	require.Equal(t, traceID, FromContext(spanCtx))
+
+	generated := FromContext(context.Background())
+	require.NotEmpty(t, generated)
+	require.NotEqual(t, generated, FromContext(context.Background()))
+}
+
+func TestNewRequestID(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log := logger.NewLogger(zap.New(core), true)
+	ctx := WithID(context.Background(), "client-id")
+
+	// requests sharing a correlation ID don't share their log fields
+	first := NewRequestID(ctx, log)
+	second := NewRequestID(ctx, log)
+	require.NotEqual(t, first, second)
+	log.ClearFields(first)
+
+	log.DebugWithID(second, "evaluated")
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "client-id", fields[FieldName])
+	require.Equal(t, second, fields[logger.RequestIDFieldName])
+}
@@ -3,6 +3,7 @@ package cors
 import (
 	"net/http"
 
+	"github.com/open-feature/flagd/flagd/pkg/service/middleware/correlation"
 	"github.com/rs/cors"
 )
 
@@ -10,7 +11,9 @@ type Middleware struct {
 	cors *cors.Cors
 }
 
-func New(allowedOrigins []string) *Middleware {
+// New creates a Middleware allowing the given origins. Additional response headers to expose to the clients, next to
+// the headers of the gRPC and Connect protocols, can be passed as exposedHeaders.
+func New(allowedOrigins []string, exposedHeaders ...string) *Middleware {
 	return &Middleware{
 		cors: cors.New(cors.Options{
 			AllowedMethods: []string{
@@ -23,7 +26,7 @@ func New(allowedOrigins []string) *Middleware {
 			},
 			AllowedOrigins: allowedOrigins,
 			AllowedHeaders: []string{"*"},
-			ExposedHeaders: []string{
+			ExposedHeaders: append([]string{
 				// Content-Type is in the default safelist.
 				"Accept",
 				"Accept-Encoding",
@@ -36,7 +39,8 @@ func New(allowedOrigins []string) *Middleware {
 				"Grpc-Message",
 				"Grpc-Status",
 				"Grpc-Status-Details-Bin",
-			},
+				correlation.HeaderName,
+			}, exposedHeaders...),
 		}),
 	}
 }
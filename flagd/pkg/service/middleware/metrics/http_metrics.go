@@ -156,6 +156,11 @@ func (w *responseWriterInterceptor) Hijack() (net.Conn, *bufio.ReadWriter, error
 	return conn, buf, nil
 }
 
+// Unwrap exposes the wrapped ResponseWriter to http.ResponseController
+func (w *responseWriterInterceptor) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Flush need to exist to be compatible with connect-go.
 // See https://github.com/connectrpc/connect-go/blob/main/protocol_connect.go
 func (w *responseWriterInterceptor) Flush() {
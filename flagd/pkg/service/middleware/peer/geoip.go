@@ -0,0 +1,107 @@
+package peer
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Database is a Locator backed by a CSV file mapping networks to country codes, one `network,country` pair per line,
+// e.g. `192.0.2.0/24,DE`. Empty lines and lines starting with # are ignored. The most specific network matching an
+// IP address determines its country.
+type Database struct {
+	v4 networkTable
+	v6 networkTable
+}
+
+// networkTable holds the countries of the networks of one address family, grouped by prefix length
+type networkTable struct {
+	networks map[int]map[netip.Prefix]string
+	// lengths holds the prefix lengths of the networks, longest first
+	lengths []int
+}
+
+// LoadDatabase reads a CSV GeoIP database from path
+func LoadDatabase(path string) (*Database, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open GeoIP database %s: %w", path, err)
+	}
+	defer file.Close()
+
+	db := &Database{}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		network, country, ok := strings.Cut(entry, ",")
+		country = strings.TrimSpace(country)
+		if !ok || country == "" {
+			return nil, fmt.Errorf("invalid entry in line %d of GeoIP database %s: expected network,country", line, path)
+		}
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(network))
+		if err != nil {
+			return nil, fmt.Errorf("invalid network in line %d of GeoIP database %s: %w", line, path, err)
+		}
+		db.add(prefix, country)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read GeoIP database %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+func (db *Database) add(prefix netip.Prefix, country string) {
+	addr := prefix.Addr()
+	if addr.Is4In6() && prefix.Bits() >= 96 {
+		// IPv4-mapped networks are matched like IPv4 networks, as the looked up addresses are unmapped
+		db.v4.add(netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96).Masked(), country)
+		return
+	}
+	if addr.Is4() {
+		db.v4.add(prefix.Masked(), country)
+		return
+	}
+	db.v6.add(prefix.Masked(), country)
+}
+
+func (db *Database) Country(ip netip.Addr) (string, bool) {
+	ip = ip.Unmap()
+	if ip.Is4() {
+		return db.v4.lookup(ip)
+	}
+	return db.v6.lookup(ip)
+}
+
+func (t *networkTable) add(prefix netip.Prefix, country string) {
+	if t.networks == nil {
+		t.networks = map[int]map[netip.Prefix]string{}
+	}
+	if _, ok := t.networks[prefix.Bits()]; !ok {
+		t.networks[prefix.Bits()] = map[netip.Prefix]string{}
+		t.lengths = append(t.lengths, prefix.Bits())
+		slices.Sort(t.lengths)
+		slices.Reverse(t.lengths)
+	}
+	t.networks[prefix.Bits()][prefix] = country
+}
+
+func (t *networkTable) lookup(ip netip.Addr) (string, bool) {
+	for _, length := range t.lengths {
+		prefix, err := ip.Prefix(length)
+		if err != nil {
+			continue
+		}
+		if country, ok := t.networks[length][prefix]; ok {
+			return country, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,81 @@
+package peer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+const (
+	// ContextKey is the evaluation context key holding the attributes of the peer, e.g. {"var": "peer.ip"}
+	ContextKey = "peer"
+	// IPKey holds the IP address of the peer
+	IPKey = "ip"
+	// CountryKey holds the country code of the peer, if a Locator is configured and knows the IP address
+	CountryKey = "country"
+)
+
+type contextKey struct{}
+
+// Locator resolves the country of an IP address. Implementations backed by other GeoIP databases or services can be
+// plugged into the Middleware.
+type Locator interface {
+	Country(ip netip.Addr) (string, bool)
+}
+
+// Middleware attaches the attributes of the peer of a request to the request context, so that they can be merged into
+// the evaluation context without having clients send them.
+type Middleware struct {
+	locator Locator
+}
+
+// New creates a Middleware, the locator is optional
+func New(locator Locator) *Middleware {
+	return &Middleware{locator: locator}
+}
+
+func (m *Middleware) Handler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attributes := m.attributes(r.RemoteAddr)
+		if attributes != nil {
+			r = r.WithContext(WithAttributes(r.Context(), attributes))
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// attributes derives the peer attributes from the remote address, requests served on a unix socket have none
+func (m *Middleware) attributes(remoteAddr string) map[string]any {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return nil
+	}
+	ip = ip.Unmap().WithZone("")
+
+	attributes := map[string]any{IPKey: ip.String()}
+	if m.locator != nil {
+		if country, ok := m.locator.Country(ip); ok {
+			attributes[CountryKey] = country
+		}
+	}
+	return attributes
+}
+
+// WithAttributes returns a copy of ctx carrying the attributes of the peer
+func WithAttributes(ctx context.Context, attributes map[string]any) context.Context {
+	return context.WithValue(ctx, contextKey{}, attributes)
+}
+
+// EvaluationContext returns the peer attributes attached to ctx as evaluation context, or nil if there are none
+func EvaluationContext(ctx context.Context) map[string]any {
+	attributes, ok := ctx.Value(contextKey{}).(map[string]any)
+	if !ok {
+		return nil
+	}
+	return map[string]any{ContextKey: attributes}
+}
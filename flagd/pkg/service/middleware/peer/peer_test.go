@@ -0,0 +1,129 @@
+package peer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDatabase(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "geoip.csv")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestMiddleware(t *testing.T) {
+	db, err := LoadDatabase(writeDatabase(t, "192.0.2.0/24,DE\n2001:db8::/32,FR\n"))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		locator    Locator
+		remoteAddr string
+		expected   map[string]any
+	}{
+		"ipv4 peer": {
+			remoteAddr: "192.0.2.1:51234",
+			expected:   map[string]any{ContextKey: map[string]any{IPKey: "192.0.2.1"}},
+		},
+		"ipv6 peer": {
+			remoteAddr: "[2001:db8::1]:51234",
+			expected:   map[string]any{ContextKey: map[string]any{IPKey: "2001:db8::1"}},
+		},
+		"ipv4-mapped peer": {
+			remoteAddr: "[::ffff:192.0.2.1]:51234",
+			expected:   map[string]any{ContextKey: map[string]any{IPKey: "192.0.2.1"}},
+		},
+		"located peer": {
+			locator:    db,
+			remoteAddr: "192.0.2.1:51234",
+			expected:   map[string]any{ContextKey: map[string]any{IPKey: "192.0.2.1", CountryKey: "DE"}},
+		},
+		"unknown location": {
+			locator:    db,
+			remoteAddr: "198.51.100.1:51234",
+			expected:   map[string]any{ContextKey: map[string]any{IPKey: "198.51.100.1"}},
+		},
+		"unix socket peer": {
+			remoteAddr: "@",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got map[string]any
+			handler := New(tt.locator).Handler(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = EvaluationContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/flagd.evaluation.v1.Service/ResolveBoolean", nil)
+			req.RemoteAddr = tt.remoteAddr
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestDatabase(t *testing.T) {
+	db, err := LoadDatabase(writeDatabase(t, `# network,country
+10.0.0.0/8,US
+10.1.0.0/16,CA
+10.1.2.3/32,MX
+
+2001:db8::/32,FR
+2001:db8:1::/48,BE
+::ffff:192.0.2.0/120,DE
+`))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		ip      string
+		country string
+	}{
+		"network":                {ip: "10.200.0.1", country: "US"},
+		"most specific network":  {ip: "10.1.2.1", country: "CA"},
+		"host":                   {ip: "10.1.2.3", country: "MX"},
+		"ipv6 network":           {ip: "2001:db8:2::1", country: "FR"},
+		"most specific ipv6":     {ip: "2001:db8:1::1", country: "BE"},
+		"ipv4-mapped network":    {ip: "192.0.2.10", country: "DE"},
+		"ipv4-mapped address":    {ip: "::ffff:10.1.2.3", country: "MX"},
+		"unknown ipv4":           {ip: "198.51.100.1"},
+		"unknown ipv6":           {ip: "2001:db9::1"},
+		"ipv4 not matching ipv6": {ip: "32.1.13.184"},
+		"ipv6 not matching ipv4": {ip: "a00::1"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			country, ok := db.Country(netip.MustParseAddr(tt.ip))
+			assert.Equal(t, tt.country != "", ok)
+			assert.Equal(t, tt.country, country)
+		})
+	}
+}
+
+func TestLoadDatabase_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing country": "10.0.0.0/8\n",
+		"empty country":   "10.0.0.0/8, \n",
+		"invalid network": "10.0.0.0/33,US\n",
+		"address":         "10.0.0.1,US\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadDatabase(writeDatabase(t, content))
+			require.Error(t, err)
+		})
+	}
+
+	_, err := LoadDatabase(filepath.Join(t.TempDir(), "missing.csv"))
+	require.Error(t, err)
+}
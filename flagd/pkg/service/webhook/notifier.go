@@ -0,0 +1,196 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+const (
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body, prefixed with "sha256="
+	SignatureHeader = "X-Flagd-Signature"
+	// EventTypeConfigurationChange is the type of events sent for applied flag configuration changes
+	EventTypeConfigurationChange = "configuration_change"
+
+	defaultMaxAttempts = 5
+	defaultBackoff     = time.Second
+	defaultTimeout     = 10 * time.Second
+	// queueSize bounds the number of pending events, further events are dropped while the webhook is unavailable
+	queueSize = 100
+)
+
+type INotifier interface {
+	// Start delivering events until the context is done
+	Start(context.Context) error
+
+	// Notify enqueues an event for delivery without blocking the caller
+	Notify(event Event)
+}
+
+// Event describes an applied flag configuration change
+type Event struct {
+	Type    string   `json:"type"`
+	Source  string   `json:"source"`
+	Flags   []string `json:"flags"`
+	Version string   `json:"version"`
+}
+
+// Configuration of the webhook notifier
+type Configuration struct {
+	Logger  *logger.Logger
+	URL     string
+	Secret  string
+	Metrics telemetry.IMetricsRecorder
+	// MaxAttempts is the number of delivery attempts of an event, defaults to 5
+	MaxAttempts int
+	// Backoff is the delay before the first retry, which doubles with each further retry. Defaults to one second
+	Backoff time.Duration
+	// Client used to deliver events, defaults to a client with a timeout of 10 seconds
+	Client *http.Client
+}
+
+// Notifier POSTs flag change events to a webhook. Events are delivered sequentially by a single worker, so that a
+// failing webhook never blocks the flag store updates.
+type Notifier struct {
+	logger      *logger.Logger
+	url         string
+	secret      []byte
+	metrics     telemetry.IMetricsRecorder
+	maxAttempts int
+	backoff     time.Duration
+	client      *http.Client
+	events      chan Event
+}
+
+func NewNotifier(cfg Configuration) (*Notifier, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook url %s: scheme must be http or https", cfg.URL)
+	}
+
+	n := &Notifier{
+		logger:      cfg.Logger,
+		url:         cfg.URL,
+		secret:      []byte(cfg.Secret),
+		metrics:     cfg.Metrics,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     cfg.Backoff,
+		client:      cfg.Client,
+		events:      make(chan Event, queueSize),
+	}
+	if n.metrics == nil {
+		n.metrics = &telemetry.NoopMetricsRecorder{}
+	}
+	if n.maxAttempts <= 0 {
+		n.maxAttempts = defaultMaxAttempts
+	}
+	if n.backoff <= 0 {
+		n.backoff = defaultBackoff
+	}
+	if n.client == nil {
+		n.client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Notify(event Event) {
+	select {
+	case n.events <- event:
+	default:
+		n.logger.Warn(fmt.Sprintf("webhook event queue is full, dropping change event of version %s", event.Version))
+		telemetry.ServiceMetrics(n.metrics).WebhookDeliveryFailure(context.Background())
+	}
+}
+
+func (n *Notifier) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-n.events:
+			if err := n.deliver(ctx, event); err != nil {
+				if ctx.Err() != nil {
+					// shutting down
+					return nil
+				}
+				n.logger.Error(fmt.Sprintf("failed to deliver change event of version %s to webhook: %v",
+					event.Version, err))
+				telemetry.ServiceMetrics(n.metrics).WebhookDeliveryFailure(ctx)
+			}
+		}
+	}
+}
+
+// deliver sends the event, retrying with exponential backoff until the webhook acknowledges it with a 2xx status
+func (n *Notifier) deliver(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	backoff := n.backoff
+	for attempt := 1; ; attempt++ {
+		err = n.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= n.maxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		n.logger.Debug(fmt.Sprintf("webhook delivery attempt %d failed, retrying in %s: %v", attempt, backoff, err))
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("delivery aborted: %w", ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling webhook: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			n.logger.Debug(fmt.Sprintf("error closing the response body: %s", err.Error()))
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the value of the SignatureHeader for the given body, allowing receivers to verify events
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
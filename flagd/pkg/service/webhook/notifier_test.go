@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failureRecorder struct {
+	telemetry.NoopMetricsRecorder
+	failures atomic.Int32
+}
+
+func (r *failureRecorder) WebhookDeliveryFailure(_ context.Context) {
+	r.failures.Add(1)
+}
+
+func TestNewNotifier_InvalidURL(t *testing.T) {
+	for _, u := range []string{"ftp://example.com", "example.com/hook", "://"} {
+		_, err := NewNotifier(Configuration{Logger: logger.NewLogger(nil, false), URL: u})
+		assert.Error(t, err, u)
+	}
+}
+
+func TestNotifier_Delivery(t *testing.T) {
+	const secret = "secret"
+	event := Event{
+		Type:    EventTypeConfigurationChange,
+		Source:  "file:flags.json",
+		Flags:   []string{"flagA", "flagB"},
+		Version: "abc",
+	}
+
+	tests := map[string]struct {
+		failures         int32
+		maxAttempts      int
+		expectDelivered  bool
+		expectedAttempts int32
+	}{
+		"delivered at first attempt": {
+			maxAttempts:      3,
+			expectDelivered:  true,
+			expectedAttempts: 1,
+		},
+		"delivered after retries": {
+			failures:         2,
+			maxAttempts:      3,
+			expectDelivered:  true,
+			expectedAttempts: 3,
+		},
+		"failed after max attempts": {
+			failures:         5,
+			maxAttempts:      3,
+			expectedAttempts: 3,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var attempts atomic.Int32
+			delivered := make(chan Event, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				assert.Equal(t, Sign([]byte(secret), body), r.Header.Get(SignatureHeader))
+
+				var received Event
+				require.NoError(t, json.Unmarshal(body, &received))
+				delivered <- received
+			}))
+			defer server.Close()
+
+			recorder := &failureRecorder{}
+			notifier, err := NewNotifier(Configuration{
+				Logger:      logger.NewLogger(nil, false),
+				URL:         server.URL,
+				Secret:      secret,
+				Metrics:     recorder,
+				MaxAttempts: tt.maxAttempts,
+				Backoff:     time.Millisecond,
+			})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				_ = notifier.Start(ctx)
+			}()
+
+			notifier.Notify(event)
+
+			if tt.expectDelivered {
+				select {
+				case received := <-delivered:
+					assert.Equal(t, event, received)
+				case <-time.After(time.Second):
+					t.Fatal("event was not delivered")
+				}
+			} else {
+				require.Eventually(t, func() bool {
+					return recorder.failures.Load() == 1
+				}, time.Second, 10*time.Millisecond)
+			}
+			assert.Equal(t, tt.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestNotifier_NotifyDoesNotBlock(t *testing.T) {
+	recorder := &failureRecorder{}
+	notifier, err := NewNotifier(Configuration{
+		Logger:  logger.NewLogger(nil, false),
+		URL:     "http://localhost/hook",
+		Metrics: recorder,
+	})
+	require.NoError(t, err)
+
+	// without a running worker the queue fills up, further events are dropped
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < queueSize+2; i++ {
+			notifier.Notify(Event{Version: "v"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("notify blocked")
+	}
+	assert.Equal(t, int32(2), recorder.failures.Load())
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"type":"configuration_change"}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"sha256=b041357fd73093769d5263b49fb3669c39ba6d930e2a32a415978cd0022ef261",
+		Sign([]byte("secret"), []byte(`{"type":"configuration_change"}`)))
+}